@@ -0,0 +1,4 @@
+package arbitary
+
+// ActorX is the context key of the uid of the member doing the request.
+type ActorX struct{}
@@ -0,0 +1,22 @@
+package arbitary
+
+import "context"
+
+// AfterCommitX is the context key of the funcs run once the transaction of the
+// request is committed.
+type AfterCommitX struct{}
+
+// AfterCommit run the func once the transaction of the context is committed,
+// for the write outside of the database which must not happen when the
+// transaction is rolled back. The func is run right away when there is no
+// transaction waiting to be committed.
+func AfterCommit(ctx context.Context, fn func() error) error {
+	fns, ok := ctx.Value(AfterCommitX{}).(*[]func() error)
+	if !ok {
+		return fn()
+	}
+
+	*fns = append(*fns, fn)
+
+	return nil
+}
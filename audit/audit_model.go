@@ -0,0 +1,49 @@
+package audit
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"strings"
+	"time"
+)
+
+// Actions of the recorded events, the entity specific action is written as is.
+const (
+	ActionCreate  = "create"
+	ActionUpdate  = "update"
+	ActionDelete  = "delete"
+	ActionApprove = "approve"
+	ActionReject  = "reject"
+	ActionRevert  = "revert"
+)
+
+type EventModel struct {
+	Id        uint64
+	ActorId   sql.NullString
+	ActorName sql.NullString
+	Action    string
+	Entity    string
+	EntityId  string
+	Diff      string
+	PrevHash  string
+	Hash      string
+	CreatedAt time.Time
+}
+
+// ComputeHash return the hash of the event chained to the previous event, a
+// change of any recorded field or of the previous event break the chain.
+func (m EventModel) ComputeHash() string {
+	h := sha256.New()
+	h.Write([]byte(strings.Join([]string{
+		m.PrevHash,
+		m.ActorId.String,
+		m.Action,
+		m.Entity,
+		m.EntityId,
+		m.Diff,
+		m.CreatedAt.UTC().Format(time.RFC3339Nano),
+	}, "\n")))
+
+	return hex.EncodeToString(h.Sum(nil))
+}
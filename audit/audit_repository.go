@@ -0,0 +1,195 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	arbitary "github.com/PA-D3RPLA/d3if43-htt-uhomestay/arbitrary"
+	"github.com/georgysavva/scany/pgxscan"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+type AuditRepository struct {
+	PostgreDb *pgxpool.Pool
+}
+
+func NewAuditRepository(postgreDb *pgxpool.Pool) *AuditRepository {
+	return &AuditRepository{
+		PostgreDb: postgreDb,
+	}
+}
+
+type (
+	AuditQuerier func(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+)
+
+// Append chain the event to the latest event and save it. The chain is locked
+// until the transaction end so the concurrent events are chained one by one,
+// a transaction is started when the context has none.
+func (r *AuditRepository) Append(ctx context.Context, m EventModel) (nm EventModel, err error) {
+	lockQuery := `
+		SELECT pg_advisory_xact_lock(hashtext('audit_events'))
+	`
+	lastQuery := `
+		SELECT hash
+		FROM audit_events
+		ORDER BY id DESC
+		LIMIT 1
+	`
+	insertQuery := `
+		INSERT INTO audit_events (
+			actor_id,
+			action,
+			entity,
+			entity_id,
+			diff,
+			prev_hash,
+			hash,
+			created_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id
+	`
+
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if !ok {
+		tx, err = r.PostgreDb.Begin(context.Background())
+		if err != nil {
+			return EventModel{}, err
+		}
+		defer tx.Rollback(context.Background())
+	}
+
+	if _, err = tx.Exec(context.Background(), lockQuery); err != nil {
+		return EventModel{}, err
+	}
+
+	err = tx.QueryRow(context.Background(), lastQuery).Scan(&m.PrevHash)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return EventModel{}, err
+	}
+
+	// The timestamp column keep microseconds, the hash must be computed from
+	// what is read back.
+	m.CreatedAt = time.Now().UTC().Truncate(time.Microsecond)
+	m.Hash = m.ComputeHash()
+
+	err = tx.QueryRow(
+		context.Background(),
+		insertQuery,
+		m.ActorId,
+		m.Action,
+		m.Entity,
+		m.EntityId,
+		m.Diff,
+		m.PrevHash,
+		m.Hash,
+		m.CreatedAt,
+	).Scan(&m.Id)
+
+	if err != nil {
+		return EventModel{}, err
+	}
+
+	if !ok {
+		if err = tx.Commit(context.Background()); err != nil {
+			return EventModel{}, err
+		}
+	}
+
+	return m, nil
+}
+
+// Query return the events from the latest, the zero filter is not applied and
+// the time range is [from, to).
+func (r *AuditRepository) Query(ctx context.Context, entity, actorId string, from, to time.Time, fromId uint64, limit int64) (ms []EventModel, err error) {
+	sqlQuery := `
+		SELECT
+			a.id,
+			a.actor_id,
+			m.name AS actor_name,
+			a.action,
+			a.entity,
+			a.entity_id,
+			a.diff,
+			a.prev_hash,
+			a.hash,
+			a.created_at
+		FROM audit_events a
+		LEFT JOIN members m ON m.id = a.actor_id
+		WHERE ($1::VARCHAR = '' OR a.entity = $1::VARCHAR)
+		AND ($2::TEXT = '' OR a.actor_id::TEXT = $2::TEXT)
+		AND ($3::TIMESTAMP IS NULL OR a.created_at >= $3::TIMESTAMP)
+		AND ($4::TIMESTAMP IS NULL OR a.created_at < $4::TIMESTAMP)
+		AND ($5::BIGINT = 0 OR a.id < $5::BIGINT)
+		ORDER BY a.id DESC
+		LIMIT $6
+	`
+
+	return r.query(ctx, sqlQuery, entity, actorId, nullTime(from), nullTime(to), fromId, limit)
+}
+
+// QueryChain return the events after the id from the oldest, it is used to
+// verify the chain.
+func (r *AuditRepository) QueryChain(ctx context.Context, afterId uint64, limit int64) (ms []EventModel, err error) {
+	sqlQuery := `
+		SELECT
+			id,
+			actor_id,
+			action,
+			entity,
+			entity_id,
+			diff,
+			prev_hash,
+			hash,
+			created_at
+		FROM audit_events
+		WHERE id > $1
+		ORDER BY id
+		LIMIT $2
+	`
+
+	return r.query(ctx, sqlQuery, afterId, limit)
+}
+
+func (r *AuditRepository) query(ctx context.Context, sqlQuery string, args ...interface{}) (ms []EventModel, err error) {
+	var query AuditQuerier
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		query = tx.Query
+	} else {
+		query = r.PostgreDb.Query
+	}
+
+	rows, err := query(
+		context.Background(),
+		sqlQuery,
+		args...,
+	)
+	if err != nil {
+		return []EventModel{}, err
+	}
+	defer rows.Close()
+
+	var mps []*EventModel
+	if err := pgxscan.ScanAll(&mps, rows); err != nil {
+		return []EventModel{}, err
+	}
+
+	ms = make([]EventModel, len(mps))
+	for i, m := range mps {
+		ms[i] = *m
+	}
+
+	return ms, nil
+}
+
+func nullTime(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+
+	return t
+}
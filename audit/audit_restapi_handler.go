@@ -0,0 +1,32 @@
+package audit
+
+import (
+	"net/http"
+
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/resp"
+)
+
+func (d *AuditDeps) GetAudit(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	out := d.QueryAudit(r.Context(), QueryAuditIn{
+		Entity:  q.Get("entity"),
+		ActorId: q.Get("actor_id"),
+		From:    q.Get("from"),
+		To:      q.Get("to"),
+		Cursor:  q.Get("cursor"),
+		Limit:   q.Get("limit"),
+	})
+	if out.Error != nil {
+		d.CaptureExeption(out.Error)
+	}
+	out.HttpJSON(w, resp.NewHttpBody(out.Res))
+}
+
+func (d *AuditDeps) GetAuditVerify(w http.ResponseWriter, r *http.Request) {
+	out := d.VerifyAudit(r.Context())
+	if out.Error != nil {
+		d.CaptureExeption(out.Error)
+	}
+	out.HttpJSON(w, resp.NewHttpBody(out.Res))
+}
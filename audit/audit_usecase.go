@@ -0,0 +1,208 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	arbitary "github.com/PA-D3RPLA/d3if43-htt-uhomestay/arbitrary"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/pagination"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/resp"
+	"github.com/gofrs/uuid"
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrDateFormat = errors.New("format tanggal tidak sesuai <tahun>-<bulan>-<hari>")
+	ErrActorId    = errors.New("id pelaku tidak valid")
+)
+
+// verifyBatch is the number of events read at once when verifying the chain.
+const verifyBatch = 500
+
+// Record save the event with the member of the request as the actor, the event
+// without actor is done by the system.
+func (d *AuditDeps) Record(ctx context.Context, e Event) error {
+	diff, err := Diff(e.Before, e.After)
+	if err != nil {
+		return errors.Wrap(err, "diff")
+	}
+
+	var actorId sql.NullString
+	if uid, ok := ctx.Value(arbitary.ActorX{}).(string); ok && uid != "" {
+		actorId = sql.NullString{String: uid, Valid: true}
+	}
+
+	_, err = d.AuditRepository.Append(ctx, EventModel{
+		ActorId:  actorId,
+		Action:   e.Action,
+		Entity:   e.Entity,
+		EntityId: e.EntityId,
+		Diff:     diff,
+	})
+	if err != nil {
+		return errors.Wrap(err, "append audit event")
+	}
+
+	return nil
+}
+
+type (
+	QueryAuditIn struct {
+		Entity  string
+		ActorId string
+		From    string
+		To      string
+		Cursor  string
+		Limit   string
+	}
+	AuditEventOut struct {
+		Id        uint64          `json:"id"`
+		ActorId   string          `json:"actor_id"`
+		ActorName string          `json:"actor_name"`
+		Action    string          `json:"action"`
+		Entity    string          `json:"entity"`
+		EntityId  string          `json:"entity_id"`
+		Diff      json.RawMessage `json:"diff"`
+		PrevHash  string          `json:"prev_hash"`
+		Hash      string          `json:"hash"`
+		CreatedAt string          `json:"created_at"`
+	}
+	QueryAuditRes struct {
+		Cursor string          `json:"cursor"`
+		Events []AuditEventOut `json:"events"`
+	}
+	QueryAuditOut struct {
+		resp.Response
+		Res QueryAuditRes
+	}
+)
+
+// QueryAudit return the events from the latest. From and To are dates in the
+// server time zone and both are included.
+func (d *AuditDeps) QueryAudit(ctx context.Context, in QueryAuditIn) (out QueryAuditOut) {
+	var err error
+	out.Response = resp.NewResponse(http.StatusOK, "", nil)
+
+	if in.ActorId != "" {
+		if _, err = uuid.FromString(in.ActorId); err != nil {
+			out.Response = resp.NewResponse(http.StatusUnprocessableEntity, "", ErrActorId)
+			return
+		}
+	}
+
+	var from, to time.Time
+	if in.From != "" {
+		if from, err = time.ParseInLocation("2006-01-02", in.From, time.Local); err != nil {
+			out.Response = resp.NewResponse(http.StatusUnprocessableEntity, "", ErrDateFormat)
+			return
+		}
+		from = from.UTC()
+	}
+	if in.To != "" {
+		if to, err = time.ParseInLocation("2006-01-02", in.To, time.Local); err != nil {
+			out.Response = resp.NewResponse(http.StatusUnprocessableEntity, "", ErrDateFormat)
+			return
+		}
+		to = to.AddDate(0, 0, 1).UTC()
+	}
+
+	s, _, err := pagination.DecodeSIDCursor(in.Cursor)
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "decode sid cursor"))
+		return
+	}
+
+	fromId, _ := strconv.ParseUint(s, 10, 64)
+	nlimit, _ := strconv.ParseInt(in.Limit, 10, 64)
+	if nlimit <= 0 {
+		nlimit = 25
+	}
+
+	ms, err := d.AuditRepository.Query(ctx, in.Entity, in.ActorId, from, to, fromId, nlimit)
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "query audit events"))
+		return
+	}
+
+	mLen := len(ms)
+
+	var nextCursor string
+	if mLen != 0 {
+		m := ms[mLen-1]
+		nextCursor = pagination.EncodeSIDCursor(strconv.FormatUint(m.Id, 10), m.CreatedAt)
+	}
+
+	outEvents := make([]AuditEventOut, mLen)
+	for i, m := range ms {
+		outEvents[i] = AuditEventOut{
+			Id:        m.Id,
+			ActorId:   m.ActorId.String,
+			ActorName: m.ActorName.String,
+			Action:    m.Action,
+			Entity:    m.Entity,
+			EntityId:  m.EntityId,
+			Diff:      json.RawMessage(m.Diff),
+			PrevHash:  m.PrevHash,
+			Hash:      m.Hash,
+			CreatedAt: m.CreatedAt.Format(time.RFC3339),
+		}
+	}
+
+	out.Res = QueryAuditRes{
+		Cursor: nextCursor,
+		Events: outEvents,
+	}
+
+	return
+}
+
+type (
+	VerifyAuditRes struct {
+		IsValid bool  `json:"is_valid"`
+		Total   int64 `json:"total"`
+		// BrokenId is the first event that is not chained to the previous
+		// event or whose content was changed.
+		BrokenId uint64 `json:"broken_id"`
+	}
+	VerifyAuditOut struct {
+		resp.Response
+		Res VerifyAuditRes
+	}
+)
+
+// VerifyAudit walk the whole chain from the oldest event and recompute every
+// hash, it detect the changed and the removed events.
+func (d *AuditDeps) VerifyAudit(ctx context.Context) (out VerifyAuditOut) {
+	out.Response = resp.NewResponse(http.StatusOK, "", nil)
+	out.Res.IsValid = true
+
+	var afterId uint64
+	var prevHash string
+	for {
+		ms, err := d.AuditRepository.QueryChain(ctx, afterId, verifyBatch)
+		if err != nil {
+			out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "query audit chain"))
+			return
+		}
+
+		for _, m := range ms {
+			out.Res.Total++
+			if m.PrevHash != prevHash || m.ComputeHash() != m.Hash {
+				out.Res.IsValid = false
+				out.Res.BrokenId = m.Id
+				return
+			}
+
+			prevHash = m.Hash
+			afterId = m.Id
+		}
+
+		if len(ms) < verifyBatch {
+			return
+		}
+	}
+}
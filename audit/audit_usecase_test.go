@@ -0,0 +1,247 @@
+package audit_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	arbitary "github.com/PA-D3RPLA/d3if43-htt-uhomestay/arbitrary"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/audit"
+)
+
+const actorId = "6f2e8a3c-1b7d-4c5e-9a0f-2d3b4c5d6e7f"
+
+type memberSeed struct {
+	Name     string `json:"name"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+func seedEvents(t *testing.T) {
+	ctx := context.WithValue(context.Background(), arbitary.ActorX{}, actorId)
+
+	events := []audit.Event{
+		{
+			Action:   audit.ActionCreate,
+			Entity:   "member",
+			EntityId: "1",
+			After:    memberSeed{Name: "Anggota", Username: "anggota", Password: "rahasia"},
+		},
+		{
+			Action:   audit.ActionUpdate,
+			Entity:   "member",
+			EntityId: "1",
+			Before:   memberSeed{Name: "Anggota", Username: "anggota", Password: "rahasia"},
+			After:    memberSeed{Name: "Anggota Baru", Username: "anggota", Password: "rahasia"},
+		},
+		{
+			Action:   audit.ActionDelete,
+			Entity:   "cashflow",
+			EntityId: "2",
+			Before:   map[string]interface{}{"idr_amount": "10000"},
+		},
+	}
+
+	for _, e := range events {
+		if err := auditDeps.Record(ctx, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Event done by the system has no actor.
+	err := auditDeps.Record(context.Background(), audit.Event{
+		Action:   audit.ActionApprove,
+		Entity:   "payment",
+		EntityId: "3",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestQueryAudit(t *testing.T) {
+	err := ClearTables(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	seedEvents(t)
+
+	today := time.Now().Format("2006-01-02")
+	tomorrow := time.Now().AddDate(0, 0, 1).Format("2006-01-02")
+
+	testCases := []struct {
+		Name               string
+		ExpectedStatusCode int
+		ExpectedTotal      int
+		In                 audit.QueryAuditIn
+	}{
+		{
+			Name:               "Query Audit Success",
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedTotal:      4,
+			In:                 audit.QueryAuditIn{},
+		},
+		{
+			Name:               "Query Audit Success, Filter Entity",
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedTotal:      2,
+			In: audit.QueryAuditIn{
+				Entity: "member",
+			},
+		},
+		{
+			Name:               "Query Audit Success, Filter Actor",
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedTotal:      3,
+			In: audit.QueryAuditIn{
+				ActorId: actorId,
+			},
+		},
+		{
+			Name:               "Query Audit Success, Filter Date",
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedTotal:      4,
+			In: audit.QueryAuditIn{
+				From: today,
+				To:   today,
+			},
+		},
+		{
+			Name:               "Query Audit Success, No Event In Date",
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedTotal:      0,
+			In: audit.QueryAuditIn{
+				From: tomorrow,
+			},
+		},
+		{
+			Name:               "Query Audit Success, Limit",
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedTotal:      1,
+			In: audit.QueryAuditIn{
+				Limit: "1",
+			},
+		},
+		{
+			Name:               "Query Audit Fail, Invalid Actor",
+			ExpectedStatusCode: http.StatusUnprocessableEntity,
+			In: audit.QueryAuditIn{
+				ActorId: "not-uuid",
+			},
+		},
+		{
+			Name:               "Query Audit Fail, Invalid Date",
+			ExpectedStatusCode: http.StatusUnprocessableEntity,
+			In: audit.QueryAuditIn{
+				From: "17-10-2022",
+			},
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.Name, func(t *testing.T) {
+			res := auditDeps.QueryAudit(context.Background(), c.In)
+
+			if res.StatusCode != c.ExpectedStatusCode {
+				t.Logf("%#v", res)
+				t.Fatalf("Expected response code %d. Got %d\n", c.ExpectedStatusCode, res.StatusCode)
+			}
+
+			if len(res.Res.Events) != c.ExpectedTotal {
+				t.Fatalf("Expected %d events. Got %d\n", c.ExpectedTotal, len(res.Res.Events))
+			}
+		})
+	}
+
+	t.Run("Query Audit Success, Diff Of Changed Field", func(t *testing.T) {
+		res := auditDeps.QueryAudit(context.Background(), audit.QueryAuditIn{
+			Entity: "member",
+		})
+		if res.Error != nil {
+			t.Fatal(res.Error)
+		}
+
+		// The latest event come first.
+		update := res.Res.Events[0]
+		if update.Action != audit.ActionUpdate || update.ActorId != actorId {
+			t.Fatalf("Expected update by %s. Got %s by %s\n", actorId, update.Action, update.ActorId)
+		}
+
+		var diff struct {
+			Before map[string]interface{} `json:"before"`
+			After  map[string]interface{} `json:"after"`
+		}
+		if err := json.Unmarshal(update.Diff, &diff); err != nil {
+			t.Fatal(err)
+		}
+
+		if len(diff.After) != 1 || diff.After["name"] != "Anggota Baru" {
+			t.Fatalf("Expected only the changed name in the diff. Got %v\n", diff.After)
+		}
+
+		var created struct {
+			After map[string]interface{} `json:"after"`
+		}
+		if err := json.Unmarshal(res.Res.Events[1].Diff, &created); err != nil {
+			t.Fatal(err)
+		}
+
+		if created.After["password"] == "rahasia" {
+			t.Fatal("Expected the password to be hidden")
+		}
+	})
+}
+
+func TestVerifyAudit(t *testing.T) {
+	err := ClearTables(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	seedEvents(t)
+
+	res := auditDeps.VerifyAudit(context.Background())
+	if res.Error != nil {
+		t.Fatal(res.Error)
+	}
+	if !res.Res.IsValid || res.Res.Total != 4 {
+		t.Fatalf("Expected valid chain of 4 events. Got %#v\n", res.Res)
+	}
+
+	// The event can not be changed or removed.
+	_, err = db.Exec(context.Background(), `UPDATE audit_events SET entity_id = '99'`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.Exec(context.Background(), `DELETE FROM audit_events`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res = auditDeps.VerifyAudit(context.Background())
+	if !res.Res.IsValid || res.Res.Total != 4 {
+		t.Fatalf("Expected the events to be unchanged. Got %#v\n", res.Res)
+	}
+
+	// Event written around the chain break it.
+	var forgedId uint64
+	err = db.QueryRow(context.Background(), `
+		INSERT INTO audit_events (action, entity, entity_id, prev_hash, hash)
+		SELECT 'delete', 'cashflow', '5', hash, 'forged'
+		FROM audit_events
+		ORDER BY id DESC
+		LIMIT 1
+		RETURNING id
+	`).Scan(&forgedId)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res = auditDeps.VerifyAudit(context.Background())
+	if res.Res.IsValid || res.Res.BrokenId != forgedId {
+		t.Fatalf("Expected the chain broken at %d. Got %#v\n", forgedId, res.Res)
+	}
+}
@@ -0,0 +1,65 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/getsentry/sentry-go"
+)
+
+type (
+	ExceptionCapturer func(exception error)
+	MessageCapturer   func(message string)
+)
+
+// Event is the administrative change to be recorded, only the difference of
+// Before and After is kept. Before is nil on create and After is nil on delete.
+type Event struct {
+	Action   string
+	Entity   string
+	EntityId string
+	Before   interface{}
+	After    interface{}
+}
+
+// Recorder record the event in the transaction of the context, so the event is
+// rolled back along with the change.
+type Recorder func(ctx context.Context, e Event) error
+
+// Record run the recorder, the nil recorder record nothing.
+func (r Recorder) Record(ctx context.Context, e Event) error {
+	if r == nil {
+		return nil
+	}
+
+	return r(ctx, e)
+}
+
+type AuditDeps struct {
+	CaptureMessage  MessageCapturer
+	CaptureExeption ExceptionCapturer
+	AuditRepository *AuditRepository
+}
+
+func NewDeps(
+	captureMessage MessageCapturer,
+	captureExeption ExceptionCapturer,
+	auditRepository *AuditRepository,
+) *AuditDeps {
+	return &AuditDeps{
+		CaptureMessage:  captureMessage,
+		CaptureExeption: captureExeption,
+		AuditRepository: auditRepository,
+	}
+}
+
+func CaptureExeption(capture func(exception error) *sentry.EventID) ExceptionCapturer {
+	return func(exception error) {
+		capture(exception)
+	}
+}
+
+func CaptureMessage(capture func(message string) *sentry.EventID) MessageCapturer {
+	return func(message string) {
+		capture(message)
+	}
+}
@@ -0,0 +1,100 @@
+package audit
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// redacted replace the value of the secret fields, only the fact that they
+// changed is recorded.
+const redacted = "[disembunyikan]"
+
+var secretFields = map[string]bool{
+	"password": true,
+}
+
+// Diff return the JSON of the fields changed between before and after as
+// {"before": {...}, "after": {...}}. When one of them is not an object, such
+// as nil on create or delete, the other is kept whole.
+func Diff(before, after interface{}) (string, error) {
+	b, err := toMap(before)
+	if err != nil {
+		return "", err
+	}
+
+	a, err := toMap(after)
+	if err != nil {
+		return "", err
+	}
+
+	d := struct {
+		Before interface{} `json:"before"`
+		After  interface{} `json:"after"`
+	}{}
+
+	d.Before, d.After = before, after
+	if b != nil && a != nil {
+		cb := make(map[string]interface{})
+		ca := make(map[string]interface{})
+		for k, v := range b {
+			if w, ok := a[k]; !ok || !reflect.DeepEqual(v, w) {
+				cb[k] = v
+			}
+		}
+		for k, v := range a {
+			if w, ok := b[k]; !ok || !reflect.DeepEqual(v, w) {
+				ca[k] = v
+			}
+		}
+		d.Before, d.After = redact(cb), redact(ca)
+	} else {
+		if b != nil {
+			d.Before = redact(b)
+		}
+		if a != nil {
+			d.After = redact(a)
+		}
+	}
+
+	byt, err := json.Marshal(d)
+	if err != nil {
+		return "", err
+	}
+
+	return string(byt), nil
+}
+
+// toMap return the JSON object of v, nil when v is nil or not an object.
+func toMap(v interface{}) (map[string]interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	byt, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var m map[string]interface{}
+	if err = json.Unmarshal(byt, &m); err != nil {
+		// Not an object, it is kept whole.
+		return nil, nil
+	}
+
+	return m, nil
+}
+
+func redact(m map[string]interface{}) interface{} {
+	if m == nil {
+		return nil
+	}
+
+	for k := range m {
+		if secretFields[strings.ToLower(k)] {
+			m[k] = redacted
+		}
+	}
+
+	return m
+}
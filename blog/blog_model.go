@@ -5,6 +5,18 @@ import (
 	"time"
 )
 
+type BlogStatus string
+
+const (
+	BlogDraft BlogStatus = "draft"
+	// BlogScheduled is published by the publisher once the publish at time
+	// has come.
+	BlogScheduled BlogStatus = "scheduled"
+	BlogPublished BlogStatus = "published"
+	// BlogArchived is hidden from the public without being removed.
+	BlogArchived BlogStatus = "archived"
+)
+
 type BlogModel struct {
 	Id           uint64
 	Title        string
@@ -12,8 +24,27 @@ type BlogModel struct {
 	ThumbnailUrl string
 	ContentText  string
 	Slug         string
+	Status       BlogStatus
 	Content      map[string]interface{}
+	PublishAt    sql.NullTime
 	CreatedAt    time.Time
 	UpdatedAt    time.Time
 	DeletedAt    sql.NullTime
 }
+
+// IsVisible report whether the blog is shown to the public at the time, the
+// scheduled blog is shown once its time has come even before the publisher
+// run.
+func (m BlogModel) IsVisible(t time.Time) bool {
+	if m.Status != BlogPublished && m.Status != BlogScheduled {
+		return false
+	}
+
+	return m.PublishAt.Valid && !m.PublishAt.Time.After(wallClock(t))
+}
+
+// wallClock return the time as it is read back from the timestamp column,
+// which keep the wall clock without the zone.
+func wallClock(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+}
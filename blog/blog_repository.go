@@ -45,11 +45,13 @@ func (r *BlogRepository) Save(ctx context.Context, m BlogModel) (nm BlogModel, e
 			content,
 			content_text,
 			slug,
+			status,
+			publish_at,
 			created_at,
 			updated_at,
 			deleted_at
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		VALUES ($1, $2, $3, $4, $5, $6, $7::blogstatus, $8, $9, $10, $11)
 		RETURNING id
 	`
 
@@ -73,6 +75,8 @@ func (r *BlogRepository) Save(ctx context.Context, m BlogModel) (nm BlogModel, e
 		m.Content,
 		m.ContentText,
 		m.Slug,
+		m.Status,
+		m.PublishAt,
 		t,
 		t,
 		nil,
@@ -89,7 +93,25 @@ func (r *BlogRepository) Save(ctx context.Context, m BlogModel) (nm BlogModel, e
 	return m, nil
 }
 
-func (r *BlogRepository) Query(ctx context.Context, q string, id, limit int64) ([]BlogModel, error) {
+// visibleCond is the condition of the blogs returned by Query and CountBlog
+// with the placeholder of the status and the visible at.
+func visibleCond(status, visibleAt string) string {
+	return `(` + visibleAt + `::TIMESTAMP IS NULL OR (status IN ('published', 'scheduled') AND publish_at <= ` + visibleAt + `::TIMESTAMP))
+			AND (` + status + `::TEXT = '' OR status::TEXT = ` + status + `::TEXT)`
+}
+
+func nullTime(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+
+	return t
+}
+
+// Query return the blogs visible to the public at the visible at time, the
+// zero visible at return the blogs of the status, or of every status when it
+// is empty, for the preview.
+func (r *BlogRepository) Query(ctx context.Context, q, status string, visibleAt time.Time, id, limit int64) ([]BlogModel, error) {
 	fromId := "id > $1"
 	if id != 0 {
 		fromId = "id < $1"
@@ -116,6 +138,8 @@ func (r *BlogRepository) Query(ctx context.Context, q string, id, limit int64) (
 			content,
 			content_text,
 			slug,
+			status::TEXT AS status,
+			publish_at,
 			created_at,
 			updated_at,
 			deleted_at
@@ -123,6 +147,7 @@ func (r *BlogRepository) Query(ctx context.Context, q string, id, limit int64) (
 		WHERE deleted_at IS NULL
 			AND ` + fromId + `
 			AND ` + like + `
+			AND ` + visibleCond("$4", "$5") + `
 		ORDER BY ` + order + ` DESC
 		LIMIT $3
 	`
@@ -133,6 +158,8 @@ func (r *BlogRepository) Query(ctx context.Context, q string, id, limit int64) (
 		id,
 		q,
 		limit,
+		status,
+		nullTime(visibleAt),
 	)
 	defer rows.Close()
 
@@ -154,10 +181,13 @@ func (r *BlogRepository) FindUndeletedById(ctx context.Context, id uint64) (m Bl
 		SELECT
 			id,
 			title,
+			short_desc,
 			thumbnail_url,
 			content,
 			content_text,
 			slug,
+			status::TEXT AS status,
+			publish_at,
 			created_at,
 			updated_at,
 			deleted_at
@@ -200,9 +230,11 @@ func (r *BlogRepository) UpdateById(ctx context.Context, id uint64, m BlogModel)
 			content,
 			content_text,
 			thumbnail_url,
+			status,
+			publish_at,
 			updated_at
-		) = ($1, $2, $3, $4, $5, $6)
-		WHERE id = $7
+		) = ($1, $2, $3, $4, $5, $6::blogstatus, $7, $8)
+		WHERE id = $9
 	`
 
 	var exec BlogExecutor
@@ -224,6 +256,8 @@ func (r *BlogRepository) UpdateById(ctx context.Context, id uint64, m BlogModel)
 		m.Content,
 		m.ContentText,
 		m.ThumbnailUrl,
+		m.Status,
+		m.PublishAt,
 		t,
 		id,
 	)
@@ -293,11 +327,13 @@ func (r *BlogRepository) DelImgUrlCache(ctx context.Context) (err error) {
 	return nil
 }
 
-func (r *BlogRepository) CountBlog(ctx context.Context) (n int64, err error) {
+// CountBlog count the blogs the same way of Query.
+func (r *BlogRepository) CountBlog(ctx context.Context, status string, visibleAt time.Time) (n int64, err error) {
 	sqlQuery := `
 		SELECT COUNT(id) AS n
 		FROM blogs 
 		WHERE deleted_at IS NULL
+			AND ` + visibleCond("$1", "$2") + `
 	`
 
 	var queryRow BlogQuerierRow
@@ -311,6 +347,8 @@ func (r *BlogRepository) CountBlog(ctx context.Context) (n int64, err error) {
 	err = queryRow(
 		context.Background(),
 		sqlQuery,
+		status,
+		nullTime(visibleAt),
 	).Scan(&n)
 
 	if err != nil {
@@ -319,3 +357,96 @@ func (r *BlogRepository) CountBlog(ctx context.Context) (n int64, err error) {
 
 	return n, nil
 }
+
+// QueryDueScheduled return the id of the scheduled blogs which publish at time
+// has come, from the earliest.
+func (r *BlogRepository) QueryDueScheduled(ctx context.Context, t time.Time, limit int64) ([]uint64, error) {
+	sqlQuery := `
+		SELECT id
+		FROM blogs
+		WHERE deleted_at IS NULL
+			AND status = 'scheduled'
+			AND publish_at <= $1
+		ORDER BY publish_at, id
+		LIMIT $2
+	`
+
+	var query BlogQuerier
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		query = tx.Query
+	} else {
+		query = r.PostgreDb.Query
+	}
+
+	rows, err := query(
+		context.Background(),
+		sqlQuery,
+		t,
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []uint64
+	for rows.Next() {
+		var id uint64
+		if err = rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// PublishScheduled flip the scheduled blog to published, false is returned
+// when the blog is no longer scheduled.
+func (r *BlogRepository) PublishScheduled(ctx context.Context, id uint64) (bool, error) {
+	sqlQuery := `
+		UPDATE blogs
+		SET status = 'published', updated_at = $1
+		WHERE id = $2
+			AND deleted_at IS NULL
+			AND status = 'scheduled'
+	`
+
+	var exec BlogExecutor
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		exec = tx.Exec
+	} else {
+		exec = r.PostgreDb.Exec
+	}
+
+	tag, err := exec(
+		context.Background(),
+		sqlQuery,
+		time.Now(),
+		id,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+// Tx run fn in a new transaction, which is committed only when fn return no
+// error.
+func (r *BlogRepository) Tx(ctx context.Context, fn func(ctx context.Context) error) error {
+	tx, err := r.PostgreDb.Begin(context.Background())
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback(context.Background())
+
+	if err = fn(context.WithValue(ctx, arbitary.TrxX{}, tx)); err != nil {
+		return err
+	}
+
+	return tx.Commit(context.Background())
+}
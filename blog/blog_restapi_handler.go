@@ -88,3 +88,23 @@ func (d *BlogDeps) PostImage(w http.ResponseWriter, r *http.Request) {
 	}
 	out.HttpJSON(w, resp.NewHttpBody(out.Res))
 }
+
+func (d *BlogDeps) GetBlogsPreview(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query().Get("q")
+	status := r.URL.Query().Get("status")
+	cursor := r.URL.Query().Get("cursor")
+	out := d.QueryBlogPreview(r.Context(), q, status, cursor)
+	if out.Error != nil {
+		d.CaptureExeption(out.Error)
+	}
+	out.HttpJSON(w, resp.NewHttpBody(out.Res))
+}
+
+func (d *BlogDeps) GetBlogPreview(w http.ResponseWriter, r *http.Request) {
+	idParam := chi.URLParam(r, "id")
+	out := d.FindBlogPreviewById(r.Context(), idParam)
+	if out.Error != nil {
+		d.CaptureExeption(out.Error)
+	}
+	out.HttpJSON(w, resp.NewHttpBody(out.Res))
+}
@@ -2,21 +2,95 @@ package blog
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"net/http"
-	"path/filepath"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/audit"
 	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/httpdecode"
 	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/resp"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/revision"
 	"github.com/jackc/pgx/v4"
 	"github.com/pkg/errors"
 )
 
 var ErrBlogNotFound = errors.New("blog tidak ditemukan")
 
+// publication set the status and the publish at of the blog from the input,
+// the empty status keep the publication of the blog. The published blog
+// without publish at is published now, or keep its publish at when it was
+// already published.
+func publication(b BlogModel, status, publishAt string, now time.Time) (BlogModel, error) {
+	if status == "" {
+		return b, nil
+	}
+
+	var at sql.NullTime
+	if publishAt != "" {
+		t, err := time.Parse(time.RFC3339, publishAt)
+		if err != nil {
+			return BlogModel{}, ErrPublishAtFormat
+		}
+		at = sql.NullTime{Time: t.In(time.Local), Valid: true}
+	}
+
+	switch BlogStatus(status) {
+	case BlogDraft:
+		b.PublishAt = at
+	case BlogScheduled:
+		if !at.Time.After(now) {
+			return BlogModel{}, ErrPublishAtPast
+		}
+		b.PublishAt = at
+	case BlogPublished:
+		if at.Valid && at.Time.After(now) {
+			return BlogModel{}, ErrPublishAtFuture
+		}
+		wasPublished := b.Status == BlogPublished || b.Status == BlogArchived
+		switch {
+		case at.Valid:
+			b.PublishAt = at
+		case !wasPublished || !b.PublishAt.Valid:
+			b.PublishAt = sql.NullTime{Time: now, Valid: true}
+		}
+	}
+	b.Status = BlogStatus(status)
+
+	return b, nil
+}
+
+// isFirstPublished report whether the change publish the blog for the first
+// time, the published hooks are only run once.
+func isFirstPublished(before, after BlogModel) bool {
+	return after.Status == BlogPublished && (before.Status == BlogDraft || before.Status == BlogScheduled)
+}
+
+// blogContent is the versioned content of the blog kept in its revisions.
+type blogContent struct {
+	Title        string                 `json:"title"`
+	ShortDesc    string                 `json:"short_desc"`
+	ThumbnailUrl string                 `json:"thumbnail_url"`
+	Content      map[string]interface{} `json:"content"`
+}
+
+func blogRevision(b BlogModel) revision.Revision {
+	return revision.Revision{
+		Entity:   revision.Blog,
+		EntityId: b.Id,
+		Content: blogContent{
+			Title:        b.Title,
+			ShortDesc:    b.ShortDesc,
+			ThumbnailUrl: b.ThumbnailUrl,
+			Content:      b.Content,
+		},
+		ContentText: b.ContentText,
+	}
+}
+
 type BlogIn struct {
 	Title        string
 	ShortDesc    string
@@ -37,8 +111,13 @@ func (d *BlogDeps) BlogModelBuilder(ctx context.Context, in BlogIn) (bm BlogMode
 	thumbnailUrl := in.ThumbnailUrl
 	if s := strings.Split(thumbnailUrl, d.ImgCldTmpFolder); thumbnailUrl != "" && len(s) == 2 {
 
-		so := s[1]
-		i := d.ImgCldTmpFolder + strings.TrimSuffix(so, filepath.Ext(so))
+		so, err := url.PathUnescape(s[1])
+		if err != nil {
+			err = errors.Wrap(err, "unescape thumbnail url")
+			return BlogModel{}, err
+		}
+
+		i := d.ImgCldTmpFolder + so
 		to := d.ImgClgFolder + so
 
 		nurl, err := d.MoveFile(i, to)
@@ -113,6 +192,9 @@ type (
 		Content      string `json:"content"`
 		ContentText  string `json:"content_text"`
 		Slug         string `json:"slug"`
+		// Status is published when it is empty.
+		Status    string `json:"status"`
+		PublishAt string `json:"publish_at"`
 	}
 	AddBlogRes struct {
 		Id int64 `json:"id"`
@@ -132,17 +214,59 @@ func (d *BlogDeps) AddBlog(ctx context.Context, in AddBlogIn) (out AddBlogOut) {
 		return
 	}
 
-	blog, err := d.BlogModelBuilder(ctx, BlogIn(in))
+	if in.Status == "" {
+		in.Status = string(BlogPublished)
+	}
+
+	pub, err := publication(BlogModel{Status: BlogDraft}, in.Status, in.PublishAt, time.Now())
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusUnprocessableEntity, "", err)
+		return
+	}
+
+	blog, err := d.BlogModelBuilder(ctx, BlogIn{
+		Title:        in.Title,
+		ShortDesc:    in.ShortDesc,
+		ThumbnailUrl: in.ThumbnailUrl,
+		Content:      in.Content,
+		ContentText:  in.ContentText,
+		Slug:         in.Slug,
+	})
 	if err != nil {
 		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "blog model builder"))
 		return
 	}
+	blog.Status = pub.Status
+	blog.PublishAt = pub.PublishAt
 
 	if blog, err = d.BlogRepository.Save(ctx, blog); err != nil {
 		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "save blog"))
 		return
 	}
 
+	err = d.Audit.Record(ctx, audit.Event{
+		Action:   audit.ActionCreate,
+		Entity:   "blog",
+		EntityId: strconv.FormatUint(blog.Id, 10),
+		After:    blog,
+	})
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "record audit"))
+		return
+	}
+
+	if err = d.Revision.Record(ctx, blogRevision(blog)); err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "record revision"))
+		return
+	}
+
+	if blog.Status == BlogPublished {
+		if err = runBlogHooks(ctx, d.BlogHooks.OnPublished, blog.Id); err != nil {
+			out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "run blog published hooks"))
+			return
+		}
+	}
+
 	out.Res.Id = int64(blog.Id)
 
 	return
@@ -168,18 +292,20 @@ type (
 	}
 )
 
+// QueryBlog return the blogs visible to the public.
 func (d *BlogDeps) QueryBlog(ctx context.Context, q, cursor string) (out QueryBlogOut) {
 	var err error
 	out.Response = resp.NewResponse(http.StatusOK, "", nil)
 
-	blogNumber, err := d.BlogRepository.CountBlog(ctx)
+	now := time.Now()
+	blogNumber, err := d.BlogRepository.CountBlog(ctx, "", now)
 	if err != nil {
 		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "count blog"))
 		return
 	}
 
 	fromCursor, _ := strconv.ParseInt(cursor, 10, 64)
-	blogs, err := d.BlogRepository.Query(ctx, q, fromCursor, 25)
+	blogs, err := d.BlogRepository.Query(ctx, q, "", now, fromCursor, 25)
 	if err != nil {
 		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "query blogs"))
 		return
@@ -229,6 +355,8 @@ type (
 	}
 )
 
+// FindBlogById return the blog visible to the public, the other blog is not
+// found.
 func (d *BlogDeps) FindBlogById(ctx context.Context, pid string) (out FindBlogOut) {
 	var err error
 	out.Response = resp.NewResponse(http.StatusOK, "", nil)
@@ -249,6 +377,11 @@ func (d *BlogDeps) FindBlogById(ctx context.Context, pid string) (out FindBlogOu
 		return
 	}
 
+	if !blog.IsVisible(time.Now()) {
+		out.Response = resp.NewResponse(http.StatusNotFound, "", ErrBlogNotFound)
+		return
+	}
+
 	b := []byte("")
 	if blog.Content != nil && len(blog.Content) != 0 {
 		b, err = json.Marshal(blog.Content)
@@ -272,6 +405,146 @@ func (d *BlogDeps) FindBlogById(ctx context.Context, pid string) (out FindBlogOu
 	return
 }
 
+type (
+	BlogPreviewOut struct {
+		BlogOut
+		Status    string `json:"status"`
+		PublishAt string `json:"publish_at"`
+	}
+	QueryBlogPreviewRes struct {
+		Total  int64            `json:"total"`
+		Cursor int64            `json:"cursor"`
+		Blogs  []BlogPreviewOut `json:"blogs"`
+	}
+	QueryBlogPreviewOut struct {
+		resp.Response
+		Res QueryBlogPreviewRes
+	}
+)
+
+func formatPublishAt(t sql.NullTime) string {
+	if !t.Valid {
+		return ""
+	}
+
+	return t.Time.Format(time.RFC3339)
+}
+
+// QueryBlogPreview return the blogs of the status including the unpublished
+// one, or of every status when it is empty.
+func (d *BlogDeps) QueryBlogPreview(ctx context.Context, q, status, cursor string) (out QueryBlogPreviewOut) {
+	var err error
+	out.Response = resp.NewResponse(http.StatusOK, "", nil)
+
+	if status != "" && !isBlogStatus(status) {
+		out.Response = resp.NewResponse(http.StatusUnprocessableEntity, "", ErrBlogStatus)
+		return
+	}
+
+	blogNumber, err := d.BlogRepository.CountBlog(ctx, status, time.Time{})
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "count blog"))
+		return
+	}
+
+	fromCursor, _ := strconv.ParseInt(cursor, 10, 64)
+	blogs, err := d.BlogRepository.Query(ctx, q, status, time.Time{}, fromCursor, 25)
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "query blogs"))
+		return
+	}
+
+	bLen := len(blogs)
+	var nextCursor int64
+	if bLen != 0 {
+		nextCursor = int64(blogs[bLen-1].Id)
+	}
+
+	outBlogs := make([]BlogPreviewOut, bLen)
+	for i, b := range blogs {
+		outBlogs[i] = BlogPreviewOut{
+			BlogOut: BlogOut{
+				Id:           int64(b.Id),
+				Title:        b.Title,
+				ShortDesc:    b.ShortDesc,
+				Slug:         b.Slug,
+				ThumbnailUrl: b.ThumbnailUrl,
+				CreatedAt:    b.CreatedAt.Format("2006-01-02"),
+			},
+			Status:    string(b.Status),
+			PublishAt: formatPublishAt(b.PublishAt),
+		}
+	}
+
+	out.Res = QueryBlogPreviewRes{
+		Total:  blogNumber,
+		Cursor: nextCursor,
+		Blogs:  outBlogs,
+	}
+
+	return
+}
+
+type (
+	BlogPreviewRes struct {
+		BlogRes
+		Status    string `json:"status"`
+		PublishAt string `json:"publish_at"`
+	}
+	FindBlogPreviewOut struct {
+		resp.Response
+		Res BlogPreviewRes
+	}
+)
+
+// FindBlogPreviewById return the blog of any status.
+func (d *BlogDeps) FindBlogPreviewById(ctx context.Context, pid string) (out FindBlogPreviewOut) {
+	var err error
+	out.Response = resp.NewResponse(http.StatusOK, "", nil)
+
+	id, err := strconv.ParseUint(pid, 10, 64)
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusNotFound, "", ErrBlogNotFound)
+		return
+	}
+
+	blog, err := d.BlogRepository.FindUndeletedById(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		out.Response = resp.NewResponse(http.StatusNotFound, "", ErrBlogNotFound)
+		return
+	}
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "find blog by id"))
+		return
+	}
+
+	b := []byte("")
+	if blog.Content != nil && len(blog.Content) != 0 {
+		b, err = json.Marshal(blog.Content)
+		if err != nil {
+			out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "json marshal"))
+			return
+		}
+	}
+
+	out.Res = BlogPreviewRes{
+		BlogRes: BlogRes{
+			Id:           int64(blog.Id),
+			Title:        blog.Title,
+			ShortDesc:    blog.ShortDesc,
+			Content:      string(b),
+			ContentText:  blog.ContentText,
+			Slug:         blog.Slug,
+			ThumbnailUrl: blog.ThumbnailUrl,
+			CreatedAt:    blog.CreatedAt.Format("2006-01-02"),
+		},
+		Status:    string(blog.Status),
+		PublishAt: formatPublishAt(blog.PublishAt),
+	}
+
+	return
+}
+
 type (
 	EditBlogIn struct {
 		Title        string `json:"title"`
@@ -279,6 +552,9 @@ type (
 		ThumbnailUrl string `json:"thumbnail_url"`
 		Content      string `json:"content"`
 		ContentText  string `json:"content_text"`
+		// Status keep the status of the blog when it is empty.
+		Status    string `json:"status"`
+		PublishAt string `json:"publish_at"`
 	}
 	EditBlogRes struct {
 		Id int64 `json:"id"`
@@ -314,6 +590,12 @@ func (d *BlogDeps) EditBlog(ctx context.Context, pid string, in EditBlogIn) (out
 		return
 	}
 
+	pub, err := publication(blog, in.Status, in.PublishAt, time.Now())
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusUnprocessableEntity, "", err)
+		return
+	}
+
 	nb, err := d.BlogModelBuilder(ctx, BlogIn{
 		Title:        in.Title,
 		ShortDesc:    in.ShortDesc,
@@ -326,16 +608,44 @@ func (d *BlogDeps) EditBlog(ctx context.Context, pid string, in EditBlogIn) (out
 		return
 	}
 
+	before := blog
 	blog.Content = nb.Content
+	blog.ContentText = nb.ContentText
 	blog.Title = nb.Title
 	blog.ShortDesc = nb.ShortDesc
 	blog.ThumbnailUrl = nb.ThumbnailUrl
+	blog.Status = pub.Status
+	blog.PublishAt = pub.PublishAt
 
 	if err = d.BlogRepository.UpdateById(ctx, id, blog); err != nil {
 		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "update position by id"))
 		return
 	}
 
+	err = d.Audit.Record(ctx, audit.Event{
+		Action:   audit.ActionUpdate,
+		Entity:   "blog",
+		EntityId: strconv.FormatUint(id, 10),
+		Before:   before,
+		After:    blog,
+	})
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "record audit"))
+		return
+	}
+
+	if err = d.Revision.Record(ctx, blogRevision(blog)); err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "record revision"))
+		return
+	}
+
+	if isFirstPublished(before, blog) {
+		if err = runBlogHooks(ctx, d.BlogHooks.OnPublished, blog.Id); err != nil {
+			out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "run blog published hooks"))
+			return
+		}
+	}
+
 	out.Res.Id = int64(id)
 
 	return
@@ -361,7 +671,7 @@ func (d *BlogDeps) RemoveBlog(ctx context.Context, pid string) (out RemoveBlogOu
 		return
 	}
 
-	_, err = d.BlogRepository.FindUndeletedById(ctx, id)
+	blog, err := d.BlogRepository.FindUndeletedById(ctx, id)
 	if errors.Is(err, pgx.ErrNoRows) {
 		out.Response = resp.NewResponse(http.StatusNotFound, "", ErrBlogNotFound)
 		return
@@ -376,6 +686,17 @@ func (d *BlogDeps) RemoveBlog(ctx context.Context, pid string) (out RemoveBlogOu
 		return
 	}
 
+	err = d.Audit.Record(ctx, audit.Event{
+		Action:   audit.ActionDelete,
+		Entity:   "blog",
+		EntityId: strconv.FormatUint(id, 10),
+		Before:   blog,
+	})
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "record audit"))
+		return
+	}
+
 	out.Res.Id = int64(id)
 
 	return
@@ -428,3 +749,41 @@ func (d *BlogDeps) UploadImg(ctx context.Context, in UploadImgIn) (out UploadImg
 
 	return
 }
+
+// RestoreBlog bring the blog back to the content of the revision, the slug and
+// the publication are kept.
+func (d *BlogDeps) RestoreBlog(ctx context.Context, rev revision.RevisionModel) error {
+	var c blogContent
+	if err := rev.Decode(&c); err != nil {
+		return errors.Wrap(err, "decode revision")
+	}
+
+	blog, err := d.BlogRepository.FindUndeletedById(ctx, rev.EntityId)
+	if err != nil {
+		return err
+	}
+
+	before := blog
+	blog.Title = c.Title
+	blog.ShortDesc = c.ShortDesc
+	blog.ThumbnailUrl = c.ThumbnailUrl
+	blog.Content = c.Content
+	blog.ContentText = rev.ContentText
+
+	if err = d.BlogRepository.UpdateById(ctx, blog.Id, blog); err != nil {
+		return errors.Wrap(err, "update blog by id")
+	}
+
+	err = d.Audit.Record(ctx, audit.Event{
+		Action:   audit.ActionUpdate,
+		Entity:   "blog",
+		EntityId: strconv.FormatUint(blog.Id, 10),
+		Before:   before,
+		After:    blog,
+	})
+	if err != nil {
+		return errors.Wrap(err, "record audit")
+	}
+
+	return nil
+}
@@ -2,15 +2,18 @@ package blog_test
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"net/http"
 	"os"
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/blog"
 	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/httpdecode"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/revision"
 )
 
 func TestAddBlog(t *testing.T) {
@@ -219,6 +222,75 @@ func TestAddBlog(t *testing.T) {
 				ContentText:  "test",
 			},
 		},
+		{
+			Name:               "Add Blog Draft Success",
+			ExpectedStatusCode: http.StatusCreated,
+			init:               func() {},
+			In: blog.AddBlogIn{
+				Title:   "Title",
+				Slug:    "slug",
+				Content: `{"test": "test"}`,
+				Status:  string(blog.BlogDraft),
+			},
+		},
+		{
+			Name:               "Add Blog Scheduled Success",
+			ExpectedStatusCode: http.StatusCreated,
+			init:               func() {},
+			In: blog.AddBlogIn{
+				Title:     "Title",
+				Slug:      "slug",
+				Content:   `{"test": "test"}`,
+				Status:    string(blog.BlogScheduled),
+				PublishAt: time.Now().Add(time.Hour).Format(time.RFC3339),
+			},
+		},
+		{
+			Name:               "Add Blog Scheduled Failed, Past Publish At",
+			ExpectedStatusCode: http.StatusUnprocessableEntity,
+			init:               func() {},
+			In: blog.AddBlogIn{
+				Title:     "Title",
+				Slug:      "slug",
+				Content:   `{"test": "test"}`,
+				Status:    string(blog.BlogScheduled),
+				PublishAt: time.Now().Add(-time.Hour).Format(time.RFC3339),
+			},
+		},
+		{
+			Name:               "Add Blog Scheduled Failed, No Publish At",
+			ExpectedStatusCode: http.StatusUnprocessableEntity,
+			init:               func() {},
+			In: blog.AddBlogIn{
+				Title:   "Title",
+				Slug:    "slug",
+				Content: `{"test": "test"}`,
+				Status:  string(blog.BlogScheduled),
+			},
+		},
+		{
+			Name:               "Add Blog Published Failed, Future Publish At",
+			ExpectedStatusCode: http.StatusUnprocessableEntity,
+			init:               func() {},
+			In: blog.AddBlogIn{
+				Title:     "Title",
+				Slug:      "slug",
+				Content:   `{"test": "test"}`,
+				Status:    string(blog.BlogPublished),
+				PublishAt: time.Now().Add(time.Hour).Format(time.RFC3339),
+			},
+		},
+		{
+			Name:               "Add Blog Failed, Invalid Status",
+			ExpectedStatusCode: http.StatusUnprocessableEntity,
+			init:               func() {},
+			In: blog.AddBlogIn{
+				Title:   "Title",
+				Slug:    "slug",
+				Content: `{"test": "test"}`,
+				Status:  "hidden",
+			},
+		},
 	}
 
 	for _, c := range testCases {
@@ -569,6 +641,290 @@ func TestRemoveBlog(t *testing.T) {
 	}
 }
 
+func TestBlogRevision(t *testing.T) {
+	err := ClearTables(postgrePool)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	add := blogDeps.AddBlog(context.Background(), blog.AddBlogIn{
+		Title:       "Title",
+		ShortDesc:   "Short Desc",
+		Slug:        "slug",
+		Content:     `{"test": "hi"}`,
+		ContentText: "hi",
+	})
+	if add.Error != nil {
+		t.Fatal(add.Error)
+	}
+
+	pid := strconv.FormatInt(add.Res.Id, 10)
+
+	edit := blogDeps.EditBlog(context.Background(), pid, blog.EditBlogIn{
+		Title:       "New Title",
+		ShortDesc:   "Short Desc",
+		Content:     `{"test": "hello"}`,
+		ContentText: "hello",
+	})
+	if edit.Error != nil {
+		t.Fatal(edit.Error)
+	}
+
+	revs := revisionDeps.QueryRevision(context.Background(), revision.Blog, pid, "", "")
+	if revs.Error != nil {
+		t.Fatal(revs.Error)
+	}
+
+	if len(revs.Res.Revisions) != 2 {
+		t.Fatalf("Expected %d revisions. Got %d\n", 2, len(revs.Res.Revisions))
+	}
+
+	first := strconv.FormatUint(revs.Res.Revisions[1].Id, 10)
+	restore := revisionDeps.RestoreRevision(context.Background(), revision.Blog, pid, first)
+	if restore.Error != nil {
+		t.Fatal(restore.Error)
+	}
+
+	res := blogDeps.FindBlogById(context.Background(), pid)
+	if res.Error != nil {
+		t.Fatal(res.Error)
+	}
+
+	if res.Res.Title != "Title" || res.Res.ContentText != "hi" {
+		t.Fatalf("Expected restored title %s. Got %s\n", "Title", res.Res.Title)
+	}
+
+	t.Run("Restore Blog Fail, Blog Removed", func(t *testing.T) {
+		if out := blogDeps.RemoveBlog(context.Background(), pid); out.Error != nil {
+			t.Fatal(out.Error)
+		}
+
+		res := revisionDeps.RestoreRevision(context.Background(), revision.Blog, pid, first)
+		if res.StatusCode != http.StatusNotFound {
+			t.Fatalf("Expected response code %d. Got %d\n", http.StatusNotFound, res.StatusCode)
+		}
+	})
+}
+
+// seedPublication add a blog of each publication state, the due scheduled
+// blog is saved directly as it can't be added with past publish at.
+func seedPublication(t *testing.T) (published, draft, scheduled, due uint64) {
+	ids := make([]uint64, 3)
+	for i, in := range []blog.AddBlogIn{
+		{Title: "Published", Content: `{"test": "hi"}`},
+		{Title: "Draft", Content: `{"test": "hi"}`, Status: string(blog.BlogDraft)},
+		{
+			Title:     "Scheduled",
+			Content:   `{"test": "hi"}`,
+			Status:    string(blog.BlogScheduled),
+			PublishAt: time.Now().Add(time.Hour).Format(time.RFC3339),
+		},
+	} {
+		out := blogDeps.AddBlog(context.Background(), in)
+		if out.Error != nil {
+			t.Fatal(out.Error)
+		}
+		ids[i] = uint64(out.Res.Id)
+	}
+
+	m := blogSeed
+	m.Status = blog.BlogScheduled
+	m.PublishAt = sql.NullTime{Time: time.Now().Add(-time.Minute), Valid: true}
+	m, err := blogRepository.Save(context.Background(), m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return ids[0], ids[1], ids[2], m.Id
+}
+
+func TestBlogPublication(t *testing.T) {
+	err := ClearTables(postgrePool)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	publishedIds = nil
+	published, draft, scheduled, due := seedPublication(t)
+
+	if len(publishedIds) != 1 || publishedIds[0] != published {
+		t.Fatalf("Expected published hooks of %d. Got %v\n", published, publishedIds)
+	}
+
+	t.Run("Query Blog Success, Only Visible", func(t *testing.T) {
+		res := blogDeps.QueryBlog(context.Background(), "", "")
+		if res.Error != nil {
+			t.Fatal(res.Error)
+		}
+
+		if res.Res.Total != 2 || len(res.Res.Blogs) != 2 {
+			t.Fatalf("Expected %d blogs. Got total %d of %d\n", 2, res.Res.Total, len(res.Res.Blogs))
+		}
+	})
+
+	findCases := []struct {
+		Name               string
+		ExpectedStatusCode int
+		Id                 uint64
+	}{
+		{
+			Name:               "Find Blog By Id Success, Published",
+			ExpectedStatusCode: http.StatusOK,
+			Id:                 published,
+		},
+		{
+			Name:               "Find Blog By Id Success, Scheduled Time Has Come",
+			ExpectedStatusCode: http.StatusOK,
+			Id:                 due,
+		},
+		{
+			Name:               "Find Blog By Id Fail, Draft",
+			ExpectedStatusCode: http.StatusNotFound,
+			Id:                 draft,
+		},
+		{
+			Name:               "Find Blog By Id Fail, Scheduled",
+			ExpectedStatusCode: http.StatusNotFound,
+			Id:                 scheduled,
+		},
+	}
+
+	for _, c := range findCases {
+		t.Run(c.Name, func(t *testing.T) {
+			res := blogDeps.FindBlogById(context.Background(), strconv.FormatUint(c.Id, 10))
+
+			if res.StatusCode != c.ExpectedStatusCode {
+				t.Logf("%#v", res)
+				t.Fatalf("Expected response code %d. Got %d\n", c.ExpectedStatusCode, res.StatusCode)
+			}
+		})
+	}
+
+	previewCases := []struct {
+		Name               string
+		ExpectedStatusCode int
+		ExpectedTotal      int64
+		Status             string
+	}{
+		{
+			Name:               "Query Blog Preview Success",
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedTotal:      4,
+		},
+		{
+			Name:               "Query Blog Preview Success, Draft",
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedTotal:      1,
+			Status:             string(blog.BlogDraft),
+		},
+		{
+			Name:               "Query Blog Preview Success, Scheduled",
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedTotal:      2,
+			Status:             string(blog.BlogScheduled),
+		},
+		{
+			Name:               "Query Blog Preview Fail, Invalid Status",
+			ExpectedStatusCode: http.StatusUnprocessableEntity,
+			Status:             "hidden",
+		},
+	}
+
+	for _, c := range previewCases {
+		t.Run(c.Name, func(t *testing.T) {
+			res := blogDeps.QueryBlogPreview(context.Background(), "", c.Status, "")
+
+			if res.StatusCode != c.ExpectedStatusCode {
+				t.Logf("%#v", res)
+				t.Fatalf("Expected response code %d. Got %d\n", c.ExpectedStatusCode, res.StatusCode)
+			}
+
+			if res.Res.Total != c.ExpectedTotal {
+				t.Fatalf("Expected %d blogs. Got %d\n", c.ExpectedTotal, res.Res.Total)
+			}
+		})
+	}
+
+	t.Run("Find Blog Preview Success, Draft", func(t *testing.T) {
+		res := blogDeps.FindBlogPreviewById(context.Background(), strconv.FormatUint(draft, 10))
+		if res.Error != nil {
+			t.Fatal(res.Error)
+		}
+
+		if res.Res.Status != string(blog.BlogDraft) {
+			t.Fatalf("Expected status %s. Got %s\n", blog.BlogDraft, res.Res.Status)
+		}
+	})
+
+	t.Run("Edit Blog Success, Publish Draft Run Hooks Once", func(t *testing.T) {
+		publishedIds = nil
+		pid := strconv.FormatUint(draft, 10)
+		in := blog.EditBlogIn{
+			Title:   "Draft",
+			Content: `{"test": "hi"}`,
+			Status:  string(blog.BlogPublished),
+		}
+
+		for i := 0; i < 2; i++ {
+			if res := blogDeps.EditBlog(context.Background(), pid, in); res.Error != nil {
+				t.Fatal(res.Error)
+			}
+		}
+
+		if len(publishedIds) != 1 || publishedIds[0] != draft {
+			t.Fatalf("Expected published hooks of %d. Got %v\n", draft, publishedIds)
+		}
+
+		if res := blogDeps.FindBlogById(context.Background(), pid); res.Error != nil {
+			t.Fatal(res.Error)
+		}
+	})
+}
+
+func TestPublishDueBlogs(t *testing.T) {
+	err := ClearTables(postgrePool)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, scheduled, due := seedPublication(t)
+	publishedIds = nil
+
+	res := blogDeps.PublishDueBlogs(context.Background(), time.Now())
+	if res.Error != nil {
+		t.Fatal(res.Error)
+	}
+
+	if res.Res.Due != 1 || res.Res.Published != 1 {
+		t.Fatalf("Expected %d published. Got %#v\n", 1, res.Res)
+	}
+
+	if len(publishedIds) != 1 || publishedIds[0] != due {
+		t.Fatalf("Expected published hooks of %d. Got %v\n", due, publishedIds)
+	}
+
+	preview := blogDeps.FindBlogPreviewById(context.Background(), strconv.FormatUint(due, 10))
+	if preview.Res.Status != string(blog.BlogPublished) {
+		t.Fatalf("Expected status %s. Got %s\n", blog.BlogPublished, preview.Res.Status)
+	}
+
+	preview = blogDeps.FindBlogPreviewById(context.Background(), strconv.FormatUint(scheduled, 10))
+	if preview.Res.Status != string(blog.BlogScheduled) {
+		t.Fatalf("Expected status %s. Got %s\n", blog.BlogScheduled, preview.Res.Status)
+	}
+
+	t.Run("Publish Due Blogs Success, Nothing Due", func(t *testing.T) {
+		res := blogDeps.PublishDueBlogs(context.Background(), time.Now())
+		if res.Error != nil {
+			t.Fatal(res.Error)
+		}
+
+		if res.Res.Due != 0 || len(publishedIds) != 1 {
+			t.Fatalf("Expected nothing published. Got %#v\n", res.Res)
+		}
+	})
+}
+
 func TestUploadImg(t *testing.T) {
 	err := ClearRedis(redisClient)
 	if err != nil {
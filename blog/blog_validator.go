@@ -1,6 +1,7 @@
 package blog
 
 import (
+	"time"
 	"unicode/utf8"
 
 	"github.com/pkg/errors"
@@ -11,8 +12,44 @@ var (
 	ErrMaxTitle     = errors.New("judul tidak dapat lebih dari 200 karakter")
 	ErrMaxShortDesc = errors.New("deskripsi singkat tidak dapat lebih dari 200 karakter")
 	ErrMaxSlug      = errors.New("slug tidak dapat lebih dari 200 karakter")
+	// ErrBlogStatus is the status other than draft, scheduled, published and
+	// archived.
+	ErrBlogStatus        = errors.New("status artikel tidak valid")
+	ErrPublishAtFormat   = errors.New("format tanggal terbit tidak valid")
+	ErrPublishAtRequired = errors.New("tanggal terbit wajib diisi untuk artikel terjadwal")
+	ErrPublishAtPast     = errors.New("tanggal terbit artikel terjadwal harus setelah waktu sekarang")
+	ErrPublishAtFuture   = errors.New("tanggal terbit artikel yang diterbitkan tidak dapat setelah waktu sekarang, gunakan status terjadwal")
 )
 
+func isBlogStatus(s string) bool {
+	switch BlogStatus(s) {
+	case BlogDraft, BlogScheduled, BlogPublished, BlogArchived:
+		return true
+	}
+
+	return false
+}
+
+// validatePublication validate the status and the publish at of the input, the
+// empty status keep the status of the blog.
+func validatePublication(status, publishAt string) error {
+	if status != "" && !isBlogStatus(status) {
+		return ErrBlogStatus
+	}
+
+	if publishAt != "" {
+		if _, err := time.Parse(time.RFC3339, publishAt); err != nil {
+			return ErrPublishAtFormat
+		}
+	}
+
+	if BlogStatus(status) == BlogScheduled && publishAt == "" {
+		return ErrPublishAtRequired
+	}
+
+	return nil
+}
+
 func ValidateAddBlogIn(i AddBlogIn) error {
 	g := new(errgroup.Group)
 	g.Go(func() error {
@@ -33,6 +70,9 @@ func ValidateAddBlogIn(i AddBlogIn) error {
 		}
 		return nil
 	})
+	g.Go(func() error {
+		return validatePublication(i.Status, i.PublishAt)
+	})
 	if err := g.Wait(); err != nil {
 		return err
 	}
@@ -53,6 +93,9 @@ func ValidateEditBlogIn(i EditBlogIn) error {
 		}
 		return nil
 	})
+	g.Go(func() error {
+		return validatePublication(i.Status, i.PublishAt)
+	})
 	if err := g.Wait(); err != nil {
 		return err
 	}
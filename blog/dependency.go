@@ -3,8 +3,11 @@ package blog
 import (
 	"context"
 	"io"
+	"path"
 
-	"github.com/cloudinary/cloudinary-go/api/uploader"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/audit"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/revision"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/storage"
 	"github.com/getsentry/sentry-go"
 )
 
@@ -15,6 +18,28 @@ type (
 	MessageCapturer   func(message string)
 )
 
+// BlogHook is run in the transaction of the blog change, so the change is
+// rolled back when the hook fail.
+type BlogHook func(ctx context.Context, id uint64) error
+
+// BlogHooks let other package follow the blog without the blog package
+// importing them.
+type BlogHooks struct {
+	// OnPublished is run once when the blog is first published, either by
+	// the editor or by the publisher of the scheduled blog.
+	OnPublished []BlogHook
+}
+
+func runBlogHooks(ctx context.Context, hooks []BlogHook, id uint64) error {
+	for _, h := range hooks {
+		if err := h(ctx, id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 type BlogDeps struct {
 	ImgCldTmpFolder string
 	ImgClgFolder    string
@@ -22,6 +47,9 @@ type BlogDeps struct {
 	CaptureExeption ExceptionCapturer
 	MoveFile        FileMover
 	Upload          FileUploader
+	BlogHooks       BlogHooks
+	Audit           audit.Recorder
+	Revision        revision.Recorder
 	BlogRepository  *BlogRepository
 }
 
@@ -32,6 +60,9 @@ func NewDeps(
 	captureExeption ExceptionCapturer,
 	moveFile FileMover,
 	upload FileUploader,
+	blogHooks BlogHooks,
+	auditRecorder audit.Recorder,
+	revisionRecorder revision.Recorder,
 	blogRepository *BlogRepository,
 ) *BlogDeps {
 	return &BlogDeps{
@@ -41,37 +72,27 @@ func NewDeps(
 		CaptureExeption: captureExeption,
 		MoveFile:        moveFile,
 		Upload:          upload,
+		BlogHooks:       blogHooks,
+		Audit:           auditRecorder,
+		Revision:        revisionRecorder,
 		BlogRepository:  blogRepository,
 	}
 }
 
-func FileUpload(uploadParams uploader.UploadParams, upload func(ctx context.Context, file interface{}, uploadParams uploader.UploadParams) (*uploader.UploadResult, error)) FileUploader {
-	return func(filename string, file io.Reader) (url string, id string, err error) {
-		ctx := context.Background()
-		uploadParams.PublicID = filename
-
-		resp, err := upload(ctx, file, uploadParams)
-		if err != nil {
+func FileUpload(store storage.Storage, folder string) FileUploader {
+	return func(filename string, file io.Reader) (url string, key string, err error) {
+		key = path.Join(folder, filename)
+		if url, err = store.Put(context.Background(), key, file); err != nil {
 			return "", "", err
 		}
 
-		return resp.SecureURL, resp.PublicID, nil
+		return url, key, nil
 	}
 }
 
-func FileMove(move func(ctx context.Context, params uploader.RenameParams) (*uploader.RenameResult, error)) FileMover {
+func FileMove(store storage.Storage) FileMover {
 	return func(from, to string) (url string, err error) {
-		ctx := context.Background()
-
-		resp, err := move(ctx, uploader.RenameParams{
-			FromPublicID: from,
-			ToPublicID:   to,
-		})
-		if err != nil {
-			return "", err
-		}
-
-		return resp.SecureURL, nil
+		return store.Move(context.Background(), from, to)
 	}
 }
 
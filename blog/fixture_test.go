@@ -2,6 +2,7 @@ package blog_test
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"io"
 	"log"
@@ -10,6 +11,7 @@ import (
 	"time"
 
 	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/blog"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/revision"
 	"github.com/go-redis/redis/v8"
 	"github.com/jackc/pgx/v4/pgxpool"
 	"github.com/ory/dockertest/v3"
@@ -21,6 +23,7 @@ var (
 	redisClient    *redis.Client
 	blogRepository *blog.BlogRepository
 	blogDeps       *blog.BlogDeps
+	revisionDeps   *revision.RevisionDeps
 	fileName       = "images.jpeg"
 	fileDir        = "./fixture/" + fileName
 	imgTmpFolder   = "blabla"
@@ -34,9 +37,13 @@ var (
 			"test": "hi",
 		},
 		ContentText: "hi",
+		Status:      blog.BlogPublished,
+		PublishAt:   sql.NullTime{Time: time.Now(), Valid: true},
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
 	}
+	// publishedIds keep the blogs given to the published hooks.
+	publishedIds []uint64
 )
 
 var (
@@ -91,6 +98,7 @@ func ClearTables(conn *pgxpool.Pool) error {
 	// This should be in order of which table truncate first before the other
 	queries := []string{
 		`TRUNCATE blogs CASCADE`,
+		`TRUNCATE revisions`,
 	}
 
 	for _, v := range queries {
@@ -188,6 +196,12 @@ func TestMain(m *testing.M) {
 		log.Fatalf("Could not connect to docker: %s", err)
 	}
 
+	revisionDeps = revision.NewDeps(
+		func(string) {},
+		func(error) {},
+		revision.NewRevisionRepository(postgrePool),
+	)
+
 	blogRepository = blog.NewRepository("imgchc", redisClient, postgrePool)
 	blogDeps = blog.NewDeps(
 		imgFolder,
@@ -196,8 +210,19 @@ func TestMain(m *testing.M) {
 		captureException,
 		moveFile,
 		upload,
+		blog.BlogHooks{
+			OnPublished: []blog.BlogHook{
+				func(ctx context.Context, id uint64) error {
+					publishedIds = append(publishedIds, id)
+					return nil
+				},
+			},
+		},
+		nil,
+		revisionDeps.RecordRevision,
 		blogRepository,
 	)
+	revisionDeps.Restorers[revision.Blog] = blogDeps.RestoreBlog
 
 	LoadTables(postgrePool)
 
@@ -0,0 +1,120 @@
+package blog
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/audit"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/resp"
+	"github.com/pkg/errors"
+)
+
+const publishBatchSize = 50
+
+type (
+	PublishScheduledRes struct {
+		Due       int `json:"due"`
+		Published int `json:"published"`
+		Failed    int `json:"failed"`
+	}
+	PublishScheduledOut struct {
+		resp.Response
+		Res PublishScheduledRes
+	}
+)
+
+// PublishDueBlogs publish the scheduled blogs which publish at time has come.
+// Each blog is published with its published hooks in its own transaction, so
+// the failed one is retried on the next run without holding back the others.
+func (d *BlogDeps) PublishDueBlogs(ctx context.Context, now time.Time) (out PublishScheduledOut) {
+	out.Response = resp.NewResponse(http.StatusOK, "", nil)
+
+	ids, err := d.BlogRepository.QueryDueScheduled(ctx, now, publishBatchSize)
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "query due scheduled blogs"))
+		return
+	}
+	out.Res.Due = len(ids)
+
+	for _, id := range ids {
+		var published bool
+		err = d.BlogRepository.Tx(ctx, func(ctx context.Context) error {
+			var err error
+			published, err = d.BlogRepository.PublishScheduled(ctx, id)
+			if err != nil {
+				return errors.Wrap(err, "publish scheduled blog")
+			}
+
+			// The blog has been published by another run or changed since it
+			// was queried.
+			if !published {
+				return nil
+			}
+
+			err = d.Audit.Record(ctx, audit.Event{
+				Action:   "publish",
+				Entity:   "blog",
+				EntityId: strconv.FormatUint(id, 10),
+				Before:   map[string]interface{}{"status": BlogScheduled},
+				After:    map[string]interface{}{"status": BlogPublished},
+			})
+			if err != nil {
+				return errors.Wrap(err, "record audit")
+			}
+
+			if err = runBlogHooks(ctx, d.BlogHooks.OnPublished, id); err != nil {
+				return errors.Wrap(err, "run blog published hooks")
+			}
+
+			return nil
+		})
+		if err != nil {
+			d.CaptureExeption(errors.Wrapf(err, "publish blog %d", id))
+			out.Res.Failed++
+			continue
+		}
+
+		if published {
+			out.Res.Published++
+		}
+	}
+
+	return
+}
+
+// RunPublisher publish the due scheduled blogs every interval until the
+// context is done.
+func (d *BlogDeps) RunPublisher(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		for {
+			out := d.PublishDueBlogs(ctx, time.Now())
+			if out.Error != nil {
+				d.CaptureExeption(out.Error)
+				break
+			}
+
+			if out.Res.Published != 0 || out.Res.Failed != 0 {
+				log.Printf("blog publisher: due %d, published %d, failed %d",
+					out.Res.Due, out.Res.Published, out.Res.Failed)
+			}
+
+			// The failed blogs stay scheduled, so they wait for the next
+			// interval instead of being queried again right away.
+			if out.Res.Due < publishBatchSize || out.Res.Failed != 0 {
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package cashflow
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/money"
+)
+
+type AccountModel struct {
+	Id        uint64
+	Name      string
+	IsDefault bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt sql.NullTime
+}
+
+type AccountBalanceViewModel struct {
+	Id        uint64
+	Name      string
+	IsDefault bool
+	Balance   money.IDR
+}
@@ -0,0 +1,244 @@
+package cashflow
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	arbitary "github.com/PA-D3RPLA/d3if43-htt-uhomestay/arbitrary"
+
+	"github.com/georgysavva/scany/pgxscan"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+type AccountRepository struct {
+	PostgreDb *pgxpool.Pool
+}
+
+func NewAccountRepository(postgreDb *pgxpool.Pool) *AccountRepository {
+	return &AccountRepository{
+		PostgreDb: postgreDb,
+	}
+}
+
+type (
+	AccountExecutor   func(ctx context.Context, sql string, arguments ...interface{}) (commandTag pgconn.CommandTag, err error)
+	AccountQuerierRow func(ctx context.Context, sql string, args ...interface{}) pgx.Row
+	AccountQuerier    func(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+)
+
+func (r *AccountRepository) Save(ctx context.Context, m AccountModel) (nm AccountModel, err error) {
+	sqlQuery := `
+		INSERT INTO cashflow_accounts (
+			name,
+			is_default,
+			created_at,
+			updated_at,
+			deleted_at
+		)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`
+
+	var queryRow AccountQuerierRow
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		queryRow = tx.QueryRow
+	} else {
+		queryRow = r.PostgreDb.QueryRow
+	}
+
+	var lastInsertId uint64
+	t := time.Now()
+
+	err = queryRow(
+		context.Background(),
+		sqlQuery,
+		m.Name,
+		false,
+		t,
+		t,
+		nil,
+	).Scan(&lastInsertId)
+
+	if err != nil {
+		return AccountModel{}, err
+	}
+
+	m.Id = lastInsertId
+	m.CreatedAt = t
+	m.UpdatedAt = t
+
+	return m, nil
+}
+
+func (r *AccountRepository) UpdateById(ctx context.Context, id uint64, m AccountModel) error {
+	sqlQuery := `
+		UPDATE cashflow_accounts SET (
+			name,
+			updated_at
+		) = ($1, $2)
+		WHERE id = $3
+	`
+
+	var exec AccountExecutor
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		exec = tx.Exec
+	} else {
+		exec = r.PostgreDb.Exec
+	}
+
+	_, err := exec(
+		context.Background(),
+		sqlQuery,
+		m.Name,
+		time.Now(),
+		id,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *AccountRepository) FindById(ctx context.Context, id uint64) (m AccountModel, err error) {
+	querystr := `
+		SELECT
+			id,
+			name,
+			is_default,
+			created_at,
+			updated_at,
+			deleted_at
+		FROM cashflow_accounts
+		WHERE deleted_at IS NULL
+			AND id = $1
+	`
+
+	var query AccountQuerier
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		query = tx.Query
+	} else {
+		query = r.PostgreDb.Query
+	}
+
+	rows, err := query(
+		context.Background(),
+		querystr,
+		id,
+	)
+	if err != nil {
+		return AccountModel{}, err
+	}
+
+	if err = pgxscan.ScanOne(&m, rows); err != nil {
+		return AccountModel{}, err
+	}
+
+	return m, nil
+}
+
+func (r *AccountRepository) DeleteById(ctx context.Context, id uint64) error {
+	sqlQuery := `
+		UPDATE cashflow_accounts
+		SET deleted_at = $1
+		WHERE id = $2
+	`
+
+	var exec AccountExecutor
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		exec = tx.Exec
+	} else {
+		exec = r.PostgreDb.Exec
+	}
+
+	_, err := exec(
+		context.Background(),
+		sqlQuery,
+		time.Now(),
+		id,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// QueryBalance return balance of every account before the given time, or the
+// latest balance when the time is null. Removed account is only returned when
+// it still has balance at that time.
+func (r *AccountRepository) QueryBalance(ctx context.Context, before sql.NullTime) ([]AccountBalanceViewModel, error) {
+	sqlQuery := `
+		SELECT
+			a.id,
+			a.name,
+			a.is_default,
+			b.balance
+		FROM cashflow_accounts a
+		JOIN LATERAL (
+			SELECT (
+				COALESCE((
+					SELECT SUM(CASE WHEN c.type = 'income' THEN c.idr_amount ELSE -c.idr_amount END)
+					FROM cashflows c
+					WHERE c.deleted_at IS NULL
+						AND c.account_id = a.id
+						AND ($1::TIMESTAMP IS NULL OR c.date < $1::TIMESTAMP)
+				), 0)
+				+ COALESCE((
+					SELECT SUM(t.idr_amount)
+					FROM cashflow_transfers t
+					WHERE t.deleted_at IS NULL
+						AND t.to_account_id = a.id
+						AND ($1::TIMESTAMP IS NULL OR t.date < $1::TIMESTAMP)
+				), 0)
+				- COALESCE((
+					SELECT SUM(t.idr_amount)
+					FROM cashflow_transfers t
+					WHERE t.deleted_at IS NULL
+						AND t.from_account_id = a.id
+						AND ($1::TIMESTAMP IS NULL OR t.date < $1::TIMESTAMP)
+				), 0)
+			)::BIGINT AS balance
+		) b ON true
+		WHERE a.deleted_at IS NULL
+			OR b.balance <> 0
+		ORDER BY a.id ASC
+	`
+
+	var query AccountQuerier
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		query = tx.Query
+	} else {
+		query = r.PostgreDb.Query
+	}
+
+	rows, err := query(
+		context.Background(),
+		sqlQuery,
+		before,
+	)
+	if err != nil {
+		return []AccountBalanceViewModel{}, err
+	}
+	defer rows.Close()
+
+	var mps []*AccountBalanceViewModel
+	if err := pgxscan.ScanAll(&mps, rows); err != nil {
+		return []AccountBalanceViewModel{}, err
+	}
+
+	ms := make([]AccountBalanceViewModel, len(mps))
+	for i, m := range mps {
+		ms[i] = *m
+	}
+
+	return ms, nil
+}
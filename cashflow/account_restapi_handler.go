@@ -0,0 +1,63 @@
+package cashflow
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/resp"
+	"github.com/go-chi/chi/v5"
+)
+
+func (d *CashflowDeps) PostCashflowAccount(w http.ResponseWriter, r *http.Request) {
+	decoder := json.NewDecoder(r.Body)
+
+	var in AddAccountIn
+	err := decoder.Decode(&in)
+	if err != nil {
+		d.CaptureExeption(err)
+		resp.NewResponse(http.StatusInternalServerError, "", err).HttpJSON(w, nil)
+		return
+	}
+
+	out := d.AddAccount(r.Context(), in)
+	if out.Error != nil {
+		d.CaptureExeption(out.Error)
+	}
+	out.HttpJSON(w, resp.NewHttpBody(out.Res))
+}
+
+func (d *CashflowDeps) GetCashflowAccounts(w http.ResponseWriter, r *http.Request) {
+	out := d.QueryAccount(r.Context())
+	if out.Error != nil {
+		d.CaptureExeption(out.Error)
+	}
+	out.HttpJSON(w, resp.NewHttpBody(out.Res))
+}
+
+func (d *CashflowDeps) PutCashflowAccount(w http.ResponseWriter, r *http.Request) {
+	decoder := json.NewDecoder(r.Body)
+
+	var in EditAccountIn
+	err := decoder.Decode(&in)
+	if err != nil {
+		d.CaptureExeption(err)
+		resp.NewResponse(http.StatusInternalServerError, "", err).HttpJSON(w, nil)
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	out := d.EditAccount(r.Context(), id, in)
+	if out.Error != nil {
+		d.CaptureExeption(out.Error)
+	}
+	out.HttpJSON(w, resp.NewHttpBody(out.Res))
+}
+
+func (d *CashflowDeps) DeleteCashflowAccount(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	out := d.RemoveAccount(r.Context(), id)
+	if out.Error != nil {
+		d.CaptureExeption(out.Error)
+	}
+	out.HttpJSON(w, resp.NewHttpBody(out.Res))
+}
@@ -0,0 +1,244 @@
+package cashflow
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"strconv"
+
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/audit"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/resp"
+	"github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrAccountNotFound = errors.New("akun kas tidak ditemukan")
+	ErrDefaultAccount  = errors.New("akun kas utama tidak dapat dihapus")
+	ErrAccountNotEmpty = errors.New("akun kas masih memiliki saldo, pindahkan saldo terlebih dahulu")
+)
+
+type (
+	AddAccountIn struct {
+		Name string `json:"name"`
+	}
+	AddAccountRes struct {
+		Id uint64 `json:"id"`
+	}
+	AddAccountOut struct {
+		resp.Response
+		Res AddAccountRes
+	}
+)
+
+func (d *CashflowDeps) AddAccount(ctx context.Context, in AddAccountIn) (out AddAccountOut) {
+	var err error
+	out.Response = resp.NewResponse(http.StatusCreated, "", nil)
+
+	if err = ValidateAddAccountIn(in); err != nil {
+		out.Response = resp.NewResponse(http.StatusUnprocessableEntity, "", err)
+		return
+	}
+
+	account := AccountModel{
+		Name: in.Name,
+	}
+	if account, err = d.AccountRepository.Save(ctx, account); err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "save account"))
+		return
+	}
+
+	err = d.Audit.Record(ctx, audit.Event{
+		Action:   audit.ActionCreate,
+		Entity:   "account",
+		EntityId: strconv.FormatUint(account.Id, 10),
+		After:    account,
+	})
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "record audit"))
+		return
+	}
+
+	out.Res.Id = account.Id
+
+	return
+}
+
+type (
+	AccountOut struct {
+		Id        uint64 `json:"id"`
+		Name      string `json:"name"`
+		IsDefault bool   `json:"is_default"`
+		Balance   string `json:"balance"`
+	}
+	QueryAccountRes struct {
+		Accounts []AccountOut `json:"accounts"`
+	}
+	QueryAccountOut struct {
+		resp.Response
+		Res QueryAccountRes
+	}
+)
+
+// QueryAccount return the account with its latest balance.
+func (d *CashflowDeps) QueryAccount(ctx context.Context) (out QueryAccountOut) {
+	out.Response = resp.NewResponse(http.StatusOK, "", nil)
+
+	accounts, err := d.AccountRepository.QueryBalance(ctx, sql.NullTime{})
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "query account balance"))
+		return
+	}
+
+	out.Res.Accounts = accountsOut(accounts)
+
+	return
+}
+
+func accountsOut(accounts []AccountBalanceViewModel) []AccountOut {
+	outAccounts := make([]AccountOut, len(accounts))
+	for i, a := range accounts {
+		outAccounts[i] = AccountOut{
+			Id:        a.Id,
+			Name:      a.Name,
+			IsDefault: a.IsDefault,
+			Balance:   a.Balance.String(),
+		}
+	}
+
+	return outAccounts
+}
+
+type (
+	EditAccountIn struct {
+		Name string `json:"name"`
+	}
+	EditAccountRes struct {
+		Id uint64 `json:"id"`
+	}
+	EditAccountOut struct {
+		resp.Response
+		Res EditAccountRes
+	}
+)
+
+func (d *CashflowDeps) EditAccount(ctx context.Context, pid string, in EditAccountIn) (out EditAccountOut) {
+	var err error
+	out.Response = resp.NewResponse(http.StatusOK, "", nil)
+
+	id, err := strconv.ParseUint(pid, 10, 64)
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusNotFound, "", ErrAccountNotFound)
+		return
+	}
+
+	if err = ValidateEditAccountIn(in); err != nil {
+		out.Response = resp.NewResponse(http.StatusUnprocessableEntity, "", err)
+		return
+	}
+
+	account, err := d.AccountRepository.FindById(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		out.Response = resp.NewResponse(http.StatusNotFound, "", ErrAccountNotFound)
+		return
+	}
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "find account by id"))
+		return
+	}
+
+	before := account
+	account.Name = in.Name
+
+	if err = d.AccountRepository.UpdateById(ctx, id, account); err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "update account by id"))
+		return
+	}
+
+	err = d.Audit.Record(ctx, audit.Event{
+		Action:   audit.ActionUpdate,
+		Entity:   "account",
+		EntityId: strconv.FormatUint(id, 10),
+		Before:   before,
+		After:    account,
+	})
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "record audit"))
+		return
+	}
+
+	out.Res.Id = id
+
+	return
+}
+
+type (
+	RemoveAccountRes struct {
+		Id uint64 `json:"id"`
+	}
+	RemoveAccountOut struct {
+		resp.Response
+		Res RemoveAccountRes
+	}
+)
+
+// RemoveAccount remove the account, only empty account other than the default
+// account can be removed.
+func (d *CashflowDeps) RemoveAccount(ctx context.Context, pid string) (out RemoveAccountOut) {
+	var err error
+	out.Response = resp.NewResponse(http.StatusOK, "", nil)
+
+	id, err := strconv.ParseUint(pid, 10, 64)
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusNotFound, "", ErrAccountNotFound)
+		return
+	}
+
+	account, err := d.AccountRepository.FindById(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		out.Response = resp.NewResponse(http.StatusNotFound, "", ErrAccountNotFound)
+		return
+	}
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "find account by id"))
+		return
+	}
+
+	if account.IsDefault {
+		out.Response = resp.NewResponse(http.StatusBadRequest, "", ErrDefaultAccount)
+		return
+	}
+
+	balances, err := d.AccountRepository.QueryBalance(ctx, sql.NullTime{})
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "query account balance"))
+		return
+	}
+
+	for _, b := range balances {
+		if b.Id == id && b.Balance != 0 {
+			out.Response = resp.NewResponse(http.StatusBadRequest, "", ErrAccountNotEmpty)
+			return
+		}
+	}
+
+	if err = d.AccountRepository.DeleteById(ctx, id); err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "delete account by id"))
+		return
+	}
+
+	err = d.Audit.Record(ctx, audit.Event{
+		Action:   audit.ActionDelete,
+		Entity:   "account",
+		EntityId: strconv.FormatUint(id, 10),
+		Before:   account,
+	})
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "record audit"))
+		return
+	}
+
+	out.Res.Id = id
+
+	return
+}
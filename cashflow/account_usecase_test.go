@@ -0,0 +1,121 @@
+package cashflow_test
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+
+	arbitary "github.com/PA-D3RPLA/d3if43-htt-uhomestay/arbitrary"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/cashflow"
+)
+
+func TestAddAccount(t *testing.T) {
+	testCases := []struct {
+		Name               string
+		ExpectedStatusCode int
+		In                 cashflow.AddAccountIn
+	}{
+		{
+			Name:               "Add Account Success",
+			ExpectedStatusCode: http.StatusCreated,
+			In: cashflow.AddAccountIn{
+				Name: "Rekening Bank Kedua",
+			},
+		},
+		{
+			Name:               "Add Account Without Name Fail",
+			ExpectedStatusCode: http.StatusUnprocessableEntity,
+			In:                 cashflow.AddAccountIn{},
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.Name, func(t *testing.T) {
+			tx, err := db.Begin(context.Background())
+
+			ctx := context.WithValue(context.Background(), arbitary.TrxX{}, tx)
+			res := cashflowDeps.AddAccount(ctx, c.In)
+			tx.Commit(context.Background())
+			tx.Rollback(context.Background())
+
+			if res.StatusCode != c.ExpectedStatusCode {
+				t.Logf("%#v", res)
+				t.Log(err)
+				t.Fatalf("Expected response code %d. Got %d\n", c.ExpectedStatusCode, res.StatusCode)
+			}
+		})
+	}
+}
+
+func TestRemoveAccount(t *testing.T) {
+	err := ClearTables(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	emptyAccount, err := accountRepository.Save(context.Background(), cashflow.AccountModel{Name: "Kosong"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	filledAccount, err := accountRepository.Save(context.Background(), cashflow.AccountModel{Name: "Berisi"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	filledCashflow := cashflow.CashflowModel(cashflowSeed)
+	filledCashflow.AccountId = filledAccount.Id
+	if _, err = cashflowRepository.Save(context.Background(), filledCashflow); err != nil {
+		t.Fatal(err)
+	}
+
+	defaultCashflow, err := cashflowRepository.Save(context.Background(), cashflowSeed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		Name               string
+		ExpectedStatusCode int
+		Id                 string
+	}{
+		{
+			Name:               "Remove Empty Account Success",
+			ExpectedStatusCode: http.StatusOK,
+			Id:                 strconv.FormatUint(emptyAccount.Id, 10),
+		},
+		{
+			Name:               "Remove Account with Balance Fail",
+			ExpectedStatusCode: http.StatusBadRequest,
+			Id:                 strconv.FormatUint(filledAccount.Id, 10),
+		},
+		{
+			Name:               "Remove Default Account Fail",
+			ExpectedStatusCode: http.StatusBadRequest,
+			Id:                 strconv.FormatUint(defaultCashflow.AccountId, 10),
+		},
+		{
+			Name:               "Remove Account, Not Found",
+			ExpectedStatusCode: http.StatusNotFound,
+			Id:                 "999",
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.Name, func(t *testing.T) {
+			tx, err := db.Begin(context.Background())
+
+			ctx := context.WithValue(context.Background(), arbitary.TrxX{}, tx)
+			res := cashflowDeps.RemoveAccount(ctx, c.Id)
+			tx.Commit(context.Background())
+			tx.Rollback(context.Background())
+
+			if res.StatusCode != c.ExpectedStatusCode {
+				t.Logf("%#v", res)
+				t.Log(err)
+				t.Fatalf("Expected response code %d. Got %d\n", c.ExpectedStatusCode, res.StatusCode)
+			}
+		})
+	}
+}
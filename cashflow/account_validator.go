@@ -0,0 +1,58 @@
+package cashflow
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/sync/errgroup"
+)
+
+var (
+	ErrAccountNameRequired = errors.New("nama akun kas tidak boleh kosong")
+	ErrMaxAccountName      = errors.New("nama akun kas tidak dapat lebih dari 200 karakter")
+)
+
+func ValidateAddAccountIn(i AddAccountIn) error {
+	g := new(errgroup.Group)
+
+	g.Go(func() error {
+		if strings.Trim(i.Name, " ") == "" {
+			return ErrAccountNameRequired
+		}
+		return nil
+	})
+	g.Go(func() error {
+		if utf8.RuneCountInString(i.Name) > 200 {
+			return ErrMaxAccountName
+		}
+		return nil
+	})
+
+	if err := g.Wait(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func ValidateEditAccountIn(i EditAccountIn) error {
+	g := new(errgroup.Group)
+
+	g.Go(func() error {
+		if strings.Trim(i.Name, " ") == "" {
+			return ErrAccountNameRequired
+		}
+		return nil
+	})
+	g.Go(func() error {
+		if utf8.RuneCountInString(i.Name) > 200 {
+			return ErrMaxAccountName
+		}
+		return nil
+	})
+
+	if err := g.Wait(); err != nil {
+		return err
+	}
+	return nil
+}
@@ -5,6 +5,8 @@ import (
 	"database/sql/driver"
 	"errors"
 	"time"
+
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/money"
 )
 
 // Ref: Saving enumerated values to a database
@@ -58,10 +60,12 @@ func (u CashflowType) Value() (driver.Value, error) {
 
 type CashflowModel struct {
 	Id           uint64
-	IdrAmount    string
+	IdrAmount    money.IDR
 	Note         string
 	ProveFileUrl string
 	Type         CashflowType
+	CategoryId   sql.NullInt64
+	AccountId    uint64
 	Date         time.Time
 	CreatedAt    time.Time
 	UpdatedAt    time.Time
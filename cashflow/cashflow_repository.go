@@ -2,9 +2,11 @@ package cashflow
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	arbitary "github.com/PA-D3RPLA/d3if43-htt-uhomestay/arbitrary"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/money"
 
 	"github.com/georgysavva/scany/pgxscan"
 	"github.com/jackc/pgconn"
@@ -28,6 +30,15 @@ type (
 	CashflowQuerier    func(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
 )
 
+// defaultAccount return sql expression of the account id parameter, which fall
+// back to the default account when the account id is zero.
+func defaultAccount(param string) string {
+	return `COALESCE(
+			NULLIF(` + param + `::BIGINT, 0),
+			(SELECT id FROM cashflow_accounts WHERE is_default = true AND deleted_at IS NULL)
+		)`
+}
+
 func (r *CashflowRepository) Save(ctx context.Context, m CashflowModel) (nm CashflowModel, err error) {
 	sqlQuery := `
 		INSERT INTO cashflows (
@@ -36,12 +47,14 @@ func (r *CashflowRepository) Save(ctx context.Context, m CashflowModel) (nm Cash
 			type,
 			note,
 			prove_file_url,
+			category_id,
+			account_id,
 			created_at,
 			updated_at,
 			deleted_at
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-		RETURNING id
+		VALUES ($1, $2, $3, $4, $5, $6, ` + defaultAccount("$7") + `, $8, $9, $10)
+		RETURNING id, account_id
 	`
 
 	var queryRow CashflowQuerierRow
@@ -52,7 +65,7 @@ func (r *CashflowRepository) Save(ctx context.Context, m CashflowModel) (nm Cash
 		queryRow = r.PostgreDb.QueryRow
 	}
 
-	var lastInsertId uint64
+	var lastInsertId, accountId uint64
 	t := time.Now()
 
 	err = queryRow(
@@ -63,16 +76,19 @@ func (r *CashflowRepository) Save(ctx context.Context, m CashflowModel) (nm Cash
 		m.Type,
 		m.Note,
 		m.ProveFileUrl,
+		m.CategoryId,
+		m.AccountId,
 		t,
 		t,
 		nil,
-	).Scan(&lastInsertId)
+	).Scan(&lastInsertId, &accountId)
 
 	if err != nil {
 		return CashflowModel{}, err
 	}
 
 	m.Id = lastInsertId
+	m.AccountId = accountId
 	m.CreatedAt = t
 	m.UpdatedAt = t
 
@@ -87,9 +103,11 @@ func (r *CashflowRepository) UpdateById(ctx context.Context, id uint64, m Cashfl
 			type,
 			note,
 			prove_file_url,
+			category_id,
+			account_id,
 			updated_at
-		) = ($1, $2, $3, $4, $5, $6)
-		WHERE id = $7
+		) = ($1, $2, $3, $4, $5, $6, ` + defaultAccount("$7") + `, $8)
+		WHERE id = $9
 	`
 
 	var exec CasflowExecutor
@@ -111,6 +129,8 @@ func (r *CashflowRepository) UpdateById(ctx context.Context, id uint64, m Cashfl
 		m.Type,
 		m.Note,
 		m.ProveFileUrl,
+		m.CategoryId,
+		m.AccountId,
 		t,
 		id,
 	)
@@ -130,6 +150,8 @@ func (r *CashflowRepository) FindById(ctx context.Context, id uint64) (m Cashflo
 			type,
 			note,
 			prove_file_url,
+			category_id,
+			account_id,
 			created_at,
 			updated_at,
 			deleted_at
@@ -210,6 +232,8 @@ func (r *CashflowRepository) Query(ctx context.Context, id, limit int64) ([]Cash
 			type,
 			note,
 			prove_file_url,
+			category_id,
+			account_id,
 			created_at,
 			updated_at,
 			deleted_at
@@ -241,33 +265,33 @@ func (r *CashflowRepository) Query(ctx context.Context, id, limit int64) ([]Cash
 	return ms, nil
 }
 
-func (r *CashflowRepository) QueryAmtByStatus(ctx context.Context, status CashflowType) ([]string, error) {
+func (r *CashflowRepository) SumAmtByType(ctx context.Context, typ CashflowType) (amt money.IDR, err error) {
 	sqlQuery := `
-		SELECT 
-			idr_amount
+		SELECT COALESCE(SUM(idr_amount), 0)::BIGINT AS amt
 		FROM cashflows 
 		WHERE deleted_at IS NULL
 			AND type = $1
 	`
 
-	rows, _ := r.PostgreDb.Query(
+	var queryRow CashflowQuerierRow
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		queryRow = tx.QueryRow
+	} else {
+		queryRow = r.PostgreDb.QueryRow
+	}
+
+	err = queryRow(
 		context.Background(),
 		sqlQuery,
-		status.String,
-	)
-	defer rows.Close()
-
-	var mps []*string
-	if err := pgxscan.ScanAll(&mps, rows); err != nil {
-		return []string{}, err
-	}
+		typ.String,
+	).Scan(&amt)
 
-	ms := make([]string, len(mps))
-	for i, m := range mps {
-		ms[i] = *m
+	if err != nil {
+		return 0, err
 	}
 
-	return ms, nil
+	return amt, nil
 }
 
 func (r *CashflowRepository) CountCashflowByType(ctx context.Context, typ string) (n int64, err error) {
@@ -298,3 +322,142 @@ func (r *CashflowRepository) CountCashflowByType(ctx context.Context, typ string
 
 	return n, nil
 }
+
+// SumBalanceBefore return the total cash, income minus outcome, of cashflow
+// dated before the given time.
+func (r *CashflowRepository) SumBalanceBefore(ctx context.Context, before time.Time) (amt money.IDR, err error) {
+	sqlQuery := `
+		SELECT COALESCE(SUM(CASE WHEN type = 'income' THEN idr_amount ELSE -idr_amount END), 0)::BIGINT AS amt
+		FROM cashflows
+		WHERE deleted_at IS NULL
+			AND date < $1
+	`
+
+	var queryRow CashflowQuerierRow
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		queryRow = tx.QueryRow
+	} else {
+		queryRow = r.PostgreDb.QueryRow
+	}
+
+	err = queryRow(
+		context.Background(),
+		sqlQuery,
+		before,
+	).Scan(&amt)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return amt, nil
+}
+
+// SumAmtByCategory return the income and outcome of each category of cashflow
+// dated from the start until before the end.
+func (r *CashflowRepository) SumAmtByCategory(ctx context.Context, start, end time.Time) ([]CategoryAmtViewModel, error) {
+	sqlQuery := `
+		SELECT
+			c.type,
+			c.category_id,
+			cc.name AS category_name,
+			SUM(c.idr_amount)::BIGINT AS idr_amount
+		FROM cashflows c
+		LEFT JOIN cashflow_categories cc ON cc.id = c.category_id
+		WHERE c.deleted_at IS NULL
+			AND c.date >= $1
+			AND c.date < $2
+		GROUP BY c.type, c.category_id, cc.name
+		ORDER BY c.type ASC, c.category_id ASC NULLS LAST
+	`
+
+	var query CashflowQuerier
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		query = tx.Query
+	} else {
+		query = r.PostgreDb.Query
+	}
+
+	rows, err := query(
+		context.Background(),
+		sqlQuery,
+		start,
+		end,
+	)
+	if err != nil {
+		return []CategoryAmtViewModel{}, err
+	}
+	defer rows.Close()
+
+	var mps []*CategoryAmtViewModel
+	if err := pgxscan.ScanAll(&mps, rows); err != nil {
+		return []CategoryAmtViewModel{}, err
+	}
+
+	ms := make([]CategoryAmtViewModel, len(mps))
+	for i, m := range mps {
+		ms[i] = *m
+	}
+
+	return ms, nil
+}
+
+// IterateLedger call fn for every cashflow dated from the start until before
+// the end, ordered by date. A null bound is not limited. The rows are read one
+// by one, so the ledger is never loaded into memory at once.
+func (r *CashflowRepository) IterateLedger(ctx context.Context, start, end sql.NullTime, fn func(m LedgerViewModel) error) error {
+	sqlQuery := `
+		SELECT
+			c.id,
+			c.date,
+			c.type,
+			cc.name AS category_name,
+			ca.name AS account_name,
+			c.idr_amount,
+			c.note
+		FROM cashflows c
+		LEFT JOIN cashflow_categories cc ON cc.id = c.category_id
+		JOIN cashflow_accounts ca ON ca.id = c.account_id
+		WHERE c.deleted_at IS NULL
+			AND ($1::TIMESTAMP IS NULL OR c.date >= $1::TIMESTAMP)
+			AND ($2::TIMESTAMP IS NULL OR c.date < $2::TIMESTAMP)
+		ORDER BY c.date ASC, c.id ASC
+	`
+
+	var query CashflowQuerier
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		query = tx.Query
+	} else {
+		query = r.PostgreDb.Query
+	}
+
+	// The request context is used, so the query is cancelled when the client
+	// stop reading the export.
+	rows, err := query(
+		ctx,
+		sqlQuery,
+		start,
+		end,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	rs := pgxscan.NewRowScanner(rows)
+	for rows.Next() {
+		var m LedgerViewModel
+		if err := rs.Scan(&m); err != nil {
+			return err
+		}
+
+		if err := fn(m); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
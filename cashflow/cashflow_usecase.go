@@ -7,24 +7,31 @@ import (
 	"strings"
 	"time"
 
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/audit"
 	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/httpdecode"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/money"
 	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/resp"
 	"github.com/jackc/pgx/v4"
 	"github.com/pkg/errors"
+	"gopkg.in/guregu/null.v4"
 )
 
 var (
 	ErrCashflowNotFound = errors.New("cashflow tidak ditemukan")
 	ErrDateFormat       = errors.New("format tanggal tidak sesuai <tahun>-<bulan>-<hari>")
+	ErrFileNotFound     = errors.New("bukti cashflow tidak ditemukan")
+	ErrLoginRequired    = errors.New("silakan login untuk mengakses file ini")
 )
 
 type (
 	AddCashflowIn struct {
-		Date      string                `mapstructure:"date"`
-		IdrAmount string                `mapstructure:"idr_amount"`
-		Type      string                `mapstructure:"type"`
-		Note      string                `mapstructure:"note"`
-		File      httpdecode.FileHeader `mapstructure:"file"`
+		Date       string                `mapstructure:"date"`
+		IdrAmount  string                `mapstructure:"idr_amount"`
+		Type       string                `mapstructure:"type"`
+		Note       string                `mapstructure:"note"`
+		CategoryId null.Int              `mapstructure:"category_id"`
+		AccountId  null.Int              `mapstructure:"account_id"`
+		File       httpdecode.FileHeader `mapstructure:"file"`
 	}
 	AddCashflowRes struct {
 		Id int64 `json:"id"`
@@ -46,6 +53,11 @@ func (d *CashflowDeps) AddCashflow(ctx context.Context, in AddCashflowIn) (out A
 		return
 	}
 
+	if res := d.checkCategoryAccount(ctx, in.CategoryId, in.AccountId); res.Error != nil {
+		out.Response = res
+		return
+	}
+
 	var file httpdecode.File
 	if in.File.File != nil {
 		file = in.File.File
@@ -72,12 +84,16 @@ func (d *CashflowDeps) AddCashflow(ctx context.Context, in AddCashflowIn) (out A
 		return
 	}
 
+	amount, _ := money.ParseIDR(in.IdrAmount)
+
 	cashflow := CashflowModel{
 		Date:         date,
-		IdrAmount:    in.IdrAmount,
+		IdrAmount:    amount,
 		Type:         ct,
 		Note:         in.Note,
 		ProveFileUrl: fileUrl,
+		CategoryId:   in.CategoryId.NullInt64,
+		AccountId:    uint64(in.AccountId.Int64),
 	}
 
 	if cashflow, err = d.CashflowRepository.Save(ctx, cashflow); err != nil {
@@ -85,6 +101,17 @@ func (d *CashflowDeps) AddCashflow(ctx context.Context, in AddCashflowIn) (out A
 		return
 	}
 
+	err = d.Audit.Record(ctx, audit.Event{
+		Action:   audit.ActionCreate,
+		Entity:   "cashflow",
+		EntityId: strconv.FormatUint(cashflow.Id, 10),
+		After:    cashflow,
+	})
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "record audit"))
+		return
+	}
+
 	out.Res.Id = int64(cashflow.Id)
 
 	return
@@ -98,6 +125,8 @@ type (
 		Type         string `json:"type"`
 		IdrAmout     string `json:"idr_amount"`
 		ProveFileUrl string `json:"prove_file_url"`
+		CategoryId   int64  `json:"category_id"`
+		AccountId    uint64 `json:"account_id"`
 	}
 	CashflowRes struct {
 		Cursor    int64         `json:"cursor"`
@@ -136,8 +165,10 @@ func (d *CashflowDeps) QueryCashflow(ctx context.Context, cursor, limit string)
 			Date:         c.Date.Format("2006-01-02"),
 			Note:         c.Note,
 			Type:         c.Type.String,
-			IdrAmout:     c.IdrAmount,
-			ProveFileUrl: c.ProveFileUrl,
+			IdrAmout:     c.IdrAmount.String(),
+			ProveFileUrl: fileEndpoint(c),
+			CategoryId:   c.CategoryId.Int64,
+			AccountId:    c.AccountId,
 		}
 	}
 
@@ -149,13 +180,52 @@ func (d *CashflowDeps) QueryCashflow(ctx context.Context, cursor, limit string)
 	return
 }
 
+// checkCategoryAccount make sure the category and account of the cashflow
+// exist, both are optional where cashflow without account is kept in the
+// default account.
+func (d *CashflowDeps) checkCategoryAccount(ctx context.Context, categoryId, accountId null.Int) resp.Response {
+	if categoryId.Valid {
+		_, err := d.CategoryRepository.FindById(ctx, uint64(categoryId.Int64))
+		if errors.Is(err, pgx.ErrNoRows) {
+			return resp.NewResponse(http.StatusUnprocessableEntity, "", ErrCategoryNotFound)
+		}
+		if err != nil {
+			return resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "find category by id"))
+		}
+	}
+
+	if accountId.Valid {
+		_, err := d.AccountRepository.FindById(ctx, uint64(accountId.Int64))
+		if errors.Is(err, pgx.ErrNoRows) {
+			return resp.NewResponse(http.StatusUnprocessableEntity, "", ErrAccountNotFound)
+		}
+		if err != nil {
+			return resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "find account by id"))
+		}
+	}
+
+	return resp.NewResponse(http.StatusOK, "", nil)
+}
+
+// fileEndpoint is where the prove file of the cashflow can be downloaded, the
+// raw storage url is never handed to the client.
+func fileEndpoint(c CashflowModel) string {
+	if c.ProveFileUrl == "" {
+		return ""
+	}
+
+	return "/api/v1/files/cashflows/" + strconv.FormatUint(c.Id, 10)
+}
+
 type (
 	EditCashflowIn struct {
-		Date      string                `mapstructure:"date"`
-		IdrAmount string                `mapstructure:"idr_amount"`
-		Type      string                `mapstructure:"type"`
-		Note      string                `mapstructure:"note"`
-		File      httpdecode.FileHeader `mapstructure:"file"`
+		Date       string                `mapstructure:"date"`
+		IdrAmount  string                `mapstructure:"idr_amount"`
+		Type       string                `mapstructure:"type"`
+		Note       string                `mapstructure:"note"`
+		CategoryId null.Int              `mapstructure:"category_id"`
+		AccountId  null.Int              `mapstructure:"account_id"`
+		File       httpdecode.FileHeader `mapstructure:"file"`
 	}
 	EditCashflowRes struct {
 		Id int64 `json:"id"`
@@ -183,6 +253,11 @@ func (d *CashflowDeps) EditCashflow(ctx context.Context, pid string, in EditCash
 		return
 	}
 
+	if res := d.checkCategoryAccount(ctx, in.CategoryId, in.AccountId); res.Error != nil {
+		out.Response = res
+		return
+	}
+
 	date, err := time.Parse("2006-01-02", in.Date)
 	if err != nil {
 		out.Response = resp.NewResponse(http.StatusUnprocessableEntity, "", ErrDateFormat)
@@ -219,10 +294,17 @@ func (d *CashflowDeps) EditCashflow(ctx context.Context, pid string, in EditCash
 		}
 	}
 
+	amount, _ := money.ParseIDR(in.IdrAmount)
+
+	before := cashflow
 	cashflow.Date = date
-	cashflow.IdrAmount = in.IdrAmount
+	cashflow.IdrAmount = amount
 	cashflow.Type = ct
 	cashflow.Note = in.Note
+	cashflow.CategoryId = in.CategoryId.NullInt64
+	if in.AccountId.Valid {
+		cashflow.AccountId = uint64(in.AccountId.Int64)
+	}
 
 	if fileUrl != "" {
 		cashflow.ProveFileUrl = fileUrl
@@ -233,6 +315,18 @@ func (d *CashflowDeps) EditCashflow(ctx context.Context, pid string, in EditCash
 		return
 	}
 
+	err = d.Audit.Record(ctx, audit.Event{
+		Action:   audit.ActionUpdate,
+		Entity:   "cashflow",
+		EntityId: strconv.FormatUint(id, 10),
+		Before:   before,
+		After:    cashflow,
+	})
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "record audit"))
+		return
+	}
+
 	out.Res.Id = int64(id)
 
 	return
@@ -258,7 +352,7 @@ func (d *CashflowDeps) RemoveCashflow(ctx context.Context, pid string) (out Remo
 		return
 	}
 
-	_, err = d.CashflowRepository.FindById(ctx, id)
+	cashflow, err := d.CashflowRepository.FindById(ctx, id)
 	if errors.Is(err, pgx.ErrNoRows) {
 		out.Response = resp.NewResponse(http.StatusNotFound, "", ErrCashflowNotFound)
 		return
@@ -273,6 +367,17 @@ func (d *CashflowDeps) RemoveCashflow(ctx context.Context, pid string) (out Remo
 		return
 	}
 
+	err = d.Audit.Record(ctx, audit.Event{
+		Action:   audit.ActionDelete,
+		Entity:   "cashflow",
+		EntityId: strconv.FormatUint(id, 10),
+		Before:   cashflow,
+	})
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "record audit"))
+		return
+	}
+
 	out.Res.Id = int64(id)
 
 	return
@@ -295,28 +400,22 @@ type (
 func (d *CashflowDeps) CalculateCashflow(ctx context.Context) (out CashflowStatsOut) {
 	out.Response = resp.NewResponse(http.StatusOK, "", nil)
 
-	duefFlow := func(ctx context.Context, status CashflowType, flow chan float64, res chan resp.Response) {
+	duefFlow := func(ctx context.Context, status CashflowType, flow chan money.IDR, res chan resp.Response) {
 		var r resp.Response
-		amts, err := d.CashflowRepository.QueryAmtByStatus(ctx, status)
+		amt, err := d.CashflowRepository.SumAmtByType(ctx, status)
 		if err != nil {
-			r = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "query "+status.String+" flow"))
-		}
-
-		var namts float64
-		for _, u := range amts {
-			cash, _ := strconv.ParseFloat(u, 64)
-			namts += cash
+			r = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "sum "+status.String+" flow"))
 		}
 
-		flow <- namts
+		flow <- amt
 		res <- r
 	}
 
-	inFlow := make(chan float64)
+	inFlow := make(chan money.IDR)
 	inRes := make(chan resp.Response)
 	go duefFlow(ctx, Income, inFlow, inRes)
 
-	outFlow := make(chan float64)
+	outFlow := make(chan money.IDR)
 	outRes := make(chan resp.Response)
 	go duefFlow(ctx, Outcome, outFlow, outRes)
 
@@ -348,10 +447,58 @@ func (d *CashflowDeps) CalculateCashflow(ctx context.Context) (out CashflowStats
 	out.Res = CashflowStatsRes{
 		IncomeTotal:  incomeNumber,
 		OutcomeTotal: outcomeNumber,
-		TotalCash:    strconv.FormatFloat(inFV-oFV, 'f', -1, 64),
-		IncomeCash:   strconv.FormatFloat(inFV, 'f', -1, 64),
-		OutcomeCash:  strconv.FormatFloat(oFV, 'f', -1, 64),
+		TotalCash:    (inFV - oFV).String(),
+		IncomeCash:   inFV.String(),
+		OutcomeCash:  oFV.String(),
+	}
+
+	return
+}
+
+type (
+	CashflowFileRes struct {
+		Url string `json:"url"`
+	}
+	CashflowFileOut struct {
+		resp.Response
+		Res CashflowFileRes
+	}
+)
+
+// FindCashflowFile return short-lived signed url of the cashflow prove file,
+// only member can see the prove file.
+func (d *CashflowDeps) FindCashflowFile(ctx context.Context, pid string, isMember bool) (out CashflowFileOut) {
+	var err error
+	out.Response = resp.NewResponse(http.StatusOK, "", nil)
+
+	if !isMember {
+		out.Response = resp.NewResponse(http.StatusUnauthorized, "", ErrLoginRequired)
+		return
 	}
 
+	id, err := strconv.ParseUint(pid, 10, 64)
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusNotFound, "", ErrFileNotFound)
+		return
+	}
+
+	cashflow, err := d.CashflowRepository.FindById(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) || cashflow.ProveFileUrl == "" {
+		out.Response = resp.NewResponse(http.StatusNotFound, "", ErrFileNotFound)
+		return
+	}
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "find cashflow by id"))
+		return
+	}
+
+	url, err := d.Sign(cashflow.ProveFileUrl)
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "sign file url"))
+		return
+	}
+
+	out.Res.Url = url
+
 	return
 }
@@ -12,6 +12,7 @@ import (
 	arbitary "github.com/PA-D3RPLA/d3if43-htt-uhomestay/arbitrary"
 	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/cashflow"
 	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/httpdecode"
+	"gopkg.in/guregu/null.v4"
 )
 
 func TestAddCashflow(t *testing.T) {
@@ -30,6 +31,26 @@ func TestAddCashflow(t *testing.T) {
 		ExpectedStatusCode int
 		In                 cashflow.AddCashflowIn
 	}{
+		{
+			Name:               "Add Income Cashflow with Unknown Category Fail",
+			ExpectedStatusCode: http.StatusUnprocessableEntity,
+			In: cashflow.AddCashflowIn{
+				Date:       time.Now().Format("2006-01-02"),
+				IdrAmount:  "10000",
+				Type:       "income",
+				CategoryId: null.IntFrom(999),
+			},
+		},
+		{
+			Name:               "Add Income Cashflow with Unknown Account Fail",
+			ExpectedStatusCode: http.StatusUnprocessableEntity,
+			In: cashflow.AddCashflowIn{
+				Date:      time.Now().Format("2006-01-02"),
+				IdrAmount: "10000",
+				Type:      "income",
+				AccountId: null.IntFrom(999),
+			},
+		},
 		{
 			Name:               "Add Income Cashflow Without Note and File Success",
 			ExpectedStatusCode: http.StatusCreated,
@@ -128,21 +149,21 @@ func TestAddCashflow(t *testing.T) {
 			},
 		},
 		{
-			Name:               "Add Income Cashflow with Idr Amout 200 chars Success",
-			ExpectedStatusCode: http.StatusCreated,
+			Name:               "Add Income Cashflow with Idr Amount Not Number Fail",
+			ExpectedStatusCode: http.StatusUnprocessableEntity,
 			In: cashflow.AddCashflowIn{
 				Date:      time.Now().Format("2006-01-02"),
-				IdrAmount: strings.Repeat("1", 200),
+				IdrAmount: "10.000",
 				Type:      "income",
 				Note:      "Just Note",
 			},
 		},
 		{
-			Name:               "Add Income Cashflow with Idr Amout over 200 chars fail",
+			Name:               "Add Income Cashflow with Idr Amount Overflow Fail",
 			ExpectedStatusCode: http.StatusUnprocessableEntity,
 			In: cashflow.AddCashflowIn{
 				Date:      time.Now().Format("2006-01-02"),
-				IdrAmount: strings.Repeat("1", 201),
+				IdrAmount: strings.Repeat("9", 19),
 				Type:      "income",
 				Note:      "Just Note",
 			},
@@ -348,23 +369,23 @@ func TestEditCashflow(t *testing.T) {
 			},
 		},
 		{
-			Name:               "Edit Income Cashflow with Idr Amount 200 chars success",
+			Name:               "Edit Income Cashflow with Idr Amount Zero Fraction Success",
 			ExpectedStatusCode: http.StatusOK,
 			Id:                 pid,
 			In: cashflow.EditCashflowIn{
 				Date:      time.Now().Format("2006-01-02"),
-				IdrAmount: strings.Repeat("1", 200),
+				IdrAmount: "10000.00",
 				Type:      "income",
 				Note:      "Just Note",
 			},
 		},
 		{
-			Name:               "Edit Income Cashflow with Idr Amount over 200 chars success",
+			Name:               "Edit Income Cashflow with Idr Amount Overflow Fail",
 			ExpectedStatusCode: http.StatusUnprocessableEntity,
 			Id:                 pid,
 			In: cashflow.EditCashflowIn{
 				Date:      time.Now().Format("2006-01-02"),
-				IdrAmount: strings.Repeat("1", 201),
+				IdrAmount: strings.Repeat("9", 19),
 				Type:      "income",
 				Note:      "Just Note",
 			},
@@ -474,3 +495,68 @@ func TestCalculateCashflow(t *testing.T) {
 		})
 	}
 }
+
+func TestFindCashflowFile(t *testing.T) {
+	err := ClearTables(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	withFile := cashflow.CashflowModel(cashflowSeed)
+	withFile.ProveFileUrl = "https://res.cloudinary.com/demo/raw/private/v1/uhomestay/cashflows/proof.pdf"
+	nc, err := cashflowRepository.Save(context.Background(), withFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nc2, err := cashflowRepository.Save(context.Background(), cashflowSeed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pid := strconv.FormatUint(nc.Id, 10)
+	pid2 := strconv.FormatUint(nc2.Id, 10)
+
+	testCases := []struct {
+		Name               string
+		ExpectedStatusCode int
+		Id                 string
+		IsMember           bool
+	}{
+		{
+			Name:               "Find Cashflow File Success",
+			ExpectedStatusCode: http.StatusOK,
+			Id:                 pid,
+			IsMember:           true,
+		},
+		{
+			Name:               "Find Cashflow File as Anonymous Fail, Unauthorized",
+			ExpectedStatusCode: http.StatusUnauthorized,
+			Id:                 pid,
+			IsMember:           false,
+		},
+		{
+			Name:               "Find Cashflow File Fail, No Prove File",
+			ExpectedStatusCode: http.StatusNotFound,
+			Id:                 pid2,
+			IsMember:           true,
+		},
+		{
+			Name:               "Find Cashflow File Fail, Not Found",
+			ExpectedStatusCode: http.StatusNotFound,
+			Id:                 "999",
+			IsMember:           true,
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.Name, func(t *testing.T) {
+			res := cashflowDeps.FindCashflowFile(context.Background(), c.Id, c.IsMember)
+
+			if res.StatusCode != c.ExpectedStatusCode {
+				t.Logf("%#v", res)
+				t.Fatalf("Expected response code %d. Got %d\n", c.ExpectedStatusCode, res.StatusCode)
+			}
+		})
+	}
+}
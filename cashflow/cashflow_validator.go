@@ -3,8 +3,8 @@ package cashflow
 import (
 	"errors"
 	"strings"
-	"unicode/utf8"
 
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/money"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -12,7 +12,6 @@ var (
 	ErrUnknownType       = errors.New("tipe cashflow tidak diketahui, tipe yang diperbolehkan 'pemasukan' atau 'pengeluaran'")
 	ErrDateRequired      = errors.New("tanggal tidak boleh kosong")
 	ErrIdrAmountRequired = errors.New("jumlah nominal rupiah tidak boleh kosong")
-	ErrMaxIdrAmount      = errors.New("jumlah nominal rupiah tidak dapat lebih dari 200 karakter")
 )
 
 func ValidateAddCashflowIn(i AddCashflowIn, ct CashflowType) error {
@@ -38,10 +37,12 @@ func ValidateAddCashflowIn(i AddCashflowIn, ct CashflowType) error {
 		return nil
 	})
 	g.Go(func() error {
-		if utf8.RuneCountInString(i.IdrAmount) > 200 {
-			return ErrMaxIdrAmount
+		if strings.Trim(i.IdrAmount, " ") == "" {
+			return nil
 		}
-		return nil
+
+		_, err := money.ParseIDR(i.IdrAmount)
+		return err
 	})
 
 	if err := g.Wait(); err != nil {
@@ -73,10 +74,12 @@ func ValidateEditCashflowIn(i EditCashflowIn, ct CashflowType) error {
 		return nil
 	})
 	g.Go(func() error {
-		if utf8.RuneCountInString(i.IdrAmount) > 200 {
-			return ErrMaxIdrAmount
+		if strings.Trim(i.IdrAmount, " ") == "" {
+			return nil
 		}
-		return nil
+
+		_, err := money.ParseIDR(i.IdrAmount)
+		return err
 	})
 
 	if err := g.Wait(); err != nil {
@@ -0,0 +1,19 @@
+package cashflow
+
+import (
+	"database/sql"
+	"time"
+)
+
+// DuesCategory is the code of the category of cashflow recorded from the paid
+// member dues.
+const DuesCategory = "dues"
+
+type CategoryModel struct {
+	Id        uint64
+	Code      sql.NullString
+	Name      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt sql.NullTime
+}
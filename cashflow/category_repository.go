@@ -0,0 +1,240 @@
+package cashflow
+
+import (
+	"context"
+	"time"
+
+	arbitary "github.com/PA-D3RPLA/d3if43-htt-uhomestay/arbitrary"
+
+	"github.com/georgysavva/scany/pgxscan"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+type CategoryRepository struct {
+	PostgreDb *pgxpool.Pool
+}
+
+func NewCategoryRepository(postgreDb *pgxpool.Pool) *CategoryRepository {
+	return &CategoryRepository{
+		PostgreDb: postgreDb,
+	}
+}
+
+type (
+	CategoryExecutor   func(ctx context.Context, sql string, arguments ...interface{}) (commandTag pgconn.CommandTag, err error)
+	CategoryQuerierRow func(ctx context.Context, sql string, args ...interface{}) pgx.Row
+	CategoryQuerier    func(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+)
+
+func (r *CategoryRepository) Save(ctx context.Context, m CategoryModel) (nm CategoryModel, err error) {
+	sqlQuery := `
+		INSERT INTO cashflow_categories (
+			name,
+			created_at,
+			updated_at,
+			deleted_at
+		)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`
+
+	var queryRow CategoryQuerierRow
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		queryRow = tx.QueryRow
+	} else {
+		queryRow = r.PostgreDb.QueryRow
+	}
+
+	var lastInsertId uint64
+	t := time.Now()
+
+	err = queryRow(
+		context.Background(),
+		sqlQuery,
+		m.Name,
+		t,
+		t,
+		nil,
+	).Scan(&lastInsertId)
+
+	if err != nil {
+		return CategoryModel{}, err
+	}
+
+	m.Id = lastInsertId
+	m.CreatedAt = t
+	m.UpdatedAt = t
+
+	return m, nil
+}
+
+func (r *CategoryRepository) UpdateById(ctx context.Context, id uint64, m CategoryModel) error {
+	sqlQuery := `
+		UPDATE cashflow_categories SET (
+			name,
+			updated_at
+		) = ($1, $2)
+		WHERE id = $3
+	`
+
+	var exec CategoryExecutor
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		exec = tx.Exec
+	} else {
+		exec = r.PostgreDb.Exec
+	}
+
+	_, err := exec(
+		context.Background(),
+		sqlQuery,
+		m.Name,
+		time.Now(),
+		id,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *CategoryRepository) FindById(ctx context.Context, id uint64) (m CategoryModel, err error) {
+	querystr := `
+		SELECT
+			id,
+			code,
+			name,
+			created_at,
+			updated_at,
+			deleted_at
+		FROM cashflow_categories
+		WHERE deleted_at IS NULL
+			AND id = $1
+	`
+
+	var query CategoryQuerier
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		query = tx.Query
+	} else {
+		query = r.PostgreDb.Query
+	}
+
+	rows, err := query(
+		context.Background(),
+		querystr,
+		id,
+	)
+	if err != nil {
+		return CategoryModel{}, err
+	}
+
+	if err = pgxscan.ScanOne(&m, rows); err != nil {
+		return CategoryModel{}, err
+	}
+
+	return m, nil
+}
+
+func (r *CategoryRepository) FindByCode(ctx context.Context, code string) (m CategoryModel, err error) {
+	querystr := `
+		SELECT
+			id,
+			code,
+			name,
+			created_at,
+			updated_at,
+			deleted_at
+		FROM cashflow_categories
+		WHERE deleted_at IS NULL
+			AND code = $1
+	`
+
+	var query CategoryQuerier
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		query = tx.Query
+	} else {
+		query = r.PostgreDb.Query
+	}
+
+	rows, err := query(
+		context.Background(),
+		querystr,
+		code,
+	)
+	if err != nil {
+		return CategoryModel{}, err
+	}
+
+	if err = pgxscan.ScanOne(&m, rows); err != nil {
+		return CategoryModel{}, err
+	}
+
+	return m, nil
+}
+
+func (r *CategoryRepository) DeleteById(ctx context.Context, id uint64) error {
+	sqlQuery := `
+		UPDATE cashflow_categories
+		SET deleted_at = $1
+		WHERE id = $2
+	`
+
+	var exec CategoryExecutor
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		exec = tx.Exec
+	} else {
+		exec = r.PostgreDb.Exec
+	}
+
+	_, err := exec(
+		context.Background(),
+		sqlQuery,
+		time.Now(),
+		id,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *CategoryRepository) Query(ctx context.Context) ([]CategoryModel, error) {
+	sqlQuery := `
+		SELECT
+			id,
+			code,
+			name,
+			created_at,
+			updated_at,
+			deleted_at
+		FROM cashflow_categories
+		WHERE deleted_at IS NULL
+		ORDER BY id ASC
+	`
+
+	rows, _ := r.PostgreDb.Query(
+		context.Background(),
+		sqlQuery,
+	)
+	defer rows.Close()
+
+	var mps []*CategoryModel
+	if err := pgxscan.ScanAll(&mps, rows); err != nil {
+		return []CategoryModel{}, err
+	}
+
+	ms := make([]CategoryModel, len(mps))
+	for i, m := range mps {
+		ms[i] = *m
+	}
+
+	return ms, nil
+}
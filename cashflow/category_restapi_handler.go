@@ -0,0 +1,63 @@
+package cashflow
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/resp"
+	"github.com/go-chi/chi/v5"
+)
+
+func (d *CashflowDeps) PostCashflowCategory(w http.ResponseWriter, r *http.Request) {
+	decoder := json.NewDecoder(r.Body)
+
+	var in AddCategoryIn
+	err := decoder.Decode(&in)
+	if err != nil {
+		d.CaptureExeption(err)
+		resp.NewResponse(http.StatusInternalServerError, "", err).HttpJSON(w, nil)
+		return
+	}
+
+	out := d.AddCategory(r.Context(), in)
+	if out.Error != nil {
+		d.CaptureExeption(out.Error)
+	}
+	out.HttpJSON(w, resp.NewHttpBody(out.Res))
+}
+
+func (d *CashflowDeps) GetCashflowCategories(w http.ResponseWriter, r *http.Request) {
+	out := d.QueryCategory(r.Context())
+	if out.Error != nil {
+		d.CaptureExeption(out.Error)
+	}
+	out.HttpJSON(w, resp.NewHttpBody(out.Res))
+}
+
+func (d *CashflowDeps) PutCashflowCategory(w http.ResponseWriter, r *http.Request) {
+	decoder := json.NewDecoder(r.Body)
+
+	var in EditCategoryIn
+	err := decoder.Decode(&in)
+	if err != nil {
+		d.CaptureExeption(err)
+		resp.NewResponse(http.StatusInternalServerError, "", err).HttpJSON(w, nil)
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	out := d.EditCategory(r.Context(), id, in)
+	if out.Error != nil {
+		d.CaptureExeption(out.Error)
+	}
+	out.HttpJSON(w, resp.NewHttpBody(out.Res))
+}
+
+func (d *CashflowDeps) DeleteCashflowCategory(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	out := d.RemoveCategory(r.Context(), id)
+	if out.Error != nil {
+		d.CaptureExeption(out.Error)
+	}
+	out.HttpJSON(w, resp.NewHttpBody(out.Res))
+}
@@ -0,0 +1,223 @@
+package cashflow
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/audit"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/resp"
+	"github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrCategoryNotFound = errors.New("kategori cashflow tidak ditemukan")
+	ErrSystemCategory   = errors.New("kategori bawaan sistem tidak dapat dihapus")
+)
+
+type (
+	AddCategoryIn struct {
+		Name string `json:"name"`
+	}
+	AddCategoryRes struct {
+		Id uint64 `json:"id"`
+	}
+	AddCategoryOut struct {
+		resp.Response
+		Res AddCategoryRes
+	}
+)
+
+func (d *CashflowDeps) AddCategory(ctx context.Context, in AddCategoryIn) (out AddCategoryOut) {
+	var err error
+	out.Response = resp.NewResponse(http.StatusCreated, "", nil)
+
+	if err = ValidateAddCategoryIn(in); err != nil {
+		out.Response = resp.NewResponse(http.StatusUnprocessableEntity, "", err)
+		return
+	}
+
+	category := CategoryModel{
+		Name: in.Name,
+	}
+	if category, err = d.CategoryRepository.Save(ctx, category); err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "save category"))
+		return
+	}
+
+	err = d.Audit.Record(ctx, audit.Event{
+		Action:   audit.ActionCreate,
+		Entity:   "category",
+		EntityId: strconv.FormatUint(category.Id, 10),
+		After:    category,
+	})
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "record audit"))
+		return
+	}
+
+	out.Res.Id = category.Id
+
+	return
+}
+
+type (
+	CategoryOut struct {
+		Id   uint64 `json:"id"`
+		Code string `json:"code"`
+		Name string `json:"name"`
+	}
+	QueryCategoryRes struct {
+		Categories []CategoryOut `json:"categories"`
+	}
+	QueryCategoryOut struct {
+		resp.Response
+		Res QueryCategoryRes
+	}
+)
+
+func (d *CashflowDeps) QueryCategory(ctx context.Context) (out QueryCategoryOut) {
+	out.Response = resp.NewResponse(http.StatusOK, "", nil)
+
+	categories, err := d.CategoryRepository.Query(ctx)
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "query categories"))
+		return
+	}
+
+	outCategories := make([]CategoryOut, len(categories))
+	for i, c := range categories {
+		outCategories[i] = CategoryOut{
+			Id:   c.Id,
+			Code: c.Code.String,
+			Name: c.Name,
+		}
+	}
+
+	out.Res.Categories = outCategories
+
+	return
+}
+
+type (
+	EditCategoryIn struct {
+		Name string `json:"name"`
+	}
+	EditCategoryRes struct {
+		Id uint64 `json:"id"`
+	}
+	EditCategoryOut struct {
+		resp.Response
+		Res EditCategoryRes
+	}
+)
+
+func (d *CashflowDeps) EditCategory(ctx context.Context, pid string, in EditCategoryIn) (out EditCategoryOut) {
+	var err error
+	out.Response = resp.NewResponse(http.StatusOK, "", nil)
+
+	id, err := strconv.ParseUint(pid, 10, 64)
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusNotFound, "", ErrCategoryNotFound)
+		return
+	}
+
+	if err = ValidateEditCategoryIn(in); err != nil {
+		out.Response = resp.NewResponse(http.StatusUnprocessableEntity, "", err)
+		return
+	}
+
+	category, err := d.CategoryRepository.FindById(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		out.Response = resp.NewResponse(http.StatusNotFound, "", ErrCategoryNotFound)
+		return
+	}
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "find category by id"))
+		return
+	}
+
+	before := category
+	category.Name = in.Name
+
+	if err = d.CategoryRepository.UpdateById(ctx, id, category); err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "update category by id"))
+		return
+	}
+
+	err = d.Audit.Record(ctx, audit.Event{
+		Action:   audit.ActionUpdate,
+		Entity:   "category",
+		EntityId: strconv.FormatUint(id, 10),
+		Before:   before,
+		After:    category,
+	})
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "record audit"))
+		return
+	}
+
+	out.Res.Id = id
+
+	return
+}
+
+type (
+	RemoveCategoryRes struct {
+		Id uint64 `json:"id"`
+	}
+	RemoveCategoryOut struct {
+		resp.Response
+		Res RemoveCategoryRes
+	}
+)
+
+// RemoveCategory remove the category, cashflow of the removed category is
+// still reported under the category name. Category used by the system, e.g.
+// dues, can not be removed.
+func (d *CashflowDeps) RemoveCategory(ctx context.Context, pid string) (out RemoveCategoryOut) {
+	var err error
+	out.Response = resp.NewResponse(http.StatusOK, "", nil)
+
+	id, err := strconv.ParseUint(pid, 10, 64)
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusNotFound, "", ErrCategoryNotFound)
+		return
+	}
+
+	category, err := d.CategoryRepository.FindById(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		out.Response = resp.NewResponse(http.StatusNotFound, "", ErrCategoryNotFound)
+		return
+	}
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "find category by id"))
+		return
+	}
+
+	if category.Code.String == DuesCategory {
+		out.Response = resp.NewResponse(http.StatusBadRequest, "", ErrSystemCategory)
+		return
+	}
+
+	if err = d.CategoryRepository.DeleteById(ctx, id); err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "delete category by id"))
+		return
+	}
+
+	err = d.Audit.Record(ctx, audit.Event{
+		Action:   audit.ActionDelete,
+		Entity:   "category",
+		EntityId: strconv.FormatUint(id, 10),
+		Before:   category,
+	})
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "record audit"))
+		return
+	}
+
+	out.Res.Id = id
+
+	return
+}
@@ -0,0 +1,163 @@
+package cashflow_test
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	arbitary "github.com/PA-D3RPLA/d3if43-htt-uhomestay/arbitrary"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/cashflow"
+)
+
+func TestAddCategory(t *testing.T) {
+	testCases := []struct {
+		Name               string
+		ExpectedStatusCode int
+		In                 cashflow.AddCategoryIn
+	}{
+		{
+			Name:               "Add Category Success",
+			ExpectedStatusCode: http.StatusCreated,
+			In: cashflow.AddCategoryIn{
+				Name: "Sewa Peralatan",
+			},
+		},
+		{
+			Name:               "Add Category Without Name Fail",
+			ExpectedStatusCode: http.StatusUnprocessableEntity,
+			In:                 cashflow.AddCategoryIn{},
+		},
+		{
+			Name:               "Add Category with Name over 200 chars Fail",
+			ExpectedStatusCode: http.StatusUnprocessableEntity,
+			In: cashflow.AddCategoryIn{
+				Name: strings.Repeat("a", 201),
+			},
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.Name, func(t *testing.T) {
+			tx, err := db.Begin(context.Background())
+
+			ctx := context.WithValue(context.Background(), arbitary.TrxX{}, tx)
+			res := cashflowDeps.AddCategory(ctx, c.In)
+			tx.Commit(context.Background())
+			tx.Rollback(context.Background())
+
+			if res.StatusCode != c.ExpectedStatusCode {
+				t.Logf("%#v", res)
+				t.Log(err)
+				t.Fatalf("Expected response code %d. Got %d\n", c.ExpectedStatusCode, res.StatusCode)
+			}
+		})
+	}
+}
+
+func TestEditCategory(t *testing.T) {
+	nc, err := categoryRepository.Save(context.Background(), cashflow.CategoryModel{Name: "Lain-lain"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pid := strconv.FormatUint(nc.Id, 10)
+	testCases := []struct {
+		Name               string
+		ExpectedStatusCode int
+		Id                 string
+		In                 cashflow.EditCategoryIn
+	}{
+		{
+			Name:               "Edit Category Success",
+			ExpectedStatusCode: http.StatusOK,
+			Id:                 pid,
+			In: cashflow.EditCategoryIn{
+				Name: "Lainnya",
+			},
+		},
+		{
+			Name:               "Edit Category Without Name Fail",
+			ExpectedStatusCode: http.StatusUnprocessableEntity,
+			Id:                 pid,
+			In:                 cashflow.EditCategoryIn{},
+		},
+		{
+			Name:               "Edit Category, Not Found",
+			ExpectedStatusCode: http.StatusNotFound,
+			Id:                 "999",
+			In: cashflow.EditCategoryIn{
+				Name: "Lainnya",
+			},
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.Name, func(t *testing.T) {
+			tx, err := db.Begin(context.Background())
+
+			ctx := context.WithValue(context.Background(), arbitary.TrxX{}, tx)
+			res := cashflowDeps.EditCategory(ctx, c.Id, c.In)
+			tx.Commit(context.Background())
+			tx.Rollback(context.Background())
+
+			if res.StatusCode != c.ExpectedStatusCode {
+				t.Logf("%#v", res)
+				t.Log(err)
+				t.Fatalf("Expected response code %d. Got %d\n", c.ExpectedStatusCode, res.StatusCode)
+			}
+		})
+	}
+}
+
+func TestRemoveCategory(t *testing.T) {
+	nc, err := categoryRepository.Save(context.Background(), cashflow.CategoryModel{Name: "Sementara"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	duesCategory, err := categoryRepository.FindByCode(context.Background(), cashflow.DuesCategory)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		Name               string
+		ExpectedStatusCode int
+		Id                 string
+	}{
+		{
+			Name:               "Remove Category Success",
+			ExpectedStatusCode: http.StatusOK,
+			Id:                 strconv.FormatUint(nc.Id, 10),
+		},
+		{
+			Name:               "Remove Dues Category Fail",
+			ExpectedStatusCode: http.StatusBadRequest,
+			Id:                 strconv.FormatUint(duesCategory.Id, 10),
+		},
+		{
+			Name:               "Remove Category, Not Found",
+			ExpectedStatusCode: http.StatusNotFound,
+			Id:                 "999",
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.Name, func(t *testing.T) {
+			tx, err := db.Begin(context.Background())
+
+			ctx := context.WithValue(context.Background(), arbitary.TrxX{}, tx)
+			res := cashflowDeps.RemoveCategory(ctx, c.Id)
+			tx.Commit(context.Background())
+			tx.Rollback(context.Background())
+
+			if res.StatusCode != c.ExpectedStatusCode {
+				t.Logf("%#v", res)
+				t.Log(err)
+				t.Fatalf("Expected response code %d. Got %d\n", c.ExpectedStatusCode, res.StatusCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package cashflow
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/sync/errgroup"
+)
+
+var (
+	ErrCategoryNameRequired = errors.New("nama kategori tidak boleh kosong")
+	ErrMaxCategoryName      = errors.New("nama kategori tidak dapat lebih dari 200 karakter")
+)
+
+func ValidateAddCategoryIn(i AddCategoryIn) error {
+	g := new(errgroup.Group)
+
+	g.Go(func() error {
+		if strings.Trim(i.Name, " ") == "" {
+			return ErrCategoryNameRequired
+		}
+		return nil
+	})
+	g.Go(func() error {
+		if utf8.RuneCountInString(i.Name) > 200 {
+			return ErrMaxCategoryName
+		}
+		return nil
+	})
+
+	if err := g.Wait(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func ValidateEditCategoryIn(i EditCategoryIn) error {
+	g := new(errgroup.Group)
+
+	g.Go(func() error {
+		if strings.Trim(i.Name, " ") == "" {
+			return ErrCategoryNameRequired
+		}
+		return nil
+	})
+	g.Go(func() error {
+		if utf8.RuneCountInString(i.Name) > 200 {
+			return ErrMaxCategoryName
+		}
+		return nil
+	})
+
+	if err := g.Wait(); err != nil {
+		return err
+	}
+	return nil
+}
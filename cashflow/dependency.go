@@ -3,13 +3,19 @@ package cashflow
 import (
 	"context"
 	"io"
+	"path"
+	"time"
 
-	"github.com/cloudinary/cloudinary-go/api/uploader"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/audit"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/export"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/storage"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/user"
 	"github.com/getsentry/sentry-go"
 )
 
 type (
 	FileUploader      func(filename string, file io.Reader) (string, error)
+	FileSigner        func(fileUrl string) (string, error)
 	ExceptionCapturer func(exception error)
 	MessageCapturer   func(message string)
 )
@@ -18,32 +24,56 @@ type CashflowDeps struct {
 	CaptureMessage     MessageCapturer
 	CaptureExeption    ExceptionCapturer
 	Upload             FileUploader
+	Sign               FileSigner
+	Letterhead         export.Letterhead
+	Audit              audit.Recorder
 	CashflowRepository *CashflowRepository
+	CategoryRepository *CategoryRepository
+	AccountRepository  *AccountRepository
+	TransferRepository *TransferRepository
+	// OrgPeriodRepository read the organization period bounding the report.
+	OrgPeriodRepository *user.OrgPeriodRepository
 }
 
 func NewDeps(
 	captureMessage MessageCapturer,
 	captureExeption ExceptionCapturer,
 	upload FileUploader,
+	sign FileSigner,
+	letterhead export.Letterhead,
+	auditRecorder audit.Recorder,
 	cashflowRepository *CashflowRepository,
+	categoryRepository *CategoryRepository,
+	accountRepository *AccountRepository,
+	transferRepository *TransferRepository,
+	orgPeriodRepository *user.OrgPeriodRepository,
 ) *CashflowDeps {
 	return &CashflowDeps{
-		Upload:             upload,
-		CashflowRepository: cashflowRepository,
+		CaptureMessage:      captureMessage,
+		CaptureExeption:     captureExeption,
+		Upload:              upload,
+		Sign:                sign,
+		Letterhead:          letterhead,
+		Audit:               auditRecorder,
+		CashflowRepository:  cashflowRepository,
+		CategoryRepository:  categoryRepository,
+		AccountRepository:   accountRepository,
+		TransferRepository:  transferRepository,
+		OrgPeriodRepository: orgPeriodRepository,
 	}
 }
 
-func FileUpload(uploadParams uploader.UploadParams, upload func(ctx context.Context, file interface{}, uploadParams uploader.UploadParams) (*uploader.UploadResult, error)) FileUploader {
+func FileUpload(store storage.Storage, folder string) FileUploader {
 	return func(filename string, file io.Reader) (url string, err error) {
-		ctx := context.Background()
-		uploadParams.PublicID = filename
-
-		resp, err := upload(ctx, file, uploadParams)
-		if err != nil {
-			return "", err
-		}
+		return store.Put(context.Background(), path.Join(folder, filename), file)
+	}
+}
 
-		return resp.SecureURL, nil
+// FileSign create signer of the uploaded file url, the signed url is only valid
+// for the given expiry.
+func FileSign(expiry time.Duration, store storage.Storage) FileSigner {
+	return func(fileUrl string) (string, error) {
+		return store.SignUrl(context.Background(), fileUrl, expiry)
 	}
 }
 
@@ -0,0 +1,32 @@
+package cashflow
+
+import (
+	"net/http"
+
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/export"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/resp"
+	"github.com/pkg/errors"
+)
+
+func (d *CashflowDeps) GetCashflowExport(w http.ResponseWriter, r *http.Request) {
+	qin := ExportCashflowQIn{
+		Format:    r.URL.Query().Get("format"),
+		PeriodId:  r.URL.Query().Get("period_id"),
+		StartDate: r.URL.Query().Get("start_date"),
+		EndDate:   r.URL.Query().Get("end_date"),
+	}
+
+	out := d.ExportCashflow(r.Context(), qin)
+	if out.Error != nil {
+		d.CaptureExeption(out.Error)
+		out.HttpJSON(w, resp.NewHttpBody(nil))
+		return
+	}
+
+	// The status is already sent once the first row is written, so an error
+	// while streaming can only be reported.
+	export.SetHttpHeader(w, out.Res.Format, out.Res.Filename)
+	if err := out.Stream(w); err != nil {
+		d.CaptureExeption(errors.Wrap(err, "stream cashflow export"))
+	}
+}
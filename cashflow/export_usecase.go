@@ -0,0 +1,136 @@
+package cashflow
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/export"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/money"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/resp"
+	"github.com/pkg/errors"
+)
+
+var ledgerColumns = []export.Column{
+	{Title: "Tanggal"},
+	{Title: "Jenis"},
+	{Title: "Kategori"},
+	{Title: "Akun"},
+	{Title: "Keterangan", Width: 3},
+	{Title: "Pemasukan", Numeric: true},
+	{Title: "Pengeluaran", Numeric: true},
+	{Title: "Saldo", Numeric: true},
+}
+
+type (
+	ExportCashflowQIn struct {
+		Format    string
+		PeriodId  string
+		StartDate string
+		EndDate   string
+	}
+	ExportRes struct {
+		Format   export.Format
+		Filename string
+	}
+	ExportCashflowOut struct {
+		resp.Response
+		Res ExportRes
+		// Stream write the ledger to w, it should only be called when there
+		// is no error.
+		Stream func(w io.Writer) error
+	}
+)
+
+// ExportCashflow export the cashflow ledger of the organization period or the
+// date range, the whole ledger is exported when neither is given. Every row
+// has the running balance.
+func (d *CashflowDeps) ExportCashflow(ctx context.Context, qin ExportCashflowQIn) (out ExportCashflowOut) {
+	out.Response = resp.NewResponse(http.StatusOK, "", nil)
+
+	f, err := export.ParseFormat(qin.Format)
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusUnprocessableEntity, "", err)
+		return
+	}
+
+	startDate, endDate, res := d.reportRange(ctx, qin.PeriodId, qin.StartDate, qin.EndDate)
+	if res.Error != nil {
+		out.Response = res
+		return
+	}
+
+	var start, until sql.NullTime
+	var opening money.IDR
+	subtitle := "Seluruh periode"
+	if !startDate.IsZero() {
+		start = sql.NullTime{Time: startDate, Valid: true}
+		until = sql.NullTime{Time: endDate.AddDate(0, 0, 1), Valid: true}
+		subtitle = "Periode " + startDate.Format("02-01-2006") + " s/d " + endDate.Format("02-01-2006")
+
+		opening, err = d.CashflowRepository.SumBalanceBefore(ctx, startDate)
+		if err != nil {
+			out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "sum balance before start date"))
+			return
+		}
+	}
+
+	h := export.Header{
+		Letterhead: d.Letterhead,
+		Title:      "Buku Kas",
+		Subtitle:   subtitle,
+	}
+
+	out.Res = ExportRes{
+		Format:   f,
+		Filename: f.Filename("buku-kas", time.Now()),
+	}
+	out.Stream = func(w io.Writer) error {
+		ew, err := export.NewWriter(w, f, h, ledgerColumns)
+		if err != nil {
+			return errors.Wrap(err, "new export writer")
+		}
+
+		balance := opening
+		if start.Valid {
+			if err := ew.Write([]string{startDate.Format("02-01-2006"), "", "", "", "Saldo Awal", "", "", balance.String()}); err != nil {
+				return errors.Wrap(err, "write opening balance")
+			}
+		}
+
+		err = d.CashflowRepository.IterateLedger(ctx, start, until, func(m LedgerViewModel) error {
+			typ, income, outcome := "Pemasukan", m.IdrAmount.String(), ""
+			if m.Type == Outcome {
+				typ, income, outcome = "Pengeluaran", "", m.IdrAmount.String()
+				balance -= m.IdrAmount
+			} else {
+				balance += m.IdrAmount
+			}
+
+			category := m.CategoryName.String
+			if !m.CategoryName.Valid {
+				category = "Tanpa Kategori"
+			}
+
+			return ew.Write([]string{
+				m.Date.Format("02-01-2006"),
+				typ,
+				category,
+				m.AccountName,
+				m.Note,
+				income,
+				outcome,
+				balance.String(),
+			})
+		})
+		if err != nil {
+			return errors.Wrap(err, "iterate ledger")
+		}
+
+		return ew.Close()
+	}
+
+	return
+}
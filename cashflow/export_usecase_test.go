@@ -0,0 +1,124 @@
+package cashflow_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/cashflow"
+)
+
+func TestExportCashflow(t *testing.T) {
+	err := ClearTables(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	date := func(s string) time.Time {
+		d, _ := time.Parse("2006-01-02", s)
+		return d
+	}
+
+	seeds := []cashflow.CashflowModel{
+		{Date: date("2022-01-10"), IdrAmount: 1000000, Type: cashflow.Income, Note: "Saldo Januari"},
+		{Date: date("2022-02-05"), IdrAmount: 500000, Type: cashflow.Income, Note: "Donasi"},
+		{Date: date("2022-02-28"), IdrAmount: 200000, Type: cashflow.Outcome, Note: "Konsumsi Rapat"},
+	}
+
+	for _, s := range seeds {
+		if _, err = cashflowRepository.Save(context.Background(), s); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	testCases := []struct {
+		Name               string
+		ExpectedStatusCode int
+		In                 cashflow.ExportCashflowQIn
+		ExpectedRows       []string
+	}{
+		{
+			Name:               "Export Cashflow as Csv Success",
+			ExpectedStatusCode: http.StatusOK,
+			In:                 cashflow.ExportCashflowQIn{},
+			ExpectedRows: []string{
+				"10-01-2022,Pemasukan,Tanpa Kategori,Kas Tunai,Saldo Januari,1000000,,1000000",
+				"28-02-2022,Pengeluaran,Tanpa Kategori,Kas Tunai,Konsumsi Rapat,,200000,1300000",
+			},
+		},
+		{
+			Name:               "Export Cashflow by Date Range Success",
+			ExpectedStatusCode: http.StatusOK,
+			In: cashflow.ExportCashflowQIn{
+				Format:    "csv",
+				StartDate: "2022-02-01",
+				EndDate:   "2022-02-28",
+			},
+			ExpectedRows: []string{
+				"01-02-2022,,,,Saldo Awal,,,1000000",
+				"05-02-2022,Pemasukan,Tanpa Kategori,Kas Tunai,Donasi,500000,,1500000",
+			},
+		},
+		{
+			Name:               "Export Cashflow as Pdf Success",
+			ExpectedStatusCode: http.StatusOK,
+			In: cashflow.ExportCashflowQIn{
+				Format: "pdf",
+			},
+		},
+		{
+			Name:               "Export Cashflow as Xlsx Success",
+			ExpectedStatusCode: http.StatusOK,
+			In: cashflow.ExportCashflowQIn{
+				Format: "xlsx",
+			},
+		},
+		{
+			Name:               "Export Cashflow with Unknown Format Fail",
+			ExpectedStatusCode: http.StatusUnprocessableEntity,
+			In: cashflow.ExportCashflowQIn{
+				Format: "doc",
+			},
+		},
+		{
+			Name:               "Export Cashflow with Start Date Only Fail",
+			ExpectedStatusCode: http.StatusUnprocessableEntity,
+			In: cashflow.ExportCashflowQIn{
+				StartDate: "2022-02-01",
+			},
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.Name, func(t *testing.T) {
+			res := cashflowDeps.ExportCashflow(context.Background(), c.In)
+
+			if res.StatusCode != c.ExpectedStatusCode {
+				t.Logf("%#v", res)
+				t.Fatalf("Expected response code %d. Got %d\n", c.ExpectedStatusCode, res.StatusCode)
+			}
+
+			if c.ExpectedStatusCode != http.StatusOK {
+				return
+			}
+
+			var buf bytes.Buffer
+			if err := res.Stream(&buf); err != nil {
+				t.Fatal(err)
+			}
+
+			if buf.Len() == 0 {
+				t.Fatal("Expected exported file not to be empty")
+			}
+
+			for _, row := range c.ExpectedRows {
+				if !strings.Contains(buf.String(), row+"\n") {
+					t.Fatalf("Expected row %q. Got %s\n", row, buf.String())
+				}
+			}
+		})
+	}
+}
@@ -10,6 +10,8 @@ import (
 	"time"
 
 	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/cashflow"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/export"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/user"
 	"github.com/jackc/pgx/v4/pgxpool"
 	"github.com/ory/dockertest/v3"
 	"github.com/ory/dockertest/v3/docker"
@@ -18,12 +20,15 @@ import (
 var (
 	db                 *pgxpool.Pool
 	cashflowRepository *cashflow.CashflowRepository
+	categoryRepository *cashflow.CategoryRepository
+	accountRepository  *cashflow.AccountRepository
+	transferRepository *cashflow.TransferRepository
 	cashflowDeps       *cashflow.CashflowDeps
 	fileName           = "images.jpeg"
 	fileDir            = "./fixture/" + fileName
 	cashflowSeed       = cashflow.CashflowModel{
 		Date:      time.Now(),
-		IdrAmount: "1000000",
+		IdrAmount: 1000000,
 		Type:      cashflow.Income,
 		Note:      "Just Note",
 	}
@@ -33,8 +38,12 @@ var (
 	upload cashflow.FileUploader = func(filename string, file io.Reader) (string, error) {
 		return "", nil
 	}
+	sign cashflow.FileSigner = func(fileUrl string) (string, error) {
+		return fileUrl + "?signed", nil
+	}
 	captureException cashflow.ExceptionCapturer = func(exception error) {}
 	captureMessage   cashflow.MessageCapturer   = func(message string) {}
+	letterhead                                  = export.Letterhead{OrgName: "U-Homestay", OrgAddress: "Bandung"}
 )
 
 func LoadTables(conn *pgxpool.Pool) error {
@@ -76,6 +85,7 @@ func ClearTables(conn *pgxpool.Pool) error {
 	// This should be in order of which table truncate first before the other
 	queries := []string{
 		`TRUNCATE cashflows CASCADE`,
+		`TRUNCATE cashflow_transfers CASCADE`,
 	}
 
 	for _, v := range queries {
@@ -147,11 +157,21 @@ func TestMain(m *testing.M) {
 	}
 
 	cashflowRepository = cashflow.NewRepository(db)
+	categoryRepository = cashflow.NewCategoryRepository(db)
+	accountRepository = cashflow.NewAccountRepository(db)
+	transferRepository = cashflow.NewTransferRepository(db)
 	cashflowDeps = cashflow.NewDeps(
 		captureMessage,
 		captureException,
 		upload,
+		sign,
+		letterhead,
+		nil,
 		cashflowRepository,
+		categoryRepository,
+		accountRepository,
+		transferRepository,
+		user.NewOrgPeriodRepository(db),
 	)
 
 	LoadTables(db)
@@ -0,0 +1,21 @@
+package cashflow
+
+import (
+	"net/http"
+
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/resp"
+)
+
+func (d *CashflowDeps) GetCashflowReport(w http.ResponseWriter, r *http.Request) {
+	qin := CashflowReportQIn{
+		PeriodId:  r.URL.Query().Get("period_id"),
+		StartDate: r.URL.Query().Get("start_date"),
+		EndDate:   r.URL.Query().Get("end_date"),
+	}
+
+	out := d.CashflowReport(r.Context(), qin)
+	if out.Error != nil {
+		d.CaptureExeption(out.Error)
+	}
+	out.HttpJSON(w, resp.NewHttpBody(out.Res))
+}
@@ -0,0 +1,221 @@
+package cashflow
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/money"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/resp"
+	"github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrPeriodNotFound      = errors.New("periode organisasi tidak ditemukan")
+	ErrReportRangeRequired = errors.New("periode organisasi atau tanggal awal dan akhir laporan tidak boleh kosong")
+	ErrReportRange         = errors.New("tanggal akhir laporan tidak boleh sebelum tanggal awal")
+)
+
+type (
+	CashflowReportQIn struct {
+		PeriodId  string
+		StartDate string
+		EndDate   string
+	}
+	CategoryAmtOut struct {
+		CategoryId   uint64 `json:"category_id"`
+		CategoryName string `json:"category_name"`
+		IdrAmount    string `json:"idr_amount"`
+	}
+	AccountReportOut struct {
+		Id             uint64 `json:"id"`
+		Name           string `json:"name"`
+		OpeningBalance string `json:"opening_balance"`
+		ClosingBalance string `json:"closing_balance"`
+	}
+	CashflowReportRes struct {
+		StartDate      string             `json:"start_date"`
+		EndDate        string             `json:"end_date"`
+		OpeningBalance string             `json:"opening_balance"`
+		IncomeTotal    string             `json:"income_total"`
+		OutcomeTotal   string             `json:"outcome_total"`
+		ClosingBalance string             `json:"closing_balance"`
+		Incomes        []CategoryAmtOut   `json:"incomes"`
+		Outcomes       []CategoryAmtOut   `json:"outcomes"`
+		Accounts       []AccountReportOut `json:"accounts"`
+	}
+	CashflowReportOut struct {
+		resp.Response
+		Res CashflowReportRes
+	}
+)
+
+// CashflowReport summarize the cashflow of the organization period, or of the
+// date range when the period is not given. Both start and end date are
+// inclusive.
+func (d *CashflowDeps) CashflowReport(ctx context.Context, qin CashflowReportQIn) (out CashflowReportOut) {
+	out.Response = resp.NewResponse(http.StatusOK, "", nil)
+
+	start, end, res := d.reportRange(ctx, qin.PeriodId, qin.StartDate, qin.EndDate)
+	if res.Error != nil {
+		out.Response = res
+		return
+	}
+	if start.IsZero() {
+		out.Response = resp.NewResponse(http.StatusUnprocessableEntity, "", ErrReportRangeRequired)
+		return
+	}
+
+	// Cashflow is dated by day, so the end date is included by querying until
+	// before the next day.
+	until := end.AddDate(0, 0, 1)
+
+	opening, err := d.CashflowRepository.SumBalanceBefore(ctx, start)
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "sum balance before start date"))
+		return
+	}
+
+	amts, err := d.CashflowRepository.SumAmtByCategory(ctx, start, until)
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "sum amount by category"))
+		return
+	}
+
+	openingAccounts, err := d.AccountRepository.QueryBalance(ctx, sql.NullTime{Time: start, Valid: true})
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "query opening account balance"))
+		return
+	}
+
+	closingAccounts, err := d.AccountRepository.QueryBalance(ctx, sql.NullTime{Time: until, Valid: true})
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "query closing account balance"))
+		return
+	}
+
+	var incomeTotal, outcomeTotal money.IDR
+	incomes := []CategoryAmtOut{}
+	outcomes := []CategoryAmtOut{}
+	for _, a := range amts {
+		categoryName := a.CategoryName.String
+		if !a.CategoryId.Valid {
+			categoryName = "Tanpa Kategori"
+		}
+
+		amtOut := CategoryAmtOut{
+			CategoryId:   uint64(a.CategoryId.Int64),
+			CategoryName: categoryName,
+			IdrAmount:    a.IdrAmount.String(),
+		}
+
+		if a.Type == Income {
+			incomeTotal += a.IdrAmount
+			incomes = append(incomes, amtOut)
+		} else {
+			outcomeTotal += a.IdrAmount
+			outcomes = append(outcomes, amtOut)
+		}
+	}
+
+	// Account created within the period has no opening balance, while account
+	// emptied and removed within the period has no closing balance.
+	openingBalances := make(map[uint64]money.IDR, len(openingAccounts))
+	for _, a := range openingAccounts {
+		openingBalances[a.Id] = a.Balance
+	}
+
+	accounts := make([]AccountReportOut, 0, len(closingAccounts))
+	for _, a := range closingAccounts {
+		accounts = append(accounts, AccountReportOut{
+			Id:             a.Id,
+			Name:           a.Name,
+			OpeningBalance: openingBalances[a.Id].String(),
+			ClosingBalance: a.Balance.String(),
+		})
+		delete(openingBalances, a.Id)
+	}
+	for _, a := range openingAccounts {
+		if _, ok := openingBalances[a.Id]; !ok {
+			continue
+		}
+
+		accounts = append(accounts, AccountReportOut{
+			Id:             a.Id,
+			Name:           a.Name,
+			OpeningBalance: a.Balance.String(),
+			ClosingBalance: money.IDR(0).String(),
+		})
+	}
+
+	out.Res = CashflowReportRes{
+		StartDate:      start.Format("2006-01-02"),
+		EndDate:        end.Format("2006-01-02"),
+		OpeningBalance: opening.String(),
+		IncomeTotal:    incomeTotal.String(),
+		OutcomeTotal:   outcomeTotal.String(),
+		ClosingBalance: (opening + incomeTotal - outcomeTotal).String(),
+		Incomes:        incomes,
+		Outcomes:       outcomes,
+		Accounts:       accounts,
+	}
+
+	return
+}
+
+// reportRange return the date range of the organization period, or of the
+// start and end date when the period is not given. Both date is zero when
+// neither is given.
+func (d *CashflowDeps) reportRange(ctx context.Context, periodId, startDate, endDate string) (start, end time.Time, res resp.Response) {
+	var err error
+	res = resp.NewResponse(http.StatusOK, "", nil)
+
+	switch {
+	case periodId != "":
+		id, err := strconv.ParseUint(periodId, 10, 64)
+		if err != nil {
+			res = resp.NewResponse(http.StatusNotFound, "", ErrPeriodNotFound)
+			return
+		}
+
+		period, err := d.OrgPeriodRepository.FindUndeletedById(ctx, id)
+		if errors.Is(err, pgx.ErrNoRows) {
+			res = resp.NewResponse(http.StatusNotFound, "", ErrPeriodNotFound)
+			return
+		}
+		if err != nil {
+			res = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "find org period by id"))
+			return
+		}
+
+		start = truncateDay(period.StartDate)
+		end = truncateDay(period.EndDate)
+	case startDate != "" && endDate != "":
+		if start, err = time.Parse("2006-01-02", startDate); err != nil {
+			res = resp.NewResponse(http.StatusUnprocessableEntity, "", ErrDateFormat)
+			return
+		}
+		if end, err = time.Parse("2006-01-02", endDate); err != nil {
+			res = resp.NewResponse(http.StatusUnprocessableEntity, "", ErrDateFormat)
+			return
+		}
+	case startDate != "" || endDate != "":
+		res = resp.NewResponse(http.StatusUnprocessableEntity, "", ErrReportRangeRequired)
+		return
+	default:
+		return
+	}
+
+	if end.Before(start) {
+		res = resp.NewResponse(http.StatusUnprocessableEntity, "", ErrReportRange)
+	}
+
+	return
+}
+
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,121 @@
+package cashflow_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	arbitary "github.com/PA-D3RPLA/d3if43-htt-uhomestay/arbitrary"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/cashflow"
+)
+
+func TestCashflowReport(t *testing.T) {
+	err := ClearTables(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	duesCategory, err := categoryRepository.FindByCode(context.Background(), cashflow.DuesCategory)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bank, err := accountRepository.Save(context.Background(), cashflow.AccountModel{Name: "Bank Laporan"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	date := func(s string) time.Time {
+		d, _ := time.Parse("2006-01-02", s)
+		return d
+	}
+
+	seeds := []cashflow.CashflowModel{
+		{Date: date("2022-01-10"), IdrAmount: 1000000, Type: cashflow.Income},
+		{Date: date("2022-02-05"), IdrAmount: 500000, Type: cashflow.Income, AccountId: bank.Id},
+		{Date: date("2022-02-28"), IdrAmount: 200000, Type: cashflow.Outcome},
+		{Date: date("2022-03-01"), IdrAmount: 300, Type: cashflow.Income},
+	}
+	seeds[1].CategoryId.Scan(int64(duesCategory.Id))
+
+	for _, s := range seeds {
+		if _, err = cashflowRepository.Save(context.Background(), s); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	testCases := []struct {
+		Name               string
+		ExpectedStatusCode int
+		In                 cashflow.CashflowReportQIn
+		ExpectedRes        cashflow.CashflowReportRes
+	}{
+		{
+			Name:               "Cashflow Report by Date Range Success",
+			ExpectedStatusCode: http.StatusOK,
+			In: cashflow.CashflowReportQIn{
+				StartDate: "2022-02-01",
+				EndDate:   "2022-02-28",
+			},
+			ExpectedRes: cashflow.CashflowReportRes{
+				OpeningBalance: "1000000",
+				IncomeTotal:    "500000",
+				OutcomeTotal:   "200000",
+				ClosingBalance: "1300000",
+			},
+		},
+		{
+			Name:               "Cashflow Report Without Range Fail",
+			ExpectedStatusCode: http.StatusUnprocessableEntity,
+			In:                 cashflow.CashflowReportQIn{},
+		},
+		{
+			Name:               "Cashflow Report with End Date Before Start Date Fail",
+			ExpectedStatusCode: http.StatusUnprocessableEntity,
+			In: cashflow.CashflowReportQIn{
+				StartDate: "2022-02-28",
+				EndDate:   "2022-02-01",
+			},
+		},
+		{
+			Name:               "Cashflow Report Period Not Found",
+			ExpectedStatusCode: http.StatusNotFound,
+			In: cashflow.CashflowReportQIn{
+				PeriodId: "999",
+			},
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.Name, func(t *testing.T) {
+			tx, err := db.Begin(context.Background())
+
+			ctx := context.WithValue(context.Background(), arbitary.TrxX{}, tx)
+			res := cashflowDeps.CashflowReport(ctx, c.In)
+			tx.Commit(context.Background())
+			tx.Rollback(context.Background())
+
+			if res.StatusCode != c.ExpectedStatusCode {
+				t.Logf("%#v", res)
+				t.Log(err)
+				t.Fatalf("Expected response code %d. Got %d\n", c.ExpectedStatusCode, res.StatusCode)
+			}
+
+			if c.ExpectedStatusCode != http.StatusOK {
+				return
+			}
+
+			if res.Res.OpeningBalance != c.ExpectedRes.OpeningBalance ||
+				res.Res.IncomeTotal != c.ExpectedRes.IncomeTotal ||
+				res.Res.OutcomeTotal != c.ExpectedRes.OutcomeTotal ||
+				res.Res.ClosingBalance != c.ExpectedRes.ClosingBalance {
+				t.Fatalf("Expected report %#v. Got %#v\n", c.ExpectedRes, res.Res)
+			}
+
+			if len(res.Res.Incomes) != 1 || res.Res.Incomes[0].CategoryId != duesCategory.Id {
+				t.Fatalf("Expected income of dues category. Got %#v\n", res.Res.Incomes)
+			}
+		})
+	}
+}
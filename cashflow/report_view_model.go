@@ -0,0 +1,25 @@
+package cashflow
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/money"
+)
+
+type CategoryAmtViewModel struct {
+	Type         CashflowType
+	CategoryId   sql.NullInt64
+	CategoryName sql.NullString
+	IdrAmount    money.IDR
+}
+
+type LedgerViewModel struct {
+	Id           uint64
+	Date         time.Time
+	Type         CashflowType
+	CategoryName sql.NullString
+	AccountName  string
+	IdrAmount    money.IDR
+	Note         string
+}
@@ -0,0 +1,20 @@
+package cashflow
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/money"
+)
+
+type TransferModel struct {
+	Id            uint64
+	Date          time.Time
+	IdrAmount     money.IDR
+	FromAccountId uint64
+	ToAccountId   uint64
+	Note          string
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	DeletedAt     sql.NullTime
+}
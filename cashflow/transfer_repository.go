@@ -0,0 +1,194 @@
+package cashflow
+
+import (
+	"context"
+	"time"
+
+	arbitary "github.com/PA-D3RPLA/d3if43-htt-uhomestay/arbitrary"
+
+	"github.com/georgysavva/scany/pgxscan"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+type TransferRepository struct {
+	PostgreDb *pgxpool.Pool
+}
+
+func NewTransferRepository(postgreDb *pgxpool.Pool) *TransferRepository {
+	return &TransferRepository{
+		PostgreDb: postgreDb,
+	}
+}
+
+type (
+	TransferExecutor   func(ctx context.Context, sql string, arguments ...interface{}) (commandTag pgconn.CommandTag, err error)
+	TransferQuerierRow func(ctx context.Context, sql string, args ...interface{}) pgx.Row
+	TransferQuerier    func(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+)
+
+func (r *TransferRepository) Save(ctx context.Context, m TransferModel) (nm TransferModel, err error) {
+	sqlQuery := `
+		INSERT INTO cashflow_transfers (
+			date,
+			idr_amount,
+			from_account_id,
+			to_account_id,
+			note,
+			created_at,
+			updated_at,
+			deleted_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id
+	`
+
+	var queryRow TransferQuerierRow
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		queryRow = tx.QueryRow
+	} else {
+		queryRow = r.PostgreDb.QueryRow
+	}
+
+	var lastInsertId uint64
+	t := time.Now()
+
+	err = queryRow(
+		context.Background(),
+		sqlQuery,
+		m.Date,
+		m.IdrAmount,
+		m.FromAccountId,
+		m.ToAccountId,
+		m.Note,
+		t,
+		t,
+		nil,
+	).Scan(&lastInsertId)
+
+	if err != nil {
+		return TransferModel{}, err
+	}
+
+	m.Id = lastInsertId
+	m.CreatedAt = t
+	m.UpdatedAt = t
+
+	return m, nil
+}
+
+func (r *TransferRepository) FindById(ctx context.Context, id uint64) (m TransferModel, err error) {
+	querystr := `
+		SELECT
+			id,
+			date,
+			idr_amount,
+			from_account_id,
+			to_account_id,
+			note,
+			created_at,
+			updated_at,
+			deleted_at
+		FROM cashflow_transfers
+		WHERE deleted_at IS NULL
+			AND id = $1
+	`
+
+	var query TransferQuerier
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		query = tx.Query
+	} else {
+		query = r.PostgreDb.Query
+	}
+
+	rows, err := query(
+		context.Background(),
+		querystr,
+		id,
+	)
+	if err != nil {
+		return TransferModel{}, err
+	}
+
+	if err = pgxscan.ScanOne(&m, rows); err != nil {
+		return TransferModel{}, err
+	}
+
+	return m, nil
+}
+
+func (r *TransferRepository) DeleteById(ctx context.Context, id uint64) error {
+	sqlQuery := `
+		UPDATE cashflow_transfers
+		SET deleted_at = $1
+		WHERE id = $2
+	`
+
+	var exec TransferExecutor
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		exec = tx.Exec
+	} else {
+		exec = r.PostgreDb.Exec
+	}
+
+	_, err := exec(
+		context.Background(),
+		sqlQuery,
+		time.Now(),
+		id,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *TransferRepository) Query(ctx context.Context, id, limit int64) ([]TransferModel, error) {
+	fromId := "id > $1"
+	if id != 0 {
+		fromId = "id < $1"
+	}
+
+	sqlQuery := `
+		SELECT
+			id,
+			date,
+			idr_amount,
+			from_account_id,
+			to_account_id,
+			note,
+			created_at,
+			updated_at,
+			deleted_at
+		FROM cashflow_transfers
+		WHERE deleted_at IS NULL
+			AND ` + fromId + `
+		ORDER BY id DESC
+		LIMIT $2
+	`
+
+	rows, _ := r.PostgreDb.Query(
+		context.Background(),
+		sqlQuery,
+		id,
+		limit,
+	)
+	defer rows.Close()
+
+	var mps []*TransferModel
+	if err := pgxscan.ScanAll(&mps, rows); err != nil {
+		return []TransferModel{}, err
+	}
+
+	ms := make([]TransferModel, len(mps))
+	for i, m := range mps {
+		ms[i] = *m
+	}
+
+	return ms, nil
+}
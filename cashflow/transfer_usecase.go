@@ -0,0 +1,204 @@
+package cashflow
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/audit"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/money"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/resp"
+	"github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+)
+
+var ErrTransferNotFound = errors.New("pemindahan saldo tidak ditemukan")
+
+type (
+	AddTransferIn struct {
+		Date          string `json:"date"`
+		IdrAmount     string `json:"idr_amount"`
+		FromAccountId uint64 `json:"from_account_id"`
+		ToAccountId   uint64 `json:"to_account_id"`
+		Note          string `json:"note"`
+	}
+	AddTransferRes struct {
+		Id uint64 `json:"id"`
+	}
+	AddTransferOut struct {
+		resp.Response
+		Res AddTransferRes
+	}
+)
+
+// AddTransfer move balance between account, transfer does not change the
+// total cash so it is not recorded as income or outcome.
+func (d *CashflowDeps) AddTransfer(ctx context.Context, in AddTransferIn) (out AddTransferOut) {
+	var err error
+	out.Response = resp.NewResponse(http.StatusCreated, "", nil)
+
+	if err = ValidateAddTransferIn(in); err != nil {
+		out.Response = resp.NewResponse(http.StatusUnprocessableEntity, "", err)
+		return
+	}
+
+	date, err := time.Parse("2006-01-02", in.Date)
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusUnprocessableEntity, "", ErrDateFormat)
+		return
+	}
+
+	for _, accountId := range []uint64{in.FromAccountId, in.ToAccountId} {
+		_, err = d.AccountRepository.FindById(ctx, accountId)
+		if errors.Is(err, pgx.ErrNoRows) {
+			out.Response = resp.NewResponse(http.StatusUnprocessableEntity, "", ErrAccountNotFound)
+			return
+		}
+		if err != nil {
+			out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "find account by id"))
+			return
+		}
+	}
+
+	amount, _ := money.ParseIDR(in.IdrAmount)
+
+	transfer := TransferModel{
+		Date:          date,
+		IdrAmount:     amount,
+		FromAccountId: in.FromAccountId,
+		ToAccountId:   in.ToAccountId,
+		Note:          in.Note,
+	}
+
+	if transfer, err = d.TransferRepository.Save(ctx, transfer); err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "save transfer"))
+		return
+	}
+
+	err = d.Audit.Record(ctx, audit.Event{
+		Action:   audit.ActionCreate,
+		Entity:   "transfer",
+		EntityId: strconv.FormatUint(transfer.Id, 10),
+		After:    transfer,
+	})
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "record audit"))
+		return
+	}
+
+	out.Res.Id = transfer.Id
+
+	return
+}
+
+type (
+	TransferOut struct {
+		Id            uint64 `json:"id"`
+		Date          string `json:"date"`
+		IdrAmount     string `json:"idr_amount"`
+		FromAccountId uint64 `json:"from_account_id"`
+		ToAccountId   uint64 `json:"to_account_id"`
+		Note          string `json:"note"`
+	}
+	QueryTransferRes struct {
+		Cursor    int64         `json:"cursor"`
+		Transfers []TransferOut `json:"transfers"`
+	}
+	QueryTransferOut struct {
+		resp.Response
+		Res QueryTransferRes
+	}
+)
+
+func (d *CashflowDeps) QueryTransfer(ctx context.Context, cursor, limit string) (out QueryTransferOut) {
+	out.Response = resp.NewResponse(http.StatusOK, "", nil)
+
+	fromCursor, _ := strconv.ParseInt(cursor, 10, 64)
+	nlimit, _ := strconv.ParseInt(limit, 10, 64)
+	if nlimit == 0 {
+		nlimit = 25
+	}
+
+	transfers, err := d.TransferRepository.Query(ctx, fromCursor, nlimit)
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "query transfers"))
+		return
+	}
+	tLen := len(transfers)
+
+	var nextCursor int64
+	if tLen != 0 {
+		nextCursor = int64(transfers[tLen-1].Id)
+	}
+
+	outTransfers := make([]TransferOut, tLen)
+	for i, t := range transfers {
+		outTransfers[i] = TransferOut{
+			Id:            t.Id,
+			Date:          t.Date.Format("2006-01-02"),
+			IdrAmount:     t.IdrAmount.String(),
+			FromAccountId: t.FromAccountId,
+			ToAccountId:   t.ToAccountId,
+			Note:          t.Note,
+		}
+	}
+
+	out.Res = QueryTransferRes{
+		Cursor:    nextCursor,
+		Transfers: outTransfers,
+	}
+
+	return
+}
+
+type (
+	RemoveTransferRes struct {
+		Id uint64 `json:"id"`
+	}
+	RemoveTransferOut struct {
+		resp.Response
+		Res RemoveTransferRes
+	}
+)
+
+func (d *CashflowDeps) RemoveTransfer(ctx context.Context, pid string) (out RemoveTransferOut) {
+	var err error
+	out.Response = resp.NewResponse(http.StatusOK, "", nil)
+
+	id, err := strconv.ParseUint(pid, 10, 64)
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusNotFound, "", ErrTransferNotFound)
+		return
+	}
+
+	transfer, err := d.TransferRepository.FindById(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		out.Response = resp.NewResponse(http.StatusNotFound, "", ErrTransferNotFound)
+		return
+	}
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "find transfer by id"))
+		return
+	}
+
+	if err = d.TransferRepository.DeleteById(ctx, id); err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "delete transfer by id"))
+		return
+	}
+
+	err = d.Audit.Record(ctx, audit.Event{
+		Action:   audit.ActionDelete,
+		Entity:   "transfer",
+		EntityId: strconv.FormatUint(id, 10),
+		Before:   transfer,
+	})
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "record audit"))
+		return
+	}
+
+	out.Res.Id = id
+
+	return
+}
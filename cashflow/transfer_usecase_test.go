@@ -0,0 +1,155 @@
+package cashflow_test
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	arbitary "github.com/PA-D3RPLA/d3if43-htt-uhomestay/arbitrary"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/cashflow"
+)
+
+func TestAddTransfer(t *testing.T) {
+	err := ClearTables(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	from, err := accountRepository.Save(context.Background(), cashflow.AccountModel{Name: "Kas Asal"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	to, err := accountRepository.Save(context.Background(), cashflow.AccountModel{Name: "Kas Tujuan"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		Name               string
+		ExpectedStatusCode int
+		In                 cashflow.AddTransferIn
+	}{
+		{
+			Name:               "Add Transfer Success",
+			ExpectedStatusCode: http.StatusCreated,
+			In: cashflow.AddTransferIn{
+				Date:          time.Now().Format("2006-01-02"),
+				IdrAmount:     "50000",
+				FromAccountId: from.Id,
+				ToAccountId:   to.Id,
+				Note:          "Setor ke bank",
+			},
+		},
+		{
+			Name:               "Add Transfer to the Same Account Fail",
+			ExpectedStatusCode: http.StatusUnprocessableEntity,
+			In: cashflow.AddTransferIn{
+				Date:          time.Now().Format("2006-01-02"),
+				IdrAmount:     "50000",
+				FromAccountId: from.Id,
+				ToAccountId:   from.Id,
+			},
+		},
+		{
+			Name:               "Add Transfer with Zero Idr Amount Fail",
+			ExpectedStatusCode: http.StatusUnprocessableEntity,
+			In: cashflow.AddTransferIn{
+				Date:          time.Now().Format("2006-01-02"),
+				IdrAmount:     "0",
+				FromAccountId: from.Id,
+				ToAccountId:   to.Id,
+			},
+		},
+		{
+			Name:               "Add Transfer to Unknown Account Fail",
+			ExpectedStatusCode: http.StatusUnprocessableEntity,
+			In: cashflow.AddTransferIn{
+				Date:          time.Now().Format("2006-01-02"),
+				IdrAmount:     "50000",
+				FromAccountId: from.Id,
+				ToAccountId:   999,
+			},
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.Name, func(t *testing.T) {
+			tx, err := db.Begin(context.Background())
+
+			ctx := context.WithValue(context.Background(), arbitary.TrxX{}, tx)
+			res := cashflowDeps.AddTransfer(ctx, c.In)
+			tx.Commit(context.Background())
+			tx.Rollback(context.Background())
+
+			if res.StatusCode != c.ExpectedStatusCode {
+				t.Logf("%#v", res)
+				t.Log(err)
+				t.Fatalf("Expected response code %d. Got %d\n", c.ExpectedStatusCode, res.StatusCode)
+			}
+		})
+	}
+}
+
+func TestRemoveTransfer(t *testing.T) {
+	err := ClearTables(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	from, err := accountRepository.Save(context.Background(), cashflow.AccountModel{Name: "Kas Asal"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	to, err := accountRepository.Save(context.Background(), cashflow.AccountModel{Name: "Kas Tujuan"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nt, err := transferRepository.Save(context.Background(), cashflow.TransferModel{
+		Date:          time.Now(),
+		IdrAmount:     50000,
+		FromAccountId: from.Id,
+		ToAccountId:   to.Id,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		Name               string
+		ExpectedStatusCode int
+		Id                 string
+	}{
+		{
+			Name:               "Remove Transfer Success",
+			ExpectedStatusCode: http.StatusOK,
+			Id:                 strconv.FormatUint(nt.Id, 10),
+		},
+		{
+			Name:               "Remove Transfer, Not Found",
+			ExpectedStatusCode: http.StatusNotFound,
+			Id:                 "999",
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.Name, func(t *testing.T) {
+			tx, err := db.Begin(context.Background())
+
+			ctx := context.WithValue(context.Background(), arbitary.TrxX{}, tx)
+			res := cashflowDeps.RemoveTransfer(ctx, c.Id)
+			tx.Commit(context.Background())
+			tx.Rollback(context.Background())
+
+			if res.StatusCode != c.ExpectedStatusCode {
+				t.Logf("%#v", res)
+				t.Log(err)
+				t.Fatalf("Expected response code %d. Got %d\n", c.ExpectedStatusCode, res.StatusCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package cashflow
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/money"
+	"golang.org/x/sync/errgroup"
+)
+
+var (
+	ErrFromAccountRequired = errors.New("akun kas asal tidak boleh kosong")
+	ErrToAccountRequired   = errors.New("akun kas tujuan tidak boleh kosong")
+	ErrSameAccount         = errors.New("akun kas asal dan tujuan tidak boleh sama")
+	ErrZeroIdrAmount       = errors.New("jumlah nominal rupiah harus lebih dari 0")
+)
+
+func ValidateAddTransferIn(i AddTransferIn) error {
+	g := new(errgroup.Group)
+
+	g.Go(func() error {
+		if strings.Trim(i.Date, " ") == "" {
+			return ErrDateRequired
+		}
+		return nil
+	})
+	g.Go(func() error {
+		if strings.Trim(i.IdrAmount, " ") == "" {
+			return ErrIdrAmountRequired
+		}
+		return nil
+	})
+	g.Go(func() error {
+		if strings.Trim(i.IdrAmount, " ") == "" {
+			return nil
+		}
+
+		amount, err := money.ParseIDR(i.IdrAmount)
+		if err != nil {
+			return err
+		}
+		if amount == 0 {
+			return ErrZeroIdrAmount
+		}
+		return nil
+	})
+	g.Go(func() error {
+		if i.FromAccountId == 0 {
+			return ErrFromAccountRequired
+		}
+		return nil
+	})
+	g.Go(func() error {
+		if i.ToAccountId == 0 {
+			return ErrToAccountRequired
+		}
+		return nil
+	})
+	g.Go(func() error {
+		if i.FromAccountId != 0 && i.FromAccountId == i.ToAccountId {
+			return ErrSameAccount
+		}
+		return nil
+	})
+
+	if err := g.Wait(); err != nil {
+		return err
+	}
+	return nil
+}
@@ -72,12 +72,15 @@ type (
 		ContentText string `json:"content_text"`
 	}
 	MembersDuesOut struct {
-		Id            int64  `json:"id"`
-		MemberId      string `json:"member_id"`
-		Status        string `json:"status"`
-		Name          string `json:"name"`
-		ProfilePicUrl string `json:"profile_pic_url"`
-		PayDate       string `json:"pay_date"`
+		Id                 int64  `json:"id"`
+		MemberId           string `json:"member_id"`
+		Status             string `json:"status"`
+		IdrAmount          string `json:"idr_amount"`
+		PaidIdrAmount      string `json:"paid_idr_amount"`
+		RemainingIdrAmount string `json:"remaining_idr_amount"`
+		Name               string `json:"name"`
+		ProfilePicUrl      string `json:"profile_pic_url"`
+		PayDate            string `json:"pay_date"`
 	}
 	FindOrgPeriodGoalRes struct {
 		Id          int64  `json:"id"`
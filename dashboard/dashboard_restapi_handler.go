@@ -3,7 +3,9 @@ package dashboard
 import (
 	"net/http"
 
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/jwt"
 	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/resp"
+	"github.com/go-chi/chi/v5"
 )
 
 func (d *DashboardDeps) GetPrivateDashboard(w http.ResponseWriter, r *http.Request) {
@@ -21,3 +23,27 @@ func (d *DashboardDeps) GetPublicDashboard(w http.ResponseWriter, r *http.Reques
 	}
 	out.HttpJSON(w, resp.NewHttpBody(out.Res))
 }
+
+func (d *DashboardDeps) GetFile(w http.ResponseWriter, r *http.Request) {
+	var uid string
+	if jwt.HasClaims(r) {
+		var jwtPayload jwt.JwtPrivateClaim
+		if err := jwt.DecodeCustomClaims(r, &jwtPayload); err != nil {
+			d.CaptureExeption(err)
+			resp.NewResponse(http.StatusInternalServerError, "", err).HttpJSON(w, nil)
+			return
+		}
+		uid = jwtPayload.Uid
+	}
+
+	kind := chi.URLParam(r, "kind")
+	id := chi.URLParam(r, "id")
+	out := d.FindFile(r.Context(), kind, id, uid)
+	if out.Error != nil {
+		d.CaptureExeption(out.Error)
+		out.HttpJSON(w, resp.NewHttpBody(out.Res))
+		return
+	}
+
+	http.Redirect(w, r, out.Res.Url, http.StatusFound)
+}
@@ -6,6 +6,11 @@ import (
 
 	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/dues"
 	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/resp"
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrFileKindNotFound = errors.New("jenis file tidak ditemukan")
 )
 
 type (
@@ -77,7 +82,7 @@ func (d *DashboardDeps) GetPrivate(ctx context.Context) (out PrivateOut) {
 	dt := make(chan int64)
 	dr := make(chan resp.Response)
 	go func(ctx context.Context, do chan []DocumentOut, dt chan int64, res chan resp.Response) {
-		out := d.QueryDocument(ctx, "", "", "999")
+		out := d.QueryDocument(ctx, "", "", "999", true)
 
 		dc := make([]DocumentOut, 0)
 		for _, v := range out.Res.Documents {
@@ -138,7 +143,11 @@ func (d *DashboardDeps) GetPrivate(ctx context.Context) (out PrivateOut) {
 
 		ps := make([]PositionOut, l)
 		for i := range ps {
-			ps[i] = PositionOut(out.Res.Positions[i])
+			ps[i] = PositionOut{
+				Level: out.Res.Positions[i].Level,
+				Id:    out.Res.Positions[i].Id,
+				Name:  out.Res.Positions[i].Name,
+			}
 		}
 
 		p <- ps
@@ -294,11 +303,11 @@ func (d *DashboardDeps) GetPublic(ctx context.Context) (out PublicOut) {
 	do := make(chan []DocumentOut)
 	dr := make(chan resp.Response)
 	go func(ctx context.Context, do chan []DocumentOut, res chan resp.Response) {
-		out := d.QueryDocument(ctx, "", "", "999")
+		out := d.QueryDocument(ctx, "", "", "999", false)
 
 		dc := make([]DocumentOut, 0)
 		for _, v := range out.Res.Documents {
-			if !v.IsPrivate && v.Type == Filetype.String {
+			if v.Type == Filetype.String {
 				dc = append(dc, DocumentOut(v))
 			}
 
@@ -416,3 +425,48 @@ func (d *DashboardDeps) GetPublic(ctx context.Context) (out PublicOut) {
 
 	return
 }
+
+type (
+	FileRes struct {
+		Url string `json:"url"`
+	}
+	FileOut struct {
+		resp.Response
+		Res FileRes
+	}
+)
+
+// FindFile return short-lived signed url of the file owned by the record of the
+// given kind, the caller rights is checked against the owning record.
+func (d *DashboardDeps) FindFile(ctx context.Context, kind, id, uid string) (out FileOut) {
+	out.Response = resp.NewResponse(http.StatusOK, "", nil)
+
+	isMember := uid != ""
+
+	switch kind {
+	case "documents":
+		o := d.FindDocumentFile(ctx, id, isMember)
+		out.Response, out.Res = o.Response, FileRes(o.Res)
+	case "member_dues":
+		o := d.FindMemberDuesFile(ctx, id, uid)
+		out.Response, out.Res = o.Response, FileRes(o.Res)
+	case "dues_payments":
+		o := d.FindDuesPaymentFile(ctx, id, uid)
+		out.Response, out.Res = o.Response, FileRes(o.Res)
+	case "cashflows":
+		// The dues cashflow show the prove file of its payment, which is
+		// only for the member paying or the dues verifier.
+		o := d.FindCashflowPaymentFile(ctx, id, uid)
+		out.Response, out.Res = o.Response, FileRes(o.Res)
+		if o.StatusCode != http.StatusNotFound {
+			break
+		}
+
+		co := d.FindCashflowFile(ctx, id, isMember)
+		out.Response, out.Res = co.Response, FileRes(co.Res)
+	default:
+		out.Response = resp.NewResponse(http.StatusNotFound, "", ErrFileKindNotFound)
+	}
+
+	return
+}
@@ -1,12 +1,16 @@
 package dashboard
 
 import (
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/audit"
 	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/blog"
 	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/cashflow"
 	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/document"
 	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/dues"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/filegc"
 	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/history"
 	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/image"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/notifications"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/revision"
 	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/user"
 	"github.com/getsentry/sentry-go"
 )
@@ -26,6 +30,10 @@ type DashboardDeps struct {
 	*cashflow.CashflowDeps
 	*dues.DuesDeps
 	*user.UserDeps
+	*filegc.FileGcDeps
+	*notifications.NotificationDeps
+	*audit.AuditDeps
+	*revision.RevisionDeps
 }
 
 func NewDeps(
@@ -38,17 +46,25 @@ func NewDeps(
 	cashflowDeps *cashflow.CashflowDeps,
 	duesDeps *dues.DuesDeps,
 	userDeps *user.UserDeps,
+	fileGcDeps *filegc.FileGcDeps,
+	notificationDeps *notifications.NotificationDeps,
+	auditDeps *audit.AuditDeps,
+	revisionDeps *revision.RevisionDeps,
 ) *DashboardDeps {
 	return &DashboardDeps{
-		CaptureMessage:  captureMessage,
-		CaptureExeption: captureExeption,
-		HistoryDeps:     historyDeps,
-		ImageDeps:       imageDeps,
-		DocumentDeps:    documentDeps,
-		BlogDeps:        blogDeps,
-		CashflowDeps:    cashflowDeps,
-		DuesDeps:        duesDeps,
-		UserDeps:        userDeps,
+		CaptureMessage:   captureMessage,
+		CaptureExeption:  captureExeption,
+		HistoryDeps:      historyDeps,
+		ImageDeps:        imageDeps,
+		DocumentDeps:     documentDeps,
+		BlogDeps:         blogDeps,
+		CashflowDeps:     cashflowDeps,
+		DuesDeps:         duesDeps,
+		UserDeps:         userDeps,
+		FileGcDeps:       fileGcDeps,
+		NotificationDeps: notificationDeps,
+		AuditDeps:        auditDeps,
+		RevisionDeps:     revisionDeps,
 	}
 }
 
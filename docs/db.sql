@@ -12,6 +12,7 @@ CREATE TABLE IF NOT EXISTS members (
   password VARCHAR(200) DEFAULT '' NOT NULL,
   is_admin BOOLEAN DEFAULT false NOT NULL,
   is_approved BOOLEAN DEFAULT false NOT NULL,
+  approved_at TIMESTAMP DEFAULT NULL,
   created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
   updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
   deleted_at TIMESTAMP DEFAULT NULL,
@@ -99,6 +100,8 @@ CREATE INDEX documents_textsearch_idx ON documents USING GIN (textsearchable_ind
 
 CREATE INDEX documents_textrank_idx ON documents USING GIN (textrank_index_col);
 
+CREATE TYPE blogstatus AS ENUM ('draft', 'scheduled', 'published', 'archived');
+
 CREATE TABLE IF NOT EXISTS blogs (
   id BIGSERIAL PRIMARY KEY,
   title VARCHAR(200) DEFAULT '' NOT NULL,
@@ -107,6 +110,8 @@ CREATE TABLE IF NOT EXISTS blogs (
   content jsonb DEFAULT '{}'::jsonb NOT NULL,
   content_text TEXT DEFAULT '' NOT NULL,
   slug VARCHAR(200) DEFAULT '' NOT NULL,
+  status blogstatus DEFAULT 'draft' NOT NULL,
+  publish_at TIMESTAMP DEFAULT NULL,
   created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
   updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
   deleted_at TIMESTAMP DEFAULT NULL,
@@ -130,6 +135,8 @@ CREATE INDEX blogs_textsearch_idx ON blogs USING GIN (textsearchable_index_col);
 
 CREATE INDEX blogs_textrank_idx ON blogs USING GIN (textrank_index_col);
 
+CREATE INDEX blogs_scheduled_idx ON blogs (publish_at) WHERE status = 'scheduled';
+
 CREATE TABLE IF NOT EXISTS histories (
   id BIGSERIAL PRIMARY KEY,
   content jsonb DEFAULT '{}'::jsonb NOT NULL,
@@ -149,41 +156,185 @@ CREATE TABLE IF NOT EXISTS goals (
 
 CREATE TYPE cashflowtype AS ENUM ('income', 'outcome'); 
 
+CREATE TABLE IF NOT EXISTS cashflow_categories (
+  id BIGSERIAL PRIMARY KEY,
+  code VARCHAR(50) DEFAULT NULL UNIQUE,
+  name VARCHAR(200) DEFAULT '' NOT NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+  updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+  deleted_at TIMESTAMP DEFAULT NULL
+);
+
+INSERT INTO cashflow_categories (code, name) VALUES
+  ('dues', 'Iuran Anggota'),
+  ('donation', 'Donasi'),
+  ('event', 'Kegiatan'),
+  ('operation', 'Operasional');
+
+CREATE TABLE IF NOT EXISTS cashflow_accounts (
+  id BIGSERIAL PRIMARY KEY,
+  name VARCHAR(200) DEFAULT '' NOT NULL,
+  is_default BOOLEAN DEFAULT false NOT NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+  updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+  deleted_at TIMESTAMP DEFAULT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS cashflow_accounts_is_default_idx ON cashflow_accounts (is_default) WHERE is_default = true AND deleted_at IS NULL;
+
+INSERT INTO cashflow_accounts (name, is_default) VALUES
+  ('Kas Tunai', true),
+  ('Rekening Bank', false);
+
 CREATE TABLE IF NOT EXISTS cashflows (
   id BIGSERIAL PRIMARY KEY,
   date TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
-  idr_amount VARCHAR(200) DEFAULT '' NOT NULL,
+  idr_amount BIGINT DEFAULT 0 NOT NULL CHECK (idr_amount >= 0),
   type cashflowtype DEFAULT 'income' NOT NULL,
   note TEXT DEFAULT '' NOT NULL,
   prove_file_url TEXT DEFAULT '' NOT NULL,
+  category_id BIGINT DEFAULT NULL REFERENCES cashflow_categories(id),
+  account_id BIGINT NOT NULL REFERENCES cashflow_accounts(id),
   created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL, 
   updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
   deleted_at TIMESTAMP DEFAULT NULL
 );
 
+CREATE TABLE IF NOT EXISTS cashflow_transfers (
+  id BIGSERIAL PRIMARY KEY,
+  date TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+  idr_amount BIGINT DEFAULT 0 NOT NULL CHECK (idr_amount > 0),
+  from_account_id BIGINT NOT NULL REFERENCES cashflow_accounts(id),
+  to_account_id BIGINT NOT NULL REFERENCES cashflow_accounts(id),
+  note TEXT DEFAULT '' NOT NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+  updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+  deleted_at TIMESTAMP DEFAULT NULL,
+  CHECK (from_account_id <> to_account_id)
+);
+
 CREATE TABLE IF NOT EXISTS dues (
   id BIGSERIAL PRIMARY KEY,
   date TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
-  idr_amount VARCHAR(200) DEFAULT '' NOT NULL,
+  idr_amount BIGINT DEFAULT 0 NOT NULL CHECK (idr_amount >= 0),
   created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL, 
   updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
   deleted_at TIMESTAMP DEFAULT NULL
 );
 
-CREATE TYPE duesstatus AS ENUM ('unpaid', 'waiting', 'paid');
+CREATE TABLE IF NOT EXISTS dues_tiers (
+  id BIGSERIAL PRIMARY KEY,
+  name VARCHAR(200) DEFAULT '' NOT NULL,
+  idr_amount BIGINT DEFAULT 0 NOT NULL CHECK (idr_amount >= 0),
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+  updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+  deleted_at TIMESTAMP DEFAULT NULL
+);
+
+CREATE TYPE duesstatus AS ENUM ('unpaid', 'waiting', 'paid', 'rejected');
 
 CREATE TABLE IF NOT EXISTS member_dues (
   id BIGSERIAL PRIMARY KEY,
   member_id UUID NOT NULL REFERENCES members(id),
   dues_id BIGINT NOT NULL REFERENCES dues(id),
   status duesstatus DEFAULT 'unpaid' NOT NULL,
+  idr_amount BIGINT DEFAULT 0 NOT NULL CHECK (idr_amount >= 0),
+  paid_idr_amount BIGINT DEFAULT 0 NOT NULL CHECK (paid_idr_amount >= 0),
   prove_file_url TEXT DEFAULT '' NOT NULL,
+  reject_reason TEXT DEFAULT '' NOT NULL,
+  cashflow_id BIGINT DEFAULT NULL REFERENCES cashflows(id),
   pay_date TIMESTAMP DEFAULT NULL,
   created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
   updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
   deleted_at TIMESTAMP DEFAULT NULL
 );
 
+CREATE TABLE IF NOT EXISTS member_dues_tiers (
+  member_id UUID PRIMARY KEY REFERENCES members(id),
+  dues_tier_id BIGINT NOT NULL REFERENCES dues_tiers(id),
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+  updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS member_dues_overrides (
+  id BIGSERIAL PRIMARY KEY,
+  member_id UUID NOT NULL REFERENCES members(id),
+  dues_id BIGINT NOT NULL REFERENCES dues(id),
+  idr_amount BIGINT DEFAULT 0 NOT NULL CHECK (idr_amount >= 0),
+  note TEXT DEFAULT '' NOT NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+  updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+  deleted_at TIMESTAMP DEFAULT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS member_dues_overrides_member_dues_idx ON member_dues_overrides (member_id, dues_id) WHERE deleted_at IS NULL;
+
+CREATE TYPE paymentstatus AS ENUM ('waiting', 'approved', 'rejected');
+
+-- A payment cover one or more member dues of the member with one prove file,
+-- the amount is allocated to the oldest member dues first and the last one
+-- may be covered partially.
+CREATE TABLE IF NOT EXISTS dues_payments (
+  id BIGSERIAL PRIMARY KEY,
+  member_id UUID NOT NULL REFERENCES members(id),
+  status paymentstatus DEFAULT 'waiting' NOT NULL,
+  idr_amount BIGINT NOT NULL CHECK (idr_amount > 0),
+  prove_file_url TEXT DEFAULT '' NOT NULL,
+  reject_reason TEXT DEFAULT '' NOT NULL,
+  cashflow_id BIGINT DEFAULT NULL REFERENCES cashflows(id),
+  pay_date TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+  updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+  deleted_at TIMESTAMP DEFAULT NULL
+);
+
+CREATE TABLE IF NOT EXISTS dues_payment_allocations (
+  payment_id BIGINT NOT NULL REFERENCES dues_payments(id),
+  member_dues_id BIGINT NOT NULL REFERENCES member_dues(id),
+  idr_amount BIGINT NOT NULL CHECK (idr_amount > 0),
+  PRIMARY KEY (payment_id, member_dues_id)
+);
+
+-- Every status transition of the member dues, the actor is empty for the
+-- transition done by the system.
+CREATE TABLE IF NOT EXISTS member_dues_histories (
+  id BIGSERIAL PRIMARY KEY,
+  member_dues_id BIGINT NOT NULL REFERENCES member_dues(id),
+  from_status duesstatus NOT NULL,
+  to_status duesstatus NOT NULL,
+  reason TEXT DEFAULT '' NOT NULL,
+  actor_id UUID DEFAULT NULL REFERENCES members(id),
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS member_dues_histories_member_dues_idx ON member_dues_histories (member_dues_id);
+
+CREATE TYPE duesschedulestatus AS ENUM ('created', 'skipped', 'failed');
+
+-- A run is kept once per dues month and status, repeated run only update the
+-- message so a failing schedule does not flood the table.
+CREATE TABLE IF NOT EXISTS dues_schedule_runs (
+  id BIGSERIAL PRIMARY KEY,
+  dues_date TIMESTAMP NOT NULL,
+  status duesschedulestatus NOT NULL,
+  dues_id BIGINT DEFAULT NULL REFERENCES dues(id),
+  idr_amount BIGINT DEFAULT 0 NOT NULL CHECK (idr_amount >= 0),
+  message TEXT DEFAULT '' NOT NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+  updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+  UNIQUE (dues_date, status)
+);
+
+-- Every run of the arrears reminder, the latest run decide when the next
+-- reminder is due so restarting the service does not remind twice.
+CREATE TABLE IF NOT EXISTS dues_reminder_runs (
+  id BIGSERIAL PRIMARY KEY,
+  members INT DEFAULT 0 NOT NULL,
+  idr_amount BIGINT DEFAULT 0 NOT NULL CHECK (idr_amount >= 0),
+  notifier VARCHAR(50) DEFAULT '' NOT NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
 CREATE TABLE IF NOT EXISTS images (
   id BIGSERIAL PRIMARY KEY,
   name VARCHAR(200) DEFAULT '' NOT NULL,
@@ -193,3 +344,111 @@ CREATE TABLE IF NOT EXISTS images (
   created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
   deleted_at TIMESTAMP DEFAULT NULL
 );
+
+CREATE TYPE notificationchannel AS ENUM ('email', 'whatsapp', 'inapp');
+CREATE TYPE outboxstatus AS ENUM ('pending', 'sent', 'failed');
+
+-- The outbox is written in the transaction of the change, so the notification
+-- is only sent by the dispatcher once the change is committed.
+CREATE TABLE IF NOT EXISTS notification_outbox (
+  id BIGSERIAL PRIMARY KEY,
+  channel notificationchannel NOT NULL,
+  member_id UUID DEFAULT NULL REFERENCES members(id),
+  recipient VARCHAR(200) DEFAULT '' NOT NULL,
+  title VARCHAR(200) DEFAULT '' NOT NULL,
+  body TEXT DEFAULT '' NOT NULL,
+  link TEXT DEFAULT '' NOT NULL,
+  is_secret BOOLEAN DEFAULT false NOT NULL,
+  status outboxstatus DEFAULT 'pending' NOT NULL,
+  attempts INT DEFAULT 0 NOT NULL,
+  last_error TEXT DEFAULT '' NOT NULL,
+  next_attempt_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+  sent_at TIMESTAMP DEFAULT NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+  updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS notification_outbox_pending_idx ON notification_outbox (next_attempt_at) WHERE status = 'pending';
+
+-- In-app notification of the member, the outbox id keep the delivery
+-- idempotent when the dispatcher retry.
+CREATE TABLE IF NOT EXISTS notifications (
+  id BIGSERIAL PRIMARY KEY,
+  member_id UUID NOT NULL REFERENCES members(id),
+  outbox_id BIGINT DEFAULT NULL UNIQUE REFERENCES notification_outbox(id),
+  title VARCHAR(200) DEFAULT '' NOT NULL,
+  body TEXT DEFAULT '' NOT NULL,
+  link TEXT DEFAULT '' NOT NULL,
+  read_at TIMESTAMP DEFAULT NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS notifications_member_idx ON notifications (member_id, id);
+
+-- Role group the permissions given to the members, the admin member has every
+-- permission without any role. The permission names are kept by the service.
+CREATE TABLE IF NOT EXISTS roles (
+  id BIGSERIAL PRIMARY KEY,
+  name VARCHAR(100) DEFAULT '' NOT NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+  updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS roles_name_idx ON roles (LOWER(name));
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+  role_id BIGINT NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+  permission VARCHAR(50) NOT NULL,
+  PRIMARY KEY (role_id, permission)
+);
+
+CREATE TABLE IF NOT EXISTS member_roles (
+  member_id UUID NOT NULL REFERENCES members(id),
+  role_id BIGINT NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+  PRIMARY KEY (member_id, role_id)
+);
+
+-- Permissions held by whoever sits in the position during the active period,
+-- so the rights move along with the org structure at every handover.
+CREATE TABLE IF NOT EXISTS position_permissions (
+  position_id BIGINT NOT NULL REFERENCES positions(id) ON DELETE CASCADE,
+  permission VARCHAR(50) NOT NULL,
+  PRIMARY KEY (position_id, permission)
+);
+
+-- Append-only log of the administrative changes, each event hash include the
+-- hash of the previous event so a changed or removed event break the chain.
+CREATE TABLE IF NOT EXISTS audit_events (
+  id BIGSERIAL PRIMARY KEY,
+  actor_id UUID DEFAULT NULL,
+  action VARCHAR(50) NOT NULL,
+  entity VARCHAR(50) NOT NULL,
+  entity_id VARCHAR(100) DEFAULT '' NOT NULL,
+  diff JSON DEFAULT '{}' NOT NULL,
+  prev_hash VARCHAR(64) DEFAULT '' NOT NULL,
+  hash VARCHAR(64) NOT NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS audit_events_entity_idx ON audit_events (entity, id);
+CREATE INDEX IF NOT EXISTS audit_events_actor_idx ON audit_events (actor_id, id);
+CREATE INDEX IF NOT EXISTS audit_events_created_at_idx ON audit_events (created_at);
+
+CREATE OR REPLACE RULE audit_events_no_update AS ON UPDATE TO audit_events DO INSTEAD NOTHING;
+CREATE OR REPLACE RULE audit_events_no_delete AS ON DELETE TO audit_events DO INSTEAD NOTHING;
+
+-- Every saved version of the blog content, the history page and the period
+-- goals, the history page is a single entity with zero id.
+CREATE TABLE IF NOT EXISTS revisions (
+  id BIGSERIAL PRIMARY KEY,
+  entity VARCHAR(20) NOT NULL,
+  entity_id BIGINT DEFAULT 0 NOT NULL,
+  author_id UUID DEFAULT NULL,
+  restored_from BIGINT DEFAULT NULL,
+  content jsonb DEFAULT '{}'::jsonb NOT NULL,
+  content_text TEXT DEFAULT '' NOT NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS revisions_entity_idx ON revisions (entity, entity_id, id);
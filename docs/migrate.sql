@@ -57,3 +57,472 @@ DROP TABLE images;
 
 ALTER TABLE images_x RENAME TO images;
 ALTER SEQUENCE images_x_id_seq RENAME TO images_id_seq;
+
+WITH RECURSIVE private_documents AS (
+  SELECT id
+  FROM documents
+  WHERE is_private = true
+    AND type = 'dir'
+    AND deleted_at IS NULL
+  UNION
+  SELECT d.id
+  FROM documents d
+  JOIN private_documents p ON d.dir_id = p.id
+  WHERE d.deleted_at IS NULL
+)
+UPDATE documents
+SET is_private = true
+WHERE id IN (SELECT id FROM private_documents)
+  AND is_private = false;
+
+CREATE TABLE IF NOT EXISTS idr_amount_migration_errors (
+  id BIGSERIAL PRIMARY KEY,
+  table_name VARCHAR(100) DEFAULT '' NOT NULL,
+  row_id BIGINT DEFAULT 0 NOT NULL,
+  idr_amount VARCHAR(200) DEFAULT '' NOT NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+-- 18 digits at most fit in BIGINT, longer amounts are reported instead of overflowing the cast.
+INSERT INTO idr_amount_migration_errors (table_name, row_id, idr_amount)
+(
+  SELECT 'cashflows', id, idr_amount
+  FROM cashflows
+  WHERE trim(idr_amount) !~ '^[0-9]{1,18}(\.0{1,2})?$'
+  UNION ALL
+  SELECT 'dues', id, idr_amount
+  FROM dues
+  WHERE trim(idr_amount) !~ '^[0-9]{1,18}(\.0{1,2})?$'
+);
+
+DO $$
+DECLARE
+  r RECORD;
+BEGIN
+  FOR r IN SELECT table_name, row_id, idr_amount FROM idr_amount_migration_errors LOOP
+    RAISE NOTICE 'idr_amount of %.% is not a valid rupiah amount: "%", set to 0', r.table_name, r.row_id, r.idr_amount;
+  END LOOP;
+END $$;
+
+ALTER TABLE cashflows ALTER COLUMN idr_amount DROP DEFAULT;
+ALTER TABLE cashflows ALTER COLUMN idr_amount TYPE BIGINT USING (
+  CASE
+    WHEN trim(idr_amount) ~ '^[0-9]{1,18}(\.0{1,2})?$' THEN split_part(trim(idr_amount), '.', 1)::BIGINT
+    ELSE 0
+  END
+);
+ALTER TABLE cashflows ALTER COLUMN idr_amount SET DEFAULT 0;
+ALTER TABLE cashflows ADD CONSTRAINT cashflows_idr_amount_check CHECK (idr_amount >= 0);
+
+ALTER TABLE dues ALTER COLUMN idr_amount DROP DEFAULT;
+ALTER TABLE dues ALTER COLUMN idr_amount TYPE BIGINT USING (
+  CASE
+    WHEN trim(idr_amount) ~ '^[0-9]{1,18}(\.0{1,2})?$' THEN split_part(trim(idr_amount), '.', 1)::BIGINT
+    ELSE 0
+  END
+);
+ALTER TABLE dues ALTER COLUMN idr_amount SET DEFAULT 0;
+ALTER TABLE dues ADD CONSTRAINT dues_idr_amount_check CHECK (idr_amount >= 0);
+
+ALTER TABLE member_dues ADD COLUMN IF NOT EXISTS cashflow_id BIGINT DEFAULT NULL REFERENCES cashflows(id);
+
+CREATE TABLE IF NOT EXISTS cashflow_categories (
+  id BIGSERIAL PRIMARY KEY,
+  code VARCHAR(50) DEFAULT NULL UNIQUE,
+  name VARCHAR(200) DEFAULT '' NOT NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+  updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+  deleted_at TIMESTAMP DEFAULT NULL
+);
+
+INSERT INTO cashflow_categories (code, name) VALUES
+  ('dues', 'Iuran Anggota'),
+  ('donation', 'Donasi'),
+  ('event', 'Kegiatan'),
+  ('operation', 'Operasional')
+ON CONFLICT (code) DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS cashflow_accounts (
+  id BIGSERIAL PRIMARY KEY,
+  name VARCHAR(200) DEFAULT '' NOT NULL,
+  is_default BOOLEAN DEFAULT false NOT NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+  updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+  deleted_at TIMESTAMP DEFAULT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS cashflow_accounts_is_default_idx ON cashflow_accounts (is_default) WHERE is_default = true AND deleted_at IS NULL;
+
+INSERT INTO cashflow_accounts (name, is_default)
+SELECT 'Kas Tunai', true
+WHERE NOT EXISTS (SELECT 1 FROM cashflow_accounts WHERE is_default = true AND deleted_at IS NULL);
+
+INSERT INTO cashflow_accounts (name, is_default)
+SELECT 'Rekening Bank', false
+WHERE NOT EXISTS (SELECT 1 FROM cashflow_accounts WHERE name = 'Rekening Bank');
+
+ALTER TABLE cashflows ADD COLUMN IF NOT EXISTS category_id BIGINT DEFAULT NULL REFERENCES cashflow_categories(id);
+ALTER TABLE cashflows ADD COLUMN IF NOT EXISTS account_id BIGINT DEFAULT NULL REFERENCES cashflow_accounts(id);
+
+-- Existing cashflow is kept in the default account, cashflow of the paid
+-- member dues is categorized as dues.
+UPDATE cashflows
+SET account_id = (SELECT id FROM cashflow_accounts WHERE is_default = true AND deleted_at IS NULL)
+WHERE account_id IS NULL;
+
+UPDATE cashflows
+SET category_id = (SELECT id FROM cashflow_categories WHERE code = 'dues')
+WHERE category_id IS NULL
+  AND id IN (SELECT cashflow_id FROM member_dues WHERE cashflow_id IS NOT NULL);
+
+ALTER TABLE cashflows ALTER COLUMN account_id DROP DEFAULT;
+ALTER TABLE cashflows ALTER COLUMN account_id SET NOT NULL;
+
+CREATE TABLE IF NOT EXISTS cashflow_transfers (
+  id BIGSERIAL PRIMARY KEY,
+  date TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+  idr_amount BIGINT DEFAULT 0 NOT NULL CHECK (idr_amount > 0),
+  from_account_id BIGINT NOT NULL REFERENCES cashflow_accounts(id),
+  to_account_id BIGINT NOT NULL REFERENCES cashflow_accounts(id),
+  note TEXT DEFAULT '' NOT NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+  updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+  deleted_at TIMESTAMP DEFAULT NULL,
+  CHECK (from_account_id <> to_account_id)
+);
+
+CREATE TABLE IF NOT EXISTS dues_tiers (
+  id BIGSERIAL PRIMARY KEY,
+  name VARCHAR(200) DEFAULT '' NOT NULL,
+  idr_amount BIGINT DEFAULT 0 NOT NULL CHECK (idr_amount >= 0),
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+  updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+  deleted_at TIMESTAMP DEFAULT NULL
+);
+
+ALTER TABLE member_dues ADD COLUMN IF NOT EXISTS idr_amount BIGINT DEFAULT 0 NOT NULL CHECK (idr_amount >= 0);
+
+-- Existing member dues is charged the amount of its dues.
+UPDATE member_dues md
+SET idr_amount = d.idr_amount
+FROM dues d
+WHERE d.id = md.dues_id
+  AND md.idr_amount = 0;
+
+CREATE TABLE IF NOT EXISTS member_dues_tiers (
+  member_id UUID PRIMARY KEY REFERENCES members(id),
+  dues_tier_id BIGINT NOT NULL REFERENCES dues_tiers(id),
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+  updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS member_dues_overrides (
+  id BIGSERIAL PRIMARY KEY,
+  member_id UUID NOT NULL REFERENCES members(id),
+  dues_id BIGINT NOT NULL REFERENCES dues(id),
+  idr_amount BIGINT DEFAULT 0 NOT NULL CHECK (idr_amount >= 0),
+  note TEXT DEFAULT '' NOT NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+  updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+  deleted_at TIMESTAMP DEFAULT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS member_dues_overrides_member_dues_idx ON member_dues_overrides (member_id, dues_id) WHERE deleted_at IS NULL;
+
+CREATE TYPE duesschedulestatus AS ENUM ('created', 'skipped', 'failed');
+
+-- A run is kept once per dues month and status, repeated run only update the
+-- message so a failing schedule does not flood the table.
+CREATE TABLE IF NOT EXISTS dues_schedule_runs (
+  id BIGSERIAL PRIMARY KEY,
+  dues_date TIMESTAMP NOT NULL,
+  status duesschedulestatus NOT NULL,
+  dues_id BIGINT DEFAULT NULL REFERENCES dues(id),
+  idr_amount BIGINT DEFAULT 0 NOT NULL CHECK (idr_amount >= 0),
+  message TEXT DEFAULT '' NOT NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+  updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+  UNIQUE (dues_date, status)
+);
+
+ALTER TABLE member_dues ADD COLUMN IF NOT EXISTS paid_idr_amount BIGINT DEFAULT 0 NOT NULL CHECK (paid_idr_amount >= 0);
+
+CREATE TYPE paymentstatus AS ENUM ('waiting', 'approved');
+
+-- A payment cover one or more member dues of the member with one prove file,
+-- the amount is allocated to the oldest member dues first and the last one
+-- may be covered partially.
+CREATE TABLE IF NOT EXISTS dues_payments (
+  id BIGSERIAL PRIMARY KEY,
+  member_id UUID NOT NULL REFERENCES members(id),
+  status paymentstatus DEFAULT 'waiting' NOT NULL,
+  idr_amount BIGINT NOT NULL CHECK (idr_amount > 0),
+  prove_file_url TEXT DEFAULT '' NOT NULL,
+  cashflow_id BIGINT DEFAULT NULL REFERENCES cashflows(id),
+  pay_date TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+  updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+  deleted_at TIMESTAMP DEFAULT NULL
+);
+
+CREATE TABLE IF NOT EXISTS dues_payment_allocations (
+  payment_id BIGINT NOT NULL REFERENCES dues_payments(id),
+  member_dues_id BIGINT NOT NULL REFERENCES member_dues(id),
+  idr_amount BIGINT NOT NULL CHECK (idr_amount > 0),
+  PRIMARY KEY (payment_id, member_dues_id)
+);
+
+-- Existing waiting and paid member dues become a payment of its own.
+DO $$
+DECLARE
+  md RECORD;
+  pid BIGINT;
+BEGIN
+  FOR md IN
+    SELECT * FROM member_dues
+    WHERE deleted_at IS NULL
+      AND status <> 'unpaid'
+      AND idr_amount > 0
+      AND NOT EXISTS (
+        SELECT 1 FROM dues_payment_allocations a WHERE a.member_dues_id = member_dues.id
+      )
+  LOOP
+    INSERT INTO dues_payments (member_id, status, idr_amount, prove_file_url, cashflow_id, pay_date, created_at, updated_at)
+    VALUES (
+      md.member_id,
+      (CASE WHEN md.status = 'paid' THEN 'approved' ELSE 'waiting' END)::paymentstatus,
+      md.idr_amount,
+      md.prove_file_url,
+      md.cashflow_id,
+      COALESCE(md.pay_date, md.updated_at),
+      md.created_at,
+      md.updated_at
+    )
+    RETURNING id INTO pid;
+
+    INSERT INTO dues_payment_allocations (payment_id, member_dues_id, idr_amount)
+    VALUES (pid, md.id, md.idr_amount);
+  END LOOP;
+END $$;
+
+UPDATE member_dues
+SET paid_idr_amount = idr_amount
+WHERE status = 'paid'
+  AND paid_idr_amount = 0;
+
+ALTER TYPE duesstatus ADD VALUE IF NOT EXISTS 'rejected';
+ALTER TYPE paymentstatus ADD VALUE IF NOT EXISTS 'rejected';
+
+ALTER TABLE member_dues ADD COLUMN IF NOT EXISTS reject_reason TEXT DEFAULT '' NOT NULL;
+ALTER TABLE dues_payments ADD COLUMN IF NOT EXISTS reject_reason TEXT DEFAULT '' NOT NULL;
+
+-- Every status transition of the member dues, the actor is empty for the
+-- transition done by the system.
+CREATE TABLE IF NOT EXISTS member_dues_histories (
+  id BIGSERIAL PRIMARY KEY,
+  member_dues_id BIGINT NOT NULL REFERENCES member_dues(id),
+  from_status duesstatus NOT NULL,
+  to_status duesstatus NOT NULL,
+  reason TEXT DEFAULT '' NOT NULL,
+  actor_id UUID DEFAULT NULL REFERENCES members(id),
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS member_dues_histories_member_dues_idx ON member_dues_histories (member_dues_id);
+
+-- Every run of the arrears reminder, the latest run decide when the next
+-- reminder is due so restarting the service does not remind twice.
+CREATE TABLE IF NOT EXISTS dues_reminder_runs (
+  id BIGSERIAL PRIMARY KEY,
+  members INT DEFAULT 0 NOT NULL,
+  idr_amount BIGINT DEFAULT 0 NOT NULL CHECK (idr_amount >= 0),
+  notifier VARCHAR(50) DEFAULT '' NOT NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+CREATE TYPE notificationchannel AS ENUM ('email', 'whatsapp', 'inapp');
+CREATE TYPE outboxstatus AS ENUM ('pending', 'sent', 'failed');
+
+-- The outbox is written in the transaction of the change, so the notification
+-- is only sent by the dispatcher once the change is committed.
+CREATE TABLE IF NOT EXISTS notification_outbox (
+  id BIGSERIAL PRIMARY KEY,
+  channel notificationchannel NOT NULL,
+  member_id UUID DEFAULT NULL REFERENCES members(id),
+  recipient VARCHAR(200) DEFAULT '' NOT NULL,
+  title VARCHAR(200) DEFAULT '' NOT NULL,
+  body TEXT DEFAULT '' NOT NULL,
+  link TEXT DEFAULT '' NOT NULL,
+  status outboxstatus DEFAULT 'pending' NOT NULL,
+  attempts INT DEFAULT 0 NOT NULL,
+  last_error TEXT DEFAULT '' NOT NULL,
+  next_attempt_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+  sent_at TIMESTAMP DEFAULT NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+  updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS notification_outbox_pending_idx ON notification_outbox (next_attempt_at) WHERE status = 'pending';
+
+-- In-app notification of the member, the outbox id keep the delivery
+-- idempotent when the dispatcher retry.
+CREATE TABLE IF NOT EXISTS notifications (
+  id BIGSERIAL PRIMARY KEY,
+  member_id UUID NOT NULL REFERENCES members(id),
+  outbox_id BIGINT DEFAULT NULL UNIQUE REFERENCES notification_outbox(id),
+  title VARCHAR(200) DEFAULT '' NOT NULL,
+  body TEXT DEFAULT '' NOT NULL,
+  link TEXT DEFAULT '' NOT NULL,
+  read_at TIMESTAMP DEFAULT NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS notifications_member_idx ON notifications (member_id, id);
+
+-- Role group the permissions given to the members, the admin member has every
+-- permission without any role. The permission names are kept by the service.
+CREATE TABLE IF NOT EXISTS roles (
+  id BIGSERIAL PRIMARY KEY,
+  name VARCHAR(100) DEFAULT '' NOT NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+  updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS roles_name_idx ON roles (LOWER(name));
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+  role_id BIGINT NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+  permission VARCHAR(50) NOT NULL,
+  PRIMARY KEY (role_id, permission)
+);
+
+CREATE TABLE IF NOT EXISTS member_roles (
+  member_id UUID NOT NULL REFERENCES members(id),
+  role_id BIGINT NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+  PRIMARY KEY (member_id, role_id)
+);
+
+-- Permissions held by whoever sits in the position during the active period,
+-- so the rights move along with the org structure at every handover.
+CREATE TABLE IF NOT EXISTS position_permissions (
+  position_id BIGINT NOT NULL REFERENCES positions(id) ON DELETE CASCADE,
+  permission VARCHAR(50) NOT NULL,
+  PRIMARY KEY (position_id, permission)
+);
+
+-- Append-only log of the administrative changes, each event hash include the
+-- hash of the previous event so a changed or removed event break the chain.
+CREATE TABLE IF NOT EXISTS audit_events (
+  id BIGSERIAL PRIMARY KEY,
+  actor_id UUID DEFAULT NULL,
+  action VARCHAR(50) NOT NULL,
+  entity VARCHAR(50) NOT NULL,
+  entity_id VARCHAR(100) DEFAULT '' NOT NULL,
+  diff JSON DEFAULT '{}' NOT NULL,
+  prev_hash VARCHAR(64) DEFAULT '' NOT NULL,
+  hash VARCHAR(64) NOT NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS audit_events_entity_idx ON audit_events (entity, id);
+CREATE INDEX IF NOT EXISTS audit_events_actor_idx ON audit_events (actor_id, id);
+CREATE INDEX IF NOT EXISTS audit_events_created_at_idx ON audit_events (created_at);
+
+CREATE OR REPLACE RULE audit_events_no_update AS ON UPDATE TO audit_events DO INSTEAD NOTHING;
+CREATE OR REPLACE RULE audit_events_no_delete AS ON DELETE TO audit_events DO INSTEAD NOTHING;
+
+-- Every saved version of the blog content, the history page and the period
+-- goals, the history page is a single entity with zero id.
+CREATE TABLE IF NOT EXISTS revisions (
+  id BIGSERIAL PRIMARY KEY,
+  entity VARCHAR(20) NOT NULL,
+  entity_id BIGINT DEFAULT 0 NOT NULL,
+  author_id UUID DEFAULT NULL,
+  restored_from BIGINT DEFAULT NULL,
+  content jsonb DEFAULT '{}'::jsonb NOT NULL,
+  content_text TEXT DEFAULT '' NOT NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS revisions_entity_idx ON revisions (entity, entity_id, id);
+
+-- The saved versions become the first revisions, without author.
+INSERT INTO revisions (entity, entity_id, content, content_text, created_at)
+SELECT 'blog', id, jsonb_build_object(
+    'title', title,
+    'short_desc', short_desc,
+    'thumbnail_url', thumbnail_url,
+    'content', content
+  ), content_text, updated_at
+FROM blogs
+WHERE deleted_at IS NULL
+AND NOT EXISTS (SELECT 1 FROM revisions)
+UNION ALL
+SELECT 'history', 0, jsonb_build_object('content', content), content_text, created_at
+FROM histories
+WHERE NOT EXISTS (SELECT 1 FROM revisions)
+UNION ALL
+SELECT 'goal', org_period_id, jsonb_build_object(
+    'vision', vision,
+    'vision_text', vision_text,
+    'mission', mission,
+    'mission_text', mission_text
+  ), vision_text || E'\n' || mission_text, created_at
+FROM goals
+WHERE NOT EXISTS (SELECT 1 FROM revisions)
+ORDER BY 5;
+
+-- The existing blogs were published when they were created.
+CREATE TYPE blogstatus AS ENUM ('draft', 'scheduled', 'published', 'archived');
+
+ALTER TABLE blogs ADD COLUMN IF NOT EXISTS status blogstatus DEFAULT 'published' NOT NULL;
+ALTER TABLE blogs ADD COLUMN IF NOT EXISTS publish_at TIMESTAMP DEFAULT NULL;
+ALTER TABLE blogs ALTER COLUMN status SET DEFAULT 'draft';
+
+UPDATE blogs
+SET publish_at = created_at
+WHERE status = 'published'
+  AND publish_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS blogs_scheduled_idx ON blogs (publish_at) WHERE status = 'scheduled';
+
+-- The dues cashflow show the prove file of its payment instead of a copy.
+UPDATE cashflows c
+SET prove_file_url = ''
+FROM dues_payments p
+WHERE p.cashflow_id = c.id
+  AND c.prove_file_url = p.prove_file_url;
+
+UPDATE cashflows c
+SET prove_file_url = ''
+FROM member_dues md
+WHERE md.cashflow_id = c.id
+  AND c.prove_file_url = md.prove_file_url;
+
+-- The password reset token is cleared from the outbox once it is sent or failed.
+ALTER TABLE notification_outbox ADD COLUMN IF NOT EXISTS is_secret BOOLEAN DEFAULT false NOT NULL;
+
+UPDATE notification_outbox
+SET is_secret = true
+WHERE link LIKE '/reset-password?token=%';
+
+UPDATE notification_outbox
+SET body = '', link = ''
+WHERE is_secret
+  AND status <> 'pending';
+
+-- The member is charged the dues since it is approved, the approval of the
+-- existing member is taken from the audit events when it is recorded.
+ALTER TABLE members ADD COLUMN IF NOT EXISTS approved_at TIMESTAMP DEFAULT NULL;
+
+UPDATE members m
+SET approved_at = COALESCE((
+  SELECT MIN(e.created_at)
+  FROM audit_events e
+  WHERE e.entity = 'member'
+    AND e.action = 'approve'
+    AND e.entity_id = m.id::TEXT
+), m.created_at)
+WHERE m.is_approved = true
+  AND m.approved_at IS NULL;
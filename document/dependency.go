@@ -3,21 +3,48 @@ package document
 import (
 	"context"
 	"io"
+	"path"
+	"time"
 
-	"github.com/cloudinary/cloudinary-go/api/uploader"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/audit"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/storage"
 	"github.com/getsentry/sentry-go"
 )
 
 type (
 	FileUploader      func(filename string, file io.Reader) (string, error)
+	FileSigner        func(fileUrl string) (string, error)
 	ExceptionCapturer func(exception error)
 	MessageCapturer   func(message string)
 )
 
+// DocumentHook is run in the transaction of the document change, so the
+// change is rolled back when the hook fail.
+type DocumentHook func(ctx context.Context, id uint64) error
+
+// DocumentHooks let other package follow the documents without the document
+// package importing them.
+type DocumentHooks struct {
+	OnShared []DocumentHook
+}
+
+func runDocumentHooks(ctx context.Context, hooks []DocumentHook, id uint64) error {
+	for _, h := range hooks {
+		if err := h(ctx, id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 type DocumentDeps struct {
 	CaptureMessage     MessageCapturer
 	CaptureExeption    ExceptionCapturer
 	Upload             FileUploader
+	Sign               FileSigner
+	DocumentHooks      DocumentHooks
+	Audit              audit.Recorder
 	DocumentRepository *DocumentRepository
 }
 
@@ -25,27 +52,33 @@ func NewDeps(
 	captureMessage MessageCapturer,
 	captureExeption ExceptionCapturer,
 	upload FileUploader,
+	sign FileSigner,
+	documentHooks DocumentHooks,
+	auditRecorder audit.Recorder,
 	documentRepository *DocumentRepository,
 ) *DocumentDeps {
 	return &DocumentDeps{
 		CaptureMessage:     captureMessage,
 		CaptureExeption:    captureExeption,
 		Upload:             upload,
+		Sign:               sign,
+		DocumentHooks:      documentHooks,
+		Audit:              auditRecorder,
 		DocumentRepository: documentRepository,
 	}
 }
 
-func FileUpload(uploadParams uploader.UploadParams, upload func(ctx context.Context, file interface{}, uploadParams uploader.UploadParams) (*uploader.UploadResult, error)) FileUploader {
+func FileUpload(store storage.Storage, folder string) FileUploader {
 	return func(filename string, file io.Reader) (url string, err error) {
-		ctx := context.Background()
-		uploadParams.PublicID = filename
-
-		resp, err := upload(ctx, file, uploadParams)
-		if err != nil {
-			return "", err
-		}
+		return store.Put(context.Background(), path.Join(folder, filename), file)
+	}
+}
 
-		return resp.SecureURL, nil
+// FileSign create signer of the uploaded file url, the signed url is only valid
+// for the given expiry.
+func FileSign(expiry time.Duration, store storage.Storage) FileSigner {
+	return func(fileUrl string) (string, error) {
+		return store.SignUrl(context.Background(), fileUrl, expiry)
 	}
 }
 
@@ -242,7 +242,7 @@ func (r *DocumentRepository) DeleteInId(ctx context.Context, ids []uint64) error
 	return nil
 }
 
-func (r *DocumentRepository) Query(ctx context.Context, q string, id, limit int64) ([]DocumentModel, error) {
+func (r *DocumentRepository) Query(ctx context.Context, q string, id, limit int64, withPrivate bool) ([]DocumentModel, error) {
 	fromId := "id > $1"
 	if id != 0 {
 		fromId = "id < $1"
@@ -260,6 +260,11 @@ func (r *DocumentRepository) Query(ctx context.Context, q string, id, limit int6
 		q = "0"
 	}
 
+	private := ""
+	if !withPrivate {
+		private = "AND is_private = false"
+	}
+
 	sqlQuery := `
 		SELECT 
 			id,
@@ -276,6 +281,7 @@ func (r *DocumentRepository) Query(ctx context.Context, q string, id, limit int6
 		WHERE deleted_at IS NULL
 			AND ` + fromId + `
 			AND ` + like + `
+			` + private + `
 		ORDER BY ` + order + ` DESC
 		LIMIT $3
 	`
@@ -302,7 +308,7 @@ func (r *DocumentRepository) Query(ctx context.Context, q string, id, limit int6
 	return ms, nil
 }
 
-func (r *DocumentRepository) FindChildren(ctx context.Context, dirId uint64, q string, id, limit int64) ([]DocumentModel, error) {
+func (r *DocumentRepository) FindChildren(ctx context.Context, dirId uint64, q string, id, limit int64, withPrivate bool) ([]DocumentModel, error) {
 	fromId := "id > $2"
 	if id != 0 {
 		fromId = "id < $2"
@@ -320,6 +326,11 @@ func (r *DocumentRepository) FindChildren(ctx context.Context, dirId uint64, q s
 		q = "0"
 	}
 
+	private := ""
+	if !withPrivate {
+		private = "AND is_private = false"
+	}
+
 	sqlQuery := `
 		SELECT 
 			id,
@@ -337,6 +348,7 @@ func (r *DocumentRepository) FindChildren(ctx context.Context, dirId uint64, q s
 			AND dir_id = $1
 			AND ` + fromId + `
 			AND ` + like + `
+			` + private + `
 		ORDER BY ` + order + ` DESC
 		LIMIT $4
 	`
@@ -403,12 +415,51 @@ func (r *DocumentRepository) FindAllChildren(ctx context.Context, dirId uint64)
 	return ms, nil
 }
 
-func (r *DocumentRepository) CountFile(ctx context.Context) (n int64, err error) {
+func (r *DocumentRepository) UpdatePrivateInId(ctx context.Context, ids []uint64, isPrivate bool) error {
+	sqlQuery := `
+		UPDATE documents
+		SET (is_private, updated_at) = ($1, $2)
+		WHERE id = ANY($3)
+	`
+
+	var exec DocumentExecutor
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		exec = tx.Exec
+	} else {
+		exec = r.PostgreDb.Exec
+	}
+
+	var err error
+	t := time.Now()
+
+	_, err = exec(
+		context.Background(),
+		sqlQuery,
+		isPrivate,
+		t,
+		ids,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *DocumentRepository) CountFile(ctx context.Context, withPrivate bool) (n int64, err error) {
+	private := ""
+	if !withPrivate {
+		private = "AND is_private = false"
+	}
+
 	sqlQuery := `
 		SELECT COUNT(id) AS n
 		FROM documents
 		WHERE deleted_at IS NULL
 			AND type = 'file'
+			` + private + `
 	`
 
 	var queryRow DocumentQuerierRow
@@ -431,13 +482,19 @@ func (r *DocumentRepository) CountFile(ctx context.Context) (n int64, err error)
 	return n, nil
 }
 
-func (r *DocumentRepository) CountFileChildren(ctx context.Context, dirId uint64) (n int64, err error) {
+func (r *DocumentRepository) CountFileChildren(ctx context.Context, dirId uint64, withPrivate bool) (n int64, err error) {
+	private := ""
+	if !withPrivate {
+		private = "AND is_private = false"
+	}
+
 	sqlQuery := `
 		SELECT COUNT(id) AS n
 		FROM documents
 		WHERE deleted_at IS NULL
 			AND type = 'file'
 			AND dir_id = $1
+			` + private + `
 	`
 
 	var queryRow DocumentQuerierRow
@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/httpdecode"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/jwt"
 	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/resp"
 	"github.com/go-chi/chi/v5"
 )
@@ -90,7 +91,7 @@ func (d *DocumentDeps) GetDocuments(w http.ResponseWriter, r *http.Request) {
 	q := r.URL.Query().Get("q")
 	cursor := r.URL.Query().Get("cursor")
 	limit := r.URL.Query().Get("limit")
-	out := d.QueryDocument(r.Context(), q, cursor, limit)
+	out := d.QueryDocument(r.Context(), q, cursor, limit, jwt.HasClaims(r))
 	if out.Error != nil {
 		d.CaptureExeption(out.Error)
 	}
@@ -101,7 +102,7 @@ func (d *DocumentDeps) GetDocumentChildren(w http.ResponseWriter, r *http.Reques
 	q := r.URL.Query().Get("q")
 	id := chi.URLParam(r, "id")
 	cursor := r.URL.Query().Get("cursor")
-	out := d.FindDocumentChildren(r.Context(), id, q, cursor)
+	out := d.FindDocumentChildren(r.Context(), id, q, cursor, jwt.HasClaims(r))
 	if out.Error != nil {
 		d.CaptureExeption(out.Error)
 	}
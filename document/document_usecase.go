@@ -8,6 +8,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/audit"
 	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/httpdecode"
 	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/resp"
 	"github.com/jackc/pgx/v4"
@@ -22,6 +23,16 @@ var (
 	ErrDocumentNotFound  = errors.New("file atau folder tidak ditemukan")
 )
 
+// fileEndpoint is where the file of the document can be downloaded, the raw
+// storage url is never handed to the client.
+func fileEndpoint(d DocumentModel) string {
+	if d.Type != Filetype || d.Url == "" {
+		return ""
+	}
+
+	return "/api/v1/files/documents/" + strconv.FormatUint(d.Id, 10)
+}
+
 type (
 	AddDirDocumentIn struct {
 		Name      string    `json:"name"`
@@ -82,6 +93,17 @@ func (d *DocumentDeps) AddDirDocument(ctx context.Context, in AddDirDocumentIn)
 		return
 	}
 
+	err = d.Audit.Record(ctx, audit.Event{
+		Action:   audit.ActionCreate,
+		Entity:   "document",
+		EntityId: strconv.FormatUint(document.Id, 10),
+		After:    document,
+	})
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "record audit"))
+		return
+	}
+
 	out.Res.Id = int64(document.Id)
 
 	return
@@ -168,6 +190,22 @@ func (d *DocumentDeps) AddFileDocument(ctx context.Context, in AddFileDocumentIn
 		return
 	}
 
+	err = d.Audit.Record(ctx, audit.Event{
+		Action:   audit.ActionCreate,
+		Entity:   "document",
+		EntityId: strconv.FormatUint(document.Id, 10),
+		After:    document,
+	})
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "record audit"))
+		return
+	}
+
+	if err = runDocumentHooks(ctx, d.DocumentHooks.OnShared, document.Id); err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "run document shared hooks"))
+		return
+	}
+
 	out.Res.Id = int64(document.Id)
 
 	return
@@ -193,7 +231,9 @@ type (
 	}
 )
 
-func (d *DocumentDeps) QueryDocument(ctx context.Context, q, cursor, limit string) (out QueryDocumentOut) {
+// QueryDocument only return private documents when the caller is a member,
+// private documents are hidden from anonymous callers.
+func (d *DocumentDeps) QueryDocument(ctx context.Context, q, cursor, limit string, isMember bool) (out QueryDocumentOut) {
 	var err error
 	out.Response = resp.NewResponse(http.StatusOK, "", nil)
 
@@ -203,13 +243,13 @@ func (d *DocumentDeps) QueryDocument(ctx context.Context, q, cursor, limit strin
 		nlimit = 25
 	}
 
-	documentNumber, err := d.DocumentRepository.CountFile(ctx)
+	documentNumber, err := d.DocumentRepository.CountFile(ctx, isMember)
 	if err != nil {
 		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "count file"))
 		return
 	}
 
-	documents, err := d.DocumentRepository.Query(ctx, q, fromCursor, nlimit)
+	documents, err := d.DocumentRepository.Query(ctx, q, fromCursor, nlimit, isMember)
 	if err != nil {
 		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "query documents"))
 		return
@@ -228,7 +268,7 @@ func (d *DocumentDeps) QueryDocument(ctx context.Context, q, cursor, limit strin
 			Id:        int64(p.Id),
 			Name:      p.Name,
 			Type:      p.Type.String,
-			Url:       p.Url,
+			Url:       fileEndpoint(p),
 			DirId:     int64(p.DirId),
 			IsPrivate: p.IsPrivate,
 		}
@@ -303,6 +343,7 @@ func (d *DocumentDeps) EditDirDocument(ctx context.Context, pid string, in EditD
 		}
 	}
 
+	before := document
 	document.Name = in.Name
 	document.IsPrivate = isPrivate
 
@@ -314,6 +355,32 @@ func (d *DocumentDeps) EditDirDocument(ctx context.Context, pid string, in EditD
 		return
 	}
 
+	err = d.Audit.Record(ctx, audit.Event{
+		Action:   audit.ActionUpdate,
+		Entity:   "document",
+		EntityId: strconv.FormatUint(id, 10),
+		Before:   before,
+		After:    document,
+	})
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "record audit"))
+		return
+	}
+
+	// Everything inside a private dir is private too.
+	if isPrivate {
+		docIds, err := d.findDescendantIds(ctx, id)
+		if err != nil {
+			out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "find document children"))
+			return
+		}
+
+		if err = d.DocumentRepository.UpdatePrivateInId(ctx, docIds, true); err != nil {
+			out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "update document private in id"))
+			return
+		}
+	}
+
 	out.Res.Id = int64(id)
 
 	return
@@ -399,6 +466,7 @@ func (d *DocumentDeps) EditFileDocument(ctx context.Context, pid string, in Edit
 		}
 	}
 
+	before := document
 	document.IsPrivate = isPrivate
 	if fileUrl != "" {
 		document.Name = in.File.Filename
@@ -413,11 +481,56 @@ func (d *DocumentDeps) EditFileDocument(ctx context.Context, pid string, in Edit
 		return
 	}
 
+	err = d.Audit.Record(ctx, audit.Event{
+		Action:   audit.ActionUpdate,
+		Entity:   "document",
+		EntityId: strconv.FormatUint(id, 10),
+		Before:   before,
+		After:    document,
+	})
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "record audit"))
+		return
+	}
+
 	out.Res.Id = int64(id)
 
 	return
 }
 
+// findDescendantIds return the id of the document itself along with its child,
+// grand child, and so on.
+func (d *DocumentDeps) findDescendantIds(ctx context.Context, id uint64) ([]uint64, error) {
+	docDirIdsM := map[uint64]bool{
+		id: true,
+	}
+
+	docDirIds := []uint64{id}
+	for len(docDirIds) != 0 {
+		var docDirId uint64
+		docDirId, docDirIds = docDirIds[0], docDirIds[1:]
+		documents, err := d.DocumentRepository.FindAllChildren(ctx, docDirId)
+		if err != nil {
+			return []uint64{}, err
+		}
+
+		for _, v := range documents {
+			_, ok := docDirIdsM[v.Id]
+			if !ok {
+				docDirIdsM[v.Id] = true
+				docDirIds = append(docDirIds, v.Id)
+			}
+		}
+	}
+
+	docDirIds = []uint64{}
+	for k := range docDirIdsM {
+		docDirIds = append(docDirIds, k)
+	}
+
+	return docDirIds, nil
+}
+
 type (
 	RemoveDocumentRes struct {
 		Id int64 `json:"id"`
@@ -448,33 +561,11 @@ func (d *DocumentDeps) RemoveDocument(ctx context.Context, pid string) (out Remo
 		return
 	}
 
-	docDirIdsM := map[uint64]bool{
-		document.Id: true,
-	}
-
 	// Remove child, grand child, and so on...
-	docDirIds := []uint64{document.Id}
-	for len(docDirIds) != 0 {
-		var docDirId uint64
-		docDirId, docDirIds = docDirIds[0], docDirIds[1:]
-		documents, err := d.DocumentRepository.FindAllChildren(ctx, docDirId)
-		if err != nil {
-			out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "find document children"))
-			return
-		}
-
-		for _, v := range documents {
-			_, ok := docDirIdsM[v.Id]
-			if !ok {
-				docDirIdsM[v.Id] = true
-				docDirIds = append(docDirIds, v.Id)
-			}
-		}
-	}
-
-	docDirIds = []uint64{}
-	for k := range docDirIdsM {
-		docDirIds = append(docDirIds, k)
+	docDirIds, err := d.findDescendantIds(ctx, document.Id)
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "find document children"))
+		return
 	}
 
 	if err = d.DocumentRepository.DeleteInId(ctx, docDirIds); err != nil {
@@ -482,6 +573,17 @@ func (d *DocumentDeps) RemoveDocument(ctx context.Context, pid string) (out Remo
 		return
 	}
 
+	err = d.Audit.Record(ctx, audit.Event{
+		Action:   audit.ActionDelete,
+		Entity:   "document",
+		EntityId: strconv.FormatUint(id, 10),
+		Before:   document,
+	})
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "record audit"))
+		return
+	}
+
 	out.Res.Id = int64(id)
 
 	return
@@ -494,7 +596,7 @@ type (
 	}
 )
 
-func (d *DocumentDeps) FindDocumentChildren(ctx context.Context, pid, q, cursor string) (out DocumentChildrenOut) {
+func (d *DocumentDeps) FindDocumentChildren(ctx context.Context, pid, q, cursor string, isMember bool) (out DocumentChildrenOut) {
 	var err error
 	out.Response = resp.NewResponse(http.StatusOK, "", nil)
 
@@ -504,14 +606,28 @@ func (d *DocumentDeps) FindDocumentChildren(ctx context.Context, pid, q, cursor
 		return
 	}
 
-	documentNumber, err := d.DocumentRepository.CountFileChildren(ctx, id)
+	// Private directory is treated as non-existent for anonymous caller, so
+	// its existence is not leaked either.
+	if !isMember {
+		dir, err := d.DocumentRepository.FindDirById(ctx, id)
+		if errors.Is(err, pgx.ErrNoRows) || dir.IsPrivate {
+			out.Response = resp.NewResponse(http.StatusNotFound, "", ErrParentDirNotFound)
+			return
+		}
+		if err != nil {
+			out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "find dir by id"))
+			return
+		}
+	}
+
+	documentNumber, err := d.DocumentRepository.CountFileChildren(ctx, id, isMember)
 	if err != nil {
 		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "count file children"))
 		return
 	}
 
 	fromCursor, _ := strconv.ParseInt(cursor, 10, 64)
-	documents, err := d.DocumentRepository.FindChildren(ctx, id, q, fromCursor, 25)
+	documents, err := d.DocumentRepository.FindChildren(ctx, id, q, fromCursor, 25, isMember)
 	if err != nil {
 		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "find document children"))
 		return
@@ -530,7 +646,7 @@ func (d *DocumentDeps) FindDocumentChildren(ctx context.Context, pid, q, cursor
 			Id:        int64(p.Id),
 			Name:      p.Name,
 			Type:      p.Type.String,
-			Url:       p.Url,
+			Url:       fileEndpoint(p),
 			DirId:     int64(p.DirId),
 			IsPrivate: p.IsPrivate,
 		}
@@ -544,3 +660,51 @@ func (d *DocumentDeps) FindDocumentChildren(ctx context.Context, pid, q, cursor
 
 	return
 }
+
+type (
+	DocumentFileRes struct {
+		Url string `json:"url"`
+	}
+	DocumentFileOut struct {
+		resp.Response
+		Res DocumentFileRes
+	}
+)
+
+// FindDocumentFile return short-lived signed url of the document file. Private
+// document is treated as non-existent for anonymous caller.
+func (d *DocumentDeps) FindDocumentFile(ctx context.Context, pid string, isMember bool) (out DocumentFileOut) {
+	var err error
+	out.Response = resp.NewResponse(http.StatusOK, "", nil)
+
+	id, err := strconv.ParseUint(pid, 10, 64)
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusNotFound, "", ErrFileNotFound)
+		return
+	}
+
+	document, err := d.DocumentRepository.FindById(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) || document.Type != Filetype || document.Url == "" {
+		out.Response = resp.NewResponse(http.StatusNotFound, "", ErrFileNotFound)
+		return
+	}
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "find document by id"))
+		return
+	}
+
+	if document.IsPrivate && !isMember {
+		out.Response = resp.NewResponse(http.StatusNotFound, "", ErrFileNotFound)
+		return
+	}
+
+	url, err := d.Sign(document.Url)
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "sign file url"))
+		return
+	}
+
+	out.Res.Url = url
+
+	return
+}
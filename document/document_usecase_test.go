@@ -247,13 +247,30 @@ func TestQueryDocument(t *testing.T) {
 		t.Fatal(err)
 	}
 
+	privateDir := dirSeed
+	privateDir.IsPrivate = true
+	_, err = documentRepository.Save(context.Background(), privateDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
 	testCases := []struct {
 		Name               string
 		ExpectedStatusCode int
+		ExpectedLen        int
+		IsMember           bool
 	}{
 		{
 			Name:               "Query Documents Success",
 			ExpectedStatusCode: http.StatusOK,
+			ExpectedLen:        2,
+			IsMember:           true,
+		},
+		{
+			Name:               "Query Documents as Anonymous, Private Documents Hidden",
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedLen:        1,
+			IsMember:           false,
 		},
 	}
 
@@ -265,7 +282,7 @@ func TestQueryDocument(t *testing.T) {
 			}
 
 			ctx := context.WithValue(context.Background(), arbitary.TrxX{}, tx)
-			res := documentDeps.QueryDocument(ctx, "", "", "0")
+			res := documentDeps.QueryDocument(ctx, "", "", "0", c.IsMember)
 			tx.Commit(context.Background())
 			tx.Rollback(context.Background())
 
@@ -274,6 +291,10 @@ func TestQueryDocument(t *testing.T) {
 				t.Log(err)
 				t.Fatalf("Expected response code %d. Got %d\n", c.ExpectedStatusCode, res.StatusCode)
 			}
+
+			if len(res.Res.Documents) != c.ExpectedLen {
+				t.Fatalf("Expected documents length %d. Got %d\n", c.ExpectedLen, len(res.Res.Documents))
+			}
 		})
 	}
 }
@@ -369,6 +390,45 @@ func TestEditDirDocument(t *testing.T) {
 	}
 }
 
+func TestEditDirDocumentPrivateInherited(t *testing.T) {
+	err := ClearTables(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, c, err := createDocumentChildren(documentRepository, dirSeed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file := fileSeed
+	file.DirId = c.Id
+	nf, err := documentRepository.Save(context.Background(), file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res := documentDeps.EditDirDocument(context.Background(), strconv.FormatUint(p.Id, 10), document.EditDirDocumentIn{
+		Name:      "Dir A",
+		IsPrivate: null.BoolFrom(true),
+	})
+	if res.StatusCode != http.StatusOK {
+		t.Logf("%#v", res)
+		t.Fatalf("Expected response code %d. Got %d\n", http.StatusOK, res.StatusCode)
+	}
+
+	for _, id := range []uint64{c.Id, nf.Id} {
+		doc, err := documentRepository.FindById(context.Background(), id)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !doc.IsPrivate {
+			t.Fatalf("Expected document %d to be private\n", id)
+		}
+	}
+}
+
 func TestEditFileDocument(t *testing.T) {
 	err := ClearTables(db)
 	if err != nil {
@@ -542,28 +602,71 @@ func TestFindDocumentChildren(t *testing.T) {
 		t.Fatal(err)
 	}
 
+	privateDir := dirSeed
+	privateDir.IsPrivate = true
+	pp, pc, err := createDocumentChildren(documentRepository, privateDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
 	pid := strconv.FormatUint(p.Id, 10)
 	cid := strconv.FormatUint(c.Id, 10)
+	ppid := strconv.FormatUint(pp.Id, 10)
+	pcid := strconv.FormatUint(pc.Id, 10)
 
 	testCases := []struct {
 		Name               string
 		ExpectedStatusCode int
 		Id                 string
+		IsMember           bool
 	}{
 		{
 			Name:               "Find Document (Dir) Childrens, Success",
 			ExpectedStatusCode: http.StatusOK,
 			Id:                 pid,
+			IsMember:           true,
 		},
 		{
 			Name:               "Find Document (Dir) Childrens, Success",
 			ExpectedStatusCode: http.StatusOK,
 			Id:                 cid,
+			IsMember:           true,
 		},
 		{
 			Name:               "Find Document (Dir) Childrens, Success",
 			ExpectedStatusCode: http.StatusOK,
 			Id:                 "999",
+			IsMember:           true,
+		},
+		{
+			Name:               "Find Document (Dir) Childrens as Anonymous, Success",
+			ExpectedStatusCode: http.StatusOK,
+			Id:                 pid,
+			IsMember:           false,
+		},
+		{
+			Name:               "Find Private Document (Dir) Childrens as Member, Success",
+			ExpectedStatusCode: http.StatusOK,
+			Id:                 ppid,
+			IsMember:           true,
+		},
+		{
+			Name:               "Find Private Document (Dir) Childrens as Anonymous, Not Found",
+			ExpectedStatusCode: http.StatusNotFound,
+			Id:                 ppid,
+			IsMember:           false,
+		},
+		{
+			Name:               "Find Document (Dir) Inside Private Dir Childrens as Anonymous, Not Found",
+			ExpectedStatusCode: http.StatusNotFound,
+			Id:                 pcid,
+			IsMember:           false,
+		},
+		{
+			Name:               "Find Non Existing Document (Dir) Childrens as Anonymous, Not Found",
+			ExpectedStatusCode: http.StatusNotFound,
+			Id:                 "999",
+			IsMember:           false,
 		},
 	}
 
@@ -575,7 +678,7 @@ func TestFindDocumentChildren(t *testing.T) {
 			}
 
 			ctx := context.WithValue(context.Background(), arbitary.TrxX{}, tx)
-			res := documentDeps.FindDocumentChildren(ctx, c.Id, "", "")
+			res := documentDeps.FindDocumentChildren(ctx, c.Id, "", "", c.IsMember)
 			tx.Commit(context.Background())
 			tx.Rollback(context.Background())
 
@@ -587,3 +690,74 @@ func TestFindDocumentChildren(t *testing.T) {
 		})
 	}
 }
+
+func TestFindDocumentFile(t *testing.T) {
+	err := ClearTables(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nf, err := documentRepository.Save(context.Background(), fileSeed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	privateFile := fileSeed
+	privateFile.IsPrivate = true
+	npf, err := documentRepository.Save(context.Background(), privateFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nd, err := documentRepository.Save(context.Background(), dirSeed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fid := strconv.FormatUint(nf.Id, 10)
+	pfid := strconv.FormatUint(npf.Id, 10)
+	did := strconv.FormatUint(nd.Id, 10)
+
+	testCases := []struct {
+		Name               string
+		ExpectedStatusCode int
+		Id                 string
+		IsMember           bool
+	}{
+		{
+			Name:               "Find Document File as Anonymous Success",
+			ExpectedStatusCode: http.StatusOK,
+			Id:                 fid,
+			IsMember:           false,
+		},
+		{
+			Name:               "Find Private Document File as Member Success",
+			ExpectedStatusCode: http.StatusOK,
+			Id:                 pfid,
+			IsMember:           true,
+		},
+		{
+			Name:               "Find Private Document File as Anonymous Fail, Not Found",
+			ExpectedStatusCode: http.StatusNotFound,
+			Id:                 pfid,
+			IsMember:           false,
+		},
+		{
+			Name:               "Find Document File Fail, Id is not File Id",
+			ExpectedStatusCode: http.StatusNotFound,
+			Id:                 did,
+			IsMember:           true,
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.Name, func(t *testing.T) {
+			res := documentDeps.FindDocumentFile(context.Background(), c.Id, c.IsMember)
+
+			if res.StatusCode != c.ExpectedStatusCode {
+				t.Logf("%#v", res)
+				t.Fatalf("Expected response code %d. Got %d\n", c.ExpectedStatusCode, res.StatusCode)
+			}
+		})
+	}
+}
@@ -37,6 +37,9 @@ var (
 	upload document.FileUploader = func(filename string, file io.Reader) (string, error) {
 		return "", nil
 	}
+	sign document.FileSigner = func(fileUrl string) (string, error) {
+		return fileUrl + "?signed", nil
+	}
 	captureException document.ExceptionCapturer = func(exception error) {}
 	captureMessage   document.MessageCapturer   = func(message string) {}
 )
@@ -172,6 +175,9 @@ func TestMain(m *testing.M) {
 		captureMessage,
 		captureException,
 		upload,
+		sign,
+		document.DocumentHooks{},
+		nil,
 		documentRepository,
 	)
 
@@ -0,0 +1,46 @@
+package dues
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/jwt"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/resp"
+	"github.com/go-chi/chi/v5"
+)
+
+func (d *DuesDeps) GetDuesArrears(w http.ResponseWriter, r *http.Request) {
+	out := d.QueryDuesArrears(r.Context(), time.Now(), QueryDuesArrearsQIn{
+		MinMonths: r.URL.Query().Get("min_months"),
+	})
+	if out.Error != nil {
+		d.CaptureExeption(out.Error)
+	}
+	out.HttpJSON(w, resp.NewHttpBody(out.Res))
+}
+
+func (d *DuesDeps) GetDuesReminders(w http.ResponseWriter, r *http.Request) {
+	out := d.QueryDuesReminders(r.Context(), time.Now(), QueryDuesRemindersQIn{
+		MinMonths: r.URL.Query().Get("min_months"),
+	})
+	if out.Error != nil {
+		d.CaptureExeption(out.Error)
+	}
+	out.HttpJSON(w, resp.NewHttpBody(out.Res))
+}
+
+func (d *DuesDeps) GetMemberDuesStatement(w http.ResponseWriter, r *http.Request) {
+	var jwtPayload jwt.JwtPrivateClaim
+	if err := jwt.DecodeCustomClaims(r, &jwtPayload); err != nil {
+		d.CaptureExeption(err)
+		resp.NewResponse(http.StatusInternalServerError, "", err).HttpJSON(w, nil)
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	out := d.QueryMemberDuesStatement(r.Context(), id, jwtPayload.Uid, time.Now())
+	if out.Error != nil {
+		d.CaptureExeption(out.Error)
+	}
+	out.HttpJSON(w, resp.NewHttpBody(out.Res))
+}
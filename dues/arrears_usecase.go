@@ -0,0 +1,222 @@
+package dues
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/money"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/resp"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/user"
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrInvalidMinMonths   = errors.New("minimal bulan tunggakan harus berupa angka lebih dari 0")
+	ErrStatementForbidden = errors.New("anda tidak memiliki akses ke rekening iuran anggota ini")
+)
+
+// parseMinMonths parse the minimum overdue months, default to one month.
+func parseMinMonths(s string) (int, error) {
+	if s == "" {
+		return 1, nil
+	}
+
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 1 {
+		return 0, ErrInvalidMinMonths
+	}
+
+	return n, nil
+}
+
+type (
+	ArrearsOut struct {
+		MemberId   string `json:"member_id"`
+		Name       string `json:"name"`
+		Username   string `json:"username"`
+		WaPhone    string `json:"wa_phone"`
+		Months     int64  `json:"months"`
+		IdrAmount  string `json:"idr_amount"`
+		OldestDate string `json:"oldest_date"`
+	}
+	QueryDuesArrearsQIn struct {
+		MinMonths string
+	}
+	QueryDuesArrearsRes struct {
+		Total     int          `json:"total"`
+		IdrAmount string       `json:"idr_amount"`
+		Arrears   []ArrearsOut `json:"arrears"`
+	}
+	QueryDuesArrearsOut struct {
+		resp.Response
+		Res QueryDuesArrearsRes
+	}
+)
+
+// QueryDuesArrears rank the members by the months and the amount of their
+// overdue member dues at the given time.
+func (d *DuesDeps) QueryDuesArrears(ctx context.Context, now time.Time, qin QueryDuesArrearsQIn) (out QueryDuesArrearsOut) {
+	out.Response = resp.NewResponse(http.StatusOK, "", nil)
+
+	minMonths, err := parseMinMonths(qin.MinMonths)
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusUnprocessableEntity, "", err)
+		return
+	}
+
+	arrears, err := d.MemberDuesRepository.QueryArrears(ctx, now, minMonths)
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "query arrears"))
+		return
+	}
+
+	var total money.IDR
+	outArrears := make([]ArrearsOut, len(arrears))
+	for i, a := range arrears {
+		total += a.IdrAmount
+		outArrears[i] = ArrearsOut{
+			MemberId:   a.MemberId,
+			Name:       a.Name,
+			Username:   a.Username,
+			WaPhone:    a.WaPhone,
+			Months:     a.Months,
+			IdrAmount:  a.IdrAmount.String(),
+			OldestDate: a.OldestDate.Format("2006-01-02"),
+		}
+	}
+
+	out.Res = QueryDuesArrearsRes{
+		Total:     len(outArrears),
+		IdrAmount: total.String(),
+		Arrears:   outArrears,
+	}
+
+	return
+}
+
+type (
+	StatementLineOut struct {
+		Id                 int64  `json:"id"`
+		DuesId             int64  `json:"dues_id"`
+		Date               string `json:"date"`
+		Status             string `json:"status"`
+		IdrAmount          string `json:"idr_amount"`
+		PaidIdrAmount      string `json:"paid_idr_amount"`
+		RemainingIdrAmount string `json:"remaining_idr_amount"`
+		IsOverdue          bool   `json:"is_overdue"`
+		PayDate            string `json:"pay_date"`
+		// Balance is the outstanding amount up to the month of the line.
+		Balance string `json:"balance"`
+	}
+	MemberDuesStatementRes struct {
+		MemberId             string             `json:"member_id"`
+		Name                 string             `json:"name"`
+		Username             string             `json:"username"`
+		WaPhone              string             `json:"wa_phone"`
+		TotalIdrAmount       string             `json:"total_idr_amount"`
+		PaidIdrAmount        string             `json:"paid_idr_amount"`
+		OutstandingIdrAmount string             `json:"outstanding_idr_amount"`
+		OverdueIdrAmount     string             `json:"overdue_idr_amount"`
+		OverdueMonths        int64              `json:"overdue_months"`
+		Lines                []StatementLineOut `json:"lines"`
+	}
+	MemberDuesStatementOut struct {
+		resp.Response
+		Res MemberDuesStatementRes
+	}
+)
+
+// QueryMemberDuesStatement return every member dues of the member with the
+// running outstanding balance, only the member itself or admin can see the
+// statement.
+func (d *DuesDeps) QueryMemberDuesStatement(ctx context.Context, uid, requesterUid string, now time.Time) (out MemberDuesStatementOut) {
+	var err error
+	out.Response = resp.NewResponse(http.StatusOK, "", nil)
+
+	if _, err = uuid.FromString(uid); err != nil {
+		out.Response = resp.NewResponse(http.StatusNotFound, "", ErrMemberNotFound)
+		return
+	}
+
+	if uid != requesterUid {
+		allowed, err := d.hasPermission(ctx, requesterUid, user.PermDuesVerify)
+		if err != nil {
+			out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "has permission"))
+			return
+		}
+
+		if !allowed {
+			out.Response = resp.NewResponse(http.StatusForbidden, "", ErrStatementForbidden)
+			return
+		}
+	}
+
+	member, err := d.MemberRepository.FindById(ctx, uid)
+	if errors.Is(err, pgx.ErrNoRows) {
+		out.Response = resp.NewResponse(http.StatusNotFound, "", ErrMemberNotFound)
+		return
+	}
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "find member by id"))
+		return
+	}
+
+	memberDues, err := d.MemberDuesRepository.QueryStatementByUid(ctx, uid)
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "query statement by uid"))
+		return
+	}
+
+	var total, paid, balance, overdue money.IDR
+	var overdueMonths int64
+	lines := make([]StatementLineOut, len(memberDues))
+	for i, m := range memberDues {
+		remaining := remainingAmt(m.Status, m.IdrAmount, m.PaidIdrAmount)
+		isOverdue := remaining > 0 && (m.Status == Unpaid || m.Status == Rejected) && !m.Date.After(now)
+
+		total += m.IdrAmount
+		paid += m.IdrAmount - remaining
+		balance += remaining
+		if isOverdue {
+			overdue += remaining
+			overdueMonths++
+		}
+
+		var payDate string
+		if m.PayDate.Valid {
+			payDate = m.PayDate.Time.Format("2006-01-02")
+		}
+
+		lines[i] = StatementLineOut{
+			Id:                 int64(m.Id),
+			DuesId:             int64(m.DuesId),
+			Date:               m.Date.Format("2006-01-02"),
+			Status:             m.Status.String,
+			IdrAmount:          m.IdrAmount.String(),
+			PaidIdrAmount:      (m.IdrAmount - remaining).String(),
+			RemainingIdrAmount: remaining.String(),
+			IsOverdue:          isOverdue,
+			PayDate:            payDate,
+			Balance:            balance.String(),
+		}
+	}
+
+	out.Res = MemberDuesStatementRes{
+		MemberId:             uid,
+		Name:                 member.Name,
+		Username:             member.Username,
+		WaPhone:              member.WaPhone,
+		TotalIdrAmount:       total.String(),
+		PaidIdrAmount:        paid.String(),
+		OutstandingIdrAmount: balance.String(),
+		OverdueIdrAmount:     overdue.String(),
+		OverdueMonths:        overdueMonths,
+		Lines:                lines,
+	}
+
+	return
+}
@@ -0,0 +1,158 @@
+package dues_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/dues"
+)
+
+var pastDuesSeed2 = dues.DuesModel{
+	Date:      time.Now().Add(-2 * (time.Hour * 750)),
+	IdrAmount: 20000,
+}
+
+func TestQueryDuesArrears(t *testing.T) {
+	err := ClearTables(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	uid, _, err := createMemberDuesMonths(duesDeps, pastDuesSeed2, pastDuesSeed, duesSeed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	uid2, _, _, err := createMemberDues(duesDeps, memberSeed2, pastDuesSeed, unpaidMemDSeed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		Name               string
+		ExpectedStatusCode int
+		ExpectedUids       []string
+		QIn                dues.QueryDuesArrearsQIn
+	}{
+		{
+			Name:               "Query Dues Arrears Success",
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedUids:       []string{uid, uid2},
+			QIn:                dues.QueryDuesArrearsQIn{},
+		},
+		{
+			Name:               "Query Dues Arrears With Min Months Success",
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedUids:       []string{uid},
+			QIn: dues.QueryDuesArrearsQIn{
+				MinMonths: "2",
+			},
+		},
+		{
+			Name:               "Query Dues Arrears Fail, Min Months Validation Fail",
+			ExpectedStatusCode: http.StatusUnprocessableEntity,
+			QIn: dues.QueryDuesArrearsQIn{
+				MinMonths: "0",
+			},
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.Name, func(t *testing.T) {
+			res := duesDeps.QueryDuesArrears(context.Background(), time.Now(), c.QIn)
+
+			if res.StatusCode != c.ExpectedStatusCode {
+				t.Logf("%#v", res)
+				t.Fatalf("Expected response code %d. Got %d\n", c.ExpectedStatusCode, res.StatusCode)
+			}
+
+			if len(res.Res.Arrears) != len(c.ExpectedUids) {
+				t.Fatalf("Expected arrears length %d. Got %d\n", len(c.ExpectedUids), len(res.Res.Arrears))
+			}
+
+			for i, a := range res.Res.Arrears {
+				if a.MemberId != c.ExpectedUids[i] {
+					t.Fatalf("Expected member %s at rank %d. Got %s\n", c.ExpectedUids[i], i+1, a.MemberId)
+				}
+			}
+		})
+	}
+}
+
+func TestQueryMemberDuesStatement(t *testing.T) {
+	err := ClearTables(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	uid, _, err := createMemberDuesMonths(duesDeps, pastDuesSeed2, pastDuesSeed, duesSeed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	uid2, _, _, err := createMemberDues(duesDeps, memberSeed2, pastDuesSeed, unpaidMemDSeed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	notAdmin, err := memberRepository.FindById(context.Background(), uid2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	notAdmin.IsAdmin = false
+	if err := memberRepository.Update(context.Background(), uid2, notAdmin); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		Name                  string
+		ExpectedStatusCode    int
+		ExpectedOverdueMonths int64
+		Uid                   string
+		RequesterUid          string
+	}{
+		{
+			Name:                  "Query Member Dues Statement Success",
+			ExpectedStatusCode:    http.StatusOK,
+			ExpectedOverdueMonths: 2,
+			Uid:                   uid,
+			RequesterUid:          uid,
+		},
+		{
+			Name:                  "Query Member Dues Statement By Admin Success",
+			ExpectedStatusCode:    http.StatusOK,
+			ExpectedOverdueMonths: 1,
+			Uid:                   uid2,
+			RequesterUid:          uid,
+		},
+		{
+			Name:               "Query Member Dues Statement Fail, Not The Owner",
+			ExpectedStatusCode: http.StatusForbidden,
+			Uid:                uid,
+			RequesterUid:       uid2,
+		},
+		{
+			Name:               "Query Member Dues Statement Fail, Member Not Found",
+			ExpectedStatusCode: http.StatusNotFound,
+			Uid:                "not-uuid",
+			RequesterUid:       uid,
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.Name, func(t *testing.T) {
+			res := duesDeps.QueryMemberDuesStatement(context.Background(), c.Uid, c.RequesterUid, time.Now())
+
+			if res.StatusCode != c.ExpectedStatusCode {
+				t.Logf("%#v", res)
+				t.Fatalf("Expected response code %d. Got %d\n", c.ExpectedStatusCode, res.StatusCode)
+			}
+
+			if res.Res.OverdueMonths != c.ExpectedOverdueMonths {
+				t.Fatalf("Expected overdue months %d. Got %d\n", c.ExpectedOverdueMonths, res.Res.OverdueMonths)
+			}
+		})
+	}
+}
@@ -3,60 +3,125 @@ package dues
 import (
 	"context"
 	"io"
+	"path"
+	"time"
 
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/audit"
 	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/cashflow"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/export"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/storage"
 	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/user"
-	"github.com/cloudinary/cloudinary-go/api/uploader"
 	"github.com/getsentry/sentry-go"
+	"github.com/gofrs/uuid"
 )
 
 type (
 	FileUploader      func(filename string, file io.Reader) (string, error)
+	FileSigner        func(fileUrl string) (string, error)
 	ExceptionCapturer func(exception error)
 	MessageCapturer   func(message string)
 )
 
+// PaymentHook is run in the transaction of the dues payment change, so the
+// change is rolled back when the hook fail.
+type PaymentHook func(ctx context.Context, uid string, paymentId uint64) error
+
+// PaymentHooks let other package follow the dues payment lifecycle without the
+// dues package importing them.
+type PaymentHooks struct {
+	OnSubmitted []PaymentHook
+	OnApproved  []PaymentHook
+	OnRejected  []PaymentHook
+}
+
+func runPaymentHooks(ctx context.Context, hooks []PaymentHook, payment PaymentModel) error {
+	for _, h := range hooks {
+		if err := h(ctx, payment.MemberId, payment.Id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 type DuesDeps struct {
-	CaptureMessage       MessageCapturer
-	CaptureExeption      ExceptionCapturer
-	Upload               FileUploader
+	CaptureMessage  MessageCapturer
+	CaptureExeption ExceptionCapturer
+	Upload          FileUploader
+	Sign            FileSigner
+	Letterhead      export.Letterhead
+	// ScheduleDay is the day of month the next month dues is generated, zero
+	// disable the schedule.
+	ScheduleDay int
+	// Notifier deliver the reminder of the overdue member dues, nil disable
+	// the reminder.
+	Notifier             Notifier
+	PaymentHooks         PaymentHooks
+	Audit                audit.Recorder
 	DuesRepository       *DuesRepository
 	MemberDuesRepository *MemberDuesRepository
+	TierRepository       *TierRepository
+	OverrideRepository   *OverrideRepository
+	ScheduleRepository   *ScheduleRepository
+	PaymentRepository    *PaymentRepository
 	MemberRepository     *user.MemberRepository
 	CashflowRepository   *cashflow.CashflowRepository
+	CategoryRepository   *cashflow.CategoryRepository
 }
 
 func NewDeps(
 	captureMessage MessageCapturer,
 	captureExeption ExceptionCapturer,
 	upload FileUploader,
+	sign FileSigner,
+	letterhead export.Letterhead,
+	scheduleDay int,
+	notifier Notifier,
+	paymentHooks PaymentHooks,
+	auditRecorder audit.Recorder,
 	duesRepository *DuesRepository,
 	memberDuesRepository *MemberDuesRepository,
+	tierRepository *TierRepository,
+	overrideRepository *OverrideRepository,
+	scheduleRepository *ScheduleRepository,
+	paymentRepository *PaymentRepository,
 	memberRepository *user.MemberRepository,
 	cashflowRepository *cashflow.CashflowRepository,
+	categoryRepository *cashflow.CategoryRepository,
 ) *DuesDeps {
 	return &DuesDeps{
 		CaptureMessage:       captureMessage,
 		CaptureExeption:      captureExeption,
 		Upload:               upload,
+		Sign:                 sign,
+		Letterhead:           letterhead,
+		ScheduleDay:          scheduleDay,
+		Notifier:             notifier,
+		PaymentHooks:         paymentHooks,
+		Audit:                auditRecorder,
 		DuesRepository:       duesRepository,
 		MemberDuesRepository: memberDuesRepository,
+		TierRepository:       tierRepository,
+		OverrideRepository:   overrideRepository,
+		ScheduleRepository:   scheduleRepository,
+		PaymentRepository:    paymentRepository,
 		MemberRepository:     memberRepository,
 		CashflowRepository:   cashflowRepository,
+		CategoryRepository:   categoryRepository,
 	}
 }
 
-func FileUpload(uploadParams uploader.UploadParams, upload func(ctx context.Context, file interface{}, uploadParams uploader.UploadParams) (*uploader.UploadResult, error)) FileUploader {
+func FileUpload(store storage.Storage, folder string) FileUploader {
 	return func(filename string, file io.Reader) (url string, err error) {
-		ctx := context.Background()
-		uploadParams.PublicID = filename
-
-		resp, err := upload(ctx, file, uploadParams)
-		if err != nil {
-			return "", err
-		}
+		return store.Put(context.Background(), path.Join(folder, filename), file)
+	}
+}
 
-		return resp.SecureURL, nil
+// FileSign create signer of the uploaded file url, the signed url is only valid
+// for the given expiry.
+func FileSign(expiry time.Duration, store storage.Storage) FileSigner {
+	return func(fileUrl string) (string, error) {
+		return store.SignUrl(context.Background(), fileUrl, expiry)
 	}
 }
 
@@ -71,3 +136,13 @@ func CaptureMessage(capture func(message string) *sentry.EventID) MessageCapture
 		capture(message)
 	}
 }
+
+// hasPermission report whether the member has the permission, the member
+// dues of other member can only be seen by the member with the permission.
+func (d *DuesDeps) hasPermission(ctx context.Context, uid, permission string) (bool, error) {
+	if _, err := uuid.FromString(uid); err != nil {
+		return false, nil
+	}
+
+	return d.MemberRepository.HasPermission(ctx, uid, permission)
+}
@@ -3,11 +3,13 @@ package dues
 import (
 	"database/sql"
 	"time"
+
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/money"
 )
 
 type DuesModel struct {
 	Id        uint64
-	IdrAmount string
+	IdrAmount money.IDR
 	Date      time.Time
 	CreatedAt time.Time
 	UpdatedAt time.Time
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	arbitary "github.com/PA-D3RPLA/d3if43-htt-uhomestay/arbitrary"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/money"
 
 	"github.com/georgysavva/scany/pgxscan"
 	"github.com/jackc/pgconn"
@@ -258,37 +259,50 @@ func (r *DuesRepository) Query(ctx context.Context) ([]DuesModel, error) {
 	return ms, nil
 }
 
-func (r *DuesRepository) QueryAmtByUidStatus(ctx context.Context, uid string, status DuesStatus) ([]string, error) {
+// SumAmtByUidStatus return the amount of the member dues of the status, the
+// paid amount include the partially paid member dues while the other status
+// only count the remaining balance. Rejected member dues count as unpaid.
+func (r *DuesRepository) SumAmtByUidStatus(ctx context.Context, uid string, status DuesStatus) (amt money.IDR, err error) {
 	sqlQuery := `
-		SELECT 
-			dues.idr_amount
+		SELECT COALESCE(SUM(
+			CASE
+				WHEN $2::TEXT <> 'paid' THEN md.idr_amount - md.paid_idr_amount
+				WHEN md.status = 'paid' THEN md.idr_amount
+				ELSE md.paid_idr_amount
+			END
+		), 0)::BIGINT AS amt
 		FROM dues 
 		RIGHT JOIN member_dues md ON md.dues_id = dues.id
 		WHERE dues.deleted_at IS NULL
 			AND md.member_id = $1
 			AND md.deleted_at IS NULL
-			AND md.status = $2
+			AND (
+				md.status::TEXT = $2::TEXT
+				OR ($2::TEXT = 'paid' AND md.paid_idr_amount > 0)
+				OR ($2::TEXT = 'unpaid' AND md.status = 'rejected')
+			)
 	`
 
-	rows, _ := r.PostgreDb.Query(
+	var queryRow DuesQuerierRow
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		queryRow = tx.QueryRow
+	} else {
+		queryRow = r.PostgreDb.QueryRow
+	}
+
+	err = queryRow(
 		context.Background(),
 		sqlQuery,
 		uid,
 		status.String,
-	)
-	defer rows.Close()
-
-	var mps []*string
-	if err := pgxscan.ScanAll(&mps, rows); err != nil {
-		return []string{}, err
-	}
+	).Scan(&amt)
 
-	ms := make([]string, len(mps))
-	for i, m := range mps {
-		ms[i] = *m
+	if err != nil {
+		return 0, err
 	}
 
-	return ms, nil
+	return amt, nil
 }
 
 func (r *DuesRepository) Latest(ctx context.Context) (m DuesModel, err error) {
@@ -3,6 +3,7 @@ package dues
 import (
 	"encoding/json"
 	"net/http"
+	"time"
 
 	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/resp"
 	"github.com/go-chi/chi/v5"
@@ -70,3 +71,11 @@ func (d *DuesDeps) GetPaidDues(w http.ResponseWriter, r *http.Request) {
 	}
 	out.HttpJSON(w, resp.NewHttpBody(out.Res))
 }
+
+func (d *DuesDeps) GetDuesSchedule(w http.ResponseWriter, r *http.Request) {
+	out := d.QueryDuesSchedule(r.Context(), time.Now())
+	if out.Error != nil {
+		d.CaptureExeption(out.Error)
+	}
+	out.HttpJSON(w, resp.NewHttpBody(out.Res))
+}
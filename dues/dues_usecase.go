@@ -6,6 +6,8 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/audit"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/money"
 	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/resp"
 	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/timediff"
 	"github.com/jackc/pgx/v4"
@@ -54,6 +56,12 @@ func (d *DuesDeps) AddDues(ctx context.Context, in AddDuesIn) (out AddDuesOut) {
 		return
 	}
 
+	// Wait for the running dues schedule, so the month is not created twice.
+	if err = d.ScheduleRepository.LockTx(ctx, duesLockKey); err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "lock dues"))
+		return
+	}
+
 	dues, err := d.DuesRepository.FindOtherByYYYYMM(ctx, 0, date)
 	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
 		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "find dues by yyyy mm"))
@@ -65,9 +73,11 @@ func (d *DuesDeps) AddDues(ctx context.Context, in AddDuesIn) (out AddDuesOut) {
 		return
 	}
 
+	amount, _ := money.ParseIDR(in.IdrAmount)
+
 	dues = DuesModel{
 		Date:      date,
-		IdrAmount: in.IdrAmount,
+		IdrAmount: amount,
 	}
 
 	if dues, err = d.DuesRepository.Save(ctx, dues); err != nil {
@@ -75,6 +85,17 @@ func (d *DuesDeps) AddDues(ctx context.Context, in AddDuesIn) (out AddDuesOut) {
 		return
 	}
 
+	err = d.Audit.Record(ctx, audit.Event{
+		Action:   audit.ActionCreate,
+		Entity:   "dues",
+		EntityId: strconv.FormatUint(dues.Id, 10),
+		After:    dues,
+	})
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "record audit"))
+		return
+	}
+
 	if err = d.MemberDuesRepository.GenerateDues(ctx, dues.Id); err != nil {
 		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "generate dues"))
 		return
@@ -117,7 +138,7 @@ func (d *DuesDeps) QueryDues(ctx context.Context) (out QueryDuesOut) {
 		outDues[i] = DuesOut{
 			Id:        int64(d.Id),
 			Date:      d.Date.Format("2006-01"),
-			IdrAmount: d.IdrAmount,
+			IdrAmount: d.IdrAmount.String(),
 		}
 	}
 
@@ -200,14 +221,36 @@ func (d *DuesDeps) EditDues(ctx context.Context, pid string, in EditDuesIn) (out
 		return
 	}
 
+	amount, _ := money.ParseIDR(in.IdrAmount)
+
+	before := dues
 	dues.Date = date
-	dues.IdrAmount = in.IdrAmount
+	dues.IdrAmount = amount
 
 	if err = d.DuesRepository.UpdateById(ctx, id, dues); err != nil {
 		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "update dues by id"))
 		return
 	}
 
+	err = d.Audit.Record(ctx, audit.Event{
+		Action:   audit.ActionUpdate,
+		Entity:   "dues",
+		EntityId: strconv.FormatUint(id, 10),
+		Before:   before,
+		After:    dues,
+	})
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "record audit"))
+		return
+	}
+
+	// No one has paid the dues, so every member dues is charged the new
+	// amount unless the member has its own tier or override.
+	if err = d.MemberDuesRepository.SyncAmtByDuesId(ctx, id, ""); err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "sync member dues amount"))
+		return
+	}
+
 	out.Res.Id = int64(id)
 
 	return
@@ -259,6 +302,17 @@ func (d *DuesDeps) RemoveDues(ctx context.Context, pid string) (out RemoveDuesOu
 		return
 	}
 
+	err = d.Audit.Record(ctx, audit.Event{
+		Action:   audit.ActionDelete,
+		Entity:   "dues",
+		EntityId: strconv.FormatUint(id, 10),
+		Before:   dues,
+	})
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "record audit"))
+		return
+	}
+
 	if err = d.MemberDuesRepository.DeleteByDuesId(ctx, id); err != nil {
 		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "delete member dues by dues id"))
 		return
@@ -339,7 +393,7 @@ func (d *DuesDeps) FindLatestDues(ctx context.Context) (out LatestDuesOut) {
 	out.Res = LatestDuesRes{
 		Id:        int64(dues.Id),
 		Date:      dues.Date.Format("2006-01"),
-		IdrAmount: dues.IdrAmount,
+		IdrAmount: dues.IdrAmount.String(),
 	}
 
 	return
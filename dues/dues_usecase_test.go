@@ -68,19 +68,19 @@ func TestAddDues(t *testing.T) {
 			},
 		},
 		{
-			Name:               "Add Dues with Idr Amount 200 chars Success",
+			Name:               "Add Dues with Idr Amount Zero Fraction Success",
 			ExpectedStatusCode: http.StatusCreated,
 			In: dues.AddDuesIn{
 				Date:      time.Now().Add(time.Hour * 24 * 100).Format("2006-01-02"),
-				IdrAmount: strings.Repeat("1", 200),
+				IdrAmount: "100000.00",
 			},
 		},
 		{
-			Name:               "Add Dues with Idr Amount over 200 chars fail",
+			Name:               "Add Dues with Idr Amount Not Number Fail",
 			ExpectedStatusCode: http.StatusUnprocessableEntity,
 			In: dues.AddDuesIn{
 				Date:      time.Now().Add(time.Hour * 24 * 200).Format("2006-01-02"),
-				IdrAmount: strings.Repeat("1", 201),
+				IdrAmount: "100.000",
 			},
 		},
 	}
@@ -235,21 +235,21 @@ func TestEditDues(t *testing.T) {
 			},
 		},
 		{
-			Name:               "Edit Dues with Idr Amount 200 chars Success",
+			Name:               "Edit Dues with Idr Amount Zero Fraction Success",
 			ExpectedStatusCode: http.StatusOK,
 			Id:                 pid,
 			In: dues.EditDuesIn{
 				Date:      time.Now().Format("2006-01-02"),
-				IdrAmount: strings.Repeat("1", 200),
+				IdrAmount: "100000.0",
 			},
 		},
 		{
-			Name:               "Edit Dues with Idr Amount over 200 chars fail",
+			Name:               "Edit Dues with Idr Amount Overflow Fail",
 			ExpectedStatusCode: http.StatusUnprocessableEntity,
 			Id:                 pid,
 			In: dues.EditDuesIn{
 				Date:      time.Now().Format("2006-01-02"),
-				IdrAmount: strings.Repeat("1", 201),
+				IdrAmount: strings.Repeat("9", 19),
 			},
 		},
 	}
@@ -3,15 +3,14 @@ package dues
 import (
 	"errors"
 	"strings"
-	"unicode/utf8"
 
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/money"
 	"golang.org/x/sync/errgroup"
 )
 
 var (
 	ErrDateRequired      = errors.New("tanggal tidak boleh kosong")
 	ErrIdrAmountRequired = errors.New("jumlah nominal rupiah tidak boleh kosong")
-	ErrMaxIdrAmount      = errors.New("jumlah nominal rupiah tidak dapat lebih dari 200 karakter")
 )
 
 func ValidateAddDuesIn(i AddDuesIn) error {
@@ -30,10 +29,12 @@ func ValidateAddDuesIn(i AddDuesIn) error {
 		return nil
 	})
 	g.Go(func() error {
-		if utf8.RuneCountInString(i.IdrAmount) > 200 {
-			return ErrMaxIdrAmount
+		if strings.Trim(i.IdrAmount, " ") == "" {
+			return nil
 		}
-		return nil
+
+		_, err := money.ParseIDR(i.IdrAmount)
+		return err
 	})
 
 	if err := g.Wait(); err != nil {
@@ -58,10 +59,12 @@ func ValidateEditDuesIn(i EditDuesIn) error {
 		return nil
 	})
 	g.Go(func() error {
-		if utf8.RuneCountInString(i.IdrAmount) > 200 {
-			return ErrMaxIdrAmount
+		if strings.Trim(i.IdrAmount, " ") == "" {
+			return nil
 		}
-		return nil
+
+		_, err := money.ParseIDR(i.IdrAmount)
+		return err
 	})
 
 	if err := g.Wait(); err != nil {
@@ -0,0 +1,54 @@
+package dues
+
+import (
+	"net/http"
+
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/export"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/jwt"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/resp"
+	"github.com/go-chi/chi/v5"
+	"github.com/pkg/errors"
+)
+
+func (d *DuesDeps) GetMembersDuesExport(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	out := d.ExportMembersDues(r.Context(), id, ExportMembersDuesQIn{
+		Format:    r.URL.Query().Get("format"),
+		StartDate: r.URL.Query().Get("start_date"),
+		EndDate:   r.URL.Query().Get("end_date"),
+	})
+	if out.Error != nil {
+		d.CaptureExeption(out.Error)
+		out.HttpJSON(w, resp.NewHttpBody(nil))
+		return
+	}
+
+	export.SetHttpHeader(w, out.Res.Format, out.Res.Filename)
+	if err := out.Stream(w); err != nil {
+		d.CaptureExeption(errors.Wrap(err, "stream members dues export"))
+	}
+}
+
+func (d *DuesDeps) GetMemberDuesExport(w http.ResponseWriter, r *http.Request) {
+	var jwtPayload jwt.JwtPrivateClaim
+	if err := jwt.DecodeCustomClaims(r, &jwtPayload); err != nil {
+		d.CaptureExeption(err)
+		resp.NewResponse(http.StatusInternalServerError, "", err).HttpJSON(w, nil)
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	out := d.ExportMemberDues(r.Context(), id, jwtPayload.Uid, ExportMemberDuesQIn{
+		Format: r.URL.Query().Get("format"),
+	})
+	if out.Error != nil {
+		d.CaptureExeption(out.Error)
+		out.HttpJSON(w, resp.NewHttpBody(nil))
+		return
+	}
+
+	export.SetHttpHeader(w, out.Res.Format, out.Res.Filename)
+	if err := out.Stream(w); err != nil {
+		d.CaptureExeption(errors.Wrap(err, "stream member dues export"))
+	}
+}
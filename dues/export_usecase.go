@@ -0,0 +1,296 @@
+package dues
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/export"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/resp"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/user"
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+)
+
+var ErrExportForbidden = errors.New("anda tidak memiliki akses ke riwayat iuran anggota ini")
+
+// exportBatchSize is the number of member dues queried at once while
+// streaming the export.
+const exportBatchSize = 500
+
+var (
+	membersDuesColumns = []export.Column{
+		{Title: "No", Width: 0.5},
+		{Title: "Nama", Width: 3},
+		{Title: "Nominal", Numeric: true},
+		{Title: "Dibayar", Numeric: true},
+		{Title: "Status", Width: 1.5},
+		{Title: "Tanggal Bayar"},
+	}
+	memberDuesColumns = []export.Column{
+		{Title: "No", Width: 0.5},
+		{Title: "Bulan"},
+		{Title: "Nominal", Numeric: true},
+		{Title: "Dibayar", Numeric: true},
+		{Title: "Status", Width: 1.5},
+		{Title: "Tanggal Bayar"},
+	}
+)
+
+func statusLabel(s DuesStatus) string {
+	switch s {
+	case Paid:
+		return "Lunas"
+	case Waiting:
+		return "Menunggu Verifikasi"
+	case Rejected:
+		return "Ditolak"
+	}
+
+	return "Belum Bayar"
+}
+
+type (
+	ExportRes struct {
+		Format   export.Format
+		Filename string
+	}
+	ExportMembersDuesQIn struct {
+		Format    string
+		StartDate string
+		EndDate   string
+	}
+	ExportMembersDuesOut struct {
+		resp.Response
+		Res ExportRes
+		// Stream write the export to w, it should only be called when there
+		// is no error.
+		Stream func(w io.Writer) error
+	}
+)
+
+// ExportMembersDues export the payment status of every member for the monthly
+// dues, the latest dues is exported when the id is zero.
+func (d *DuesDeps) ExportMembersDues(ctx context.Context, pid string, qin ExportMembersDuesQIn) (out ExportMembersDuesOut) {
+	var err error
+	out.Response = resp.NewResponse(http.StatusOK, "", nil)
+
+	f, err := export.ParseFormat(qin.Format)
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusUnprocessableEntity, "", err)
+		return
+	}
+
+	var startDate, endDate time.Time
+	parsedStartDate, err := time.Parse(time.RFC3339, qin.StartDate+"T00:00:00Z")
+	if err == nil {
+		startDate = parsedStartDate
+	}
+
+	parsedEndDate, err := time.Parse(time.RFC3339, qin.EndDate+"T23:59:59Z")
+	if err == nil {
+		endDate = parsedEndDate
+	}
+
+	if !startDate.IsZero() && endDate.IsZero() {
+		endDate = startDate
+	}
+
+	id, err := strconv.ParseUint(pid, 10, 64)
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusNotFound, "", ErrDuesNotFound)
+		return
+	}
+
+	var dues DuesModel
+	if id == 0 {
+		dues, err = d.DuesRepository.Latest(ctx)
+	} else {
+		dues, err = d.DuesRepository.FindById(ctx, id)
+	}
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "find dues"))
+		return
+	}
+
+	if dues.Id == 0 {
+		out.Response = resp.NewResponse(http.StatusNotFound, "", ErrDuesNotFound)
+		return
+	}
+
+	h := export.Header{
+		Letterhead: d.Letterhead,
+		Title:      "Status Pembayaran Iuran Bulan " + dues.Date.Format("01-2006"),
+		Subtitle:   "Nominal iuran Rp " + dues.IdrAmount.String(),
+	}
+	if !startDate.IsZero() {
+		h.Subtitle += ", dibayar " + startDate.Format("02-01-2006") + " s/d " + endDate.Format("02-01-2006")
+	}
+
+	out.Res = ExportRes{
+		Format:   f,
+		Filename: f.Filename("iuran-"+dues.Date.Format("2006-01"), time.Now()),
+	}
+	out.Stream = func(w io.Writer) error {
+		ew, err := export.NewWriter(w, f, h, membersDuesColumns)
+		if err != nil {
+			return errors.Wrap(err, "new export writer")
+		}
+
+		var no, cursor int64
+		for {
+			memberDues, err := d.MemberDuesRepository.QueryDMVByDuesId(ctx, dues.Id, cursor, exportBatchSize, startDate, endDate)
+			if err != nil {
+				return errors.Wrap(err, "query member dues by dues id")
+			}
+
+			for _, m := range memberDues {
+				no++
+
+				payDate := "-"
+				if m.PayDate.Valid {
+					payDate = m.PayDate.Time.Format("02-01-2006")
+				}
+				paidAmt := m.IdrAmount - remainingAmt(m.Status, m.IdrAmount, m.PaidIdrAmount)
+
+				if err := ew.Write([]string{strconv.FormatInt(no, 10), m.Name, m.IdrAmount.String(), paidAmt.String(), statusLabel(m.Status), payDate}); err != nil {
+					return errors.Wrap(err, "write member dues")
+				}
+			}
+
+			if len(memberDues) < exportBatchSize {
+				break
+			}
+			cursor = int64(memberDues[len(memberDues)-1].Id)
+		}
+
+		return ew.Close()
+	}
+
+	return
+}
+
+type (
+	ExportMemberDuesQIn struct {
+		Format string
+	}
+	ExportMemberDuesOut struct {
+		resp.Response
+		Res ExportRes
+		// Stream write the export to w, it should only be called when there
+		// is no error.
+		Stream func(w io.Writer) error
+	}
+)
+
+// ExportMemberDues export the dues history of the member, only the member
+// itself or admin can export the history.
+func (d *DuesDeps) ExportMemberDues(ctx context.Context, uid, requesterUid string, qin ExportMemberDuesQIn) (out ExportMemberDuesOut) {
+	var err error
+	out.Response = resp.NewResponse(http.StatusOK, "", nil)
+
+	f, err := export.ParseFormat(qin.Format)
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusUnprocessableEntity, "", err)
+		return
+	}
+
+	if _, err = uuid.FromString(uid); err != nil {
+		out.Response = resp.NewResponse(http.StatusNotFound, "", ErrMemberNotFound)
+		return
+	}
+
+	if uid != requesterUid {
+		allowed, err := d.hasPermission(ctx, requesterUid, user.PermDuesVerify)
+		if err != nil {
+			out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "has permission"))
+			return
+		}
+
+		if !allowed {
+			out.Response = resp.NewResponse(http.StatusForbidden, "", ErrExportForbidden)
+			return
+		}
+	}
+
+	member, err := d.MemberRepository.FindById(ctx, uid)
+	if errors.Is(err, pgx.ErrNoRows) {
+		out.Response = resp.NewResponse(http.StatusNotFound, "", ErrMemberNotFound)
+		return
+	}
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "find member by id"))
+		return
+	}
+
+	paid, err := d.DuesRepository.SumAmtByUidStatus(ctx, uid, Paid)
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "sum paid dues by uid"))
+		return
+	}
+
+	unpaid, err := d.DuesRepository.SumAmtByUidStatus(ctx, uid, Unpaid)
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "sum unpaid dues by uid"))
+		return
+	}
+
+	h := export.Header{
+		Letterhead: d.Letterhead,
+		Title:      "Riwayat Iuran " + member.Name,
+		Subtitle:   "Terbayar Rp " + paid.String() + ", belum terbayar Rp " + unpaid.String(),
+	}
+
+	out.Res = ExportRes{
+		Format:   f,
+		Filename: f.Filename("riwayat-iuran-"+member.Username, time.Now()),
+	}
+	out.Stream = func(w io.Writer) error {
+		ew, err := export.NewWriter(w, f, h, memberDuesColumns)
+		if err != nil {
+			return errors.Wrap(err, "new export writer")
+		}
+
+		var no, cursor int64
+		for {
+			memberDues, err := d.MemberDuesRepository.QueryMDVPageByUid(ctx, uid, cursor, exportBatchSize)
+			if err != nil {
+				return errors.Wrap(err, "query member dues view page by uid")
+			}
+
+			for _, m := range memberDues {
+				no++
+
+				payDate := "-"
+				if m.PayDate.Valid {
+					payDate = m.PayDate.Time.Format("02-01-2006")
+				}
+				paidAmt := m.IdrAmount - remainingAmt(m.Status, m.IdrAmount, m.PaidIdrAmount)
+
+				err := ew.Write([]string{
+					strconv.FormatInt(no, 10),
+					m.Date.Format("01-2006"),
+					m.IdrAmount.String(),
+					paidAmt.String(),
+					statusLabel(m.Status),
+					payDate,
+				})
+				if err != nil {
+					return errors.Wrap(err, "write member dues")
+				}
+			}
+
+			if len(memberDues) < exportBatchSize {
+				break
+			}
+			cursor = int64(memberDues[len(memberDues)-1].Id)
+		}
+
+		return ew.Close()
+	}
+
+	return
+}
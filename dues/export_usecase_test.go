@@ -0,0 +1,158 @@
+package dues_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/dues"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/user"
+	"github.com/gofrs/uuid"
+)
+
+func TestExportMembersDues(t *testing.T) {
+	err := ClearTables(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, duesId, _, err := createMemberDues(duesDeps, memberSeed, duesSeed, paidMemDSeed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		Name               string
+		ExpectedStatusCode int
+		Id                 string
+		In                 dues.ExportMembersDuesQIn
+	}{
+		{
+			Name:               "Export Members Dues Success",
+			ExpectedStatusCode: http.StatusOK,
+			Id:                 strconv.FormatUint(duesId, 10),
+		},
+		{
+			Name:               "Export Latest Members Dues as Pdf Success",
+			ExpectedStatusCode: http.StatusOK,
+			Id:                 "0",
+			In:                 dues.ExportMembersDuesQIn{Format: "pdf"},
+		},
+		{
+			Name:               "Export Members Dues with Unknown Format Fail",
+			ExpectedStatusCode: http.StatusUnprocessableEntity,
+			Id:                 strconv.FormatUint(duesId, 10),
+			In:                 dues.ExportMembersDuesQIn{Format: "doc"},
+		},
+		{
+			Name:               "Export Members Dues Fail, Not Found",
+			ExpectedStatusCode: http.StatusNotFound,
+			Id:                 "999",
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.Name, func(t *testing.T) {
+			res := duesDeps.ExportMembersDues(context.Background(), c.Id, c.In)
+
+			if res.StatusCode != c.ExpectedStatusCode {
+				t.Logf("%#v", res)
+				t.Fatalf("Expected response code %d. Got %d\n", c.ExpectedStatusCode, res.StatusCode)
+			}
+
+			if c.ExpectedStatusCode != http.StatusOK {
+				return
+			}
+
+			var buf bytes.Buffer
+			if err := res.Stream(&buf); err != nil {
+				t.Fatal(err)
+			}
+
+			if c.In.Format == "" && !strings.Contains(buf.String(), "1,"+memberSeed.Name+",Lunas,") {
+				t.Fatalf("Expected paid member row. Got %s\n", buf.String())
+			}
+		})
+	}
+}
+
+func TestExportMemberDues(t *testing.T) {
+	err := ClearTables(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	uid1, _, _, err := createMemberDues(duesDeps, memberSeed, duesSeed, unpaidMemDSeed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nonAdmin := user.MemberModel(memberSeed2)
+	nonAdmin.IsAdmin = false
+	uid2, _ := uuid.NewV6()
+	nonAdmin.Id.Scan(uid2.String())
+	if err = memberRepository.Save(context.Background(), nonAdmin); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		Name               string
+		ExpectedStatusCode int
+		Uid                string
+		RequesterUid       string
+		In                 dues.ExportMemberDuesQIn
+	}{
+		{
+			Name:               "Export Member Dues as Owner Success",
+			ExpectedStatusCode: http.StatusOK,
+			Uid:                uid1,
+			RequesterUid:       uid1,
+		},
+		{
+			Name:               "Export Member Dues as Xlsx Success",
+			ExpectedStatusCode: http.StatusOK,
+			Uid:                uid1,
+			RequesterUid:       uid1,
+			In:                 dues.ExportMemberDuesQIn{Format: "xlsx"},
+		},
+		{
+			Name:               "Export Member Dues as Other Member Fail, Forbidden",
+			ExpectedStatusCode: http.StatusForbidden,
+			Uid:                uid1,
+			RequesterUid:       uid2.String(),
+		},
+		{
+			Name:               "Export Member Dues Fail, Not Found",
+			ExpectedStatusCode: http.StatusNotFound,
+			Uid:                "not-uuid",
+			RequesterUid:       uid1,
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.Name, func(t *testing.T) {
+			res := duesDeps.ExportMemberDues(context.Background(), c.Uid, c.RequesterUid, c.In)
+
+			if res.StatusCode != c.ExpectedStatusCode {
+				t.Logf("%#v", res)
+				t.Fatalf("Expected response code %d. Got %d\n", c.ExpectedStatusCode, res.StatusCode)
+			}
+
+			if c.ExpectedStatusCode != http.StatusOK {
+				return
+			}
+
+			var buf bytes.Buffer
+			if err := res.Stream(&buf); err != nil {
+				t.Fatal(err)
+			}
+
+			if c.In.Format == "" && !strings.Contains(buf.String(), "1,"+duesSeed.Date.Format("01-2006")+",20000,Belum Bayar,-") {
+				t.Fatalf("Expected unpaid dues row. Got %s\n", buf.String())
+			}
+		})
+	}
+}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/cashflow"
 	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/dues"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/export"
 	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/user"
 	"github.com/gofrs/uuid"
 	"github.com/jackc/pgx/v4/pgxpool"
@@ -22,8 +23,13 @@ var (
 	db                   *pgxpool.Pool
 	duesRepository       *dues.DuesRepository
 	memberDuesRepository *dues.MemberDuesRepository
+	tierRepository       *dues.TierRepository
+	overrideRepository   *dues.OverrideRepository
+	scheduleRepository   *dues.ScheduleRepository
+	paymentRepository    *dues.PaymentRepository
 	memberRepository     *user.MemberRepository
 	cashflowRepository   *cashflow.CashflowRepository
+	categoryRepository   *cashflow.CategoryRepository
 	duesDeps             *dues.DuesDeps
 	fileName             = "images.jpeg"
 	fileDir              = "./fixture/" + fileName
@@ -55,19 +61,19 @@ var (
 	}
 	duesSeed = dues.DuesModel{
 		Date:      time.Now().Add(time.Hour * 750),
-		IdrAmount: "20000",
+		IdrAmount: 20000,
 	}
 	duesSeed2 = dues.DuesModel{
 		Date:      time.Now().Add(time.Hour * 750 * 2),
-		IdrAmount: "20000",
+		IdrAmount: 20000,
 	}
 	duesSeed3 = dues.DuesModel{
 		Date:      time.Now().Add(time.Hour * 750 * 3),
-		IdrAmount: "20000",
+		IdrAmount: 20000,
 	}
 	pastDuesSeed = dues.DuesModel{
 		Date:      time.Now().Add(-1 * (time.Hour * 750)),
-		IdrAmount: "20000",
+		IdrAmount: 20000,
 	}
 	paidMemDSeed = dues.MemberDuesModel{
 		Status: dues.Paid,
@@ -81,10 +87,30 @@ var (
 	upload dues.FileUploader = func(filename string, file io.Reader) (string, error) {
 		return "", nil
 	}
+	sign dues.FileSigner = func(fileUrl string) (string, error) {
+		return fileUrl + "?signed", nil
+	}
 	captureException dues.ExceptionCapturer = func(exception error) {}
 	captureMessage   dues.MessageCapturer   = func(message string) {}
+	letterhead                              = export.Letterhead{OrgName: "U-Homestay", OrgAddress: "Bandung"}
+	scheduleDay                             = 25
+	notifier                                = &recordNotifier{}
 )
 
+// recordNotifier keep the notified reminders, so the test can check them.
+type recordNotifier struct {
+	reminders []dues.Reminder
+}
+
+func (n *recordNotifier) Name() string {
+	return "record"
+}
+
+func (n *recordNotifier) Notify(ctx context.Context, reminders []dues.Reminder) error {
+	n.reminders = append(n.reminders, reminders...)
+	return nil
+}
+
 func LoadTables(conn *pgxpool.Pool) error {
 	tx, err := conn.Begin(context.Background())
 	if err != nil {
@@ -123,7 +149,16 @@ func ClearTables(conn *pgxpool.Pool) error {
 
 	// This should be in order of which table truncate first before the other
 	queries := []string{
+		`TRUNCATE dues_schedule_runs CASCADE`,
+		`TRUNCATE dues_reminder_runs CASCADE`,
+		`TRUNCATE dues_payment_allocations CASCADE`,
+		`TRUNCATE dues_payments CASCADE`,
+		`TRUNCATE member_dues_overrides CASCADE`,
+		`TRUNCATE member_dues_tiers CASCADE`,
+		`TRUNCATE dues_tiers CASCADE`,
+		`TRUNCATE member_dues_histories CASCADE`,
 		`TRUNCATE member_dues CASCADE`,
+		`TRUNCATE cashflows CASCADE`,
 		`TRUNCATE dues CASCADE`,
 		`TRUNCATE members CASCADE`,
 	}
@@ -185,6 +220,9 @@ func createMemberDues(d *dues.DuesDeps, member user.MemberModel, duesm dues.Dues
 	memberDuesCp := dues.MemberDuesModel(memberDues)
 	memberDuesCp.MemberId = uid.String()
 	memberDuesCp.DuesId = nd2.Id
+	if memberDuesCp.IdrAmount == 0 {
+		memberDuesCp.IdrAmount = duesm.IdrAmount
+	}
 
 	nd3, err := d.MemberDuesRepository.Save(context.Background(), memberDuesCp)
 	if err != nil {
@@ -247,17 +285,33 @@ func TestMain(m *testing.M) {
 
 	duesRepository = dues.NewDeusRepository(db)
 	memberDuesRepository = dues.NewMemberDeusRepository(db)
+	tierRepository = dues.NewTierRepository(db)
+	overrideRepository = dues.NewOverrideRepository(db)
+	scheduleRepository = dues.NewScheduleRepository(db)
+	paymentRepository = dues.NewPaymentRepository(db)
 	memberRepository = user.NewMemberRepository(db)
 	cashflowRepository = cashflow.NewRepository(db)
+	categoryRepository = cashflow.NewCategoryRepository(db)
 
 	duesDeps = dues.NewDeps(
 		captureMessage,
 		captureException,
 		upload,
+		sign,
+		letterhead,
+		scheduleDay,
+		notifier,
+		dues.PaymentHooks{},
+		nil,
 		duesRepository,
 		memberDuesRepository,
+		tierRepository,
+		overrideRepository,
+		scheduleRepository,
+		paymentRepository,
 		memberRepository,
 		cashflowRepository,
+		categoryRepository,
 	)
 
 	LoadTables(db)
@@ -0,0 +1,167 @@
+package dues
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/audit"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/resp"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/user"
+	"github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrRejectReasonRequired = errors.New("alasan penolakan bukti pembayaran tidak boleh kosong")
+	ErrHistoryForbidden     = errors.New("anda tidak memiliki akses ke riwayat tagihan iuran ini")
+)
+
+// saveMemberDues update the member dues and keep the status transition in the
+// history of the member dues.
+func (d *DuesDeps) saveMemberDues(ctx context.Context, actorId string, from DuesStatus, md MemberDuesModel) error {
+	if err := d.MemberDuesRepository.UpdateById(ctx, md.Id, md); err != nil {
+		return errors.Wrap(err, "update member dues by id")
+	}
+
+	if from == md.Status {
+		return nil
+	}
+
+	history := MemberDuesHistoryModel{
+		MemberDuesId: md.Id,
+		FromStatus:   from,
+		ToStatus:     md.Status,
+	}
+	if md.Status == Rejected {
+		history.Reason = md.RejectReason
+	}
+	if actorId != "" {
+		history.ActorId.Scan(actorId)
+	}
+
+	if err := d.MemberDuesRepository.SaveHistory(ctx, history); err != nil {
+		return errors.Wrap(err, "save member dues history")
+	}
+
+	return nil
+}
+
+// rejectMemberDues reject the waiting payment of the member dues, which reject
+// every member dues covered by the payment.
+func (d *DuesDeps) rejectMemberDues(ctx context.Context, actorId string, md MemberDuesModel, reason string) resp.Response {
+	if strings.Trim(reason, " ") == "" {
+		return resp.NewResponse(http.StatusUnprocessableEntity, "", ErrRejectReasonRequired)
+	}
+
+	payment, err := d.PaymentRepository.FindWaitingByMemberDuesId(ctx, md.Id)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "find waiting payment by member dues id"))
+	}
+
+	if payment.Id != 0 {
+		err = d.rejectPayment(ctx, actorId, payment, reason)
+	} else {
+		before := md
+		md.Status = Rejected
+		md.RejectReason = reason
+		err = d.saveMemberDues(ctx, actorId, Waiting, md)
+		if err == nil {
+			err = d.Audit.Record(ctx, audit.Event{
+				Action:   audit.ActionReject,
+				Entity:   "member_dues",
+				EntityId: strconv.FormatUint(md.Id, 10),
+				Before:   before,
+				After:    md,
+			})
+		}
+	}
+	if errors.Is(err, ErrPaymentChanged) {
+		return resp.NewResponse(http.StatusConflict, "", err)
+	}
+	if err != nil {
+		return resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "reject member dues"))
+	}
+
+	return resp.NewResponse(http.StatusOK, "", nil)
+}
+
+type (
+	MemberDuesHistoryOut struct {
+		Id         int64  `json:"id"`
+		FromStatus string `json:"from_status"`
+		ToStatus   string `json:"to_status"`
+		Reason     string `json:"reason"`
+		ActorId    string `json:"actor_id"`
+		ActorName  string `json:"actor_name"`
+		CreatedAt  string `json:"created_at"`
+	}
+	QueryMemberDuesHistoryRes struct {
+		Histories []MemberDuesHistoryOut `json:"histories"`
+	}
+	QueryMemberDuesHistoryOut struct {
+		resp.Response
+		Res QueryMemberDuesHistoryRes
+	}
+)
+
+// QueryMemberDuesHistory return every status transition of the member dues,
+// only the owner of the dues or admin can see the history.
+func (d *DuesDeps) QueryMemberDuesHistory(ctx context.Context, pid, uid string) (out QueryMemberDuesHistoryOut) {
+	var err error
+	out.Response = resp.NewResponse(http.StatusOK, "", nil)
+
+	id, err := strconv.ParseUint(pid, 10, 64)
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusNotFound, "", ErrMemberDuesNotFound)
+		return
+	}
+
+	memberDues, err := d.MemberDuesRepository.FindById(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		out.Response = resp.NewResponse(http.StatusNotFound, "", ErrMemberDuesNotFound)
+		return
+	}
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "find member dues by id"))
+		return
+	}
+
+	if memberDues.MemberId != uid {
+		allowed, err := d.hasPermission(ctx, uid, user.PermDuesVerify)
+		if err != nil {
+			out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "has permission"))
+			return
+		}
+
+		if !allowed {
+			out.Response = resp.NewResponse(http.StatusForbidden, "", ErrHistoryForbidden)
+			return
+		}
+	}
+
+	histories, err := d.MemberDuesRepository.QueryHistoryByMemberDuesId(ctx, id)
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "query member dues history"))
+		return
+	}
+
+	outHistories := make([]MemberDuesHistoryOut, len(histories))
+	for i, h := range histories {
+		outHistories[i] = MemberDuesHistoryOut{
+			Id:         int64(h.Id),
+			FromStatus: h.FromStatus.String,
+			ToStatus:   h.ToStatus.String,
+			Reason:     h.Reason,
+			ActorId:    h.ActorId.String,
+			ActorName:  h.ActorName.String,
+			CreatedAt:  h.CreatedAt.Format(time.RFC3339),
+		}
+	}
+
+	out.Res.Histories = outHistories
+
+	return
+}
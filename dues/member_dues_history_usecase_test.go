@@ -0,0 +1,209 @@
+package dues_test
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"strconv"
+	"testing"
+
+	arbitary "github.com/PA-D3RPLA/d3if43-htt-uhomestay/arbitrary"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/dues"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/httpdecode"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/user"
+	"github.com/gofrs/uuid"
+	"gopkg.in/guregu/null.v4"
+)
+
+func TestRejectMemberDues(t *testing.T) {
+	err := ClearTables(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.OpenFile(fileDir, os.O_RDONLY, 0o444)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	uid, _, nd, err := createMemberDues(duesDeps, memberSeed, duesSeed, unpaidMemDSeed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pid := strconv.FormatUint(nd, 10)
+
+	res := duesDeps.PayMemberDues(context.Background(), uid, pid, dues.PayMemberDuesIn{
+		File: httpdecode.FileHeader{
+			Filename: fileName,
+			File:     f,
+		},
+	})
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected response code %d. Got %d\n", http.StatusOK, res.StatusCode)
+	}
+
+	testCases := []struct {
+		Name               string
+		ExpectedStatusCode int
+		Id                 string
+		In                 dues.PaidMemberDuesIn
+	}{
+		{
+			Name:               "Reject Member Dues Fail, Reason Required",
+			ExpectedStatusCode: http.StatusUnprocessableEntity,
+			Id:                 pid,
+			In: dues.PaidMemberDuesIn{
+				IsPaid: null.BoolFrom(false),
+			},
+		},
+		{
+			Name:               "Reject Member Dues Success",
+			ExpectedStatusCode: http.StatusOK,
+			Id:                 pid,
+			In: dues.PaidMemberDuesIn{
+				IsPaid: null.BoolFrom(false),
+				Reason: "Bukti pembayaran tidak terbaca",
+			},
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.Name, func(t *testing.T) {
+			tx, err := db.Begin(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ctx := context.WithValue(context.Background(), arbitary.TrxX{}, tx)
+			res := duesDeps.PaidMemberDues(ctx, uid, c.Id, c.In)
+			tx.Commit(context.Background())
+			tx.Rollback(context.Background())
+
+			if res.StatusCode != c.ExpectedStatusCode {
+				t.Logf("%#v", res)
+				t.Fatalf("Expected response code %d. Got %d\n", c.ExpectedStatusCode, res.StatusCode)
+			}
+		})
+	}
+
+	md, err := memberDuesRepository.FindById(context.Background(), nd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if md.Status != dues.Rejected || md.RejectReason != "Bukti pembayaran tidak terbaca" {
+		t.Fatalf("Expected member dues rejected with reason. Got %#v\n", md)
+	}
+
+	// The member re-upload the prove of payment of the rejected member dues.
+	f2, err := os.OpenFile(fileDir, os.O_RDONLY, 0o444)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res = duesDeps.PayMemberDues(context.Background(), uid, pid, dues.PayMemberDuesIn{
+		File: httpdecode.FileHeader{
+			Filename: fileName,
+			File:     f2,
+		},
+	})
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected response code %d. Got %d\n", http.StatusOK, res.StatusCode)
+	}
+
+	md, err = memberDuesRepository.FindById(context.Background(), nd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if md.Status != dues.Waiting || md.RejectReason != "" {
+		t.Fatalf("Expected member dues waiting without reason. Got %#v\n", md)
+	}
+}
+
+func TestQueryMemberDuesHistory(t *testing.T) {
+	err := ClearTables(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.OpenFile(fileDir, os.O_RDONLY, 0o444)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	uid, _, nd, err := createMemberDues(duesDeps, memberSeed, duesSeed, unpaidMemDSeed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	notAdmin := user.MemberModel(memberSeed2)
+	notAdmin.IsAdmin = false
+	nuid, _ := uuid.NewV6()
+	notAdmin.Id.Scan(nuid.String())
+	if err := memberRepository.Save(context.Background(), notAdmin); err != nil {
+		t.Fatal(err)
+	}
+
+	pid := strconv.FormatUint(nd, 10)
+
+	res := duesDeps.PayMemberDues(context.Background(), uid, pid, dues.PayMemberDuesIn{
+		File: httpdecode.FileHeader{
+			Filename: fileName,
+			File:     f,
+		},
+	})
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected response code %d. Got %d\n", http.StatusOK, res.StatusCode)
+	}
+
+	res2 := duesDeps.PaidMemberDues(context.Background(), uid, pid, dues.PaidMemberDuesIn{
+		IsPaid: null.BoolFrom(false),
+		Reason: "Nominal tidak sesuai",
+	})
+	if res2.StatusCode != http.StatusOK {
+		t.Fatalf("Expected response code %d. Got %d\n", http.StatusOK, res2.StatusCode)
+	}
+
+	testCases := []struct {
+		Name               string
+		ExpectedStatusCode int
+		ExpectedLen        int
+		Id                 string
+		Uid                string
+	}{
+		{
+			Name:               "Query Member Dues History Success",
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedLen:        2,
+			Id:                 pid,
+			Uid:                uid,
+		},
+		{
+			Name:               "Query Member Dues History Fail, Not The Owner",
+			ExpectedStatusCode: http.StatusForbidden,
+			Id:                 pid,
+			Uid:                nuid.String(),
+		},
+		{
+			Name:               "Query Member Dues History Fail, Not Found",
+			ExpectedStatusCode: http.StatusNotFound,
+			Id:                 "999",
+			Uid:                uid,
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.Name, func(t *testing.T) {
+			res := duesDeps.QueryMemberDuesHistory(context.Background(), c.Id, c.Uid)
+
+			if res.StatusCode != c.ExpectedStatusCode {
+				t.Logf("%#v", res)
+				t.Fatalf("Expected response code %d. Got %d\n", c.ExpectedStatusCode, res.StatusCode)
+			}
+
+			if len(res.Res.Histories) != c.ExpectedLen {
+				t.Fatalf("Expected histories length %d. Got %d\n", c.ExpectedLen, len(res.Res.Histories))
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"database/sql/driver"
 	"time"
 
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/money"
 	"github.com/pkg/errors"
 )
 
@@ -19,6 +20,9 @@ var (
 	Unpaid  = DuesStatus{"unpaid"}
 	Waiting = DuesStatus{"waiting"}
 	Paid    = DuesStatus{"paid"}
+	// Rejected is the member dues which prove of payment is rejected by the
+	// admin, the member can pay it again.
+	Rejected = DuesStatus{"rejected"}
 )
 
 func typeFromString(s string) (DuesStatus, error) {
@@ -29,6 +33,8 @@ func typeFromString(s string) (DuesStatus, error) {
 		return Waiting, nil
 	case Paid.String:
 		return Paid, nil
+	case Rejected.String:
+		return Rejected, nil
 	}
 
 	return Unknown, errors.New("unknown type: " + s)
@@ -64,10 +70,28 @@ type MemberDuesModel struct {
 	Id           uint64
 	DuesId       uint64
 	ProveFileUrl string
+	RejectReason string
 	MemberId     string
 	Status       DuesStatus
+	IdrAmount    money.IDR
+	// PaidIdrAmount is the amount settled by the approved payments, the
+	// member dues is paid once it reach the amount.
+	PaidIdrAmount money.IDR
+	CashflowId    sql.NullInt64
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	PayDate       sql.NullTime
+	DeletedAt     sql.NullTime
+}
+
+// MemberDuesHistoryModel is a status transition of the member dues, actor is
+// empty for the transition done by the system.
+type MemberDuesHistoryModel struct {
+	Id           uint64
+	MemberDuesId uint64
+	FromStatus   DuesStatus
+	ToStatus     DuesStatus
+	Reason       string
+	ActorId      sql.NullString
 	CreatedAt    time.Time
-	UpdatedAt    time.Time
-	PayDate      sql.NullTime
-	DeletedAt    sql.NullTime
 }
@@ -2,9 +2,12 @@ package dues
 
 import (
 	"context"
+	"database/sql"
+	"strconv"
 	"time"
 
 	arbitary "github.com/PA-D3RPLA/d3if43-htt-uhomestay/arbitrary"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/money"
 
 	"github.com/georgysavva/scany/pgxscan"
 	"github.com/jackc/pgconn"
@@ -28,18 +31,18 @@ type (
 	MemberDuesQuerier          func(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
 )
 
-func (r *MemberDuesRepository) QueryMDVByUid(ctx context.Context, uid string, id, limit int64) ([]MemberDuesViewModel, int64, error) {
-	from := `
+const mdvByUidFrom = `
 		FROM member_dues md
 			LEFT JOIN dues d ON d.id = md.dues_id
 		WHERE d.deleted_at IS NULL
 			AND md.deleted_at IS NULL
 			AND md.member_id = $1
-	`
+`
 
+func (r *MemberDuesRepository) QueryMDVByUid(ctx context.Context, uid string, id, limit int64) ([]MemberDuesViewModel, int64, error) {
 	countSqlQuery := `
 		SELECT COUNT(md.id) AS n
-		` + from + `
+		` + mdvByUidFrom + `
 	`
 
 	var n int64
@@ -52,6 +55,17 @@ func (r *MemberDuesRepository) QueryMDVByUid(ctx context.Context, uid string, id
 		return []MemberDuesViewModel{}, 0, err
 	}
 
+	ms, err := r.QueryMDVPageByUid(ctx, uid, id, limit)
+	if err != nil {
+		return []MemberDuesViewModel{}, 0, err
+	}
+
+	return ms, n, nil
+}
+
+// QueryMDVPageByUid return the page of the member dues of the member without
+// counting the total, for the caller walking through every page.
+func (r *MemberDuesRepository) QueryMDVPageByUid(ctx context.Context, uid string, id, limit int64) ([]MemberDuesViewModel, error) {
 	fromId := "md.id > $2"
 	if id != 0 {
 		fromId = "md.id < $2"
@@ -63,27 +77,32 @@ func (r *MemberDuesRepository) QueryMDVByUid(ctx context.Context, uid string, id
 			md.dues_id,
 			d.date,
 			md.status,
-			d.idr_amount,
+			md.idr_amount,
+			md.paid_idr_amount,
 			md.prove_file_url,
+			md.reject_reason,
 			md.pay_date
-		` + from + `
+		` + mdvByUidFrom + `
 			AND ` + fromId + `
 		ORDER BY md.id DESC
 		LIMIT $3
 	`
 
-	rows, _ := r.PostgreDb.Query(
+	rows, err := r.PostgreDb.Query(
 		context.Background(),
 		selectSqlQuery,
 		uid,
 		id,
 		limit,
 	)
+	if err != nil {
+		return []MemberDuesViewModel{}, err
+	}
 	defer rows.Close()
 
 	var mps []*MemberDuesViewModel
 	if err := pgxscan.ScanAll(&mps, rows); err != nil {
-		return []MemberDuesViewModel{}, 0, err
+		return []MemberDuesViewModel{}, err
 	}
 
 	ms := make([]MemberDuesViewModel, len(mps))
@@ -91,7 +110,7 @@ func (r *MemberDuesRepository) QueryMDVByUid(ctx context.Context, uid string, id
 		ms[i] = *m
 	}
 
-	return ms, n, nil
+	return ms, nil
 }
 
 func (r *MemberDuesRepository) QueryDMVByDuesId(ctx context.Context, duesId uint64, id, limit int64, startDate, endDate time.Time) ([]DuesMemberViewModel, error) {
@@ -99,17 +118,17 @@ func (r *MemberDuesRepository) QueryDMVByDuesId(ctx context.Context, duesId uint
 	selectQueryParams := []interface{}{duesId, id, limit}
 
 	if !startDate.IsZero() {
+		selectQueryParams = append(selectQueryParams, startDate.Format(time.RFC3339))
 		dateFilter = dateFilter + `
-			AND md.pay_date >= $4::timestamp
+			AND md.pay_date >= $` + strconv.Itoa(len(selectQueryParams)) + `::timestamp
 		`
-		selectQueryParams = append(selectQueryParams, startDate.Format(time.RFC3339))
 	}
 
 	if !endDate.IsZero() {
+		selectQueryParams = append(selectQueryParams, endDate.Format(time.RFC3339))
 		dateFilter = dateFilter + `
-		AND md.pay_date <= $5::timestamp
+		AND md.pay_date <= $` + strconv.Itoa(len(selectQueryParams)) + `::timestamp
 		`
-		selectQueryParams = append(selectQueryParams, endDate.Format(time.RFC3339))
 	}
 
 	fromUid := "md.id > $2"
@@ -122,6 +141,8 @@ func (r *MemberDuesRepository) QueryDMVByDuesId(ctx context.Context, duesId uint
 			md.id,
 			md.member_id,
 			md.status,
+			md.idr_amount,
+			md.paid_idr_amount,
 			md.created_at,
 			md.pay_date,
 			m.name,
@@ -157,6 +178,103 @@ func (r *MemberDuesRepository) QueryDMVByDuesId(ctx context.Context, duesId uint
 	return ms, nil
 }
 
+func (r *MemberDuesRepository) FindById(ctx context.Context, id uint64) (m MemberDuesModel, err error) {
+	querystr := `
+		SELECT
+			id,
+			member_id,
+			dues_id,
+			status,
+			idr_amount,
+			paid_idr_amount,
+			prove_file_url,
+			reject_reason,
+			cashflow_id,
+			created_at,
+			updated_at,
+			pay_date,
+			deleted_at
+		FROM member_dues 
+		WHERE deleted_at IS NULL
+			AND id = $1
+	`
+
+	var query MemberDuesQuerier
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		query = tx.Query
+	} else {
+		query = r.PostgreDb.Query
+	}
+
+	var rows pgx.Rows
+	rows, err = query(
+		context.Background(),
+		querystr,
+		id,
+	)
+
+	if err != nil {
+		return MemberDuesModel{}, err
+	}
+
+	if err = pgxscan.ScanOne(&m, rows); err != nil {
+		return MemberDuesModel{}, err
+	}
+
+	return m, nil
+}
+
+// FindByIdForUpdate return the member dues locked until the transaction of
+// the context end, so the paid amount is not changed concurrently.
+func (r *MemberDuesRepository) FindByIdForUpdate(ctx context.Context, id uint64) (m MemberDuesModel, err error) {
+	querystr := `
+		SELECT
+			id,
+			member_id,
+			dues_id,
+			status,
+			idr_amount,
+			paid_idr_amount,
+			prove_file_url,
+			reject_reason,
+			cashflow_id,
+			created_at,
+			updated_at,
+			pay_date,
+			deleted_at
+		FROM member_dues 
+		WHERE deleted_at IS NULL
+			AND id = $1
+		FOR UPDATE
+	`
+
+	var query MemberDuesQuerier
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		query = tx.Query
+	} else {
+		query = r.PostgreDb.Query
+	}
+
+	var rows pgx.Rows
+	rows, err = query(
+		context.Background(),
+		querystr,
+		id,
+	)
+
+	if err != nil {
+		return MemberDuesModel{}, err
+	}
+
+	if err = pgxscan.ScanOne(&m, rows); err != nil {
+		return MemberDuesModel{}, err
+	}
+
+	return m, nil
+}
+
 func (r *MemberDuesRepository) FindUnpaidById(ctx context.Context, id uint64) (m MemberDuesModel, err error) {
 	querystr := `
 		SELECT
@@ -164,7 +282,11 @@ func (r *MemberDuesRepository) FindUnpaidById(ctx context.Context, id uint64) (m
 			member_id,
 			dues_id,
 			status,
+			idr_amount,
+			paid_idr_amount,
 			prove_file_url,
+			reject_reason,
+			cashflow_id,
 			created_at,
 			updated_at,
 			pay_date,
@@ -208,7 +330,11 @@ func (r *MemberDuesRepository) FindUnpaidByIdAndMemberId(ctx context.Context, id
 			member_id,
 			dues_id,
 			status,
+			idr_amount,
+			paid_idr_amount,
 			prove_file_url,
+			reject_reason,
+			cashflow_id,
 			created_at,
 			updated_at,
 			pay_date,
@@ -217,7 +343,7 @@ func (r *MemberDuesRepository) FindUnpaidByIdAndMemberId(ctx context.Context, id
 		WHERE deleted_at IS NULL
 			AND id = $1
 			AND member_id = $2
-			AND status = 'unpaid'
+			AND status IN ('unpaid', 'rejected')
 	`
 
 	var query MemberDuesQuerier
@@ -247,19 +373,132 @@ func (r *MemberDuesRepository) FindUnpaidByIdAndMemberId(ctx context.Context, id
 	return m, nil
 }
 
+// QueryUnpaidByIdsAndMemberId return the unpaid or rejected member dues of the
+// member ordered from the oldest month, the payment is allocated in that order.
+// The member dues are locked until the transaction of the context end, so the
+// concurrent payment of the same months wait and find them no longer unpaid.
+func (r *MemberDuesRepository) QueryUnpaidByIdsAndMemberId(ctx context.Context, ids []int64, uid string) ([]MemberDuesModel, error) {
+	querystr := `
+		SELECT
+			md.id,
+			md.member_id,
+			md.dues_id,
+			md.status,
+			md.idr_amount,
+			md.paid_idr_amount,
+			md.prove_file_url,
+			md.reject_reason,
+			md.cashflow_id,
+			md.created_at,
+			md.updated_at,
+			md.pay_date,
+			md.deleted_at
+		FROM member_dues md
+			JOIN dues d ON d.id = md.dues_id
+		WHERE md.deleted_at IS NULL
+			AND d.deleted_at IS NULL
+			AND md.id = ANY($1::BIGINT[])
+			AND md.member_id = $2
+			AND md.status IN ('unpaid', 'rejected')
+		ORDER BY d.date, md.id
+		FOR UPDATE OF md
+	`
+
+	var query MemberDuesQuerier
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		query = tx.Query
+	} else {
+		query = r.PostgreDb.Query
+	}
+
+	rows, err := query(
+		context.Background(),
+		querystr,
+		ids,
+		uid,
+	)
+	if err != nil {
+		return []MemberDuesModel{}, err
+	}
+	defer rows.Close()
+
+	var mps []*MemberDuesModel
+	if err := pgxscan.ScanAll(&mps, rows); err != nil {
+		return []MemberDuesModel{}, err
+	}
+
+	ms := make([]MemberDuesModel, len(mps))
+	for i, m := range mps {
+		ms[i] = *m
+	}
+
+	return ms, nil
+}
+
+func (r *MemberDuesRepository) FindByDuesIdAndMemberId(ctx context.Context, duesId uint64, uid string) (m MemberDuesModel, err error) {
+	querystr := `
+		SELECT
+			id,
+			member_id,
+			dues_id,
+			status,
+			idr_amount,
+			paid_idr_amount,
+			prove_file_url,
+			reject_reason,
+			cashflow_id,
+			created_at,
+			updated_at,
+			pay_date,
+			deleted_at
+		FROM member_dues
+		WHERE deleted_at IS NULL
+			AND dues_id = $1
+			AND member_id = $2
+	`
+
+	var query MemberDuesQuerier
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		query = tx.Query
+	} else {
+		query = r.PostgreDb.Query
+	}
+
+	rows, err := query(
+		context.Background(),
+		querystr,
+		duesId,
+		uid,
+	)
+	if err != nil {
+		return MemberDuesModel{}, err
+	}
+
+	if err = pgxscan.ScanOne(&m, rows); err != nil {
+		return MemberDuesModel{}, err
+	}
+
+	return m, nil
+}
+
 func (r *MemberDuesRepository) Save(ctx context.Context, m MemberDuesModel) (nm MemberDuesModel, err error) {
 	sqlQuery := `
 		INSERT INTO member_dues (
 			member_id,
 			dues_id,
 			status,
+			idr_amount,
+			paid_idr_amount,
 			prove_file_url,
+			cashflow_id,
 			created_at,
 			updated_at,
 			pay_date,
 			deleted_at
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 		RETURNING id
 	`
 
@@ -280,7 +519,10 @@ func (r *MemberDuesRepository) Save(ctx context.Context, m MemberDuesModel) (nm
 		m.MemberId,
 		m.DuesId,
 		m.Status,
+		m.IdrAmount,
+		m.PaidIdrAmount,
 		m.ProveFileUrl,
+		m.CashflowId,
 		t,
 		t,
 		nil,
@@ -302,11 +544,14 @@ func (r *MemberDuesRepository) UpdateById(ctx context.Context, id uint64, m Memb
 	sqlQuery := `
 		UPDATE member_dues SET (
 			prove_file_url,
+			reject_reason,
 			status,
+			paid_idr_amount,
 			pay_date,
+			cashflow_id,
 			updated_at
-		) = ($1, $2, $3, $4)
-		WHERE id = $5
+		) = ($1, $2, $3, $4, $5, $6, $7)
+		WHERE id = $8
 	`
 
 	var exec MemberDuesExecutor
@@ -324,8 +569,11 @@ func (r *MemberDuesRepository) UpdateById(ctx context.Context, id uint64, m Memb
 		context.Background(),
 		sqlQuery,
 		m.ProveFileUrl,
+		m.RejectReason,
 		m.Status,
+		m.PaidIdrAmount,
 		m.PayDate,
+		m.CashflowId,
 		t,
 		id,
 	)
@@ -336,6 +584,26 @@ func (r *MemberDuesRepository) UpdateById(ctx context.Context, id uint64, m Memb
 	return nil
 }
 
+// memberDuesAmt select the amount charged to every approved member for the
+// dues of the first parameter. The override of the dues take precedence over
+// the member tier, which take precedence over the dues amount. Member charged
+// with zero amount is exempted from the dues.
+const memberDuesAmt = `
+		SELECT
+			m.id AS member_id,
+			COALESCE(o.idr_amount, t.idr_amount, d.idr_amount) AS idr_amount
+		FROM members m
+			JOIN dues d ON d.id = $1
+			LEFT JOIN member_dues_tiers mt ON mt.member_id = m.id
+			LEFT JOIN dues_tiers t ON t.id = mt.dues_tier_id
+				AND t.deleted_at IS NULL
+			LEFT JOIN member_dues_overrides o ON o.member_id = m.id
+				AND o.dues_id = d.id
+				AND o.deleted_at IS NULL
+		WHERE m.deleted_at IS NULL
+			AND m.is_approved = true
+`
+
 func (r *MemberDuesRepository) GenerateDues(ctx context.Context, duesId uint64) (err error) {
 	// Ref: PostgreSQL: insert from another table
 	// https://stackoverflow.com/a/6898775/12976234
@@ -343,6 +611,7 @@ func (r *MemberDuesRepository) GenerateDues(ctx context.Context, duesId uint64)
 		INSERT INTO member_dues (
 			dues_id,
 			status, 
+			idr_amount,
 			member_id,
 			created_at,
 			updated_at,
@@ -352,14 +621,14 @@ func (r *MemberDuesRepository) GenerateDues(ctx context.Context, duesId uint64)
 		SELECT
 			$1,
 			'unpaid',
-			id,
+			a.idr_amount,
+			a.member_id,
 			$2,
 			$3,
 			$4,
 			$5
-		FROM members 
-		WHERE deleted_at IS NULL 
-			AND is_approved = true
+		FROM (` + memberDuesAmt + `) a
+		WHERE a.idr_amount > 0
 	`
 
 	var exec MemberDuesExecutor
@@ -389,6 +658,75 @@ func (r *MemberDuesRepository) GenerateDues(ctx context.Context, duesId uint64)
 	return nil
 }
 
+// SyncAmtByDuesId recalculate the amount of the unpaid member dues of the
+// dues not partially paid yet, of every member or only of the given member. The member dues of the
+// exempted member is removed, while the member dues of the member no longer
+// exempted is generated.
+func (r *MemberDuesRepository) SyncAmtByDuesId(ctx context.Context, duesId uint64, uid string) (err error) {
+	memberFilter := `
+		AND ($2::UUID IS NULL OR a.member_id = $2::UUID)
+	`
+
+	sqlQueries := []string{
+		`
+		UPDATE member_dues md
+		SET idr_amount = a.idr_amount, updated_at = $3
+		FROM (` + memberDuesAmt + `) a
+		WHERE md.member_id = a.member_id
+			AND md.dues_id = $1
+			AND md.deleted_at IS NULL
+			AND md.status = 'unpaid'
+			AND md.paid_idr_amount = 0
+			AND a.idr_amount > 0
+			` + memberFilter,
+		`
+		UPDATE member_dues md
+		SET deleted_at = $3
+		FROM (` + memberDuesAmt + `) a
+		WHERE md.member_id = a.member_id
+			AND md.dues_id = $1
+			AND md.deleted_at IS NULL
+			AND md.status = 'unpaid'
+			AND md.paid_idr_amount = 0
+			AND a.idr_amount = 0
+			` + memberFilter,
+		`
+		INSERT INTO member_dues (dues_id, status, idr_amount, member_id, created_at, updated_at)
+		SELECT $1, 'unpaid', a.idr_amount, a.member_id, $3, $3
+		FROM (` + memberDuesAmt + `) a
+		WHERE a.idr_amount > 0
+			AND NOT EXISTS (
+				SELECT 1 FROM member_dues md
+				WHERE md.member_id = a.member_id
+					AND md.dues_id = $1
+					AND md.deleted_at IS NULL
+			)
+			` + memberFilter,
+	}
+
+	var exec MemberDuesExecutor
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		exec = tx.Exec
+	} else {
+		exec = r.PostgreDb.Exec
+	}
+
+	var member interface{}
+	if uid != "" {
+		member = uid
+	}
+
+	t := time.Now()
+	for _, q := range sqlQueries {
+		if _, err = exec(context.Background(), q, duesId, member, t); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (r *MemberDuesRepository) CheckSomeonePaid(ctx context.Context, duesId uint64) ([]MemberDuesModel, error) {
 	sqlQuery := `
 		SELECT
@@ -401,7 +739,7 @@ func (r *MemberDuesRepository) CheckSomeonePaid(ctx context.Context, duesId uint
 			deleted_at
 		FROM member_dues
 		WHERE deleted_at IS NULL 
-			AND status != 'unpaid'
+			AND (status != 'unpaid' OR paid_idr_amount > 0)
 			AND dues_id = $1
 	`
 
@@ -457,11 +795,14 @@ func (r *MemberDuesRepository) DeleteByDuesId(ctx context.Context, duesId uint64
 	return nil
 }
 
-func (r *MemberDuesRepository) QueryAmtByDuesId(ctx context.Context, duesId uint64, startDate, endDate time.Time) ([]MemberDuesAmtViewModel, error) {
+// SumAmtByDuesId return the paid and unpaid amount of the dues, partially paid
+// member dues count toward both. The date range filter the pay date of the
+// member dues.
+func (r *MemberDuesRepository) SumAmtByDuesId(ctx context.Context, duesId uint64, startDate, endDate time.Time) (paid, unpaid money.IDR, err error) {
 	sqlQuery := `
 	SELECT
-		d.idr_amount,
-		md.status
+		COALESCE(SUM(CASE WHEN md.status = 'paid' THEN md.idr_amount ELSE md.paid_idr_amount END), 0)::BIGINT AS paid,
+		COALESCE(SUM(md.idr_amount - md.paid_idr_amount) FILTER (WHERE md.status <> 'paid'), 0)::BIGINT AS unpaid
 	FROM dues d
 		LEFT JOIN member_dues md ON md.dues_id = d.id
 	WHERE d.deleted_at IS NULL
@@ -471,33 +812,33 @@ func (r *MemberDuesRepository) QueryAmtByDuesId(ctx context.Context, duesId uint
 
 	queryParams := []interface{}{duesId}
 	if !startDate.IsZero() {
+		queryParams = append(queryParams, startDate.Format(time.RFC3339))
 		sqlQuery = sqlQuery + `
-			AND md.pay_date >= $2::timestamp
+			AND md.pay_date >= $` + strconv.Itoa(len(queryParams)) + `::timestamp
 		`
-		queryParams = append(queryParams, startDate.Format(time.RFC3339))
 	}
 
 	if !endDate.IsZero() {
+		queryParams = append(queryParams, endDate.Format(time.RFC3339))
 		sqlQuery = sqlQuery + `
-		AND md.pay_date <= $3::timestamp
+		AND md.pay_date <= $` + strconv.Itoa(len(queryParams)) + `::timestamp
 		`
-		queryParams = append(queryParams, endDate.Format(time.RFC3339))
 	}
 
-	rows, _ := r.PostgreDb.Query(context.Background(), sqlQuery, queryParams...)
-	defer rows.Close()
-
-	var mps []*MemberDuesAmtViewModel
-	if err := pgxscan.ScanAll(&mps, rows); err != nil {
-		return []MemberDuesAmtViewModel{}, err
+	var queryRow MemberMemberDuesQuerierRow
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		queryRow = tx.QueryRow
+	} else {
+		queryRow = r.PostgreDb.QueryRow
 	}
 
-	ms := make([]MemberDuesAmtViewModel, len(mps))
-	for i, m := range mps {
-		ms[i] = *m
+	err = queryRow(context.Background(), sqlQuery, queryParams...).Scan(&paid, &unpaid)
+	if err != nil {
+		return 0, 0, err
 	}
 
-	return ms, nil
+	return paid, unpaid, nil
 }
 
 func (r *MemberDuesRepository) CountDMVByDuesId(ctx context.Context, duesId uint64, startDate, endDate time.Time) (int64, error) {
@@ -512,17 +853,17 @@ func (r *MemberDuesRepository) CountDMVByDuesId(ctx context.Context, duesId uint
 	countQueryParams := []interface{}{duesId}
 
 	if !startDate.IsZero() {
+		countQueryParams = append(countQueryParams, startDate.Format(time.RFC3339))
 		sqlCountQuery = sqlCountQuery + `
-		AND md.pay_date >= $2::timestamp
+		AND md.pay_date >= $` + strconv.Itoa(len(countQueryParams)) + `::timestamp
 		`
-		countQueryParams = append(countQueryParams, startDate.Format(time.RFC3339))
 	}
 
 	if !endDate.IsZero() {
+		countQueryParams = append(countQueryParams, endDate.Format(time.RFC3339))
 		sqlCountQuery = sqlCountQuery + `
-		AND md.pay_date <= $3::timestamp
+		AND md.pay_date <= $` + strconv.Itoa(len(countQueryParams)) + `::timestamp
 		`
-		countQueryParams = append(countQueryParams, endDate.Format(time.RFC3339))
 	}
 
 	var n int64
@@ -537,3 +878,286 @@ func (r *MemberDuesRepository) CountDMVByDuesId(ctx context.Context, duesId uint
 
 	return n, nil
 }
+
+// Backfill create the missing unpaid member dues of the approved member, or of
+// every approved member when uid is empty, for the dues since the given month.
+// Dues before the month the member is approved is never charged.
+func (r *MemberDuesRepository) Backfill(ctx context.Context, uid string, since sql.NullTime) (int64, error) {
+	sqlQuery := `
+		INSERT INTO member_dues (dues_id, status, idr_amount, member_id, created_at, updated_at)
+		SELECT a.dues_id, 'unpaid', a.idr_amount, a.member_id, $3, $3
+		FROM (
+			SELECT
+				d.id AS dues_id,
+				m.id AS member_id,
+				COALESCE(o.idr_amount, t.idr_amount, d.idr_amount) AS idr_amount
+			FROM members m
+				JOIN dues d ON d.deleted_at IS NULL
+					AND d.date >= date_trunc('month', COALESCE(m.approved_at, m.created_at))
+					AND ($2::TIMESTAMP IS NULL OR d.date >= $2::TIMESTAMP)
+				LEFT JOIN member_dues_tiers mt ON mt.member_id = m.id
+				LEFT JOIN dues_tiers t ON t.id = mt.dues_tier_id
+					AND t.deleted_at IS NULL
+				LEFT JOIN member_dues_overrides o ON o.member_id = m.id
+					AND o.dues_id = d.id
+					AND o.deleted_at IS NULL
+			WHERE m.deleted_at IS NULL
+				AND m.is_approved = true
+				AND ($1::UUID IS NULL OR m.id = $1::UUID)
+		) a
+		WHERE a.idr_amount > 0
+			AND NOT EXISTS (
+				SELECT 1 FROM member_dues md
+				WHERE md.member_id = a.member_id
+					AND md.dues_id = a.dues_id
+					AND md.deleted_at IS NULL
+			)
+	`
+
+	var exec MemberDuesExecutor
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		exec = tx.Exec
+	} else {
+		exec = r.PostgreDb.Exec
+	}
+
+	var member interface{}
+	if uid != "" {
+		member = uid
+	}
+
+	ct, err := exec(
+		context.Background(),
+		sqlQuery,
+		member,
+		since,
+		time.Now(),
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return ct.RowsAffected(), nil
+}
+
+// VoidUnpaid soft delete the unpaid member dues of the removed member, or of
+// every removed member when uid is empty. Partially paid member dues is kept.
+func (r *MemberDuesRepository) VoidUnpaid(ctx context.Context, uid string) (int64, error) {
+	sqlQuery := `
+		UPDATE member_dues md
+		SET deleted_at = $2
+		FROM members m
+		WHERE m.id = md.member_id
+			AND m.deleted_at IS NOT NULL
+			AND md.deleted_at IS NULL
+			AND md.status IN ('unpaid', 'rejected')
+			AND md.paid_idr_amount = 0
+			AND ($1::UUID IS NULL OR m.id = $1::UUID)
+	`
+
+	var exec MemberDuesExecutor
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		exec = tx.Exec
+	} else {
+		exec = r.PostgreDb.Exec
+	}
+
+	var member interface{}
+	if uid != "" {
+		member = uid
+	}
+
+	ct, err := exec(
+		context.Background(),
+		sqlQuery,
+		member,
+		time.Now(),
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return ct.RowsAffected(), nil
+}
+
+func (r *MemberDuesRepository) SaveHistory(ctx context.Context, m MemberDuesHistoryModel) error {
+	sqlQuery := `
+		INSERT INTO member_dues_histories (
+			member_dues_id,
+			from_status,
+			to_status,
+			reason,
+			actor_id,
+			created_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	var exec MemberDuesExecutor
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		exec = tx.Exec
+	} else {
+		exec = r.PostgreDb.Exec
+	}
+
+	_, err := exec(
+		context.Background(),
+		sqlQuery,
+		m.MemberDuesId,
+		m.FromStatus,
+		m.ToStatus,
+		m.Reason,
+		m.ActorId,
+		time.Now(),
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *MemberDuesRepository) QueryHistoryByMemberDuesId(ctx context.Context, memberDuesId uint64) ([]MemberDuesHistoryViewModel, error) {
+	sqlQuery := `
+		SELECT
+			h.id,
+			h.from_status,
+			h.to_status,
+			h.reason,
+			h.actor_id,
+			m.name AS actor_name,
+			h.created_at
+		FROM member_dues_histories h
+			LEFT JOIN members m ON m.id = h.actor_id
+		WHERE h.member_dues_id = $1
+		ORDER BY h.id
+	`
+
+	rows, err := r.PostgreDb.Query(
+		context.Background(),
+		sqlQuery,
+		memberDuesId,
+	)
+	if err != nil {
+		return []MemberDuesHistoryViewModel{}, err
+	}
+	defer rows.Close()
+
+	var mps []*MemberDuesHistoryViewModel
+	if err := pgxscan.ScanAll(&mps, rows); err != nil {
+		return []MemberDuesHistoryViewModel{}, err
+	}
+
+	ms := make([]MemberDuesHistoryViewModel, len(mps))
+	for i, m := range mps {
+		ms[i] = *m
+	}
+
+	return ms, nil
+}
+
+// QueryArrears return the members with at least the minimum months of member
+// dues overdue at the given time, the member with the most months and amount
+// overdue first.
+func (r *MemberDuesRepository) QueryArrears(ctx context.Context, at time.Time, minMonths int) ([]ArrearsViewModel, error) {
+	sqlQuery := `
+		SELECT
+			m.id AS member_id,
+			m.name,
+			m.username,
+			m.wa_phone,
+			COUNT(md.id) AS months,
+			SUM(md.idr_amount - md.paid_idr_amount)::BIGINT AS idr_amount,
+			MIN(d.date) AS oldest_date
+		FROM member_dues md
+			JOIN dues d ON d.id = md.dues_id
+			JOIN members m ON m.id = md.member_id
+		WHERE md.deleted_at IS NULL
+			AND d.deleted_at IS NULL
+			AND m.deleted_at IS NULL
+			AND md.status IN ('unpaid', 'rejected')
+			AND d.date <= $1
+		GROUP BY m.id, m.name, m.username, m.wa_phone
+		HAVING COUNT(md.id) >= $2
+		ORDER BY months DESC, idr_amount DESC, m.name
+	`
+
+	var query MemberDuesQuerier
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		query = tx.Query
+	} else {
+		query = r.PostgreDb.Query
+	}
+
+	rows, err := query(
+		context.Background(),
+		sqlQuery,
+		at,
+		minMonths,
+	)
+	if err != nil {
+		return []ArrearsViewModel{}, err
+	}
+	defer rows.Close()
+
+	var mps []*ArrearsViewModel
+	if err := pgxscan.ScanAll(&mps, rows); err != nil {
+		return []ArrearsViewModel{}, err
+	}
+
+	ms := make([]ArrearsViewModel, len(mps))
+	for i, m := range mps {
+		ms[i] = *m
+	}
+
+	return ms, nil
+}
+
+// QueryStatementByUid return every member dues of the member ordered by the
+// month of the dues.
+func (r *MemberDuesRepository) QueryStatementByUid(ctx context.Context, uid string) ([]MemberDuesViewModel, error) {
+	sqlQuery := `
+		SELECT
+			md.id,
+			md.dues_id,
+			d.date,
+			md.status,
+			md.idr_amount,
+			md.paid_idr_amount,
+			md.prove_file_url,
+			md.reject_reason,
+			md.pay_date
+		FROM member_dues md
+			JOIN dues d ON d.id = md.dues_id
+		WHERE d.deleted_at IS NULL
+			AND md.deleted_at IS NULL
+			AND md.member_id = $1
+		ORDER BY d.date, md.id
+	`
+
+	rows, err := r.PostgreDb.Query(
+		context.Background(),
+		sqlQuery,
+		uid,
+	)
+	if err != nil {
+		return []MemberDuesViewModel{}, err
+	}
+	defer rows.Close()
+
+	var mps []*MemberDuesViewModel
+	if err := pgxscan.ScanAll(&mps, rows); err != nil {
+		return []MemberDuesViewModel{}, err
+	}
+
+	ms := make([]MemberDuesViewModel, len(mps))
+	for i, m := range mps {
+		ms[i] = *m
+	}
+
+	return ms, nil
+}
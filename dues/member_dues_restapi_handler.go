@@ -11,10 +11,21 @@ import (
 )
 
 func (d *DuesDeps) GetMemberDues(w http.ResponseWriter, r *http.Request) {
+	var requesterUid string
+	if jwt.HasClaims(r) {
+		var jwtPayload jwt.JwtPrivateClaim
+		if err := jwt.DecodeCustomClaims(r, &jwtPayload); err != nil {
+			d.CaptureExeption(err)
+			resp.NewResponse(http.StatusInternalServerError, "", err).HttpJSON(w, nil)
+			return
+		}
+		requesterUid = jwtPayload.Uid
+	}
+
 	cursor := r.URL.Query().Get("cursor")
 	id := chi.URLParam(r, "id")
 	limit := r.URL.Query().Get("limit")
-	out := d.QueryMemberDues(r.Context(), id, cursor, limit)
+	out := d.QueryMemberDues(r.Context(), id, requesterUid, cursor, limit)
 	if out.Error != nil {
 		d.CaptureExeption(out.Error)
 	}
@@ -75,6 +86,13 @@ func (d *DuesDeps) PutMemberDues(w http.ResponseWriter, r *http.Request) {
 }
 
 func (d *DuesDeps) PatchMemberDues(w http.ResponseWriter, r *http.Request) {
+	var jwtPayload jwt.JwtPrivateClaim
+	if err := jwt.DecodeCustomClaims(r, &jwtPayload); err != nil {
+		d.CaptureExeption(err)
+		resp.NewResponse(http.StatusInternalServerError, "", err).HttpJSON(w, nil)
+		return
+	}
+
 	decoder := json.NewDecoder(r.Body)
 
 	var in PaidMemberDuesIn
@@ -86,7 +104,23 @@ func (d *DuesDeps) PatchMemberDues(w http.ResponseWriter, r *http.Request) {
 	}
 
 	id := chi.URLParam(r, "id")
-	out := d.PaidMemberDues(r.Context(), id, in)
+	out := d.PaidMemberDues(r.Context(), jwtPayload.Uid, id, in)
+	if out.Error != nil {
+		d.CaptureExeption(out.Error)
+	}
+	out.HttpJSON(w, resp.NewHttpBody(out.Res))
+}
+
+func (d *DuesDeps) GetMemberDuesHistory(w http.ResponseWriter, r *http.Request) {
+	var jwtPayload jwt.JwtPrivateClaim
+	if err := jwt.DecodeCustomClaims(r, &jwtPayload); err != nil {
+		d.CaptureExeption(err)
+		resp.NewResponse(http.StatusInternalServerError, "", err).HttpJSON(w, nil)
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	out := d.QueryMemberDuesHistory(r.Context(), id, jwtPayload.Uid)
 	if out.Error != nil {
 		d.CaptureExeption(out.Error)
 	}
@@ -2,14 +2,17 @@ package dues
 
 import (
 	"context"
+	"database/sql"
 	"net/http"
 	"strconv"
 	"strings"
 	"time"
 
-	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/cashflow"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/audit"
 	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/httpdecode"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/money"
 	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/resp"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/user"
 	"github.com/gofrs/uuid"
 	"github.com/jackc/pgx/v4"
 	"github.com/pkg/errors"
@@ -19,17 +22,45 @@ import (
 var (
 	ErrMemberNotFound     = errors.New("anggota tidak ditemukan")
 	ErrMemberDuesNotFound = errors.New("tagihan iuran bulanan anggota tidak ditemukan")
+	ErrFileNotFound       = errors.New("bukti pembayaran iuran tidak ditemukan")
+	ErrLoginRequired      = errors.New("silakan login untuk mengakses file ini")
+	ErrFileForbidden      = errors.New("anda tidak memiliki akses ke file ini")
 )
 
+// fileEndpoint is where the prove file of the member dues can be downloaded,
+// the raw storage url is never handed to the client.
+func fileEndpoint(id uint64, proveFileUrl string) string {
+	if proveFileUrl == "" {
+		return ""
+	}
+
+	return "/api/v1/files/member_dues/" + strconv.FormatUint(id, 10)
+}
+
+// remainingAmt return the balance left to pay, member dues paid before the
+// payment is recorded has no paid amount.
+func remainingAmt(status DuesStatus, amt, paidAmt money.IDR) money.IDR {
+	if status == Paid || paidAmt >= amt {
+		return 0
+	}
+
+	return amt - paidAmt
+}
+
 type (
 	MemberDuesOut struct {
-		Id           int64  `json:"id"`
-		DuesId       int64  `json:"dues_id"`
-		Date         string `json:"date"`
-		Status       string `json:"status"`
-		IdrAmout     string `json:"idr_amount"`
-		ProveFileUrl string `json:"prove_file_url"`
-		PayDate      string `json:"pay_date"`
+		Id       int64  `json:"id"`
+		DuesId   int64  `json:"dues_id"`
+		Date     string `json:"date"`
+		Status   string `json:"status"`
+		IdrAmout string `json:"idr_amount"`
+		// PaidIdrAmount is the amount settled so far, the rest is the
+		// remaining balance of the member dues.
+		PaidIdrAmount      string `json:"paid_idr_amount"`
+		RemainingIdrAmount string `json:"remaining_idr_amount"`
+		ProveFileUrl       string `json:"prove_file_url"`
+		RejectReason       string `json:"reject_reason"`
+		PayDate            string `json:"pay_date"`
 	}
 	MemberDuesRes struct {
 		Cursor     int64           `json:"cursor"`
@@ -45,7 +76,9 @@ type (
 	}
 )
 
-func (d *DuesDeps) QueryMemberDues(ctx context.Context, uid string, cursor, limit string) (out QueryMemberDuesOut) {
+// QueryMemberDues return the member dues of the member, the reject reason is
+// shown only to the member itself or the dues verifier.
+func (d *DuesDeps) QueryMemberDues(ctx context.Context, uid, requesterUid string, cursor, limit string) (out QueryMemberDuesOut) {
 	var err error
 	out.Response = resp.NewResponse(http.StatusOK, "", nil)
 
@@ -66,28 +99,31 @@ func (d *DuesDeps) QueryMemberDues(ctx context.Context, uid string, cursor, limi
 		return
 	}
 
-	duefFlow := func(ctx context.Context, uid string, status DuesStatus, dues chan float64, res chan resp.Response) {
-		var r resp.Response
-		amts, err := d.DuesRepository.QueryAmtByUidStatus(ctx, uid, status)
+	showReason := uid == requesterUid
+	if !showReason && requesterUid != "" {
+		showReason, err = d.hasPermission(ctx, requesterUid, user.PermDuesVerify)
 		if err != nil {
-			r = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "query "+status.String+" dues by uid"))
+			out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "has permission"))
+			return
 		}
+	}
 
-		var namts float64
-		for _, u := range amts {
-			cash, _ := strconv.ParseFloat(u, 64)
-			namts += cash
+	duefFlow := func(ctx context.Context, uid string, status DuesStatus, dues chan money.IDR, res chan resp.Response) {
+		var r resp.Response
+		amt, err := d.DuesRepository.SumAmtByUidStatus(ctx, uid, status)
+		if err != nil {
+			r = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "sum "+status.String+" dues by uid"))
 		}
 
-		dues <- namts
+		dues <- amt
 		res <- r
 	}
 
-	paidDues := make(chan float64)
+	paidDues := make(chan money.IDR)
 	pRes := make(chan resp.Response)
 	go duefFlow(ctx, uid, Paid, paidDues, pRes)
 
-	unpaidDues := make(chan float64)
+	unpaidDues := make(chan money.IDR)
 	uRes := make(chan resp.Response)
 	go duefFlow(ctx, uid, Unpaid, unpaidDues, uRes)
 
@@ -128,14 +164,22 @@ func (d *DuesDeps) QueryMemberDues(ctx context.Context, uid string, cursor, limi
 				payDate = d.PayDate.Time.Format("2006-01-02")
 			}
 
+			rejectReason := ""
+			if showReason {
+				rejectReason = d.RejectReason
+			}
+
 			outMemberDues[i] = MemberDuesOut{
-				Id:           int64(d.Id),
-				DuesId:       int64(d.DuesId),
-				Date:         d.Date.Format("2006-01"),
-				Status:       status.String,
-				IdrAmout:     d.IdrAmount,
-				ProveFileUrl: d.ProveFileUrl,
-				PayDate:      payDate,
+				Id:                 int64(d.Id),
+				DuesId:             int64(d.DuesId),
+				Date:               d.Date.Format("2006-01"),
+				Status:             status.String,
+				IdrAmout:           d.IdrAmount.String(),
+				PaidIdrAmount:      d.PaidIdrAmount.String(),
+				RemainingIdrAmount: remainingAmt(d.Status, d.IdrAmount, d.PaidIdrAmount).String(),
+				ProveFileUrl:       fileEndpoint(d.Id, d.ProveFileUrl),
+				RejectReason:       rejectReason,
+				PayDate:            payDate,
 			}
 		}
 
@@ -172,9 +216,9 @@ func (d *DuesDeps) QueryMemberDues(ctx context.Context, uid string, cursor, limi
 	out.Res = MemberDuesRes{
 		Cursor:     nextCursorV,
 		Total:      memberDuesNV,
-		TotalDues:  strconv.FormatFloat(paidDuesV, 'f', -1, 64),
-		PaidDues:   strconv.FormatFloat(paidDuesV, 'f', -1, 64),
-		UnpaidDues: strconv.FormatFloat(unpaidDuesV, 'f', -1, 64),
+		TotalDues:  paidDuesV.String(),
+		PaidDues:   paidDuesV.String(),
+		UnpaidDues: unpaidDuesV.String(),
 		Dues:       outMemberDuesV,
 	}
 
@@ -183,12 +227,15 @@ func (d *DuesDeps) QueryMemberDues(ctx context.Context, uid string, cursor, limi
 
 type (
 	MembersDuesOut struct {
-		Id            int64  `json:"id"`
-		MemberId      string `json:"member_id"`
-		Status        string `json:"status"`
-		Name          string `json:"name"`
-		ProfilePicUrl string `json:"profile_pic_url"`
-		PayDate       string `json:"pay_date"`
+		Id                 int64  `json:"id"`
+		MemberId           string `json:"member_id"`
+		Status             string `json:"status"`
+		IdrAmount          string `json:"idr_amount"`
+		PaidIdrAmount      string `json:"paid_idr_amount"`
+		RemainingIdrAmount string `json:"remaining_idr_amount"`
+		Name               string `json:"name"`
+		ProfilePicUrl      string `json:"profile_pic_url"`
+		PayDate            string `json:"pay_date"`
 	}
 	QueryMembersDuesRes struct {
 		DuesId     int64            `json:"dues_id"`
@@ -266,36 +313,23 @@ func (d *DuesDeps) QueryMembersDues(ctx context.Context, pid string, qin QueryMe
 		ctx context.Context,
 		duesId uint64,
 		startDate, endDate time.Time,
-		paidDues chan float64,
-		unpaidDues chan float64,
+		paidDues chan money.IDR,
+		unpaidDues chan money.IDR,
 		res chan resp.Response,
 	) {
 		var r resp.Response
-		amts, err := d.MemberDuesRepository.QueryAmtByDuesId(ctx, duesId, startDate, endDate)
+		paid, unpaid, err := d.MemberDuesRepository.SumAmtByDuesId(ctx, duesId, startDate, endDate)
 		if err != nil {
-			r = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "query dues amt by dues id"))
-		}
-
-		var pdues float64
-		var updues float64
-		for _, u := range amts {
-			cash, _ := strconv.ParseFloat(u.IdrAmount, 64)
-
-			if u.Status == Paid {
-				pdues += cash
-				continue
-			}
-
-			updues += cash
+			r = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "sum dues amt by dues id"))
 		}
 
-		paidDues <- pdues
-		unpaidDues <- updues
+		paidDues <- paid
+		unpaidDues <- unpaid
 		res <- r
 	}
 
-	paidDues := make(chan float64)
-	unpaidDues := make(chan float64)
+	paidDues := make(chan money.IDR)
+	unpaidDues := make(chan money.IDR)
 	pRes := make(chan resp.Response)
 	go duefFlow(ctx, dues.Id, startDate, endDate, paidDues, unpaidDues, pRes)
 
@@ -350,12 +384,15 @@ func (d *DuesDeps) QueryMembersDues(ctx context.Context, pid string, qin QueryMe
 			}
 
 			outMembersDues[i] = MembersDuesOut{
-				Id:            int64(m.Id),
-				MemberId:      m.MemberId,
-				Status:        status.String,
-				Name:          m.Name,
-				ProfilePicUrl: m.ProfilePicUrl,
-				PayDate:       payDate,
+				Id:                 int64(m.Id),
+				MemberId:           m.MemberId,
+				Status:             status.String,
+				IdrAmount:          m.IdrAmount.String(),
+				PaidIdrAmount:      m.PaidIdrAmount.String(),
+				RemainingIdrAmount: remainingAmt(m.Status, m.IdrAmount, m.PaidIdrAmount).String(),
+				Name:               m.Name,
+				ProfilePicUrl:      m.ProfilePicUrl,
+				PayDate:            payDate,
 			}
 		}
 
@@ -387,11 +424,11 @@ func (d *DuesDeps) QueryMembersDues(ctx context.Context, pid string, qin QueryMe
 		DuesId:     int64(dues.Id),
 		Cursor:     nextCursorV,
 		DuesDate:   dues.Date.Format("2006-01-02"),
-		DuesAmount: dues.IdrAmount,
+		DuesAmount: dues.IdrAmount.String(),
 		MemberDues: outMemberDuesV,
 		Total:      memberDuesNV,
-		PaidDues:   strconv.FormatFloat(paidDuesV, 'f', -1, 64),
-		UnpaidDues: strconv.FormatFloat(unpaidDuesV, 'f', -1, 64),
+		PaidDues:   paidDuesV.String(),
+		UnpaidDues: unpaidDuesV.String(),
 	}
 
 	return
@@ -461,12 +498,19 @@ func (d *DuesDeps) PayMemberDues(ctx context.Context, uid, pid string, in PayMem
 		}
 	}
 
-	memberDues.Status = Waiting
-	memberDues.ProveFileUrl = fileUrl
-	memberDues.PayDate.Scan(time.Now())
+	payment := PaymentModel{
+		MemberId:     uid,
+		Status:       PaymentWaiting,
+		ProveFileUrl: fileUrl,
+		PayDate:      time.Now(),
+	}
+	if payment, err = d.submitPayment(ctx, uid, payment, []MemberDuesModel{memberDues}); err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "submit payment"))
+		return
+	}
 
-	if err = d.MemberDuesRepository.UpdateById(ctx, id, memberDues); err != nil {
-		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "save member dues"))
+	if err = runPaymentHooks(ctx, d.PaymentHooks.OnSubmitted, payment); err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "run payment submitted hooks"))
 		return
 	}
 
@@ -533,6 +577,7 @@ func (d *DuesDeps) EditMemberDues(ctx context.Context, pid string, in EditMember
 		}
 	}
 
+	before := memberDues
 	memberDues.ProveFileUrl = fileUrl
 
 	if err = d.MemberDuesRepository.UpdateById(ctx, id, memberDues); err != nil {
@@ -540,6 +585,32 @@ func (d *DuesDeps) EditMemberDues(ctx context.Context, pid string, in EditMember
 		return
 	}
 
+	err = d.Audit.Record(ctx, audit.Event{
+		Action:   audit.ActionUpdate,
+		Entity:   "member_dues",
+		EntityId: strconv.FormatUint(id, 10),
+		Before:   before,
+		After:    memberDues,
+	})
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "record audit"))
+		return
+	}
+
+	// The prove file is shared by every member dues of the waiting payment.
+	payment, err := d.PaymentRepository.FindWaitingByMemberDuesId(ctx, id)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "find waiting payment by member dues id"))
+		return
+	}
+
+	if payment.Id != 0 {
+		if err = d.setPaymentFile(ctx, payment, fileUrl); err != nil {
+			out.Response = resp.NewResponse(http.StatusInternalServerError, "", err)
+			return
+		}
+	}
+
 	out.Res.Id = int64(id)
 
 	return
@@ -548,6 +619,8 @@ func (d *DuesDeps) EditMemberDues(ctx context.Context, pid string, in EditMember
 type (
 	PaidMemberDuesIn struct {
 		IsPaid null.Bool `json:"is_paid"`
+		// Reason is required to reject the waiting payment.
+		Reason string `json:"reason"`
 	}
 	PaidMemberDuesRes struct {
 		Id int64 `json:"id"`
@@ -558,7 +631,12 @@ type (
 	}
 )
 
-func (d *DuesDeps) PaidMemberDues(ctx context.Context, pid string, in PaidMemberDuesIn) (out PaidMemberDuesOut) {
+// PaidMemberDues approve the waiting payment of the member dues, which settle
+// every member dues covered by the payment. The approved payment is recorded
+// as income cashflow. Disapproving reject the waiting payment with the reason,
+// or revert the approved payments covering the member dues and void the
+// cashflow, both happen in the same transaction.
+func (d *DuesDeps) PaidMemberDues(ctx context.Context, uid, pid string, in PaidMemberDuesIn) (out PaidMemberDuesOut) {
 	var err error
 	out.Response = resp.NewResponse(http.StatusOK, "", nil)
 
@@ -573,59 +651,203 @@ func (d *DuesDeps) PaidMemberDues(ctx context.Context, pid string, in PaidMember
 		return
 	}
 
+	if !in.IsPaid.Bool {
+		out = d.revertPaidMemberDues(ctx, uid, id, in.Reason)
+		return
+	}
+
 	memberDues, err := d.MemberDuesRepository.FindUnpaidById(ctx, id)
 	if errors.Is(err, pgx.ErrNoRows) {
 		out.Response = resp.NewResponse(http.StatusNotFound, "", ErrMemberDuesNotFound)
 		return
 	}
 	if err != nil {
-		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "find document by id"))
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "find unpaid member dues by id"))
 		return
 	}
 
-	dues, err := d.DuesRepository.FindById(ctx, memberDues.DuesId)
-	if errors.Is(err, pgx.ErrNoRows) {
-		out.Response = resp.NewResponse(http.StatusNotFound, "", ErrDuesNotFound)
+	payment, err := d.PaymentRepository.FindWaitingByMemberDuesId(ctx, id)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "find waiting payment by member dues id"))
+		return
+	}
+
+	// Member dues without waiting payment is paid in cash directly to the
+	// admin, the remaining balance is recorded as a payment of its own.
+	if payment.Id == 0 {
+		payment = PaymentModel{
+			MemberId:     memberDues.MemberId,
+			Status:       PaymentWaiting,
+			ProveFileUrl: memberDues.ProveFileUrl,
+			PayDate:      time.Now(),
+		}
+		if memberDues.PayDate.Valid {
+			payment.PayDate = memberDues.PayDate.Time
+		}
+
+		if payment, err = d.submitPayment(ctx, uid, payment, []MemberDuesModel{memberDues}); err != nil {
+			out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "submit payment"))
+			return
+		}
+	}
+
+	err = d.approvePayment(ctx, uid, payment)
+	if errors.Is(err, ErrPaymentExceed) || errors.Is(err, ErrPaymentChanged) {
+		out.Response = resp.NewResponse(http.StatusConflict, "", err)
 		return
 	}
 	if err != nil {
-		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "find document by id"))
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "approve payment"))
 		return
 	}
 
-	member, err := d.MemberRepository.FindById(ctx, memberDues.MemberId)
+	out.Res.Id = int64(id)
+
+	return
+}
+
+// revertPaidMemberDues reject the waiting payment of the member dues, or
+// revert the approved payments of the member dues, the member dues goes back
+// to waiting for approval, or unpaid when there is no prove file.
+func (d *DuesDeps) revertPaidMemberDues(ctx context.Context, uid string, id uint64, reason string) (out PaidMemberDuesOut) {
+	var err error
+	out.Response = resp.NewResponse(http.StatusOK, "", nil)
+
+	memberDues, err := d.MemberDuesRepository.FindById(ctx, id)
 	if errors.Is(err, pgx.ErrNoRows) {
-		out.Response = resp.NewResponse(http.StatusNotFound, "", ErrMemberNotFound)
+		out.Response = resp.NewResponse(http.StatusNotFound, "", ErrMemberDuesNotFound)
 		return
 	}
 	if err != nil {
-		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "find member by id"))
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "find member dues by id"))
 		return
 	}
 
-	if in.IsPaid.Valid && in.IsPaid.Bool {
-		memberDues.Status = Paid
+	out.Res.Id = int64(id)
+
+	if memberDues.Status == Waiting {
+		out.Response = d.rejectMemberDues(ctx, uid, memberDues, reason)
+		return
 	}
 
-	if err = d.MemberDuesRepository.UpdateById(ctx, id, memberDues); err != nil {
-		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "update document by id"))
+	payments, err := d.PaymentRepository.QueryApprovedByMemberDuesId(ctx, id)
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "query approved payment by member dues id"))
 		return
 	}
 
-	cashflow := cashflow.CashflowModel{
-		Date:         memberDues.CreatedAt,
-		IdrAmount:    dues.IdrAmount,
-		Type:         cashflow.Income,
-		Note:         "Pembayaran Iuran Anggota, Nama " + member.Name,
-		ProveFileUrl: memberDues.ProveFileUrl,
+	if len(payments) != 0 {
+		for _, p := range payments {
+			err = d.revertPayment(ctx, uid, p)
+			if errors.Is(err, ErrPaymentChanged) {
+				out.Response = resp.NewResponse(http.StatusConflict, "", err)
+				return
+			}
+			if err != nil {
+				out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "revert payment"))
+				return
+			}
+		}
+		return
 	}
 
-	if cashflow, err = d.CashflowRepository.Save(ctx, cashflow); err != nil {
-		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "save cashflow"))
+	// Member dues paid before the payment is recorded has no payment to revert.
+	if memberDues.Status != Paid {
 		return
 	}
 
-	out.Res.Id = int64(id)
+	if memberDues.CashflowId.Valid {
+		if err = d.CashflowRepository.DeleteById(ctx, uint64(memberDues.CashflowId.Int64)); err != nil {
+			out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "delete cashflow by id"))
+			return
+		}
+	}
+
+	before := memberDues
+	memberDues.Status = Waiting
+	if memberDues.ProveFileUrl == "" {
+		memberDues.Status = Unpaid
+		memberDues.PayDate = sql.NullTime{}
+	}
+	memberDues.CashflowId = sql.NullInt64{}
+
+	if err = d.saveMemberDues(ctx, uid, Paid, memberDues); err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", err)
+		return
+	}
+
+	err = d.Audit.Record(ctx, audit.Event{
+		Action:   audit.ActionRevert,
+		Entity:   "member_dues",
+		EntityId: strconv.FormatUint(id, 10),
+		Before:   before,
+		After:    memberDues,
+	})
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "record audit"))
+		return
+	}
+
+	return
+}
+
+type (
+	MemberDuesFileRes struct {
+		Url string `json:"url"`
+	}
+	MemberDuesFileOut struct {
+		resp.Response
+		Res MemberDuesFileRes
+	}
+)
+
+// FindMemberDuesFile return short-lived signed url of the member dues prove
+// file, only the owner of the dues or admin can see the prove file.
+func (d *DuesDeps) FindMemberDuesFile(ctx context.Context, pid, uid string) (out MemberDuesFileOut) {
+	var err error
+	out.Response = resp.NewResponse(http.StatusOK, "", nil)
+
+	if uid == "" {
+		out.Response = resp.NewResponse(http.StatusUnauthorized, "", ErrLoginRequired)
+		return
+	}
+
+	id, err := strconv.ParseUint(pid, 10, 64)
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusNotFound, "", ErrFileNotFound)
+		return
+	}
+
+	memberDues, err := d.MemberDuesRepository.FindById(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) || memberDues.ProveFileUrl == "" {
+		out.Response = resp.NewResponse(http.StatusNotFound, "", ErrFileNotFound)
+		return
+	}
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "find member dues by id"))
+		return
+	}
+
+	if memberDues.MemberId != uid {
+		allowed, err := d.hasPermission(ctx, uid, user.PermDuesVerify)
+		if err != nil {
+			out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "has permission"))
+			return
+		}
+
+		if !allowed {
+			out.Response = resp.NewResponse(http.StatusForbidden, "", ErrFileForbidden)
+			return
+		}
+	}
+
+	url, err := d.Sign(memberDues.ProveFileUrl)
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "sign file url"))
+		return
+	}
+
+	out.Res.Url = url
 
 	return
 }
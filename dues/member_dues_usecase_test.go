@@ -9,8 +9,11 @@ import (
 	"testing"
 
 	arbitary "github.com/PA-D3RPLA/d3if43-htt-uhomestay/arbitrary"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/cashflow"
 	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/dues"
 	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/httpdecode"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/user"
+	"github.com/gofrs/uuid"
 	"gopkg.in/guregu/null.v4"
 )
 
@@ -55,7 +58,7 @@ func TestQueryMemberDues(t *testing.T) {
 			}
 
 			ctx := context.WithValue(context.Background(), arbitary.TrxX{}, tx)
-			res := duesDeps.QueryMemberDues(ctx, c.Id, "", "")
+			res := duesDeps.QueryMemberDues(ctx, c.Id, "", "", "")
 			tx.Commit(context.Background())
 			tx.Rollback(context.Background())
 
@@ -68,6 +71,83 @@ func TestQueryMemberDues(t *testing.T) {
 	}
 }
 
+func TestQueryMemberDuesRejectReason(t *testing.T) {
+	err := ClearTables(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	adminUid, _, err := createMemberNDues(duesDeps, memberSeed, duesSeed2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	uid, _, mdid, err := createMemberDues(duesDeps, memberSeed2, pastDuesSeed, unpaidMemDSeed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	notAdmin, err := memberRepository.FindById(context.Background(), uid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	notAdmin.IsAdmin = false
+	if err := memberRepository.Update(context.Background(), uid, notAdmin); err != nil {
+		t.Fatal(err)
+	}
+
+	md, err := memberDuesRepository.FindById(context.Background(), mdid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	md.Status = dues.Rejected
+	md.RejectReason = "bukti transfer tidak terbaca"
+	if err := memberDuesRepository.UpdateById(context.Background(), mdid, md); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		Name                 string
+		RequesterUid         string
+		ExpectedRejectReason string
+	}{
+		{
+			Name:                 "Query Member Dues as Owner Show Reject Reason",
+			RequesterUid:         uid,
+			ExpectedRejectReason: md.RejectReason,
+		},
+		{
+			Name:                 "Query Member Dues as Admin Show Reject Reason",
+			RequesterUid:         adminUid,
+			ExpectedRejectReason: md.RejectReason,
+		},
+		{
+			Name:                 "Query Member Dues as Anonymous Hide Reject Reason",
+			RequesterUid:         "",
+			ExpectedRejectReason: "",
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.Name, func(t *testing.T) {
+			res := duesDeps.QueryMemberDues(context.Background(), uid, c.RequesterUid, "", "")
+
+			if res.StatusCode != http.StatusOK {
+				t.Logf("%#v", res)
+				t.Fatalf("Expected response code %d. Got %d\n", http.StatusOK, res.StatusCode)
+			}
+
+			if len(res.Res.Dues) != 1 {
+				t.Fatalf("Expected 1 member dues. Got %d\n", len(res.Res.Dues))
+			}
+
+			if res.Res.Dues[0].RejectReason != c.ExpectedRejectReason {
+				t.Fatalf("Expected reject reason %q. Got %q\n", c.ExpectedRejectReason, res.Res.Dues[0].RejectReason)
+			}
+		})
+	}
+}
+
 func TestQueryMembersDues(t *testing.T) {
 	err := ClearTables(db)
 	if err != nil {
@@ -403,6 +483,22 @@ func TestPaidMemberDues(t *testing.T) {
 				IsPaid: null.BoolFrom(true),
 			},
 		},
+		{
+			Name:               "Revert Paid Member Dues Success",
+			ExpectedStatusCode: http.StatusOK,
+			Id:                 pid1,
+			In: dues.PaidMemberDuesIn{
+				IsPaid: null.BoolFrom(false),
+			},
+		},
+		{
+			Name:               "Paid Reverted Member Dues to Paid Success",
+			ExpectedStatusCode: http.StatusOK,
+			Id:                 pid1,
+			In: dues.PaidMemberDuesIn{
+				IsPaid: null.BoolFrom(true),
+			},
+		},
 		{
 			Name:               "Paid Unpaid Member Dues to Paid Fail, Dues Already Paid",
 			ExpectedStatusCode: http.StatusNotFound,
@@ -435,7 +531,7 @@ func TestPaidMemberDues(t *testing.T) {
 			}
 
 			ctx := context.WithValue(context.Background(), arbitary.TrxX{}, tx)
-			res := duesDeps.PaidMemberDues(ctx, c.Id, c.In)
+			res := duesDeps.PaidMemberDues(ctx, "", c.Id, c.In)
 			tx.Commit(context.Background())
 			tx.Rollback(context.Background())
 
@@ -446,4 +542,107 @@ func TestPaidMemberDues(t *testing.T) {
 			}
 		})
 	}
+	// Only the last approval of the member dues is recorded as income.
+	md, err := memberDuesRepository.FindById(context.Background(), nd1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if md.Status != dues.Paid || !md.CashflowId.Valid {
+		t.Fatalf("Expected member dues paid and linked to cashflow. Got %#v\n", md)
+	}
+
+	amt, err := cashflowRepository.SumAmtByType(context.Background(), cashflow.Income)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if amt != duesSeed.IdrAmount {
+		t.Fatalf("Expected income %s. Got %s\n", duesSeed.IdrAmount, amt)
+	}
+}
+
+func TestFindMemberDuesFile(t *testing.T) {
+	err := ClearTables(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	withFile := dues.MemberDuesModel(paidMemDSeed)
+	withFile.ProveFileUrl = "https://res.cloudinary.com/demo/raw/private/v1/uhomestay/dues/proof.pdf"
+
+	uid1, _, nd1, err := createMemberDues(duesDeps, memberSeed, duesSeed, withFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, nd2, err := createMemberDues(duesDeps, memberSeed2, duesSeed2, unpaidMemDSeed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nonAdmin := user.MemberModel(memberSeed2)
+	nonAdmin.Username = "nonadminusername"
+	nonAdmin.WaPhone = "+62 821-1111-0002"
+	nonAdmin.OtherPhone = "+62 821-1111-0002"
+	nonAdmin.IsAdmin = false
+	uid3, _ := uuid.NewV6()
+	nonAdmin.Id.Scan(uid3.String())
+	if err = memberRepository.Save(context.Background(), nonAdmin); err != nil {
+		t.Fatal(err)
+	}
+
+	pid1 := strconv.FormatUint(nd1, 10)
+	pid2 := strconv.FormatUint(nd2, 10)
+
+	testCases := []struct {
+		Name               string
+		ExpectedStatusCode int
+		Id                 string
+		Uid                string
+	}{
+		{
+			Name:               "Find Member Dues File as Owner Success",
+			ExpectedStatusCode: http.StatusOK,
+			Id:                 pid1,
+			Uid:                uid1,
+		},
+		{
+			Name:               "Find Member Dues File as Other Member Fail, Forbidden",
+			ExpectedStatusCode: http.StatusForbidden,
+			Id:                 pid1,
+			Uid:                uid3.String(),
+		},
+		{
+			Name:               "Find Member Dues File as Anonymous Fail, Unauthorized",
+			ExpectedStatusCode: http.StatusUnauthorized,
+			Id:                 pid1,
+			Uid:                "",
+		},
+		{
+			Name:               "Find Member Dues File Fail, No Prove File",
+			ExpectedStatusCode: http.StatusNotFound,
+			Id:                 pid2,
+			Uid:                uid1,
+		},
+		{
+			Name:               "Find Member Dues File Fail, Not Found",
+			ExpectedStatusCode: http.StatusNotFound,
+			Id:                 "999",
+			Uid:                uid1,
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.Name, func(t *testing.T) {
+			res := duesDeps.FindMemberDuesFile(context.Background(), c.Id, c.Uid)
+
+			if res.StatusCode != c.ExpectedStatusCode {
+				t.Logf("%#v", res)
+				t.Fatalf("Expected response code %d. Got %d\n", c.ExpectedStatusCode, res.StatusCode)
+			}
+
+			if res.StatusCode == http.StatusOK && res.Res.Url == "" {
+				t.Fatal("Expected signed url")
+			}
+		})
+	}
 }
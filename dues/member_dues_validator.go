@@ -2,8 +2,10 @@ package dues
 
 import (
 	"errors"
+	"strings"
 	"unicode/utf8"
 
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/money"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -11,6 +13,7 @@ var (
 	ErrFileRequired   = errors.New("file tidak boleh kosong")
 	ErrIsPaidRequired = errors.New("status persetujuan tidak boleh kosong")
 	ErrMaxFilename    = errors.New("nama file tidak dapat lebih dari 200 karakter")
+	ErrMaxReason      = errors.New("alasan penolakan tidak dapat lebih dari 500 karakter")
 )
 
 func ValidatePayMemberDuesIn(i PayMemberDuesIn) error {
@@ -66,6 +69,72 @@ func ValidatePaidMemberDuesIn(i PaidMemberDuesIn) error {
 		}
 		return nil
 	})
+	g.Go(func() error {
+		if utf8.RuneCountInString(i.Reason) > 500 {
+			return ErrMaxReason
+		}
+		return nil
+	})
+
+	if err := g.Wait(); err != nil {
+		return err
+	}
+	return nil
+}
+
+var ErrMemberDuesIdsRequired = errors.New("tagihan iuran yang dibayar tidak boleh kosong")
+
+func ValidateAddDuesPaymentIn(i AddDuesPaymentIn) error {
+	g := new(errgroup.Group)
+
+	g.Go(func() error {
+		if len(parseIds(i.MemberDuesIds)) == 0 {
+			return ErrMemberDuesIdsRequired
+		}
+		return nil
+	})
+	g.Go(func() error {
+		if strings.Trim(i.IdrAmount, " ") == "" {
+			return nil
+		}
+
+		_, err := money.ParseIDR(i.IdrAmount)
+		return err
+	})
+	g.Go(func() error {
+		if i.File.File == nil || i.File.Filename == "" {
+			return ErrFileRequired
+		}
+		return nil
+	})
+	g.Go(func() error {
+		if utf8.RuneCountInString(i.File.Filename) > 200 {
+			return ErrMaxFilename
+		}
+		return nil
+	})
+
+	if err := g.Wait(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func ValidatePaidDuesPaymentIn(i PaidDuesPaymentIn) error {
+	g := new(errgroup.Group)
+
+	g.Go(func() error {
+		if !i.IsPaid.Valid {
+			return ErrIsPaidRequired
+		}
+		return nil
+	})
+	g.Go(func() error {
+		if utf8.RuneCountInString(i.Reason) > 500 {
+			return ErrMaxReason
+		}
+		return nil
+	})
 
 	if err := g.Wait(); err != nil {
 		return err
@@ -3,16 +3,20 @@ package dues
 import (
 	"database/sql"
 	"time"
+
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/money"
 )
 
 type MemberDuesViewModel struct {
-	Id           uint64
-	DuesId       uint64
-	IdrAmount    string
-	ProveFileUrl string
-	Status       DuesStatus
-	Date         time.Time
-	PayDate      sql.NullTime
+	Id            uint64
+	DuesId        uint64
+	IdrAmount     money.IDR
+	PaidIdrAmount money.IDR
+	ProveFileUrl  string
+	RejectReason  string
+	Status        DuesStatus
+	Date          time.Time
+	PayDate       sql.NullTime
 }
 
 type DuesMemberViewModel struct {
@@ -21,11 +25,30 @@ type DuesMemberViewModel struct {
 	Name          string
 	ProfilePicUrl string
 	Status        DuesStatus
+	IdrAmount     money.IDR
+	PaidIdrAmount money.IDR
 	CreatedAt     time.Time
 	PayDate       sql.NullTime
 }
 
-type MemberDuesAmtViewModel struct {
-	IdrAmount string
-	Status    DuesStatus
+type MemberDuesHistoryViewModel struct {
+	Id         uint64
+	FromStatus DuesStatus
+	ToStatus   DuesStatus
+	Reason     string
+	ActorId    sql.NullString
+	ActorName  sql.NullString
+	CreatedAt  time.Time
+}
+
+// ArrearsViewModel is the overdue member dues of the member, the member dues
+// is overdue once its month has started.
+type ArrearsViewModel struct {
+	MemberId   string
+	Name       string
+	Username   string
+	WaPhone    string
+	Months     int64
+	IdrAmount  money.IDR
+	OldestDate time.Time
 }
@@ -0,0 +1,79 @@
+package dues
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/resp"
+	"github.com/pkg/errors"
+)
+
+// BackfillMemberDues charge the newly approved member the dues of this month
+// and the already generated next months, it is run as the member approved
+// hook.
+func (d *DuesDeps) BackfillMemberDues(ctx context.Context, uid string) error {
+	now := time.Now()
+	since := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+
+	if err := d.ScheduleRepository.LockTx(ctx, duesLockKey); err != nil {
+		return errors.Wrap(err, "lock dues")
+	}
+
+	if _, err := d.MemberDuesRepository.Backfill(ctx, uid, sql.NullTime{Time: since, Valid: true}); err != nil {
+		return errors.Wrap(err, "backfill member dues")
+	}
+
+	return nil
+}
+
+// VoidMemberDues remove the unpaid dues of the removed member, so it is no
+// longer counted as unpaid. It is run as the member removed hook.
+func (d *DuesDeps) VoidMemberDues(ctx context.Context, uid string) error {
+	if _, err := d.MemberDuesRepository.VoidUnpaid(ctx, uid); err != nil {
+		return errors.Wrap(err, "void unpaid member dues")
+	}
+
+	return nil
+}
+
+type (
+	ReconcileMemberDuesRes struct {
+		// Created is the number of member dues created for the member approved
+		// before the dues month.
+		Created int64 `json:"created"`
+		// Voided is the number of unpaid member dues of the removed member.
+		Voided int64 `json:"voided"`
+	}
+	ReconcileMemberDuesOut struct {
+		resp.Response
+		Res ReconcileMemberDuesRes
+	}
+)
+
+// ReconcileMemberDues repair the member dues created before the membership
+// hooks exist. The member is charged every dues since the month it is approved
+// with its current tier, so it should be run in a transaction which is only
+// committed after the result is reviewed.
+func (d *DuesDeps) ReconcileMemberDues(ctx context.Context) (out ReconcileMemberDuesOut) {
+	var err error
+	out.Response = resp.NewResponse(http.StatusOK, "", nil)
+
+	if err = d.ScheduleRepository.LockTx(ctx, duesLockKey); err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "lock dues"))
+		return
+	}
+
+	if out.Res.Created, err = d.MemberDuesRepository.Backfill(ctx, "", sql.NullTime{}); err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "backfill member dues"))
+		return
+	}
+
+	if out.Res.Voided, err = d.MemberDuesRepository.VoidUnpaid(ctx, ""); err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "void unpaid member dues"))
+		return
+	}
+
+	return
+}
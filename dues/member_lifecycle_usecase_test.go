@@ -0,0 +1,176 @@
+package dues_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	arbitary "github.com/PA-D3RPLA/d3if43-htt-uhomestay/arbitrary"
+	"github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+)
+
+func TestBackfillMemberDues(t *testing.T) {
+	err := ClearTables(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pastDues, err := duesRepository.Save(context.Background(), pastDuesSeed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	uid, duesId, err := createMemberNDues(duesDeps, memberSeed, duesSeed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = duesDeps.BackfillMemberDues(context.Background(), uid); err != nil {
+		t.Fatal(err)
+	}
+
+	memberDues, err := memberDuesRepository.FindByDuesIdAndMemberId(context.Background(), duesId, uid)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if memberDues.IdrAmount != duesSeed.IdrAmount {
+		t.Fatalf("Expected amount %s. Got %s\n", duesSeed.IdrAmount, memberDues.IdrAmount)
+	}
+
+	// The closed month is not charged.
+	_, err = memberDuesRepository.FindByDuesIdAndMemberId(context.Background(), pastDues.Id, uid)
+	if !errors.Is(err, pgx.ErrNoRows) {
+		t.Fatalf("Expected no member dues of the past month. Got %v\n", err)
+	}
+
+	// Backfill again does not charge the member twice.
+	created, err := memberDuesRepository.Backfill(context.Background(), uid, sql.NullTime{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if created != 0 {
+		t.Fatalf("Expected no member dues created. Got %d\n", created)
+	}
+}
+
+func TestVoidMemberDues(t *testing.T) {
+	err := ClearTables(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	uid, duesId, _, err := createMemberDues(duesDeps, memberSeed, duesSeed, unpaidMemDSeed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	paidUid, paidDuesId, _, err := createMemberDues(duesDeps, memberSeed2, duesSeed2, paidMemDSeed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, id := range []string{uid, paidUid} {
+		if err = memberRepository.DeleteById(context.Background(), id); err != nil {
+			t.Fatal(err)
+		}
+
+		if err = duesDeps.VoidMemberDues(context.Background(), id); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_, err = memberDuesRepository.FindByDuesIdAndMemberId(context.Background(), duesId, uid)
+	if !errors.Is(err, pgx.ErrNoRows) {
+		t.Fatalf("Expected unpaid member dues to be voided. Got %v\n", err)
+	}
+
+	// Paid member dues is kept since the money is already recorded.
+	if _, err = memberDuesRepository.FindByDuesIdAndMemberId(context.Background(), paidDuesId, paidUid); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReconcileMemberDues(t *testing.T) {
+	err := ClearTables(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, err = createMemberNDues(duesDeps, memberSeed, duesSeed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	removedUid, _, _, err := createMemberDues(duesDeps, memberSeed2, duesSeed2, unpaidMemDSeed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = memberRepository.DeleteById(context.Background(), removedUid); err != nil {
+		t.Fatal(err)
+	}
+
+	tx, err := db.Begin(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.WithValue(context.Background(), arbitary.TrxX{}, tx)
+	res := duesDeps.ReconcileMemberDues(ctx)
+	tx.Commit(context.Background())
+	tx.Rollback(context.Background())
+
+	if res.Error != nil {
+		t.Fatal(res.Error)
+	}
+
+	// The approved member is charged both dues.
+	if res.Res.Created != 2 {
+		t.Fatalf("Expected 2 created member dues. Got %d\n", res.Res.Created)
+	}
+
+	if res.Res.Voided != 1 {
+		t.Fatalf("Expected 1 voided member dues. Got %d\n", res.Res.Voided)
+	}
+}
+
+func TestReconcileMemberDuesSinceApproved(t *testing.T) {
+	err := ClearTables(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pastDues, err := duesRepository.Save(context.Background(), pastDuesSeed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	uid, duesId, err := createMemberNDues(duesDeps, memberSeed, duesSeed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The member registered before the past dues month, but is approved this
+	// month.
+	_, err = db.Exec(context.Background(), `UPDATE members SET created_at = $1 WHERE id = $2`, pastDuesSeed.Date.AddDate(0, -2, 0), uid)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res := duesDeps.ReconcileMemberDues(context.Background())
+	if res.Error != nil {
+		t.Fatal(res.Error)
+	}
+
+	if _, err = memberDuesRepository.FindByDuesIdAndMemberId(context.Background(), duesId, uid); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = memberDuesRepository.FindByDuesIdAndMemberId(context.Background(), pastDues.Id, uid)
+	if !errors.Is(err, pgx.ErrNoRows) {
+		t.Fatalf("Expected no member dues before the approval. Got %v\n", err)
+	}
+}
@@ -0,0 +1,30 @@
+package dues
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/money"
+)
+
+// OverrideModel is the amount of the dues charged to the member for a month,
+// it take precedence over the member tier. Zero amount exempt the member from
+// the dues of that month.
+type OverrideModel struct {
+	Id        uint64
+	MemberId  string
+	DuesId    uint64
+	IdrAmount money.IDR
+	Note      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt sql.NullTime
+}
+
+type OverrideViewModel struct {
+	Id        uint64
+	MemberId  string
+	Name      string
+	IdrAmount money.IDR
+	Note      string
+}
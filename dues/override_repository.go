@@ -0,0 +1,228 @@
+package dues
+
+import (
+	"context"
+	"time"
+
+	arbitary "github.com/PA-D3RPLA/d3if43-htt-uhomestay/arbitrary"
+
+	"github.com/georgysavva/scany/pgxscan"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+type OverrideRepository struct {
+	PostgreDb *pgxpool.Pool
+}
+
+func NewOverrideRepository(postgreDb *pgxpool.Pool) *OverrideRepository {
+	return &OverrideRepository{
+		PostgreDb: postgreDb,
+	}
+}
+
+type (
+	OverrideExecutor   func(ctx context.Context, sql string, arguments ...interface{}) (commandTag pgconn.CommandTag, err error)
+	OverrideQuerierRow func(ctx context.Context, sql string, args ...interface{}) pgx.Row
+	OverrideQuerier    func(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+)
+
+func (r *OverrideRepository) Save(ctx context.Context, m OverrideModel) (nm OverrideModel, err error) {
+	sqlQuery := `
+		INSERT INTO member_dues_overrides (
+			member_id,
+			dues_id,
+			idr_amount,
+			note,
+			created_at,
+			updated_at,
+			deleted_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`
+
+	var queryRow OverrideQuerierRow
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		queryRow = tx.QueryRow
+	} else {
+		queryRow = r.PostgreDb.QueryRow
+	}
+
+	var lastInsertId uint64
+	t := time.Now()
+
+	err = queryRow(
+		context.Background(),
+		sqlQuery,
+		m.MemberId,
+		m.DuesId,
+		m.IdrAmount,
+		m.Note,
+		t,
+		t,
+		nil,
+	).Scan(&lastInsertId)
+
+	if err != nil {
+		return OverrideModel{}, err
+	}
+
+	m.Id = lastInsertId
+	m.CreatedAt = t
+	m.UpdatedAt = t
+
+	return m, nil
+}
+
+func (r *OverrideRepository) UpdateById(ctx context.Context, id uint64, m OverrideModel) error {
+	sqlQuery := `
+		UPDATE member_dues_overrides SET (
+			idr_amount,
+			note,
+			updated_at
+		) = ($1, $2, $3)
+		WHERE id = $4
+	`
+
+	var exec OverrideExecutor
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		exec = tx.Exec
+	} else {
+		exec = r.PostgreDb.Exec
+	}
+
+	_, err := exec(
+		context.Background(),
+		sqlQuery,
+		m.IdrAmount,
+		m.Note,
+		time.Now(),
+		id,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *OverrideRepository) FindByDuesIdAndMemberId(ctx context.Context, duesId uint64, uid string) (m OverrideModel, err error) {
+	querystr := `
+		SELECT
+			id,
+			member_id,
+			dues_id,
+			idr_amount,
+			note,
+			created_at,
+			updated_at,
+			deleted_at
+		FROM member_dues_overrides
+		WHERE deleted_at IS NULL
+			AND dues_id = $1
+			AND member_id = $2
+	`
+
+	var query OverrideQuerier
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		query = tx.Query
+	} else {
+		query = r.PostgreDb.Query
+	}
+
+	rows, err := query(
+		context.Background(),
+		querystr,
+		duesId,
+		uid,
+	)
+	if err != nil {
+		return OverrideModel{}, err
+	}
+
+	if err = pgxscan.ScanOne(&m, rows); err != nil {
+		return OverrideModel{}, err
+	}
+
+	return m, nil
+}
+
+func (r *OverrideRepository) DeleteById(ctx context.Context, id uint64) error {
+	sqlQuery := `
+		UPDATE member_dues_overrides
+		SET deleted_at = $1
+		WHERE id = $2
+	`
+
+	var exec OverrideExecutor
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		exec = tx.Exec
+	} else {
+		exec = r.PostgreDb.Exec
+	}
+
+	_, err := exec(
+		context.Background(),
+		sqlQuery,
+		time.Now(),
+		id,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *OverrideRepository) QueryByDuesId(ctx context.Context, duesId uint64) ([]OverrideViewModel, error) {
+	sqlQuery := `
+		SELECT
+			o.id,
+			o.member_id,
+			m.name,
+			o.idr_amount,
+			o.note
+		FROM member_dues_overrides o
+			JOIN members m ON m.id = o.member_id
+		WHERE o.deleted_at IS NULL
+			AND m.deleted_at IS NULL
+			AND o.dues_id = $1
+		ORDER BY m.name ASC
+	`
+
+	var query OverrideQuerier
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		query = tx.Query
+	} else {
+		query = r.PostgreDb.Query
+	}
+
+	rows, err := query(
+		context.Background(),
+		sqlQuery,
+		duesId,
+	)
+	if err != nil {
+		return []OverrideViewModel{}, err
+	}
+	defer rows.Close()
+
+	var mps []*OverrideViewModel
+	if err := pgxscan.ScanAll(&mps, rows); err != nil {
+		return []OverrideViewModel{}, err
+	}
+
+	ms := make([]OverrideViewModel, len(mps))
+	for i, m := range mps {
+		ms[i] = *m
+	}
+
+	return ms, nil
+}
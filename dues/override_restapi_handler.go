@@ -0,0 +1,48 @@
+package dues
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/resp"
+	"github.com/go-chi/chi/v5"
+)
+
+func (d *DuesDeps) GetDuesOverrides(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	out := d.QueryOverride(r.Context(), id)
+	if out.Error != nil {
+		d.CaptureExeption(out.Error)
+	}
+	out.HttpJSON(w, resp.NewHttpBody(out.Res))
+}
+
+func (d *DuesDeps) PutDuesOverride(w http.ResponseWriter, r *http.Request) {
+	decoder := json.NewDecoder(r.Body)
+
+	var in SetOverrideIn
+	err := decoder.Decode(&in)
+	if err != nil {
+		d.CaptureExeption(err)
+		resp.NewResponse(http.StatusInternalServerError, "", err).HttpJSON(w, nil)
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	uid := chi.URLParam(r, "uid")
+	out := d.SetOverride(r.Context(), id, uid, in)
+	if out.Error != nil {
+		d.CaptureExeption(out.Error)
+	}
+	out.HttpJSON(w, resp.NewHttpBody(out.Res))
+}
+
+func (d *DuesDeps) DeleteDuesOverride(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	uid := chi.URLParam(r, "uid")
+	out := d.RemoveOverride(r.Context(), id, uid)
+	if out.Error != nil {
+		d.CaptureExeption(out.Error)
+	}
+	out.HttpJSON(w, resp.NewHttpBody(out.Res))
+}
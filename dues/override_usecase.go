@@ -0,0 +1,260 @@
+package dues
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/audit"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/money"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/resp"
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrOverrideNotFound    = errors.New("penyesuaian iuran anggota tidak ditemukan")
+	ErrProcessedMemberDues = errors.New("anggota telah melakukan pembayaran untuk tagihan iuran ini, nominal tidak dapat diubah")
+)
+
+// checkOverridable return error response when the dues or the member is not
+// found, or when the member has paid the dues.
+func (d *DuesDeps) checkOverridable(ctx context.Context, pid, uid string) (dues DuesModel, res resp.Response) {
+	res = resp.NewResponse(http.StatusOK, "", nil)
+
+	id, err := strconv.ParseUint(pid, 10, 64)
+	if err != nil {
+		res = resp.NewResponse(http.StatusNotFound, "", ErrDuesNotFound)
+		return
+	}
+
+	dues, err = d.DuesRepository.FindById(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		res = resp.NewResponse(http.StatusNotFound, "", ErrDuesNotFound)
+		return
+	}
+	if err != nil {
+		res = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "find dues by id"))
+		return
+	}
+
+	if _, err = uuid.FromString(uid); err != nil {
+		res = resp.NewResponse(http.StatusNotFound, "", ErrMemberNotFound)
+		return
+	}
+
+	_, err = d.MemberRepository.FindById(ctx, uid)
+	if errors.Is(err, pgx.ErrNoRows) {
+		res = resp.NewResponse(http.StatusNotFound, "", ErrMemberNotFound)
+		return
+	}
+	if err != nil {
+		res = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "find member by id"))
+		return
+	}
+
+	memberDues, err := d.MemberDuesRepository.FindByDuesIdAndMemberId(ctx, dues.Id, uid)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		res = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "find member dues by dues id and member id"))
+		return
+	}
+
+	if memberDues.Id != 0 && (memberDues.Status != Unpaid || memberDues.PaidIdrAmount > 0) {
+		res = resp.NewResponse(http.StatusBadRequest, "", ErrProcessedMemberDues)
+		return
+	}
+
+	return
+}
+
+type (
+	SetOverrideIn struct {
+		IdrAmount string `json:"idr_amount"`
+		Note      string `json:"note"`
+	}
+	SetOverrideRes struct {
+		Id uint64 `json:"id"`
+	}
+	SetOverrideOut struct {
+		resp.Response
+		Res SetOverrideRes
+	}
+)
+
+// SetOverride charge the member the given amount for the dues instead of its
+// tier or the dues amount, zero amount exempt the member from the dues. The
+// unpaid member dues is updated right away.
+func (d *DuesDeps) SetOverride(ctx context.Context, pid, uid string, in SetOverrideIn) (out SetOverrideOut) {
+	var err error
+	out.Response = resp.NewResponse(http.StatusOK, "", nil)
+
+	dues, res := d.checkOverridable(ctx, pid, uid)
+	if res.Error != nil {
+		out.Response = res
+		return
+	}
+
+	if err = ValidateSetOverrideIn(in); err != nil {
+		out.Response = resp.NewResponse(http.StatusUnprocessableEntity, "", err)
+		return
+	}
+
+	amount, _ := money.ParseIDR(in.IdrAmount)
+
+	override, err := d.OverrideRepository.FindByDuesIdAndMemberId(ctx, dues.Id, uid)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "find override by dues id and member id"))
+		return
+	}
+
+	var before interface{}
+	action := audit.ActionCreate
+	if override.Id != 0 {
+		before, action = override, audit.ActionUpdate
+	}
+
+	override.MemberId = uid
+	override.DuesId = dues.Id
+	override.IdrAmount = amount
+	override.Note = in.Note
+
+	if override.Id == 0 {
+		override, err = d.OverrideRepository.Save(ctx, override)
+	} else {
+		err = d.OverrideRepository.UpdateById(ctx, override.Id, override)
+	}
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "save override"))
+		return
+	}
+
+	err = d.Audit.Record(ctx, audit.Event{
+		Action:   action,
+		Entity:   "override",
+		EntityId: strconv.FormatUint(override.Id, 10),
+		Before:   before,
+		After:    override,
+	})
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "record audit"))
+		return
+	}
+
+	if err = d.MemberDuesRepository.SyncAmtByDuesId(ctx, dues.Id, uid); err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "sync member dues amount"))
+		return
+	}
+
+	out.Res.Id = override.Id
+
+	return
+}
+
+type (
+	OverrideOut struct {
+		Id        uint64 `json:"id"`
+		MemberId  string `json:"member_id"`
+		Name      string `json:"name"`
+		IdrAmount string `json:"idr_amount"`
+		IsExempt  bool   `json:"is_exempt"`
+		Note      string `json:"note"`
+	}
+	QueryOverrideRes struct {
+		Overrides []OverrideOut `json:"overrides"`
+	}
+	QueryOverrideOut struct {
+		resp.Response
+		Res QueryOverrideRes
+	}
+)
+
+func (d *DuesDeps) QueryOverride(ctx context.Context, pid string) (out QueryOverrideOut) {
+	out.Response = resp.NewResponse(http.StatusOK, "", nil)
+
+	id, err := strconv.ParseUint(pid, 10, 64)
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusNotFound, "", ErrDuesNotFound)
+		return
+	}
+
+	overrides, err := d.OverrideRepository.QueryByDuesId(ctx, id)
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "query override by dues id"))
+		return
+	}
+
+	outOverrides := make([]OverrideOut, len(overrides))
+	for i, o := range overrides {
+		outOverrides[i] = OverrideOut{
+			Id:        o.Id,
+			MemberId:  o.MemberId,
+			Name:      o.Name,
+			IdrAmount: o.IdrAmount.String(),
+			IsExempt:  o.IdrAmount == 0,
+			Note:      o.Note,
+		}
+	}
+
+	out.Res.Overrides = outOverrides
+
+	return
+}
+
+type (
+	RemoveOverrideRes struct {
+		Id uint64 `json:"id"`
+	}
+	RemoveOverrideOut struct {
+		resp.Response
+		Res RemoveOverrideRes
+	}
+)
+
+// RemoveOverride charge the member its tier or the dues amount again, the
+// unpaid member dues is updated right away.
+func (d *DuesDeps) RemoveOverride(ctx context.Context, pid, uid string) (out RemoveOverrideOut) {
+	var err error
+	out.Response = resp.NewResponse(http.StatusOK, "", nil)
+
+	dues, res := d.checkOverridable(ctx, pid, uid)
+	if res.Error != nil {
+		out.Response = res
+		return
+	}
+
+	override, err := d.OverrideRepository.FindByDuesIdAndMemberId(ctx, dues.Id, uid)
+	if errors.Is(err, pgx.ErrNoRows) {
+		out.Response = resp.NewResponse(http.StatusNotFound, "", ErrOverrideNotFound)
+		return
+	}
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "find override by dues id and member id"))
+		return
+	}
+
+	if err = d.OverrideRepository.DeleteById(ctx, override.Id); err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "delete override by id"))
+		return
+	}
+
+	err = d.Audit.Record(ctx, audit.Event{
+		Action:   audit.ActionDelete,
+		Entity:   "override",
+		EntityId: strconv.FormatUint(override.Id, 10),
+		Before:   override,
+	})
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "record audit"))
+		return
+	}
+
+	if err = d.MemberDuesRepository.SyncAmtByDuesId(ctx, dues.Id, uid); err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "sync member dues amount"))
+		return
+	}
+
+	out.Res.Id = override.Id
+
+	return
+}
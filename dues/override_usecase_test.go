@@ -0,0 +1,173 @@
+package dues_test
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/dues"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/money"
+	"github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+)
+
+func TestSetOverride(t *testing.T) {
+	err := ClearTables(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	uid, duesId, _, err := createMemberDues(duesDeps, memberSeed, duesSeed, unpaidMemDSeed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	paidUid, paidDuesId, _, err := createMemberDues(duesDeps, memberSeed2, duesSeed2, paidMemDSeed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		Name               string
+		ExpectedStatusCode int
+		Id                 string
+		Uid                string
+		In                 dues.SetOverrideIn
+		ExpectedAmount     money.IDR
+		ExpectedNoDues     bool
+	}{
+		{
+			Name:               "Set Override Success",
+			ExpectedStatusCode: http.StatusOK,
+			Id:                 strconv.FormatUint(duesId, 10),
+			Uid:                uid,
+			In: dues.SetOverrideIn{
+				IdrAmount: "10000",
+				Note:      "Keringanan",
+			},
+			ExpectedAmount: 10000,
+		},
+		{
+			Name:               "Set Override Exempt Success",
+			ExpectedStatusCode: http.StatusOK,
+			Id:                 strconv.FormatUint(duesId, 10),
+			Uid:                uid,
+			In: dues.SetOverrideIn{
+				IdrAmount: "0",
+				Note:      "Homestay sedang renovasi",
+			},
+			ExpectedNoDues: true,
+		},
+		{
+			Name:               "Set Override Fail, Member Has Paid",
+			ExpectedStatusCode: http.StatusBadRequest,
+			Id:                 strconv.FormatUint(paidDuesId, 10),
+			Uid:                paidUid,
+			In: dues.SetOverrideIn{
+				IdrAmount: "10000",
+			},
+		},
+		{
+			Name:               "Set Override Fail, Dues Not Found",
+			ExpectedStatusCode: http.StatusNotFound,
+			Id:                 "99999",
+			Uid:                uid,
+			In: dues.SetOverrideIn{
+				IdrAmount: "10000",
+			},
+		},
+		{
+			Name:               "Set Override Fail, Idr Amount Validation Fail",
+			ExpectedStatusCode: http.StatusUnprocessableEntity,
+			Id:                 strconv.FormatUint(duesId, 10),
+			Uid:                uid,
+			In:                 dues.SetOverrideIn{},
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.Name, func(t *testing.T) {
+			res := duesDeps.SetOverride(context.Background(), c.Id, c.Uid, c.In)
+			if res.StatusCode != c.ExpectedStatusCode {
+				t.Logf("%#v", res)
+				t.Fatalf("Expected response code %d. Got %d\n", c.ExpectedStatusCode, res.StatusCode)
+			}
+
+			if res.StatusCode != http.StatusOK {
+				return
+			}
+
+			memberDues, err := memberDuesRepository.FindByDuesIdAndMemberId(context.Background(), duesId, c.Uid)
+			if c.ExpectedNoDues {
+				if !errors.Is(err, pgx.ErrNoRows) {
+					t.Fatalf("Expected exempt member to have no dues. Got %#v\n", memberDues)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if memberDues.IdrAmount != c.ExpectedAmount {
+				t.Fatalf("Expected amount %s. Got %s\n", c.ExpectedAmount, memberDues.IdrAmount)
+			}
+		})
+	}
+}
+
+func TestRemoveOverride(t *testing.T) {
+	err := ClearTables(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	uid, duesId, _, err := createMemberDues(duesDeps, memberSeed, duesSeed, unpaidMemDSeed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res := duesDeps.SetOverride(context.Background(), strconv.FormatUint(duesId, 10), uid, dues.SetOverrideIn{IdrAmount: "0"})
+	if res.Error != nil {
+		t.Fatal(res.Error)
+	}
+
+	testCases := []struct {
+		Name               string
+		ExpectedStatusCode int
+		Id                 string
+		Uid                string
+	}{
+		{
+			Name:               "Remove Override Success",
+			ExpectedStatusCode: http.StatusOK,
+			Id:                 strconv.FormatUint(duesId, 10),
+			Uid:                uid,
+		},
+		{
+			Name:               "Remove Override Fail, Already Removed",
+			ExpectedStatusCode: http.StatusNotFound,
+			Id:                 strconv.FormatUint(duesId, 10),
+			Uid:                uid,
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.Name, func(t *testing.T) {
+			res := duesDeps.RemoveOverride(context.Background(), c.Id, c.Uid)
+			if res.StatusCode != c.ExpectedStatusCode {
+				t.Logf("%#v", res)
+				t.Fatalf("Expected response code %d. Got %d\n", c.ExpectedStatusCode, res.StatusCode)
+			}
+		})
+	}
+
+	memberDues, err := memberDuesRepository.FindByDuesIdAndMemberId(context.Background(), duesId, uid)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if memberDues.IdrAmount != duesSeed.IdrAmount {
+		t.Fatalf("Expected amount %s. Got %s\n", duesSeed.IdrAmount, memberDues.IdrAmount)
+	}
+}
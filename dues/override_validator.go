@@ -0,0 +1,36 @@
+package dues
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/money"
+	"golang.org/x/sync/errgroup"
+)
+
+var ErrMaxOverrideNote = errors.New("catatan tidak dapat lebih dari 500 karakter")
+
+func ValidateSetOverrideIn(i SetOverrideIn) error {
+	g := new(errgroup.Group)
+
+	g.Go(func() error {
+		if strings.Trim(i.IdrAmount, " ") == "" {
+			return ErrIdrAmountRequired
+		}
+
+		_, err := money.ParseIDR(i.IdrAmount)
+		return err
+	})
+	g.Go(func() error {
+		if utf8.RuneCountInString(i.Note) > 500 {
+			return ErrMaxOverrideNote
+		}
+		return nil
+	})
+
+	if err := g.Wait(); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package dues
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/money"
+)
+
+type PaymentStatus string
+
+const (
+	PaymentWaiting  PaymentStatus = "waiting"
+	PaymentApproved PaymentStatus = "approved"
+	PaymentRejected PaymentStatus = "rejected"
+)
+
+// PaymentModel is the payment of the member with one prove file, the amount
+// is allocated to one or more member dues of the member.
+type PaymentModel struct {
+	Id           uint64
+	MemberId     string
+	Status       PaymentStatus
+	IdrAmount    money.IDR
+	ProveFileUrl string
+	RejectReason string
+	CashflowId   sql.NullInt64
+	PayDate      time.Time
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	DeletedAt    sql.NullTime
+}
+
+// AllocationModel is the part of the payment amount settling the member dues.
+type AllocationModel struct {
+	PaymentId    uint64
+	MemberDuesId uint64
+	IdrAmount    money.IDR
+}
+
+type PaymentViewModel struct {
+	Id           uint64
+	MemberId     string
+	Name         string
+	Status       PaymentStatus
+	IdrAmount    money.IDR
+	ProveFileUrl string
+	RejectReason string
+	PayDate      time.Time
+}
+
+type AllocationViewModel struct {
+	PaymentId    uint64
+	MemberDuesId uint64
+	Date         time.Time
+	IdrAmount    money.IDR
+}
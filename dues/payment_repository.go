@@ -0,0 +1,489 @@
+package dues
+
+import (
+	"context"
+	"time"
+
+	arbitary "github.com/PA-D3RPLA/d3if43-htt-uhomestay/arbitrary"
+
+	"github.com/georgysavva/scany/pgxscan"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+type PaymentRepository struct {
+	PostgreDb *pgxpool.Pool
+}
+
+func NewPaymentRepository(postgreDb *pgxpool.Pool) *PaymentRepository {
+	return &PaymentRepository{
+		PostgreDb: postgreDb,
+	}
+}
+
+type (
+	PaymentExecutor   func(ctx context.Context, sql string, arguments ...interface{}) (commandTag pgconn.CommandTag, err error)
+	PaymentQuerierRow func(ctx context.Context, sql string, args ...interface{}) pgx.Row
+	PaymentQuerier    func(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+)
+
+func (r *PaymentRepository) Save(ctx context.Context, m PaymentModel) (nm PaymentModel, err error) {
+	sqlQuery := `
+		INSERT INTO dues_payments (
+			member_id,
+			status,
+			idr_amount,
+			prove_file_url,
+			cashflow_id,
+			pay_date,
+			created_at,
+			updated_at,
+			deleted_at
+		)
+		VALUES ($1, $2::paymentstatus, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id
+	`
+
+	var queryRow PaymentQuerierRow
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		queryRow = tx.QueryRow
+	} else {
+		queryRow = r.PostgreDb.QueryRow
+	}
+
+	var lastInsertId uint64
+	t := time.Now()
+	if m.PayDate.IsZero() {
+		m.PayDate = t
+	}
+
+	err = queryRow(
+		context.Background(),
+		sqlQuery,
+		m.MemberId,
+		string(m.Status),
+		m.IdrAmount,
+		m.ProveFileUrl,
+		m.CashflowId,
+		m.PayDate,
+		t,
+		t,
+		nil,
+	).Scan(&lastInsertId)
+
+	if err != nil {
+		return PaymentModel{}, err
+	}
+
+	m.Id = lastInsertId
+	m.CreatedAt = t
+	m.UpdatedAt = t
+
+	return m, nil
+}
+
+func (r *PaymentRepository) UpdateById(ctx context.Context, id uint64, m PaymentModel) error {
+	sqlQuery := `
+		UPDATE dues_payments SET (
+			status,
+			prove_file_url,
+			reject_reason,
+			cashflow_id,
+			updated_at
+		) = ($1::paymentstatus, $2, $3, $4, $5)
+		WHERE id = $6
+	`
+
+	var exec PaymentExecutor
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		exec = tx.Exec
+	} else {
+		exec = r.PostgreDb.Exec
+	}
+
+	_, err := exec(
+		context.Background(),
+		sqlQuery,
+		string(m.Status),
+		m.ProveFileUrl,
+		m.RejectReason,
+		m.CashflowId,
+		time.Now(),
+		id,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *PaymentRepository) DeleteById(ctx context.Context, id uint64) error {
+	sqlQuery := `
+		UPDATE dues_payments
+		SET deleted_at = $1
+		WHERE id = $2
+	`
+
+	var exec PaymentExecutor
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		exec = tx.Exec
+	} else {
+		exec = r.PostgreDb.Exec
+	}
+
+	_, err := exec(
+		context.Background(),
+		sqlQuery,
+		time.Now(),
+		id,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+const paymentColumns = `
+			p.id,
+			p.member_id,
+			p.status::TEXT AS status,
+			p.idr_amount,
+			p.prove_file_url,
+			p.reject_reason,
+			p.cashflow_id,
+			p.pay_date,
+			p.created_at,
+			p.updated_at,
+			p.deleted_at
+`
+
+func (r *PaymentRepository) FindById(ctx context.Context, id uint64) (m PaymentModel, err error) {
+	querystr := `
+		SELECT ` + paymentColumns + `
+		FROM dues_payments p
+		WHERE p.deleted_at IS NULL
+			AND p.id = $1
+	`
+
+	var query PaymentQuerier
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		query = tx.Query
+	} else {
+		query = r.PostgreDb.Query
+	}
+
+	rows, err := query(
+		context.Background(),
+		querystr,
+		id,
+	)
+	if err != nil {
+		return PaymentModel{}, err
+	}
+
+	if err = pgxscan.ScanOne(&m, rows); err != nil {
+		return PaymentModel{}, err
+	}
+
+	return m, nil
+}
+
+// FindByIdForUpdate lock the payment until the end of the transaction, so a
+// concurrent review of the same payment wait and read the updated status.
+func (r *PaymentRepository) FindByIdForUpdate(ctx context.Context, id uint64) (m PaymentModel, err error) {
+	querystr := `
+		SELECT ` + paymentColumns + `
+		FROM dues_payments p
+		WHERE p.deleted_at IS NULL
+			AND p.id = $1
+		FOR UPDATE
+	`
+
+	var query PaymentQuerier
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		query = tx.Query
+	} else {
+		query = r.PostgreDb.Query
+	}
+
+	rows, err := query(
+		context.Background(),
+		querystr,
+		id,
+	)
+	if err != nil {
+		return PaymentModel{}, err
+	}
+
+	if err = pgxscan.ScanOne(&m, rows); err != nil {
+		return PaymentModel{}, err
+	}
+
+	return m, nil
+}
+
+// FindByCashflowId return the approved payment recorded as the cashflow.
+func (r *PaymentRepository) FindByCashflowId(ctx context.Context, cashflowId uint64) (m PaymentModel, err error) {
+	querystr := `
+		SELECT ` + paymentColumns + `
+		FROM dues_payments p
+		WHERE p.deleted_at IS NULL
+			AND p.cashflow_id = $1
+		LIMIT 1
+	`
+
+	var query PaymentQuerier
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		query = tx.Query
+	} else {
+		query = r.PostgreDb.Query
+	}
+
+	rows, err := query(
+		context.Background(),
+		querystr,
+		cashflowId,
+	)
+	if err != nil {
+		return PaymentModel{}, err
+	}
+
+	if err = pgxscan.ScanOne(&m, rows); err != nil {
+		return PaymentModel{}, err
+	}
+
+	return m, nil
+}
+
+// FindWaitingByMemberDuesId return the payment of the member dues still
+// waiting for approval, a member dues is covered by one waiting payment at most.
+func (r *PaymentRepository) FindWaitingByMemberDuesId(ctx context.Context, memberDuesId uint64) (m PaymentModel, err error) {
+	querystr := `
+		SELECT ` + paymentColumns + `
+		FROM dues_payments p
+			JOIN dues_payment_allocations a ON a.payment_id = p.id
+		WHERE p.deleted_at IS NULL
+			AND p.status = 'waiting'
+			AND a.member_dues_id = $1
+		LIMIT 1
+	`
+
+	var query PaymentQuerier
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		query = tx.Query
+	} else {
+		query = r.PostgreDb.Query
+	}
+
+	rows, err := query(
+		context.Background(),
+		querystr,
+		memberDuesId,
+	)
+	if err != nil {
+		return PaymentModel{}, err
+	}
+
+	if err = pgxscan.ScanOne(&m, rows); err != nil {
+		return PaymentModel{}, err
+	}
+
+	return m, nil
+}
+
+func (r *PaymentRepository) QueryApprovedByMemberDuesId(ctx context.Context, memberDuesId uint64) ([]PaymentModel, error) {
+	querystr := `
+		SELECT ` + paymentColumns + `
+		FROM dues_payments p
+			JOIN dues_payment_allocations a ON a.payment_id = p.id
+		WHERE p.deleted_at IS NULL
+			AND p.status = 'approved'
+			AND a.member_dues_id = $1
+		ORDER BY p.id DESC
+	`
+
+	var query PaymentQuerier
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		query = tx.Query
+	} else {
+		query = r.PostgreDb.Query
+	}
+
+	rows, err := query(
+		context.Background(),
+		querystr,
+		memberDuesId,
+	)
+	if err != nil {
+		return []PaymentModel{}, err
+	}
+	defer rows.Close()
+
+	var mps []*PaymentModel
+	if err := pgxscan.ScanAll(&mps, rows); err != nil {
+		return []PaymentModel{}, err
+	}
+
+	ms := make([]PaymentModel, len(mps))
+	for i, m := range mps {
+		ms[i] = *m
+	}
+
+	return ms, nil
+}
+
+func (r *PaymentRepository) SaveAllocation(ctx context.Context, m AllocationModel) error {
+	sqlQuery := `
+		INSERT INTO dues_payment_allocations (
+			payment_id,
+			member_dues_id,
+			idr_amount
+		)
+		VALUES ($1, $2, $3)
+	`
+
+	var exec PaymentExecutor
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		exec = tx.Exec
+	} else {
+		exec = r.PostgreDb.Exec
+	}
+
+	_, err := exec(
+		context.Background(),
+		sqlQuery,
+		m.PaymentId,
+		m.MemberDuesId,
+		m.IdrAmount,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// QueryAllocationByPaymentIds return the allocation of the payments ordered by
+// the month of the member dues.
+func (r *PaymentRepository) QueryAllocationByPaymentIds(ctx context.Context, paymentIds []int64) ([]AllocationViewModel, error) {
+	querystr := `
+		SELECT
+			a.payment_id,
+			a.member_dues_id,
+			d.date,
+			a.idr_amount
+		FROM dues_payment_allocations a
+			JOIN member_dues md ON md.id = a.member_dues_id
+			JOIN dues d ON d.id = md.dues_id
+		WHERE a.payment_id = ANY($1::BIGINT[])
+		ORDER BY a.payment_id, d.date
+	`
+
+	var query PaymentQuerier
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		query = tx.Query
+	} else {
+		query = r.PostgreDb.Query
+	}
+
+	rows, err := query(
+		context.Background(),
+		querystr,
+		paymentIds,
+	)
+	if err != nil {
+		return []AllocationViewModel{}, err
+	}
+	defer rows.Close()
+
+	var mps []*AllocationViewModel
+	if err := pgxscan.ScanAll(&mps, rows); err != nil {
+		return []AllocationViewModel{}, err
+	}
+
+	ms := make([]AllocationViewModel, len(mps))
+	for i, m := range mps {
+		ms[i] = *m
+	}
+
+	return ms, nil
+}
+
+// QueryPVByStatus return the payments of the status, or of every status when
+// empty, the newest first.
+func (r *PaymentRepository) QueryPVByStatus(ctx context.Context, status string, id, limit int64) ([]PaymentViewModel, int64, error) {
+	from := `
+		FROM dues_payments p
+			LEFT JOIN members m ON m.id = p.member_id
+		WHERE p.deleted_at IS NULL
+			AND ($1::TEXT = '' OR p.status::TEXT = $1::TEXT)
+	`
+
+	var n int64
+	err := r.PostgreDb.QueryRow(
+		context.Background(),
+		`SELECT COUNT(p.id) AS n `+from,
+		status,
+	).Scan(&n)
+	if err != nil {
+		return []PaymentViewModel{}, 0, err
+	}
+
+	fromId := "p.id > $2"
+	if id != 0 {
+		fromId = "p.id < $2"
+	}
+
+	selectSqlQuery := `
+		SELECT
+			p.id,
+			p.member_id,
+			COALESCE(m.name, '') AS name,
+			p.status::TEXT AS status,
+			p.idr_amount,
+			p.prove_file_url,
+			p.reject_reason,
+			p.pay_date
+		` + from + `
+			AND ` + fromId + `
+		ORDER BY p.id DESC
+		LIMIT $3
+	`
+
+	rows, err := r.PostgreDb.Query(
+		context.Background(),
+		selectSqlQuery,
+		status,
+		id,
+		limit,
+	)
+	if err != nil {
+		return []PaymentViewModel{}, 0, err
+	}
+	defer rows.Close()
+
+	var mps []*PaymentViewModel
+	if err := pgxscan.ScanAll(&mps, rows); err != nil {
+		return []PaymentViewModel{}, 0, err
+	}
+
+	ms := make([]PaymentViewModel, len(mps))
+	for i, m := range mps {
+		ms[i] = *m
+	}
+
+	return ms, n, nil
+}
@@ -0,0 +1,767 @@
+package dues
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/audit"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/cashflow"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/httpdecode"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/money"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/resp"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/user"
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+	"gopkg.in/guregu/null.v4"
+)
+
+var (
+	ErrPaymentNotFound = errors.New("pembayaran iuran tidak ditemukan")
+	ErrPaymentExceed   = errors.New("nominal pembayaran melebihi sisa tagihan iuran yang dipilih")
+	ErrPaymentNotCover = errors.New("nominal pembayaran tidak mencukupi untuk setiap bulan yang dipilih")
+	ErrPaymentChanged  = errors.New("status pembayaran iuran telah diubah, muat ulang halaman")
+)
+
+// paymentFileEndpoint is where the prove file of the payment can be downloaded.
+func paymentFileEndpoint(id uint64, proveFileUrl string) string {
+	if proveFileUrl == "" {
+		return ""
+	}
+
+	return "/api/v1/files/dues_payments/" + strconv.FormatUint(id, 10)
+}
+
+// allocatePayment split the amount to the member dues, which is ordered from
+// the oldest month. Each member dues is settled before moving to the next one,
+// so only the last member dues can be covered partially. Zero amount pay the
+// whole remaining balance.
+func allocatePayment(memberDues []MemberDuesModel, amt money.IDR) ([]money.IDR, error) {
+	var remaining money.IDR
+	for _, md := range memberDues {
+		remaining += md.IdrAmount - md.PaidIdrAmount
+	}
+
+	if amt == 0 {
+		amt = remaining
+	}
+
+	if amt > remaining {
+		return nil, ErrPaymentExceed
+	}
+
+	allocs := make([]money.IDR, len(memberDues))
+	for i, md := range memberDues {
+		alloc := md.IdrAmount - md.PaidIdrAmount
+		if alloc > amt {
+			alloc = amt
+		}
+
+		if alloc <= 0 {
+			return nil, ErrPaymentNotCover
+		}
+
+		allocs[i] = alloc
+		amt -= alloc
+	}
+
+	return allocs, nil
+}
+
+// submitPayment record the payment of the member dues waiting for approval,
+// the member dues is waiting until the payment is approved or reverted.
+func (d *DuesDeps) submitPayment(ctx context.Context, actorId string, payment PaymentModel, memberDues []MemberDuesModel) (PaymentModel, error) {
+	allocs, err := allocatePayment(memberDues, payment.IdrAmount)
+	if err != nil {
+		return PaymentModel{}, err
+	}
+
+	payment.IdrAmount = 0
+	for _, alloc := range allocs {
+		payment.IdrAmount += alloc
+	}
+
+	if payment, err = d.PaymentRepository.Save(ctx, payment); err != nil {
+		return PaymentModel{}, errors.Wrap(err, "save payment")
+	}
+
+	for i, md := range memberDues {
+		allocation := AllocationModel{
+			PaymentId:    payment.Id,
+			MemberDuesId: md.Id,
+			IdrAmount:    allocs[i],
+		}
+		if err = d.PaymentRepository.SaveAllocation(ctx, allocation); err != nil {
+			return PaymentModel{}, errors.Wrap(err, "save payment allocation")
+		}
+
+		from := md.Status
+		md.Status = Waiting
+		md.ProveFileUrl = payment.ProveFileUrl
+		md.RejectReason = ""
+		md.PayDate.Scan(payment.PayDate)
+
+		if err = d.saveMemberDues(ctx, actorId, from, md); err != nil {
+			return PaymentModel{}, err
+		}
+	}
+
+	return payment, nil
+}
+
+// lockPayment lock the payment for the rest of the transaction and make sure it
+// is still in the status, the payment may have been reviewed concurrently.
+func (d *DuesDeps) lockPayment(ctx context.Context, id uint64, status PaymentStatus) (PaymentModel, error) {
+	payment, err := d.PaymentRepository.FindByIdForUpdate(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return PaymentModel{}, ErrPaymentChanged
+	}
+	if err != nil {
+		return PaymentModel{}, errors.Wrap(err, "find payment by id for update")
+	}
+
+	if payment.Status != status {
+		return PaymentModel{}, ErrPaymentChanged
+	}
+
+	return payment, nil
+}
+
+// approvePayment record the payment as one income cashflow and settle the
+// member dues covered by the payment. Member dues not fully paid goes back to
+// unpaid with the remaining balance.
+func (d *DuesDeps) approvePayment(ctx context.Context, actorId string, payment PaymentModel) error {
+	payment, err := d.lockPayment(ctx, payment.Id, PaymentWaiting)
+	if err != nil {
+		return err
+	}
+
+	allocations, err := d.PaymentRepository.QueryAllocationByPaymentIds(ctx, []int64{int64(payment.Id)})
+	if err != nil {
+		return errors.Wrap(err, "query payment allocation")
+	}
+
+	// The member dues may have been settled by another payment since the
+	// payment was submitted.
+	memberDues := make([]MemberDuesModel, len(allocations))
+	for i, a := range allocations {
+		md, err := d.MemberDuesRepository.FindByIdForUpdate(ctx, a.MemberDuesId)
+		if err != nil {
+			return errors.Wrap(err, "find member dues by id for update")
+		}
+
+		if a.IdrAmount > md.IdrAmount-md.PaidIdrAmount {
+			return ErrPaymentExceed
+		}
+
+		memberDues[i] = md
+	}
+
+	member, err := d.MemberRepository.FindById(ctx, payment.MemberId)
+	if err != nil {
+		return errors.Wrap(err, "find member by id")
+	}
+
+	category, err := d.CategoryRepository.FindByCode(ctx, cashflow.DuesCategory)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return errors.Wrap(err, "find dues category")
+	}
+
+	months := make([]string, len(allocations))
+	for i, a := range allocations {
+		months[i] = a.Date.Format("01-2006")
+	}
+
+	cf := cashflow.CashflowModel{
+		Date:      payment.PayDate,
+		IdrAmount: payment.IdrAmount,
+		Type:      cashflow.Income,
+		Note:      "Pembayaran Iuran Anggota Bulan " + strings.Join(months, ", ") + ", Nama " + member.Name,
+	}
+	if category.Id != 0 {
+		cf.CategoryId.Scan(int64(category.Id))
+	}
+
+	if cf, err = d.CashflowRepository.Save(ctx, cf); err != nil {
+		return errors.Wrap(err, "save cashflow")
+	}
+
+	before := payment
+	payment.Status = PaymentApproved
+	payment.CashflowId.Scan(int64(cf.Id))
+	if err = d.PaymentRepository.UpdateById(ctx, payment.Id, payment); err != nil {
+		return errors.Wrap(err, "update payment by id")
+	}
+
+	err = d.Audit.Record(ctx, audit.Event{
+		Action:   audit.ActionApprove,
+		Entity:   "payment",
+		EntityId: strconv.FormatUint(payment.Id, 10),
+		Before:   before,
+		After:    payment,
+	})
+	if err != nil {
+		return errors.Wrap(err, "record audit")
+	}
+
+	for i, a := range allocations {
+		md := memberDues[i]
+
+		from := md.Status
+		md.PaidIdrAmount += a.IdrAmount
+		md.Status = Unpaid
+		if md.PaidIdrAmount >= md.IdrAmount {
+			md.Status = Paid
+		}
+		md.CashflowId = payment.CashflowId
+
+		if err = d.saveMemberDues(ctx, actorId, from, md); err != nil {
+			return err
+		}
+	}
+
+	if err = runPaymentHooks(ctx, d.PaymentHooks.OnApproved, payment); err != nil {
+		return errors.Wrap(err, "run payment approved hooks")
+	}
+
+	return nil
+}
+
+// revertPayment void the cashflow of the approved payment and take back the
+// amount settled to the member dues. Payment with prove file goes back to
+// waiting for approval, while payment in cash is removed.
+func (d *DuesDeps) revertPayment(ctx context.Context, actorId string, payment PaymentModel) error {
+	payment, err := d.lockPayment(ctx, payment.Id, PaymentApproved)
+	if err != nil {
+		return err
+	}
+
+	allocations, err := d.PaymentRepository.QueryAllocationByPaymentIds(ctx, []int64{int64(payment.Id)})
+	if err != nil {
+		return errors.Wrap(err, "query payment allocation")
+	}
+
+	cashflowId := payment.CashflowId
+	if cashflowId.Valid {
+		if err = d.CashflowRepository.DeleteById(ctx, uint64(cashflowId.Int64)); err != nil {
+			return errors.Wrap(err, "delete cashflow by id")
+		}
+	}
+
+	before := payment
+	var after interface{}
+	isCash := payment.ProveFileUrl == ""
+	if isCash {
+		err = d.PaymentRepository.DeleteById(ctx, payment.Id)
+	} else {
+		payment.Status = PaymentWaiting
+		payment.CashflowId = sql.NullInt64{}
+		after = payment
+		err = d.PaymentRepository.UpdateById(ctx, payment.Id, payment)
+	}
+	if err != nil {
+		return errors.Wrap(err, "revert payment by id")
+	}
+
+	err = d.Audit.Record(ctx, audit.Event{
+		Action:   audit.ActionRevert,
+		Entity:   "payment",
+		EntityId: strconv.FormatUint(payment.Id, 10),
+		Before:   before,
+		After:    after,
+	})
+	if err != nil {
+		return errors.Wrap(err, "record audit")
+	}
+
+	for _, a := range allocations {
+		md, err := d.MemberDuesRepository.FindById(ctx, a.MemberDuesId)
+		if err != nil {
+			return errors.Wrap(err, "find member dues by id")
+		}
+
+		from := md.Status
+		md.PaidIdrAmount -= a.IdrAmount
+		if md.PaidIdrAmount < 0 {
+			md.PaidIdrAmount = 0
+		}
+
+		md.Status = Waiting
+		if isCash {
+			md.Status = Unpaid
+		}
+		if md.PaidIdrAmount == 0 && isCash {
+			md.ProveFileUrl = ""
+			md.PayDate = sql.NullTime{}
+		}
+		if md.CashflowId == cashflowId {
+			md.CashflowId = sql.NullInt64{}
+		}
+
+		if err = d.saveMemberDues(ctx, actorId, from, md); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// rejectPayment reject the waiting payment with the reason shown to the member,
+// the member dues it cover can be paid again.
+func (d *DuesDeps) rejectPayment(ctx context.Context, actorId string, payment PaymentModel, reason string) error {
+	payment, err := d.lockPayment(ctx, payment.Id, PaymentWaiting)
+	if err != nil {
+		return err
+	}
+
+	allocations, err := d.PaymentRepository.QueryAllocationByPaymentIds(ctx, []int64{int64(payment.Id)})
+	if err != nil {
+		return errors.Wrap(err, "query payment allocation")
+	}
+
+	before := payment
+	payment.Status = PaymentRejected
+	payment.RejectReason = reason
+	if err = d.PaymentRepository.UpdateById(ctx, payment.Id, payment); err != nil {
+		return errors.Wrap(err, "update payment by id")
+	}
+
+	err = d.Audit.Record(ctx, audit.Event{
+		Action:   audit.ActionReject,
+		Entity:   "payment",
+		EntityId: strconv.FormatUint(payment.Id, 10),
+		Before:   before,
+		After:    payment,
+	})
+	if err != nil {
+		return errors.Wrap(err, "record audit")
+	}
+
+	for _, a := range allocations {
+		md, err := d.MemberDuesRepository.FindById(ctx, a.MemberDuesId)
+		if err != nil {
+			return errors.Wrap(err, "find member dues by id")
+		}
+
+		if md.Status != Waiting {
+			continue
+		}
+
+		md.Status = Rejected
+		md.RejectReason = reason
+
+		if err = d.saveMemberDues(ctx, actorId, Waiting, md); err != nil {
+			return err
+		}
+	}
+
+	if err = runPaymentHooks(ctx, d.PaymentHooks.OnRejected, payment); err != nil {
+		return errors.Wrap(err, "run payment rejected hooks")
+	}
+
+	return nil
+}
+
+// setPaymentFile replace the prove file of the payment and the member dues it
+// cover.
+func (d *DuesDeps) setPaymentFile(ctx context.Context, payment PaymentModel, fileUrl string) error {
+	payment.ProveFileUrl = fileUrl
+	if err := d.PaymentRepository.UpdateById(ctx, payment.Id, payment); err != nil {
+		return errors.Wrap(err, "update payment by id")
+	}
+
+	allocations, err := d.PaymentRepository.QueryAllocationByPaymentIds(ctx, []int64{int64(payment.Id)})
+	if err != nil {
+		return errors.Wrap(err, "query payment allocation")
+	}
+
+	for _, a := range allocations {
+		md, err := d.MemberDuesRepository.FindById(ctx, a.MemberDuesId)
+		if err != nil {
+			return errors.Wrap(err, "find member dues by id")
+		}
+
+		md.ProveFileUrl = fileUrl
+		if err = d.MemberDuesRepository.UpdateById(ctx, md.Id, md); err != nil {
+			return errors.Wrap(err, "update member dues by id")
+		}
+	}
+
+	return nil
+}
+
+type (
+	AddDuesPaymentIn struct {
+		// MemberDuesIds is comma separated id of the member dues.
+		MemberDuesIds string                `mapstructure:"member_dues_ids"`
+		IdrAmount     string                `mapstructure:"idr_amount"`
+		File          httpdecode.FileHeader `mapstructure:"file"`
+	}
+	AddDuesPaymentRes struct {
+		Id int64 `json:"id"`
+	}
+	AddDuesPaymentOut struct {
+		resp.Response
+		Res AddDuesPaymentRes
+	}
+)
+
+// AddDuesPayment submit one prove file paying several member dues at once, or
+// a part of the member dues. Empty amount pay the whole remaining balance.
+func (d *DuesDeps) AddDuesPayment(ctx context.Context, uid string, in AddDuesPaymentIn) (out AddDuesPaymentOut) {
+	var err error
+	out.Response = resp.NewResponse(http.StatusOK, "", nil)
+
+	_, err = uuid.FromString(uid)
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusNotFound, "", ErrMemberNotFound)
+		return
+	}
+
+	if err = ValidateAddDuesPaymentIn(in); err != nil {
+		out.Response = resp.NewResponse(http.StatusUnprocessableEntity, "", errors.Wrap(err, "add dues payment validation"))
+		return
+	}
+
+	ids := parseIds(in.MemberDuesIds)
+	memberDues, err := d.MemberDuesRepository.QueryUnpaidByIdsAndMemberId(ctx, ids, uid)
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "query unpaid member dues by ids"))
+		return
+	}
+
+	if len(memberDues) == 0 || len(memberDues) != len(ids) {
+		out.Response = resp.NewResponse(http.StatusNotFound, "", ErrMemberDuesNotFound)
+		return
+	}
+
+	var amt money.IDR
+	if strings.Trim(in.IdrAmount, " ") != "" {
+		amt, _ = money.ParseIDR(in.IdrAmount)
+	}
+
+	if _, err = allocatePayment(memberDues, amt); err != nil {
+		out.Response = resp.NewResponse(http.StatusUnprocessableEntity, "", err)
+		return
+	}
+
+	file := in.File.File
+	defer file.Close()
+
+	filename := strconv.FormatInt(time.Now().Unix(), 10) + "-" + strings.Trim(in.File.Filename, " ")
+	fileUrl, err := d.Upload(filename, file)
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "upload file"))
+		return
+	}
+
+	payment := PaymentModel{
+		MemberId:     uid,
+		Status:       PaymentWaiting,
+		IdrAmount:    amt,
+		ProveFileUrl: fileUrl,
+		PayDate:      time.Now(),
+	}
+	if payment, err = d.submitPayment(ctx, uid, payment, memberDues); err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "submit payment"))
+		return
+	}
+
+	if err = runPaymentHooks(ctx, d.PaymentHooks.OnSubmitted, payment); err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "run payment submitted hooks"))
+		return
+	}
+
+	out.Res.Id = int64(payment.Id)
+
+	return
+}
+
+// parseIds parse comma separated id, duplicated and invalid id is dropped.
+func parseIds(s string) []int64 {
+	seen := map[int64]bool{}
+	ids := []int64{}
+	for _, v := range strings.Split(s, ",") {
+		id, err := strconv.ParseInt(strings.Trim(v, " "), 10, 64)
+		if err != nil || id <= 0 || seen[id] {
+			continue
+		}
+
+		seen[id] = true
+		ids = append(ids, id)
+	}
+
+	return ids
+}
+
+type (
+	PaidDuesPaymentIn struct {
+		IsPaid null.Bool `json:"is_paid"`
+		// Reason is required to reject the waiting payment.
+		Reason string `json:"reason"`
+	}
+	PaidDuesPaymentRes struct {
+		Id int64 `json:"id"`
+	}
+	PaidDuesPaymentOut struct {
+		resp.Response
+		Res PaidDuesPaymentRes
+	}
+)
+
+// PaidDuesPayment approve the payment settling every member dues it cover.
+// Disapproving reject the waiting payment with the reason, or revert the
+// approved payment.
+func (d *DuesDeps) PaidDuesPayment(ctx context.Context, uid, pid string, in PaidDuesPaymentIn) (out PaidDuesPaymentOut) {
+	var err error
+	out.Response = resp.NewResponse(http.StatusOK, "", nil)
+
+	id, err := strconv.ParseUint(pid, 10, 64)
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusNotFound, "", ErrPaymentNotFound)
+		return
+	}
+
+	if err = ValidatePaidDuesPaymentIn(in); err != nil {
+		out.Response = resp.NewResponse(http.StatusUnprocessableEntity, "", err)
+		return
+	}
+
+	payment, err := d.PaymentRepository.FindById(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		out.Response = resp.NewResponse(http.StatusNotFound, "", ErrPaymentNotFound)
+		return
+	}
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "find payment by id"))
+		return
+	}
+
+	out.Res.Id = int64(id)
+
+	if !in.IsPaid.Bool && payment.Status == PaymentWaiting && strings.Trim(in.Reason, " ") == "" {
+		out.Response = resp.NewResponse(http.StatusUnprocessableEntity, "", ErrRejectReasonRequired)
+		return
+	}
+
+	switch {
+	case in.IsPaid.Bool && payment.Status == PaymentWaiting:
+		err = d.approvePayment(ctx, uid, payment)
+	case !in.IsPaid.Bool && payment.Status == PaymentWaiting:
+		err = d.rejectPayment(ctx, uid, payment, in.Reason)
+	case !in.IsPaid.Bool && payment.Status == PaymentApproved:
+		err = d.revertPayment(ctx, uid, payment)
+	default:
+		err = ErrPaymentChanged
+	}
+
+	if errors.Is(err, ErrPaymentExceed) || errors.Is(err, ErrPaymentChanged) {
+		out.Response = resp.NewResponse(http.StatusConflict, "", err)
+		return
+	}
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", err)
+		return
+	}
+
+	return
+}
+
+type (
+	DuesPaymentAllocationOut struct {
+		MemberDuesId int64  `json:"member_dues_id"`
+		Date         string `json:"date"`
+		IdrAmount    string `json:"idr_amount"`
+	}
+	DuesPaymentOut struct {
+		Id           int64                      `json:"id"`
+		MemberId     string                     `json:"member_id"`
+		Name         string                     `json:"name"`
+		Status       string                     `json:"status"`
+		IdrAmount    string                     `json:"idr_amount"`
+		ProveFileUrl string                     `json:"prove_file_url"`
+		RejectReason string                     `json:"reject_reason"`
+		PayDate      string                     `json:"pay_date"`
+		Allocations  []DuesPaymentAllocationOut `json:"allocations"`
+	}
+	QueryDuesPaymentRes struct {
+		Cursor   int64            `json:"cursor"`
+		Total    int64            `json:"total"`
+		Payments []DuesPaymentOut `json:"payments"`
+	}
+	QueryDuesPaymentOut struct {
+		resp.Response
+		Res QueryDuesPaymentRes
+	}
+	QueryDuesPaymentQIn struct {
+		Status string
+		Cursor string
+		Limit  string
+	}
+)
+
+func (d *DuesDeps) QueryDuesPayment(ctx context.Context, qin QueryDuesPaymentQIn) (out QueryDuesPaymentOut) {
+	var err error
+	out.Response = resp.NewResponse(http.StatusOK, "", nil)
+
+	status := qin.Status
+	if status != string(PaymentWaiting) && status != string(PaymentApproved) && status != string(PaymentRejected) {
+		status = ""
+	}
+
+	fromCursor, _ := strconv.ParseInt(qin.Cursor, 10, 64)
+	limit, _ := strconv.ParseInt(qin.Limit, 10, 64)
+	if limit == 0 {
+		limit = 25
+	}
+
+	payments, n, err := d.PaymentRepository.QueryPVByStatus(ctx, status, fromCursor, limit)
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "query payment by status"))
+		return
+	}
+
+	ids := make([]int64, len(payments))
+	for i, p := range payments {
+		ids[i] = int64(p.Id)
+	}
+
+	allocations, err := d.PaymentRepository.QueryAllocationByPaymentIds(ctx, ids)
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "query payment allocation"))
+		return
+	}
+
+	outAllocations := map[uint64][]DuesPaymentAllocationOut{}
+	for _, a := range allocations {
+		outAllocations[a.PaymentId] = append(outAllocations[a.PaymentId], DuesPaymentAllocationOut{
+			MemberDuesId: int64(a.MemberDuesId),
+			Date:         a.Date.Format("2006-01"),
+			IdrAmount:    a.IdrAmount.String(),
+		})
+	}
+
+	var nextCursor int64
+	outPayments := make([]DuesPaymentOut, len(payments))
+	for i, p := range payments {
+		nextCursor = int64(p.Id)
+
+		allocs := outAllocations[p.Id]
+		if allocs == nil {
+			allocs = []DuesPaymentAllocationOut{}
+		}
+
+		outPayments[i] = DuesPaymentOut{
+			Id:           int64(p.Id),
+			MemberId:     p.MemberId,
+			Name:         p.Name,
+			Status:       string(p.Status),
+			IdrAmount:    p.IdrAmount.String(),
+			ProveFileUrl: paymentFileEndpoint(p.Id, p.ProveFileUrl),
+			RejectReason: p.RejectReason,
+			PayDate:      p.PayDate.Format("2006-01-02"),
+			Allocations:  allocs,
+		}
+	}
+
+	out.Res = QueryDuesPaymentRes{
+		Cursor:   nextCursor,
+		Total:    n,
+		Payments: outPayments,
+	}
+
+	return
+}
+
+// FindDuesPaymentFile return short-lived signed url of the payment prove file,
+// only the member paying or admin can see the prove file.
+func (d *DuesDeps) FindDuesPaymentFile(ctx context.Context, pid, uid string) (out MemberDuesFileOut) {
+	out.Response = resp.NewResponse(http.StatusOK, "", nil)
+
+	if uid == "" {
+		out.Response = resp.NewResponse(http.StatusUnauthorized, "", ErrLoginRequired)
+		return
+	}
+
+	id, err := strconv.ParseUint(pid, 10, 64)
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusNotFound, "", ErrFileNotFound)
+		return
+	}
+
+	payment, err := d.PaymentRepository.FindById(ctx, id)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "find payment by id"))
+		return
+	}
+
+	out = d.paymentFile(ctx, payment, uid)
+
+	return
+}
+
+// FindCashflowPaymentFile return short-lived signed url of the prove file of
+// the payment recorded as the dues cashflow. The prove file is not copied to
+// the cashflow, so it is guarded the same as the payment prove file.
+func (d *DuesDeps) FindCashflowPaymentFile(ctx context.Context, pid, uid string) (out MemberDuesFileOut) {
+	out.Response = resp.NewResponse(http.StatusOK, "", nil)
+
+	if uid == "" {
+		out.Response = resp.NewResponse(http.StatusUnauthorized, "", ErrLoginRequired)
+		return
+	}
+
+	id, err := strconv.ParseUint(pid, 10, 64)
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusNotFound, "", ErrFileNotFound)
+		return
+	}
+
+	payment, err := d.PaymentRepository.FindByCashflowId(ctx, id)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "find payment by cashflow id"))
+		return
+	}
+
+	out = d.paymentFile(ctx, payment, uid)
+
+	return
+}
+
+// paymentFile sign the payment prove file when the user is the member paying
+// or the dues verifier.
+func (d *DuesDeps) paymentFile(ctx context.Context, payment PaymentModel, uid string) (out MemberDuesFileOut) {
+	out.Response = resp.NewResponse(http.StatusOK, "", nil)
+
+	if payment.ProveFileUrl == "" {
+		out.Response = resp.NewResponse(http.StatusNotFound, "", ErrFileNotFound)
+		return
+	}
+
+	if payment.MemberId != uid {
+		allowed, err := d.hasPermission(ctx, uid, user.PermDuesVerify)
+		if err != nil {
+			out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "has permission"))
+			return
+		}
+
+		if !allowed {
+			out.Response = resp.NewResponse(http.StatusForbidden, "", ErrFileForbidden)
+			return
+		}
+	}
+
+	url, err := d.Sign(payment.ProveFileUrl)
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "sign file url"))
+		return
+	}
+
+	out.Res.Url = url
+
+	return
+}
@@ -0,0 +1,546 @@
+package dues_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"testing"
+
+	arbitary "github.com/PA-D3RPLA/d3if43-htt-uhomestay/arbitrary"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/dues"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/httpdecode"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/user"
+	"github.com/gofrs/uuid"
+	"gopkg.in/guregu/null.v4"
+)
+
+// createMemberDuesMonths create the member with the unpaid member dues of the
+// given dues, ordered as given.
+func createMemberDuesMonths(d *dues.DuesDeps, duesms ...dues.DuesModel) (uid string, memberDuesIds []uint64, err error) {
+	uid, _, id, err := createMemberDues(d, memberSeed, duesms[0], unpaidMemDSeed)
+	if err != nil {
+		return "", nil, err
+	}
+	memberDuesIds = append(memberDuesIds, id)
+
+	for _, duesm := range duesms[1:] {
+		nd, err := d.DuesRepository.Save(context.Background(), duesm)
+		if err != nil {
+			return "", nil, err
+		}
+
+		md, err := d.MemberDuesRepository.Save(context.Background(), dues.MemberDuesModel{
+			MemberId:  uid,
+			DuesId:    nd.Id,
+			Status:    dues.Unpaid,
+			IdrAmount: duesm.IdrAmount,
+		})
+		if err != nil {
+			return "", nil, err
+		}
+		memberDuesIds = append(memberDuesIds, md.Id)
+	}
+
+	return uid, memberDuesIds, nil
+}
+
+func TestAddDuesPayment(t *testing.T) {
+	err := ClearTables(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.OpenFile(fileDir, os.O_RDONLY, 0o444)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	uid, ids, err := createMemberDuesMonths(duesDeps, duesSeed, duesSeed2, duesSeed3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file := httpdecode.FileHeader{
+		Filename: fileName,
+		File:     f,
+	}
+
+	testCases := []struct {
+		Name               string
+		ExpectedStatusCode int
+		Uid                string
+		In                 dues.AddDuesPaymentIn
+	}{
+		{
+			Name:               "Add Dues Payment Fail, Exceed Remaining Balance",
+			ExpectedStatusCode: http.StatusUnprocessableEntity,
+			Uid:                uid,
+			In: dues.AddDuesPaymentIn{
+				MemberDuesIds: strconv.FormatUint(ids[0], 10) + "," + strconv.FormatUint(ids[1], 10),
+				IdrAmount:     "50000",
+				File:          file,
+			},
+		},
+		{
+			Name:               "Add Dues Payment Fail, Not Covering Every Month",
+			ExpectedStatusCode: http.StatusUnprocessableEntity,
+			Uid:                uid,
+			In: dues.AddDuesPaymentIn{
+				MemberDuesIds: strconv.FormatUint(ids[0], 10) + "," + strconv.FormatUint(ids[1], 10),
+				IdrAmount:     "15000",
+				File:          file,
+			},
+		},
+		{
+			Name:               "Add Dues Payment Fail, Member Dues Ids Validation Fail",
+			ExpectedStatusCode: http.StatusUnprocessableEntity,
+			Uid:                uid,
+			In: dues.AddDuesPaymentIn{
+				File: file,
+			},
+		},
+		{
+			Name:               "Add Dues Payment Fail, File Validation Fail",
+			ExpectedStatusCode: http.StatusUnprocessableEntity,
+			Uid:                uid,
+			In: dues.AddDuesPaymentIn{
+				MemberDuesIds: strconv.FormatUint(ids[0], 10),
+			},
+		},
+		{
+			Name:               "Add Dues Payment Fail, Member Dues Not Found",
+			ExpectedStatusCode: http.StatusNotFound,
+			Uid:                uid,
+			In: dues.AddDuesPaymentIn{
+				MemberDuesIds: strconv.FormatUint(ids[0], 10) + ",999",
+				File:          file,
+			},
+		},
+		{
+			Name:               "Add Dues Payment Two Months and a Half Success",
+			ExpectedStatusCode: http.StatusOK,
+			Uid:                uid,
+			In: dues.AddDuesPaymentIn{
+				MemberDuesIds: strconv.FormatUint(ids[2], 10) + "," + strconv.FormatUint(ids[0], 10) + "," + strconv.FormatUint(ids[1], 10),
+				IdrAmount:     "50000",
+				File:          file,
+			},
+		},
+		{
+			Name:               "Add Dues Payment Fail, Member Dues Waiting",
+			ExpectedStatusCode: http.StatusNotFound,
+			Uid:                uid,
+			In: dues.AddDuesPaymentIn{
+				MemberDuesIds: strconv.FormatUint(ids[0], 10),
+				File:          file,
+			},
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.Name, func(t *testing.T) {
+			tx, err := db.Begin(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ctx := context.WithValue(context.Background(), arbitary.TrxX{}, tx)
+			res := duesDeps.AddDuesPayment(ctx, c.Uid, c.In)
+			tx.Commit(context.Background())
+			tx.Rollback(context.Background())
+
+			if res.StatusCode != c.ExpectedStatusCode {
+				t.Logf("%#v", res)
+				t.Fatalf("Expected response code %d. Got %d\n", c.ExpectedStatusCode, res.StatusCode)
+			}
+		})
+	}
+
+	// The oldest month is settled first whatever the order of the ids.
+	for _, id := range ids {
+		md, err := memberDuesRepository.FindById(context.Background(), id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if md.Status != dues.Waiting {
+			t.Fatalf("Expected member dues %d waiting. Got %#v\n", id, md)
+		}
+	}
+}
+
+func TestPaidDuesPayment(t *testing.T) {
+	err := ClearTables(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.OpenFile(fileDir, os.O_RDONLY, 0o444)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := *duesDeps
+	d.Upload = func(filename string, file io.Reader) (string, error) {
+		return "https://res.cloudinary.com/demo/raw/private/v1/uhomestay/dues/" + filename, nil
+	}
+
+	uid, ids, err := createMemberDuesMonths(&d, duesSeed, duesSeed2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	payment := d.AddDuesPayment(context.Background(), uid, dues.AddDuesPaymentIn{
+		MemberDuesIds: strconv.FormatUint(ids[0], 10) + "," + strconv.FormatUint(ids[1], 10),
+		IdrAmount:     "30000",
+		File: httpdecode.FileHeader{
+			Filename: fileName,
+			File:     f,
+		},
+	})
+	if payment.Error != nil {
+		t.Fatal(payment.Error)
+	}
+
+	pid := strconv.FormatInt(payment.Res.Id, 10)
+
+	testCases := []struct {
+		Name               string
+		ExpectedStatusCode int
+		Id                 string
+		In                 dues.PaidDuesPaymentIn
+		ExpectedStatus     []dues.DuesStatus
+	}{
+		{
+			Name:               "Approve Dues Payment Success",
+			ExpectedStatusCode: http.StatusOK,
+			Id:                 pid,
+			In: dues.PaidDuesPaymentIn{
+				IsPaid: null.BoolFrom(true),
+			},
+			ExpectedStatus: []dues.DuesStatus{dues.Paid, dues.Unpaid},
+		},
+		{
+			Name:               "Revert Dues Payment Success",
+			ExpectedStatusCode: http.StatusOK,
+			Id:                 pid,
+			In: dues.PaidDuesPaymentIn{
+				IsPaid: null.BoolFrom(false),
+			},
+			ExpectedStatus: []dues.DuesStatus{dues.Waiting, dues.Waiting},
+		},
+		{
+			Name:               "Approve Reverted Dues Payment Success",
+			ExpectedStatusCode: http.StatusOK,
+			Id:                 pid,
+			In: dues.PaidDuesPaymentIn{
+				IsPaid: null.BoolFrom(true),
+			},
+			ExpectedStatus: []dues.DuesStatus{dues.Paid, dues.Unpaid},
+		},
+		{
+			Name:               "Approve Dues Payment Fail, Already Approved",
+			ExpectedStatusCode: http.StatusConflict,
+			Id:                 pid,
+			In: dues.PaidDuesPaymentIn{
+				IsPaid: null.BoolFrom(true),
+			},
+			ExpectedStatus: []dues.DuesStatus{dues.Paid, dues.Unpaid},
+		},
+		{
+			Name:               "Approve Dues Payment Fail, Is Paid Validation Fail",
+			ExpectedStatusCode: http.StatusUnprocessableEntity,
+			Id:                 pid,
+			In:                 dues.PaidDuesPaymentIn{},
+		},
+		{
+			Name:               "Approve Dues Payment Fail, Not Found",
+			ExpectedStatusCode: http.StatusNotFound,
+			Id:                 "999",
+			In: dues.PaidDuesPaymentIn{
+				IsPaid: null.BoolFrom(true),
+			},
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.Name, func(t *testing.T) {
+			tx, err := db.Begin(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ctx := context.WithValue(context.Background(), arbitary.TrxX{}, tx)
+			res := d.PaidDuesPayment(ctx, "", c.Id, c.In)
+			tx.Commit(context.Background())
+			tx.Rollback(context.Background())
+
+			if res.StatusCode != c.ExpectedStatusCode {
+				t.Logf("%#v", res)
+				t.Fatalf("Expected response code %d. Got %d\n", c.ExpectedStatusCode, res.StatusCode)
+			}
+
+			for i, status := range c.ExpectedStatus {
+				md, err := memberDuesRepository.FindById(context.Background(), ids[i])
+				if err != nil {
+					t.Fatal(err)
+				}
+				if md.Status != status {
+					t.Fatalf("Expected member dues %d %s. Got %#v\n", ids[i], status.String, md)
+				}
+			}
+		})
+	}
+
+	// The second month keep the remaining balance of the partial payment.
+	md, err := memberDuesRepository.FindById(context.Background(), ids[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if md.PaidIdrAmount != 10000 {
+		t.Fatalf("Expected paid amount 10000. Got %s\n", md.PaidIdrAmount)
+	}
+
+	// The rest of the balance is paid in cash to the admin.
+	res := d.PaidMemberDues(context.Background(), "", strconv.FormatUint(ids[1], 10), dues.PaidMemberDuesIn{
+		IsPaid: null.BoolFrom(true),
+	})
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected response code %d. Got %d\n", http.StatusOK, res.StatusCode)
+	}
+
+	md, err = memberDuesRepository.FindById(context.Background(), ids[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if md.Status != dues.Paid || md.PaidIdrAmount != md.IdrAmount {
+		t.Fatalf("Expected member dues paid. Got %#v\n", md)
+	}
+}
+
+func TestQueryDuesPayment(t *testing.T) {
+	err := ClearTables(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.OpenFile(fileDir, os.O_RDONLY, 0o444)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	uid, ids, err := createMemberDuesMonths(duesDeps, duesSeed, duesSeed2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	payment := duesDeps.AddDuesPayment(context.Background(), uid, dues.AddDuesPaymentIn{
+		MemberDuesIds: strconv.FormatUint(ids[0], 10) + "," + strconv.FormatUint(ids[1], 10),
+		File: httpdecode.FileHeader{
+			Filename: fileName,
+			File:     f,
+		},
+	})
+	if payment.Error != nil {
+		t.Fatal(payment.Error)
+	}
+
+	testCases := []struct {
+		Name          string
+		Status        string
+		ExpectedTotal int64
+	}{
+		{
+			Name:          "Query Waiting Dues Payment Success",
+			Status:        "waiting",
+			ExpectedTotal: 1,
+		},
+		{
+			Name:          "Query Approved Dues Payment Success",
+			Status:        "approved",
+			ExpectedTotal: 0,
+		},
+		{
+			Name:          "Query Any Dues Payment Success",
+			ExpectedTotal: 1,
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.Name, func(t *testing.T) {
+			res := duesDeps.QueryDuesPayment(context.Background(), dues.QueryDuesPaymentQIn{Status: c.Status})
+			if res.StatusCode != http.StatusOK {
+				t.Logf("%#v", res)
+				t.Fatalf("Expected response code %d. Got %d\n", http.StatusOK, res.StatusCode)
+			}
+
+			if res.Res.Total != c.ExpectedTotal {
+				t.Fatalf("Expected total %d. Got %d\n", c.ExpectedTotal, res.Res.Total)
+			}
+
+			if c.ExpectedTotal != 0 && len(res.Res.Payments[0].Allocations) != 2 {
+				t.Fatalf("Expected 2 allocations. Got %#v\n", res.Res.Payments[0])
+			}
+		})
+	}
+}
+
+func TestFindCashflowPaymentFile(t *testing.T) {
+	err := ClearTables(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.OpenFile(fileDir, os.O_RDONLY, 0o444)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := *duesDeps
+	d.Upload = func(filename string, file io.Reader) (string, error) {
+		return "https://res.cloudinary.com/demo/raw/private/v1/uhomestay/dues/" + filename, nil
+	}
+
+	uid, ids, err := createMemberDuesMonths(&d, duesSeed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nonAdmin := user.MemberModel(memberSeed2)
+	nonAdmin.IsAdmin = false
+	otherUid, _ := uuid.NewV6()
+	nonAdmin.Id.Scan(otherUid.String())
+	if err = memberRepository.Save(context.Background(), nonAdmin); err != nil {
+		t.Fatal(err)
+	}
+
+	payment := d.AddDuesPayment(context.Background(), uid, dues.AddDuesPaymentIn{
+		MemberDuesIds: strconv.FormatUint(ids[0], 10),
+		IdrAmount:     "20000",
+		File: httpdecode.FileHeader{
+			Filename: fileName,
+			File:     f,
+		},
+	})
+	if payment.Error != nil {
+		t.Fatal(payment.Error)
+	}
+
+	paid := d.PaidDuesPayment(context.Background(), "", strconv.FormatInt(payment.Res.Id, 10), dues.PaidDuesPaymentIn{
+		IsPaid: null.BoolFrom(true),
+	})
+	if paid.Error != nil {
+		t.Fatal(paid.Error)
+	}
+
+	md, err := memberDuesRepository.FindById(context.Background(), ids[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cf, err := cashflowRepository.FindById(context.Background(), uint64(md.CashflowId.Int64))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cf.ProveFileUrl != "" {
+		t.Fatalf("Expected dues cashflow without prove file. Got %s\n", cf.ProveFileUrl)
+	}
+
+	cid := strconv.FormatInt(md.CashflowId.Int64, 10)
+
+	testCases := []struct {
+		Name               string
+		ExpectedStatusCode int
+		Id                 string
+		Uid                string
+	}{
+		{
+			Name:               "Find Cashflow Payment File as Owner Success",
+			ExpectedStatusCode: http.StatusOK,
+			Id:                 cid,
+			Uid:                uid,
+		},
+		{
+			Name:               "Find Cashflow Payment File as Other Member Fail, Forbidden",
+			ExpectedStatusCode: http.StatusForbidden,
+			Id:                 cid,
+			Uid:                otherUid.String(),
+		},
+		{
+			Name:               "Find Cashflow Payment File as Anonymous Fail, Unauthorized",
+			ExpectedStatusCode: http.StatusUnauthorized,
+			Id:                 cid,
+			Uid:                "",
+		},
+		{
+			Name:               "Find Cashflow Payment File Fail, Not Found",
+			ExpectedStatusCode: http.StatusNotFound,
+			Id:                 "999",
+			Uid:                uid,
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.Name, func(t *testing.T) {
+			res := d.FindCashflowPaymentFile(context.Background(), c.Id, c.Uid)
+
+			if res.StatusCode != c.ExpectedStatusCode {
+				t.Logf("%#v", res)
+				t.Fatalf("Expected response code %d. Got %d\n", c.ExpectedStatusCode, res.StatusCode)
+			}
+		})
+	}
+}
+
+func TestPaidDuesPaymentSettled(t *testing.T) {
+	err := ClearTables(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.OpenFile(fileDir, os.O_RDONLY, 0o444)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := *duesDeps
+	d.Upload = func(filename string, file io.Reader) (string, error) {
+		return "https://res.cloudinary.com/demo/raw/private/v1/uhomestay/dues/" + filename, nil
+	}
+
+	uid, ids, err := createMemberDuesMonths(&d, duesSeed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	payment := d.AddDuesPayment(context.Background(), uid, dues.AddDuesPaymentIn{
+		MemberDuesIds: strconv.FormatUint(ids[0], 10),
+		IdrAmount:     "20000",
+		File: httpdecode.FileHeader{
+			Filename: fileName,
+			File:     f,
+		},
+	})
+	if payment.Error != nil {
+		t.Fatal(payment.Error)
+	}
+
+	// The member dues is settled by another payment while waiting.
+	md, err := memberDuesRepository.FindById(context.Background(), ids[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	md.PaidIdrAmount = md.IdrAmount
+	if err = memberDuesRepository.UpdateById(context.Background(), md.Id, md); err != nil {
+		t.Fatal(err)
+	}
+
+	res := d.PaidDuesPayment(context.Background(), "", strconv.FormatInt(payment.Res.Id, 10), dues.PaidDuesPaymentIn{
+		IsPaid: null.BoolFrom(true),
+	})
+	if res.StatusCode != http.StatusConflict {
+		t.Logf("%#v", res)
+		t.Fatalf("Expected response code %d. Got %d\n", http.StatusConflict, res.StatusCode)
+	}
+}
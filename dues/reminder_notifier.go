@@ -0,0 +1,114 @@
+package dues
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/money"
+	"github.com/pkg/errors"
+)
+
+const (
+	LogNotifierName  = "log"
+	FileNotifierName = "file"
+)
+
+// Reminder is the message to the member with overdue member dues.
+type Reminder struct {
+	MemberId   string    `json:"member_id"`
+	Name       string    `json:"name"`
+	WaPhone    string    `json:"wa_phone"`
+	Months     int64     `json:"months"`
+	IdrAmount  money.IDR `json:"idr_amount"`
+	OldestDate time.Time `json:"oldest_date"`
+	Message    string    `json:"message"`
+	// WaLink open the WhatsApp chat of the member with the message, it is
+	// empty when the member has no WhatsApp phone.
+	WaLink string `json:"wa_link"`
+}
+
+// Notifier deliver the reminders of the overdue member dues. The reminder run
+// is retried when Notify fail, so it may receive the same reminders again.
+type Notifier interface {
+	Name() string
+	Notify(ctx context.Context, reminders []Reminder) error
+}
+
+// NewNotifier create the notifier of the kind, the file notifier append the
+// reminders to the file at the path.
+func NewNotifier(kind, path string) (Notifier, error) {
+	switch kind {
+	case LogNotifierName:
+		return NewLogNotifier(log.Default()), nil
+	case FileNotifierName:
+		return NewFileNotifier(path), nil
+	}
+
+	return nil, fmt.Errorf("unknown notifier %q, must be one of %s or %s", kind, LogNotifierName, FileNotifierName)
+}
+
+// LogNotifier only print the reminders, it is meant for local use.
+type LogNotifier struct {
+	Logger *log.Logger
+}
+
+func NewLogNotifier(logger *log.Logger) *LogNotifier {
+	return &LogNotifier{
+		Logger: logger,
+	}
+}
+
+func (n *LogNotifier) Name() string {
+	return LogNotifierName
+}
+
+func (n *LogNotifier) Notify(ctx context.Context, reminders []Reminder) error {
+	for _, r := range reminders {
+		n.Logger.Printf("dues reminder: %s (%s) overdue %d months, amount %s: %s",
+			r.Name, r.WaPhone, r.Months, r.IdrAmount, r.WaLink)
+	}
+
+	return nil
+}
+
+// FileNotifier append every reminder as a JSON line to the file, so the
+// reminders can be sent by hand or by other tool.
+type FileNotifier struct {
+	Path string
+	mu   sync.Mutex
+}
+
+func NewFileNotifier(path string) *FileNotifier {
+	return &FileNotifier{
+		Path: path,
+	}
+}
+
+func (n *FileNotifier) Name() string {
+	return FileNotifierName
+}
+
+func (n *FileNotifier) Notify(ctx context.Context, reminders []Reminder) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	f, err := os.OpenFile(n.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return errors.Wrap(err, "open reminder file")
+	}
+	defer f.Close()
+
+	enc := json.NewEncoder(f)
+	for _, r := range reminders {
+		if err := enc.Encode(r); err != nil {
+			return errors.Wrap(err, "write reminder")
+		}
+	}
+
+	return f.Close()
+}
@@ -0,0 +1,268 @@
+package dues
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/money"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/resp"
+	"github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+)
+
+// reminderLockKey is the advisory lock key held while the reminder is sent,
+// so other instance doesn't send the same reminder.
+const reminderLockKey int64 = 4275002
+
+// reminderCheckInterval is how often the reminder job check whether the next
+// reminder is due, the cadence itself is kept by the latest reminder run.
+const reminderCheckInterval = time.Hour
+
+// waNumber turn the stored phone into the international number used by the
+// WhatsApp link, the local 0 prefix is replaced by the Indonesia code.
+func waNumber(phone string) string {
+	var b strings.Builder
+	for _, c := range phone {
+		if c >= '0' && c <= '9' {
+			b.WriteRune(c)
+		}
+	}
+
+	n := b.String()
+	if strings.HasPrefix(n, "0") {
+		n = "62" + n[1:]
+	}
+
+	return n
+}
+
+func (d *DuesDeps) newReminder(a ArrearsViewModel) Reminder {
+	msg := fmt.Sprintf(
+		"Halo %s, iuran %s anda tertunggak %d bulan sejak %s dengan total Rp %s. Mohon segera melakukan pembayaran, abaikan pesan ini jika sudah membayar.",
+		a.Name,
+		d.Letterhead.OrgName,
+		a.Months,
+		a.OldestDate.Format("01-2006"),
+		a.IdrAmount.String(),
+	)
+
+	var waLink string
+	if n := waNumber(a.WaPhone); n != "" {
+		waLink = "https://wa.me/" + n + "?text=" + url.QueryEscape(msg)
+	}
+
+	return Reminder{
+		MemberId:   a.MemberId,
+		Name:       a.Name,
+		WaPhone:    a.WaPhone,
+		Months:     a.Months,
+		IdrAmount:  a.IdrAmount,
+		OldestDate: a.OldestDate,
+		Message:    msg,
+		WaLink:     waLink,
+	}
+}
+
+type (
+	ReminderOut struct {
+		MemberId   string `json:"member_id"`
+		Name       string `json:"name"`
+		WaPhone    string `json:"wa_phone"`
+		Months     int64  `json:"months"`
+		IdrAmount  string `json:"idr_amount"`
+		OldestDate string `json:"oldest_date"`
+		Message    string `json:"message"`
+		WaLink     string `json:"wa_link"`
+	}
+	ReminderRunOut struct {
+		Id        uint64 `json:"id"`
+		Members   int    `json:"members"`
+		IdrAmount string `json:"idr_amount"`
+		Notifier  string `json:"notifier"`
+		CreatedAt string `json:"created_at"`
+	}
+	QueryDuesRemindersQIn struct {
+		MinMonths string
+	}
+	QueryDuesRemindersRes struct {
+		// LastRun is nil when the reminder has never been sent.
+		LastRun   *ReminderRunOut `json:"last_run"`
+		Reminders []ReminderOut   `json:"reminders"`
+	}
+	QueryDuesRemindersOut struct {
+		resp.Response
+		Res QueryDuesRemindersRes
+	}
+)
+
+func toReminderRunOut(m ReminderRunModel) *ReminderRunOut {
+	return &ReminderRunOut{
+		Id:        m.Id,
+		Members:   m.Members,
+		IdrAmount: m.IdrAmount.String(),
+		Notifier:  m.Notifier,
+		CreatedAt: m.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+// QueryDuesReminders list the members who need a WhatsApp reminder of their
+// overdue member dues at the given time.
+func (d *DuesDeps) QueryDuesReminders(ctx context.Context, now time.Time, qin QueryDuesRemindersQIn) (out QueryDuesRemindersOut) {
+	out.Response = resp.NewResponse(http.StatusOK, "", nil)
+
+	minMonths, err := parseMinMonths(qin.MinMonths)
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusUnprocessableEntity, "", err)
+		return
+	}
+
+	arrears, err := d.MemberDuesRepository.QueryArrears(ctx, now, minMonths)
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "query arrears"))
+		return
+	}
+
+	lastRun, err := d.ScheduleRepository.LatestReminderRun(ctx)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "latest reminder run"))
+		return
+	}
+
+	if lastRun.Id != 0 {
+		out.Res.LastRun = toReminderRunOut(lastRun)
+	}
+
+	outReminders := make([]ReminderOut, len(arrears))
+	for i, a := range arrears {
+		r := d.newReminder(a)
+		outReminders[i] = ReminderOut{
+			MemberId:   r.MemberId,
+			Name:       r.Name,
+			WaPhone:    r.WaPhone,
+			Months:     r.Months,
+			IdrAmount:  r.IdrAmount.String(),
+			OldestDate: r.OldestDate.Format("2006-01-02"),
+			Message:    r.Message,
+			WaLink:     r.WaLink,
+		}
+	}
+	out.Res.Reminders = outReminders
+
+	return
+}
+
+type (
+	RemindDuesRes struct {
+		// IsRun is false when the reminder is not due yet or other instance is
+		// sending it.
+		IsRun bool            `json:"is_run"`
+		Run   *ReminderRunOut `json:"run"`
+	}
+	RemindDuesOut struct {
+		resp.Response
+		Res RemindDuesRes
+	}
+)
+
+// RemindDues send the reminder to every member with at least the minimum
+// months of overdue member dues through the notifier, once the interval since
+// the latest reminder has passed.
+func (d *DuesDeps) RemindDues(ctx context.Context, now time.Time, interval time.Duration, minMonths int) (out RemindDuesOut) {
+	out.Response = resp.NewResponse(http.StatusOK, "", nil)
+
+	if d.Notifier == nil || interval <= 0 {
+		return
+	}
+
+	var run ReminderRunModel
+	locked, err := d.ScheduleRepository.TryLockTx(ctx, reminderLockKey, func(ctx context.Context) error {
+		lastRun, err := d.ScheduleRepository.LatestReminderRun(ctx)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return errors.Wrap(err, "latest reminder run")
+		}
+
+		if lastRun.Id != 0 && now.Before(lastRun.CreatedAt.Add(interval)) {
+			return nil
+		}
+
+		arrears, err := d.MemberDuesRepository.QueryArrears(ctx, now, minMonths)
+		if err != nil {
+			return errors.Wrap(err, "query arrears")
+		}
+
+		var amt money.IDR
+		reminders := make([]Reminder, len(arrears))
+		for i, a := range arrears {
+			amt += a.IdrAmount
+			reminders[i] = d.newReminder(a)
+		}
+
+		if len(reminders) != 0 {
+			if err = d.Notifier.Notify(ctx, reminders); err != nil {
+				return errors.Wrap(err, "notify reminders")
+			}
+		}
+
+		run, err = d.ScheduleRepository.SaveReminderRun(ctx, ReminderRunModel{
+			Members:   len(reminders),
+			IdrAmount: amt,
+			Notifier:  d.Notifier.Name(),
+		})
+		if err != nil {
+			return errors.Wrap(err, "save reminder run")
+		}
+
+		return nil
+	})
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "remind dues"))
+		return
+	}
+
+	if !locked || run.Id == 0 {
+		return
+	}
+
+	out.Res = RemindDuesRes{
+		IsRun: true,
+		Run:   toReminderRunOut(run),
+	}
+
+	return
+}
+
+// RunReminder send the dues reminder at the interval cadence until the context
+// is done.
+func (d *DuesDeps) RunReminder(ctx context.Context, interval time.Duration, minMonths int) {
+	checkInterval := reminderCheckInterval
+	if interval < checkInterval {
+		checkInterval = interval
+	}
+
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		out := d.RemindDues(ctx, time.Now(), interval, minMonths)
+		if out.Error != nil {
+			d.CaptureExeption(out.Error)
+			continue
+		}
+
+		if out.Res.IsRun {
+			log.Printf("dues reminder: %d members reminded through %s, amount %s",
+				out.Res.Run.Members, out.Res.Run.Notifier, out.Res.Run.IdrAmount)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package dues_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/dues"
+)
+
+func TestRemindDues(t *testing.T) {
+	err := ClearTables(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	notifier.reminders = nil
+
+	uid, _, err := createMemberDuesMonths(duesDeps, pastDuesSeed2, pastDuesSeed, duesSeed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+
+	testCases := []struct {
+		Name          string
+		ExpectedIsRun bool
+		Now           time.Time
+	}{
+		{
+			Name:          "Remind Dues Success",
+			ExpectedIsRun: true,
+			Now:           now,
+		},
+		{
+			Name:          "Remind Dues Skipped, Not Due Yet",
+			ExpectedIsRun: false,
+			Now:           now.Add(time.Hour),
+		},
+		{
+			Name:          "Remind Dues Success, Interval Passed",
+			ExpectedIsRun: true,
+			Now:           now.Add(time.Hour * 24 * 8),
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.Name, func(t *testing.T) {
+			res := duesDeps.RemindDues(context.Background(), c.Now, time.Hour*24*7, 1)
+
+			if res.StatusCode != http.StatusOK {
+				t.Logf("%#v", res)
+				t.Fatalf("Expected response code %d. Got %d\n", http.StatusOK, res.StatusCode)
+			}
+
+			if res.Res.IsRun != c.ExpectedIsRun {
+				t.Fatalf("Expected is run %t. Got %t\n", c.ExpectedIsRun, res.Res.IsRun)
+			}
+		})
+	}
+
+	if len(notifier.reminders) != 2 {
+		t.Fatalf("Expected 2 reminders. Got %d\n", len(notifier.reminders))
+	}
+
+	r := notifier.reminders[0]
+	if r.MemberId != uid || r.Months != 2 || r.IdrAmount != 40000 {
+		t.Fatalf("Expected reminder of 2 months overdue. Got %#v\n", r)
+	}
+	if !strings.HasPrefix(r.WaLink, "https://wa.me/6282111110000?text=") {
+		t.Fatalf("Expected WhatsApp link of the member. Got %s\n", r.WaLink)
+	}
+}
+
+func TestQueryDuesReminders(t *testing.T) {
+	err := ClearTables(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, err = createMemberDuesMonths(duesDeps, pastDuesSeed2, pastDuesSeed, duesSeed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		Name               string
+		ExpectedStatusCode int
+		ExpectedLen        int
+		QIn                dues.QueryDuesRemindersQIn
+	}{
+		{
+			Name:               "Query Dues Reminders Success",
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedLen:        1,
+			QIn:                dues.QueryDuesRemindersQIn{},
+		},
+		{
+			Name:               "Query Dues Reminders With Min Months Success",
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedLen:        0,
+			QIn: dues.QueryDuesRemindersQIn{
+				MinMonths: "3",
+			},
+		},
+		{
+			Name:               "Query Dues Reminders Fail, Min Months Validation Fail",
+			ExpectedStatusCode: http.StatusUnprocessableEntity,
+			QIn: dues.QueryDuesRemindersQIn{
+				MinMonths: "satu",
+			},
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.Name, func(t *testing.T) {
+			res := duesDeps.QueryDuesReminders(context.Background(), time.Now(), c.QIn)
+
+			if res.StatusCode != c.ExpectedStatusCode {
+				t.Logf("%#v", res)
+				t.Fatalf("Expected response code %d. Got %d\n", c.ExpectedStatusCode, res.StatusCode)
+			}
+
+			if len(res.Res.Reminders) != c.ExpectedLen {
+				t.Fatalf("Expected reminders length %d. Got %d\n", c.ExpectedLen, len(res.Res.Reminders))
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package dues
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/money"
+)
+
+type ScheduleStatus string
+
+const (
+	ScheduleCreated ScheduleStatus = "created"
+	ScheduleSkipped ScheduleStatus = "skipped"
+	ScheduleFailed  ScheduleStatus = "failed"
+)
+
+type ScheduleRunModel struct {
+	Id        uint64
+	DuesDate  time.Time
+	Status    ScheduleStatus
+	DuesId    sql.NullInt64
+	IdrAmount money.IDR
+	Message   string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type ReminderRunModel struct {
+	Id        uint64
+	Members   int
+	IdrAmount money.IDR
+	Notifier  string
+	CreatedAt time.Time
+}
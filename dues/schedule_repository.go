@@ -0,0 +1,246 @@
+package dues
+
+import (
+	"context"
+	"time"
+
+	arbitary "github.com/PA-D3RPLA/d3if43-htt-uhomestay/arbitrary"
+
+	"github.com/georgysavva/scany/pgxscan"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+type ScheduleRepository struct {
+	PostgreDb *pgxpool.Pool
+}
+
+func NewScheduleRepository(postgreDb *pgxpool.Pool) *ScheduleRepository {
+	return &ScheduleRepository{
+		PostgreDb: postgreDb,
+	}
+}
+
+type ScheduleQuerier func(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+
+// TryLockTx run fn in a new transaction holding the advisory lock of the key,
+// fn is not run and false is returned when another session hold the lock.
+// The transaction is committed only when fn return no error.
+func (r *ScheduleRepository) TryLockTx(ctx context.Context, key int64, fn func(ctx context.Context) error) (bool, error) {
+	tx, err := r.PostgreDb.Begin(context.Background())
+	if err != nil {
+		return false, err
+	}
+
+	defer tx.Rollback(context.Background())
+
+	var locked bool
+	err = tx.QueryRow(
+		context.Background(),
+		`SELECT pg_try_advisory_xact_lock($1)`,
+		key,
+	).Scan(&locked)
+	if err != nil {
+		return false, err
+	}
+
+	if !locked {
+		return false, nil
+	}
+
+	if err = fn(context.WithValue(ctx, arbitary.TrxX{}, tx)); err != nil {
+		return true, err
+	}
+
+	return true, tx.Commit(context.Background())
+}
+
+// LockTx wait for the advisory lock of the key until the transaction in the
+// context end, it does nothing outside of transaction.
+func (r *ScheduleRepository) LockTx(ctx context.Context, key int64) error {
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if !ok {
+		return nil
+	}
+
+	_, err := tx.Exec(
+		context.Background(),
+		`SELECT pg_advisory_xact_lock($1)`,
+		key,
+	)
+
+	return err
+}
+
+func (r *ScheduleRepository) SaveRun(ctx context.Context, m ScheduleRunModel) (nm ScheduleRunModel, err error) {
+	sqlQuery := `
+		INSERT INTO dues_schedule_runs (
+			dues_date,
+			status,
+			dues_id,
+			idr_amount,
+			message,
+			created_at,
+			updated_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (dues_date, status) DO UPDATE
+		SET
+			dues_id = EXCLUDED.dues_id,
+			idr_amount = EXCLUDED.idr_amount,
+			message = EXCLUDED.message,
+			updated_at = EXCLUDED.updated_at
+		RETURNING id, created_at
+	`
+
+	var query ScheduleQuerier
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		query = tx.Query
+	} else {
+		query = r.PostgreDb.Query
+	}
+
+	t := time.Now()
+
+	rows, err := query(
+		context.Background(),
+		sqlQuery,
+		m.DuesDate,
+		m.Status,
+		m.DuesId,
+		m.IdrAmount,
+		m.Message,
+		t,
+		t,
+	)
+	if err != nil {
+		return ScheduleRunModel{}, err
+	}
+
+	if err = pgxscan.ScanOne(&m, rows); err != nil {
+		return ScheduleRunModel{}, err
+	}
+
+	m.UpdatedAt = t
+
+	return m, nil
+}
+
+func (r *ScheduleRepository) QueryRun(ctx context.Context, limit int) ([]ScheduleRunModel, error) {
+	sqlQuery := `
+		SELECT
+			id,
+			dues_date,
+			status,
+			dues_id,
+			idr_amount,
+			message,
+			created_at,
+			updated_at
+		FROM dues_schedule_runs
+		ORDER BY updated_at DESC, id DESC
+		LIMIT $1
+	`
+
+	rows, err := r.PostgreDb.Query(
+		context.Background(),
+		sqlQuery,
+		limit,
+	)
+	if err != nil {
+		return []ScheduleRunModel{}, err
+	}
+	defer rows.Close()
+
+	var mps []*ScheduleRunModel
+	if err := pgxscan.ScanAll(&mps, rows); err != nil {
+		return []ScheduleRunModel{}, err
+	}
+
+	ms := make([]ScheduleRunModel, len(mps))
+	for i, m := range mps {
+		ms[i] = *m
+	}
+
+	return ms, nil
+}
+
+func (r *ScheduleRepository) SaveReminderRun(ctx context.Context, m ReminderRunModel) (nm ReminderRunModel, err error) {
+	sqlQuery := `
+		INSERT INTO dues_reminder_runs (
+			members,
+			idr_amount,
+			notifier,
+			created_at
+		)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`
+
+	var query ScheduleQuerier
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		query = tx.Query
+	} else {
+		query = r.PostgreDb.Query
+	}
+
+	t := time.Now()
+
+	rows, err := query(
+		context.Background(),
+		sqlQuery,
+		m.Members,
+		m.IdrAmount,
+		m.Notifier,
+		t,
+	)
+	if err != nil {
+		return ReminderRunModel{}, err
+	}
+
+	if err = pgxscan.ScanOne(&m, rows); err != nil {
+		return ReminderRunModel{}, err
+	}
+
+	m.CreatedAt = t
+
+	return m, nil
+}
+
+func (r *ScheduleRepository) LatestReminderRun(ctx context.Context) (m ReminderRunModel, err error) {
+	sqlQuery := `
+		SELECT
+			id,
+			members,
+			idr_amount,
+			notifier,
+			created_at
+		FROM dues_reminder_runs
+		ORDER BY created_at DESC, id DESC
+		LIMIT 1
+	`
+
+	var query ScheduleQuerier
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		query = tx.Query
+	} else {
+		query = r.PostgreDb.Query
+	}
+
+	rows, err := query(
+		context.Background(),
+		sqlQuery,
+	)
+	if err != nil {
+		return ReminderRunModel{}, err
+	}
+
+	if err = pgxscan.ScanOne(&m, rows); err != nil {
+		return ReminderRunModel{}, err
+	}
+
+	return m, nil
+}
@@ -0,0 +1,259 @@
+package dues
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/resp"
+	"github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+)
+
+// duesLockKey is the advisory lock key held while the dues of a month is
+// created, so the scheduler of other instance or the admin can't create the
+// same month at the same time.
+const duesLockKey int64 = 4275001
+
+const (
+	scheduleRunLimit    = 24
+	scheduleUpcomingLen = 3
+)
+
+var ErrNoPreviousDues = errors.New("belum ada tagihan iuran sebelumnya untuk menentukan nominal")
+
+// scheduleDuesDate return the month of the dues generated by the run on the
+// given time, which is the next month.
+func scheduleDuesDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+}
+
+type (
+	ScheduleRunOut struct {
+		Id        uint64 `json:"id"`
+		DuesDate  string `json:"dues_date"`
+		Status    string `json:"status"`
+		DuesId    int64  `json:"dues_id"`
+		IdrAmount string `json:"idr_amount"`
+		Message   string `json:"message"`
+		CreatedAt string `json:"created_at"`
+		UpdatedAt string `json:"updated_at"`
+	}
+	RunDuesScheduleRes struct {
+		// IsRun is false when the schedule is not due yet or other instance is
+		// running it.
+		IsRun bool           `json:"is_run"`
+		Run   ScheduleRunOut `json:"run"`
+	}
+	RunDuesScheduleOut struct {
+		resp.Response
+		Res RunDuesScheduleRes
+	}
+)
+
+func toScheduleRunOut(m ScheduleRunModel) ScheduleRunOut {
+	return ScheduleRunOut{
+		Id:        m.Id,
+		DuesDate:  m.DuesDate.Format("2006-01"),
+		Status:    string(m.Status),
+		DuesId:    m.DuesId.Int64,
+		IdrAmount: m.IdrAmount.String(),
+		Message:   m.Message,
+		CreatedAt: m.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: m.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+// RunDuesSchedule create the next month dues with the latest dues amount once
+// the schedule day of the month has come. The run is skipped when the dues of
+// the month already exist, so it is safe to be run repeatedly.
+func (d *DuesDeps) RunDuesSchedule(ctx context.Context, now time.Time) (out RunDuesScheduleOut) {
+	out.Response = resp.NewResponse(http.StatusOK, "", nil)
+
+	if d.ScheduleDay == 0 || now.Day() < d.ScheduleDay {
+		return
+	}
+
+	run := ScheduleRunModel{
+		DuesDate: scheduleDuesDate(now),
+	}
+
+	locked, err := d.ScheduleRepository.TryLockTx(ctx, duesLockKey, func(ctx context.Context) error {
+		dues, err := d.DuesRepository.FindOtherByYYYYMM(ctx, 0, run.DuesDate)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return errors.Wrap(err, "find dues by yyyy mm")
+		}
+
+		if dues.Id != 0 {
+			run.Status = ScheduleSkipped
+			run.Message = ErrExistDues.Error()
+		} else {
+			latest, err := d.DuesRepository.Latest(ctx)
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrNoPreviousDues
+			}
+			if err != nil {
+				return errors.Wrap(err, "latest dues")
+			}
+
+			dues = DuesModel{
+				Date:      run.DuesDate,
+				IdrAmount: latest.IdrAmount,
+			}
+			if dues, err = d.DuesRepository.Save(ctx, dues); err != nil {
+				return errors.Wrap(err, "save dues")
+			}
+
+			if err = d.MemberDuesRepository.GenerateDues(ctx, dues.Id); err != nil {
+				return errors.Wrap(err, "generate dues")
+			}
+
+			run.Status = ScheduleCreated
+		}
+
+		run.DuesId = sql.NullInt64{Int64: int64(dues.Id), Valid: true}
+		run.IdrAmount = dues.IdrAmount
+
+		saved, err := d.ScheduleRepository.SaveRun(ctx, run)
+		if err != nil {
+			return errors.Wrap(err, "save schedule run")
+		}
+		run = saved
+
+		return nil
+	})
+	if !locked && err == nil {
+		return
+	}
+
+	out.Res.IsRun = true
+
+	if err != nil {
+		// The transaction is rolled back, so the failure is recorded outside of
+		// it.
+		run.Status = ScheduleFailed
+		run.DuesId = sql.NullInt64{}
+		run.IdrAmount = 0
+		run.Message = errors.Cause(err).Error()
+
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "run dues schedule"))
+
+		saved, err := d.ScheduleRepository.SaveRun(context.Background(), run)
+		if err != nil {
+			d.CaptureExeption(errors.Wrap(err, "save failed schedule run"))
+		} else {
+			run = saved
+		}
+	}
+
+	out.Res.Run = toScheduleRunOut(run)
+
+	return
+}
+
+// RunScheduler check the dues schedule every interval until the context is
+// done.
+func (d *DuesDeps) RunScheduler(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		out := d.RunDuesSchedule(ctx, time.Now())
+		if out.Error != nil {
+			d.CaptureExeption(out.Error)
+			continue
+		}
+
+		if out.Res.Run.Status == string(ScheduleCreated) {
+			log.Printf("dues schedule: created dues %d of %s, amount %s",
+				out.Res.Run.DuesId, out.Res.Run.DuesDate, out.Res.Run.IdrAmount)
+		}
+	}
+}
+
+type (
+	UpcomingScheduleOut struct {
+		RunDate  string `json:"run_date"`
+		DuesDate string `json:"dues_date"`
+		// IdrAmount is the latest dues amount which is used when the run
+		// create the dues.
+		IdrAmount string `json:"idr_amount"`
+		// DuesId is set when the dues of the month already exist, so the run
+		// will be skipped.
+		DuesId int64 `json:"dues_id"`
+	}
+	QueryDuesScheduleRes struct {
+		Day      int                   `json:"day"`
+		Upcoming []UpcomingScheduleOut `json:"upcoming"`
+		Runs     []ScheduleRunOut      `json:"runs"`
+	}
+	QueryDuesScheduleOut struct {
+		resp.Response
+		Res QueryDuesScheduleRes
+	}
+)
+
+// QueryDuesSchedule list the upcoming runs and the latest past runs of the
+// dues schedule.
+func (d *DuesDeps) QueryDuesSchedule(ctx context.Context, now time.Time) (out QueryDuesScheduleOut) {
+	out.Response = resp.NewResponse(http.StatusOK, "", nil)
+
+	runs, err := d.ScheduleRepository.QueryRun(ctx, scheduleRunLimit)
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "query schedule run"))
+		return
+	}
+
+	outRuns := make([]ScheduleRunOut, len(runs))
+	for i, r := range runs {
+		outRuns[i] = toScheduleRunOut(r)
+	}
+
+	outUpcoming := []UpcomingScheduleOut{}
+	if d.ScheduleDay > 0 {
+		latest, err := d.DuesRepository.Latest(ctx)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "latest dues"))
+			return
+		}
+
+		for i := 0; len(outUpcoming) < scheduleUpcomingLen; i++ {
+			runDate := time.Date(now.Year(), now.Month()+time.Month(i), d.ScheduleDay, 0, 0, 0, 0, now.Location())
+			duesDate := scheduleDuesDate(runDate)
+
+			dues, err := d.DuesRepository.FindOtherByYYYYMM(ctx, 0, duesDate)
+			if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+				out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "find dues by yyyy mm"))
+				return
+			}
+
+			// This month run is done once its dues exist.
+			if dues.Id != 0 && !now.Before(runDate) {
+				continue
+			}
+
+			outUpcoming = append(outUpcoming, UpcomingScheduleOut{
+				RunDate:   runDate.Format("2006-01-02"),
+				DuesDate:  duesDate.Format("2006-01"),
+				IdrAmount: latest.IdrAmount.String(),
+				DuesId:    int64(dues.Id),
+			})
+		}
+	}
+
+	out.Res = QueryDuesScheduleRes{
+		Day:      d.ScheduleDay,
+		Upcoming: outUpcoming,
+		Runs:     outRuns,
+	}
+
+	return
+}
@@ -0,0 +1,162 @@
+package dues_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/dues"
+)
+
+func TestRunDuesSchedule(t *testing.T) {
+	err := ClearTables(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	uid, _, err := createMemberNDues(duesDeps, memberSeed, duesSeed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		Name               string
+		ExpectedStatusCode int
+		Now                time.Time
+		ExpectedIsRun      bool
+		ExpectedStatus     dues.ScheduleStatus
+	}{
+		{
+			Name:               "Run Dues Schedule, Not Due Yet",
+			ExpectedStatusCode: http.StatusOK,
+			Now:                time.Date(2030, 5, scheduleDay-1, 10, 0, 0, 0, time.Local),
+		},
+		{
+			Name:               "Run Dues Schedule Success, Dues Created",
+			ExpectedStatusCode: http.StatusOK,
+			Now:                time.Date(2030, 5, scheduleDay, 10, 0, 0, 0, time.Local),
+			ExpectedIsRun:      true,
+			ExpectedStatus:     dues.ScheduleCreated,
+		},
+		{
+			Name:               "Run Dues Schedule Success, Already Created",
+			ExpectedStatusCode: http.StatusOK,
+			Now:                time.Date(2030, 5, scheduleDay+1, 10, 0, 0, 0, time.Local),
+			ExpectedIsRun:      true,
+			ExpectedStatus:     dues.ScheduleSkipped,
+		},
+	}
+
+	var duesId int64
+	for _, c := range testCases {
+		t.Run(c.Name, func(t *testing.T) {
+			res := duesDeps.RunDuesSchedule(context.Background(), c.Now)
+			if res.StatusCode != c.ExpectedStatusCode {
+				t.Logf("%#v", res)
+				t.Fatalf("Expected response code %d. Got %d\n", c.ExpectedStatusCode, res.StatusCode)
+			}
+
+			if res.Res.IsRun != c.ExpectedIsRun {
+				t.Fatalf("Expected is run %t. Got %t\n", c.ExpectedIsRun, res.Res.IsRun)
+			}
+
+			if !c.ExpectedIsRun {
+				return
+			}
+
+			if res.Res.Run.Status != string(c.ExpectedStatus) {
+				t.Fatalf("Expected status %s. Got %s\n", c.ExpectedStatus, res.Res.Run.Status)
+			}
+
+			if res.Res.Run.DuesDate != "2030-06" {
+				t.Fatalf("Expected dues date 2030-06. Got %s\n", res.Res.Run.DuesDate)
+			}
+
+			if duesId == 0 {
+				duesId = res.Res.Run.DuesId
+			}
+			if res.Res.Run.DuesId != duesId {
+				t.Fatalf("Expected dues %d. Got %d\n", duesId, res.Res.Run.DuesId)
+			}
+		})
+	}
+
+	memberDues, err := memberDuesRepository.FindByDuesIdAndMemberId(context.Background(), uint64(duesId), uid)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if memberDues.IdrAmount != duesSeed.IdrAmount {
+		t.Fatalf("Expected amount %s. Got %s\n", duesSeed.IdrAmount, memberDues.IdrAmount)
+	}
+}
+
+func TestRunDuesScheduleNoPreviousDues(t *testing.T) {
+	err := ClearTables(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2030, 5, scheduleDay, 10, 0, 0, 0, time.Local)
+	for i := 0; i < 2; i++ {
+		res := duesDeps.RunDuesSchedule(context.Background(), now)
+		if res.StatusCode != http.StatusInternalServerError {
+			t.Logf("%#v", res)
+			t.Fatalf("Expected response code %d. Got %d\n", http.StatusInternalServerError, res.StatusCode)
+		}
+
+		if res.Res.Run.Status != string(dues.ScheduleFailed) {
+			t.Fatalf("Expected status %s. Got %s\n", dues.ScheduleFailed, res.Res.Run.Status)
+		}
+	}
+
+	res := duesDeps.QueryDuesSchedule(context.Background(), now)
+	if res.Error != nil {
+		t.Fatal(res.Error)
+	}
+
+	// Repeated failure of the same month is kept as one run.
+	if len(res.Res.Runs) != 1 {
+		t.Fatalf("Expected 1 run. Got %d\n", len(res.Res.Runs))
+	}
+}
+
+func TestQueryDuesSchedule(t *testing.T) {
+	err := ClearTables(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, err = createMemberNDues(duesDeps, memberSeed, duesSeed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2030, 5, scheduleDay, 10, 0, 0, 0, time.Local)
+	if res := duesDeps.RunDuesSchedule(context.Background(), now); res.Error != nil {
+		t.Fatal(res.Error)
+	}
+
+	res := duesDeps.QueryDuesSchedule(context.Background(), now.Add(time.Hour))
+	if res.StatusCode != http.StatusOK {
+		t.Logf("%#v", res)
+		t.Fatalf("Expected response code %d. Got %d\n", http.StatusOK, res.StatusCode)
+	}
+
+	if len(res.Res.Runs) != 1 {
+		t.Fatalf("Expected 1 run. Got %d\n", len(res.Res.Runs))
+	}
+
+	// The run of this month is done, so the next one generate the dues of
+	// July.
+	expectedDuesDates := []string{"2030-07", "2030-08", "2030-09"}
+	if len(res.Res.Upcoming) != len(expectedDuesDates) {
+		t.Fatalf("Expected %d upcoming run. Got %d\n", len(expectedDuesDates), len(res.Res.Upcoming))
+	}
+	for i, u := range res.Res.Upcoming {
+		if u.DuesDate != expectedDuesDates[i] {
+			t.Fatalf("Expected upcoming dues date %s. Got %s\n", expectedDuesDates[i], u.DuesDate)
+		}
+	}
+}
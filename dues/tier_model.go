@@ -0,0 +1,34 @@
+package dues
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/money"
+)
+
+// TierModel is the rate of the dues charged to the member assigned to the
+// tier, instead of the dues amount. Tier with zero amount exempt the member
+// from the dues.
+type TierModel struct {
+	Id        uint64
+	Name      string
+	IdrAmount money.IDR
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt sql.NullTime
+}
+
+type TierViewModel struct {
+	Id          uint64
+	Name        string
+	IdrAmount   money.IDR
+	MemberTotal int64
+}
+
+type MemberTierViewModel struct {
+	MemberId string
+	Name     string
+	TierId   uint64
+	TierName string
+}
@@ -0,0 +1,354 @@
+package dues
+
+import (
+	"context"
+	"time"
+
+	arbitary "github.com/PA-D3RPLA/d3if43-htt-uhomestay/arbitrary"
+
+	"github.com/georgysavva/scany/pgxscan"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+type TierRepository struct {
+	PostgreDb *pgxpool.Pool
+}
+
+func NewTierRepository(postgreDb *pgxpool.Pool) *TierRepository {
+	return &TierRepository{
+		PostgreDb: postgreDb,
+	}
+}
+
+type (
+	TierExecutor   func(ctx context.Context, sql string, arguments ...interface{}) (commandTag pgconn.CommandTag, err error)
+	TierQuerierRow func(ctx context.Context, sql string, args ...interface{}) pgx.Row
+	TierQuerier    func(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+)
+
+func (r *TierRepository) Save(ctx context.Context, m TierModel) (nm TierModel, err error) {
+	sqlQuery := `
+		INSERT INTO dues_tiers (
+			name,
+			idr_amount,
+			created_at,
+			updated_at,
+			deleted_at
+		)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`
+
+	var queryRow TierQuerierRow
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		queryRow = tx.QueryRow
+	} else {
+		queryRow = r.PostgreDb.QueryRow
+	}
+
+	var lastInsertId uint64
+	t := time.Now()
+
+	err = queryRow(
+		context.Background(),
+		sqlQuery,
+		m.Name,
+		m.IdrAmount,
+		t,
+		t,
+		nil,
+	).Scan(&lastInsertId)
+
+	if err != nil {
+		return TierModel{}, err
+	}
+
+	m.Id = lastInsertId
+	m.CreatedAt = t
+	m.UpdatedAt = t
+
+	return m, nil
+}
+
+func (r *TierRepository) UpdateById(ctx context.Context, id uint64, m TierModel) error {
+	sqlQuery := `
+		UPDATE dues_tiers SET (
+			name,
+			idr_amount,
+			updated_at
+		) = ($1, $2, $3)
+		WHERE id = $4
+	`
+
+	var exec TierExecutor
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		exec = tx.Exec
+	} else {
+		exec = r.PostgreDb.Exec
+	}
+
+	_, err := exec(
+		context.Background(),
+		sqlQuery,
+		m.Name,
+		m.IdrAmount,
+		time.Now(),
+		id,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *TierRepository) FindById(ctx context.Context, id uint64) (m TierModel, err error) {
+	querystr := `
+		SELECT
+			id,
+			name,
+			idr_amount,
+			created_at,
+			updated_at,
+			deleted_at
+		FROM dues_tiers
+		WHERE deleted_at IS NULL
+			AND id = $1
+	`
+
+	var query TierQuerier
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		query = tx.Query
+	} else {
+		query = r.PostgreDb.Query
+	}
+
+	rows, err := query(
+		context.Background(),
+		querystr,
+		id,
+	)
+	if err != nil {
+		return TierModel{}, err
+	}
+
+	if err = pgxscan.ScanOne(&m, rows); err != nil {
+		return TierModel{}, err
+	}
+
+	return m, nil
+}
+
+// DeleteById remove the tier and unassign its member, the member is charged
+// the dues amount for the next dues.
+func (r *TierRepository) DeleteById(ctx context.Context, id uint64) error {
+	sqlQuery := `
+		UPDATE dues_tiers
+		SET deleted_at = $1
+		WHERE id = $2
+	`
+
+	var exec TierExecutor
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		exec = tx.Exec
+	} else {
+		exec = r.PostgreDb.Exec
+	}
+
+	_, err := exec(
+		context.Background(),
+		sqlQuery,
+		time.Now(),
+		id,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *TierRepository) Query(ctx context.Context) ([]TierViewModel, error) {
+	sqlQuery := `
+		SELECT
+			t.id,
+			t.name,
+			t.idr_amount,
+			COUNT(m.id) AS member_total
+		FROM dues_tiers t
+			LEFT JOIN member_dues_tiers mt ON mt.dues_tier_id = t.id
+			LEFT JOIN members m ON m.id = mt.member_id
+				AND m.deleted_at IS NULL
+		WHERE t.deleted_at IS NULL
+		GROUP BY t.id
+		ORDER BY t.idr_amount DESC, t.id ASC
+	`
+
+	var query TierQuerier
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		query = tx.Query
+	} else {
+		query = r.PostgreDb.Query
+	}
+
+	rows, err := query(context.Background(), sqlQuery)
+	if err != nil {
+		return []TierViewModel{}, err
+	}
+	defer rows.Close()
+
+	var mps []*TierViewModel
+	if err := pgxscan.ScanAll(&mps, rows); err != nil {
+		return []TierViewModel{}, err
+	}
+
+	ms := make([]TierViewModel, len(mps))
+	for i, m := range mps {
+		ms[i] = *m
+	}
+
+	return ms, nil
+}
+
+// SaveMemberTier assign the member to the tier, replacing the previous tier of
+// the member.
+func (r *TierRepository) SaveMemberTier(ctx context.Context, uid string, tierId uint64) error {
+	sqlQuery := `
+		INSERT INTO member_dues_tiers (
+			member_id,
+			dues_tier_id,
+			created_at,
+			updated_at
+		)
+		VALUES ($1, $2, $3, $3)
+		ON CONFLICT (member_id) DO UPDATE
+		SET dues_tier_id = EXCLUDED.dues_tier_id, updated_at = EXCLUDED.updated_at
+	`
+
+	var exec TierExecutor
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		exec = tx.Exec
+	} else {
+		exec = r.PostgreDb.Exec
+	}
+
+	_, err := exec(
+		context.Background(),
+		sqlQuery,
+		uid,
+		tierId,
+		time.Now(),
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteMemberTierByTierId unassign every member of the tier, the member is
+// charged the dues amount for the next dues.
+func (r *TierRepository) DeleteMemberTierByTierId(ctx context.Context, tierId uint64) error {
+	sqlQuery := `
+		DELETE FROM member_dues_tiers
+		WHERE dues_tier_id = $1
+	`
+
+	var exec TierExecutor
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		exec = tx.Exec
+	} else {
+		exec = r.PostgreDb.Exec
+	}
+
+	_, err := exec(
+		context.Background(),
+		sqlQuery,
+		tierId,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *TierRepository) DeleteMemberTier(ctx context.Context, uid string) error {
+	sqlQuery := `
+		DELETE FROM member_dues_tiers
+		WHERE member_id = $1
+	`
+
+	var exec TierExecutor
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		exec = tx.Exec
+	} else {
+		exec = r.PostgreDb.Exec
+	}
+
+	_, err := exec(
+		context.Background(),
+		sqlQuery,
+		uid,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// QueryMemberTier return every approved member with its tier, member without
+// tier has zero tier id.
+func (r *TierRepository) QueryMemberTier(ctx context.Context) ([]MemberTierViewModel, error) {
+	sqlQuery := `
+		SELECT
+			m.id AS member_id,
+			m.name,
+			COALESCE(t.id, 0) AS tier_id,
+			COALESCE(t.name, '') AS tier_name
+		FROM members m
+			LEFT JOIN member_dues_tiers mt ON mt.member_id = m.id
+			LEFT JOIN dues_tiers t ON t.id = mt.dues_tier_id
+				AND t.deleted_at IS NULL
+		WHERE m.deleted_at IS NULL
+			AND m.is_approved = true
+		ORDER BY m.name ASC
+	`
+
+	var query TierQuerier
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		query = tx.Query
+	} else {
+		query = r.PostgreDb.Query
+	}
+
+	rows, err := query(context.Background(), sqlQuery)
+	if err != nil {
+		return []MemberTierViewModel{}, err
+	}
+	defer rows.Close()
+
+	var mps []*MemberTierViewModel
+	if err := pgxscan.ScanAll(&mps, rows); err != nil {
+		return []MemberTierViewModel{}, err
+	}
+
+	ms := make([]MemberTierViewModel, len(mps))
+	for i, m := range mps {
+		ms[i] = *m
+	}
+
+	return ms, nil
+}
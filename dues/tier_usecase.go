@@ -0,0 +1,355 @@
+package dues
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/audit"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/money"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/resp"
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+)
+
+var ErrTierNotFound = errors.New("golongan iuran tidak ditemukan")
+
+type (
+	AddTierIn struct {
+		Name      string `json:"name"`
+		IdrAmount string `json:"idr_amount"`
+	}
+	AddTierRes struct {
+		Id uint64 `json:"id"`
+	}
+	AddTierOut struct {
+		resp.Response
+		Res AddTierRes
+	}
+)
+
+func (d *DuesDeps) AddTier(ctx context.Context, in AddTierIn) (out AddTierOut) {
+	var err error
+	out.Response = resp.NewResponse(http.StatusCreated, "", nil)
+
+	if err = ValidateAddTierIn(in); err != nil {
+		out.Response = resp.NewResponse(http.StatusUnprocessableEntity, "", err)
+		return
+	}
+
+	amount, _ := money.ParseIDR(in.IdrAmount)
+
+	tier := TierModel{
+		Name:      in.Name,
+		IdrAmount: amount,
+	}
+	if tier, err = d.TierRepository.Save(ctx, tier); err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "save tier"))
+		return
+	}
+
+	err = d.Audit.Record(ctx, audit.Event{
+		Action:   audit.ActionCreate,
+		Entity:   "tier",
+		EntityId: strconv.FormatUint(tier.Id, 10),
+		After:    tier,
+	})
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "record audit"))
+		return
+	}
+
+	out.Res.Id = tier.Id
+
+	return
+}
+
+type (
+	TierOut struct {
+		Id          uint64 `json:"id"`
+		Name        string `json:"name"`
+		IdrAmount   string `json:"idr_amount"`
+		MemberTotal int64  `json:"member_total"`
+	}
+	QueryTierRes struct {
+		Tiers []TierOut `json:"tiers"`
+	}
+	QueryTierOut struct {
+		resp.Response
+		Res QueryTierRes
+	}
+)
+
+func (d *DuesDeps) QueryTier(ctx context.Context) (out QueryTierOut) {
+	out.Response = resp.NewResponse(http.StatusOK, "", nil)
+
+	tiers, err := d.TierRepository.Query(ctx)
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "query tiers"))
+		return
+	}
+
+	outTiers := make([]TierOut, len(tiers))
+	for i, t := range tiers {
+		outTiers[i] = TierOut{
+			Id:          t.Id,
+			Name:        t.Name,
+			IdrAmount:   t.IdrAmount.String(),
+			MemberTotal: t.MemberTotal,
+		}
+	}
+
+	out.Res.Tiers = outTiers
+
+	return
+}
+
+type (
+	EditTierIn struct {
+		Name      string `json:"name"`
+		IdrAmount string `json:"idr_amount"`
+	}
+	EditTierRes struct {
+		Id uint64 `json:"id"`
+	}
+	EditTierOut struct {
+		resp.Response
+		Res EditTierRes
+	}
+)
+
+// EditTier change the tier, the new amount is only charged on the next dues
+// since the amount is stored on every member dues.
+func (d *DuesDeps) EditTier(ctx context.Context, pid string, in EditTierIn) (out EditTierOut) {
+	var err error
+	out.Response = resp.NewResponse(http.StatusOK, "", nil)
+
+	id, err := strconv.ParseUint(pid, 10, 64)
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusNotFound, "", ErrTierNotFound)
+		return
+	}
+
+	if err = ValidateEditTierIn(in); err != nil {
+		out.Response = resp.NewResponse(http.StatusUnprocessableEntity, "", err)
+		return
+	}
+
+	tier, err := d.TierRepository.FindById(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		out.Response = resp.NewResponse(http.StatusNotFound, "", ErrTierNotFound)
+		return
+	}
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "find tier by id"))
+		return
+	}
+
+	amount, _ := money.ParseIDR(in.IdrAmount)
+
+	before := tier
+	tier.Name = in.Name
+	tier.IdrAmount = amount
+
+	if err = d.TierRepository.UpdateById(ctx, id, tier); err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "update tier by id"))
+		return
+	}
+
+	err = d.Audit.Record(ctx, audit.Event{
+		Action:   audit.ActionUpdate,
+		Entity:   "tier",
+		EntityId: strconv.FormatUint(id, 10),
+		Before:   before,
+		After:    tier,
+	})
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "record audit"))
+		return
+	}
+
+	out.Res.Id = id
+
+	return
+}
+
+type (
+	RemoveTierRes struct {
+		Id uint64 `json:"id"`
+	}
+	RemoveTierOut struct {
+		resp.Response
+		Res RemoveTierRes
+	}
+)
+
+// RemoveTier remove the tier and unassign its member, the member is charged
+// the dues amount starting from the next dues.
+func (d *DuesDeps) RemoveTier(ctx context.Context, pid string) (out RemoveTierOut) {
+	var err error
+	out.Response = resp.NewResponse(http.StatusOK, "", nil)
+
+	id, err := strconv.ParseUint(pid, 10, 64)
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusNotFound, "", ErrTierNotFound)
+		return
+	}
+
+	tier, err := d.TierRepository.FindById(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		out.Response = resp.NewResponse(http.StatusNotFound, "", ErrTierNotFound)
+		return
+	}
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "find tier by id"))
+		return
+	}
+
+	if err = d.TierRepository.DeleteById(ctx, id); err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "delete tier by id"))
+		return
+	}
+
+	err = d.Audit.Record(ctx, audit.Event{
+		Action:   audit.ActionDelete,
+		Entity:   "tier",
+		EntityId: strconv.FormatUint(id, 10),
+		Before:   tier,
+	})
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "record audit"))
+		return
+	}
+
+	if err = d.TierRepository.DeleteMemberTierByTierId(ctx, id); err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "delete member tier by tier id"))
+		return
+	}
+
+	out.Res.Id = id
+
+	return
+}
+
+type (
+	MemberTierOut struct {
+		MemberId string `json:"member_id"`
+		Name     string `json:"name"`
+		TierId   uint64 `json:"tier_id"`
+		TierName string `json:"tier_name"`
+	}
+	QueryMemberTierRes struct {
+		Members []MemberTierOut `json:"members"`
+	}
+	QueryMemberTierOut struct {
+		resp.Response
+		Res QueryMemberTierRes
+	}
+)
+
+func (d *DuesDeps) QueryMemberTier(ctx context.Context) (out QueryMemberTierOut) {
+	out.Response = resp.NewResponse(http.StatusOK, "", nil)
+
+	members, err := d.TierRepository.QueryMemberTier(ctx)
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "query member tier"))
+		return
+	}
+
+	outMembers := make([]MemberTierOut, len(members))
+	for i, m := range members {
+		outMembers[i] = MemberTierOut(m)
+	}
+
+	out.Res.Members = outMembers
+
+	return
+}
+
+type (
+	EditMemberTierIn struct {
+		TierId uint64 `json:"tier_id"`
+	}
+	EditMemberTierRes struct {
+		MemberId string `json:"member_id"`
+	}
+	EditMemberTierOut struct {
+		resp.Response
+		Res EditMemberTierRes
+	}
+)
+
+// EditMemberTier assign the member to the tier, zero tier id unassign the
+// member so it is charged the dues amount. The tier is only charged starting
+// from the next dues.
+func (d *DuesDeps) EditMemberTier(ctx context.Context, uid string, in EditMemberTierIn) (out EditMemberTierOut) {
+	var err error
+	out.Response = resp.NewResponse(http.StatusOK, "", nil)
+
+	if _, err = uuid.FromString(uid); err != nil {
+		out.Response = resp.NewResponse(http.StatusNotFound, "", ErrMemberNotFound)
+		return
+	}
+
+	_, err = d.MemberRepository.FindById(ctx, uid)
+	if errors.Is(err, pgx.ErrNoRows) {
+		out.Response = resp.NewResponse(http.StatusNotFound, "", ErrMemberNotFound)
+		return
+	}
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "find member by id"))
+		return
+	}
+
+	if in.TierId == 0 {
+		if err = d.TierRepository.DeleteMemberTier(ctx, uid); err != nil {
+			out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "delete member tier"))
+			return
+		}
+
+		err = d.Audit.Record(ctx, audit.Event{
+			Action:   audit.ActionUpdate,
+			Entity:   "member_tier",
+			EntityId: uid,
+			After:    EditMemberTierIn{},
+		})
+		if err != nil {
+			out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "record audit"))
+			return
+		}
+
+		out.Res.MemberId = uid
+		return
+	}
+
+	_, err = d.TierRepository.FindById(ctx, in.TierId)
+	if errors.Is(err, pgx.ErrNoRows) {
+		out.Response = resp.NewResponse(http.StatusUnprocessableEntity, "", ErrTierNotFound)
+		return
+	}
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "find tier by id"))
+		return
+	}
+
+	if err = d.TierRepository.SaveMemberTier(ctx, uid, in.TierId); err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "save member tier"))
+		return
+	}
+
+	err = d.Audit.Record(ctx, audit.Event{
+		Action:   audit.ActionUpdate,
+		Entity:   "member_tier",
+		EntityId: uid,
+		After:    in,
+	})
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "record audit"))
+		return
+	}
+
+	out.Res.MemberId = uid
+
+	return
+}
@@ -0,0 +1,299 @@
+package dues_test
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	arbitary "github.com/PA-D3RPLA/d3if43-htt-uhomestay/arbitrary"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/dues"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/money"
+	"github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+)
+
+func TestAddTier(t *testing.T) {
+	err := ClearTables(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		Name               string
+		ExpectedStatusCode int
+		In                 dues.AddTierIn
+	}{
+		{
+			Name:               "Add Tier Success",
+			ExpectedStatusCode: http.StatusCreated,
+			In: dues.AddTierIn{
+				Name:      "Homestay Besar",
+				IdrAmount: "50000",
+			},
+		},
+		{
+			Name:               "Add Tier Exempt Success",
+			ExpectedStatusCode: http.StatusCreated,
+			In: dues.AddTierIn{
+				Name:      "Bebas Iuran",
+				IdrAmount: "0",
+			},
+		},
+		{
+			Name:               "Add Tier Fail, Name Required",
+			ExpectedStatusCode: http.StatusUnprocessableEntity,
+			In: dues.AddTierIn{
+				IdrAmount: "50000",
+			},
+		},
+		{
+			Name:               "Add Tier Fail, Idr Amount Not Number",
+			ExpectedStatusCode: http.StatusUnprocessableEntity,
+			In: dues.AddTierIn{
+				Name:      "Homestay Kecil",
+				IdrAmount: "50.000",
+			},
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.Name, func(t *testing.T) {
+			res := duesDeps.AddTier(context.Background(), c.In)
+			if res.StatusCode != c.ExpectedStatusCode {
+				t.Logf("%#v", res)
+				t.Fatalf("Expected response code %d. Got %d\n", c.ExpectedStatusCode, res.StatusCode)
+			}
+		})
+	}
+}
+
+func TestEditTier(t *testing.T) {
+	err := ClearTables(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tier, err := tierRepository.Save(context.Background(), dues.TierModel{Name: "Homestay Besar", IdrAmount: 50000})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		Name               string
+		ExpectedStatusCode int
+		Id                 string
+		In                 dues.EditTierIn
+	}{
+		{
+			Name:               "Edit Tier Success",
+			ExpectedStatusCode: http.StatusOK,
+			Id:                 strconv.FormatUint(tier.Id, 10),
+			In: dues.EditTierIn{
+				Name:      "Homestay Sedang",
+				IdrAmount: "35000",
+			},
+		},
+		{
+			Name:               "Edit Tier Fail, Not Found",
+			ExpectedStatusCode: http.StatusNotFound,
+			Id:                 "99999",
+			In: dues.EditTierIn{
+				Name:      "Homestay Sedang",
+				IdrAmount: "35000",
+			},
+		},
+		{
+			Name:               "Edit Tier Fail, Name Required",
+			ExpectedStatusCode: http.StatusUnprocessableEntity,
+			Id:                 strconv.FormatUint(tier.Id, 10),
+			In: dues.EditTierIn{
+				IdrAmount: "35000",
+			},
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.Name, func(t *testing.T) {
+			res := duesDeps.EditTier(context.Background(), c.Id, c.In)
+			if res.StatusCode != c.ExpectedStatusCode {
+				t.Logf("%#v", res)
+				t.Fatalf("Expected response code %d. Got %d\n", c.ExpectedStatusCode, res.StatusCode)
+			}
+		})
+	}
+}
+
+func TestRemoveTier(t *testing.T) {
+	err := ClearTables(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tier, err := tierRepository.Save(context.Background(), dues.TierModel{Name: "Homestay Besar", IdrAmount: 50000})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	uid, _, err := createMemberNDues(duesDeps, memberSeed, duesSeed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = tierRepository.SaveMemberTier(context.Background(), uid, tier.Id); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		Name               string
+		ExpectedStatusCode int
+		Id                 string
+	}{
+		{
+			Name:               "Remove Tier Success",
+			ExpectedStatusCode: http.StatusOK,
+			Id:                 strconv.FormatUint(tier.Id, 10),
+		},
+		{
+			Name:               "Remove Tier Fail, Already Removed",
+			ExpectedStatusCode: http.StatusNotFound,
+			Id:                 strconv.FormatUint(tier.Id, 10),
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.Name, func(t *testing.T) {
+			res := duesDeps.RemoveTier(context.Background(), c.Id)
+			if res.StatusCode != c.ExpectedStatusCode {
+				t.Logf("%#v", res)
+				t.Fatalf("Expected response code %d. Got %d\n", c.ExpectedStatusCode, res.StatusCode)
+			}
+		})
+	}
+
+	members, err := tierRepository.QueryMemberTier(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range members {
+		if m.MemberId == uid && m.TierId != 0 {
+			t.Fatalf("Expected member to be unassigned. Got tier %d\n", m.TierId)
+		}
+	}
+}
+
+func TestEditMemberTier(t *testing.T) {
+	err := ClearTables(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tier, err := tierRepository.Save(context.Background(), dues.TierModel{Name: "Homestay Besar", IdrAmount: 50000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	exemptTier, err := tierRepository.Save(context.Background(), dues.TierModel{Name: "Bebas Iuran", IdrAmount: 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	uid, _, err := createMemberNDues(duesDeps, memberSeed, duesSeed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		Name               string
+		ExpectedStatusCode int
+		Uid                string
+		In                 dues.EditMemberTierIn
+		DuesDate           time.Time
+		ExpectedAmount     money.IDR
+		ExpectedNoDues     bool
+	}{
+		{
+			Name:               "Edit Member Tier Success, Charged Tier Amount",
+			ExpectedStatusCode: http.StatusOK,
+			Uid:                uid,
+			In:                 dues.EditMemberTierIn{TierId: tier.Id},
+			DuesDate:           duesSeed2.Date,
+			ExpectedAmount:     50000,
+		},
+		{
+			Name:               "Edit Member Tier Success, Exempt Tier",
+			ExpectedStatusCode: http.StatusOK,
+			Uid:                uid,
+			In:                 dues.EditMemberTierIn{TierId: exemptTier.Id},
+			DuesDate:           duesSeed3.Date,
+			ExpectedNoDues:     true,
+		},
+		{
+			Name:               "Edit Member Tier Success, Unassign",
+			ExpectedStatusCode: http.StatusOK,
+			Uid:                uid,
+			In:                 dues.EditMemberTierIn{TierId: 0},
+			DuesDate:           duesSeed3.Date.Add(time.Hour * 750),
+			ExpectedAmount:     100000,
+		},
+		{
+			Name:               "Edit Member Tier Fail, Tier Not Found",
+			ExpectedStatusCode: http.StatusUnprocessableEntity,
+			Uid:                uid,
+			In:                 dues.EditMemberTierIn{TierId: 99999},
+		},
+		{
+			Name:               "Edit Member Tier Fail, Member Not Found",
+			ExpectedStatusCode: http.StatusNotFound,
+			Uid:                "1ecd5ad4-ba24-6e6c-a4a7-b6ff5b1a3f1e",
+			In:                 dues.EditMemberTierIn{TierId: tier.Id},
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.Name, func(t *testing.T) {
+			res := duesDeps.EditMemberTier(context.Background(), c.Uid, c.In)
+			if res.StatusCode != c.ExpectedStatusCode {
+				t.Logf("%#v", res)
+				t.Fatalf("Expected response code %d. Got %d\n", c.ExpectedStatusCode, res.StatusCode)
+			}
+
+			if c.DuesDate.IsZero() {
+				return
+			}
+
+			tx, err := db.Begin(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ctx := context.WithValue(context.Background(), arbitary.TrxX{}, tx)
+			addRes := duesDeps.AddDues(ctx, dues.AddDuesIn{
+				Date:      c.DuesDate.Format("2006-01-02"),
+				IdrAmount: "100000",
+			})
+			tx.Commit(context.Background())
+			tx.Rollback(context.Background())
+
+			if addRes.StatusCode != http.StatusCreated {
+				t.Logf("%#v", addRes)
+				t.Fatalf("Expected response code %d. Got %d\n", http.StatusCreated, addRes.StatusCode)
+			}
+
+			memberDues, err := memberDuesRepository.FindByDuesIdAndMemberId(context.Background(), uint64(addRes.Res.Id), c.Uid)
+			if c.ExpectedNoDues {
+				if !errors.Is(err, pgx.ErrNoRows) {
+					t.Fatalf("Expected exempt member to have no dues. Got %#v\n", memberDues)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if memberDues.IdrAmount != c.ExpectedAmount {
+				t.Fatalf("Expected amount %s. Got %s\n", c.ExpectedAmount, memberDues.IdrAmount)
+			}
+		})
+	}
+}
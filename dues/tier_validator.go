@@ -0,0 +1,75 @@
+package dues
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/money"
+	"golang.org/x/sync/errgroup"
+)
+
+var (
+	ErrTierNameRequired = errors.New("nama golongan iuran tidak boleh kosong")
+	ErrMaxTierName      = errors.New("nama golongan iuran tidak dapat lebih dari 200 karakter")
+)
+
+func ValidateAddTierIn(i AddTierIn) error {
+	g := new(errgroup.Group)
+
+	g.Go(func() error {
+		if strings.Trim(i.Name, " ") == "" {
+			return ErrTierNameRequired
+		}
+		return nil
+	})
+	g.Go(func() error {
+		if utf8.RuneCountInString(i.Name) > 200 {
+			return ErrMaxTierName
+		}
+		return nil
+	})
+	g.Go(func() error {
+		if strings.Trim(i.IdrAmount, " ") == "" {
+			return ErrIdrAmountRequired
+		}
+
+		_, err := money.ParseIDR(i.IdrAmount)
+		return err
+	})
+
+	if err := g.Wait(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func ValidateEditTierIn(i EditTierIn) error {
+	g := new(errgroup.Group)
+
+	g.Go(func() error {
+		if strings.Trim(i.Name, " ") == "" {
+			return ErrTierNameRequired
+		}
+		return nil
+	})
+	g.Go(func() error {
+		if utf8.RuneCountInString(i.Name) > 200 {
+			return ErrMaxTierName
+		}
+		return nil
+	})
+	g.Go(func() error {
+		if strings.Trim(i.IdrAmount, " ") == "" {
+			return ErrIdrAmountRequired
+		}
+
+		_, err := money.ParseIDR(i.IdrAmount)
+		return err
+	})
+
+	if err := g.Wait(); err != nil {
+		return err
+	}
+	return nil
+}
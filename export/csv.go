@@ -0,0 +1,66 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+)
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func newCsvWriter(w io.Writer, columns []Column) (*csvWriter, error) {
+	cw := &csvWriter{w: csv.NewWriter(w)}
+
+	titles := make([]string, len(columns))
+	for i, c := range columns {
+		titles[i] = c.Title
+	}
+
+	if err := cw.Write(titles); err != nil {
+		return nil, err
+	}
+
+	return cw, nil
+}
+
+func (cw *csvWriter) Write(row []string) error {
+	cells := make([]string, len(row))
+	for i, v := range row {
+		cells[i] = escapeFormula(v)
+	}
+
+	if err := cw.w.Write(cells); err != nil {
+		return err
+	}
+
+	// Flush every row so the row reach the client as soon as possible, the
+	// underlying writer is expected to be buffered.
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+func (cw *csvWriter) Close() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+// escapeFormula prevent the cell from being evaluated as formula by the
+// spreadsheet application, number is kept as is.
+func escapeFormula(v string) string {
+	if v == "" {
+		return v
+	}
+
+	switch v[0] {
+	case '=', '+', '-', '@', '\t', '\r':
+		if _, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return v
+		}
+
+		return "'" + v
+	}
+
+	return v
+}
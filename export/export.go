@@ -0,0 +1,111 @@
+// Package export write tabular report as csv, xlsx or pdf. Every format is
+// written row by row to the underlying writer, so a report of any size can be
+// streamed without keeping the whole report in memory.
+package export
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type Format string
+
+const (
+	CSV  Format = "csv"
+	XLSX Format = "xlsx"
+	PDF  Format = "pdf"
+)
+
+var ErrUnknownFormat = errors.New("format ekspor tidak diketahui, format yang diperbolehkan 'csv', 'xlsx' atau 'pdf'")
+
+// ParseFormat return the format of the given name, csv is used when the name
+// is empty.
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(strings.Trim(s, " "))); f {
+	case "":
+		return CSV, nil
+	case CSV, XLSX, PDF:
+		return f, nil
+	}
+
+	return "", ErrUnknownFormat
+}
+
+func (f Format) ContentType() string {
+	switch f {
+	case XLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	case PDF:
+		return "application/pdf"
+	}
+
+	return "text/csv; charset=utf-8"
+}
+
+// Filename return the name of the exported file, the name is suffixed with
+// the export date.
+func (f Format) Filename(name string, t time.Time) string {
+	return name + "-" + t.Format("20060102") + "." + string(f)
+}
+
+// Letterhead is the association identity printed on top of the report.
+type Letterhead struct {
+	OrgName    string
+	OrgAddress string
+}
+
+type Header struct {
+	Letterhead
+	Title    string
+	Subtitle string
+}
+
+type Column struct {
+	Title string
+	// Width is the relative width of the column, default to 1.
+	Width float64
+	// Numeric column is written as number in xlsx and aligned right in pdf.
+	Numeric bool
+}
+
+type Writer interface {
+	Write(row []string) error
+	// Close write the rest of the report, it does not close the underlying
+	// writer.
+	Close() error
+}
+
+// NewWriter start the report on w, the header is only written to the format
+// that can show it, csv only has the column title.
+func NewWriter(w io.Writer, f Format, h Header, columns []Column) (Writer, error) {
+	switch f {
+	case CSV:
+		return newCsvWriter(w, columns)
+	case XLSX:
+		return newXlsxWriter(w, h, columns)
+	case PDF:
+		return newPdfWriter(w, h, columns)
+	}
+
+	return nil, ErrUnknownFormat
+}
+
+// SetHttpHeader set the response header to download the report as file, it
+// should be called before the report is written.
+func SetHttpHeader(w http.ResponseWriter, f Format, filename string) {
+	w.Header().Set("Content-Type", f.ContentType())
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	w.Header().Set("Cache-Control", "no-store")
+}
+
+func columnWidth(c Column) float64 {
+	if c.Width <= 0 {
+		return 1
+	}
+
+	return c.Width
+}
@@ -0,0 +1,168 @@
+package export_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/export"
+)
+
+var (
+	header = export.Header{
+		Letterhead: export.Letterhead{
+			OrgName:    "Asosiasi Homestay",
+			OrgAddress: "Jl. Contoh No. 1",
+		},
+		Title:    "Buku Kas",
+		Subtitle: "01-01-2022 s/d 31-01-2022",
+	}
+	columns = []export.Column{
+		{Title: "Tanggal"},
+		{Title: "Keterangan", Width: 3},
+		{Title: "Nominal", Numeric: true},
+	}
+	rows = [][]string{
+		{"01-01-2022", "Iuran (Januari)", "150000"},
+		{"02-01-2022", "=SUM(A1:A2)", "-25000"},
+	}
+)
+
+func write(t *testing.T, f export.Format, rows [][]string) []byte {
+	var buf bytes.Buffer
+	w, err := export.NewWriter(&buf, f, header, columns)
+	if err != nil {
+		t.Fatalf("Expected no error. Got %v\n", err)
+	}
+
+	for _, r := range rows {
+		if err := w.Write(r); err != nil {
+			t.Fatalf("Expected no error. Got %v\n", err)
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatalf("Expected no error. Got %v\n", err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestParseFormat(t *testing.T) {
+	testCases := []struct {
+		in       string
+		expected export.Format
+		err      error
+	}{
+		{in: "", expected: export.CSV},
+		{in: "csv", expected: export.CSV},
+		{in: "XLSX", expected: export.XLSX},
+		{in: "pdf", expected: export.PDF},
+		{in: "doc", err: export.ErrUnknownFormat},
+	}
+
+	for _, c := range testCases {
+		f, err := export.ParseFormat(c.in)
+		if err != c.err {
+			t.Fatalf("Expected error %v. Got %v\n", c.err, err)
+		}
+
+		if f != c.expected {
+			t.Fatalf("Expected %s. Got %s\n", c.expected, f)
+		}
+	}
+}
+
+func TestCsvWriter(t *testing.T) {
+	b := write(t, export.CSV, rows)
+
+	expected := "Tanggal,Keterangan,Nominal\n" +
+		"01-01-2022,Iuran (Januari),150000\n" +
+		"02-01-2022,'=SUM(A1:A2),-25000\n"
+	if string(b) != expected {
+		t.Fatalf("Expected %q. Got %q\n", expected, string(b))
+	}
+}
+
+func TestXlsxWriter(t *testing.T) {
+	b := write(t, export.XLSX, rows)
+
+	zr, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		t.Fatalf("Expected valid zip. Got %v\n", err)
+	}
+
+	files := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("Expected no error. Got %v\n", err)
+		}
+
+		c, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(c)
+	}
+
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/styles.xml"} {
+		if _, ok := files[name]; !ok {
+			t.Fatalf("Expected %s in the workbook\n", name)
+		}
+	}
+
+	sheet := files["xl/worksheets/sheet1.xml"]
+	for _, s := range []string{
+		"Asosiasi Homestay",
+		"Iuran (Januari)",
+		"=SUM(A1:A2)",
+		`<c r="C7" s="0"><v>150000</v></c>`,
+		`<c r="C8" s="0"><v>-25000</v></c>`,
+	} {
+		if !strings.Contains(sheet, s) {
+			t.Fatalf("Expected sheet to contain %q. Got %s\n", s, sheet)
+		}
+	}
+}
+
+func TestPdfWriter(t *testing.T) {
+	many := make([][]string, 100)
+	for i := range many {
+		many[i] = rows[i%len(rows)]
+	}
+
+	b := write(t, export.PDF, many)
+	if !bytes.HasPrefix(b, []byte("%PDF-1.4")) || !bytes.HasSuffix(b, []byte("%%EOF\n")) {
+		t.Fatal("Expected pdf header and trailer")
+	}
+
+	for _, s := range []string{"(Asosiasi Homestay)", "(Iuran \\(Januari\\))", "(150.000)", "(-25.000)"} {
+		if !bytes.Contains(b, []byte(s)) {
+			t.Fatalf("Expected pdf to contain %s\n", s)
+		}
+	}
+
+	count := regexp.MustCompile(`/Count (\d+)`).FindSubmatch(b)
+	if count == nil || string(count[1]) == "1" {
+		t.Fatalf("Expected more than one page. Got %s\n", count)
+	}
+
+	// Every offset in the cross reference table should point to its object.
+	start := regexp.MustCompile(`startxref\n(\d+)`).FindSubmatch(b)
+	xref, _ := strconv.Atoi(string(start[1]))
+	if !bytes.HasPrefix(b[xref:], []byte("xref\n")) {
+		t.Fatalf("Expected xref at %d\n", xref)
+	}
+
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(b[xref:], -1)
+	for i, e := range entries {
+		off, _ := strconv.Atoi(string(e[1]))
+		obj := strconv.Itoa(i+1) + " 0 obj"
+		if !bytes.HasPrefix(b[off:], []byte(obj)) {
+			t.Fatalf("Expected %q at %d\n", obj, off)
+		}
+	}
+}
@@ -0,0 +1,304 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The pdf is written using the standard Helvetica font, so no font has to be
+// embedded, with A4 landscape page.
+const (
+	pdfPageWidth  = 842.0
+	pdfPageHeight = 595.0
+	pdfMargin     = 36.0
+	pdfFontSize   = 9.0
+	pdfRowHeight  = 16.0
+	pdfCellPad    = 4.0
+)
+
+// Object number reserved before the page object.
+const (
+	pdfCatalogObj = iota + 1
+	pdfPagesObj
+	pdfFontObj
+	pdfBoldFontObj
+	pdfFirstFreeObj
+)
+
+// helveticaWidths is the glyph width of the printable ascii character in the
+// Helvetica font, per 1000 unit of font size.
+var helveticaWidths = [...]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
+
+type pdfWriter struct {
+	w       *countWriter
+	h       Header
+	columns []Column
+	widths  []float64
+	printed string
+	// offsets of the object indexed by the object number.
+	offsets []int64
+	pages   []int
+	page    bytes.Buffer
+	y       float64
+	err     error
+}
+
+func newPdfWriter(w io.Writer, h Header, columns []Column) (*pdfWriter, error) {
+	pw := &pdfWriter{
+		w:       &countWriter{w: w},
+		h:       h,
+		columns: columns,
+		widths:  make([]float64, len(columns)),
+		printed: time.Now().Format("02-01-2006 15:04"),
+		offsets: make([]int64, pdfFirstFreeObj),
+	}
+
+	total := 0.0
+	for _, c := range columns {
+		total += columnWidth(c)
+	}
+	for i, c := range columns {
+		pw.widths[i] = (pdfPageWidth - 2*pdfMargin) * columnWidth(c) / total
+	}
+
+	pw.printf("%%PDF-1.4\n%%\xe2\xe3\xcf\xd3\n")
+	pw.object(pdfFontObj, "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	pw.object(pdfBoldFontObj, "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	pw.startPage()
+
+	if pw.err != nil {
+		return nil, pw.err
+	}
+
+	return pw, nil
+}
+
+func (pw *pdfWriter) Write(row []string) error {
+	if pw.y-pdfRowHeight < pdfMargin+pdfRowHeight {
+		pw.finishPage()
+		pw.startPage()
+	}
+
+	pw.drawRow(row, "F1")
+	return pw.err
+}
+
+func (pw *pdfWriter) Close() error {
+	pw.finishPage()
+
+	var kids strings.Builder
+	for i, n := range pw.pages {
+		if i > 0 {
+			kids.WriteString(" ")
+		}
+		fmt.Fprintf(&kids, "%d 0 R", n)
+	}
+
+	pw.object(pdfPagesObj, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", kids.String(), len(pw.pages)))
+	pw.object(pdfCatalogObj, fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pdfPagesObj))
+
+	xref := pw.w.n
+	pw.printf("xref\n0 %d\n0000000000 65535 f \n", len(pw.offsets))
+	for _, off := range pw.offsets[1:] {
+		pw.printf("%010d 00000 n \n", off)
+	}
+	pw.printf("trailer\n<< /Size %d /Root %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(pw.offsets), pdfCatalogObj, xref)
+
+	return pw.err
+}
+
+func (pw *pdfWriter) startPage() {
+	pw.page.Reset()
+	y := pdfPageHeight - pdfMargin
+
+	if pw.h.OrgName != "" {
+		y -= 14
+		pw.drawText("F2", 14, pdfMargin, y, pw.h.OrgName)
+	}
+	if pw.h.OrgAddress != "" {
+		y -= 12
+		pw.drawText("F1", pdfFontSize, pdfMargin, y, pw.h.OrgAddress)
+	}
+	if pw.h.OrgName != "" || pw.h.OrgAddress != "" {
+		y -= 8
+		fmt.Fprintf(&pw.page, "1 w %.2f %.2f m %.2f %.2f l S\n", pdfMargin, y, pdfPageWidth-pdfMargin, y)
+	}
+
+	y -= 18
+	pw.drawText("F2", 12, pdfMargin, y, pw.h.Title)
+	if pw.h.Subtitle != "" {
+		y -= 13
+		pw.drawText("F1", pdfFontSize, pdfMargin, y, pw.h.Subtitle)
+	}
+
+	pw.y = y - 8
+	fmt.Fprintf(&pw.page, "0.9 g %.2f %.2f %.2f %.2f re f 0 g\n", pdfMargin, pw.y-pdfRowHeight, pdfPageWidth-2*pdfMargin, pdfRowHeight)
+
+	titles := make([]string, len(pw.columns))
+	for i, c := range pw.columns {
+		titles[i] = c.Title
+	}
+	pw.drawRow(titles, "F2")
+}
+
+func (pw *pdfWriter) finishPage() {
+	footer := fmt.Sprintf("Dicetak %s - Halaman %d", pw.printed, len(pw.pages)+1)
+	pw.drawText("F1", 8, pdfPageWidth-pdfMargin-textWidth(footer, 8), pdfMargin/2, footer)
+
+	content := len(pw.offsets)
+	pw.offsets = append(pw.offsets, 0, 0)
+
+	pw.object(content, fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", pw.page.Len(), pw.page.String()))
+	pw.object(content+1, fmt.Sprintf(
+		"<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %.0f %.0f] /Resources << /Font << /F1 %d 0 R /F2 %d 0 R >> >> /Contents %d 0 R >>",
+		pdfPagesObj, pdfPageWidth, pdfPageHeight, pdfFontObj, pdfBoldFontObj, content,
+	))
+
+	pw.pages = append(pw.pages, content+1)
+}
+
+func (pw *pdfWriter) drawRow(row []string, font string) {
+	y := pw.y - pdfRowHeight
+	base := y + (pdfRowHeight-pdfFontSize)/2 + 1
+
+	x := pdfMargin
+	for i, w := range pw.widths {
+		v := ""
+		if i < len(row) {
+			v = row[i]
+		}
+
+		numeric := pw.columns[i].Numeric && font == "F1"
+		if numeric {
+			v = groupDigits(v)
+		}
+
+		v = fitText(v, w-2*pdfCellPad, pdfFontSize)
+		if numeric {
+			pw.drawText(font, pdfFontSize, x+w-pdfCellPad-textWidth(v, pdfFontSize), base, v)
+		} else {
+			pw.drawText(font, pdfFontSize, x+pdfCellPad, base, v)
+		}
+
+		x += w
+	}
+
+	fmt.Fprintf(&pw.page, "0.5 w 0.6 G %.2f %.2f m %.2f %.2f l S 0 G\n", pdfMargin, y, pdfPageWidth-pdfMargin, y)
+	pw.y = y
+}
+
+func (pw *pdfWriter) drawText(font string, size, x, y float64, s string) {
+	if s == "" {
+		return
+	}
+
+	fmt.Fprintf(&pw.page, "BT /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, y, pdfString(s))
+}
+
+func (pw *pdfWriter) object(n int, body string) {
+	pw.offsets[n] = pw.w.n
+	pw.printf("%d 0 obj\n%s\nendobj\n", n, body)
+}
+
+func (pw *pdfWriter) printf(format string, a ...interface{}) {
+	if pw.err != nil {
+		return
+	}
+
+	_, pw.err = fmt.Fprintf(pw.w, format, a...)
+}
+
+// pdfString return the string encoded as WinAnsi and escaped to be used in
+// the literal string, character outside of latin-1 is replaced with '?'.
+func pdfString(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			sb.WriteByte('\\')
+			sb.WriteByte(byte(r))
+		case r < 0x20:
+			sb.WriteByte(' ')
+		case r < 0x7f || (r >= 0xa0 && r <= 0xff):
+			sb.WriteByte(byte(r))
+		default:
+			sb.WriteByte('?')
+		}
+	}
+
+	return sb.String()
+}
+
+func textWidth(s string, size float64) float64 {
+	w := 0
+	for _, r := range s {
+		if r >= 0x20 && int(r-0x20) < len(helveticaWidths) {
+			w += helveticaWidths[r-0x20]
+		} else {
+			w += 556
+		}
+	}
+
+	return float64(w) * size / 1000
+}
+
+// fitText cut the text with ellipsis so it is no wider than the given width.
+func fitText(s string, width, size float64) string {
+	if textWidth(s, size) <= width {
+		return s
+	}
+
+	rs := []rune(s)
+	for len(rs) > 0 && textWidth(string(rs)+"...", size) > width {
+		rs = rs[:len(rs)-1]
+	}
+
+	return string(rs) + "..."
+}
+
+// groupDigits add thousand separator to integer, the other value is returned
+// as is.
+func groupDigits(v string) string {
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return v
+	}
+
+	sign := ""
+	digits := strconv.FormatInt(n, 10)
+	if n < 0 {
+		sign, digits = "-", digits[1:]
+	}
+
+	var sb strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			sb.WriteByte('.')
+		}
+		sb.WriteRune(d)
+	}
+
+	return sign + sb.String()
+}
@@ -0,0 +1,197 @@
+package export
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	xlsxContentTypes = xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+		`</Types>`
+	xlsxRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`
+	xlsxWorkbookRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+		`</Relationships>`
+	// Style 0 is the default, 1 is bold and 2 is bold with bigger font.
+	xlsxStyles = xml.Header + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+		`<fonts count="3"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="14"/><name val="Calibri"/></font></fonts>` +
+		`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+		`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+		`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+		`<cellXfs count="3"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/><xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/><xf numFmtId="0" fontId="2" fillId="0" borderId="0" xfId="0" applyFont="1"/></cellXfs>` +
+		`</styleSheet>`
+)
+
+const (
+	xlsxStyleDefault = 0
+	xlsxStyleBold    = 1
+	xlsxStyleTitle   = 2
+)
+
+type xlsxWriter struct {
+	zw      *zip.Writer
+	w       *bufio.Writer
+	columns []Column
+	row     int
+}
+
+func newXlsxWriter(w io.Writer, h Header, columns []Column) (*xlsxWriter, error) {
+	zw := zip.NewWriter(w)
+
+	parts := []struct{ name, body string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRels},
+		{"xl/workbook.xml", xlsxWorkbook(h.Title)},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+		{"xl/styles.xml", xlsxStyles},
+	}
+	for _, p := range parts {
+		f, err := zw.Create(p.name)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := io.WriteString(f, p.body); err != nil {
+			return nil, err
+		}
+	}
+
+	// The worksheet is the last entry, so the rows can be written directly to
+	// the zip entry as they come.
+	f, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+
+	xw := &xlsxWriter{
+		zw:      zw,
+		w:       bufio.NewWriter(f),
+		columns: columns,
+	}
+
+	xw.w.WriteString(xml.Header + `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><cols>`)
+	for i, c := range columns {
+		fmt.Fprintf(xw.w, `<col min="%d" max="%d" width="%.2f" customWidth="1"/>`, i+1, i+1, columnWidth(c)*12)
+	}
+	xw.w.WriteString(`</cols><sheetData>`)
+
+	for _, l := range []struct {
+		v     string
+		style int
+	}{
+		{h.OrgName, xlsxStyleTitle},
+		{h.OrgAddress, xlsxStyleDefault},
+		{h.Title, xlsxStyleBold},
+		{h.Subtitle, xlsxStyleDefault},
+	} {
+		if l.v == "" {
+			continue
+		}
+
+		xw.writeRow([]string{l.v}, l.style, false)
+	}
+	if xw.row > 0 {
+		// Leave an empty row between the header and the table.
+		xw.row++
+	}
+
+	titles := make([]string, len(columns))
+	for i, c := range columns {
+		titles[i] = c.Title
+	}
+	xw.writeRow(titles, xlsxStyleBold, false)
+
+	return xw, nil
+}
+
+func (xw *xlsxWriter) Write(row []string) error {
+	xw.writeRow(row, xlsxStyleDefault, true)
+	return nil
+}
+
+func (xw *xlsxWriter) Close() error {
+	xw.w.WriteString(`</sheetData></worksheet>`)
+	if err := xw.w.Flush(); err != nil {
+		return err
+	}
+
+	return xw.zw.Close()
+}
+
+// writeRow write the row to the buffer, the write error is returned by the
+// buffer flush on close.
+func (xw *xlsxWriter) writeRow(row []string, style int, typed bool) {
+	xw.row++
+	fmt.Fprintf(xw.w, `<row r="%d">`, xw.row)
+
+	for i, v := range row {
+		ref := xlsxColumnName(i) + strconv.Itoa(xw.row)
+		if typed && i < len(xw.columns) && xw.columns[i].Numeric {
+			if _, err := strconv.ParseInt(v, 10, 64); err == nil {
+				fmt.Fprintf(xw.w, `<c r="%s" s="%d"><v>%s</v></c>`, ref, style, v)
+				continue
+			}
+		}
+
+		fmt.Fprintf(xw.w, `<c r="%s" s="%d" t="inlineStr"><is><t xml:space="preserve">`, ref, style)
+		xml.EscapeText(xw.w, []byte(v))
+		xw.w.WriteString(`</t></is></c>`)
+	}
+
+	xw.w.WriteString(`</row>`)
+}
+
+func xlsxWorkbook(title string) string {
+	var sb strings.Builder
+	sb.WriteString(xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="`)
+	xml.EscapeText(&sb, []byte(xlsxSheetName(title)))
+	sb.WriteString(`" sheetId="1" r:id="rId1"/></sheets></workbook>`)
+
+	return sb.String()
+}
+
+// xlsxSheetName return sheet name allowed by the spreadsheet application, at
+// most 31 characters without any of []:*?/\.
+func xlsxSheetName(title string) string {
+	name := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return -1
+		}
+
+		return r
+	}, title)
+
+	if rs := []rune(name); len(rs) > 31 {
+		name = string(rs[:31])
+	}
+
+	if strings.Trim(name, " ") == "" {
+		return "Sheet1"
+	}
+
+	return name
+}
+
+// xlsxColumnName return the column name of the zero based index, A to Z then
+// AA and so on.
+func xlsxColumnName(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+
+	return name
+}
@@ -0,0 +1,56 @@
+package filegc
+
+import (
+	"time"
+
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/storage"
+	"github.com/getsentry/sentry-go"
+)
+
+type (
+	ExceptionCapturer func(exception error)
+	MessageCapturer   func(message string)
+)
+
+type FileGcDeps struct {
+	// Prefix of the object key to be collected, so object of other app in the
+	// same storage is never touched.
+	Prefix string
+	// GracePeriod keep the newly uploaded object which is not referenced yet,
+	// e.g. the blog image uploaded before the blog is saved.
+	GracePeriod     time.Duration
+	CaptureMessage  MessageCapturer
+	CaptureExeption ExceptionCapturer
+	Storages        []storage.Storage
+	FileRepository  *FileRepository
+}
+
+func NewDeps(
+	prefix string,
+	gracePeriod time.Duration,
+	captureMessage MessageCapturer,
+	captureExeption ExceptionCapturer,
+	storages []storage.Storage,
+	fileRepository *FileRepository,
+) *FileGcDeps {
+	return &FileGcDeps{
+		Prefix:          prefix,
+		GracePeriod:     gracePeriod,
+		CaptureMessage:  captureMessage,
+		CaptureExeption: captureExeption,
+		Storages:        storages,
+		FileRepository:  fileRepository,
+	}
+}
+
+func CaptureExeption(capture func(exception error) *sentry.EventID) ExceptionCapturer {
+	return func(exception error) {
+		capture(exception)
+	}
+}
+
+func CaptureMessage(capture func(message string) *sentry.EventID) MessageCapturer {
+	return func(message string) {
+		capture(message)
+	}
+}
@@ -0,0 +1,82 @@
+package filegc
+
+import (
+	"context"
+
+	arbitary "github.com/PA-D3RPLA/d3if43-htt-uhomestay/arbitrary"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+type FileRepository struct {
+	PostgreDb *pgxpool.Pool
+}
+
+func NewRepository(postgreDb *pgxpool.Pool) *FileRepository {
+	return &FileRepository{
+		PostgreDb: postgreDb,
+	}
+}
+
+type FileQuerier func(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+
+// QueryReferencedUrl return every file url still referenced by live row,
+// including the image inside the editor content of blog, history and goal and
+// their revisions that can still be restored. Every history and goal row is
+// scanned, not only the latest, since the older one is still kept.
+func (r *FileRepository) QueryReferencedUrl(ctx context.Context) ([]string, error) {
+	sqlQuery := `
+		WITH contents AS (
+			SELECT content::text AS c FROM blogs WHERE deleted_at IS NULL
+			UNION ALL
+			SELECT content::text AS c FROM histories
+			UNION ALL
+			SELECT (vision::text || mission::text) AS c FROM goals
+			UNION ALL
+			SELECT r.content::text AS c FROM revisions r
+			LEFT JOIN blogs b ON r.entity = 'blog' AND b.id = r.entity_id
+			WHERE r.entity <> 'blog' OR b.deleted_at IS NULL
+		)
+		SELECT url FROM images WHERE deleted_at IS NULL AND url <> ''
+		UNION
+		SELECT url FROM documents WHERE deleted_at IS NULL AND url <> ''
+		UNION
+		SELECT prove_file_url FROM cashflows WHERE deleted_at IS NULL AND prove_file_url <> ''
+		UNION
+		SELECT prove_file_url FROM member_dues WHERE deleted_at IS NULL AND prove_file_url <> ''
+		UNION
+		SELECT prove_file_url FROM dues_payments WHERE deleted_at IS NULL AND prove_file_url <> ''
+		UNION
+		SELECT profile_pic_url FROM members WHERE deleted_at IS NULL AND profile_pic_url <> ''
+		UNION
+		SELECT thumbnail_url FROM blogs WHERE deleted_at IS NULL AND thumbnail_url <> ''
+		UNION
+		SELECT (regexp_matches(c, 'https?://[^"\s\\]+', 'g'))[1] FROM contents
+	`
+
+	var query FileQuerier
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		query = tx.Query
+	} else {
+		query = r.PostgreDb.Query
+	}
+
+	rows, err := query(ctx, sqlQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var urls []string
+	for rows.Next() {
+		var u string
+		if err = rows.Scan(&u); err != nil {
+			return nil, err
+		}
+		urls = append(urls, u)
+	}
+
+	return urls, rows.Err()
+}
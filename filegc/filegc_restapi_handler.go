@@ -0,0 +1,15 @@
+package filegc
+
+import (
+	"net/http"
+
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/resp"
+)
+
+func (d *FileGcDeps) GetOrphanFiles(w http.ResponseWriter, r *http.Request) {
+	out := d.CollectOrphan(r.Context(), true)
+	if out.Error != nil {
+		d.CaptureExeption(out.Error)
+	}
+	out.HttpJSON(w, resp.NewHttpBody(out.Res))
+}
@@ -0,0 +1,131 @@
+package filegc
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/resp"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/storage"
+	"github.com/pkg/errors"
+)
+
+type (
+	OrphanFileRes struct {
+		Url       string    `json:"url"`
+		UpdatedAt time.Time `json:"updated_at"`
+	}
+	CollectOrphanRes struct {
+		DryRun        bool            `json:"dry_run"`
+		Scanned       int             `json:"scanned"`
+		Referenced    int             `json:"referenced"`
+		InGracePeriod int             `json:"in_grace_period"`
+		Deleted       int             `json:"deleted"`
+		Orphans       []OrphanFileRes `json:"orphans"`
+	}
+	CollectOrphanOut struct {
+		resp.Response
+		Res CollectOrphanRes
+	}
+)
+
+// CollectOrphan delete every object of the storages which is no longer
+// referenced by any live row and older than the grace period. On dry run the
+// orphan is only reported.
+func (d *FileGcDeps) CollectOrphan(ctx context.Context, dryRun bool) (out CollectOrphanOut) {
+	var err error
+	out.Response = resp.NewResponse(http.StatusOK, "", nil)
+	out.Res.DryRun = dryRun
+	out.Res.Orphans = []OrphanFileRes{}
+
+	// Reference must be queried before listing, so object uploaded in between
+	// is protected by the grace period instead.
+	urls, err := d.FileRepository.QueryReferencedUrl(ctx)
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "query referenced url"))
+		return
+	}
+
+	referenced := make(map[string]bool)
+	for _, u := range urls {
+		for _, s := range d.Storages {
+			if id, err := s.ObjectId(u); err == nil {
+				referenced[id] = true
+			}
+		}
+	}
+
+	before := time.Now().Add(-d.GracePeriod)
+	seen := make(map[string]bool)
+	for _, s := range d.Storages {
+		err = s.List(ctx, d.Prefix, func(obj storage.Object) error {
+			if seen[obj.Id] {
+				return nil
+			}
+			seen[obj.Id] = true
+			out.Res.Scanned++
+
+			if referenced[obj.Id] {
+				out.Res.Referenced++
+				return nil
+			}
+
+			if obj.UpdatedAt.After(before) {
+				out.Res.InGracePeriod++
+				return nil
+			}
+
+			out.Res.Orphans = append(out.Res.Orphans, OrphanFileRes{
+				Url:       obj.Url,
+				UpdatedAt: obj.UpdatedAt,
+			})
+
+			if dryRun {
+				return nil
+			}
+
+			if err := s.Delete(ctx, obj.Url); err != nil && !errors.Is(err, storage.ErrObjectNotFound) {
+				return errors.Wrapf(err, "delete %s", obj.Url)
+			}
+			out.Res.Deleted++
+
+			return nil
+		})
+		if err != nil {
+			out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "list storage object"))
+			return
+		}
+	}
+
+	return
+}
+
+// RunCollector collect the orphan object every interval until the context is
+// done, the result is logged so dry run can be reviewed before enabling it.
+func (d *FileGcDeps) RunCollector(ctx context.Context, interval time.Duration, dryRun bool) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		out := d.CollectOrphan(ctx, dryRun)
+		if out.Error != nil {
+			d.CaptureExeption(out.Error)
+			continue
+		}
+
+		log.Printf("file gc: dry run %t, scanned %d, referenced %d, in grace period %d, orphan %d, deleted %d",
+			out.Res.DryRun, out.Res.Scanned, out.Res.Referenced, out.Res.InGracePeriod, len(out.Res.Orphans), out.Res.Deleted)
+		if dryRun {
+			for _, o := range out.Res.Orphans {
+				log.Printf("file gc: orphan %s, updated at %s", o.Url, o.UpdatedAt.Format(time.RFC3339))
+			}
+		}
+	}
+}
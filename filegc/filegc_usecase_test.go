@@ -0,0 +1,123 @@
+package filegc_test
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/storage"
+)
+
+func TestCollectOrphan(t *testing.T) {
+	if err := ClearTables(db); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	old := time.Now().Add(-2 * time.Hour)
+
+	put := func(s storage.Storage, key string, isOld bool) string {
+		u, err := s.Put(ctx, key, strings.NewReader(key))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if isOld {
+			id, err := s.ObjectId(u)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err = os.Chtimes(filepath.Join(storageDir, filepath.FromSlash(id)), old, old); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		return u
+	}
+
+	liveImage := put(publicStorage, "uhomestay/images-gallery/1-live.jpg", true)
+	deletedImage := put(publicStorage, "uhomestay/images-gallery/2-deleted.jpg", true)
+	contentImage := put(publicStorage, "uhomestay/blog-images/3-content.jpg", true)
+	thumbnail := put(publicStorage, "uhomestay/blog-images/4-thumbnail.jpg", true)
+	replacedProof := put(privateStorage, "uhomestay/cashflows/5-replaced.pdf", true)
+	put(publicStorage, "uhomestay/blog-images-tmp/6-new.jpg", false)
+	historyImage := put(publicStorage, "uhomestay/blog-images/7-history.jpg", true)
+
+	queries := []struct {
+		sql  string
+		args []interface{}
+	}{
+		{`INSERT INTO images (name, url) VALUES ('live', $1)`, []interface{}{liveImage}},
+		{`INSERT INTO images (name, url, deleted_at) VALUES ('deleted', $1, now())`, []interface{}{deletedImage}},
+		{`INSERT INTO blogs (title, thumbnail_url, content) VALUES ('blog', $1, $2::jsonb)`, []interface{}{
+			thumbnail,
+			`{"type":"doc","content":[{"type":"image","attrs":{"src":"` + contentImage + `"}}]}`,
+		}},
+		// The image of the older history is kept though the latest one doesn't
+		// use it.
+		{`INSERT INTO histories (content, created_at) VALUES ($1::jsonb, now() - interval '1 day')`, []interface{}{
+			`{"type":"doc","content":[{"type":"image","attrs":{"src":"` + historyImage + `"}}]}`,
+		}},
+		{`INSERT INTO histories (content) VALUES ('{}'::jsonb)`, nil},
+	}
+	for _, q := range queries {
+		if _, err := db.Exec(ctx, q.sql, q.args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Run("Dry Run Only Report", func(t *testing.T) {
+		out := fileGcDeps.CollectOrphan(ctx, true)
+		if out.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status code %d. Got %d, %v\n", http.StatusOK, out.StatusCode, out.Error)
+		}
+
+		if out.Res.Scanned != 7 || out.Res.Referenced != 4 || out.Res.InGracePeriod != 1 || out.Res.Deleted != 0 {
+			t.Fatalf("Unexpected report %#v\n", out.Res)
+		}
+
+		orphans := []string{}
+		for _, o := range out.Res.Orphans {
+			orphans = append(orphans, o.Url)
+		}
+		sort.Strings(orphans)
+
+		expected := []string{replacedProof, deletedImage}
+		sort.Strings(expected)
+		if strings.Join(orphans, ",") != strings.Join(expected, ",") {
+			t.Fatalf("Expected orphans %v. Got %v\n", expected, orphans)
+		}
+
+		if _, err := os.Stat(filepath.Join(storageDir, "public/uhomestay/images-gallery/2-deleted.jpg")); err != nil {
+			t.Fatalf("Expected dry run to keep the object. Got %v\n", err)
+		}
+	})
+
+	t.Run("Delete Orphan", func(t *testing.T) {
+		out := fileGcDeps.CollectOrphan(ctx, false)
+		if out.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status code %d. Got %d, %v\n", http.StatusOK, out.StatusCode, out.Error)
+		}
+
+		if out.Res.Deleted != 2 {
+			t.Fatalf("Expected 2 object deleted. Got %#v\n", out.Res)
+		}
+
+		for _, p := range []string{"public/uhomestay/images-gallery/2-deleted.jpg", "private/uhomestay/cashflows/5-replaced.pdf"} {
+			if _, err := os.Stat(filepath.Join(storageDir, p)); !os.IsNotExist(err) {
+				t.Fatalf("Expected %s to be deleted. Got %v\n", p, err)
+			}
+		}
+
+		for _, p := range []string{"public/uhomestay/images-gallery/1-live.jpg", "public/uhomestay/blog-images-tmp/6-new.jpg", "public/uhomestay/blog-images/7-history.jpg"} {
+			if _, err := os.Stat(filepath.Join(storageDir, p)); err != nil {
+				t.Fatalf("Expected %s to be kept. Got %v\n", p, err)
+			}
+		}
+	})
+}
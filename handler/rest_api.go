@@ -3,6 +3,7 @@ package handler
 import (
 	"fmt"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
@@ -10,6 +11,9 @@ import (
 
 	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/config"
 	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/dashboard"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/revision"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/storage"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/user"
 	sentryhttp "github.com/getsentry/sentry-go/http"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
@@ -50,9 +54,17 @@ func (p *RestApiConf) RestApiHandler() {
 	sentryHandler := sentryhttp.New(sentryhttp.Options{
 		Repanic: true,
 	})
-	jwtMidd := jwt.NewMiddleware(p.Conf.JwtKey, p.Conf.JwtIssuerUrl, p.Conf.JwtAudiences, &jwt.JwtPrivateClaim{})
-	adminJwtMidd := jwt.NewMiddleware(p.Conf.JwtKey, p.Conf.JwtIssuerUrl, p.Conf.JwtAudiences, &jwt.JwtPrivateAdminClaim{})
+	jwtMidd := jwt.NewMiddleware(p.Conf.JwtKey, p.Conf.JwtIssuerUrl, p.Conf.JwtAudiences, &jwt.JwtPrivateClaim{}, p.DashboardDeps.IsTokenRevoked)
+	optJwtMidd := jwt.NewOptionalMiddleware(p.Conf.JwtKey, p.Conf.JwtIssuerUrl, p.Conf.JwtAudiences, &jwt.JwtPrivateClaim{}, p.DashboardDeps.IsTokenRevoked)
 	trxMidd := mw.NewTrxMiddleware(p.PosgrePool)
+	permMidd := mw.NewPermissionMiddleware(p.DashboardDeps.HasPermission)
+
+	// can require the token of a member having the permission.
+	can := func(permission string) func(next http.Handler) http.Handler {
+		return func(next http.Handler) http.Handler {
+			return jwtMidd(permMidd(permission)(next))
+		}
+	}
 
 	// Basic CORS
 	// for more ideas, see: https://developer.github.com/v3/#cross-origin-resource-sharing
@@ -122,6 +134,10 @@ func (p *RestApiConf) RestApiHandler() {
 	r.With(trxMidd).Post("/api/v1/register", p.DashboardDeps.PostRegisterMember)
 	r.Post("/api/v1/login/members", p.DashboardDeps.PostLoginMember)
 	r.Post("/api/v1/login/admins", p.DashboardDeps.PostLoginAdmin)
+	r.Post("/api/v1/token/refresh", p.DashboardDeps.PostRefreshToken)
+	r.With(jwtMidd).Post("/api/v1/logout", p.DashboardDeps.PostLogout)
+	r.With(trxMidd).Post("/api/v1/password/forgot", p.DashboardDeps.PostForgotPassword)
+	r.With(trxMidd).Post("/api/v1/password/reset", p.DashboardDeps.PostResetPassword)
 
 	if p.Conf.Env == "uat" {
 		r.Patch("/api/v1/get-admin-jwt/{username}", p.DashboardDeps.GetAdminJwt)
@@ -132,75 +148,152 @@ func (p *RestApiConf) RestApiHandler() {
 	r.Get("/api/v1/members", p.DashboardDeps.GetMembers)
 	r.Get("/api/v1/members/{id}", p.DashboardDeps.GetMember)
 	r.With(jwtMidd).Get("/api/v1/profile", p.DashboardDeps.GetProfileMember)
-	r.With(adminJwtMidd).With(trxMidd).Post("/api/v1/members", p.DashboardDeps.PostMember)
+	r.With(jwtMidd).With(trxMidd).Put("/api/v1/profile/password", p.DashboardDeps.PutProfilePassword)
+	r.With(can(user.PermMemberWrite)).With(trxMidd).Post("/api/v1/members", p.DashboardDeps.PostMember)
 	r.With(jwtMidd).With(trxMidd).Put("/api/v1/members", p.DashboardDeps.PutMemberProfile)
-	r.With(adminJwtMidd).With(trxMidd).Put("/api/v1/members/{id}", p.DashboardDeps.PutMember)
-	r.With(adminJwtMidd).With(trxMidd).Delete("/api/v1/members/{id}", p.DashboardDeps.DeleteMember)
-	r.With(adminJwtMidd).With(trxMidd).Patch("/api/v1/members/{id}", p.DashboardDeps.PatchMemberApproval)
+	r.With(can(user.PermMemberWrite)).With(trxMidd).Put("/api/v1/members/{id}", p.DashboardDeps.PutMember)
+	r.With(can(user.PermMemberWrite)).With(trxMidd).Delete("/api/v1/members/{id}", p.DashboardDeps.DeleteMember)
+	r.With(can(user.PermMemberApprove)).With(trxMidd).Patch("/api/v1/members/{id}", p.DashboardDeps.PatchMemberApproval)
+	r.With(can(user.PermMemberWrite)).With(trxMidd).Delete("/api/v1/members/{id}/sessions", p.DashboardDeps.DeleteMemberSessions)
+	r.With(can(user.PermMemberWrite)).Get("/api/v1/password/stats", p.DashboardDeps.GetPasswordHashStats)
+	r.With(can(user.PermRoleManage)).Get("/api/v1/members/{id}/roles", p.DashboardDeps.GetMemberRoles)
+	r.With(can(user.PermRoleManage)).With(trxMidd).Put("/api/v1/members/{id}/roles", p.DashboardDeps.PutMemberRoles)
+
+	r.With(can(user.PermRoleManage)).Get("/api/v1/permissions", p.DashboardDeps.GetPermissions)
+	r.With(can(user.PermRoleManage)).Get("/api/v1/roles", p.DashboardDeps.GetRoles)
+	r.With(can(user.PermRoleManage)).With(trxMidd).Post("/api/v1/roles", p.DashboardDeps.PostRole)
+	r.With(can(user.PermRoleManage)).With(trxMidd).Put("/api/v1/roles/{id}", p.DashboardDeps.PutRole)
+	r.With(can(user.PermRoleManage)).With(trxMidd).Delete("/api/v1/roles/{id}", p.DashboardDeps.DeleteRole)
+
+	r.With(jwtMidd).Get("/api/v1/notifications", p.DashboardDeps.GetNotifications)
+	r.With(jwtMidd).Get("/api/v1/notifications/stream", p.DashboardDeps.GetNotificationStream)
+	r.With(jwtMidd).Patch("/api/v1/notifications", p.DashboardDeps.PatchNotificationsRead)
+	r.With(jwtMidd).Patch("/api/v1/notifications/{id}", p.DashboardDeps.PatchNotificationRead)
 
 	r.Get("/api/v1/periods", p.DashboardDeps.GetPeriods)
 	r.Get("/api/v1/periods/active", p.DashboardDeps.GetActivePeriod)
 	r.Get("/api/v1/periods/{id}/structures", p.DashboardDeps.GetPeriodStructure)
-	r.With(adminJwtMidd).With(trxMidd).Post("/api/v1/periods", p.DashboardDeps.PostPeriod)
-	r.With(adminJwtMidd).Post("/api/v1/periods/goals", p.DashboardDeps.PostGoal)
-	// r.With(adminJwtMidd).With(trxMidd).Put("/api/v1/periods/{id}", p.DashboardDeps.PutPeriod)
-	r.With(adminJwtMidd).With(trxMidd).Delete("/api/v1/periods/{id}", p.DashboardDeps.DeletePeriod)
-	// r.With(adminJwtMidd).With(trxMidd).Patch("/api/v1/periods/{id}/status", p.DashboardDeps.PatchPeriodStatus)
+	r.With(can(user.PermOrgWrite)).With(trxMidd).Post("/api/v1/periods", p.DashboardDeps.PostPeriod)
+	r.With(can(user.PermOrgWrite)).With(trxMidd).Post("/api/v1/periods/goals", p.DashboardDeps.PostGoal)
+	// r.With(can(user.PermOrgWrite)).With(trxMidd).Put("/api/v1/periods/{id}", p.DashboardDeps.PutPeriod)
+	r.With(can(user.PermOrgWrite)).With(trxMidd).Delete("/api/v1/periods/{id}", p.DashboardDeps.DeletePeriod)
+	// r.With(can(user.PermOrgWrite)).With(trxMidd).Patch("/api/v1/periods/{id}/status", p.DashboardDeps.PatchPeriodStatus)
 	r.Get("/api/v1/periods/{id}/goal", p.DashboardDeps.GetOrgPeriodGoal)
+	r.With(can(user.PermOrgWrite)).Get("/api/v1/periods/{id}/goal/revisions", p.DashboardDeps.GetRevisions(revision.Goal))
+	r.With(can(user.PermOrgWrite)).Get("/api/v1/periods/{id}/goal/revisions/diff", p.DashboardDeps.GetRevisionDiff(revision.Goal))
+	r.With(can(user.PermOrgWrite)).Get("/api/v1/periods/{id}/goal/revisions/{rid}", p.DashboardDeps.GetRevision(revision.Goal))
+	r.With(can(user.PermOrgWrite)).With(trxMidd).Post("/api/v1/periods/{id}/goal/revisions/{rid}/restore", p.DashboardDeps.PostRevisionRestore(revision.Goal))
 
 	r.Get("/api/v1/positions", p.DashboardDeps.GetPositions)
 	r.Get("/api/v1/positions/levels", p.DashboardDeps.GetPositionLevels)
-	r.With(adminJwtMidd).Post("/api/v1/positions", p.DashboardDeps.PostPosition)
-	r.With(adminJwtMidd).With(trxMidd).Put("/api/v1/positions/{id}", p.DashboardDeps.PutPositions)
-	r.With(adminJwtMidd).With(trxMidd).Delete("/api/v1/positions/{id}", p.DashboardDeps.DeletePosition)
-
-	r.Get("/api/v1/documents", p.DashboardDeps.GetDocuments)
-	r.With(adminJwtMidd).Post("/api/v1/documents/dir", p.DashboardDeps.PostDirDocument)
-	r.With(adminJwtMidd).Post("/api/v1/documents/file", p.DashboardDeps.PostFileDocument)
-	r.With(adminJwtMidd).With(trxMidd).Put("/api/v1/documents/dir/{id}", p.DashboardDeps.PutDirDocument)
-	r.With(adminJwtMidd).With(trxMidd).Put("/api/v1/documents/file/{id}", p.DashboardDeps.PutFileDocument)
-	r.Get("/api/v1/documents/{id}", p.DashboardDeps.GetDocumentChildren)
-	r.With(adminJwtMidd).With(trxMidd).Delete("/api/v1/documents/{id}", p.DashboardDeps.DeleteDocument)
-
-	r.With(adminJwtMidd).Post("/api/v1/histories", p.DashboardDeps.PostHistory)
+	r.With(can(user.PermOrgWrite)).With(trxMidd).Post("/api/v1/positions", p.DashboardDeps.PostPosition)
+	r.With(can(user.PermOrgWrite)).With(trxMidd).Put("/api/v1/positions/{id}", p.DashboardDeps.PutPositions)
+	r.With(can(user.PermOrgWrite)).With(trxMidd).Delete("/api/v1/positions/{id}", p.DashboardDeps.DeletePosition)
+
+	r.With(optJwtMidd).Get("/api/v1/documents", p.DashboardDeps.GetDocuments)
+	r.With(can(user.PermDocumentWrite)).With(trxMidd).Post("/api/v1/documents/dir", p.DashboardDeps.PostDirDocument)
+	r.With(can(user.PermDocumentWrite)).With(trxMidd).Post("/api/v1/documents/file", p.DashboardDeps.PostFileDocument)
+	r.With(can(user.PermDocumentWrite)).With(trxMidd).Put("/api/v1/documents/dir/{id}", p.DashboardDeps.PutDirDocument)
+	r.With(can(user.PermDocumentWrite)).With(trxMidd).Put("/api/v1/documents/file/{id}", p.DashboardDeps.PutFileDocument)
+	r.With(optJwtMidd).Get("/api/v1/documents/{id}", p.DashboardDeps.GetDocumentChildren)
+	r.With(can(user.PermDocumentWrite)).With(trxMidd).Delete("/api/v1/documents/{id}", p.DashboardDeps.DeleteDocument)
+
+	r.With(can(user.PermHistoryWrite)).With(trxMidd).Post("/api/v1/histories", p.DashboardDeps.PostHistory)
 	r.Get("/api/v1/histories", p.DashboardDeps.GetHistory)
+	r.With(can(user.PermHistoryWrite)).Get("/api/v1/histories/revisions", p.DashboardDeps.GetRevisions(revision.History))
+	r.With(can(user.PermHistoryWrite)).Get("/api/v1/histories/revisions/diff", p.DashboardDeps.GetRevisionDiff(revision.History))
+	r.With(can(user.PermHistoryWrite)).Get("/api/v1/histories/revisions/{rid}", p.DashboardDeps.GetRevision(revision.History))
+	r.With(can(user.PermHistoryWrite)).With(trxMidd).Post("/api/v1/histories/revisions/{rid}/restore", p.DashboardDeps.PostRevisionRestore(revision.History))
 
 	r.Get("/api/v1/blogs", p.DashboardDeps.GetBlogs)
 	r.Get("/api/v1/blogs/{id}", p.DashboardDeps.GetBlog)
-	r.With(adminJwtMidd).Post("/api/v1/blogs", p.DashboardDeps.PostBlog)
-	r.With(adminJwtMidd).Put("/api/v1/blogs/{id}", p.DashboardDeps.PutBlogs)
-	r.With(adminJwtMidd).Delete("/api/v1/blogs/{id}", p.DashboardDeps.DeleteBlog)
-	r.With(adminJwtMidd).Post("/api/v1/blogs/image", p.DashboardDeps.PostImage)
+	r.With(can(user.PermBlogPublish)).Get("/api/v1/blogs/preview", p.DashboardDeps.GetBlogsPreview)
+	r.With(can(user.PermBlogPublish)).Get("/api/v1/blogs/{id}/preview", p.DashboardDeps.GetBlogPreview)
+	r.With(can(user.PermBlogPublish)).With(trxMidd).Post("/api/v1/blogs", p.DashboardDeps.PostBlog)
+	r.With(can(user.PermBlogPublish)).With(trxMidd).Put("/api/v1/blogs/{id}", p.DashboardDeps.PutBlogs)
+	r.With(can(user.PermBlogPublish)).With(trxMidd).Delete("/api/v1/blogs/{id}", p.DashboardDeps.DeleteBlog)
+	r.With(can(user.PermBlogPublish)).Post("/api/v1/blogs/image", p.DashboardDeps.PostImage)
+	r.With(can(user.PermBlogPublish)).Get("/api/v1/blogs/{id}/revisions", p.DashboardDeps.GetRevisions(revision.Blog))
+	r.With(can(user.PermBlogPublish)).Get("/api/v1/blogs/{id}/revisions/diff", p.DashboardDeps.GetRevisionDiff(revision.Blog))
+	r.With(can(user.PermBlogPublish)).Get("/api/v1/blogs/{id}/revisions/{rid}", p.DashboardDeps.GetRevision(revision.Blog))
+	r.With(can(user.PermBlogPublish)).With(trxMidd).Post("/api/v1/blogs/{id}/revisions/{rid}/restore", p.DashboardDeps.PostRevisionRestore(revision.Blog))
 
 	r.Get("/api/v1/cashflows", p.DashboardDeps.GetCashflows)
 	r.Get("/api/v1/cashflows/stats", p.DashboardDeps.GetCashflowsStats)
-	r.With(adminJwtMidd).Post("/api/v1/cashflows", p.DashboardDeps.PostCashflow)
-	r.With(adminJwtMidd).Put("/api/v1/cashflows/{id}", p.DashboardDeps.PutCashflow)
-	r.With(adminJwtMidd).Delete("/api/v1/cashflows/{id}", p.DashboardDeps.DeleteCashflow)
-
-	r.With(adminJwtMidd).Put("/api/v1/dues/members/monthly/{id}", p.DashboardDeps.PutMemberDues)
-	r.With(adminJwtMidd).Patch("/api/v1/dues/members/monthly/{id}", p.DashboardDeps.PatchMemberDues)
-	r.With(jwtMidd).Post("/api/v1/dues/members/monthly/{id}", p.DashboardDeps.PostMemberDues)
-	r.Get("/api/v1/dues/members/{id}", p.DashboardDeps.GetMemberDues)
+	r.With(can(user.PermCashflowWrite)).With(trxMidd).Post("/api/v1/cashflows", p.DashboardDeps.PostCashflow)
+	r.With(can(user.PermCashflowWrite)).With(trxMidd).Put("/api/v1/cashflows/{id}", p.DashboardDeps.PutCashflow)
+	r.With(can(user.PermCashflowWrite)).With(trxMidd).Delete("/api/v1/cashflows/{id}", p.DashboardDeps.DeleteCashflow)
+	r.Get("/api/v1/cashflows/report", p.DashboardDeps.GetCashflowReport)
+	r.With(can(user.PermCashflowWrite)).Get("/api/v1/cashflows/export", p.DashboardDeps.GetCashflowExport)
+	r.Get("/api/v1/cashflows/categories", p.DashboardDeps.GetCashflowCategories)
+	r.With(can(user.PermCashflowWrite)).With(trxMidd).Post("/api/v1/cashflows/categories", p.DashboardDeps.PostCashflowCategory)
+	r.With(can(user.PermCashflowWrite)).With(trxMidd).Put("/api/v1/cashflows/categories/{id}", p.DashboardDeps.PutCashflowCategory)
+	r.With(can(user.PermCashflowWrite)).With(trxMidd).Delete("/api/v1/cashflows/categories/{id}", p.DashboardDeps.DeleteCashflowCategory)
+	r.Get("/api/v1/cashflows/accounts", p.DashboardDeps.GetCashflowAccounts)
+	r.With(can(user.PermCashflowWrite)).With(trxMidd).Post("/api/v1/cashflows/accounts", p.DashboardDeps.PostCashflowAccount)
+	r.With(can(user.PermCashflowWrite)).With(trxMidd).Put("/api/v1/cashflows/accounts/{id}", p.DashboardDeps.PutCashflowAccount)
+	r.With(can(user.PermCashflowWrite)).With(trxMidd).Delete("/api/v1/cashflows/accounts/{id}", p.DashboardDeps.DeleteCashflowAccount)
+	r.Get("/api/v1/cashflows/transfers", p.DashboardDeps.GetCashflowTransfers)
+	r.With(can(user.PermCashflowWrite)).With(trxMidd).Post("/api/v1/cashflows/transfers", p.DashboardDeps.PostCashflowTransfer)
+	r.With(can(user.PermCashflowWrite)).With(trxMidd).Delete("/api/v1/cashflows/transfers/{id}", p.DashboardDeps.DeleteCashflowTransfer)
+
+	r.With(can(user.PermDuesVerify)).With(trxMidd).Put("/api/v1/dues/members/monthly/{id}", p.DashboardDeps.PutMemberDues)
+	r.With(can(user.PermDuesVerify)).With(trxMidd).Patch("/api/v1/dues/members/monthly/{id}", p.DashboardDeps.PatchMemberDues)
+	r.With(jwtMidd).Get("/api/v1/dues/members/monthly/{id}/histories", p.DashboardDeps.GetMemberDuesHistory)
+	r.With(jwtMidd).With(trxMidd).Post("/api/v1/dues/members/monthly/{id}", p.DashboardDeps.PostMemberDues)
+	r.With(optJwtMidd).Get("/api/v1/dues/members/{id}", p.DashboardDeps.GetMemberDues)
+	r.With(jwtMidd).Get("/api/v1/dues/members/{id}/export", p.DashboardDeps.GetMemberDuesExport)
+	r.With(jwtMidd).Get("/api/v1/dues/members/{id}/statement", p.DashboardDeps.GetMemberDuesStatement)
 	r.Get("/api/v1/dues/{id}/members", p.DashboardDeps.GetMembersDues)
+	r.With(can(user.PermDuesVerify)).Get("/api/v1/dues/payments", p.DashboardDeps.GetDuesPayments)
+	r.With(jwtMidd).With(trxMidd).Post("/api/v1/dues/payments", p.DashboardDeps.PostDuesPayment)
+	r.With(can(user.PermDuesVerify)).With(trxMidd).Patch("/api/v1/dues/payments/{id}", p.DashboardDeps.PatchDuesPayment)
+	r.With(can(user.PermDuesWrite)).Get("/api/v1/dues/{id}/members/export", p.DashboardDeps.GetMembersDuesExport)
 
 	r.Get("/api/v1/dues", p.DashboardDeps.GetDues)
-	r.With(adminJwtMidd).Post("/api/v1/dues", p.DashboardDeps.PostDues)
+	r.With(can(user.PermDuesWrite)).With(trxMidd).Post("/api/v1/dues", p.DashboardDeps.PostDues)
+	r.With(can(user.PermDuesWrite)).Get("/api/v1/dues/schedule", p.DashboardDeps.GetDuesSchedule)
+	r.With(can(user.PermDuesWrite)).Get("/api/v1/dues/arrears", p.DashboardDeps.GetDuesArrears)
+	r.With(can(user.PermDuesWrite)).Get("/api/v1/dues/reminders", p.DashboardDeps.GetDuesReminders)
 	r.Get("/api/v1/dues/{id}/check", p.DashboardDeps.GetPaidDues)
-	r.With(adminJwtMidd).Put("/api/v1/dues/{id}", p.DashboardDeps.PutDues)
-	r.With(adminJwtMidd).Delete("/api/v1/dues/{id}", p.DashboardDeps.DeleteDues)
+	r.With(can(user.PermDuesWrite)).With(trxMidd).Put("/api/v1/dues/{id}", p.DashboardDeps.PutDues)
+	r.With(can(user.PermDuesWrite)).With(trxMidd).Delete("/api/v1/dues/{id}", p.DashboardDeps.DeleteDues)
+	r.With(can(user.PermDuesWrite)).Get("/api/v1/dues/{id}/overrides", p.DashboardDeps.GetDuesOverrides)
+	r.With(can(user.PermDuesWrite)).With(trxMidd).Put("/api/v1/dues/{id}/overrides/{uid}", p.DashboardDeps.PutDuesOverride)
+	r.With(can(user.PermDuesWrite)).With(trxMidd).Delete("/api/v1/dues/{id}/overrides/{uid}", p.DashboardDeps.DeleteDuesOverride)
+	r.With(can(user.PermDuesWrite)).Get("/api/v1/dues/tiers", p.DashboardDeps.GetDuesTiers)
+	r.With(can(user.PermDuesWrite)).With(trxMidd).Post("/api/v1/dues/tiers", p.DashboardDeps.PostDuesTier)
+	r.With(can(user.PermDuesWrite)).With(trxMidd).Put("/api/v1/dues/tiers/{id}", p.DashboardDeps.PutDuesTier)
+	r.With(can(user.PermDuesWrite)).With(trxMidd).Delete("/api/v1/dues/tiers/{id}", p.DashboardDeps.DeleteDuesTier)
+	r.With(can(user.PermDuesWrite)).Get("/api/v1/dues/tiers/members", p.DashboardDeps.GetMemberDuesTiers)
+	r.With(can(user.PermDuesWrite)).With(trxMidd).Put("/api/v1/dues/members/{id}/tier", p.DashboardDeps.PutMemberDuesTier)
 
 	r.Get("/api/v1/dashboard", p.DashboardDeps.GetPublicDashboard)
-	r.With(adminJwtMidd).Get("/api/v1/dashboard/private", p.DashboardDeps.GetPrivateDashboard)
+	r.With(can(user.PermDashboardRead)).Get("/api/v1/dashboard/private", p.DashboardDeps.GetPrivateDashboard)
+
+	r.With(can(user.PermAuditRead)).Get("/api/v1/audit", p.DashboardDeps.GetAudit)
+	r.With(can(user.PermAuditRead)).Get("/api/v1/audit/verify", p.DashboardDeps.GetAuditVerify)
+
+	r.With(can(user.PermFileManage)).Get("/api/v1/files/orphans", p.DashboardDeps.GetOrphanFiles)
+	r.With(optJwtMidd).Get("/api/v1/files/{kind}/{id}", p.DashboardDeps.GetFile)
 
 	r.Get("/api/v1/images", p.DashboardDeps.GetImages)
-	r.With(adminJwtMidd).Post("/api/v1/images", p.DashboardDeps.PostGalleryImage)
-	r.With(adminJwtMidd).Delete("/api/v1/images/{id}", p.DashboardDeps.DeleteImage)
+	r.With(can(user.PermGalleryWrite)).With(trxMidd).Post("/api/v1/images", p.DashboardDeps.PostGalleryImage)
+	r.With(can(user.PermGalleryWrite)).With(trxMidd).Delete("/api/v1/images/{id}", p.DashboardDeps.DeleteImage)
 
 	workDir, _ := os.Getwd()
 	filesDir := http.Dir(filepath.Join(workDir, "docs"))
 	ChiFileServer(r, "/docs", filesDir)
 
+	// Object of local storage driver is served by the service itself.
+	if p.Conf.StorageDriver == storage.Local {
+		if u, err := url.Parse(p.Conf.StorageBaseUrl); err == nil {
+			prefix := strings.TrimRight(u.Path, "/")
+			localStorage := storage.NewLocal(p.Conf.StorageLocalDir, p.Conf.StorageBaseUrl, p.Conf.StorageSignKey, false)
+			r.Handle(prefix+"/*", localStorage.Handler(prefix))
+		}
+	}
+
 	http.ListenAndServe(fmt.Sprintf(":%s", p.Conf.Port), r)
 }
 
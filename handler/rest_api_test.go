@@ -2,6 +2,7 @@ package handler_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -91,7 +92,7 @@ func TestPrivateJWTRoute(t *testing.T) {
 	jwtIssuerUrl := "http://localhost:8080"
 	jwtAudiences := []string{"test"}
 
-	jwtMidd := jwt.NewMiddleware(jwtKey, jwtIssuerUrl, jwtAudiences, &jwt.JwtPrivateClaim{})
+	jwtMidd := jwt.NewMiddleware(jwtKey, jwtIssuerUrl, jwtAudiences, &jwt.JwtPrivateClaim{}, nil)
 
 	testCases := []struct {
 		name               string
@@ -174,6 +175,25 @@ func TestPrivateJWTRoute(t *testing.T) {
 			},
 			expectedStatusCode: http.StatusUnauthorized,
 		},
+		{
+			name: "Access Private Route Fail, Token Without Expiry",
+			setHeader: func(r *http.Request) {
+				jwtToken, _ := jwt.Sign(
+					"",
+					"token",
+					jwtIssuerUrl,
+					jwtKey,
+					jwtAudiences,
+					time.Time{},
+					time.Time{},
+					time.Time{},
+					jwt.JwtPrivateClaim{
+						Uid: "12345678-1234-1234-1234-123456789012",
+					})
+				r.Header.Set("Authorization", "Bearer "+jwtToken)
+			},
+			expectedStatusCode: http.StatusUnauthorized,
+		},
 		{
 			name: "Access Private Route Fail, Not JWT Token",
 			setHeader: func(r *http.Request) {
@@ -227,7 +247,7 @@ func TestPrivateJWTAdminRoute(t *testing.T) {
 	jwtIssuerUrl := "http://localhost:8080"
 	jwtAudiences := []string{"test"}
 
-	jwtMidd := jwt.NewMiddleware(jwtKey, jwtIssuerUrl, jwtAudiences, &jwt.JwtPrivateAdminClaim{})
+	jwtMidd := jwt.NewMiddleware(jwtKey, jwtIssuerUrl, jwtAudiences, &jwt.JwtPrivateAdminClaim{}, nil)
 
 	testCases := []struct {
 		name               string
@@ -403,3 +423,105 @@ func TestSomething(t *testing.T) {
 		t.Fatal("Expected response code")
 	}
 }
+
+func TestPermissionRoute(t *testing.T) {
+	jwtKey := []byte("test")
+	jwtIssuerUrl := "http://localhost:8080"
+	jwtAudiences := []string{"test"}
+
+	jwtMidd := jwt.NewMiddleware(jwtKey, jwtIssuerUrl, jwtAudiences, &jwt.JwtPrivateClaim{}, nil)
+	permMidd := mw.NewPermissionMiddleware(func(ctx context.Context, uid, permission string) (bool, error) {
+		switch uid {
+		case "12345678-1234-1234-1234-123456789012":
+			return permission == "blog:publish", nil
+		case "12345678-1234-1234-1234-123456789013":
+			return false, errors.New("database down")
+		}
+		return false, nil
+	})
+
+	sign := func(uid string) func(r *http.Request) {
+		return func(r *http.Request) {
+			jwtToken, _ := jwt.Sign(
+				"",
+				"token",
+				jwtIssuerUrl,
+				jwtKey,
+				jwtAudiences,
+				time.Time{},
+				time.Now().Add(time.Hour),
+				time.Time{},
+				jwt.JwtPrivateClaim{
+					Uid: uid,
+				})
+			r.Header.Set("Authorization", "Bearer "+jwtToken)
+		}
+	}
+
+	testCases := []struct {
+		name               string
+		path               string
+		setHeader          func(r *http.Request)
+		expectedStatusCode int
+	}{
+		{
+			name:               "Access Permission Route Success",
+			path:               "/blogs",
+			setHeader:          sign("12345678-1234-1234-1234-123456789012"),
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "Access Permission Route Fail, Permission Not Granted",
+			path:               "/cashflows",
+			setHeader:          sign("12345678-1234-1234-1234-123456789012"),
+			expectedStatusCode: http.StatusForbidden,
+		},
+		{
+			name:               "Access Permission Route Fail, Member Without Permission",
+			path:               "/blogs",
+			setHeader:          sign("12345678-1234-1234-1234-123456789014"),
+			expectedStatusCode: http.StatusForbidden,
+		},
+		{
+			name:               "Access Permission Route Fail, Checker Error",
+			path:               "/blogs",
+			setHeader:          sign("12345678-1234-1234-1234-123456789013"),
+			expectedStatusCode: http.StatusInternalServerError,
+		},
+		{
+			name: "Access Permission Route Fail, Authorization Header not Provided",
+			path: "/blogs",
+			setHeader: func(r *http.Request) {
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+		},
+	}
+
+	hi := func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hi"))
+	}
+
+	r := chi.NewRouter()
+	r.With(jwtMidd, permMidd("blog:publish")).Get("/blogs", hi)
+	r.With(jwtMidd, permMidd("cashflow:write")).Get("/cashflows", hi)
+
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", c.path, nil)
+			c.setHeader(req)
+
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			body, err := io.ReadAll(rr.Body)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			if rr.Code != c.expectedStatusCode {
+				t.Logf("%s", body)
+				t.Fatalf("Expected response code %d. Got %d\n", c.expectedStatusCode, rr.Code)
+			}
+		})
+	}
+}
@@ -1,6 +1,10 @@
 package history
 
-import "github.com/getsentry/sentry-go"
+import (
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/audit"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/revision"
+	"github.com/getsentry/sentry-go"
+)
 
 type (
 	ExceptionCapturer func(exception error)
@@ -10,17 +14,23 @@ type (
 type HistoryDeps struct {
 	CaptureMessage    MessageCapturer
 	CaptureExeption   ExceptionCapturer
+	Audit             audit.Recorder
+	Revision          revision.Recorder
 	HistoryRepository *HistoryRepository
 }
 
 func NewDeps(
 	captureMessage MessageCapturer,
 	captureExeption ExceptionCapturer,
+	auditRecorder audit.Recorder,
+	revisionRecorder revision.Recorder,
 	historyRepository *HistoryRepository,
 ) *HistoryDeps {
 	return &HistoryDeps{
 		CaptureMessage:    captureMessage,
 		CaptureExeption:   captureExeption,
+		Audit:             auditRecorder,
+		Revision:          revisionRecorder,
 		HistoryRepository: historyRepository,
 	}
 }
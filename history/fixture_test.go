@@ -71,6 +71,7 @@ func ClearTables(conn *pgxpool.Pool) error {
 	// This should be in order of which table truncate first before the other
 	queries := []string{
 		`TRUNCATE histories CASCADE`,
+		`TRUNCATE revisions`,
 	}
 
 	for _, v := range queries {
@@ -145,6 +146,8 @@ func TestMain(m *testing.M) {
 	historyDeps = history.NewDeps(
 		captureMessage,
 		captureException,
+		nil,
+		nil,
 		historyRepository,
 	)
 
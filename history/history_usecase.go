@@ -4,12 +4,29 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
+	"strconv"
 
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/audit"
 	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/resp"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/revision"
 	"github.com/jackc/pgx/v4"
 	"github.com/pkg/errors"
 )
 
+// historyContent is the versioned content of the history page kept in its
+// revisions.
+type historyContent struct {
+	Content map[string]interface{} `json:"content"`
+}
+
+func historyRevision(h HistoryModel) revision.Revision {
+	return revision.Revision{
+		Entity:      revision.History,
+		Content:     historyContent{Content: h.Content},
+		ContentText: h.ContentText,
+	}
+}
+
 type (
 	AddHistoryIn struct {
 		Content     string `json:"content"`
@@ -51,6 +68,22 @@ func (d *HistoryDeps) AddHistory(ctx context.Context, in AddHistoryIn) (out AddH
 		return
 	}
 
+	err = d.Audit.Record(ctx, audit.Event{
+		Action:   audit.ActionCreate,
+		Entity:   "history",
+		EntityId: strconv.FormatUint(history.Id, 10),
+		After:    history,
+	})
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "record audit"))
+		return
+	}
+
+	if err = d.Revision.Record(ctx, historyRevision(history)); err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "record revision"))
+		return
+	}
+
 	out.StatusCode = http.StatusCreated
 	out.Res.Id = int64(history.Id)
 
@@ -97,3 +130,31 @@ func (d *HistoryDeps) FindLatestHistory(ctx context.Context) (out FindLatestHist
 
 	return
 }
+
+// RestoreHistory save the content of the revision as the latest history.
+func (d *HistoryDeps) RestoreHistory(ctx context.Context, rev revision.RevisionModel) error {
+	var c historyContent
+	if err := rev.Decode(&c); err != nil {
+		return errors.Wrap(err, "decode revision")
+	}
+
+	history, err := d.HistoryRepository.Save(ctx, HistoryModel{
+		Content:     c.Content,
+		ContentText: rev.ContentText,
+	})
+	if err != nil {
+		return errors.Wrap(err, "save history")
+	}
+
+	err = d.Audit.Record(ctx, audit.Event{
+		Action:   audit.ActionCreate,
+		Entity:   "history",
+		EntityId: strconv.FormatUint(history.Id, 10),
+		After:    history,
+	})
+	if err != nil {
+		return errors.Wrap(err, "record audit")
+	}
+
+	return nil
+}
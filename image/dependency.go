@@ -3,8 +3,10 @@ package image
 import (
 	"context"
 	"io"
+	"path"
 
-	"github.com/cloudinary/cloudinary-go/api/uploader"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/audit"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/storage"
 	"github.com/getsentry/sentry-go"
 )
 
@@ -18,6 +20,7 @@ type ImageDeps struct {
 	CaptureMessage  MessageCapturer
 	CaptureExeption ExceptionCapturer
 	Upload          FileUploader
+	Audit           audit.Recorder
 	ImageRepository *ImageRepository
 }
 
@@ -25,27 +28,21 @@ func NewDeps(
 	captureMessage MessageCapturer,
 	captureExeption ExceptionCapturer,
 	upload FileUploader,
+	auditRecorder audit.Recorder,
 	imageRepository *ImageRepository,
 ) *ImageDeps {
 	return &ImageDeps{
 		CaptureMessage:  captureMessage,
 		CaptureExeption: captureExeption,
 		Upload:          upload,
+		Audit:           auditRecorder,
 		ImageRepository: imageRepository,
 	}
 }
 
-func FileUpload(uploadParams uploader.UploadParams, upload func(ctx context.Context, file interface{}, uploadParams uploader.UploadParams) (*uploader.UploadResult, error)) FileUploader {
+func FileUpload(store storage.Storage, folder string) FileUploader {
 	return func(filename string, file io.Reader) (url string, err error) {
-		ctx := context.Background()
-		uploadParams.PublicID = filename
-
-		resp, err := upload(ctx, file, uploadParams)
-		if err != nil {
-			return "", err
-		}
-
-		return resp.SecureURL, nil
+		return store.Put(context.Background(), path.Join(folder, filename), file)
 	}
 }
 
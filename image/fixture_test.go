@@ -149,6 +149,7 @@ func TestMain(m *testing.M) {
 		captureMessage,
 		captureException,
 		upload,
+		nil,
 		imageRepository,
 	)
 
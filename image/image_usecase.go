@@ -10,6 +10,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/audit"
 	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/filetype"
 	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/httpdecode"
 	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/resp"
@@ -90,6 +91,17 @@ func (d *ImageDeps) AddImage(ctx context.Context, in AddImageIn) (out AddImageOu
 		return
 	}
 
+	err = d.Audit.Record(ctx, audit.Event{
+		Action:   audit.ActionCreate,
+		Entity:   "image",
+		EntityId: strconv.FormatUint(image.Id, 10),
+		After:    image,
+	})
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "record audit"))
+		return
+	}
+
 	out.Res.Id = int64(image.Id)
 
 	return
@@ -181,7 +193,7 @@ func (d *ImageDeps) RemoveImage(ctx context.Context, pid string) (out RemoveImag
 		return
 	}
 
-	_, err = d.ImageRepository.FindById(ctx, id)
+	image, err := d.ImageRepository.FindById(ctx, id)
 	if errors.Is(err, pgx.ErrNoRows) {
 		out.Response = resp.NewResponse(http.StatusNotFound, "", ErrImageNotFound)
 		return
@@ -196,6 +208,17 @@ func (d *ImageDeps) RemoveImage(ctx context.Context, pid string) (out RemoveImag
 		return
 	}
 
+	err = d.Audit.Record(ctx, audit.Event{
+		Action:   audit.ActionDelete,
+		Entity:   "image",
+		EntityId: strconv.FormatUint(id, 10),
+		Before:   image,
+	})
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "record audit"))
+		return
+	}
+
 	out.Res.Id = int64(id)
 
 	return
@@ -6,15 +6,66 @@ import (
 	"errors"
 	"log"
 	"net/http"
+	"reflect"
 	"time"
 
 	jwtmiddleware "github.com/auth0/go-jwt-middleware/v2"
 	"github.com/auth0/go-jwt-middleware/v2/validator"
 	"github.com/go-jose/go-jose/v3"
 	"github.com/go-jose/go-jose/v3/jwt"
+	squarejwt "gopkg.in/square/go-jose.v2/jwt"
+
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/resp"
+)
+
+var (
+	ErrTokenExpired   = errors.New("token sudah kedaluwarsa, silakan perbarui token")
+	ErrTokenNoExpired = errors.New("token tidak memiliki waktu kedaluwarsa")
+	ErrTokenRevoked   = errors.New("token sudah dicabut, silakan login kembali")
 )
 
-func NewMiddleware(jwtKey []byte, jwtIssuerUrl string, jwtAudiences []string, customClaims validator.CustomClaims) func(next http.Handler) http.Handler {
+// RevocationChecker report whether the token with the given id, owned by the
+// given member uid and issued at the given unix time is already revoked.
+type RevocationChecker func(ctx context.Context, jti, uid string, issuedAt int64) (bool, error)
+
+func NewMiddleware(jwtKey []byte, jwtIssuerUrl string, jwtAudiences []string, customClaims validator.CustomClaims, isRevoked RevocationChecker) func(next http.Handler) http.Handler {
+	return newMiddleware(jwtKey, jwtIssuerUrl, jwtAudiences, customClaims, isRevoked)
+}
+
+// NewOptionalMiddleware let request without token pass through without claims,
+// while request with invalid, expired or revoked token is still rejected.
+func NewOptionalMiddleware(jwtKey []byte, jwtIssuerUrl string, jwtAudiences []string, customClaims validator.CustomClaims, isRevoked RevocationChecker) func(next http.Handler) http.Handler {
+	return newMiddleware(
+		jwtKey,
+		jwtIssuerUrl,
+		jwtAudiences,
+		customClaims,
+		isRevoked,
+		jwtmiddleware.WithCredentialsOptional(true),
+		jwtmiddleware.WithTokenExtractor(
+			jwtmiddleware.MultiTokenExtractor(
+				jwtmiddleware.AuthHeaderTokenExtractor,
+				optionalCookieTokenExtractor("jwt"),
+			),
+		),
+	)
+}
+
+// optionalCookieTokenExtractor treat missing cookie as no token instead of
+// an error, so anonymous request is not rejected.
+func optionalCookieTokenExtractor(cookieName string) jwtmiddleware.TokenExtractor {
+	extract := jwtmiddleware.CookieTokenExtractor(cookieName)
+	return func(r *http.Request) (string, error) {
+		token, err := extract(r)
+		if errors.Is(err, http.ErrNoCookie) {
+			return "", nil
+		}
+
+		return token, err
+	}
+}
+
+func newMiddleware(jwtKey []byte, jwtIssuerUrl string, jwtAudiences []string, customClaims validator.CustomClaims, isRevoked RevocationChecker, opts ...jwtmiddleware.Option) func(next http.Handler) http.Handler {
 	keyFunc := func(ctx context.Context) (interface{}, error) {
 		// Our token must be signed using this data.
 		return jwtKey, nil
@@ -27,27 +78,91 @@ func NewMiddleware(jwtKey []byte, jwtIssuerUrl string, jwtAudiences []string, cu
 		jwtIssuerUrl,
 		jwtAudiences,
 		validator.WithCustomClaims(func() validator.CustomClaims {
-			return customClaims
+			// Each token need its own claims, or else concurrent requests
+			// would write to the same claims.
+			return reflect.New(reflect.TypeOf(customClaims).Elem()).Interface().(validator.CustomClaims)
 		}),
 	)
 	if err != nil {
 		log.Fatalf("Fail setup jwt validator: %s", err)
 	}
 
+	// Token issued before access token expiry is introduced never expire,
+	// reject them so the client is forced to login again.
+	validateToken := func(ctx context.Context, token string) (interface{}, error) {
+		claims, err := jwtValidator.ValidateToken(ctx, token)
+		if err != nil {
+			return nil, err
+		}
+
+		validatedClaims := claims.(*validator.ValidatedClaims)
+		if validatedClaims.RegisteredClaims.Expiry == 0 {
+			return nil, ErrTokenNoExpired
+		}
+
+		if isRevoked == nil {
+			return claims, nil
+		}
+
+		var uid string
+		switch c := validatedClaims.CustomClaims.(type) {
+		case *JwtPrivateClaim:
+			uid = c.Uid
+		case *JwtPrivateAdminClaim:
+			uid = c.Uid
+		}
+
+		revoked, err := isRevoked(ctx, validatedClaims.RegisteredClaims.ID, uid, validatedClaims.RegisteredClaims.IssuedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		if revoked {
+			return nil, ErrTokenRevoked
+		}
+
+		return claims, nil
+	}
+
 	// Set up the middleware.
-	jwtMidd := jwtmiddleware.New(
-		jwtValidator.ValidateToken,
+	opts = append([]jwtmiddleware.Option{
+		jwtmiddleware.WithErrorHandler(ErrorHandler),
 		jwtmiddleware.WithTokenExtractor(
 			jwtmiddleware.MultiTokenExtractor(
 				jwtmiddleware.AuthHeaderTokenExtractor,
 				jwtmiddleware.CookieTokenExtractor("jwt"),
 			),
 		),
-	).CheckJWT
+	}, opts...)
+	jwtMidd := jwtmiddleware.New(validateToken, opts...).CheckJWT
 
 	return jwtMidd
 }
 
+// ErrorHandler tell the client to refresh its token when the token is expired,
+// other error is handled the same as the default jwt middleware error handler.
+func ErrorHandler(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, squarejwt.ErrExpired) {
+		w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token", error_description="token expired"`)
+		resp.NewResponse(http.StatusUnauthorized, "", ErrTokenExpired).HttpJSON(w, nil)
+		return
+	}
+
+	if errors.Is(err, ErrTokenRevoked) {
+		resp.NewResponse(http.StatusUnauthorized, "", ErrTokenRevoked).HttpJSON(w, nil)
+		return
+	}
+
+	jwtmiddleware.DefaultErrorHandler(w, r, err)
+}
+
+// HasClaims report whether the request carry a valid token, useful for route
+// using the optional middleware.
+func HasClaims(r *http.Request) bool {
+	claims, ok := r.Context().Value(jwtmiddleware.ContextKey{}).(*validator.ValidatedClaims)
+	return ok && claims != nil
+}
+
 func MarshalClaims(r *http.Request) ([]byte, error) {
 	claims := r.Context().Value(jwtmiddleware.ContextKey{}).(*validator.ValidatedClaims)
 
@@ -59,6 +174,12 @@ func MarshalClaims(r *http.Request) ([]byte, error) {
 	return payload, nil
 }
 
+func RegisteredClaims(r *http.Request) validator.RegisteredClaims {
+	claims := r.Context().Value(jwtmiddleware.ContextKey{}).(*validator.ValidatedClaims)
+
+	return claims.RegisteredClaims
+}
+
 func MarshalCustomClaims(r *http.Request) ([]byte, error) {
 	claims := r.Context().Value(jwtmiddleware.ContextKey{}).(*validator.ValidatedClaims)
 
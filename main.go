@@ -4,24 +4,33 @@ import (
 	"context"
 	"embed"
 	"log"
+	"os"
 	"time"
 
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.mongodb.org/mongo-driver/mongo/readpref"
 
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/audit"
 	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/blog"
 	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/cashflow"
 	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/config"
 	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/dashboard"
 	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/document"
 	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/dues"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/export"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/filegc"
 	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/handler"
 	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/history"
 	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/image"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/notifications"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/passhash"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/revision"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/storage"
 	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/user"
 
 	"github.com/cloudinary/cloudinary-go"
+	"github.com/cloudinary/cloudinary-go/api"
 	"github.com/cloudinary/cloudinary-go/api/uploader"
 	"github.com/getsentry/sentry-go"
 	"github.com/go-redis/redis/v8"
@@ -93,9 +102,35 @@ func main() {
 		log.Fatalf("fail to ping redis: %s", err)
 	}
 
-	cld, err := cloudinary.NewFromURL(conf.CloudinaryUrl)
-	if err != nil {
-		log.Fatalf("cloudinary.NewFromURL: %s", err)
+	var cld *cloudinary.Cloudinary
+	if conf.StorageDriver == storage.Cloudinary {
+		cld, err = cloudinary.NewFromURL(conf.CloudinaryUrl)
+		if err != nil {
+			log.Fatalf("cloudinary.NewFromURL: %s", err)
+		}
+	}
+
+	// newStorage create storage of the configured driver, upload params is only
+	// used by cloudinary, while private object of other driver is kept under
+	// the private prefix.
+	newStorage := func(uploadParams uploader.UploadParams) storage.Storage {
+		private := uploadParams.Type == api.Private
+
+		switch conf.StorageDriver {
+		case storage.Local:
+			return storage.NewLocal(conf.StorageLocalDir, conf.StorageBaseUrl, conf.StorageSignKey, private)
+		case storage.S3:
+			return storage.NewS3(storage.S3Config{
+				Endpoint:  conf.S3Endpoint,
+				Region:    conf.S3Region,
+				Bucket:    conf.S3Bucket,
+				AccessKey: conf.S3AccessKey,
+				SecretKey: conf.S3SecretKey,
+				PublicUrl: conf.S3PublicUrl,
+			}, private)
+		default:
+			return storage.NewCloudinary(&cld.Upload, &cld.Admin, uploadParams)
+		}
 	}
 
 	_, err = logger.NewLogger(logger.Options{App: "U-Homestay"}, conf.LogDnaKey)
@@ -127,11 +162,27 @@ func main() {
 	orgRepository := user.NewOrgStructureRepository(posgrePool)
 	periodRepository := user.NewOrgPeriodRepository(posgrePool)
 	goalRepository := user.NewGoalRepository(posgrePool)
+	roleRepository := user.NewRoleRepository(posgrePool)
+	refreshTokenRepository := user.NewRefreshTokenRepository("rtkn", redisClient)
+	tokenRevocationRepository := user.NewTokenRevocationRepository("rvkn", redisClient)
+	passwordResetRepository := user.NewPasswordResetRepository("pwrs", redisClient)
 	documentRepository := document.NewRepository(posgrePool)
 	cashflowRepository := cashflow.NewRepository(posgrePool)
+	cashflowCategoryRepository := cashflow.NewCategoryRepository(posgrePool)
+	cashflowAccountRepository := cashflow.NewAccountRepository(posgrePool)
+	cashflowTransferRepository := cashflow.NewTransferRepository(posgrePool)
 	duesRepository := dues.NewDeusRepository(posgrePool)
 	memberDuesRepository := dues.NewMemberDeusRepository(posgrePool)
+	duesTierRepository := dues.NewTierRepository(posgrePool)
+	duesOverrideRepository := dues.NewOverrideRepository(posgrePool)
+	duesScheduleRepository := dues.NewScheduleRepository(posgrePool)
+	duesPaymentRepository := dues.NewPaymentRepository(posgrePool)
 	imageRepository := image.NewRepository(posgrePool)
+	fileRepository := filegc.NewRepository(posgrePool)
+	outboxRepository := notifications.NewOutboxRepository(posgrePool)
+	inboxRepository := notifications.NewInboxRepository(posgrePool)
+	auditRepository := audit.NewAuditRepository(posgrePool)
+	revisionRepository := revision.NewRevisionRepository(posgrePool)
 
 	historyRepository := history.NewRepository(
 		posgrePool,
@@ -142,95 +193,246 @@ func main() {
 		posgrePool,
 	)
 
-	userDeps := user.NewDeps(
-		conf.JwtKey,
-		conf.JwtIssuerUrl,
-		conf.Argon2Salt,
-		conf.JwtAudiences,
-		user.CaptureMessage(sentry.CaptureMessage),
-		user.CaptureExeption(sentry.CaptureException),
-		user.FileUpload(uploader.UploadParams{
-			Transformation: "c_crop,g_center/q_auto/f_auto",
-			Tags:           []string{"profile"},
-			Folder:         "uhomestay/profile",
-			ResourceType:   "image",
-		}, cld.Upload.Upload),
-		tmpl,
-		memberRepository,
-		positionRepository,
-		orgRepository,
-		periodRepository,
-		goalRepository,
+	senders := []notifications.Sender{notifications.NewInAppSender(inboxRepository)}
+	if conf.SmtpHost != "" {
+		senders = append(senders, notifications.NewSmtpSender(notifications.SmtpConfig{
+			Host:     conf.SmtpHost,
+			Port:     conf.SmtpPort,
+			Username: conf.SmtpUsername,
+			Password: conf.SmtpPassword,
+			From:     conf.SmtpFrom,
+		}))
+	}
+	if conf.WaGatewayUrl != "" {
+		senders = append(senders, notifications.NewWhatsAppSender(conf.WaGatewayUrl, conf.WaGatewayToken))
+	}
+	notificationDeps := notifications.NewDeps(
+		conf.OrgName,
+		conf.NotificationAdminEmail,
+		conf.NotificationMaxAttempts,
+		conf.NotificationRetryBackoff,
+		notifications.CaptureMessage(sentry.CaptureMessage),
+		notifications.CaptureExeption(sentry.CaptureException),
+		senders,
+		outboxRepository,
+		inboxRepository,
+	)
+
+	if conf.NotificationInterval > 0 {
+		go notificationDeps.RunDispatcher(context.Background(), conf.NotificationInterval)
+	}
+
+	auditDeps := audit.NewDeps(
+		audit.CaptureMessage(sentry.CaptureMessage),
+		audit.CaptureExeption(sentry.CaptureException),
+		auditRepository,
+	)
+
+	revisionDeps := revision.NewDeps(
+		revision.CaptureMessage(sentry.CaptureMessage),
+		revision.CaptureExeption(sentry.CaptureException),
+		revisionRepository,
 	)
 
+	profileStorage := newStorage(uploader.UploadParams{
+		Transformation: "c_crop,g_center/q_auto/f_auto",
+		Tags:           []string{"profile"},
+		ResourceType:   "image",
+	})
+
+	documentStorage := newStorage(uploader.UploadParams{
+		Tags:         []string{"document"},
+		ResourceType: "raw",
+		Type:         api.Private,
+	})
 	documentDeps := document.NewDeps(
 		document.CaptureMessage(sentry.CaptureMessage),
 		document.CaptureExeption(sentry.CaptureException),
-		document.FileUpload(uploader.UploadParams{
-			Tags:         []string{"document"},
-			Folder:       "uhomestay/document",
-			ResourceType: "raw",
-		}, cld.Upload.Upload),
+		document.FileUpload(documentStorage, "uhomestay/document"),
+		document.FileSign(conf.FileUrlExpiry, documentStorage),
+		document.DocumentHooks{
+			OnShared: []document.DocumentHook{notificationDeps.DocumentShared},
+		},
+		auditDeps.Record,
 		documentRepository,
 	)
 
 	historyDeps := history.NewDeps(
 		history.CaptureMessage(sentry.CaptureMessage),
 		history.CaptureExeption(sentry.CaptureException),
+		auditDeps.Record,
+		revisionDeps.RecordRevision,
 		historyRepository,
 	)
 
 	blogImgFolder := "uhomestay/blog-images-tmp"
+	blogStorage := newStorage(uploader.UploadParams{
+		Tags:         []string{"blogs"},
+		ResourceType: "raw",
+	})
 	blogDeps := blog.NewDeps(
 		"uhomestay/blog-images",
 		blogImgFolder,
 		blog.CaptureMessage(sentry.CaptureMessage),
 		blog.CaptureExeption(sentry.CaptureException),
-		blog.FileMove(cld.Upload.Rename),
-		blog.FileUpload(uploader.UploadParams{
-			Tags:         []string{"blogs"},
-			Folder:       blogImgFolder,
-			ResourceType: "raw",
-		}, cld.Upload.Upload),
+		blog.FileMove(blogStorage),
+		blog.FileUpload(blogStorage, blogImgFolder),
+		blog.BlogHooks{
+			OnPublished: []blog.BlogHook{notificationDeps.BlogPublished},
+		},
+		auditDeps.Record,
+		revisionDeps.RecordRevision,
 		blogRepository,
 	)
 
+	if conf.BlogPublishInterval > 0 {
+		go blogDeps.RunPublisher(context.Background(), conf.BlogPublishInterval)
+	}
+
+	letterhead := export.Letterhead{
+		OrgName:    conf.OrgName,
+		OrgAddress: conf.OrgAddress,
+	}
+
+	cashflowStorage := newStorage(uploader.UploadParams{
+		Tags:         []string{"cashflow"},
+		ResourceType: "raw",
+		Type:         api.Private,
+	})
 	cashflowDeps := cashflow.NewDeps(
 		cashflow.CaptureMessage(sentry.CaptureMessage),
 		cashflow.CaptureExeption(sentry.CaptureException),
-		cashflow.FileUpload(uploader.UploadParams{
-			Tags:         []string{"cashflow"},
-			Folder:       "uhomestay/cashflows",
-			ResourceType: "raw",
-		}, cld.Upload.Upload),
+		cashflow.FileUpload(cashflowStorage, "uhomestay/cashflows"),
+		cashflow.FileSign(conf.FileUrlExpiry, cashflowStorage),
+		letterhead,
+		auditDeps.Record,
 		cashflowRepository,
+		cashflowCategoryRepository,
+		cashflowAccountRepository,
+		cashflowTransferRepository,
+		periodRepository,
 	)
 
+	duesStorage := newStorage(uploader.UploadParams{
+		Tags:         []string{"dues"},
+		ResourceType: "raw",
+		Type:         api.Private,
+	})
+	duesNotifier, err := dues.NewNotifier(conf.DuesReminderNotifier, conf.DuesReminderFile)
+	if err != nil {
+		log.Fatalf("dues.NewNotifier: %s", err)
+	}
 	duesDeps := dues.NewDeps(
 		dues.CaptureMessage(sentry.CaptureMessage),
 		dues.CaptureExeption(sentry.CaptureException),
-		dues.FileUpload(uploader.UploadParams{
-			Tags:         []string{"dues"},
-			Folder:       "uhomestay/dues",
-			ResourceType: "raw",
-		}, cld.Upload.Upload),
+		dues.FileUpload(duesStorage, "uhomestay/dues"),
+		dues.FileSign(conf.FileUrlExpiry, duesStorage),
+		letterhead,
+		conf.DuesScheduleDay,
+		duesNotifier,
+		dues.PaymentHooks{
+			OnSubmitted: []dues.PaymentHook{notificationDeps.DuesPaymentSubmitted},
+			OnApproved:  []dues.PaymentHook{notificationDeps.DuesPaymentApproved},
+			OnRejected:  []dues.PaymentHook{notificationDeps.DuesPaymentRejected},
+		},
+		auditDeps.Record,
 		duesRepository,
 		memberDuesRepository,
+		duesTierRepository,
+		duesOverrideRepository,
+		duesScheduleRepository,
+		duesPaymentRepository,
 		memberRepository,
 		cashflowRepository,
+		cashflowCategoryRepository,
+	)
+
+	if len(os.Args) > 1 && os.Args[1] == "reconcile-dues" {
+		reconcileDues(posgrePool, duesDeps, os.Args[2:])
+		return
+	}
+
+	if conf.DuesScheduleDay > 0 {
+		go duesDeps.RunScheduler(context.Background(), conf.DuesScheduleInterval)
+	}
+	if conf.DuesReminderInterval > 0 {
+		go duesDeps.RunReminder(context.Background(), conf.DuesReminderInterval, conf.DuesReminderMinMonths)
+	}
+
+	userDeps := user.NewDeps(
+		conf.JwtKey,
+		conf.JwtIssuerUrl,
+		passhash.Params{
+			Time:    conf.Argon2Time,
+			Memory:  conf.Argon2Memory,
+			Threads: conf.Argon2Threads,
+			SaltLen: passhash.DefaultParams.SaltLen,
+			KeyLen:  passhash.DefaultParams.KeyLen,
+		},
+		conf.JwtAudiences,
+		conf.JwtAccessExpiry,
+		conf.JwtRefreshExpiry,
+		conf.PasswordResetExpiry,
+		conf.PasswordResetLimit,
+		user.CaptureMessage(sentry.CaptureMessage),
+		user.CaptureExeption(sentry.CaptureException),
+		user.FileUpload(profileStorage, "uhomestay/profile"),
+		tmpl,
+		user.MemberHooks{
+			OnRegistered: []user.MemberHook{notificationDeps.MemberRegistered},
+			OnApproved:   []user.MemberHook{duesDeps.BackfillMemberDues, notificationDeps.MemberApproved},
+			OnRemoved:    []user.MemberHook{duesDeps.VoidMemberDues},
+			OnPasswordResetRequested: []user.PasswordResetHook{
+				notificationDeps.PasswordResetRequested,
+			},
+		},
+		auditDeps.Record,
+		revisionDeps.RecordRevision,
+		memberRepository,
+		positionRepository,
+		orgRepository,
+		periodRepository,
+		goalRepository,
+		refreshTokenRepository,
+		tokenRevocationRepository,
+		passwordResetRepository,
+		roleRepository,
 	)
+	if stats := userDeps.QueryPasswordHashStats(context.Background()); stats.Error == nil {
+		log.Printf("password hash: %d of %d members still use the legacy hash, %d use outdated cost",
+			stats.Res.Legacy, stats.Res.Total, stats.Res.Outdated)
+	}
+
+	revisionDeps.Restorers[revision.Blog] = blogDeps.RestoreBlog
+	revisionDeps.Restorers[revision.History] = historyDeps.RestoreHistory
+	revisionDeps.Restorers[revision.Goal] = userDeps.RestoreGoal
 
+	imageStorage := newStorage(uploader.UploadParams{
+		Tags:         []string{"image"},
+		ResourceType: "raw",
+	})
 	imageDeps := image.NewDeps(
 		image.CaptureMessage(sentry.CaptureMessage),
 		image.CaptureExeption(sentry.CaptureException),
-		image.FileUpload(uploader.UploadParams{
-			Tags:         []string{"image"},
-			Folder:       "uhomestay/images-gallery",
-			ResourceType: "raw",
-		}, cld.Upload.Upload),
+		image.FileUpload(imageStorage, "uhomestay/images-gallery"),
+		auditDeps.Record,
 		imageRepository,
 	)
 
+	// Storage of the same kind list the same objects, so only one of each
+	// is enough.
+	fileGcDeps := filegc.NewDeps(
+		"uhomestay/",
+		conf.FileGcGracePeriod,
+		filegc.CaptureMessage(sentry.CaptureMessage),
+		filegc.CaptureExeption(sentry.CaptureException),
+		[]storage.Storage{profileStorage, documentStorage, imageStorage},
+		fileRepository,
+	)
+	if conf.FileGcInterval > 0 {
+		go fileGcDeps.RunCollector(context.Background(), conf.FileGcInterval, conf.FileGcDryRun)
+	}
+
 	dashboardDeps := dashboard.NewDeps(
 		dashboard.CaptureMessage(sentry.CaptureMessage),
 		dashboard.CaptureExeption(sentry.CaptureException),
@@ -241,6 +443,10 @@ func main() {
 		cashflowDeps,
 		duesDeps,
 		userDeps,
+		fileGcDeps,
+		notificationDeps,
+		auditDeps,
+		revisionDeps,
 	)
 
 	restApi := handler.NewRestApi(
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	arbitary "github.com/PA-D3RPLA/d3if43-htt-uhomestay/arbitrary"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/jwt"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/resp"
+)
+
+var ErrPermissionDenied = errors.New("anda tidak memiliki izin untuk melakukan aksi ini")
+
+// PermissionChecker report whether the member with the given uid has the
+// permission.
+type PermissionChecker func(ctx context.Context, uid, permission string) (bool, error)
+
+// NewPermissionMiddleware return the middleware requiring the permission of
+// the member owning the token, it must be placed after the jwt middleware. The
+// member is set as the actor of the request for the audit.
+func NewPermissionMiddleware(hasPermission PermissionChecker) func(permission string) func(next http.Handler) http.Handler {
+	return func(permission string) func(next http.Handler) http.Handler {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var claims jwt.JwtPrivateClaim
+				if err := jwt.DecodeCustomClaims(r, &claims); err != nil {
+					resp.NewResponse(http.StatusInternalServerError, "", err).HttpJSON(w, nil)
+					return
+				}
+
+				ok, err := hasPermission(r.Context(), claims.Uid, permission)
+				if err != nil {
+					resp.NewResponse(http.StatusInternalServerError, "", err).HttpJSON(w, nil)
+					return
+				}
+
+				if !ok {
+					resp.NewResponse(http.StatusForbidden, "", ErrPermissionDenied).HttpJSON(w, nil)
+					return
+				}
+
+				ctx := context.WithValue(r.Context(), arbitary.ActorX{}, claims.Uid)
+				next.ServeHTTP(w, r.WithContext(ctx))
+			})
+		}
+	}
+}
@@ -5,7 +5,9 @@ import (
 	"net/http"
 
 	arbitary "github.com/PA-D3RPLA/d3if43-htt-uhomestay/arbitrary"
+	"github.com/getsentry/sentry-go"
 	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/pkg/errors"
 )
 
 // Ref:
@@ -46,6 +48,9 @@ func NewTrxMiddleware(db *pgxpool.Pool) func(next http.Handler) http.Handler {
 			// to value of `"pgx.Tx"`
 			ctx := context.WithValue(r.Context(), arbitary.TrxX{}, tx)
 
+			var afterCommit []func() error
+			ctx = context.WithValue(ctx, arbitary.AfterCommitX{}, &afterCommit)
+
 			// Capture status code from handler
 			lrw := NewLoggingResponseWriter(w)
 
@@ -64,6 +69,12 @@ func NewTrxMiddleware(db *pgxpool.Pool) func(next http.Handler) http.Handler {
 					w.Write([]byte("error in final commit transaction"))
 					return
 				}
+
+				for _, fn := range afterCommit {
+					if err = fn(); err != nil {
+						sentry.CaptureException(errors.Wrap(err, "run after commit"))
+					}
+				}
 			}
 		})
 	}
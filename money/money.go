@@ -0,0 +1,83 @@
+package money
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrNotValidIDR = errors.New("jumlah nominal rupiah harus berupa bilangan bulat tanpa pemisah ribuan")
+	ErrNegativeIDR = errors.New("jumlah nominal rupiah tidak boleh negatif")
+)
+
+// IDR is amount of rupiah. Rupiah has no sub unit in daily use, so it is kept
+// as integer to make the sum exact, both in Go and in the `BIGINT` column.
+type IDR int64
+
+// ParseIDR parse non negative rupiah amount written as plain digits, e.g.
+// `150000`. Decimal with one or two zero fraction, e.g. `150000.00`, is
+// accepted since that is how some spreadsheet export the amount, while
+// `150.000` is rejected because it is likely a thousand separator.
+func ParseIDR(s string) (IDR, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, ErrNotValidIDR
+	}
+
+	if i := strings.IndexByte(s, '.'); i != -1 {
+		frac := s[i+1:]
+		if frac != "0" && frac != "00" {
+			return 0, ErrNotValidIDR
+		}
+		s = s[:i]
+	}
+
+	if strings.HasPrefix(s, "-") {
+		return 0, ErrNegativeIDR
+	}
+
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return 0, ErrNotValidIDR
+		}
+	}
+
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, ErrNotValidIDR
+	}
+
+	return IDR(n), nil
+}
+
+func (m IDR) String() string {
+	return strconv.FormatInt(int64(m), 10)
+}
+
+func (m *IDR) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*m = 0
+	case int64:
+		*m = IDR(v)
+	case int32:
+		*m = IDR(v)
+	case string:
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return err
+		}
+		*m = IDR(n)
+	default:
+		return fmt.Errorf("cannot scan %T into money.IDR", src)
+	}
+
+	return nil
+}
+
+func (m IDR) Value() (driver.Value, error) {
+	return int64(m), nil
+}
@@ -0,0 +1,59 @@
+package money_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/money"
+)
+
+func TestParseIDR(t *testing.T) {
+	testCases := []struct {
+		name     string
+		in       string
+		expected money.IDR
+		err      error
+	}{
+		{name: "Plain Digits", in: "150000", expected: 150000},
+		{name: "Surrounding Space", in: " 150000 ", expected: 150000},
+		{name: "Zero", in: "0", expected: 0},
+		{name: "Zero Fraction", in: "150000.00", expected: 150000},
+		{name: "Empty", in: "", err: money.ErrNotValidIDR},
+		{name: "Non Zero Fraction", in: "150000.5", err: money.ErrNotValidIDR},
+		{name: "Empty Fraction", in: "150000.", err: money.ErrNotValidIDR},
+		{name: "Thousand Separator", in: "150.000", err: money.ErrNotValidIDR},
+		{name: "Comma Separator", in: "150,000", err: money.ErrNotValidIDR},
+		{name: "Currency Symbol", in: "Rp150000", err: money.ErrNotValidIDR},
+		{name: "Exponent", in: "1e5", err: money.ErrNotValidIDR},
+		{name: "Negative", in: "-150000", err: money.ErrNegativeIDR},
+		{name: "Overflow", in: strings.Repeat("9", 19), err: money.ErrNotValidIDR},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			m, err := money.ParseIDR(c.in)
+			if err != c.err {
+				t.Fatalf("Expected error %v. Got %v\n", c.err, err)
+			}
+
+			if m != c.expected {
+				t.Fatalf("Expected %d. Got %d\n", c.expected, m)
+			}
+		})
+	}
+}
+
+func TestIDRScan(t *testing.T) {
+	var m money.IDR
+	if err := m.Scan(int64(25000)); err != nil || m != 25000 {
+		t.Fatalf("Expected 25000. Got %d, %v\n", m, err)
+	}
+
+	if err := m.Scan(nil); err != nil || m != 0 {
+		t.Fatalf("Expected 0. Got %d, %v\n", m, err)
+	}
+
+	if err := m.Scan(1.5); err == nil {
+		t.Fatal("Expected error scanning float")
+	}
+}
@@ -0,0 +1,78 @@
+package notifications
+
+import (
+	"context"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+)
+
+type (
+	ExceptionCapturer func(exception error)
+	MessageCapturer   func(message string)
+)
+
+// Sender deliver the outbox notification through its channel. Send may be
+// called again with the same notification when the previous attempt failed.
+type Sender interface {
+	Channel() Channel
+	Send(ctx context.Context, m OutboxModel) error
+}
+
+type NotificationDeps struct {
+	// OrgName is used as the sender name in the message.
+	OrgName string
+	// AdminEmail receive the email notification of the admin, empty disable
+	// the email to admin.
+	AdminEmail string
+	// MaxAttempts is the number of attempts before the notification is marked
+	// as failed, the wait between attempts grow from RetryBackoff.
+	MaxAttempts      int
+	RetryBackoff     time.Duration
+	CaptureMessage   MessageCapturer
+	CaptureExeption  ExceptionCapturer
+	Senders          map[Channel]Sender
+	OutboxRepository *OutboxRepository
+	InboxRepository  *InboxRepository
+}
+
+func NewDeps(
+	orgName string,
+	adminEmail string,
+	maxAttempts int,
+	retryBackoff time.Duration,
+	captureMessage MessageCapturer,
+	captureExeption ExceptionCapturer,
+	senders []Sender,
+	outboxRepository *OutboxRepository,
+	inboxRepository *InboxRepository,
+) *NotificationDeps {
+	sendersByChannel := make(map[Channel]Sender, len(senders))
+	for _, s := range senders {
+		sendersByChannel[s.Channel()] = s
+	}
+
+	return &NotificationDeps{
+		OrgName:          orgName,
+		AdminEmail:       adminEmail,
+		MaxAttempts:      maxAttempts,
+		RetryBackoff:     retryBackoff,
+		CaptureMessage:   captureMessage,
+		CaptureExeption:  captureExeption,
+		Senders:          sendersByChannel,
+		OutboxRepository: outboxRepository,
+		InboxRepository:  inboxRepository,
+	}
+}
+
+func CaptureExeption(capture func(exception error) *sentry.EventID) ExceptionCapturer {
+	return func(exception error) {
+		capture(exception)
+	}
+}
+
+func CaptureMessage(capture func(message string) *sentry.EventID) MessageCapturer {
+	return func(message string) {
+		capture(message)
+	}
+}
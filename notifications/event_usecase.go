@@ -0,0 +1,191 @@
+package notifications
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+)
+
+var months = [...]string{
+	"Januari", "Februari", "Maret", "April", "Mei", "Juni",
+	"Juli", "Agustus", "September", "Oktober", "November", "Desember",
+}
+
+// monthNames join the months in Indonesian, e.g. "Maret 2022, April 2022".
+func monthNames(ts []time.Time) string {
+	names := make([]string, len(ts))
+	for i, t := range ts {
+		names[i] = months[t.Month()-1] + " " + strconv.Itoa(t.Year())
+	}
+
+	return strings.Join(names, ", ")
+}
+
+// The event handlers match the hooks of the user and dues package, they are
+// run in the transaction of the change so the notification is only sent when
+// the change is committed.
+
+// MemberRegistered notify the admins of the new member waiting for approval.
+func (d *NotificationDeps) MemberRegistered(ctx context.Context, uid string) error {
+	member, err := d.OutboxRepository.FindRecipientById(ctx, uid)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "find recipient by id")
+	}
+
+	return d.NotifyAdmins(
+		ctx,
+		"Pendaftaran anggota baru",
+		fmt.Sprintf("%s mendaftar sebagai anggota dan menunggu persetujuan.", member.Name),
+		"/members/"+uid,
+	)
+}
+
+// MemberApproved notify the member whose membership is approved.
+func (d *NotificationDeps) MemberApproved(ctx context.Context, uid string) error {
+	return d.NotifyMember(
+		ctx,
+		uid,
+		"Keanggotaan disetujui",
+		fmt.Sprintf("Keanggotaan anda di %s telah disetujui, silakan masuk untuk melihat tagihan iuran.", d.OrgName),
+		"/dues",
+	)
+}
+
+// DuesPaymentSubmitted notify the admins of the dues payment waiting for
+// verification.
+func (d *NotificationDeps) DuesPaymentSubmitted(ctx context.Context, uid string, paymentId uint64) error {
+	member, err := d.OutboxRepository.FindRecipientById(ctx, uid)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "find recipient by id")
+	}
+
+	amt, _, err := d.OutboxRepository.FindDuesPaymentById(ctx, paymentId)
+	if err != nil {
+		return errors.Wrap(err, "find dues payment by id")
+	}
+
+	return d.NotifyAdmins(
+		ctx,
+		"Pembayaran iuran baru",
+		fmt.Sprintf("%s mengirim bukti pembayaran iuran Rp %s yang menunggu verifikasi.", member.Name, amt.String()),
+		"/dues/payments",
+	)
+}
+
+// DuesPaymentApproved notify the member whose dues payment is verified with
+// the months it cover.
+func (d *NotificationDeps) DuesPaymentApproved(ctx context.Context, uid string, paymentId uint64) error {
+	amt, _, err := d.OutboxRepository.FindDuesPaymentById(ctx, paymentId)
+	if err != nil {
+		return errors.Wrap(err, "find dues payment by id")
+	}
+
+	months, err := d.OutboxRepository.QueryDuesPaymentMonths(ctx, paymentId)
+	if err != nil {
+		return errors.Wrap(err, "query dues payment months")
+	}
+
+	return d.NotifyMember(
+		ctx,
+		uid,
+		"Pembayaran iuran diterima",
+		fmt.Sprintf("Iuran anda bulan %s sebesar Rp %s telah diverifikasi, terima kasih.", monthNames(months), amt.String()),
+		"/dues",
+	)
+}
+
+// DuesPaymentRejected notify the member whose prove of dues payment is
+// rejected with the reason, so the member can upload it again.
+func (d *NotificationDeps) DuesPaymentRejected(ctx context.Context, uid string, paymentId uint64) error {
+	amt, reason, err := d.OutboxRepository.FindDuesPaymentById(ctx, paymentId)
+	if err != nil {
+		return errors.Wrap(err, "find dues payment by id")
+	}
+
+	return d.NotifyMember(
+		ctx,
+		uid,
+		"Pembayaran iuran ditolak",
+		fmt.Sprintf("Bukti pembayaran iuran anda sebesar Rp %s ditolak: %s. Silakan unggah ulang bukti pembayaran.", amt.String(), reason),
+		"/dues",
+	)
+}
+
+// BlogPublished notify every member of the new blog post.
+func (d *NotificationDeps) BlogPublished(ctx context.Context, id uint64) error {
+	title, _, err := d.OutboxRepository.FindBlogById(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "find blog by id")
+	}
+
+	return d.NotifyMembers(
+		ctx,
+		"Artikel baru",
+		fmt.Sprintf("Artikel baru telah terbit: %s.", title),
+		"/blogs/"+strconv.FormatUint(id, 10),
+	)
+}
+
+// DocumentShared notify every member of the new document file.
+func (d *NotificationDeps) DocumentShared(ctx context.Context, id uint64) error {
+	name, dirId, err := d.OutboxRepository.FindDocumentById(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "find document by id")
+	}
+
+	return d.NotifyMembers(
+		ctx,
+		"Dokumen baru",
+		fmt.Sprintf("Dokumen baru telah dibagikan: %s.", name),
+		"/documents/"+strconv.FormatUint(dirId, 10),
+	)
+}
+
+// PasswordResetRequested send the password reset token to the WhatsApp of the
+// member only, the token must not be shown in-app to whoever hold the session.
+func (d *NotificationDeps) PasswordResetRequested(ctx context.Context, uid, token string, expiresAt time.Time) error {
+	member, err := d.OutboxRepository.FindRecipientById(ctx, uid)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "find recipient by id")
+	}
+
+	if member.WaPhone == "" {
+		return nil
+	}
+
+	return d.enqueue(ctx, OutboxModel{
+		Channel:   WhatsApp,
+		MemberId:  sql.NullString{String: member.Id, Valid: true},
+		Recipient: member.WaPhone,
+		Title:     "Reset password",
+		Body: fmt.Sprintf(
+			"Gunakan token berikut untuk mengganti password akun %s anda, berlaku sampai %s: %s\n\nAbaikan pesan ini jika anda tidak meminta reset password.",
+			d.OrgName,
+			expiresAt.Format("02-01-2006 15:04"),
+			token,
+		),
+		Link:     "/reset-password?token=" + token,
+		IsSecret: true,
+	})
+}
@@ -0,0 +1,240 @@
+package notifications_test
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/notifications"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/user"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/ory/dockertest/v3"
+	"github.com/ory/dockertest/v3/docker"
+)
+
+var (
+	db               *pgxpool.Pool
+	memberRepository *user.MemberRepository
+	outboxRepository *notifications.OutboxRepository
+	inboxRepository  *notifications.InboxRepository
+	emailSender      *stubSender
+	notificationDeps *notifications.NotificationDeps
+)
+
+var (
+	captureException notifications.ExceptionCapturer = func(exception error) {}
+	captureMessage   notifications.MessageCapturer   = func(message string) {}
+)
+
+var (
+	memberSeed = user.MemberModel{
+		Name:              "Name",
+		HomestayName:      "Homestay Name",
+		Username:          "existusername",
+		WaPhone:           "+62 821-1111-0000",
+		OtherPhone:        "+62 821-1111-0000",
+		HomestayAddress:   "Homestay Address",
+		HomestayLatitude:  "120.12312312",
+		HomestayLongitude: "90.1212321",
+		Password:          "password",
+		IsAdmin:           true,
+		IsApproved:        true,
+	}
+	memberSeed2 = user.MemberModel{
+		Name:              "Name Two",
+		HomestayName:      "Homestay Name Two",
+		Username:          "existusernametwo",
+		OtherPhone:        "+62 821-1111-0001",
+		HomestayAddress:   "Homestay Address Two",
+		HomestayLatitude:  "120.12312312",
+		HomestayLongitude: "90.1212321",
+		Password:          "password",
+		IsAdmin:           false,
+		IsApproved:        true,
+	}
+)
+
+// stubSender record the sent notifications, the next sends fail while Fails
+// is more than zero.
+type stubSender struct {
+	mu    sync.Mutex
+	Kind  notifications.Channel
+	Fails int
+	Sent  []notifications.OutboxModel
+}
+
+func (s *stubSender) Channel() notifications.Channel {
+	return s.Kind
+}
+
+func (s *stubSender) Send(ctx context.Context, m notifications.OutboxModel) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.Fails > 0 {
+		s.Fails--
+		return fmt.Errorf("stub %s sender fail", s.Kind)
+	}
+
+	s.Sent = append(s.Sent, m)
+	return nil
+}
+
+func (s *stubSender) Reset(fails int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Fails = fails
+	s.Sent = nil
+}
+
+func LoadTables(conn *pgxpool.Pool) error {
+	tx, err := conn.Begin(context.Background())
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback(context.Background())
+
+	f, err := os.ReadFile("../docs/db.sql")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(context.Background(),
+		string(f),
+	)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit(context.Background())
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func ClearTables(conn *pgxpool.Pool) error {
+	tx, err := conn.Begin(context.Background())
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback(context.Background())
+
+	// This should be in order of which table truncate first before the other
+	queries := []string{
+		`TRUNCATE notifications CASCADE`,
+		`TRUNCATE notification_outbox CASCADE`,
+		`TRUNCATE dues_payment_allocations CASCADE`,
+		`TRUNCATE dues_payments CASCADE`,
+		`TRUNCATE members CASCADE`,
+	}
+
+	for _, v := range queries {
+		_, err = tx.Exec(context.Background(),
+			v,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = tx.Commit(context.Background())
+	if err != nil {
+		return err
+	}
+
+	emailSender.Reset(0)
+
+	return nil
+}
+
+func TestMain(m *testing.M) {
+	// uses a sensible default on windows (tcp/http) and linux/osx (socket)
+	pool, err := dockertest.NewPool("")
+	if err != nil {
+		log.Fatalf("Could not connect to docker: %s", err)
+	}
+
+	// pulls an image, creates a container based on it and runs it
+	resource, err := pool.RunWithOptions(&dockertest.RunOptions{
+		Repository: "postgres",
+		Tag:        "14.1",
+		Env: []string{
+			"POSTGRES_PASSWORD=secret",
+			"POSTGRES_USER=user_name",
+			"POSTGRES_DB=dbname",
+			"listen_addresses = '*'",
+		},
+	}, func(config *docker.HostConfig) {
+		// set AutoRemove to true so that stopped container goes away by itself
+		config.AutoRemove = true
+		config.RestartPolicy = docker.RestartPolicy{Name: "no"}
+	})
+	if err != nil {
+		log.Fatalf("Could not start resource: %s", err)
+	}
+
+	hostAndPort := resource.GetHostPort("5432/tcp")
+	databaseUrl := fmt.Sprintf("postgres://user_name:secret@%s/dbname?sslmode=disable", hostAndPort)
+
+	log.Println("Connecting to database on url: ", databaseUrl)
+
+	resource.Expire(120) // Tell docker to hard kill the container in 120 seconds
+
+	// exponential backoff-retry, because the application in the container might not be ready to accept connections yet
+	pool.MaxWait = 120 * time.Second
+	if err = pool.Retry(func() error {
+		dbConfig, err := pgxpool.ParseConfig(databaseUrl)
+		if err != nil {
+			return err
+		}
+
+		db, err = pgxpool.ConnectConfig(context.Background(), dbConfig)
+		if err != nil {
+			return err
+		}
+
+		return db.Ping(context.Background())
+	}); err != nil {
+		log.Fatalf("Could not connect to docker: %s", err)
+	}
+
+	memberRepository = user.NewMemberRepository(db)
+	outboxRepository = notifications.NewOutboxRepository(db)
+	inboxRepository = notifications.NewInboxRepository(db)
+	emailSender = &stubSender{Kind: notifications.Email}
+	notificationDeps = notifications.NewDeps(
+		"Uhomestay",
+		"admin@example.com",
+		3,
+		time.Minute,
+		captureMessage,
+		captureException,
+		[]notifications.Sender{
+			notifications.NewInAppSender(inboxRepository),
+			emailSender,
+		},
+		outboxRepository,
+		inboxRepository,
+	)
+
+	LoadTables(db)
+
+	// Run tests
+	code := m.Run()
+
+	// You can't defer this because os.Exit doesn't care for defer
+	if err := pool.Purge(resource); err != nil {
+		log.Fatalf("Could not purge resource: %s", err)
+	}
+
+	os.Exit(code)
+}
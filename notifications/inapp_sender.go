@@ -0,0 +1,39 @@
+package notifications
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+var ErrNoMember = errors.New("in-app notification has no member")
+
+// InAppSender add the notification to the inbox of the member.
+type InAppSender struct {
+	InboxRepository *InboxRepository
+}
+
+func NewInAppSender(inboxRepository *InboxRepository) *InAppSender {
+	return &InAppSender{
+		InboxRepository: inboxRepository,
+	}
+}
+
+func (s *InAppSender) Channel() Channel {
+	return InApp
+}
+
+func (s *InAppSender) Send(ctx context.Context, m OutboxModel) error {
+	if !m.MemberId.Valid {
+		return ErrNoMember
+	}
+
+	return s.InboxRepository.Save(ctx, InboxModel{
+		MemberId: m.MemberId.String,
+		OutboxId: sql.NullInt64{Int64: int64(m.Id), Valid: true},
+		Title:    m.Title,
+		Body:     m.Body,
+		Link:     m.Link,
+	})
+}
@@ -0,0 +1,242 @@
+package notifications
+
+import (
+	"context"
+	"time"
+
+	arbitary "github.com/PA-D3RPLA/d3if43-htt-uhomestay/arbitrary"
+
+	"github.com/georgysavva/scany/pgxscan"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+type InboxRepository struct {
+	PostgreDb *pgxpool.Pool
+}
+
+func NewInboxRepository(postgreDb *pgxpool.Pool) *InboxRepository {
+	return &InboxRepository{
+		PostgreDb: postgreDb,
+	}
+}
+
+type InboxExecutor func(ctx context.Context, sql string, arguments ...interface{}) (commandTag pgconn.CommandTag, err error)
+
+// Save add the notification to the inbox of the member, the notification of
+// the same outbox is only saved once.
+func (r *InboxRepository) Save(ctx context.Context, m InboxModel) error {
+	sqlQuery := `
+		INSERT INTO notifications (
+			member_id,
+			outbox_id,
+			title,
+			body,
+			link,
+			created_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (outbox_id) DO NOTHING
+	`
+
+	var exec InboxExecutor
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		exec = tx.Exec
+	} else {
+		exec = r.PostgreDb.Exec
+	}
+
+	_, err := exec(
+		context.Background(),
+		sqlQuery,
+		m.MemberId,
+		m.OutboxId,
+		m.Title,
+		m.Body,
+		m.Link,
+		time.Now(),
+	)
+
+	return err
+}
+
+type (
+	InboxQuerierRow func(ctx context.Context, sql string, args ...interface{}) pgx.Row
+	InboxQuerier    func(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+)
+
+// Query return the latest notifications of the member older than the id,
+// zero id start from the latest.
+func (r *InboxRepository) Query(ctx context.Context, uid string, fromId uint64, limit int64) (ms []InboxModel, err error) {
+	sqlQuery := `
+		SELECT
+			id,
+			member_id,
+			outbox_id,
+			title,
+			body,
+			link,
+			read_at,
+			created_at
+		FROM notifications
+		WHERE member_id = $1
+		AND ($2::BIGINT = 0 OR id < $2::BIGINT)
+		ORDER BY id DESC
+		LIMIT $3
+	`
+
+	return r.query(ctx, sqlQuery, uid, fromId, limit)
+}
+
+// QueryAfter return the notifications of the member newer than the id from
+// the oldest, it is used to follow the inbox.
+func (r *InboxRepository) QueryAfter(ctx context.Context, uid string, afterId uint64, limit int64) (ms []InboxModel, err error) {
+	sqlQuery := `
+		SELECT
+			id,
+			member_id,
+			outbox_id,
+			title,
+			body,
+			link,
+			read_at,
+			created_at
+		FROM notifications
+		WHERE member_id = $1
+		AND id > $2
+		ORDER BY id
+		LIMIT $3
+	`
+
+	return r.query(ctx, sqlQuery, uid, afterId, limit)
+}
+
+func (r *InboxRepository) query(ctx context.Context, sqlQuery string, args ...interface{}) (ms []InboxModel, err error) {
+	var query InboxQuerier
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		query = tx.Query
+	} else {
+		query = r.PostgreDb.Query
+	}
+
+	rows, err := query(
+		context.Background(),
+		sqlQuery,
+		args...,
+	)
+	if err != nil {
+		return []InboxModel{}, err
+	}
+	defer rows.Close()
+
+	var mps []*InboxModel
+	if err := pgxscan.ScanAll(&mps, rows); err != nil {
+		return []InboxModel{}, err
+	}
+
+	ms = make([]InboxModel, len(mps))
+	for i, m := range mps {
+		ms[i] = *m
+	}
+
+	return ms, nil
+}
+
+// LatestId return the id of the latest notification of the member, zero when
+// the inbox is empty.
+func (r *InboxRepository) LatestId(ctx context.Context, uid string) (id uint64, err error) {
+	sqlQuery := `
+		SELECT COALESCE(MAX(id), 0)
+		FROM notifications
+		WHERE member_id = $1
+	`
+
+	var queryRow InboxQuerierRow
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		queryRow = tx.QueryRow
+	} else {
+		queryRow = r.PostgreDb.QueryRow
+	}
+
+	err = queryRow(context.Background(), sqlQuery, uid).Scan(&id)
+
+	return
+}
+
+func (r *InboxRepository) CountUnread(ctx context.Context, uid string) (n int64, err error) {
+	sqlQuery := `
+		SELECT COUNT(*)
+		FROM notifications
+		WHERE member_id = $1
+		AND read_at IS NULL
+	`
+
+	var queryRow InboxQuerierRow
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		queryRow = tx.QueryRow
+	} else {
+		queryRow = r.PostgreDb.QueryRow
+	}
+
+	err = queryRow(context.Background(), sqlQuery, uid).Scan(&n)
+
+	return
+}
+
+// MarkRead mark the notification of the member as read, the read time of
+// notification already read is kept. It return false when the member has no
+// such notification.
+func (r *InboxRepository) MarkRead(ctx context.Context, uid string, id uint64, at time.Time) (bool, error) {
+	sqlQuery := `
+		UPDATE notifications SET
+			read_at = COALESCE(read_at, $3)
+		WHERE id = $1
+		AND member_id = $2
+	`
+
+	var exec InboxExecutor
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		exec = tx.Exec
+	} else {
+		exec = r.PostgreDb.Exec
+	}
+
+	cmd, err := exec(context.Background(), sqlQuery, id, uid, at)
+	if err != nil {
+		return false, err
+	}
+
+	return cmd.RowsAffected() != 0, nil
+}
+
+// MarkAllRead mark every unread notification of the member as read and
+// return the number of them.
+func (r *InboxRepository) MarkAllRead(ctx context.Context, uid string, at time.Time) (int64, error) {
+	sqlQuery := `
+		UPDATE notifications SET
+			read_at = $2
+		WHERE member_id = $1
+		AND read_at IS NULL
+	`
+
+	var exec InboxExecutor
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		exec = tx.Exec
+	} else {
+		exec = r.PostgreDb.Exec
+	}
+
+	cmd, err := exec(context.Background(), sqlQuery, uid, at)
+	if err != nil {
+		return 0, err
+	}
+
+	return cmd.RowsAffected(), nil
+}
@@ -0,0 +1,110 @@
+package notifications
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/jwt"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/resp"
+	"github.com/go-chi/chi/v5"
+)
+
+func (d *NotificationDeps) GetNotifications(w http.ResponseWriter, r *http.Request) {
+	var jwtPayload jwt.JwtPrivateClaim
+	if err := jwt.DecodeCustomClaims(r, &jwtPayload); err != nil {
+		d.CaptureExeption(err)
+		resp.NewResponse(http.StatusInternalServerError, "", err).HttpJSON(w, nil)
+		return
+	}
+
+	cursor := r.URL.Query().Get("cursor")
+	limit := r.URL.Query().Get("limit")
+
+	out := d.QueryNotification(r.Context(), jwtPayload.Uid, cursor, limit)
+	if out.Error != nil {
+		d.CaptureExeption(out.Error)
+	}
+	out.HttpJSON(w, resp.NewHttpBody(out.Res))
+}
+
+func (d *NotificationDeps) PatchNotificationRead(w http.ResponseWriter, r *http.Request) {
+	var jwtPayload jwt.JwtPrivateClaim
+	if err := jwt.DecodeCustomClaims(r, &jwtPayload); err != nil {
+		d.CaptureExeption(err)
+		resp.NewResponse(http.StatusInternalServerError, "", err).HttpJSON(w, nil)
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+
+	out := d.ReadNotification(r.Context(), jwtPayload.Uid, id)
+	if out.Error != nil {
+		d.CaptureExeption(out.Error)
+	}
+	out.HttpJSON(w, resp.NewHttpBody(out.Res))
+}
+
+func (d *NotificationDeps) PatchNotificationsRead(w http.ResponseWriter, r *http.Request) {
+	var jwtPayload jwt.JwtPrivateClaim
+	if err := jwt.DecodeCustomClaims(r, &jwtPayload); err != nil {
+		d.CaptureExeption(err)
+		resp.NewResponse(http.StatusInternalServerError, "", err).HttpJSON(w, nil)
+		return
+	}
+
+	out := d.ReadAllNotification(r.Context(), jwtPayload.Uid)
+	if out.Error != nil {
+		d.CaptureExeption(out.Error)
+	}
+	out.HttpJSON(w, resp.NewHttpBody(out.Res))
+}
+
+// GetNotificationStream stream the inbox as Server-Sent Events, the browser
+// resume from the Last-Event-ID header when it reconnect.
+func (d *NotificationDeps) GetNotificationStream(w http.ResponseWriter, r *http.Request) {
+	var jwtPayload jwt.JwtPrivateClaim
+	if err := jwt.DecodeCustomClaims(r, &jwtPayload); err != nil {
+		d.CaptureExeption(err)
+		resp.NewResponse(http.StatusInternalServerError, "", err).HttpJSON(w, nil)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		resp.NewResponse(http.StatusInternalServerError, "", ErrStreamUnsupported).HttpJSON(w, nil)
+		return
+	}
+
+	lastId, _ := strconv.ParseUint(r.Header.Get("Last-Event-ID"), 10, 64)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	err := d.StreamNotification(r.Context(), jwtPayload.Uid, lastId, func(e StreamEvent) error {
+		var err error
+		switch {
+		case e.IsHeartbeat:
+			_, err = fmt.Fprint(w, ": heartbeat\n\n")
+		case e.Notification != nil:
+			data, _ := json.Marshal(e.Notification)
+			_, err = fmt.Fprintf(w, "id: %d\nevent: notification\ndata: %s\n\n", e.Notification.Id, data)
+		default:
+			_, err = fmt.Fprintf(w, "event: unread\ndata: {\"unread\":%d}\n\n", e.Unread)
+		}
+		if err != nil {
+			return err
+		}
+
+		flusher.Flush()
+		return nil
+	})
+	if err != nil && r.Context().Err() == nil {
+		d.CaptureExeption(err)
+	}
+}
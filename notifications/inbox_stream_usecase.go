@@ -0,0 +1,94 @@
+package notifications
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/pkg/errors"
+)
+
+const (
+	// streamPollInterval is how often the followed inbox is checked, the inbox
+	// is read from the database so every instance see the same notifications.
+	streamPollInterval = 3 * time.Second
+	// streamHeartbeat keep the idle stream open through the proxies.
+	streamHeartbeat = 30 * time.Second
+	streamBatchSize = 50
+)
+
+// StreamEvent is one event of the inbox stream, the notification is nil for
+// the heartbeat and the unread count event.
+type StreamEvent struct {
+	Notification *NotificationOut
+	Unread       int64
+	IsHeartbeat  bool
+}
+
+// StreamNotification follow the inbox of the member after the last id until
+// the context is done or send fail. Zero last id start from the latest
+// notification. The unread count is sent first and after every new
+// notification.
+func (d *NotificationDeps) StreamNotification(ctx context.Context, uid string, lastId uint64, send func(StreamEvent) error) error {
+	if _, err := uuid.FromString(uid); err != nil {
+		return ErrMemberNotFound
+	}
+
+	var err error
+	if lastId == 0 {
+		if lastId, err = d.InboxRepository.LatestId(ctx, uid); err != nil {
+			return errors.Wrap(err, "latest inbox id")
+		}
+	}
+
+	sendUnread := func() error {
+		unread, err := d.InboxRepository.CountUnread(ctx, uid)
+		if err != nil {
+			return errors.Wrap(err, "count unread")
+		}
+
+		return send(StreamEvent{Unread: unread})
+	}
+
+	if err = sendUnread(); err != nil {
+		return err
+	}
+
+	poll := time.NewTicker(streamPollInterval)
+	defer poll.Stop()
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-heartbeat.C:
+			if err = send(StreamEvent{IsHeartbeat: true}); err != nil {
+				return err
+			}
+			continue
+		case <-poll.C:
+		}
+
+		ms, err := d.InboxRepository.QueryAfter(ctx, uid, lastId, streamBatchSize)
+		if err != nil {
+			return errors.Wrap(err, "query inbox after")
+		}
+		if len(ms) == 0 {
+			continue
+		}
+
+		for _, m := range ms {
+			n := toNotificationOut(m)
+			if err = send(StreamEvent{Notification: &n}); err != nil {
+				return err
+			}
+			lastId = m.Id
+		}
+
+		if err = sendUnread(); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package notifications
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/pagination"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/resp"
+	"github.com/gofrs/uuid"
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrMemberNotFound       = errors.New("anggota tidak ditemukan")
+	ErrNotificationNotFound = errors.New("notifikasi tidak ditemukan")
+	ErrStreamUnsupported    = errors.New("streaming tidak didukung")
+)
+
+type (
+	NotificationOut struct {
+		Id        int64  `json:"id"`
+		Title     string `json:"title"`
+		Body      string `json:"body"`
+		Link      string `json:"link"`
+		IsRead    bool   `json:"is_read"`
+		CreatedAt string `json:"created_at"`
+	}
+	QueryNotificationRes struct {
+		Unread        int64             `json:"unread"`
+		Cursor        string            `json:"cursor"`
+		Notifications []NotificationOut `json:"notifications"`
+	}
+	QueryNotificationOut struct {
+		resp.Response
+		Res QueryNotificationRes
+	}
+)
+
+func toNotificationOut(m InboxModel) NotificationOut {
+	return NotificationOut{
+		Id:        int64(m.Id),
+		Title:     m.Title,
+		Body:      m.Body,
+		Link:      m.Link,
+		IsRead:    m.ReadAt.Valid,
+		CreatedAt: m.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+// QueryNotification return the inbox of the member from the latest, the
+// cursor is the last notification of the previous page.
+func (d *NotificationDeps) QueryNotification(ctx context.Context, uid, cursor, limit string) (out QueryNotificationOut) {
+	out.Response = resp.NewResponse(http.StatusOK, "", nil)
+
+	if _, err := uuid.FromString(uid); err != nil {
+		out.Response = resp.NewResponse(http.StatusNotFound, "", ErrMemberNotFound)
+		return
+	}
+
+	s, _, err := pagination.DecodeSIDCursor(cursor)
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "decode sid cursor"))
+		return
+	}
+
+	fromId, _ := strconv.ParseUint(s, 10, 64)
+	nlimit, _ := strconv.ParseInt(limit, 10, 64)
+	if nlimit <= 0 {
+		nlimit = 25
+	}
+
+	unread, err := d.InboxRepository.CountUnread(ctx, uid)
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "count unread"))
+		return
+	}
+
+	ms, err := d.InboxRepository.Query(ctx, uid, fromId, nlimit)
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "query inbox"))
+		return
+	}
+
+	mLen := len(ms)
+
+	var nextCursor string
+	if mLen != 0 {
+		m := ms[mLen-1]
+		nextCursor = pagination.EncodeSIDCursor(strconv.FormatUint(m.Id, 10), m.CreatedAt)
+	}
+
+	outNotifications := make([]NotificationOut, mLen)
+	for i, m := range ms {
+		outNotifications[i] = toNotificationOut(m)
+	}
+
+	out.Res = QueryNotificationRes{
+		Unread:        unread,
+		Cursor:        nextCursor,
+		Notifications: outNotifications,
+	}
+
+	return
+}
+
+type (
+	ReadNotificationRes struct {
+		Id     int64 `json:"id"`
+		Unread int64 `json:"unread"`
+	}
+	ReadNotificationOut struct {
+		resp.Response
+		Res ReadNotificationRes
+	}
+)
+
+// ReadNotification mark the notification of the member as read.
+func (d *NotificationDeps) ReadNotification(ctx context.Context, uid, pid string) (out ReadNotificationOut) {
+	out.Response = resp.NewResponse(http.StatusOK, "", nil)
+
+	if _, err := uuid.FromString(uid); err != nil {
+		out.Response = resp.NewResponse(http.StatusNotFound, "", ErrMemberNotFound)
+		return
+	}
+
+	id, err := strconv.ParseUint(pid, 10, 64)
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusNotFound, "", ErrNotificationNotFound)
+		return
+	}
+
+	ok, err := d.InboxRepository.MarkRead(ctx, uid, id, time.Now())
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "mark read"))
+		return
+	}
+	if !ok {
+		out.Response = resp.NewResponse(http.StatusNotFound, "", ErrNotificationNotFound)
+		return
+	}
+
+	unread, err := d.InboxRepository.CountUnread(ctx, uid)
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "count unread"))
+		return
+	}
+
+	out.Res = ReadNotificationRes{
+		Id:     int64(id),
+		Unread: unread,
+	}
+
+	return
+}
+
+type (
+	ReadAllNotificationRes struct {
+		Count int64 `json:"count"`
+	}
+	ReadAllNotificationOut struct {
+		resp.Response
+		Res ReadAllNotificationRes
+	}
+)
+
+// ReadAllNotification mark every unread notification of the member as read.
+func (d *NotificationDeps) ReadAllNotification(ctx context.Context, uid string) (out ReadAllNotificationOut) {
+	out.Response = resp.NewResponse(http.StatusOK, "", nil)
+
+	if _, err := uuid.FromString(uid); err != nil {
+		out.Response = resp.NewResponse(http.StatusNotFound, "", ErrMemberNotFound)
+		return
+	}
+
+	n, err := d.InboxRepository.MarkAllRead(ctx, uid, time.Now())
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "mark all read"))
+		return
+	}
+
+	out.Res.Count = n
+
+	return
+}
+
+type (
+	CountUnreadRes struct {
+		Unread int64 `json:"unread"`
+	}
+	CountUnreadOut struct {
+		resp.Response
+		Res CountUnreadRes
+	}
+)
+
+func (d *NotificationDeps) CountUnreadNotification(ctx context.Context, uid string) (out CountUnreadOut) {
+	out.Response = resp.NewResponse(http.StatusOK, "", nil)
+
+	if _, err := uuid.FromString(uid); err != nil {
+		out.Response = resp.NewResponse(http.StatusNotFound, "", ErrMemberNotFound)
+		return
+	}
+
+	n, err := d.InboxRepository.CountUnread(ctx, uid)
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "count unread"))
+		return
+	}
+
+	out.Res.Unread = n
+
+	return
+}
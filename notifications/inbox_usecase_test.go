@@ -0,0 +1,181 @@
+package notifications_test
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/notifications"
+)
+
+func createInbox(uid string, n int) error {
+	for i := 0; i < n; i++ {
+		err := inboxRepository.Save(context.Background(), notifications.InboxModel{
+			MemberId: uid,
+			Title:    "Judul " + strconv.Itoa(i),
+			Body:     "Isi",
+			Link:     "/dues",
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func TestQueryNotification(t *testing.T) {
+	err := ClearTables(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	uid, err := createMember(memberSeed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = createInbox(uid, 3); err != nil {
+		t.Fatal(err)
+	}
+
+	first := notificationDeps.QueryNotification(context.Background(), uid, "", "2")
+	if first.StatusCode != http.StatusOK {
+		t.Logf("%#v", first)
+		t.Fatalf("Expected response code %d. Got %d\n", http.StatusOK, first.StatusCode)
+	}
+
+	testCases := []struct {
+		Name               string
+		ExpectedStatusCode int
+		ExpectedLen        int
+		ExpectedUnread     int64
+		Uid                string
+		Cursor             string
+	}{
+		{
+			Name:               "Query Notification Success",
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedLen:        3,
+			ExpectedUnread:     3,
+			Uid:                uid,
+		},
+		{
+			Name:               "Query Notification Next Page Success",
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedLen:        1,
+			ExpectedUnread:     3,
+			Uid:                uid,
+			Cursor:             first.Res.Cursor,
+		},
+		{
+			Name:               "Query Notification Fail, Member Not Found",
+			ExpectedStatusCode: http.StatusNotFound,
+			Uid:                "not-uuid",
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.Name, func(t *testing.T) {
+			res := notificationDeps.QueryNotification(context.Background(), c.Uid, c.Cursor, "")
+
+			if res.StatusCode != c.ExpectedStatusCode {
+				t.Logf("%#v", res)
+				t.Fatalf("Expected response code %d. Got %d\n", c.ExpectedStatusCode, res.StatusCode)
+			}
+
+			if len(res.Res.Notifications) != c.ExpectedLen {
+				t.Fatalf("Expected notifications length %d. Got %d\n", c.ExpectedLen, len(res.Res.Notifications))
+			}
+
+			if res.Res.Unread != c.ExpectedUnread {
+				t.Fatalf("Expected unread %d. Got %d\n", c.ExpectedUnread, res.Res.Unread)
+			}
+		})
+	}
+}
+
+func TestReadNotification(t *testing.T) {
+	err := ClearTables(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	uid, err := createMember(memberSeed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	uid2, err := createMember(memberSeed2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = createInbox(uid, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	res := notificationDeps.QueryNotification(context.Background(), uid, "", "")
+	if len(res.Res.Notifications) != 2 {
+		t.Fatalf("Expected notifications length 2. Got %d\n", len(res.Res.Notifications))
+	}
+	id := strconv.FormatInt(res.Res.Notifications[0].Id, 10)
+
+	testCases := []struct {
+		Name               string
+		ExpectedStatusCode int
+		ExpectedUnread     int64
+		Uid                string
+		Id                 string
+	}{
+		{
+			Name:               "Read Notification Fail, Not The Owner",
+			ExpectedStatusCode: http.StatusNotFound,
+			Uid:                uid2,
+			Id:                 id,
+		},
+		{
+			Name:               "Read Notification Fail, Not Found",
+			ExpectedStatusCode: http.StatusNotFound,
+			Uid:                uid,
+			Id:                 "999",
+		},
+		{
+			Name:               "Read Notification Success",
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedUnread:     1,
+			Uid:                uid,
+			Id:                 id,
+		},
+		{
+			Name:               "Read Notification Again Success",
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedUnread:     1,
+			Uid:                uid,
+			Id:                 id,
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.Name, func(t *testing.T) {
+			res := notificationDeps.ReadNotification(context.Background(), c.Uid, c.Id)
+
+			if res.StatusCode != c.ExpectedStatusCode {
+				t.Logf("%#v", res)
+				t.Fatalf("Expected response code %d. Got %d\n", c.ExpectedStatusCode, res.StatusCode)
+			}
+
+			if res.Res.Unread != c.ExpectedUnread {
+				t.Fatalf("Expected unread %d. Got %d\n", c.ExpectedUnread, res.Res.Unread)
+			}
+		})
+	}
+
+	all := notificationDeps.ReadAllNotification(context.Background(), uid)
+	if all.StatusCode != http.StatusOK || all.Res.Count != 1 {
+		t.Fatalf("Expected read all count 1. Got %#v\n", all)
+	}
+
+	unread := notificationDeps.CountUnreadNotification(context.Background(), uid)
+	if unread.Res.Unread != 0 {
+		t.Fatalf("Expected no unread notification. Got %d\n", unread.Res.Unread)
+	}
+}
@@ -0,0 +1,62 @@
+package notifications
+
+import (
+	"database/sql"
+	"time"
+)
+
+type Channel string
+
+const (
+	Email    Channel = "email"
+	WhatsApp Channel = "whatsapp"
+	InApp    Channel = "inapp"
+)
+
+type OutboxStatus string
+
+const (
+	OutboxPending OutboxStatus = "pending"
+	OutboxSent    OutboxStatus = "sent"
+	OutboxFailed  OutboxStatus = "failed"
+)
+
+// OutboxModel is the notification waiting to be sent through the channel,
+// the recipient is the email address or the phone depend on the channel.
+type OutboxModel struct {
+	Id        uint64
+	Channel   Channel
+	MemberId  sql.NullString
+	Recipient string
+	Title     string
+	Body      string
+	Link      string
+	// IsSecret clear the body and link once the notification is sent or
+	// failed, for the notification carrying a credential.
+	IsSecret      bool
+	Status        OutboxStatus
+	Attempts      int
+	LastError     string
+	NextAttemptAt time.Time
+	SentAt        sql.NullTime
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// InboxModel is the in-app notification of the member.
+type InboxModel struct {
+	Id        uint64
+	MemberId  string
+	OutboxId  sql.NullInt64
+	Title     string
+	Body      string
+	Link      string
+	ReadAt    sql.NullTime
+	CreatedAt time.Time
+}
+
+type RecipientModel struct {
+	Id      string
+	Name    string
+	WaPhone string
+}
@@ -0,0 +1,203 @@
+package notifications
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/resp"
+	"github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+)
+
+const (
+	// dispatchBatchSize is the number of notifications claimed at once.
+	dispatchBatchSize = 50
+	// dispatchLease keep the claimed notification from other dispatcher while
+	// it is being sent.
+	dispatchLease = 5 * time.Minute
+	// maxRetryBackoff limit the wait between attempts.
+	maxRetryBackoff = 24 * time.Hour
+)
+
+var ErrNoSender = errors.New("no sender of the channel")
+
+// enqueue write the notifications to the outbox, notification of the channel
+// without sender is dropped.
+func (d *NotificationDeps) enqueue(ctx context.Context, ms ...OutboxModel) error {
+	for _, m := range ms {
+		if _, ok := d.Senders[m.Channel]; !ok {
+			continue
+		}
+
+		if _, err := d.OutboxRepository.Save(ctx, m); err != nil {
+			return errors.Wrap(err, "save outbox")
+		}
+	}
+
+	return nil
+}
+
+// NotifyMember notify the member in-app and through WhatsApp when the member
+// has the phone. It should be called in the transaction of the change.
+func (d *NotificationDeps) NotifyMember(ctx context.Context, uid, title, body, link string) error {
+	member, err := d.OutboxRepository.FindRecipientById(ctx, uid)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "find recipient by id")
+	}
+
+	memberId := sql.NullString{String: member.Id, Valid: true}
+	ms := []OutboxModel{
+		{Channel: InApp, MemberId: memberId, Title: title, Body: body, Link: link},
+	}
+	if member.WaPhone != "" {
+		ms = append(ms, OutboxModel{Channel: WhatsApp, MemberId: memberId, Recipient: member.WaPhone, Title: title, Body: body, Link: link})
+	}
+
+	return d.enqueue(ctx, ms...)
+}
+
+// NotifyAdmins notify every admin in-app and the admin email. It should be
+// called in the transaction of the change.
+func (d *NotificationDeps) NotifyAdmins(ctx context.Context, title, body, link string) error {
+	admins, err := d.OutboxRepository.QueryAdminRecipients(ctx)
+	if err != nil {
+		return errors.Wrap(err, "query admin recipients")
+	}
+
+	ms := make([]OutboxModel, 0, len(admins)+1)
+	for _, a := range admins {
+		ms = append(ms, OutboxModel{
+			Channel:  InApp,
+			MemberId: sql.NullString{String: a.Id, Valid: true},
+			Title:    title,
+			Body:     body,
+			Link:     link,
+		})
+	}
+	if d.AdminEmail != "" {
+		ms = append(ms, OutboxModel{Channel: Email, Recipient: d.AdminEmail, Title: title, Body: body, Link: link})
+	}
+
+	return d.enqueue(ctx, ms...)
+}
+
+// NotifyMembers notify every approved member in-app. It should be called in
+// the transaction of the change.
+func (d *NotificationDeps) NotifyMembers(ctx context.Context, title, body, link string) error {
+	if _, ok := d.Senders[InApp]; !ok {
+		return nil
+	}
+
+	_, err := d.OutboxRepository.SaveForMembers(ctx, OutboxModel{Channel: InApp, Title: title, Body: body, Link: link})
+	if err != nil {
+		return errors.Wrap(err, "save outbox for members")
+	}
+
+	return nil
+}
+
+// retryAt return the time of the next attempt, the wait is doubled on every
+// failed attempt.
+func (d *NotificationDeps) retryAt(now time.Time, attempts int) time.Time {
+	backoff := d.RetryBackoff
+	for i := 1; i < attempts && backoff < maxRetryBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxRetryBackoff {
+		backoff = maxRetryBackoff
+	}
+
+	return now.Add(backoff)
+}
+
+type (
+	DispatchRes struct {
+		Claimed int `json:"claimed"`
+		Sent    int `json:"sent"`
+		Retried int `json:"retried"`
+		Failed  int `json:"failed"`
+	}
+	DispatchOut struct {
+		resp.Response
+		Res DispatchRes
+	}
+)
+
+// Dispatch send the due notifications of the outbox through their channel.
+// The failed notification is retried later until the max attempts is reached.
+func (d *NotificationDeps) Dispatch(ctx context.Context, now time.Time) (out DispatchOut) {
+	out.Response = resp.NewResponse(http.StatusOK, "", nil)
+
+	ms, err := d.OutboxRepository.Claim(ctx, now, dispatchLease, dispatchBatchSize)
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "claim outbox"))
+		return
+	}
+	out.Res.Claimed = len(ms)
+
+	for _, m := range ms {
+		sender, ok := d.Senders[m.Channel]
+		if !ok {
+			err = ErrNoSender
+		} else {
+			err = sender.Send(ctx, m)
+		}
+
+		switch {
+		case err == nil:
+			err = d.OutboxRepository.MarkSent(ctx, m.Id, time.Now())
+			out.Res.Sent++
+		case !ok || m.Attempts >= d.MaxAttempts:
+			d.CaptureExeption(errors.Wrapf(err, "send %s notification %d", m.Channel, m.Id))
+			err = d.OutboxRepository.MarkFailed(ctx, m.Id, err.Error())
+			out.Res.Failed++
+		default:
+			err = d.OutboxRepository.MarkRetry(ctx, m.Id, d.retryAt(now, m.Attempts), err.Error())
+			out.Res.Retried++
+		}
+		if err != nil {
+			out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "mark outbox"))
+			return
+		}
+	}
+
+	return
+}
+
+// RunDispatcher dispatch the outbox every interval until the context is done,
+// the outbox is drained before waiting for the next interval.
+func (d *NotificationDeps) RunDispatcher(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		for {
+			out := d.Dispatch(ctx, time.Now())
+			if out.Error != nil {
+				d.CaptureExeption(out.Error)
+				break
+			}
+
+			if out.Res.Claimed != 0 {
+				log.Printf("notification: claimed %d, sent %d, retried %d, failed %d",
+					out.Res.Claimed, out.Res.Sent, out.Res.Retried, out.Res.Failed)
+			}
+
+			if out.Res.Claimed < dispatchBatchSize {
+				break
+			}
+		}
+	}
+}
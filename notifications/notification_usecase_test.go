@@ -0,0 +1,313 @@
+package notifications_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/notifications"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/user"
+	"github.com/gofrs/uuid"
+)
+
+func createMember(m user.MemberModel) (string, error) {
+	uid, err := uuid.NewV6()
+	if err != nil {
+		return "", err
+	}
+
+	m.Id.Scan(uid.String())
+	if err = memberRepository.Save(context.Background(), m); err != nil {
+		return "", err
+	}
+
+	return uid.String(), nil
+}
+
+func countOutbox(channel notifications.Channel, status notifications.OutboxStatus) (n int, err error) {
+	err = db.QueryRow(context.Background(),
+		`SELECT COUNT(*) FROM notification_outbox WHERE channel = $1::notificationchannel AND status = $2::outboxstatus`,
+		channel,
+		status,
+	).Scan(&n)
+
+	return
+}
+
+func TestNotifyMember(t *testing.T) {
+	err := ClearTables(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	uid, err := createMember(memberSeed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		Name          string
+		ExpectedInApp int
+		Uid           string
+	}{
+		{
+			Name:          "Notify Member Success",
+			ExpectedInApp: 1,
+			Uid:           uid,
+		},
+		{
+			Name:          "Notify Member Not Found Is Skipped",
+			ExpectedInApp: 1,
+			Uid:           "1ed1b1a2-0000-6000-8000-000000000000",
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.Name, func(t *testing.T) {
+			err := notificationDeps.NotifyMember(context.Background(), c.Uid, "Judul", "Isi", "/dues")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			n, err := countOutbox(notifications.InApp, notifications.OutboxPending)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n != c.ExpectedInApp {
+				t.Fatalf("Expected in-app outbox %d. Got %d\n", c.ExpectedInApp, n)
+			}
+
+			// The WhatsApp channel has no sender, so it is never written.
+			n, err = countOutbox(notifications.WhatsApp, notifications.OutboxPending)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n != 0 {
+				t.Fatalf("Expected no whatsapp outbox. Got %d\n", n)
+			}
+		})
+	}
+}
+
+func TestNotifyAdmins(t *testing.T) {
+	err := ClearTables(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = createMember(memberSeed); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = createMember(memberSeed2); err != nil {
+		t.Fatal(err)
+	}
+
+	err = notificationDeps.NotifyAdmins(context.Background(), "Judul", "Isi", "/members")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := countOutbox(notifications.InApp, notifications.OutboxPending)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Fatalf("Expected in-app outbox of the admin only. Got %d\n", n)
+	}
+
+	n, err = countOutbox(notifications.Email, notifications.OutboxPending)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Fatalf("Expected email outbox to the admin email. Got %d\n", n)
+	}
+}
+
+func TestNotifyMembers(t *testing.T) {
+	err := ClearTables(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = createMember(memberSeed); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = createMember(memberSeed2); err != nil {
+		t.Fatal(err)
+	}
+
+	err = notificationDeps.NotifyMembers(context.Background(), "Artikel baru", "Isi", "/blogs/1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := countOutbox(notifications.InApp, notifications.OutboxPending)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Fatalf("Expected in-app outbox of every member. Got %d\n", n)
+	}
+}
+
+func TestDispatch(t *testing.T) {
+	err := ClearTables(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	uid, err := createMember(memberSeed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = notificationDeps.NotifyAdmins(context.Background(), "Judul", "Isi", "/members")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The first email attempt fail.
+	emailSender.Reset(1)
+	now := time.Now()
+
+	testCases := []struct {
+		Name            string
+		ExpectedClaimed int
+		ExpectedSent    int
+		ExpectedRetried int
+		ExpectedFailed  int
+		Now             time.Time
+	}{
+		{
+			Name:            "Dispatch Send In-App And Retry Email",
+			ExpectedClaimed: 2,
+			ExpectedSent:    1,
+			ExpectedRetried: 1,
+			Now:             now,
+		},
+		{
+			Name: "Dispatch Wait For Retry Backoff",
+			Now:  now.Add(30 * time.Second),
+		},
+		{
+			Name:            "Dispatch Retry Email Success",
+			ExpectedClaimed: 1,
+			ExpectedSent:    1,
+			Now:             now.Add(time.Hour),
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.Name, func(t *testing.T) {
+			res := notificationDeps.Dispatch(context.Background(), c.Now)
+			if res.StatusCode != http.StatusOK {
+				t.Logf("%#v", res)
+				t.Fatalf("Expected response code %d. Got %d\n", http.StatusOK, res.StatusCode)
+			}
+
+			expected := notifications.DispatchRes{
+				Claimed: c.ExpectedClaimed,
+				Sent:    c.ExpectedSent,
+				Retried: c.ExpectedRetried,
+				Failed:  c.ExpectedFailed,
+			}
+			if res.Res != expected {
+				t.Fatalf("Expected dispatch %#v. Got %#v\n", expected, res.Res)
+			}
+		})
+	}
+
+	if len(emailSender.Sent) != 1 || emailSender.Sent[0].Recipient != "admin@example.com" {
+		t.Fatalf("Expected one email to the admin email. Got %#v\n", emailSender.Sent)
+	}
+
+	var inbox int
+	err = db.QueryRow(context.Background(), `SELECT COUNT(*) FROM notifications WHERE member_id = $1`, uid).Scan(&inbox)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inbox != 1 {
+		t.Fatalf("Expected one in-app notification of the admin. Got %d\n", inbox)
+	}
+}
+
+func TestDispatchMaxAttempts(t *testing.T) {
+	err := ClearTables(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = notificationDeps.NotifyAdmins(context.Background(), "Judul", "Isi", "/members")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	emailSender.Reset(10)
+	now := time.Now()
+
+	// The max attempts of the fixture is 3, the email is failed on the third
+	// attempt.
+	for i := 1; i <= 3; i++ {
+		res := notificationDeps.Dispatch(context.Background(), now)
+		if res.StatusCode != http.StatusOK {
+			t.Logf("%#v", res)
+			t.Fatalf("Expected response code %d. Got %d\n", http.StatusOK, res.StatusCode)
+		}
+		if res.Res.Claimed != 1 {
+			t.Fatalf("Expected claimed 1 on attempt %d. Got %d\n", i, res.Res.Claimed)
+		}
+
+		now = now.Add(24 * time.Hour)
+	}
+
+	n, err := countOutbox(notifications.Email, notifications.OutboxFailed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Fatalf("Expected failed email outbox 1. Got %d\n", n)
+	}
+
+	res := notificationDeps.Dispatch(context.Background(), now)
+	if res.Res.Claimed != 0 {
+		t.Fatalf("Expected failed outbox not claimed again. Got %d\n", res.Res.Claimed)
+	}
+}
+
+func TestPasswordResetRequested(t *testing.T) {
+	err := ClearTables(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	uid, err := createMember(memberSeed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = notificationDeps.PasswordResetRequested(context.Background(), uid, "secrettoken", time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The fixture has no whatsapp sender, so the notification is failed on
+	// its first attempt.
+	res := notificationDeps.Dispatch(context.Background(), time.Now())
+	if res.Res.Failed != 1 {
+		t.Logf("%#v", res)
+		t.Fatalf("Expected failed 1. Got %d\n", res.Res.Failed)
+	}
+
+	var n int
+	err = db.QueryRow(context.Background(),
+		`SELECT COUNT(*) FROM notification_outbox WHERE body LIKE '%secrettoken%' OR link LIKE '%secrettoken%'`,
+	).Scan(&n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 0 {
+		t.Fatalf("Expected reset token cleared from the outbox. Got %d\n", n)
+	}
+}
@@ -0,0 +1,519 @@
+package notifications
+
+import (
+	"context"
+	"time"
+
+	arbitary "github.com/PA-D3RPLA/d3if43-htt-uhomestay/arbitrary"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/money"
+
+	"github.com/georgysavva/scany/pgxscan"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+type OutboxRepository struct {
+	PostgreDb *pgxpool.Pool
+}
+
+func NewOutboxRepository(postgreDb *pgxpool.Pool) *OutboxRepository {
+	return &OutboxRepository{
+		PostgreDb: postgreDb,
+	}
+}
+
+type (
+	OutboxExecutor   func(ctx context.Context, sql string, arguments ...interface{}) (commandTag pgconn.CommandTag, err error)
+	OutboxQuerierRow func(ctx context.Context, sql string, args ...interface{}) pgx.Row
+	OutboxQuerier    func(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+)
+
+// Save write the notification to the outbox in the transaction of the
+// context, so it is discarded when the transaction is rolled back.
+func (r *OutboxRepository) Save(ctx context.Context, m OutboxModel) (nm OutboxModel, err error) {
+	sqlQuery := `
+		INSERT INTO notification_outbox (
+			channel,
+			member_id,
+			recipient,
+			title,
+			body,
+			link,
+			is_secret,
+			status,
+			next_attempt_at,
+			created_at,
+			updated_at
+		)
+		VALUES ($1::notificationchannel, $2, $3, $4, $5, $6, $7, $8::outboxstatus, $9, $10, $11)
+		RETURNING id
+	`
+
+	var queryRow OutboxQuerierRow
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		queryRow = tx.QueryRow
+	} else {
+		queryRow = r.PostgreDb.QueryRow
+	}
+
+	t := time.Now()
+	m.Status = OutboxPending
+	if m.NextAttemptAt.IsZero() {
+		m.NextAttemptAt = t
+	}
+
+	err = queryRow(
+		context.Background(),
+		sqlQuery,
+		string(m.Channel),
+		m.MemberId,
+		m.Recipient,
+		m.Title,
+		m.Body,
+		m.Link,
+		m.IsSecret,
+		string(m.Status),
+		m.NextAttemptAt,
+		t,
+		t,
+	).Scan(&m.Id)
+	if err != nil {
+		return OutboxModel{}, err
+	}
+
+	m.CreatedAt = t
+	m.UpdatedAt = t
+
+	return m, nil
+}
+
+// Claim take the due pending notifications and push their next attempt by the
+// lease, so other dispatcher doesn't send them while they are being sent. The
+// attempts is counted on claim, so a crashed attempt still count.
+func (r *OutboxRepository) Claim(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]OutboxModel, error) {
+	sqlQuery := `
+		UPDATE notification_outbox SET
+			attempts = attempts + 1,
+			next_attempt_at = $2,
+			updated_at = $1
+		WHERE id IN (
+			SELECT id
+			FROM notification_outbox
+			WHERE status = 'pending'
+				AND next_attempt_at <= $1
+			ORDER BY id
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING
+			id,
+			channel::TEXT AS channel,
+			member_id,
+			recipient,
+			title,
+			body,
+			link,
+			is_secret,
+			status::TEXT AS status,
+			attempts,
+			last_error,
+			next_attempt_at,
+			sent_at,
+			created_at,
+			updated_at
+	`
+
+	rows, err := r.PostgreDb.Query(
+		context.Background(),
+		sqlQuery,
+		now,
+		now.Add(lease),
+		limit,
+	)
+	if err != nil {
+		return []OutboxModel{}, err
+	}
+	defer rows.Close()
+
+	var mps []*OutboxModel
+	if err := pgxscan.ScanAll(&mps, rows); err != nil {
+		return []OutboxModel{}, err
+	}
+
+	ms := make([]OutboxModel, len(mps))
+	for i, m := range mps {
+		ms[i] = *m
+	}
+
+	return ms, nil
+}
+
+// MarkSent mark the notification as sent, the body and link of the secret
+// notification is cleared as it is no longer needed.
+func (r *OutboxRepository) MarkSent(ctx context.Context, id uint64, at time.Time) error {
+	sqlQuery := `
+		UPDATE notification_outbox SET (
+			status,
+			last_error,
+			body,
+			link,
+			sent_at,
+			updated_at
+		) = (
+			'sent',
+			'',
+			CASE WHEN is_secret THEN '' ELSE body END,
+			CASE WHEN is_secret THEN '' ELSE link END,
+			$1,
+			$1
+		)
+		WHERE id = $2
+	`
+
+	_, err := r.PostgreDb.Exec(
+		context.Background(),
+		sqlQuery,
+		at,
+		id,
+	)
+
+	return err
+}
+
+// MarkRetry keep the notification pending until the next attempt.
+func (r *OutboxRepository) MarkRetry(ctx context.Context, id uint64, nextAttemptAt time.Time, lastError string) error {
+	sqlQuery := `
+		UPDATE notification_outbox SET (
+			last_error,
+			next_attempt_at,
+			updated_at
+		) = ($1, $2, $3)
+		WHERE id = $4
+	`
+
+	_, err := r.PostgreDb.Exec(
+		context.Background(),
+		sqlQuery,
+		lastError,
+		nextAttemptAt,
+		time.Now(),
+		id,
+	)
+
+	return err
+}
+
+// MarkFailed stop retrying the notification, the body and link of the secret
+// notification is cleared the same as when it is sent.
+func (r *OutboxRepository) MarkFailed(ctx context.Context, id uint64, lastError string) error {
+	sqlQuery := `
+		UPDATE notification_outbox SET (
+			status,
+			last_error,
+			body,
+			link,
+			updated_at
+		) = (
+			'failed',
+			$1,
+			CASE WHEN is_secret THEN '' ELSE body END,
+			CASE WHEN is_secret THEN '' ELSE link END,
+			$2
+		)
+		WHERE id = $3
+	`
+
+	_, err := r.PostgreDb.Exec(
+		context.Background(),
+		sqlQuery,
+		lastError,
+		time.Now(),
+		id,
+	)
+
+	return err
+}
+
+func (r *OutboxRepository) FindById(ctx context.Context, id uint64) (m OutboxModel, err error) {
+	sqlQuery := `
+		SELECT
+			id,
+			channel::TEXT AS channel,
+			member_id,
+			recipient,
+			title,
+			body,
+			link,
+			is_secret,
+			status::TEXT AS status,
+			attempts,
+			last_error,
+			next_attempt_at,
+			sent_at,
+			created_at,
+			updated_at
+		FROM notification_outbox
+		WHERE id = $1
+	`
+
+	rows, err := r.PostgreDb.Query(
+		context.Background(),
+		sqlQuery,
+		id,
+	)
+	if err != nil {
+		return OutboxModel{}, err
+	}
+
+	if err = pgxscan.ScanOne(&m, rows); err != nil {
+		return OutboxModel{}, err
+	}
+
+	return m, nil
+}
+
+// FindRecipientById return the member to be notified, it read the member in
+// the transaction of the context so the uncommitted member is found.
+func (r *OutboxRepository) FindRecipientById(ctx context.Context, uid string) (m RecipientModel, err error) {
+	sqlQuery := `
+		SELECT
+			id,
+			name,
+			wa_phone
+		FROM members
+		WHERE deleted_at IS NULL
+			AND id = $1
+	`
+
+	var query OutboxQuerier
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		query = tx.Query
+	} else {
+		query = r.PostgreDb.Query
+	}
+
+	rows, err := query(
+		context.Background(),
+		sqlQuery,
+		uid,
+	)
+	if err != nil {
+		return RecipientModel{}, err
+	}
+
+	if err = pgxscan.ScanOne(&m, rows); err != nil {
+		return RecipientModel{}, err
+	}
+
+	return m, nil
+}
+
+func (r *OutboxRepository) QueryAdminRecipients(ctx context.Context) ([]RecipientModel, error) {
+	sqlQuery := `
+		SELECT
+			id,
+			name,
+			wa_phone
+		FROM members
+		WHERE deleted_at IS NULL
+			AND is_admin = true
+			AND is_approved = true
+		ORDER BY name
+	`
+
+	var query OutboxQuerier
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		query = tx.Query
+	} else {
+		query = r.PostgreDb.Query
+	}
+
+	rows, err := query(
+		context.Background(),
+		sqlQuery,
+	)
+	if err != nil {
+		return []RecipientModel{}, err
+	}
+	defer rows.Close()
+
+	var mps []*RecipientModel
+	if err := pgxscan.ScanAll(&mps, rows); err != nil {
+		return []RecipientModel{}, err
+	}
+
+	ms := make([]RecipientModel, len(mps))
+	for i, m := range mps {
+		ms[i] = *m
+	}
+
+	return ms, nil
+}
+
+// FindDuesPaymentById return the amount and the reject reason of the dues
+// payment being notified.
+func (r *OutboxRepository) FindDuesPaymentById(ctx context.Context, id uint64) (amt money.IDR, rejectReason string, err error) {
+	sqlQuery := `
+		SELECT
+			idr_amount,
+			reject_reason
+		FROM dues_payments
+		WHERE id = $1
+	`
+
+	var queryRow OutboxQuerierRow
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		queryRow = tx.QueryRow
+	} else {
+		queryRow = r.PostgreDb.QueryRow
+	}
+
+	err = queryRow(
+		context.Background(),
+		sqlQuery,
+		id,
+	).Scan(&amt, &rejectReason)
+
+	return amt, rejectReason, err
+}
+
+// SaveForMembers write the notification to the outbox of every approved
+// member, it is meant for in-app notification sent to all members.
+func (r *OutboxRepository) SaveForMembers(ctx context.Context, m OutboxModel) (int64, error) {
+	sqlQuery := `
+		INSERT INTO notification_outbox (
+			channel,
+			member_id,
+			title,
+			body,
+			link,
+			status,
+			next_attempt_at,
+			created_at,
+			updated_at
+		)
+		SELECT $1::notificationchannel, id, $2, $3, $4, 'pending', $5, $5, $5
+		FROM members
+		WHERE deleted_at IS NULL
+			AND is_approved = true
+	`
+
+	var exec OutboxExecutor
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		exec = tx.Exec
+	} else {
+		exec = r.PostgreDb.Exec
+	}
+
+	cmd, err := exec(
+		context.Background(),
+		sqlQuery,
+		m.Channel,
+		m.Title,
+		m.Body,
+		m.Link,
+		time.Now(),
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return cmd.RowsAffected(), nil
+}
+
+// QueryDuesPaymentMonths return the month of every member dues covered by the
+// dues payment from the oldest.
+func (r *OutboxRepository) QueryDuesPaymentMonths(ctx context.Context, id uint64) ([]time.Time, error) {
+	sqlQuery := `
+		SELECT d.date
+		FROM dues_payment_allocations a
+		JOIN member_dues md ON md.id = a.member_dues_id
+		JOIN dues d ON d.id = md.dues_id
+		WHERE a.payment_id = $1
+		ORDER BY d.date
+	`
+
+	var query OutboxQuerier
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		query = tx.Query
+	} else {
+		query = r.PostgreDb.Query
+	}
+
+	rows, err := query(
+		context.Background(),
+		sqlQuery,
+		id,
+	)
+	if err != nil {
+		return []time.Time{}, err
+	}
+	defer rows.Close()
+
+	months := []time.Time{}
+	for rows.Next() {
+		var t time.Time
+		if err = rows.Scan(&t); err != nil {
+			return []time.Time{}, err
+		}
+		months = append(months, t)
+	}
+
+	return months, rows.Err()
+}
+
+// FindBlogById return the title and the slug of the blog being notified.
+func (r *OutboxRepository) FindBlogById(ctx context.Context, id uint64) (title, slug string, err error) {
+	sqlQuery := `
+		SELECT
+			title,
+			slug
+		FROM blogs
+		WHERE deleted_at IS NULL
+			AND id = $1
+	`
+
+	var queryRow OutboxQuerierRow
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		queryRow = tx.QueryRow
+	} else {
+		queryRow = r.PostgreDb.QueryRow
+	}
+
+	err = queryRow(context.Background(), sqlQuery, id).Scan(&title, &slug)
+
+	return title, slug, err
+}
+
+// FindDocumentById return the name and the parent dir of the document being
+// notified.
+func (r *OutboxRepository) FindDocumentById(ctx context.Context, id uint64) (name string, dirId uint64, err error) {
+	sqlQuery := `
+		SELECT
+			name,
+			dir_id
+		FROM documents
+		WHERE deleted_at IS NULL
+			AND id = $1
+	`
+
+	var queryRow OutboxQuerierRow
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		queryRow = tx.QueryRow
+	} else {
+		queryRow = r.PostgreDb.QueryRow
+	}
+
+	err = queryRow(context.Background(), sqlQuery, id).Scan(&name, &dirId)
+
+	return name, dirId, err
+}
@@ -0,0 +1,111 @@
+package notifications
+
+import (
+	"context"
+	"crypto/tls"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// smtpTimeout limit the whole conversation with the SMTP server, so a stuck
+// server doesn't hold the dispatcher.
+const smtpTimeout = 30 * time.Second
+
+type SmtpConfig struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+// SmtpSender send the notification as plain text email, STARTTLS is used
+// when the server support it.
+type SmtpSender struct {
+	Config SmtpConfig
+}
+
+func NewSmtpSender(config SmtpConfig) *SmtpSender {
+	return &SmtpSender{
+		Config: config,
+	}
+}
+
+func (s *SmtpSender) Channel() Channel {
+	return Email
+}
+
+func (s *SmtpSender) message(m OutboxModel, now time.Time) []byte {
+	body := m.Body
+	if m.Link != "" {
+		body += "\n\n" + m.Link
+	}
+
+	var b strings.Builder
+	b.WriteString("From: " + s.Config.From + "\r\n")
+	b.WriteString("To: " + m.Recipient + "\r\n")
+	b.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", m.Title) + "\r\n")
+	b.WriteString("Date: " + now.Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+	b.WriteString("\r\n")
+
+	return []byte(b.String())
+}
+
+func (s *SmtpSender) Send(ctx context.Context, m OutboxModel) error {
+	dialer := net.Dialer{Timeout: smtpTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(s.Config.Host, s.Config.Port))
+	if err != nil {
+		return errors.Wrap(err, "dial smtp server")
+	}
+	conn.SetDeadline(time.Now().Add(smtpTimeout))
+
+	c, err := smtp.NewClient(conn, s.Config.Host)
+	if err != nil {
+		conn.Close()
+		return errors.Wrap(err, "new smtp client")
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err = c.StartTLS(&tls.Config{ServerName: s.Config.Host}); err != nil {
+			return errors.Wrap(err, "start tls")
+		}
+	}
+
+	if s.Config.Username != "" {
+		auth := smtp.PlainAuth("", s.Config.Username, s.Config.Password, s.Config.Host)
+		if err = c.Auth(auth); err != nil {
+			return errors.Wrap(err, "smtp auth")
+		}
+	}
+
+	if err = c.Mail(s.Config.From); err != nil {
+		return errors.Wrap(err, "smtp mail")
+	}
+	if err = c.Rcpt(m.Recipient); err != nil {
+		return errors.Wrap(err, "smtp rcpt")
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return errors.Wrap(err, "smtp data")
+	}
+	if _, err = w.Write(s.message(m, time.Now())); err != nil {
+		return errors.Wrap(err, "write smtp data")
+	}
+	if err = w.Close(); err != nil {
+		return errors.Wrap(err, "close smtp data")
+	}
+
+	return c.Quit()
+}
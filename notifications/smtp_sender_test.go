@@ -0,0 +1,111 @@
+package notifications_test
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/notifications"
+)
+
+// serveSmtp answer one SMTP conversation without STARTTLS and auth, and send
+// the received data to the channel.
+func serveSmtp(l net.Listener, data chan<- string) {
+	conn, err := l.Accept()
+	if err != nil {
+		close(data)
+		return
+	}
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	reply := func(s string) {
+		conn.Write([]byte(s + "\r\n"))
+	}
+
+	reply("220 localhost stub")
+	var b strings.Builder
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			close(data)
+			return
+		}
+
+		cmd := strings.ToUpper(strings.TrimSpace(line))
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+			reply("250 localhost")
+		case strings.HasPrefix(cmd, "MAIL"), strings.HasPrefix(cmd, "RCPT"):
+			reply("250 OK")
+		case cmd == "DATA":
+			reply("354 end data with <CR><LF>.<CR><LF>")
+			for {
+				line, err := r.ReadString('\n')
+				if err != nil {
+					close(data)
+					return
+				}
+				if line == ".\r\n" {
+					break
+				}
+				b.WriteString(line)
+			}
+			reply("250 OK")
+		case cmd == "QUIT":
+			reply("221 bye")
+			data <- b.String()
+			return
+		default:
+			reply("502 not implemented")
+		}
+	}
+}
+
+func TestSmtpSender(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	data := make(chan string, 1)
+	go serveSmtp(l, data)
+
+	host, port, err := net.SplitHostPort(l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sender := notifications.NewSmtpSender(notifications.SmtpConfig{
+		Host: host,
+		Port: port,
+		From: "noreply@example.com",
+	})
+
+	err = sender.Send(context.Background(), notifications.OutboxModel{
+		Channel:   notifications.Email,
+		Recipient: "admin@example.com",
+		Title:     "Pendaftaran anggota baru",
+		Body:      "Name mendaftar sebagai anggota dan menunggu persetujuan.",
+		Link:      "/members",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := <-data
+	for _, s := range []string{
+		"From: noreply@example.com\r\n",
+		"To: admin@example.com\r\n",
+		"Subject: Pendaftaran anggota baru\r\n",
+		"Name mendaftar sebagai anggota dan menunggu persetujuan.\r\n",
+		"/members\r\n",
+	} {
+		if !strings.Contains(got, s) {
+			t.Fatalf("Expected email to contain %q. Got %q\n", s, got)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// waNumber turn the stored phone into the international number expected by
+// the gateway, the local 0 prefix is replaced by the Indonesia code.
+func waNumber(phone string) string {
+	var b strings.Builder
+	for _, c := range phone {
+		if c >= '0' && c <= '9' {
+			b.WriteRune(c)
+		}
+	}
+
+	n := b.String()
+	if strings.HasPrefix(n, "0") {
+		n = "62" + n[1:]
+	}
+
+	return n
+}
+
+type whatsAppMessage struct {
+	Phone   string `json:"phone"`
+	Message string `json:"message"`
+}
+
+// WhatsAppSender post the notification as JSON `{"phone", "message"}` to the
+// WhatsApp HTTP gateway, any non 2xx response is a failed attempt.
+type WhatsAppSender struct {
+	Url    string
+	Token  string
+	Client *http.Client
+}
+
+func NewWhatsAppSender(url, token string) *WhatsAppSender {
+	return &WhatsAppSender{
+		Url:   url,
+		Token: token,
+		Client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+func (s *WhatsAppSender) Channel() Channel {
+	return WhatsApp
+}
+
+func (s *WhatsAppSender) Send(ctx context.Context, m OutboxModel) error {
+	text := "*" + m.Title + "*\n\n" + m.Body
+	if m.Link != "" {
+		text += "\n\n" + m.Link
+	}
+
+	payload, err := json.Marshal(whatsAppMessage{
+		Phone:   waNumber(m.Recipient),
+		Message: text,
+	})
+	if err != nil {
+		return errors.Wrap(err, "marshal whatsapp message")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.Url, bytes.NewReader(payload))
+	if err != nil {
+		return errors.Wrap(err, "new whatsapp request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.Token)
+	}
+
+	res, err := s.Client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "post whatsapp message")
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 512))
+		return fmt.Errorf("whatsapp gateway response %d: %s", res.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	return nil
+}
@@ -0,0 +1,80 @@
+package notifications_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/notifications"
+)
+
+func TestWhatsAppSender(t *testing.T) {
+	type message struct {
+		Phone   string `json:"phone"`
+		Message string `json:"message"`
+	}
+
+	var got message
+	var auth string
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		w.WriteHeader(status)
+		w.Write([]byte(`{"status":"ok"}`))
+	}))
+	defer srv.Close()
+
+	sender := notifications.NewWhatsAppSender(srv.URL, "secret")
+	m := notifications.OutboxModel{
+		Channel:   notifications.WhatsApp,
+		Recipient: "0821-1111-0000",
+		Title:     "Pembayaran iuran diterima",
+		Body:      "Pembayaran iuran anda telah diverifikasi.",
+		Link:      "/dues",
+	}
+
+	testCases := []struct {
+		Name          string
+		GatewayStatus int
+		ExpectedErr   bool
+	}{
+		{
+			Name:          "Send WhatsApp Success",
+			GatewayStatus: http.StatusOK,
+		},
+		{
+			Name:          "Send WhatsApp Fail, Gateway Error",
+			GatewayStatus: http.StatusBadGateway,
+			ExpectedErr:   true,
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.Name, func(t *testing.T) {
+			status = c.GatewayStatus
+
+			err := sender.Send(context.Background(), m)
+			if (err != nil) != c.ExpectedErr {
+				t.Fatalf("Expected error %t. Got %v\n", c.ExpectedErr, err)
+			}
+
+			if auth != "Bearer secret" {
+				t.Fatalf("Expected bearer token. Got %q\n", auth)
+			}
+			if got.Phone != "6282111110000" {
+				t.Fatalf("Expected international phone. Got %q\n", got.Phone)
+			}
+			if !strings.Contains(got.Message, m.Title) || !strings.Contains(got.Message, m.Body) {
+				t.Fatalf("Expected message with the title and body. Got %q\n", got.Message)
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package passhash
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+var (
+	ErrInvalidHash = errors.New("password hash is not a valid argon2id hash")
+	ErrMismatch    = errors.New("password does not match the hash")
+)
+
+// Params is the Argon2id cost of the new hashes, Memory is in KiB.
+type Params struct {
+	Time    uint32
+	Memory  uint32
+	Threads uint8
+	SaltLen uint32
+	KeyLen  uint32
+}
+
+// DefaultParams follow the second recommended option of RFC 9106.
+var DefaultParams = Params{
+	Time:    3,
+	Memory:  64 * 1024,
+	Threads: 4,
+	SaltLen: 16,
+	KeyLen:  32,
+}
+
+func (p Params) String() string {
+	return fmt.Sprintf("m=%d,t=%d,p=%d", p.Memory, p.Time, p.Threads)
+}
+
+// Hash return the PHC string of the password, e.g.
+// `$argon2id$v=19$m=65536,t=3,p=4$<salt>$<key>` where the salt and the key are
+// unpadded base64. Every hash has its own random salt.
+func Hash(password string, p Params) (string, error) {
+	salt := make([]byte, p.SaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(password), salt, p.Time, p.Memory, p.Threads, p.KeyLen)
+
+	return fmt.Sprintf("$argon2id$v=%d$%s$%s$%s",
+		argon2.Version,
+		p,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+type hash struct {
+	params Params
+	salt   string
+	key    string
+}
+
+func decode(encoded string) (h hash, err error) {
+	vals := strings.Split(encoded, "$")
+	if len(vals) != 6 || vals[0] != "" || vals[1] != "argon2id" {
+		return h, ErrInvalidHash
+	}
+
+	var version int
+	if _, err = fmt.Sscanf(vals[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return h, ErrInvalidHash
+	}
+
+	if _, err = fmt.Sscanf(vals[3], "m=%d,t=%d,p=%d", &h.params.Memory, &h.params.Time, &h.params.Threads); err != nil {
+		return h, ErrInvalidHash
+	}
+
+	h.salt = vals[4]
+	h.key = vals[5]
+
+	return h, nil
+}
+
+// legacy decode the salt and the key of the hash made with the global salt,
+// they were written as hex instead of base64. The key of the current hash is
+// never valid hex since its unpadded base64 has odd length.
+func (h hash) legacy() (salt, key []byte, ok bool) {
+	salt, err := hex.DecodeString(h.salt)
+	if err != nil || len(salt) == 0 {
+		return nil, nil, false
+	}
+
+	key, err = hex.DecodeString(h.key)
+	if err != nil || len(key) == 0 {
+		return nil, nil, false
+	}
+
+	return salt, key, true
+}
+
+// IsLegacy report whether the hash was made with the global salt.
+func IsLegacy(encoded string) bool {
+	h, err := decode(encoded)
+	if err != nil {
+		return false
+	}
+
+	_, _, ok := h.legacy()
+	return ok
+}
+
+// Verify check the password against the hash, the legacy hash is verified
+// with the global salt written in it.
+func Verify(encoded, password string) error {
+	h, err := decode(encoded)
+	if err != nil {
+		return err
+	}
+
+	salt, key, ok := h.legacy()
+	if !ok {
+		salt, err = base64.RawStdEncoding.DecodeString(h.salt)
+		if err != nil {
+			return ErrInvalidHash
+		}
+		key, err = base64.RawStdEncoding.DecodeString(h.key)
+	}
+	if err != nil || len(key) == 0 {
+		return ErrInvalidHash
+	}
+
+	other := argon2.IDKey([]byte(password), salt, h.params.Time, h.params.Memory, h.params.Threads, uint32(len(key)))
+	if subtle.ConstantTimeCompare(key, other) != 1 {
+		return ErrMismatch
+	}
+
+	return nil
+}
+
+// NeedsRehash report whether the hash should be replaced, either because it
+// was made with the global salt or with other cost than the given one.
+func NeedsRehash(encoded string, p Params) bool {
+	h, err := decode(encoded)
+	if err != nil {
+		return true
+	}
+
+	if _, _, ok := h.legacy(); ok {
+		return true
+	}
+
+	return h.params.Memory != p.Memory || h.params.Time != p.Time || h.params.Threads != p.Threads
+}
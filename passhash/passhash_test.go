@@ -0,0 +1,127 @@
+package passhash_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/passhash"
+	"github.com/fikryfahrezy/crypt/agron2"
+	"golang.org/x/crypto/argon2"
+)
+
+var (
+	testParams = passhash.Params{
+		Time:    1,
+		Memory:  8 * 1024,
+		Threads: 1,
+		SaltLen: 16,
+		KeyLen:  32,
+	}
+	legacySalt = "saltingmin8chars"
+	otherSalt  = "othersaltmin8chars"
+)
+
+func TestHash(t *testing.T) {
+	h1, err := passhash.Hash("password", testParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h2, err := passhash.Hash("password", testParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if h1 == h2 {
+		t.Fatal("Expected different hash of the same password")
+	}
+
+	if !strings.HasPrefix(h1, "$argon2id$v=19$m=8192,t=1,p=1$") {
+		t.Fatalf("Expected PHC string. Got %s\n", h1)
+	}
+
+	if passhash.IsLegacy(h1) {
+		t.Fatal("Expected not legacy hash")
+	}
+}
+
+func TestVerify(t *testing.T) {
+	current, err := passhash.Hash("password", testParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	legacy, err := agron2.Argon2Hash("password", legacySalt, 1, 8*1024, 1, 32, argon2.Version, agron2.Argon2Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	otherLegacy, err := agron2.Argon2Hash("password", otherSalt, 1, 8*1024, 1, 32, argon2.Version, agron2.Argon2Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name     string
+		encoded  string
+		password string
+		err      error
+	}{
+		{name: "Current Hash", encoded: current, password: "password"},
+		{name: "Legacy Hash", encoded: legacy, password: "password"},
+		{name: "Legacy Hash Of Other Salt", encoded: otherLegacy, password: "password"},
+		{name: "Current Hash Wrong Password", encoded: current, password: "wrongpassword", err: passhash.ErrMismatch},
+		{name: "Legacy Hash Wrong Password", encoded: legacy, password: "wrongpassword", err: passhash.ErrMismatch},
+		{name: "Not A Hash", encoded: "password", password: "password", err: passhash.ErrInvalidHash},
+		{name: "Other Algorithm", encoded: strings.Replace(current, "argon2id", "argon2i", 1), password: "password", err: passhash.ErrInvalidHash},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			if err := passhash.Verify(c.encoded, c.password); err != c.err {
+				t.Fatalf("Expected error %v. Got %v\n", c.err, err)
+			}
+		})
+	}
+}
+
+func TestNeedsRehash(t *testing.T) {
+	current, err := passhash.Hash("password", testParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	legacy, err := agron2.Argon2Hash("password", legacySalt, 1, 8*1024, 1, 32, argon2.Version, agron2.Argon2Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	otherLegacy, err := agron2.Argon2Hash("password", otherSalt, 1, 8*1024, 1, 32, argon2.Version, agron2.Argon2Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	costlier := testParams
+	costlier.Time = 2
+
+	testCases := []struct {
+		name     string
+		encoded  string
+		params   passhash.Params
+		expected bool
+	}{
+		{name: "Current Hash", encoded: current, params: testParams, expected: false},
+		{name: "Legacy Hash", encoded: legacy, params: testParams, expected: true},
+		{name: "Legacy Hash Of Other Salt", encoded: otherLegacy, params: testParams, expected: true},
+		{name: "Cost Changed", encoded: current, params: costlier, expected: true},
+		{name: "Not A Hash", encoded: "password", params: testParams, expected: true},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			if r := passhash.NeedsRehash(c.encoded, c.params); r != c.expected {
+				t.Fatalf("Expected rehash %t. Got %t\n", c.expected, r)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+
+	arbitary "github.com/PA-D3RPLA/d3if43-htt-uhomestay/arbitrary"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/dues"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// reconcileDues repair the member dues of the member approved or removed after
+// the dues is generated. The change is rolled back unless -commit is given, so
+// the result can be reviewed first.
+//
+// Usage: main reconcile-dues [-commit]
+func reconcileDues(pool *pgxpool.Pool, d *dues.DuesDeps, args []string) {
+	fs := flag.NewFlagSet("reconcile-dues", flag.ExitOnError)
+	commit := fs.Bool("commit", false, "commit the reconciliation instead of rolling it back")
+	fs.Parse(args)
+
+	tx, err := pool.Begin(context.Background())
+	if err != nil {
+		log.Printf("reconcile dues: begin transaction: %s", err)
+		return
+	}
+
+	defer tx.Rollback(context.Background())
+
+	ctx := context.WithValue(context.Background(), arbitary.TrxX{}, tx)
+	out := d.ReconcileMemberDues(ctx)
+	if out.Error != nil {
+		d.CaptureExeption(out.Error)
+		log.Printf("reconcile dues: %s", out.Error)
+		return
+	}
+
+	log.Printf("reconcile dues: created %d member dues, voided %d unpaid member dues of removed member", out.Res.Created, out.Res.Voided)
+
+	if !*commit {
+		log.Print("reconcile dues: dry run, nothing is changed, run with -commit to apply")
+		return
+	}
+
+	if err = tx.Commit(context.Background()); err != nil {
+		log.Printf("reconcile dues: commit transaction: %s", err)
+		return
+	}
+
+	log.Print("reconcile dues: committed")
+}
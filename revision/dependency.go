@@ -0,0 +1,72 @@
+package revision
+
+import (
+	"context"
+
+	"github.com/getsentry/sentry-go"
+)
+
+type (
+	ExceptionCapturer func(exception error)
+	MessageCapturer   func(message string)
+)
+
+// Revision is the saved version of the entity. Content hold every versioned
+// field of the entity and ContentText is the plain text compared between
+// revisions.
+type Revision struct {
+	Entity      string
+	EntityId    uint64
+	Content     interface{}
+	ContentText string
+}
+
+// Recorder save the revision in the transaction of the context, so the
+// revision is rolled back along with the change.
+type Recorder func(ctx context.Context, r Revision) error
+
+// Record run the recorder, the nil recorder record nothing.
+func (r Recorder) Record(ctx context.Context, rev Revision) error {
+	if r == nil {
+		return nil
+	}
+
+	return r(ctx, rev)
+}
+
+// Restorer bring the entity back to the content of the revision, it is given
+// by the package owning the entity. pgx.ErrNoRows is returned when the entity
+// no longer exist.
+type Restorer func(ctx context.Context, rev RevisionModel) error
+
+type RevisionDeps struct {
+	CaptureMessage     MessageCapturer
+	CaptureExeption    ExceptionCapturer
+	Restorers          map[string]Restorer
+	RevisionRepository *RevisionRepository
+}
+
+func NewDeps(
+	captureMessage MessageCapturer,
+	captureExeption ExceptionCapturer,
+	revisionRepository *RevisionRepository,
+) *RevisionDeps {
+	return &RevisionDeps{
+		CaptureMessage:     captureMessage,
+		CaptureExeption:    captureExeption,
+		Restorers:          make(map[string]Restorer),
+		RevisionRepository: revisionRepository,
+	}
+}
+
+func CaptureExeption(capture func(exception error) *sentry.EventID) ExceptionCapturer {
+	return func(exception error) {
+		capture(exception)
+	}
+}
+
+func CaptureMessage(capture func(message string) *sentry.EventID) MessageCapturer {
+	return func(message string) {
+		capture(message)
+	}
+}
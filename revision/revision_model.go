@@ -0,0 +1,37 @@
+package revision
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+)
+
+// Entities having revisions.
+const (
+	Blog    = "blog"
+	History = "history"
+	Goal    = "goal"
+)
+
+type RevisionModel struct {
+	Id           uint64
+	Entity       string
+	EntityId     uint64
+	AuthorId     sql.NullString
+	AuthorName   sql.NullString
+	RestoredFrom sql.NullInt64
+	Content      map[string]interface{}
+	ContentText  string
+	CreatedAt    time.Time
+}
+
+// Decode fill v with the content of the revision, v is the same type the
+// revision was recorded with.
+func (m RevisionModel) Decode(v interface{}) error {
+	byt, err := json.Marshal(m.Content)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(byt, v)
+}
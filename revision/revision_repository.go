@@ -0,0 +1,171 @@
+package revision
+
+import (
+	"context"
+	"time"
+
+	arbitary "github.com/PA-D3RPLA/d3if43-htt-uhomestay/arbitrary"
+	"github.com/georgysavva/scany/pgxscan"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+type RevisionRepository struct {
+	PostgreDb *pgxpool.Pool
+}
+
+func NewRevisionRepository(postgreDb *pgxpool.Pool) *RevisionRepository {
+	return &RevisionRepository{
+		PostgreDb: postgreDb,
+	}
+}
+
+type (
+	RevisionQuerierRow func(ctx context.Context, sql string, args ...interface{}) pgx.Row
+	RevisionQuerier    func(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+)
+
+func (r *RevisionRepository) Save(ctx context.Context, m RevisionModel) (nm RevisionModel, err error) {
+	sqlQuery := `
+		INSERT INTO revisions (
+			entity,
+			entity_id,
+			author_id,
+			restored_from,
+			content,
+			content_text,
+			created_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`
+
+	var queryRow RevisionQuerierRow
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		queryRow = tx.QueryRow
+	} else {
+		queryRow = r.PostgreDb.QueryRow
+	}
+
+	t := time.Now()
+	err = queryRow(
+		context.Background(),
+		sqlQuery,
+		m.Entity,
+		m.EntityId,
+		m.AuthorId,
+		m.RestoredFrom,
+		m.Content,
+		m.ContentText,
+		t,
+	).Scan(&m.Id)
+
+	if err != nil {
+		return RevisionModel{}, err
+	}
+
+	m.CreatedAt = t
+
+	return m, nil
+}
+
+// Query return the revisions of the entity from the latest, without content.
+func (r *RevisionRepository) Query(ctx context.Context, entity string, entityId, fromId uint64, limit int64) ([]RevisionModel, error) {
+	sqlQuery := `
+		SELECT
+			r.id,
+			r.entity,
+			r.entity_id,
+			r.author_id,
+			m.name AS author_name,
+			r.restored_from,
+			r.content_text,
+			r.created_at
+		FROM revisions r
+		LEFT JOIN members m ON m.id = r.author_id
+		WHERE r.entity = $1
+		AND r.entity_id = $2
+		AND ($3::BIGINT = 0 OR r.id < $3::BIGINT)
+		ORDER BY r.id DESC
+		LIMIT $4
+	`
+
+	var query RevisionQuerier
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		query = tx.Query
+	} else {
+		query = r.PostgreDb.Query
+	}
+
+	rows, err := query(
+		context.Background(),
+		sqlQuery,
+		entity,
+		entityId,
+		fromId,
+		limit,
+	)
+	if err != nil {
+		return []RevisionModel{}, err
+	}
+	defer rows.Close()
+
+	var mps []*RevisionModel
+	if err := pgxscan.ScanAll(&mps, rows); err != nil {
+		return []RevisionModel{}, err
+	}
+
+	ms := make([]RevisionModel, len(mps))
+	for i, m := range mps {
+		ms[i] = *m
+	}
+
+	return ms, nil
+}
+
+func (r *RevisionRepository) FindById(ctx context.Context, entity string, entityId, id uint64) (m RevisionModel, err error) {
+	sqlQuery := `
+		SELECT
+			r.id,
+			r.entity,
+			r.entity_id,
+			r.author_id,
+			m.name AS author_name,
+			r.restored_from,
+			r.content,
+			r.content_text,
+			r.created_at
+		FROM revisions r
+		LEFT JOIN members m ON m.id = r.author_id
+		WHERE r.entity = $1
+		AND r.entity_id = $2
+		AND r.id = $3
+	`
+
+	var query RevisionQuerier
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		query = tx.Query
+	} else {
+		query = r.PostgreDb.Query
+	}
+
+	rows, err := query(
+		context.Background(),
+		sqlQuery,
+		entity,
+		entityId,
+		id,
+	)
+	if err != nil {
+		return RevisionModel{}, err
+	}
+
+	if err = pgxscan.ScanOne(&m, rows); err != nil {
+		return RevisionModel{}, err
+	}
+
+	return m, nil
+}
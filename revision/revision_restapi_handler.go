@@ -0,0 +1,65 @@
+package revision
+
+import (
+	"net/http"
+
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/resp"
+	"github.com/go-chi/chi/v5"
+)
+
+// The handlers serve the revisions of the entity given to them. The entity id
+// is read from the id url param, the history page has no id and use zero.
+
+func entityIdParam(r *http.Request) string {
+	if id := chi.URLParam(r, "id"); id != "" {
+		return id
+	}
+
+	return "0"
+}
+
+func (d *RevisionDeps) GetRevisions(entity string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		cursor := r.URL.Query().Get("cursor")
+		limit := r.URL.Query().Get("limit")
+		out := d.QueryRevision(r.Context(), entity, entityIdParam(r), cursor, limit)
+		if out.Error != nil {
+			d.CaptureExeption(out.Error)
+		}
+		out.HttpJSON(w, resp.NewHttpBody(out.Res))
+	}
+}
+
+func (d *RevisionDeps) GetRevision(entity string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ridParam := chi.URLParam(r, "rid")
+		out := d.FindRevision(r.Context(), entity, entityIdParam(r), ridParam)
+		if out.Error != nil {
+			d.CaptureExeption(out.Error)
+		}
+		out.HttpJSON(w, resp.NewHttpBody(out.Res))
+	}
+}
+
+func (d *RevisionDeps) GetRevisionDiff(entity string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		from := r.URL.Query().Get("from")
+		to := r.URL.Query().Get("to")
+		out := d.DiffRevision(r.Context(), entity, entityIdParam(r), from, to)
+		if out.Error != nil {
+			d.CaptureExeption(out.Error)
+		}
+		out.HttpJSON(w, resp.NewHttpBody(out.Res))
+	}
+}
+
+func (d *RevisionDeps) PostRevisionRestore(entity string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ridParam := chi.URLParam(r, "rid")
+		out := d.RestoreRevision(r.Context(), entity, entityIdParam(r), ridParam)
+		if out.Error != nil {
+			d.CaptureExeption(out.Error)
+		}
+		out.HttpJSON(w, resp.NewHttpBody(out.Res))
+	}
+}
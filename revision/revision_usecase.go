@@ -0,0 +1,288 @@
+package revision
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	arbitary "github.com/PA-D3RPLA/d3if43-htt-uhomestay/arbitrary"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/pagination"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/resp"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/textdiff"
+	"github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrRevisionNotFound = errors.New("revisi tidak ditemukan")
+	ErrEntityNotFound   = errors.New("data tidak ditemukan")
+)
+
+// RecordRevision save the revision with the member of the request as the
+// author.
+func (d *RevisionDeps) RecordRevision(ctx context.Context, rev Revision) error {
+	byt, err := json.Marshal(rev.Content)
+	if err != nil {
+		return errors.Wrap(err, "marshal content")
+	}
+
+	var content map[string]interface{}
+	if err = json.Unmarshal(byt, &content); err != nil {
+		return errors.Wrap(err, "unmarshal content")
+	}
+
+	_, err = d.RevisionRepository.Save(ctx, RevisionModel{
+		Entity:      rev.Entity,
+		EntityId:    rev.EntityId,
+		AuthorId:    author(ctx),
+		Content:     content,
+		ContentText: rev.ContentText,
+	})
+	if err != nil {
+		return errors.Wrap(err, "save revision")
+	}
+
+	return nil
+}
+
+func author(ctx context.Context) sql.NullString {
+	if uid, ok := ctx.Value(arbitary.ActorX{}).(string); ok && uid != "" {
+		return sql.NullString{String: uid, Valid: true}
+	}
+
+	return sql.NullString{}
+}
+
+type (
+	RevisionOut struct {
+		Id           uint64 `json:"id"`
+		AuthorId     string `json:"author_id"`
+		AuthorName   string `json:"author_name"`
+		RestoredFrom uint64 `json:"restored_from"`
+		CreatedAt    string `json:"created_at"`
+	}
+	QueryRevisionRes struct {
+		Cursor    string        `json:"cursor"`
+		Revisions []RevisionOut `json:"revisions"`
+	}
+	QueryRevisionOut struct {
+		resp.Response
+		Res QueryRevisionRes
+	}
+)
+
+func revisionOut(m RevisionModel) RevisionOut {
+	return RevisionOut{
+		Id:           m.Id,
+		AuthorId:     m.AuthorId.String,
+		AuthorName:   m.AuthorName.String,
+		RestoredFrom: uint64(m.RestoredFrom.Int64),
+		CreatedAt:    m.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+// QueryRevision return the revisions of the entity from the latest.
+func (d *RevisionDeps) QueryRevision(ctx context.Context, entity, pid, cursor, limit string) (out QueryRevisionOut) {
+	var err error
+	out.Response = resp.NewResponse(http.StatusOK, "", nil)
+
+	entityId, err := strconv.ParseUint(pid, 10, 64)
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusNotFound, "", ErrEntityNotFound)
+		return
+	}
+
+	s, _, err := pagination.DecodeSIDCursor(cursor)
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "decode sid cursor"))
+		return
+	}
+
+	fromId, _ := strconv.ParseUint(s, 10, 64)
+	nlimit, _ := strconv.ParseInt(limit, 10, 64)
+	if nlimit <= 0 {
+		nlimit = 25
+	}
+
+	ms, err := d.RevisionRepository.Query(ctx, entity, entityId, fromId, nlimit)
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "query revisions"))
+		return
+	}
+
+	mLen := len(ms)
+
+	var nextCursor string
+	if mLen != 0 {
+		m := ms[mLen-1]
+		nextCursor = pagination.EncodeSIDCursor(strconv.FormatUint(m.Id, 10), m.CreatedAt)
+	}
+
+	outRevisions := make([]RevisionOut, mLen)
+	for i, m := range ms {
+		outRevisions[i] = revisionOut(m)
+	}
+
+	out.Res = QueryRevisionRes{
+		Cursor:    nextCursor,
+		Revisions: outRevisions,
+	}
+
+	return
+}
+
+func (d *RevisionDeps) findRevision(ctx context.Context, entity, pid, prid string) (m RevisionModel, res resp.Response) {
+	entityId, err := strconv.ParseUint(pid, 10, 64)
+	if err != nil {
+		return RevisionModel{}, resp.NewResponse(http.StatusNotFound, "", ErrRevisionNotFound)
+	}
+
+	id, err := strconv.ParseUint(prid, 10, 64)
+	if err != nil {
+		return RevisionModel{}, resp.NewResponse(http.StatusNotFound, "", ErrRevisionNotFound)
+	}
+
+	m, err = d.RevisionRepository.FindById(ctx, entity, entityId, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return RevisionModel{}, resp.NewResponse(http.StatusNotFound, "", ErrRevisionNotFound)
+	}
+	if err != nil {
+		return RevisionModel{}, resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "find revision by id"))
+	}
+
+	return m, resp.NewResponse(http.StatusOK, "", nil)
+}
+
+type (
+	FindRevisionRes struct {
+		RevisionOut
+		Content     map[string]interface{} `json:"content"`
+		ContentText string                 `json:"content_text"`
+	}
+	FindRevisionOut struct {
+		resp.Response
+		Res FindRevisionRes
+	}
+)
+
+func (d *RevisionDeps) FindRevision(ctx context.Context, entity, pid, prid string) (out FindRevisionOut) {
+	m, res := d.findRevision(ctx, entity, pid, prid)
+	out.Response = res
+	if res.Error != nil {
+		return
+	}
+
+	out.Res = FindRevisionRes{
+		RevisionOut: revisionOut(m),
+		Content:     m.Content,
+		ContentText: m.ContentText,
+	}
+
+	return
+}
+
+type (
+	DiffLineOut struct {
+		Op   string `json:"op"`
+		Text string `json:"text"`
+	}
+	DiffRevisionRes struct {
+		From  RevisionOut   `json:"from"`
+		To    RevisionOut   `json:"to"`
+		Lines []DiffLineOut `json:"lines"`
+	}
+	DiffRevisionOut struct {
+		resp.Response
+		Res DiffRevisionRes
+	}
+)
+
+// DiffRevision return the line by line difference of the content text to turn
+// the from revision into the to revision.
+func (d *RevisionDeps) DiffRevision(ctx context.Context, entity, pid, from, to string) (out DiffRevisionOut) {
+	fm, res := d.findRevision(ctx, entity, pid, from)
+	out.Response = res
+	if res.Error != nil {
+		return
+	}
+
+	tm, res := d.findRevision(ctx, entity, pid, to)
+	out.Response = res
+	if res.Error != nil {
+		return
+	}
+
+	lines := textdiff.Lines(fm.ContentText, tm.ContentText)
+
+	outLines := make([]DiffLineOut, len(lines))
+	for i, l := range lines {
+		outLines[i] = DiffLineOut{
+			Op:   string(l.Op),
+			Text: l.Text,
+		}
+	}
+
+	out.Res = DiffRevisionRes{
+		From:  revisionOut(fm),
+		To:    revisionOut(tm),
+		Lines: outLines,
+	}
+
+	return
+}
+
+type (
+	RestoreRevisionRes struct {
+		Id uint64 `json:"id"`
+	}
+	RestoreRevisionOut struct {
+		resp.Response
+		Res RestoreRevisionRes
+	}
+)
+
+// RestoreRevision bring the entity back to the revision, the restore is saved
+// as the latest revision so it can be undone the same way.
+func (d *RevisionDeps) RestoreRevision(ctx context.Context, entity, pid, prid string) (out RestoreRevisionOut) {
+	m, res := d.findRevision(ctx, entity, pid, prid)
+	out.Response = res
+	if res.Error != nil {
+		return
+	}
+
+	restore, ok := d.Restorers[entity]
+	if !ok {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.New("no restorer of "+entity))
+		return
+	}
+
+	err := restore(ctx, m)
+	if errors.Is(err, pgx.ErrNoRows) {
+		out.Response = resp.NewResponse(http.StatusNotFound, "", ErrEntityNotFound)
+		return
+	}
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "restore "+entity))
+		return
+	}
+
+	restored, err := d.RevisionRepository.Save(ctx, RevisionModel{
+		Entity:       m.Entity,
+		EntityId:     m.EntityId,
+		AuthorId:     author(ctx),
+		RestoredFrom: sql.NullInt64{Int64: int64(m.Id), Valid: true},
+		Content:      m.Content,
+		ContentText:  m.ContentText,
+	})
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "save revision"))
+		return
+	}
+
+	out.Res.Id = restored.Id
+
+	return
+}
@@ -0,0 +1,349 @@
+package revision_test
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+
+	arbitary "github.com/PA-D3RPLA/d3if43-htt-uhomestay/arbitrary"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/revision"
+	"github.com/jackc/pgx/v4"
+)
+
+const (
+	authorId      = "6f2e8a3c-1b7d-4c5e-9a0f-2d3b4c5d6e7f"
+	goneEntityId  = 99
+	blogEntityId  = 1
+	blogEntityPid = "1"
+)
+
+type blogSeed struct {
+	Title string `json:"title"`
+}
+
+// restored keep the revisions given to the stub restorer.
+var restored []revision.RevisionModel
+
+func stubRestorer(ctx context.Context, rev revision.RevisionModel) error {
+	if rev.EntityId == goneEntityId {
+		return pgx.ErrNoRows
+	}
+
+	restored = append(restored, rev)
+
+	return nil
+}
+
+func seedRevisions(t *testing.T) []uint64 {
+	ctx := context.WithValue(context.Background(), arbitary.ActorX{}, authorId)
+
+	revs := []revision.Revision{
+		{
+			Entity:      revision.Blog,
+			EntityId:    blogEntityId,
+			Content:     blogSeed{Title: "Judul"},
+			ContentText: "paragraf satu\nparagraf dua",
+		},
+		{
+			Entity:      revision.Blog,
+			EntityId:    blogEntityId,
+			Content:     blogSeed{Title: "Judul Baru"},
+			ContentText: "paragraf satu\nparagraf tiga",
+		},
+		{
+			Entity:      revision.Blog,
+			EntityId:    goneEntityId,
+			Content:     blogSeed{Title: "Terhapus"},
+			ContentText: "terhapus",
+		},
+		{
+			Entity:      revision.History,
+			ContentText: "sejarah",
+		},
+	}
+
+	for _, r := range revs {
+		if err := revisionDeps.RecordRevision(ctx, r); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	res := revisionDeps.QueryRevision(context.Background(), revision.Blog, blogEntityPid, "", "")
+	if res.Error != nil {
+		t.Fatal(res.Error)
+	}
+
+	ids := make([]uint64, len(res.Res.Revisions))
+	for i, r := range res.Res.Revisions {
+		ids[i] = r.Id
+	}
+
+	return ids
+}
+
+func TestQueryRevision(t *testing.T) {
+	err := ClearTables(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	seedRevisions(t)
+
+	testCases := []struct {
+		Name               string
+		ExpectedStatusCode int
+		ExpectedTotal      int
+		Entity             string
+		Pid                string
+		Limit              string
+	}{
+		{
+			Name:               "Query Revision Success",
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedTotal:      2,
+			Entity:             revision.Blog,
+			Pid:                blogEntityPid,
+		},
+		{
+			Name:               "Query Revision Success, Limit",
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedTotal:      1,
+			Entity:             revision.Blog,
+			Pid:                blogEntityPid,
+			Limit:              "1",
+		},
+		{
+			Name:               "Query Revision Success, History",
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedTotal:      1,
+			Entity:             revision.History,
+			Pid:                "0",
+		},
+		{
+			Name:               "Query Revision Success, No Revision",
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedTotal:      0,
+			Entity:             revision.Goal,
+			Pid:                blogEntityPid,
+		},
+		{
+			Name:               "Query Revision Fail, Invalid Id",
+			ExpectedStatusCode: http.StatusNotFound,
+			Entity:             revision.Blog,
+			Pid:                "abc",
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.Name, func(t *testing.T) {
+			res := revisionDeps.QueryRevision(context.Background(), c.Entity, c.Pid, "", c.Limit)
+
+			if res.StatusCode != c.ExpectedStatusCode {
+				t.Logf("%#v", res)
+				t.Fatalf("Expected response code %d. Got %d\n", c.ExpectedStatusCode, res.StatusCode)
+			}
+
+			if len(res.Res.Revisions) != c.ExpectedTotal {
+				t.Fatalf("Expected %d revisions. Got %d\n", c.ExpectedTotal, len(res.Res.Revisions))
+			}
+		})
+	}
+
+	t.Run("Query Revision Success, Latest First With Author", func(t *testing.T) {
+		res := revisionDeps.QueryRevision(context.Background(), revision.Blog, blogEntityPid, "", "")
+		if res.Error != nil {
+			t.Fatal(res.Error)
+		}
+
+		if res.Res.Revisions[0].Id <= res.Res.Revisions[1].Id {
+			t.Fatalf("Expected latest revision first. Got %d before %d\n", res.Res.Revisions[0].Id, res.Res.Revisions[1].Id)
+		}
+
+		if res.Res.Revisions[0].AuthorId != authorId {
+			t.Fatalf("Expected author %s. Got %s\n", authorId, res.Res.Revisions[0].AuthorId)
+		}
+	})
+}
+
+func TestFindRevision(t *testing.T) {
+	err := ClearTables(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ids := seedRevisions(t)
+	latest := strconv.FormatUint(ids[0], 10)
+
+	testCases := []struct {
+		Name               string
+		ExpectedStatusCode int
+		Pid                string
+		Rid                string
+	}{
+		{
+			Name:               "Find Revision Success",
+			ExpectedStatusCode: http.StatusOK,
+			Pid:                blogEntityPid,
+			Rid:                latest,
+		},
+		{
+			Name:               "Find Revision Fail, Other Entity",
+			ExpectedStatusCode: http.StatusNotFound,
+			Pid:                strconv.Itoa(goneEntityId),
+			Rid:                latest,
+		},
+		{
+			Name:               "Find Revision Fail, Not Found",
+			ExpectedStatusCode: http.StatusNotFound,
+			Pid:                blogEntityPid,
+			Rid:                "999999",
+		},
+		{
+			Name:               "Find Revision Fail, Invalid Id",
+			ExpectedStatusCode: http.StatusNotFound,
+			Pid:                blogEntityPid,
+			Rid:                "abc",
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.Name, func(t *testing.T) {
+			res := revisionDeps.FindRevision(context.Background(), revision.Blog, c.Pid, c.Rid)
+
+			if res.StatusCode != c.ExpectedStatusCode {
+				t.Logf("%#v", res)
+				t.Fatalf("Expected response code %d. Got %d\n", c.ExpectedStatusCode, res.StatusCode)
+			}
+		})
+	}
+
+	t.Run("Find Revision Success, Content", func(t *testing.T) {
+		res := revisionDeps.FindRevision(context.Background(), revision.Blog, blogEntityPid, latest)
+		if res.Error != nil {
+			t.Fatal(res.Error)
+		}
+
+		if res.Res.Content["title"] != "Judul Baru" {
+			t.Fatalf("Expected title %s. Got %v\n", "Judul Baru", res.Res.Content["title"])
+		}
+	})
+}
+
+func TestDiffRevision(t *testing.T) {
+	err := ClearTables(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ids := seedRevisions(t)
+
+	res := revisionDeps.DiffRevision(
+		context.Background(),
+		revision.Blog,
+		blogEntityPid,
+		strconv.FormatUint(ids[1], 10),
+		strconv.FormatUint(ids[0], 10),
+	)
+	if res.Error != nil {
+		t.Fatal(res.Error)
+	}
+
+	expected := []revision.DiffLineOut{
+		{Op: "equal", Text: "paragraf satu"},
+		{Op: "delete", Text: "paragraf dua"},
+		{Op: "insert", Text: "paragraf tiga"},
+	}
+
+	if len(res.Res.Lines) != len(expected) {
+		t.Fatalf("Expected %d lines. Got %d\n", len(expected), len(res.Res.Lines))
+	}
+
+	for i, l := range expected {
+		if res.Res.Lines[i] != l {
+			t.Fatalf("Expected line %d %v. Got %v\n", i, l, res.Res.Lines[i])
+		}
+	}
+
+	t.Run("Diff Revision Fail, Not Found", func(t *testing.T) {
+		res := revisionDeps.DiffRevision(context.Background(), revision.Blog, blogEntityPid, "999999", strconv.FormatUint(ids[0], 10))
+
+		if res.StatusCode != http.StatusNotFound {
+			t.Fatalf("Expected response code %d. Got %d\n", http.StatusNotFound, res.StatusCode)
+		}
+	})
+}
+
+func TestRestoreRevision(t *testing.T) {
+	err := ClearTables(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ids := seedRevisions(t)
+	first := strconv.FormatUint(ids[1], 10)
+
+	revisionDeps.Restorers[revision.Blog] = stubRestorer
+	restored = nil
+
+	gone := revisionDeps.QueryRevision(context.Background(), revision.Blog, strconv.Itoa(goneEntityId), "", "")
+	if gone.Error != nil {
+		t.Fatal(gone.Error)
+	}
+
+	testCases := []struct {
+		Name               string
+		ExpectedStatusCode int
+		Pid                string
+		Rid                string
+	}{
+		{
+			Name:               "Restore Revision Success",
+			ExpectedStatusCode: http.StatusOK,
+			Pid:                blogEntityPid,
+			Rid:                first,
+		},
+		{
+			Name:               "Restore Revision Fail, Revision Not Found",
+			ExpectedStatusCode: http.StatusNotFound,
+			Pid:                blogEntityPid,
+			Rid:                "999999",
+		},
+		{
+			Name:               "Restore Revision Fail, Entity Not Found",
+			ExpectedStatusCode: http.StatusNotFound,
+			Pid:                strconv.Itoa(goneEntityId),
+			Rid:                strconv.FormatUint(gone.Res.Revisions[0].Id, 10),
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.Name, func(t *testing.T) {
+			res := revisionDeps.RestoreRevision(context.Background(), revision.Blog, c.Pid, c.Rid)
+
+			if res.StatusCode != c.ExpectedStatusCode {
+				t.Logf("%#v", res)
+				t.Fatalf("Expected response code %d. Got %d\n", c.ExpectedStatusCode, res.StatusCode)
+			}
+		})
+	}
+
+	t.Run("Restore Revision Success, Saved As Latest", func(t *testing.T) {
+		if len(restored) != 1 || restored[0].Id != ids[1] {
+			t.Fatalf("Expected revision %d given to the restorer. Got %v\n", ids[1], restored)
+		}
+
+		res := revisionDeps.QueryRevision(context.Background(), revision.Blog, blogEntityPid, "", "")
+		if res.Error != nil {
+			t.Fatal(res.Error)
+		}
+
+		if len(res.Res.Revisions) != 3 {
+			t.Fatalf("Expected %d revisions. Got %d\n", 3, len(res.Res.Revisions))
+		}
+
+		if res.Res.Revisions[0].RestoredFrom != ids[1] {
+			t.Fatalf("Expected restored from %d. Got %d\n", ids[1], res.Res.Revisions[0].RestoredFrom)
+		}
+	})
+}
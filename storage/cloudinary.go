@@ -0,0 +1,251 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/cloudinary/cloudinary-go/api"
+	"github.com/cloudinary/cloudinary-go/api/admin"
+	"github.com/cloudinary/cloudinary-go/api/uploader"
+)
+
+var ErrNotCloudinaryUrl = errors.New("url is not a cloudinary delivery url")
+
+var cldVersionRe = regexp.MustCompile(`^v[0-9]+$`)
+
+type CloudinaryUploader interface {
+	Upload(ctx context.Context, file interface{}, uploadParams uploader.UploadParams) (*uploader.UploadResult, error)
+	Rename(ctx context.Context, params uploader.RenameParams) (*uploader.RenameResult, error)
+	Destroy(ctx context.Context, params uploader.DestroyParams) (*uploader.DestroyResult, error)
+	PrivateDownloadUrl(params uploader.PrivateDownloadUrlParams) (string, error)
+}
+
+type CloudinaryAdmin interface {
+	Assets(ctx context.Context, params admin.AssetsParams) (*admin.AssetsResult, error)
+}
+
+// CloudinaryStorage upload every object with the same upload params, so the
+// tags, transformation, resource type and delivery type are set per storage.
+type CloudinaryStorage struct {
+	Uploader     CloudinaryUploader
+	Admin        CloudinaryAdmin
+	UploadParams uploader.UploadParams
+}
+
+func NewCloudinary(cldUploader CloudinaryUploader, cldAdmin CloudinaryAdmin, uploadParams uploader.UploadParams) *CloudinaryStorage {
+	return &CloudinaryStorage{
+		Uploader:     cldUploader,
+		Admin:        cldAdmin,
+		UploadParams: uploadParams,
+	}
+}
+
+// publicId return the public id of the key, only raw asset keep its extension
+// since cloudinary append the delivered format to other asset.
+func (s *CloudinaryStorage) publicId(key string) string {
+	key = strings.TrimLeft(key, "/")
+	if s.UploadParams.ResourceType == api.File {
+		return key
+	}
+
+	return strings.TrimSuffix(key, path.Ext(key))
+}
+
+func (s *CloudinaryStorage) Put(ctx context.Context, key string, file io.Reader) (string, error) {
+	params := s.UploadParams
+	params.Folder = ""
+	params.PublicID = s.publicId(key)
+
+	res, err := s.Uploader.Upload(ctx, file, params)
+	if err != nil {
+		return "", err
+	}
+	if res.Error.Message != "" {
+		return "", errors.New(res.Error.Message)
+	}
+
+	return res.SecureURL, nil
+}
+
+func (s *CloudinaryStorage) Move(ctx context.Context, fromKey, toKey string) (string, error) {
+	res, err := s.Uploader.Rename(ctx, uploader.RenameParams{
+		FromPublicID: s.publicId(fromKey),
+		ToPublicID:   s.publicId(toKey),
+		Type:         string(s.UploadParams.Type),
+		ResourceType: s.UploadParams.ResourceType,
+	})
+	if err != nil {
+		return "", err
+	}
+	if res.Error != nil {
+		return "", ErrObjectNotFound
+	}
+
+	return res.SecureURL, nil
+}
+
+func (s *CloudinaryStorage) Delete(ctx context.Context, fileUrl string) error {
+	asset, err := ParseCloudinaryUrl(fileUrl)
+	if err != nil {
+		return err
+	}
+
+	res, err := s.Uploader.Destroy(ctx, uploader.DestroyParams{
+		PublicID:     asset.PublicId,
+		Type:         asset.DeliveryType,
+		ResourceType: asset.ResourceType,
+		Invalidate:   true,
+	})
+	if err != nil {
+		return err
+	}
+	if res.Error.Message != "" {
+		return errors.New(res.Error.Message)
+	}
+	if res.Result == "not found" {
+		return ErrObjectNotFound
+	}
+
+	return nil
+}
+
+func (s *CloudinaryStorage) SignUrl(ctx context.Context, fileUrl string, expiry time.Duration) (string, error) {
+	asset, err := ParseCloudinaryUrl(fileUrl)
+	if err != nil {
+		return "", err
+	}
+
+	expiresAt := time.Now().Add(expiry)
+
+	return s.Uploader.PrivateDownloadUrl(uploader.PrivateDownloadUrlParams{
+		PublicID:     asset.PublicId,
+		Format:       asset.Format,
+		DeliveryType: asset.DeliveryType,
+		ExpiresAt:    &expiresAt,
+		ResourceType: api.AssetType(asset.ResourceType),
+	})
+}
+
+func (s *CloudinaryStorage) resourceType() string {
+	if s.UploadParams.ResourceType == "" {
+		return string(api.Image)
+	}
+
+	return s.UploadParams.ResourceType
+}
+
+func (s *CloudinaryStorage) deliveryType() string {
+	if s.UploadParams.Type == "" {
+		return string(api.Upload)
+	}
+
+	return string(s.UploadParams.Type)
+}
+
+// List only the asset with the same resource and delivery type of the
+// storage, since cloudinary list them separately.
+func (s *CloudinaryStorage) List(ctx context.Context, prefix string, fn func(obj Object) error) error {
+	params := admin.AssetsParams{
+		AssetType:    api.AssetType(s.resourceType()),
+		DeliveryType: s.deliveryType(),
+		Prefix:       strings.TrimLeft(prefix, "/"),
+		MaxResults:   500,
+	}
+
+	for {
+		res, err := s.Admin.Assets(ctx, params)
+		if err != nil {
+			return err
+		}
+		if res.Error.Message != "" {
+			return errors.New(res.Error.Message)
+		}
+
+		for _, a := range res.Assets {
+			err = fn(Object{
+				Id:        a.AssetType + "/" + a.Type + "/" + a.PublicID,
+				Url:       a.SecureURL,
+				UpdatedAt: a.CreatedAt,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		if res.NextCursor == "" {
+			return nil
+		}
+		params.NextCursor = res.NextCursor
+	}
+}
+
+// ObjectId is formatted as `<resource type>/<delivery type>/<public id>`, the
+// public id alone is not unique across them.
+func (s *CloudinaryStorage) ObjectId(fileUrl string) (string, error) {
+	asset, err := ParseCloudinaryUrl(fileUrl)
+	if err != nil {
+		return "", err
+	}
+
+	return asset.ResourceType + "/" + asset.DeliveryType + "/" + asset.PublicId, nil
+}
+
+type CloudinaryAsset struct {
+	ResourceType string
+	DeliveryType string
+	PublicId     string
+	Format       string
+}
+
+// ParseCloudinaryUrl extract the asset information from cloudinary delivery url, formatted as
+// `https://res.cloudinary.com/<cloud>/<resource type>/<delivery type>/[<transformations>/][v<version>/]<public id>[.<format>]`
+func ParseCloudinaryUrl(rawUrl string) (CloudinaryAsset, error) {
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		return CloudinaryAsset{}, err
+	}
+
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(segments) < 4 {
+		return CloudinaryAsset{}, ErrNotCloudinaryUrl
+	}
+
+	asset := CloudinaryAsset{
+		ResourceType: segments[1],
+		DeliveryType: segments[2],
+	}
+
+	rest := segments[3:]
+	for i, s := range rest {
+		if cldVersionRe.MatchString(s) {
+			rest = rest[i+1:]
+			break
+		}
+	}
+
+	if len(rest) == 0 {
+		return CloudinaryAsset{}, ErrNotCloudinaryUrl
+	}
+
+	publicId, err := url.PathUnescape(strings.Join(rest, "/"))
+	if err != nil {
+		return CloudinaryAsset{}, err
+	}
+
+	// Raw asset keep its extension as part of the public id.
+	if asset.ResourceType != api.File {
+		if ext := path.Ext(publicId); ext != "" {
+			asset.Format = strings.TrimPrefix(ext, ".")
+			publicId = strings.TrimSuffix(publicId, ext)
+		}
+	}
+	asset.PublicId = publicId
+
+	return asset, nil
+}
@@ -0,0 +1,69 @@
+package storage_test
+
+import (
+	"testing"
+
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/storage"
+)
+
+func TestParseCloudinaryUrl(t *testing.T) {
+	testCases := []struct {
+		name     string
+		url      string
+		expected storage.CloudinaryAsset
+		isErr    bool
+	}{
+		{
+			name: "Raw Asset With Version",
+			url:  "https://res.cloudinary.com/demo/raw/upload/v1650000000/uhomestay/dues/1650000000-proof.pdf",
+			expected: storage.CloudinaryAsset{
+				ResourceType: "raw",
+				DeliveryType: "upload",
+				PublicId:     "uhomestay/dues/1650000000-proof.pdf",
+			},
+		},
+		{
+			name: "Image Asset With Transformation",
+			url:  "https://res.cloudinary.com/demo/image/private/c_crop,g_center/v1/uhomestay/profile/pic.jpg",
+			expected: storage.CloudinaryAsset{
+				ResourceType: "image",
+				DeliveryType: "private",
+				PublicId:     "uhomestay/profile/pic",
+				Format:       "jpg",
+			},
+		},
+		{
+			name: "Raw Asset With Escaped Name",
+			url:  "https://res.cloudinary.com/demo/raw/upload/v2/uhomestay/document/1-AD%20ART.pdf",
+			expected: storage.CloudinaryAsset{
+				ResourceType: "raw",
+				DeliveryType: "upload",
+				PublicId:     "uhomestay/document/1-AD ART.pdf",
+			},
+		},
+		{
+			name:  "Not Cloudinary Url",
+			url:   "http://localhost:5000/file.jpg",
+			isErr: true,
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			asset, err := storage.ParseCloudinaryUrl(c.url)
+			if c.isErr {
+				if err == nil {
+					t.Fatalf("Expected error. Got %#v\n", asset)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if asset != c.expected {
+				t.Fatalf("Expected %#v. Got %#v\n", c.expected, asset)
+			}
+		})
+	}
+}
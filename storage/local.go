@@ -0,0 +1,222 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LocalStorage keep the object in a directory of the local disk, useful for
+// development and running the service offline. The object is served by
+// Handler, where private object is only served through signed url.
+type LocalStorage struct {
+	Dir     string
+	BaseUrl string
+	Private bool
+	key     []byte
+}
+
+func NewLocal(dir, baseUrl string, key []byte, private bool) *LocalStorage {
+	return &LocalStorage{
+		Dir:     dir,
+		BaseUrl: strings.TrimRight(baseUrl, "/"),
+		Private: private,
+		key:     key,
+	}
+}
+
+func (s *LocalStorage) path(objectKey string) string {
+	return filepath.Join(s.Dir, filepath.FromSlash(path.Clean("/"+objectKey)))
+}
+
+func (s *LocalStorage) url(objectKey string) string {
+	return s.BaseUrl + "/" + (&url.URL{Path: objectKey}).EscapedPath()
+}
+
+// objectKey return the key of the object behind the url.
+func (s *LocalStorage) objectKey(fileUrl string) (string, error) {
+	u, err := url.Parse(fileUrl)
+	if err != nil {
+		return "", err
+	}
+
+	base, err := url.Parse(s.BaseUrl)
+	if err != nil {
+		return "", err
+	}
+
+	if u.Host != base.Host || !strings.HasPrefix(u.Path, base.Path+"/") {
+		return "", ErrUnknownUrl
+	}
+
+	return strings.TrimPrefix(u.Path, base.Path+"/"), nil
+}
+
+func (s *LocalStorage) ObjectId(fileUrl string) (string, error) {
+	return s.objectKey(fileUrl)
+}
+
+func (s *LocalStorage) Put(ctx context.Context, key string, file io.Reader) (string, error) {
+	objKey := objectKey(key, s.Private)
+	p := s.path(objKey)
+
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return "", err
+	}
+
+	f, err := os.Create(p)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	if _, err = io.Copy(f, file); err != nil {
+		return "", err
+	}
+
+	return s.url(objKey), nil
+}
+
+func (s *LocalStorage) Move(ctx context.Context, fromKey, toKey string) (string, error) {
+	from := s.path(objectKey(fromKey, s.Private))
+	objKey := objectKey(toKey, s.Private)
+	to := s.path(objKey)
+
+	if err := os.MkdirAll(filepath.Dir(to), 0o755); err != nil {
+		return "", err
+	}
+
+	if err := os.Rename(from, to); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", ErrObjectNotFound
+		}
+
+		return "", err
+	}
+
+	return s.url(objKey), nil
+}
+
+func (s *LocalStorage) Delete(ctx context.Context, fileUrl string) error {
+	objKey, err := s.objectKey(fileUrl)
+	if err != nil {
+		return err
+	}
+
+	if err = os.Remove(s.path(objKey)); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return ErrObjectNotFound
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func (s *LocalStorage) List(ctx context.Context, prefix string, fn func(obj Object) error) error {
+	root := s.path(objectKey("", s.Private))
+	keyPrefix := objectKey(prefix, s.Private)
+
+	err := filepath.WalkDir(root, func(p string, de fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+		if de.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(s.Dir, p)
+		if err != nil {
+			return err
+		}
+
+		objKey := filepath.ToSlash(rel)
+		if !strings.HasPrefix(objKey, keyPrefix) {
+			return nil
+		}
+
+		fi, err := de.Info()
+		if err != nil {
+			return err
+		}
+
+		return fn(Object{
+			Id:        objKey,
+			Url:       s.url(objKey),
+			UpdatedAt: fi.ModTime(),
+		})
+	})
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	return err
+}
+
+func (s *LocalStorage) signature(objectKey string, expires int64) string {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(objectKey + "\n" + strconv.FormatInt(expires, 10)))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *LocalStorage) SignUrl(ctx context.Context, fileUrl string, expiry time.Duration) (string, error) {
+	objKey, err := s.objectKey(fileUrl)
+	if err != nil {
+		return "", err
+	}
+
+	expires := time.Now().Add(expiry).Unix()
+
+	q := url.Values{}
+	q.Set("expires", strconv.FormatInt(expires, 10))
+	q.Set("signature", s.signature(objKey, expires))
+
+	return s.url(objKey) + "?" + q.Encode(), nil
+}
+
+// Handler serve the object under the given path prefix, the prefix should be
+// the path of the base url.
+func (s *LocalStorage) Handler(prefix string) http.Handler {
+	fileServer := http.StripPrefix(prefix, http.FileServer(http.Dir(s.Dir)))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		objKey := strings.TrimPrefix(path.Clean("/"+strings.TrimPrefix(r.URL.Path, prefix)), "/")
+
+		// Never list the directory content.
+		if fi, err := os.Stat(s.path(objKey)); err != nil || fi.IsDir() {
+			http.NotFound(w, r)
+			return
+		}
+
+		if !isPrivateKey(objKey) {
+			fileServer.ServeHTTP(w, r)
+			return
+		}
+
+		expires, _ := strconv.ParseInt(r.URL.Query().Get("expires"), 10, 64)
+		signature := r.URL.Query().Get("signature")
+		if expires < time.Now().Unix() || !hmac.Equal([]byte(signature), []byte(s.signature(objKey, expires))) {
+			http.Error(w, "signature is not valid or expired", http.StatusForbidden)
+			return
+		}
+
+		fileServer.ServeHTTP(w, r)
+	})
+}
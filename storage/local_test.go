@@ -0,0 +1,134 @@
+package storage_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/storage"
+)
+
+func TestLocalStorage(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	key := []byte("secret")
+
+	public := storage.NewLocal(dir, "http://localhost:5000/storage", key, false)
+	private := storage.NewLocal(dir, "http://localhost:5000/storage", key, true)
+
+	mux := http.NewServeMux()
+	mux.Handle("/storage/", public.Handler("/storage"))
+
+	get := func(rawUrl string) *httptest.ResponseRecorder {
+		u, err := url.Parse(rawUrl)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, u.RequestURI(), nil))
+		return rr
+	}
+
+	t.Run("Public Object", func(t *testing.T) {
+		u, err := public.Put(ctx, "uhomestay/blog/1-thumbnail.jpg", strings.NewReader("jpg"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected := "http://localhost:5000/storage/public/uhomestay/blog/1-thumbnail.jpg"; u != expected {
+			t.Fatalf("Expected url %s. Got %s\n", expected, u)
+		}
+
+		if rr := get(u); rr.Code != http.StatusOK || rr.Body.String() != "jpg" {
+			t.Fatalf("Expected public object to be served. Got %d %s\n", rr.Code, rr.Body.String())
+		}
+	})
+
+	t.Run("Private Object Need Signed Url", func(t *testing.T) {
+		u, err := private.Put(ctx, "uhomestay/dues/1-proof.pdf", strings.NewReader("pdf"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if rr := get(u); rr.Code != http.StatusForbidden {
+			t.Fatalf("Expected status code %d. Got %d\n", http.StatusForbidden, rr.Code)
+		}
+
+		signed, err := private.SignUrl(ctx, u, time.Minute)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rr := get(signed); rr.Code != http.StatusOK || rr.Body.String() != "pdf" {
+			t.Fatalf("Expected signed object to be served. Got %d %s\n", rr.Code, rr.Body.String())
+		}
+
+		expired, err := private.SignUrl(ctx, u, -time.Minute)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rr := get(expired); rr.Code != http.StatusForbidden {
+			t.Fatalf("Expected status code %d. Got %d\n", http.StatusForbidden, rr.Code)
+		}
+	})
+
+	t.Run("Directory Is Not Listed", func(t *testing.T) {
+		if rr := get("http://localhost:5000/storage/public/uhomestay/"); rr.Code != http.StatusNotFound {
+			t.Fatalf("Expected status code %d. Got %d\n", http.StatusNotFound, rr.Code)
+		}
+	})
+
+	t.Run("List Object Of The Same Kind", func(t *testing.T) {
+		ids := []string{}
+		err := private.List(ctx, "uhomestay/", func(obj storage.Object) error {
+			id, err := private.ObjectId(obj.Url)
+			if err != nil || id != obj.Id {
+				t.Fatalf("Expected object id %s from url %s. Got %s %v\n", obj.Id, obj.Url, id, err)
+			}
+			ids = append(ids, obj.Id)
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(ids) != 1 || ids[0] != "private/uhomestay/dues/1-proof.pdf" {
+			t.Fatalf("Expected only the private object. Got %v\n", ids)
+		}
+	})
+
+	t.Run("Move And Delete", func(t *testing.T) {
+		if _, err := public.Put(ctx, "uhomestay/blog/2-image.png", strings.NewReader("png")); err != nil {
+			t.Fatal(err)
+		}
+
+		u, err := public.Move(ctx, "uhomestay/blog/2-image.png", "uhomestay/blog/3-image.png")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rr := get(u)
+		if b, _ := io.ReadAll(rr.Body); rr.Code != http.StatusOK || string(b) != "png" {
+			t.Fatalf("Expected moved object to be served. Got %d %s\n", rr.Code, b)
+		}
+
+		if _, err = public.Move(ctx, "uhomestay/blog/2-image.png", "uhomestay/blog/4-image.png"); !errors.Is(err, storage.ErrObjectNotFound) {
+			t.Fatalf("Expected error %v. Got %v\n", storage.ErrObjectNotFound, err)
+		}
+
+		if err = public.Delete(ctx, u); err != nil {
+			t.Fatal(err)
+		}
+		if err = public.Delete(ctx, u); !errors.Is(err, storage.ErrObjectNotFound) {
+			t.Fatalf("Expected error %v. Got %v\n", storage.ErrObjectNotFound, err)
+		}
+		if err = public.Delete(ctx, "https://example.com/storage/public/a.png"); !errors.Is(err, storage.ErrUnknownUrl) {
+			t.Fatalf("Expected error %v. Got %v\n", storage.ErrUnknownUrl, err)
+		}
+	})
+}
@@ -0,0 +1,371 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	s3Algorithm     = "AWS4-HMAC-SHA256"
+	s3Service       = "s3"
+	s3UnsignedBody  = "UNSIGNED-PAYLOAD"
+	s3TimeFormat    = "20060102T150405Z"
+	s3ShortTimeForm = "20060102"
+)
+
+type S3Config struct {
+	// Endpoint of the s3-compatible service, e.g. `http://localhost:9000` for
+	// local MinIO or `https://s3.ap-southeast-1.amazonaws.com`.
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	// PublicUrl is the url where public object can be reached, default to
+	// `<endpoint>/<bucket>`.
+	PublicUrl string
+}
+
+// S3Storage keep the object in a s3-compatible bucket using path-style
+// request, so it work with MinIO as well. The bucket should only allow
+// anonymous read on the `public/` prefix, private object is read through
+// presigned url.
+type S3Storage struct {
+	S3Config
+	Private bool
+	Client  *http.Client
+	now     func() time.Time
+}
+
+func NewS3(conf S3Config, private bool) *S3Storage {
+	conf.Endpoint = strings.TrimRight(conf.Endpoint, "/")
+	if conf.Region == "" {
+		conf.Region = "us-east-1"
+	}
+	if conf.PublicUrl == "" {
+		conf.PublicUrl = conf.Endpoint + "/" + conf.Bucket
+	}
+	conf.PublicUrl = strings.TrimRight(conf.PublicUrl, "/")
+
+	return &S3Storage{
+		S3Config: conf,
+		Private:  private,
+		Client:   http.DefaultClient,
+		now:      time.Now,
+	}
+}
+
+func (s *S3Storage) objectPath(objectKey string) string {
+	return "/" + s.Bucket + "/" + objectKey
+}
+
+func (s *S3Storage) url(objectKey string) string {
+	return s.PublicUrl + "/" + (&url.URL{Path: objectKey}).EscapedPath()
+}
+
+// objectKey return the key of the object behind the url.
+func (s *S3Storage) objectKey(fileUrl string) (string, error) {
+	u, err := url.Parse(fileUrl)
+	if err != nil {
+		return "", err
+	}
+
+	base, err := url.Parse(s.PublicUrl)
+	if err != nil {
+		return "", err
+	}
+
+	if u.Host != base.Host || !strings.HasPrefix(u.Path, base.Path+"/") {
+		return "", ErrUnknownUrl
+	}
+
+	return strings.TrimPrefix(u.Path, base.Path+"/"), nil
+}
+
+func (s *S3Storage) do(ctx context.Context, method, p string, query url.Values, header http.Header, body []byte) (*http.Response, error) {
+	u, err := url.Parse(s.Endpoint + p)
+	if err != nil {
+		return nil, err
+	}
+	u.RawQuery = canonicalQuery(query)
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = int64(len(body))
+
+	for k, v := range header {
+		req.Header[k] = v
+	}
+
+	s.signRequest(req, s.now().UTC())
+
+	res, err := s.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode == http.StatusNotFound {
+		res.Body.Close()
+		return nil, ErrObjectNotFound
+	}
+
+	if res.StatusCode >= 300 {
+		defer res.Body.Close()
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return nil, fmt.Errorf("s3 %s %s: %s: %s", method, p, res.Status, msg)
+	}
+
+	return res, nil
+}
+
+func (s *S3Storage) Put(ctx context.Context, key string, file io.Reader) (string, error) {
+	body, err := io.ReadAll(file)
+	if err != nil {
+		return "", err
+	}
+
+	objKey := objectKey(key, s.Private)
+
+	header := http.Header{}
+	header.Set("Content-Type", http.DetectContentType(body))
+
+	res, err := s.do(ctx, http.MethodPut, s.objectPath(objKey), nil, header, body)
+	if err != nil {
+		return "", err
+	}
+	res.Body.Close()
+
+	return s.url(objKey), nil
+}
+
+func (s *S3Storage) Move(ctx context.Context, fromKey, toKey string) (string, error) {
+	fromObjKey := objectKey(fromKey, s.Private)
+	objKey := objectKey(toKey, s.Private)
+
+	header := http.Header{}
+	header.Set("X-Amz-Copy-Source", (&url.URL{Path: s.objectPath(fromObjKey)}).EscapedPath())
+
+	res, err := s.do(ctx, http.MethodPut, s.objectPath(objKey), nil, header, nil)
+	if err != nil {
+		return "", err
+	}
+	res.Body.Close()
+
+	res, err = s.do(ctx, http.MethodDelete, s.objectPath(fromObjKey), nil, nil, nil)
+	if err != nil {
+		return "", err
+	}
+	res.Body.Close()
+
+	return s.url(objKey), nil
+}
+
+func (s *S3Storage) Delete(ctx context.Context, fileUrl string) error {
+	objKey, err := s.objectKey(fileUrl)
+	if err != nil {
+		return err
+	}
+
+	res, err := s.do(ctx, http.MethodDelete, s.objectPath(objKey), nil, nil, nil)
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+
+	return nil
+}
+
+func (s *S3Storage) ObjectId(fileUrl string) (string, error) {
+	return s.objectKey(fileUrl)
+}
+
+type s3ListResult struct {
+	Contents []struct {
+		Key          string    `xml:"Key"`
+		LastModified time.Time `xml:"LastModified"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+func (s *S3Storage) List(ctx context.Context, prefix string, fn func(obj Object) error) error {
+	q := url.Values{}
+	q.Set("list-type", "2")
+	q.Set("prefix", objectKey(prefix, s.Private))
+
+	for {
+		res, err := s.do(ctx, http.MethodGet, "/"+s.Bucket, q, nil, nil)
+		if err != nil {
+			return err
+		}
+
+		var list s3ListResult
+		err = xml.NewDecoder(res.Body).Decode(&list)
+		res.Body.Close()
+		if err != nil {
+			return err
+		}
+
+		for _, c := range list.Contents {
+			err = fn(Object{
+				Id:        c.Key,
+				Url:       s.url(c.Key),
+				UpdatedAt: c.LastModified,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		if !list.IsTruncated || list.NextContinuationToken == "" {
+			return nil
+		}
+		q.Set("continuation-token", list.NextContinuationToken)
+	}
+}
+
+// SignUrl return presigned GET url of the object, valid for the given expiry.
+func (s *S3Storage) SignUrl(ctx context.Context, fileUrl string, expiry time.Duration) (string, error) {
+	objKey, err := s.objectKey(fileUrl)
+	if err != nil {
+		return "", err
+	}
+
+	u, err := url.Parse(s.Endpoint + s.objectPath(objKey))
+	if err != nil {
+		return "", err
+	}
+
+	return s.presign(u, s.now().UTC(), expiry), nil
+}
+
+func (s *S3Storage) scope(t time.Time) string {
+	return t.Format(s3ShortTimeForm) + "/" + s.Region + "/" + s3Service + "/aws4_request"
+}
+
+func (s *S3Storage) signingKey(t time.Time) []byte {
+	k := hmacSHA256([]byte("AWS4"+s.SecretKey), t.Format(s3ShortTimeForm))
+	k = hmacSHA256(k, s.Region)
+	k = hmacSHA256(k, s3Service)
+	return hmacSHA256(k, "aws4_request")
+}
+
+func (s *S3Storage) signature(t time.Time, canonicalRequest string) string {
+	stringToSign := strings.Join([]string{
+		s3Algorithm,
+		t.Format(s3TimeFormat),
+		s.scope(t),
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	return hex.EncodeToString(hmacSHA256(s.signingKey(t), stringToSign))
+}
+
+// signRequest sign the request with AWS signature version 4 in the
+// Authorization header.
+func (s *S3Storage) signRequest(req *http.Request, t time.Time) {
+	req.Header.Set("Host", req.URL.Host)
+	req.Header.Set("X-Amz-Date", t.Format(s3TimeFormat))
+	req.Header.Set("X-Amz-Content-Sha256", s3UnsignedBody)
+
+	names := make([]string, 0, len(req.Header))
+	for k := range req.Header {
+		names = append(names, strings.ToLower(k))
+	}
+	sort.Strings(names)
+
+	var headers strings.Builder
+	for _, k := range names {
+		headers.WriteString(k + ":" + strings.TrimSpace(req.Header.Get(k)) + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		canonicalQuery(req.URL.Query()),
+		headers.String(),
+		signedHeaders,
+		s3UnsignedBody,
+	}, "\n")
+
+	req.Header.Del("Host")
+	req.Header.Set("Authorization", s3Algorithm+
+		" Credential="+s.AccessKey+"/"+s.scope(t)+
+		", SignedHeaders="+signedHeaders+
+		", Signature="+s.signature(t, canonicalRequest))
+}
+
+// presign sign the GET url with AWS signature version 4 in the query string.
+func (s *S3Storage) presign(u *url.URL, t time.Time, expiry time.Duration) string {
+	q := u.Query()
+	q.Set("X-Amz-Algorithm", s3Algorithm)
+	q.Set("X-Amz-Credential", s.AccessKey+"/"+s.scope(t))
+	q.Set("X-Amz-Date", t.Format(s3TimeFormat))
+	q.Set("X-Amz-Expires", strconv.FormatInt(int64(expiry.Seconds()), 10))
+	q.Set("X-Amz-SignedHeaders", "host")
+
+	canonicalRequest := strings.Join([]string{
+		http.MethodGet,
+		u.EscapedPath(),
+		canonicalQuery(q),
+		"host:" + u.Host + "\n",
+		"host",
+		s3UnsignedBody,
+	}, "\n")
+
+	q.Set("X-Amz-Signature", s.signature(t, canonicalRequest))
+	u.RawQuery = canonicalQuery(q)
+
+	return u.String()
+}
+
+func canonicalQuery(q url.Values) string {
+	keys := make([]string, 0, len(q))
+	for k := range q {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		vs := append([]string{}, q[k]...)
+		sort.Strings(vs)
+		for _, v := range vs {
+			parts = append(parts, s3Escape(k)+"="+s3Escape(v))
+		}
+	}
+
+	return strings.Join(parts, "&")
+}
+
+// s3Escape percent-encode everything except the unreserved characters, as
+// required by the signature.
+func s3Escape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"time"
+)
+
+var (
+	ErrObjectNotFound = errors.New("object not found")
+	ErrUnknownUrl     = errors.New("url is not owned by the storage")
+)
+
+// Storage is where uploaded file is kept. Object is put and moved by its key,
+// a slash separated path like `uhomestay/document/1650000000-file.pdf`, while
+// deleting and signing is done by the url returned from put or move, since
+// that is what the rows keep.
+type Storage interface {
+	Put(ctx context.Context, key string, file io.Reader) (url string, err error)
+	Move(ctx context.Context, fromKey, toKey string) (url string, err error)
+	Delete(ctx context.Context, fileUrl string) error
+	SignUrl(ctx context.Context, fileUrl string, expiry time.Duration) (url string, err error)
+	// List call fn for every object of the storage whose key start with the
+	// prefix, stop at the first error returned by fn.
+	List(ctx context.Context, prefix string, fn func(obj Object) error) error
+	// ObjectId return the id of the object behind the url, different url of
+	// the same object, e.g. with transformation or signature, give the same id.
+	ObjectId(fileUrl string) (string, error)
+}
+
+type Object struct {
+	Id        string
+	Url       string
+	UpdatedAt time.Time
+}
+
+const (
+	Cloudinary = "cloudinary"
+	Local      = "local"
+	S3         = "s3"
+)
+
+const (
+	publicPrefix  = "public/"
+	privatePrefix = "private/"
+)
+
+// objectKey put the key under the public or private prefix, so the local and
+// s3 driver can tell which object can be accessed without signed url.
+func objectKey(key string, private bool) string {
+	key = strings.TrimLeft(key, "/")
+	if private {
+		return privatePrefix + key
+	}
+
+	return publicPrefix + key
+}
+
+func isPrivateKey(objectKey string) bool {
+	return strings.HasPrefix(objectKey, privatePrefix)
+}
@@ -0,0 +1,76 @@
+package textdiff
+
+import (
+	"strings"
+)
+
+type Op string
+
+const (
+	Equal  Op = "equal"
+	Insert Op = "insert"
+	Delete Op = "delete"
+)
+
+type Line struct {
+	Op   Op
+	Text string
+}
+
+// Lines return the line by line difference to turn a into b, based on the
+// longest common subsequence of both lines.
+func Lines(a, b string) []Line {
+	al, bl := split(a), split(b)
+	n, m := len(al), len(bl)
+
+	// lcs[i][j] is the length of the longest common subsequence of al[i:]
+	// and bl[j:].
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if al[i] == bl[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	lines := make([]Line, 0, n+m)
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case al[i] == bl[j]:
+			lines = append(lines, Line{Op: Equal, Text: al[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, Line{Op: Delete, Text: al[i]})
+			i++
+		default:
+			lines = append(lines, Line{Op: Insert, Text: bl[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		lines = append(lines, Line{Op: Delete, Text: al[i]})
+	}
+	for ; j < m; j++ {
+		lines = append(lines, Line{Op: Insert, Text: bl[j]})
+	}
+
+	return lines
+}
+
+func split(s string) []string {
+	if s == "" {
+		return nil
+	}
+
+	return strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+}
@@ -0,0 +1,84 @@
+package textdiff_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/textdiff"
+)
+
+func TestLines(t *testing.T) {
+	testCases := []struct {
+		name string
+		a    string
+		b    string
+		res  []textdiff.Line
+	}{
+		{
+			name: "same text",
+			a:    "visi\nmisi",
+			b:    "visi\nmisi",
+			res: []textdiff.Line{
+				{Op: textdiff.Equal, Text: "visi"},
+				{Op: textdiff.Equal, Text: "misi"},
+			},
+		},
+		{
+			name: "empty to text",
+			a:    "",
+			b:    "visi",
+			res: []textdiff.Line{
+				{Op: textdiff.Insert, Text: "visi"},
+			},
+		},
+		{
+			name: "text to empty",
+			a:    "visi",
+			b:    "",
+			res: []textdiff.Line{
+				{Op: textdiff.Delete, Text: "visi"},
+			},
+		},
+		{
+			name: "changed middle line",
+			a:    "sejarah\nberdiri 1990\nakhir",
+			b:    "sejarah\nberdiri 1991\nakhir",
+			res: []textdiff.Line{
+				{Op: textdiff.Equal, Text: "sejarah"},
+				{Op: textdiff.Delete, Text: "berdiri 1990"},
+				{Op: textdiff.Insert, Text: "berdiri 1991"},
+				{Op: textdiff.Equal, Text: "akhir"},
+			},
+		},
+		{
+			name: "added and removed line",
+			a:    "a\nb\nc",
+			b:    "b\nc\nd",
+			res: []textdiff.Line{
+				{Op: textdiff.Delete, Text: "a"},
+				{Op: textdiff.Equal, Text: "b"},
+				{Op: textdiff.Equal, Text: "c"},
+				{Op: textdiff.Insert, Text: "d"},
+			},
+		},
+		{
+			name: "windows line ending",
+			a:    "a\r\nb",
+			b:    "a\nb",
+			res: []textdiff.Line{
+				{Op: textdiff.Equal, Text: "a"},
+				{Op: textdiff.Equal, Text: "b"},
+			},
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			res := textdiff.Lines(c.a, c.b)
+
+			if !reflect.DeepEqual(res, c.res) {
+				t.Fatalf("Expected %v. Got %v\n", c.res, res)
+			}
+		})
+	}
+}
@@ -4,9 +4,13 @@ import (
 	"context"
 	"embed"
 	"io"
-	"path/filepath"
+	"path"
+	"time"
 
-	"github.com/cloudinary/cloudinary-go/api/uploader"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/audit"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/passhash"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/revision"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/storage"
 	"github.com/getsentry/sentry-go"
 )
 
@@ -16,66 +20,119 @@ type (
 	MessageCapturer   func(message string)
 )
 
+// MemberHook is run in the transaction of the member change, so the change is
+// rolled back when the hook fail.
+type MemberHook func(ctx context.Context, uid string) error
+
+// MemberHooks let other package follow the membership lifecycle without the
+// user package importing them.
+// PasswordResetHook deliver the reset token to the member, the token is only
+// known by the hook since it is stored hashed.
+type PasswordResetHook func(ctx context.Context, uid, token string, expiresAt time.Time) error
+
+type MemberHooks struct {
+	// OnRegistered is run when the member register and wait for approval.
+	OnRegistered             []MemberHook
+	OnApproved               []MemberHook
+	OnRemoved                []MemberHook
+	OnPasswordResetRequested []PasswordResetHook
+}
+
+func runMemberHooks(ctx context.Context, hooks []MemberHook, uid string) error {
+	for _, h := range hooks {
+		if err := h(ctx, uid); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 type UserDeps struct {
-	JwtKey                 []byte
-	JwtIssuerUrl           string
-	Argon2Salt             string
-	JwtAudiences           []string
-	CaptureMessage         MessageCapturer
-	CaptureExeption        ExceptionCapturer
-	Upload                 FileUploader
-	Tmpl                   embed.FS
-	MemberRepository       *MemberRepository
-	PositionRepository     *PositionRepository
-	OrgStructureRepository *OrgStructureRepository
-	OrgPeriodRepository    *OrgPeriodRepository
-	GoalRepository         *GoalRepository
+	JwtKey              []byte
+	JwtIssuerUrl        string
+	PasswordParams      passhash.Params
+	JwtAudiences        []string
+	AccessTokenExpiry   time.Duration
+	RefreshTokenExpiry  time.Duration
+	PasswordResetExpiry time.Duration
+	// PasswordResetLimit is the max reset request of a username in an hour.
+	PasswordResetLimit        int
+	CaptureMessage            MessageCapturer
+	CaptureExeption           ExceptionCapturer
+	Upload                    FileUploader
+	Tmpl                      embed.FS
+	MemberHooks               MemberHooks
+	Audit                     audit.Recorder
+	Revision                  revision.Recorder
+	MemberRepository          *MemberRepository
+	PositionRepository        *PositionRepository
+	OrgStructureRepository    *OrgStructureRepository
+	OrgPeriodRepository       *OrgPeriodRepository
+	GoalRepository            *GoalRepository
+	RefreshTokenRepository    *RefreshTokenRepository
+	TokenRevocationRepository *TokenRevocationRepository
+	PasswordResetRepository   *PasswordResetRepository
+	RoleRepository            *RoleRepository
 }
 
 func NewDeps(
 	jwtKey []byte,
 	jwtIssuerUrl string,
-	argon2Salt string,
+	passwordParams passhash.Params,
 	jwtAudiences []string,
+	accessTokenExpiry time.Duration,
+	refreshTokenExpiry time.Duration,
+	passwordResetExpiry time.Duration,
+	passwordResetLimit int,
 	captureMessage MessageCapturer,
 	captureExeption ExceptionCapturer,
 	upload FileUploader,
 	tmpl embed.FS,
+	memberHooks MemberHooks,
+	auditRecorder audit.Recorder,
+	revisionRecorder revision.Recorder,
 	memberRepository *MemberRepository,
 	positionRepository *PositionRepository,
 	orgStructureRepository *OrgStructureRepository,
 	orgPeriodRepository *OrgPeriodRepository,
 	goalRepository *GoalRepository,
+	refreshTokenRepository *RefreshTokenRepository,
+	tokenRevocationRepository *TokenRevocationRepository,
+	passwordResetRepository *PasswordResetRepository,
+	roleRepository *RoleRepository,
 ) *UserDeps {
 	return &UserDeps{
-		JwtKey:                 jwtKey,
-		JwtIssuerUrl:           jwtIssuerUrl,
-		Argon2Salt:             argon2Salt,
-		CaptureMessage:         captureMessage,
-		CaptureExeption:        captureExeption,
-		JwtAudiences:           jwtAudiences,
-		Upload:                 upload,
-		Tmpl:                   tmpl,
-		MemberRepository:       memberRepository,
-		PositionRepository:     positionRepository,
-		OrgStructureRepository: orgStructureRepository,
-		OrgPeriodRepository:    orgPeriodRepository,
-		GoalRepository:         goalRepository,
+		JwtKey:                    jwtKey,
+		JwtIssuerUrl:              jwtIssuerUrl,
+		PasswordParams:            passwordParams,
+		CaptureMessage:            captureMessage,
+		CaptureExeption:           captureExeption,
+		JwtAudiences:              jwtAudiences,
+		AccessTokenExpiry:         accessTokenExpiry,
+		RefreshTokenExpiry:        refreshTokenExpiry,
+		PasswordResetExpiry:       passwordResetExpiry,
+		PasswordResetLimit:        passwordResetLimit,
+		Upload:                    upload,
+		Tmpl:                      tmpl,
+		MemberHooks:               memberHooks,
+		Audit:                     auditRecorder,
+		Revision:                  revisionRecorder,
+		MemberRepository:          memberRepository,
+		PositionRepository:        positionRepository,
+		OrgStructureRepository:    orgStructureRepository,
+		OrgPeriodRepository:       orgPeriodRepository,
+		GoalRepository:            goalRepository,
+		RefreshTokenRepository:    refreshTokenRepository,
+		TokenRevocationRepository: tokenRevocationRepository,
+		PasswordResetRepository:   passwordResetRepository,
+		RoleRepository:            roleRepository,
 	}
 }
 
-func FileUpload(uploadParams uploader.UploadParams, upload func(ctx context.Context, file interface{}, uploadParams uploader.UploadParams) (*uploader.UploadResult, error)) FileUploader {
+func FileUpload(store storage.Storage, folder string) FileUploader {
 	return func(filename string, file io.Reader) (url string, err error) {
-		filename = filename[:len(filename)-len(filepath.Ext(filename))]
-		ctx := context.Background()
-		uploadParams.PublicID = filename
-
-		resp, err := upload(ctx, file, uploadParams)
-		if err != nil {
-			return "", err
-		}
-
-		return resp.SecureURL, nil
+		return store.Put(context.Background(), path.Join(folder, filename), file)
 	}
 }
 
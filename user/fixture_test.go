@@ -10,33 +10,43 @@ import (
 	"testing"
 	"time"
 
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/audit"
 	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/config"
 	"github.com/fikryfahrezy/crypt/agron2"
+	"github.com/go-redis/redis/v8"
 	"github.com/gofrs/uuid"
 	"github.com/jackc/pgx/v4/pgxpool"
 	"github.com/ory/dockertest/v3"
 	"github.com/ory/dockertest/v3/docker"
 	"golang.org/x/crypto/argon2"
 
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/passhash"
 	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/user"
 )
 
 var (
 	db                  *pgxpool.Pool
+	redisClient         *redis.Client
 	memberRepository    *user.MemberRepository
 	positionRepository  *user.PositionRepository
 	orgRepository       *user.OrgStructureRepository
 	orgPeriodRepository *user.OrgPeriodRepository
 	goalRepository      *user.GoalRepository
+	refreshTokenRepo    *user.RefreshTokenRepository
+	tokenRevocationRepo *user.TokenRevocationRepository
+	passwordResetRepo   *user.PasswordResetRepository
+	roleRepository      *user.RoleRepository
+	auditDeps           *audit.AuditDeps
 	userDeps            *user.UserDeps
 	tmpl                embed.FS
 	conf                = config.Config{
-		JwtKey:          []byte("testestestest"),
-		JwtAudiencesStr: "this",
-		JwtKeyStr:       "testestestest",
-		JwtIssuerUrl:    "http://localhost:5000",
-		Argon2Salt:      "saltingmin8chars",
-		JwtAudiences:    []string{"those"},
+		JwtKey:           []byte("testestestest"),
+		JwtAudiencesStr:  "this",
+		JwtKeyStr:        "testestestest",
+		JwtIssuerUrl:     "http://localhost:5000",
+		JwtAudiences:     []string{"those"},
+		JwtAccessExpiry:  time.Minute * 15,
+		JwtRefreshExpiry: time.Hour * 24,
 	}
 	fileName = "images.jpeg"
 	fileDir  = "./fixture/" + fileName
@@ -137,6 +147,32 @@ var (
 	}
 	captureException user.ExceptionCapturer = func(exception error) {}
 	captureMessage   user.MessageCapturer   = func(message string) {}
+	// legacySalt is the global salt the members registered before the per
+	// password salt were hashed with.
+	legacySalt   = "saltingmin8chars"
+	approvedUids []string
+	removedUids  []string
+	resetTokens  = map[string]string{}
+	memberHooks  = user.MemberHooks{
+		OnApproved: []user.MemberHook{
+			func(ctx context.Context, uid string) error {
+				approvedUids = append(approvedUids, uid)
+				return nil
+			},
+		},
+		OnRemoved: []user.MemberHook{
+			func(ctx context.Context, uid string) error {
+				removedUids = append(removedUids, uid)
+				return nil
+			},
+		},
+		OnPasswordResetRequested: []user.PasswordResetHook{
+			func(ctx context.Context, uid, token string, expiresAt time.Time) error {
+				resetTokens[uid] = token
+				return nil
+			},
+		},
+	}
 )
 
 func LoadTables(conn *pgxpool.Pool) error {
@@ -182,6 +218,9 @@ func ClearTables(conn *pgxpool.Pool) error {
 		`TRUNCATE positions CASCADE`,
 		`TRUNCATE org_periods CASCADE`,
 		`TRUNCATE goals CASCADE`,
+		`TRUNCATE roles CASCADE`,
+		`TRUNCATE audit_events`,
+		`TRUNCATE revisions`,
 	}
 
 	for _, v := range queries {
@@ -201,10 +240,12 @@ func ClearTables(conn *pgxpool.Pool) error {
 	return nil
 }
 
+// createUser save the member with the legacy global salt hash, like the
+// members registered before the per password salt.
 func createUser(r *user.MemberRepository, member user.MemberModel) (muid string, err error) {
 	memberCp := user.MemberModel(member)
 
-	hash, err := agron2.Argon2Hash(memberCp.Password, userDeps.Argon2Salt, 1, 64*1024, 4, 32, argon2.Version, agron2.Argon2Id)
+	hash, err := agron2.Argon2Hash(memberCp.Password, legacySalt, 1, 64*1024, 4, 32, argon2.Version, agron2.Argon2Id)
 	if err != nil {
 		return "", err
 	}
@@ -319,9 +360,22 @@ func TestMain(m *testing.M) {
 
 	resource.Expire(120) // Tell docker to hard kill the container in 120 seconds
 
+	redisResource, err := pool.Run("redis", "7.0.0", nil)
+	if err != nil {
+		log.Fatalf("Could not start redis resource: %s", err)
+	}
+
 	// exponential backoff-retry, because the application in the container might not be ready to accept connections yet
 	pool.MaxWait = 120 * time.Second
 	if err = pool.Retry(func() error {
+		redisClient = redis.NewClient(&redis.Options{
+			Addr: fmt.Sprintf("localhost:%s", redisResource.GetPort("6379/tcp")),
+		})
+
+		if err := redisClient.Ping(context.TODO()).Err(); err != nil {
+			return err
+		}
+
 		dbConfig, err := pgxpool.ParseConfig(databaseUrl)
 		if err != nil {
 			return err
@@ -342,21 +396,41 @@ func TestMain(m *testing.M) {
 	orgRepository = user.NewOrgStructureRepository(db)
 	orgPeriodRepository = user.NewOrgPeriodRepository(db)
 	goalRepository = user.NewGoalRepository(db)
+	refreshTokenRepo = user.NewRefreshTokenRepository("rtkn", redisClient)
+	tokenRevocationRepo = user.NewTokenRevocationRepository("rvkn", redisClient)
+	passwordResetRepo = user.NewPasswordResetRepository("pwrs", redisClient)
+	roleRepository = user.NewRoleRepository(db)
+	auditDeps = audit.NewDeps(
+		func(message string) {},
+		func(exception error) {},
+		audit.NewAuditRepository(db),
+	)
 
 	userDeps = user.NewDeps(
 		conf.JwtKey,
 		conf.JwtIssuerUrl,
-		conf.Argon2Salt,
+		passhash.DefaultParams,
 		conf.JwtAudiences,
+		conf.JwtAccessExpiry,
+		conf.JwtRefreshExpiry,
+		30*time.Minute,
+		3,
 		captureMessage,
 		captureException,
 		upload,
 		tmpl,
+		memberHooks,
+		auditDeps.Record,
+		nil,
 		memberRepository,
 		positionRepository,
 		orgRepository,
 		orgPeriodRepository,
 		goalRepository,
+		refreshTokenRepo,
+		tokenRevocationRepo,
+		passwordResetRepo,
+		roleRepository,
 	)
 
 	LoadTables(db)
@@ -369,5 +443,9 @@ func TestMain(m *testing.M) {
 		log.Fatalf("Could not purge resource: %s", err)
 	}
 
+	if err := pool.Purge(redisResource); err != nil {
+		log.Fatalf("Could not purge redis resource: %s", err)
+	}
+
 	os.Exit(code)
 }
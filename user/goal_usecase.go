@@ -6,13 +6,38 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/audit"
 	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/resp"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/revision"
 	"github.com/jackc/pgx/v4"
 	"github.com/pkg/errors"
 )
 
 var ErrNotValidContent = errors.New("format kontent tidak valid")
 
+// goalContent is the versioned content of the period goal kept in its
+// revisions.
+type goalContent struct {
+	Vision      map[string]interface{} `json:"vision"`
+	VisionText  string                 `json:"vision_text"`
+	Mission     map[string]interface{} `json:"mission"`
+	MissionText string                 `json:"mission_text"`
+}
+
+func goalRevision(g GoalModel) revision.Revision {
+	return revision.Revision{
+		Entity:   revision.Goal,
+		EntityId: g.OrgPeriodId,
+		Content: goalContent{
+			Vision:      g.Vision,
+			VisionText:  g.VisionText,
+			Mission:     g.Mission,
+			MissionText: g.MissionText,
+		},
+		ContentText: g.VisionText + "\n" + g.MissionText,
+	}
+}
+
 type (
 	AddGoalIn struct {
 		Vision      string `json:"vision"`
@@ -100,6 +125,22 @@ func (d *UserDeps) AddGoal(ctx context.Context, in AddGoalIn) (out AddGoalOut) {
 		return
 	}
 
+	if err = d.Revision.Record(ctx, goalRevision(goal)); err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "record revision"))
+		return
+	}
+
+	err = d.Audit.Record(ctx, audit.Event{
+		Action:   audit.ActionCreate,
+		Entity:   "goal",
+		EntityId: strconv.FormatUint(goal.Id, 10),
+		After:    goal,
+	})
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "record audit"))
+		return
+	}
+
 	out.Res.Id = int64(goal.Id)
 
 	return
@@ -200,3 +241,39 @@ func (d *UserDeps) FindOrgPeriodGoal(ctx context.Context, pid string) (out FindO
 
 	return
 }
+
+// RestoreGoal save the content of the revision as the latest goal of the
+// period.
+func (d *UserDeps) RestoreGoal(ctx context.Context, rev revision.RevisionModel) error {
+	var c goalContent
+	if err := rev.Decode(&c); err != nil {
+		return errors.Wrap(err, "decode revision")
+	}
+
+	if _, err := d.OrgPeriodRepository.FindUndeletedById(ctx, rev.EntityId); err != nil {
+		return err
+	}
+
+	goal, err := d.GoalRepository.Save(ctx, GoalModel{
+		Vision:      c.Vision,
+		VisionText:  c.VisionText,
+		Mission:     c.Mission,
+		MissionText: c.MissionText,
+		OrgPeriodId: rev.EntityId,
+	})
+	if err != nil {
+		return errors.Wrap(err, "save goal")
+	}
+
+	err = d.Audit.Record(ctx, audit.Event{
+		Action:   audit.ActionCreate,
+		Entity:   "goal",
+		EntityId: strconv.FormatUint(goal.Id, 10),
+		After:    goal,
+	})
+	if err != nil {
+		return errors.Wrap(err, "record audit")
+	}
+
+	return nil
+}
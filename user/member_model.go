@@ -25,3 +25,13 @@ type MemberModel struct {
 	DeletedAt         sql.NullTime
 	Id                pgtypeuuid.UUID
 }
+
+// PasswordHashCountModel count the password hashes of the members by how they
+// were made.
+type PasswordHashCountModel struct {
+	Total int64
+	// Legacy is made with the global salt.
+	Legacy int64
+	// Outdated is made with a random salt but other cost than the current one.
+	Outdated int64
+}
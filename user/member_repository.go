@@ -44,11 +44,12 @@ func (r *MemberRepository) Save(ctx context.Context, m MemberModel) error {
 			password,
 			is_admin,
 			is_approved,
+			approved_at,
 			created_at,
 			updated_at,
 			deleted_at
 		)
-		VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+		VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, CASE WHEN $13 THEN $14::TIMESTAMP END, $14, $15, $16)
 	`
 
 	var exec MemberExecutor
@@ -218,8 +219,13 @@ func (r *MemberRepository) Update(ctx context.Context, id string, m MemberModel)
 			password,
 			is_admin,
 			is_approved,
+			approved_at,
 			updated_at
-		) = ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		) = (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12,
+			CASE WHEN $12 THEN COALESCE(approved_at, $13::TIMESTAMP) END,
+			$13
+		)
 		WHERE id = $14
 	`
 
@@ -260,6 +266,37 @@ func (r *MemberRepository) Update(ctx context.Context, id string, m MemberModel)
 	return nil
 }
 
+func (r *MemberRepository) UpdatePassword(ctx context.Context, id, password string) error {
+	sqlQuery := `
+		UPDATE members SET
+			password = $1,
+			updated_at = $2
+		WHERE id = $3
+	`
+
+	var exec MemberExecutor
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		exec = tx.Exec
+	} else {
+		exec = r.PostgreDb.Exec
+	}
+
+	_, err := exec(
+		context.Background(),
+		sqlQuery,
+		password,
+		time.Now(),
+		id,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (r *MemberRepository) FindById(ctx context.Context, uid string) (m MemberModel, err error) {
 	sqlQuery := `
 		SELECT
@@ -517,6 +554,205 @@ func (r *MemberRepository) Query(ctx context.Context, uid pgtypeuuid.UUID, q str
 	return ms, nil
 }
 
+// RehashPassword replace the password hash only when it is still the old one,
+// so the rehash on login doesn't overwrite a password changed meanwhile.
+func (r *MemberRepository) RehashPassword(ctx context.Context, id, oldPassword, password string) error {
+	sqlQuery := `
+		UPDATE members SET
+			password = $1
+		WHERE id = $2
+		AND password = $3
+	`
+
+	var exec MemberExecutor
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		exec = tx.Exec
+	} else {
+		exec = r.PostgreDb.Exec
+	}
+
+	_, err := exec(
+		context.Background(),
+		sqlQuery,
+		password,
+		id,
+		oldPassword,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// CountPasswordHash count the password hashes of the members, the legacy
+// hashes are the ones with the given salt segment and the outdated hashes are
+// the others without the given cost segment.
+func (r *MemberRepository) CountPasswordHash(ctx context.Context, params string) (m PasswordHashCountModel, err error) {
+	// The legacy hash has its key written as hex instead of base64.
+	sqlQuery := `
+		SELECT
+			COUNT(id) AS total,
+			COUNT(id) FILTER (WHERE split_part(password, '$', 6) ~ '^([0-9a-f]{2})+$') AS legacy,
+			COUNT(id) FILTER (
+				WHERE split_part(password, '$', 6) !~ '^([0-9a-f]{2})+$'
+				AND split_part(password, '$', 4) <> $1
+			) AS outdated
+		FROM members
+		WHERE deleted_at IS NULL
+	`
+
+	var queryRow MemberQuerierRow
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		queryRow = tx.QueryRow
+	} else {
+		queryRow = r.PostgreDb.QueryRow
+	}
+
+	err = queryRow(
+		context.Background(),
+		sqlQuery,
+		params,
+	).Scan(&m.Total, &m.Legacy, &m.Outdated)
+
+	if err != nil {
+		return m, err
+	}
+
+	return m, nil
+}
+
+// memberPermissions select the permissions the member get from the roles and
+// from the positions held in the active period, it is the single place a new
+// source of permission is added. The position permissions end along with the
+// period, the end date being the last day of it.
+const memberPermissions = `
+	SELECT rp.permission
+	FROM member_roles mr
+	JOIN role_permissions rp ON rp.role_id = mr.role_id
+	WHERE mr.member_id = $1
+	UNION
+	SELECT pp.permission
+	FROM org_structures os
+	JOIN org_periods op ON op.id = os.org_period_id
+	JOIN positions p ON p.id = os.position_id
+	JOIN position_permissions pp ON pp.position_id = os.position_id
+	WHERE os.member_id = $1
+	AND os.deleted_at IS NULL
+	AND op.deleted_at IS NULL
+	AND op.is_active = true
+	AND op.end_date + INTERVAL '1 day' > LOCALTIMESTAMP
+	AND p.deleted_at IS NULL
+`
+
+// HasPermission report whether the approved member is an admin or has the
+// permission.
+func (r *MemberRepository) HasPermission(ctx context.Context, uid, permission string) (ok bool, err error) {
+	sqlQuery := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM members
+			WHERE id = $1
+			AND deleted_at IS NULL
+			AND is_approved = true
+			AND (
+				is_admin = true
+				OR $2 IN (` + memberPermissions + `)
+			)
+		)
+	`
+
+	var queryRow MemberQuerierRow
+	tx, isTx := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if isTx {
+		queryRow = tx.QueryRow
+	} else {
+		queryRow = r.PostgreDb.QueryRow
+	}
+
+	err = queryRow(
+		context.Background(),
+		sqlQuery,
+		uid,
+		permission,
+	).Scan(&ok)
+
+	if err != nil {
+		return false, err
+	}
+
+	return ok, nil
+}
+
+// QueryPermission list the permissions the member get from the roles and the
+// active positions, the admin permissions are not included.
+func (r *MemberRepository) QueryPermission(ctx context.Context, uid string) (ps []string, err error) {
+	sqlQuery := `
+		SELECT DISTINCT permission
+		FROM (` + memberPermissions + `) p
+		ORDER BY permission
+	`
+
+	var query MemberQuerier
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		query = tx.Query
+	} else {
+		query = r.PostgreDb.Query
+	}
+
+	rows, err := query(
+		context.Background(),
+		sqlQuery,
+		uid,
+	)
+
+	if err != nil {
+		return []string{}, err
+	}
+
+	ps = []string{}
+	if err = pgxscan.ScanAll(&ps, rows); err != nil {
+		return []string{}, err
+	}
+
+	return ps, nil
+}
+
+// CountUnreadNotification count the unread in-app notifications of the
+// member shown on the profile.
+func (r *MemberRepository) CountUnreadNotification(ctx context.Context, uid string) (n int64, err error) {
+	sqlQuery := `
+		SELECT COUNT(id) AS n
+		FROM notifications
+		WHERE member_id = $1
+		AND read_at IS NULL
+	`
+
+	var queryRow MemberQuerierRow
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		queryRow = tx.QueryRow
+	} else {
+		queryRow = r.PostgreDb.QueryRow
+	}
+
+	err = queryRow(
+		context.Background(),
+		sqlQuery,
+		uid,
+	).Scan(&n)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return n, nil
+}
+
 func (r *MemberRepository) CountMember(ctx context.Context) (n int64, err error) {
 	sqlQuery := `
 		SELECT COUNT(id) AS n
@@ -157,12 +157,100 @@ func (d *UserDeps) PutMemberProfile(w http.ResponseWriter, r *http.Request) {
 	out.HttpJSON(w, resp.NewHttpBody(out.Res))
 }
 
+func (d *UserDeps) PutProfilePassword(w http.ResponseWriter, r *http.Request) {
+	var jwtPayload jwt.JwtPrivateClaim
+	if err := jwt.DecodeCustomClaims(r, &jwtPayload); err != nil {
+		d.CaptureExeption(err)
+		resp.NewResponse(http.StatusInternalServerError, "", err).HttpJSON(w, nil)
+		return
+	}
+
+	var in ChangePasswordIn
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		d.CaptureExeption(err)
+		resp.NewResponse(http.StatusInternalServerError, "", err).HttpJSON(w, nil)
+		return
+	}
+
+	out := d.ChangePassword(r.Context(), jwtPayload.Uid, in)
+	if out.Error != nil {
+		d.CaptureExeption(out.Error)
+	}
+	out.HttpJSON(w, resp.NewHttpBody(out.Res))
+}
+
+func (d *UserDeps) PostForgotPassword(w http.ResponseWriter, r *http.Request) {
+	var in ForgotPasswordIn
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		d.CaptureExeption(err)
+		resp.NewResponse(http.StatusInternalServerError, "", err).HttpJSON(w, nil)
+		return
+	}
+
+	out := d.ForgotPassword(r.Context(), in)
+	if out.Error != nil {
+		d.CaptureExeption(out.Error)
+	}
+	out.HttpJSON(w, resp.NewHttpBody(out.Res))
+}
+
+func (d *UserDeps) PostResetPassword(w http.ResponseWriter, r *http.Request) {
+	var in ResetPasswordIn
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		d.CaptureExeption(err)
+		resp.NewResponse(http.StatusInternalServerError, "", err).HttpJSON(w, nil)
+		return
+	}
+
+	out := d.ResetPassword(r.Context(), in)
+	if out.Error != nil {
+		d.CaptureExeption(out.Error)
+	}
+	out.HttpJSON(w, resp.NewHttpBody(out.Res))
+}
+
 func (d *UserDeps) GetProfileMember(w http.ResponseWriter, r *http.Request) {
 	w.Header().Add("Content-Type", "application/json")
 
 	claims := r.Context().Value(jwtmiddleware.ContextKey{}).(*validator.ValidatedClaims)
 
-	payload, err := json.Marshal(claims)
+	var jwtPayload jwt.JwtPrivateClaim
+	if err := jwt.DecodeCustomClaims(r, &jwtPayload); err != nil {
+		d.CaptureExeption(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	unread, err := d.MemberRepository.CountUnreadNotification(r.Context(), jwtPayload.Uid)
+	if err != nil {
+		d.CaptureExeption(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	member, err := d.MemberRepository.FindById(r.Context(), jwtPayload.Uid)
+	if err != nil {
+		d.CaptureExeption(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	permissions, err := d.memberPermissions(r.Context(), member)
+	if err != nil {
+		d.CaptureExeption(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	payload, err := json.Marshal(struct {
+		*validator.ValidatedClaims
+		UnreadNotifications int64    `json:"unread_notifications"`
+		Permissions         []string `json:"permissions"`
+	}{
+		ValidatedClaims:     claims,
+		UnreadNotifications: unread,
+		Permissions:         permissions,
+	})
 	if err != nil {
 		d.CaptureExeption(err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -234,3 +322,11 @@ func (d *UserDeps) GetUserJwt(w http.ResponseWriter, r *http.Request) {
 	}
 	out.HttpJSON(w, resp.NewHttpBody(out.Res))
 }
+
+func (d *UserDeps) GetPasswordHashStats(w http.ResponseWriter, r *http.Request) {
+	out := d.QueryPasswordHashStats(r.Context())
+	if out.Error != nil {
+		d.CaptureExeption(out.Error)
+	}
+	out.HttpJSON(w, resp.NewHttpBody(out.Res))
+}
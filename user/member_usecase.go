@@ -10,19 +10,17 @@ import (
 	"time"
 
 	arbitary "github.com/PA-D3RPLA/d3if43-htt-uhomestay/arbitrary"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/audit"
 	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/filetype"
 	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/pagination"
 
 	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/httpdecode"
 	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/resp"
-	"github.com/fikryfahrezy/crypt/agron2"
 
-	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/jwt"
 	"github.com/gofrs/uuid"
 	pgtypeuuid "github.com/jackc/pgtype/ext/gofrs-uuid"
 	"github.com/jackc/pgx/v4"
 	"github.com/pkg/errors"
-	"golang.org/x/crypto/argon2"
 	"gopkg.in/guregu/null.v4"
 )
 
@@ -150,7 +148,7 @@ func (d *UserDeps) MemberSaver(ctx context.Context, in AddMemberIn, isApproved b
 		return
 	}
 
-	hash, err := agron2.Argon2Hash(in.Password, d.Argon2Salt, 1, 64*1024, 4, 32, argon2.Version, agron2.Argon2Id)
+	hash, err := d.hashPassword(in.Password)
 	if err != nil {
 		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "hashing password"))
 		return
@@ -194,6 +192,16 @@ func (d *UserDeps) MemberSaver(ctx context.Context, in AddMemberIn, isApproved b
 		return
 	}
 
+	if isApproved {
+		err = errors.Wrap(runMemberHooks(ctx, d.MemberHooks.OnApproved, memberId), "run member approved hooks")
+	} else {
+		err = errors.Wrap(runMemberHooks(ctx, d.MemberHooks.OnRegistered, memberId), "run member registered hooks")
+	}
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", err)
+		return
+	}
+
 	if len(positions) != 0 && periodId != 0 {
 		structures := make([]OrgStructureModel, len(positions))
 		for i, position := range positions {
@@ -226,12 +234,42 @@ func (d *UserDeps) AddMember(ctx context.Context, in AddMemberIn) (out AddMember
 		return
 	}
 
+	if in.IsAdmin.Bool {
+		allowed, err := d.canManageRole(ctx)
+		if err != nil {
+			out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "can manage role"))
+			return
+		}
+
+		if !allowed {
+			out.Response = resp.NewResponse(http.StatusForbidden, "", ErrRoleManageAdmin)
+			return
+		}
+	}
+
 	saverOut := d.MemberSaver(ctx, in, true)
 	if saverOut.Error != nil {
 		out.Response = saverOut.Response
 		return
 	}
 
+	member, err := d.MemberRepository.FindById(ctx, saverOut.Res.Id)
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "find member by id"))
+		return
+	}
+
+	err = d.Audit.Record(ctx, audit.Event{
+		Action:   audit.ActionCreate,
+		Entity:   "member",
+		EntityId: saverOut.Res.Id,
+		After:    member,
+	})
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "record audit"))
+		return
+	}
+
 	out.Res.Id = saverOut.Res.Id
 
 	return
@@ -299,7 +337,10 @@ type (
 		Password   string `json:"password"`
 	}
 	LoginRes struct {
-		Token string `json:"token"`
+		Token        string `json:"token"`
+		RefreshToken string `json:"refresh_token"`
+		ExpiresIn    int64  `json:"expires_in"`
+		ExpiresAt    string `json:"expires_at"`
 	}
 	LoginOut struct {
 		resp.Response
@@ -332,30 +373,19 @@ func (d *UserDeps) MemberLogin(ctx context.Context, in LoginIn) (out LoginOut) {
 		return
 	}
 
-	if err = agron2.Argon2Verify(member.Password, in.Password, agron2.Argon2Id); err != nil {
+	if err = d.verifyPassword(member, in.Password); err != nil {
 		out.Response = resp.NewResponse(http.StatusBadRequest, "", ErrPasswordNotMatch)
 		return
 	}
 
-	jwtToken, err := jwt.Sign(
-		"",
-		"token",
-		d.JwtIssuerUrl,
-		d.JwtKey,
-		d.JwtAudiences,
-		time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC),
-		time.Time{},
-		time.Time{},
-		jwt.JwtPrivateClaim{
-			Uid: member.Id.UUID.String(),
-		})
+	d.upgradePassword(ctx, member, in.Password)
+
+	out.Res, err = d.signToken(ctx, member, false)
 	if err != nil {
-		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "jwt signer"))
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "sign token"))
 		return
 	}
 
-	out.Res.Token = jwtToken
-
 	return
 }
 
@@ -379,7 +409,15 @@ func (d *UserDeps) AdminLogin(ctx context.Context, in LoginIn) (out LoginOut) {
 		return
 	}
 
-	if !member.IsAdmin {
+	// Member with any permission from the roles can login to the dashboard,
+	// the permission itself is checked per route.
+	permissions, err := d.memberPermissions(ctx, member)
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "member permissions"))
+		return
+	}
+
+	if len(permissions) == 0 {
 		out.Response = resp.NewResponse(http.StatusNotFound, "", ErrMemberNotFound)
 		return
 	}
@@ -389,31 +427,19 @@ func (d *UserDeps) AdminLogin(ctx context.Context, in LoginIn) (out LoginOut) {
 		return
 	}
 
-	if err = agron2.Argon2Verify(member.Password, in.Password, agron2.Argon2Id); err != nil {
+	if err = d.verifyPassword(member, in.Password); err != nil {
 		out.Response = resp.NewResponse(http.StatusBadRequest, "", ErrPasswordNotMatch)
 		return
 	}
 
-	jwtToken, err := jwt.Sign(
-		"",
-		"token",
-		d.JwtIssuerUrl,
-		d.JwtKey,
-		d.JwtAudiences,
-		time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC),
-		time.Time{},
-		time.Time{},
-		jwt.JwtPrivateAdminClaim{
-			Uid:     member.Id.UUID.String(),
-			IsAdmin: true,
-		})
+	d.upgradePassword(ctx, member, in.Password)
+
+	out.Res, err = d.signToken(ctx, member, member.IsAdmin)
 	if err != nil {
-		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "jwt signer"))
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "sign token"))
 		return
 	}
 
-	out.Res.Token = jwtToken
-
 	return
 }
 
@@ -468,6 +494,21 @@ func (d *UserDeps) EditMember(ctx context.Context, uid string, in EditMemberIn)
 		return
 	}
 
+	if in.IsAdmin.Bool != member.IsAdmin {
+		allowed, err := d.canManageRole(ctx)
+		if err != nil {
+			out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "can manage role"))
+			return
+		}
+
+		if !allowed {
+			out.Response = resp.NewResponse(http.StatusForbidden, "", ErrRoleManageAdmin)
+			return
+		}
+	}
+
+	before := member
+
 	orgStructure, err := d.OrgStructureRepository.FindLatestByMemberId(ctx, uid)
 	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
 		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "find user org structure by member id"))
@@ -530,6 +571,9 @@ func (d *UserDeps) EditMember(ctx context.Context, uid string, in EditMemberIn)
 	member.HomestayAddress = in.HomestayAddress
 	member.HomestayLatitude = in.HomestayLatitude
 	member.HomestayLongitude = in.HomestayLongitude
+	// Demoted admin or member with new password should login again.
+	shouldRevoke := (member.IsAdmin && !in.IsAdmin.Bool) || in.Password != ""
+
 	member.Username = in.Username
 	member.IsAdmin = in.IsAdmin.Bool
 
@@ -545,7 +589,7 @@ func (d *UserDeps) EditMember(ctx context.Context, uid string, in EditMemberIn)
 	}
 
 	if in.Password != "" {
-		hash, err := agron2.Argon2Hash(in.Password, d.Argon2Salt, 1, 64*1024, 4, 32, argon2.Version, agron2.Argon2Id)
+		hash, err := d.hashPassword(in.Password)
 		if err != nil {
 			out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "hashing password"))
 			return
@@ -592,6 +636,25 @@ func (d *UserDeps) EditMember(ctx context.Context, uid string, in EditMemberIn)
 		return
 	}
 
+	err = d.Audit.Record(ctx, audit.Event{
+		Action:   audit.ActionUpdate,
+		Entity:   "member",
+		EntityId: uid,
+		Before:   before,
+		After:    member,
+	})
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "record audit"))
+		return
+	}
+
+	if shouldRevoke {
+		if err = d.revokeMemberSessions(ctx, uid); err != nil {
+			out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "revoke member sessions"))
+			return
+		}
+	}
+
 	if periodId != orgStructure.OrgPeriodId || len(positions) != 0 {
 		memId := member.Id.UUID.String()
 		structures := make([]OrgStructureModel, len(positions))
@@ -641,7 +704,7 @@ func (d *UserDeps) RemoveMember(ctx context.Context, uid string) (out RemoveMemb
 		return
 	}
 
-	_, err = d.MemberRepository.FindById(ctx, uid)
+	member, err := d.MemberRepository.FindById(ctx, uid)
 	if errors.Is(err, pgx.ErrNoRows) {
 		out.Response = resp.NewResponse(http.StatusNotFound, "", ErrMemberNotFound)
 		return
@@ -656,6 +719,27 @@ func (d *UserDeps) RemoveMember(ctx context.Context, uid string) (out RemoveMemb
 		return
 	}
 
+	err = d.Audit.Record(ctx, audit.Event{
+		Action:   audit.ActionDelete,
+		Entity:   "member",
+		EntityId: uid,
+		Before:   member,
+	})
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "record audit"))
+		return
+	}
+
+	if err = runMemberHooks(ctx, d.MemberHooks.OnRemoved, uid); err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "run member removed hooks"))
+		return
+	}
+
+	if err = d.revokeMemberSessions(ctx, uid); err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "revoke member sessions"))
+		return
+	}
+
 	out.Res.Id = uid
 
 	return
@@ -959,12 +1043,30 @@ func (d *UserDeps) ApproveMember(ctx context.Context, uid string) (out MemberApp
 		return
 	}
 
+	before := member
 	member.IsApproved = true
 	if err = d.MemberRepository.Update(ctx, uid, member); err != nil {
 		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "update member"))
 		return
 	}
 
+	err = d.Audit.Record(ctx, audit.Event{
+		Action:   audit.ActionApprove,
+		Entity:   "member",
+		EntityId: uid,
+		Before:   before,
+		After:    member,
+	})
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "record audit"))
+		return
+	}
+
+	if err = runMemberHooks(ctx, d.MemberHooks.OnApproved, uid); err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "run member approved hooks"))
+		return
+	}
+
 	out.Res.Id = uid
 
 	return
@@ -1039,7 +1141,7 @@ func (d *UserDeps) UpdatProfile(ctx context.Context, uid string, in UpdateProfil
 	}
 
 	if in.Password != "" {
-		hash, err := agron2.Argon2Hash(in.Password, d.Argon2Salt, 1, 64*1024, 4, 32, argon2.Version, agron2.Argon2Id)
+		hash, err := d.hashPassword(in.Password)
 		if err != nil {
 			out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "hashing password"))
 			return
@@ -1111,25 +1213,12 @@ func (d *UserDeps) MemberLoginWithUsername(ctx context.Context, username string)
 		return
 	}
 
-	jwtToken, err := jwt.Sign(
-		"",
-		"token",
-		d.JwtIssuerUrl,
-		d.JwtKey,
-		d.JwtAudiences,
-		time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC),
-		time.Time{},
-		time.Time{},
-		jwt.JwtPrivateClaim{
-			Uid: member.Id.UUID.String(),
-		})
+	out.Res, err = d.signToken(ctx, member, false)
 	if err != nil {
-		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "jwt signer"))
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "sign token"))
 		return
 	}
 
-	out.Res.Token = jwtToken
-
 	return
 }
 
@@ -1158,26 +1247,12 @@ func (d *UserDeps) AdminLoginWithUsername(ctx context.Context, username string)
 		return
 	}
 
-	jwtToken, err := jwt.Sign(
-		"",
-		"token",
-		d.JwtIssuerUrl,
-		d.JwtKey,
-		d.JwtAudiences,
-		time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC),
-		time.Time{},
-		time.Time{},
-		jwt.JwtPrivateAdminClaim{
-			Uid:     member.Id.UUID.String(),
-			IsAdmin: true,
-		})
+	out.Res, err = d.signToken(ctx, member, true)
 	if err != nil {
-		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "jwt signer"))
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "sign token"))
 		return
 	}
 
-	out.Res.Token = jwtToken
-
 	return
 }
 
@@ -1201,48 +1276,11 @@ func (d *UserDeps) UserLoginWithUsername(ctx context.Context, username string) (
 		return
 	}
 
-	var jwtToken string
-
-	if member.IsAdmin {
-		jwtToken, err = jwt.Sign(
-			"",
-			"token",
-			d.JwtIssuerUrl,
-			d.JwtKey,
-			d.JwtAudiences,
-			time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC),
-			time.Time{},
-			time.Time{},
-			jwt.JwtPrivateAdminClaim{
-				Uid:     member.Id.UUID.String(),
-				IsAdmin: true,
-			})
-		if err != nil {
-			out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "jwt signer"))
-			return
-		}
-	}
-
-	if jwtToken == "" {
-		jwtToken, err = jwt.Sign(
-			"",
-			"token",
-			d.JwtIssuerUrl,
-			d.JwtKey,
-			d.JwtAudiences,
-			time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC),
-			time.Time{},
-			time.Time{},
-			jwt.JwtPrivateClaim{
-				Uid: member.Id.UUID.String(),
-			})
-		if err != nil {
-			out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "jwt signer"))
-			return
-		}
+	out.Res, err = d.signToken(ctx, member, member.IsAdmin)
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "sign token"))
+		return
 	}
 
-	out.Res.Token = jwtToken
-
 	return
 }
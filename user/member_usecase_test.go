@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	arbitary "github.com/PA-D3RPLA/d3if43-htt-uhomestay/arbitrary"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/audit"
 	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/httpdecode"
 	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/user"
 	"github.com/stretchr/testify/assert"
@@ -1167,6 +1168,10 @@ func TestRemoveMember(t *testing.T) {
 			}
 		})
 	}
+
+	if len(removedUids) == 0 || removedUids[len(removedUids)-1] != uid {
+		t.Fatalf("Expected removed hook to be run for %s. Got %v\n", uid, removedUids)
+	}
 }
 
 func TestQueryMember(t *testing.T) {
@@ -1314,6 +1319,21 @@ func TestApproveMember(t *testing.T) {
 			}
 		})
 	}
+
+	if len(approvedUids) == 0 || approvedUids[len(approvedUids)-1] != uid2 {
+		t.Fatalf("Expected approved hook to be run for %s. Got %v\n", uid2, approvedUids)
+	}
+
+	events := auditDeps.QueryAudit(context.Background(), audit.QueryAuditIn{
+		Entity: "member",
+	})
+	if events.Error != nil {
+		t.Fatal(events.Error)
+	}
+
+	if len(events.Res.Events) != 1 || events.Res.Events[0].Action != audit.ActionApprove || events.Res.Events[0].EntityId != uid2 {
+		t.Fatalf("Expected the approval of %s to be recorded. Got %#v\n", uid2, events.Res.Events)
+	}
 }
 
 func TestUpdatProfile(t *testing.T) {
@@ -1584,3 +1604,94 @@ func TestUpdatProfile(t *testing.T) {
 		})
 	}
 }
+
+func TestEditMemberAdminRequireRoleManage(t *testing.T) {
+	err := ClearTables(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	uid, err := createUser(memberRepository, memberNormal)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pr, err := orgPeriodRepository.Save(context.Background(), period)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ps, err := positionRepository.Save(context.Background(), position)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	role := userDeps.AddRole(context.Background(), user.AddRoleIn{
+		Name:        "Pengurus Anggota",
+		Permissions: []string{user.PermMemberWrite},
+	})
+	if role.Error != nil {
+		t.Fatal(role.Error)
+	}
+
+	res := userDeps.EditMemberRole(context.Background(), uid, user.EditMemberRoleIn{
+		RoleIds: []uint64{role.Res.Id},
+	})
+	if res.Error != nil {
+		t.Fatal(res.Error)
+	}
+
+	// The member holding member:write only try to make itself an admin.
+	ctx := context.WithValue(context.Background(), arbitary.ActorX{}, uid)
+
+	t.Run("Edit Member Fail, Set Admin Without Role Manage", func(t *testing.T) {
+		res := userDeps.EditMember(ctx, uid, user.EditMemberIn{
+			Name:              memberNormal.Name,
+			HomestayName:      memberNormal.HomestayName,
+			Username:          memberNormal.Username,
+			PositionIds:       []int64{int64(ps.Id)},
+			PeriodId:          int64(pr.Id),
+			WaPhone:           memberNormal.WaPhone,
+			OtherPhone:        memberNormal.OtherPhone,
+			HomestayAddress:   memberNormal.HomestayAddress,
+			HomestayLatitude:  memberNormal.HomestayLatitude,
+			HomestayLongitude: memberNormal.HomestayLongitude,
+			IsAdmin:           null.BoolFrom(true),
+		})
+
+		if res.StatusCode != http.StatusForbidden {
+			t.Logf("%#v", res)
+			t.Fatalf("Expected response code %d. Got %d\n", http.StatusForbidden, res.StatusCode)
+		}
+	})
+
+	t.Run("Add Member Fail, Set Admin Without Role Manage", func(t *testing.T) {
+		res := userDeps.AddMember(ctx, user.AddMemberIn{
+			Name:              "Name",
+			HomestayName:      "Homestay Name",
+			Username:          "newadmin",
+			PositionIds:       []int64{int64(ps.Id)},
+			PeriodId:          int64(pr.Id),
+			WaPhone:           "+62 821-1111-0010",
+			OtherPhone:        "+62 821-1111-0010",
+			HomestayAddress:   "Homestay Address",
+			HomestayLatitude:  "120.12312312",
+			HomestayLongitude: "90.1212321",
+			Password:          "password",
+			IsAdmin:           null.BoolFrom(true),
+		})
+
+		if res.StatusCode != http.StatusForbidden {
+			t.Logf("%#v", res)
+			t.Fatalf("Expected response code %d. Got %d\n", http.StatusForbidden, res.StatusCode)
+		}
+	})
+
+	member, err := memberRepository.FindById(context.Background(), uid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if member.IsAdmin {
+		t.Fatal("Expected member not to be admin")
+	}
+}
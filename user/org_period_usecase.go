@@ -7,6 +7,7 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/audit"
 	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/resp"
 	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/timediff"
 	"github.com/jackc/pgx/v4"
@@ -212,11 +213,27 @@ func (d *UserDeps) AddPeriod(ctx context.Context, in AddPeriodIn) (out AddPeriod
 		return
 	}
 
+	if err = d.Revision.Record(ctx, goalRevision(goal)); err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "record revision"))
+		return
+	}
+
 	if err = d.SaveOrgStructure(ctx, period.Id, in.Positions); err != nil {
 		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "build structure"))
 		return
 	}
 
+	err = d.Audit.Record(ctx, audit.Event{
+		Action:   audit.ActionCreate,
+		Entity:   "period",
+		EntityId: strconv.FormatUint(period.Id, 10),
+		After:    period,
+	})
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "record audit"))
+		return
+	}
+
 	out.Res.Id = period.Id
 
 	return
@@ -381,6 +398,7 @@ func (d *UserDeps) EditPeriod(ctx context.Context, pid string, in EditPeriodIn)
 		return
 	}
 
+	before := period
 	period.StartDate = startDate
 	period.EndDate = endDate
 
@@ -390,6 +408,18 @@ func (d *UserDeps) EditPeriod(ctx context.Context, pid string, in EditPeriodIn)
 		return
 	}
 
+	err = d.Audit.Record(ctx, audit.Event{
+		Action:   audit.ActionUpdate,
+		Entity:   "period",
+		EntityId: strconv.FormatUint(id, 10),
+		Before:   before,
+		After:    period,
+	})
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "record audit"))
+		return
+	}
+
 	if len(in.Positions) != 0 {
 		if err = d.OrgStructureRepository.DeleteByPeriodId(ctx, id); err != nil {
 			out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "delete org structure by period id"))
@@ -415,6 +445,11 @@ func (d *UserDeps) EditPeriod(ctx context.Context, pid string, in EditPeriodIn)
 			out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "save goal"))
 			return
 		}
+
+		if err = d.Revision.Record(ctx, goalRevision(goal)); err != nil {
+			out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "record revision"))
+			return
+		}
 	}
 
 	out.Res.Id = id
@@ -460,6 +495,17 @@ func (d *UserDeps) RemovePeriod(ctx context.Context, pid string) (out RemovePeri
 		return
 	}
 
+	err = d.Audit.Record(ctx, audit.Event{
+		Action:   audit.ActionDelete,
+		Entity:   "period",
+		EntityId: strconv.FormatUint(id, 10),
+		Before:   period,
+	})
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "record audit"))
+		return
+	}
+
 	if err = d.OrgPeriodRepository.UpdateStatusById(ctx, id, period); err != nil {
 		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "update period status by id"))
 		return
@@ -533,9 +579,22 @@ func (d *UserDeps) SwitchPeriodStatus(ctx context.Context, pid string, in Switch
 		return
 	}
 
+	before := period
 	period.IsActive = in.IsActive.Bool
 	out.Res.Id = id
 
+	err = d.Audit.Record(ctx, audit.Event{
+		Action:   audit.ActionUpdate,
+		Entity:   "period",
+		EntityId: strconv.FormatUint(id, 10),
+		Before:   before,
+		After:    period,
+	})
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "record audit"))
+		return
+	}
+
 	if period.IsActive {
 		if err = d.OrgPeriodRepository.DisableAll(ctx); err != nil {
 			out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "enable other last period"))
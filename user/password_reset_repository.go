@@ -0,0 +1,97 @@
+package user
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// PasswordResetRepository keep the hash of the password reset token, the
+// token itself is only known by the member.
+type PasswordResetRepository struct {
+	KeyPrefix string
+	RedisCl   *redis.Client
+}
+
+func NewPasswordResetRepository(keyPrefix string, redisCl *redis.Client) *PasswordResetRepository {
+	return &PasswordResetRepository{
+		KeyPrefix: keyPrefix,
+		RedisCl:   redisCl,
+	}
+}
+
+func (r *PasswordResetRepository) tokenKey(tokenHash string) string {
+	return r.KeyPrefix + ":token:" + tokenHash
+}
+
+func (r *PasswordResetRepository) memberKey(uid string) string {
+	return r.KeyPrefix + ":member:" + uid
+}
+
+func (r *PasswordResetRepository) requestKey(username string) string {
+	return r.KeyPrefix + ":request:" + username
+}
+
+// Save keep the token of the member until the ttl, the previous token of the
+// member is removed so only the latest token can be used.
+func (r *PasswordResetRepository) Save(ctx context.Context, uid, tokenHash string, ttl time.Duration) error {
+	prev, err := r.RedisCl.Get(ctx, r.memberKey(uid)).Result()
+	if err != nil && err != redis.Nil {
+		return err
+	}
+
+	_, err = r.RedisCl.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		if prev != "" {
+			pipe.Del(ctx, r.tokenKey(prev))
+		}
+		pipe.Set(ctx, r.tokenKey(tokenHash), uid, ttl)
+		pipe.Set(ctx, r.memberKey(uid), tokenHash, ttl)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Take return the member of the token and remove the token, so the token can
+// only be used once. It return redis.Nil when the token is unknown or expired.
+func (r *PasswordResetRepository) Take(ctx context.Context, tokenHash string) (uid string, err error) {
+	var get *redis.StringCmd
+	_, err = r.RedisCl.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		get = pipe.Get(ctx, r.tokenKey(tokenHash))
+		pipe.Del(ctx, r.tokenKey(tokenHash))
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	uid = get.Val()
+	if err = r.RedisCl.Del(ctx, r.memberKey(uid)).Err(); err != nil {
+		return "", err
+	}
+
+	return uid, nil
+}
+
+// CountRequest count the reset request of the username in the window started
+// by the first request.
+func (r *PasswordResetRepository) CountRequest(ctx context.Context, username string, window time.Duration) (int64, error) {
+	key := r.requestKey(username)
+
+	n, err := r.RedisCl.Incr(ctx, key).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	if n == 1 {
+		if err = r.RedisCl.Expire(ctx, key, window).Err(); err != nil {
+			return 0, err
+		}
+	}
+
+	return n, nil
+}
@@ -0,0 +1,303 @@
+package user
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/passhash"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/resp"
+	"github.com/go-redis/redis/v8"
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+)
+
+// passwordResetWindow is the window of the reset request limit per username.
+const passwordResetWindow = time.Hour
+
+var ErrResetTokenInvalid = errors.New("token reset password tidak valid atau sudah kedaluwarsa")
+
+func (d *UserDeps) hashPassword(password string) (string, error) {
+	return passhash.Hash(password, d.PasswordParams)
+}
+
+// verifyPassword check the password of the member, the hash made with the
+// legacy global salt is still accepted.
+func (d *UserDeps) verifyPassword(member MemberModel, password string) error {
+	return passhash.Verify(member.Password, password)
+}
+
+// upgradePassword rehash the verified password of the member when the hash is
+// made with the legacy global salt or other cost than the current one. The
+// login is not failed by it, the hash is upgraded on the next login instead.
+func (d *UserDeps) upgradePassword(ctx context.Context, member MemberModel, password string) {
+	if !passhash.NeedsRehash(member.Password, d.PasswordParams) {
+		return
+	}
+
+	hash, err := d.hashPassword(password)
+	if err != nil {
+		d.CaptureExeption(errors.Wrap(err, "hashing password"))
+		return
+	}
+
+	if err = d.MemberRepository.RehashPassword(ctx, member.Id.UUID.String(), member.Password, hash); err != nil {
+		d.CaptureExeption(errors.Wrap(err, "rehash password"))
+	}
+}
+
+func hashResetToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func newResetToken() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+type (
+	ChangePasswordIn struct {
+		OldPassword string `json:"old_password"`
+		NewPassword string `json:"new_password"`
+	}
+	ChangePasswordRes struct {
+		Id string `json:"id"`
+	}
+	ChangePasswordOut struct {
+		resp.Response
+		Res ChangePasswordRes
+	}
+)
+
+// ChangePassword replace the password of the member after the old password is
+// verified, every session of the member is revoked so the member login again.
+func (d *UserDeps) ChangePassword(ctx context.Context, uid string, in ChangePasswordIn) (out ChangePasswordOut) {
+	var err error
+	out.Response = resp.NewResponse(http.StatusOK, "", nil)
+
+	if _, err = uuid.FromString(uid); err != nil {
+		out.Response = resp.NewResponse(http.StatusNotFound, "", ErrMemberNotFound)
+		return
+	}
+
+	if err = ValidateChangePasswordIn(in); err != nil {
+		out.Response = resp.NewResponse(http.StatusUnprocessableEntity, "", err)
+		return
+	}
+
+	member, err := d.MemberRepository.FindById(ctx, uid)
+	if errors.Is(err, pgx.ErrNoRows) {
+		out.Response = resp.NewResponse(http.StatusNotFound, "", ErrMemberNotFound)
+		return
+	}
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "find member by id"))
+		return
+	}
+
+	if err = d.verifyPassword(member, in.OldPassword); err != nil {
+		out.Response = resp.NewResponse(http.StatusBadRequest, "", ErrPasswordNotMatch)
+		return
+	}
+
+	if err = d.setPassword(ctx, uid, in.NewPassword); err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", err)
+		return
+	}
+
+	out.Res.Id = uid
+
+	return
+}
+
+// setPassword hash and save the password of the member, then revoke every
+// session of the member.
+func (d *UserDeps) setPassword(ctx context.Context, uid, password string) error {
+	hash, err := d.hashPassword(password)
+	if err != nil {
+		return errors.Wrap(err, "hashing password")
+	}
+
+	if err = d.MemberRepository.UpdatePassword(ctx, uid, hash); err != nil {
+		return errors.Wrap(err, "update password")
+	}
+
+	if err = d.revokeMemberSessions(ctx, uid); err != nil {
+		return errors.Wrap(err, "revoke member sessions")
+	}
+
+	return nil
+}
+
+type (
+	ForgotPasswordIn struct {
+		Username string `json:"username"`
+	}
+	ForgotPasswordRes struct {
+		ExpiresIn int64 `json:"expires_in"`
+	}
+	ForgotPasswordOut struct {
+		resp.Response
+		Res ForgotPasswordRes
+	}
+)
+
+// ForgotPassword send a single use reset token to the member through the
+// password reset hooks. The response is the same whether the username exist or
+// not, so it can't be used to find the members.
+func (d *UserDeps) ForgotPassword(ctx context.Context, in ForgotPasswordIn) (out ForgotPasswordOut) {
+	var err error
+	out.Response = resp.NewResponse(http.StatusOK, "", nil)
+
+	if err = ValidateForgotPasswordIn(in); err != nil {
+		out.Response = resp.NewResponse(http.StatusUnprocessableEntity, "", err)
+		return
+	}
+
+	username := strings.Trim(in.Username, " ")
+
+	n, err := d.PasswordResetRepository.CountRequest(ctx, username, passwordResetWindow)
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "count reset request"))
+		return
+	}
+	if n > int64(d.PasswordResetLimit) {
+		out.Response = resp.NewResponse(http.StatusTooManyRequests, "", ErrResetPasswordTooOften)
+		return
+	}
+
+	out.Res.ExpiresIn = int64(d.PasswordResetExpiry.Seconds())
+
+	member, err := d.MemberRepository.FindByUsername(username)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return
+	}
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "find member by username"))
+		return
+	}
+
+	if !member.IsApproved {
+		return
+	}
+
+	token, err := newResetToken()
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "new reset token"))
+		return
+	}
+
+	uid := member.Id.UUID.String()
+	if err = d.PasswordResetRepository.Save(ctx, uid, hashResetToken(token), d.PasswordResetExpiry); err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "save reset token"))
+		return
+	}
+
+	expiresAt := time.Now().Add(d.PasswordResetExpiry)
+	for _, h := range d.MemberHooks.OnPasswordResetRequested {
+		if err = h(ctx, uid, token, expiresAt); err != nil {
+			out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "run password reset requested hooks"))
+			return
+		}
+	}
+
+	return
+}
+
+type (
+	ResetPasswordIn struct {
+		Token    string `json:"token"`
+		Password string `json:"password"`
+	}
+	ResetPasswordRes struct {
+		Id string `json:"id"`
+	}
+	ResetPasswordOut struct {
+		resp.Response
+		Res ResetPasswordRes
+	}
+)
+
+// ResetPassword replace the password of the member owning the reset token,
+// the token can't be used again.
+func (d *UserDeps) ResetPassword(ctx context.Context, in ResetPasswordIn) (out ResetPasswordOut) {
+	var err error
+	out.Response = resp.NewResponse(http.StatusOK, "", nil)
+
+	if err = ValidateResetPasswordIn(in); err != nil {
+		out.Response = resp.NewResponse(http.StatusUnprocessableEntity, "", err)
+		return
+	}
+
+	uid, err := d.PasswordResetRepository.Take(ctx, hashResetToken(strings.Trim(in.Token, " ")))
+	if errors.Is(err, redis.Nil) {
+		out.Response = resp.NewResponse(http.StatusBadRequest, "", ErrResetTokenInvalid)
+		return
+	}
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "take reset token"))
+		return
+	}
+
+	_, err = d.MemberRepository.FindById(ctx, uid)
+	if errors.Is(err, pgx.ErrNoRows) {
+		out.Response = resp.NewResponse(http.StatusBadRequest, "", ErrResetTokenInvalid)
+		return
+	}
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "find member by id"))
+		return
+	}
+
+	if err = d.setPassword(ctx, uid, in.Password); err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", err)
+		return
+	}
+
+	out.Res.Id = uid
+
+	return
+}
+
+type (
+	PasswordHashStatsRes struct {
+		Total    int64 `json:"total"`
+		Legacy   int64 `json:"legacy"`
+		Outdated int64 `json:"outdated"`
+	}
+	PasswordHashStatsOut struct {
+		resp.Response
+		Res PasswordHashStatsRes
+	}
+)
+
+// QueryPasswordHashStats count how many password hashes are still made with
+// the legacy global salt or the outdated cost, they are upgraded on login.
+func (d *UserDeps) QueryPasswordHashStats(ctx context.Context) (out PasswordHashStatsOut) {
+	out.Response = resp.NewResponse(http.StatusOK, "", nil)
+
+	m, err := d.MemberRepository.CountPasswordHash(ctx, d.PasswordParams.String())
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "count password hash"))
+		return
+	}
+
+	out.Res = PasswordHashStatsRes{
+		Total:    m.Total,
+		Legacy:   m.Legacy,
+		Outdated: m.Outdated,
+	}
+
+	return
+}
@@ -0,0 +1,308 @@
+package user_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/user"
+)
+
+func TestChangePassword(t *testing.T) {
+	err := ClearTables(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	uid, err := createUser(memberRepository, memberNormal)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		Name               string
+		ExpectedStatusCode int
+		Uid                string
+		In                 user.ChangePasswordIn
+	}{
+		{
+			Name:               "Change Password Fail, Old Password Required",
+			ExpectedStatusCode: http.StatusUnprocessableEntity,
+			Uid:                uid,
+			In: user.ChangePasswordIn{
+				NewPassword: "newpassword",
+			},
+		},
+		{
+			Name:               "Change Password Fail, Same Password",
+			ExpectedStatusCode: http.StatusUnprocessableEntity,
+			Uid:                uid,
+			In: user.ChangePasswordIn{
+				OldPassword: memberNormal.Password,
+				NewPassword: memberNormal.Password,
+			},
+		},
+		{
+			Name:               "Change Password Fail, Wrong Old Password",
+			ExpectedStatusCode: http.StatusBadRequest,
+			Uid:                uid,
+			In: user.ChangePasswordIn{
+				OldPassword: "wrongpassword",
+				NewPassword: "newpassword",
+			},
+		},
+		{
+			Name:               "Change Password Fail, Member Not Found",
+			ExpectedStatusCode: http.StatusNotFound,
+			Uid:                "not-uuid",
+			In: user.ChangePasswordIn{
+				OldPassword: memberNormal.Password,
+				NewPassword: "newpassword",
+			},
+		},
+		{
+			Name:               "Change Password Success",
+			ExpectedStatusCode: http.StatusOK,
+			Uid:                uid,
+			In: user.ChangePasswordIn{
+				OldPassword: memberNormal.Password,
+				NewPassword: "newpassword",
+			},
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.Name, func(t *testing.T) {
+			res := userDeps.ChangePassword(context.Background(), c.Uid, c.In)
+
+			if res.StatusCode != c.ExpectedStatusCode {
+				t.Logf("%#v", res)
+				t.Fatalf("Expected response code %d. Got %d\n", c.ExpectedStatusCode, res.StatusCode)
+			}
+		})
+	}
+
+	login := userDeps.MemberLogin(context.Background(), user.LoginIn{
+		Identifier: memberNormal.Username,
+		Password:   "newpassword",
+	})
+	if login.StatusCode != http.StatusOK {
+		t.Fatalf("Expected login with the new password code %d. Got %d\n", http.StatusOK, login.StatusCode)
+	}
+}
+
+func TestForgotPassword(t *testing.T) {
+	err := ClearTables(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = redisClient.FlushDB(context.Background()).Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	uid, err := createUser(memberRepository, memberNormal)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		Name               string
+		ExpectedStatusCode int
+		ExpectedToken      bool
+		In                 user.ForgotPasswordIn
+	}{
+		{
+			Name:               "Forgot Password Fail, Username Required",
+			ExpectedStatusCode: http.StatusUnprocessableEntity,
+			In:                 user.ForgotPasswordIn{},
+		},
+		{
+			Name:               "Forgot Password Unknown Username Success",
+			ExpectedStatusCode: http.StatusOK,
+			In: user.ForgotPasswordIn{
+				Username: "notexistusername",
+			},
+		},
+		{
+			Name:               "Forgot Password Success",
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedToken:      true,
+			In: user.ForgotPasswordIn{
+				Username: memberNormal.Username,
+			},
+		},
+		{
+			Name:               "Forgot Password Second Request Success",
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedToken:      true,
+			In: user.ForgotPasswordIn{
+				Username: memberNormal.Username,
+			},
+		},
+		{
+			Name:               "Forgot Password Third Request Success",
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedToken:      true,
+			In: user.ForgotPasswordIn{
+				Username: memberNormal.Username,
+			},
+		},
+		{
+			Name:               "Forgot Password Fail, Too Many Request",
+			ExpectedStatusCode: http.StatusTooManyRequests,
+			In: user.ForgotPasswordIn{
+				Username: memberNormal.Username,
+			},
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.Name, func(t *testing.T) {
+			delete(resetTokens, uid)
+			res := userDeps.ForgotPassword(context.Background(), c.In)
+
+			if res.StatusCode != c.ExpectedStatusCode {
+				t.Logf("%#v", res)
+				t.Fatalf("Expected response code %d. Got %d\n", c.ExpectedStatusCode, res.StatusCode)
+			}
+
+			if _, ok := resetTokens[uid]; ok != c.ExpectedToken {
+				t.Fatalf("Expected reset token sent %t. Got %t\n", c.ExpectedToken, ok)
+			}
+		})
+	}
+}
+
+func TestResetPassword(t *testing.T) {
+	err := ClearTables(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = redisClient.FlushDB(context.Background()).Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	uid, err := createUser(memberRepository, memberNormal)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	forgot := userDeps.ForgotPassword(context.Background(), user.ForgotPasswordIn{
+		Username: memberNormal.Username,
+	})
+	if forgot.StatusCode != http.StatusOK {
+		t.Fatalf("Expected response code %d. Got %d\n", http.StatusOK, forgot.StatusCode)
+	}
+
+	token := resetTokens[uid]
+
+	testCases := []struct {
+		Name               string
+		ExpectedStatusCode int
+		In                 user.ResetPasswordIn
+	}{
+		{
+			Name:               "Reset Password Fail, Token Required",
+			ExpectedStatusCode: http.StatusUnprocessableEntity,
+			In: user.ResetPasswordIn{
+				Password: "newpassword",
+			},
+		},
+		{
+			Name:               "Reset Password Fail, Invalid Token",
+			ExpectedStatusCode: http.StatusBadRequest,
+			In: user.ResetPasswordIn{
+				Token:    "not-a-reset-token",
+				Password: "newpassword",
+			},
+		},
+		{
+			Name:               "Reset Password Success",
+			ExpectedStatusCode: http.StatusOK,
+			In: user.ResetPasswordIn{
+				Token:    token,
+				Password: "newpassword",
+			},
+		},
+		{
+			Name:               "Reset Password Fail, Token Already Used",
+			ExpectedStatusCode: http.StatusBadRequest,
+			In: user.ResetPasswordIn{
+				Token:    token,
+				Password: "otherpassword",
+			},
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.Name, func(t *testing.T) {
+			res := userDeps.ResetPassword(context.Background(), c.In)
+
+			if res.StatusCode != c.ExpectedStatusCode {
+				t.Logf("%#v", res)
+				t.Fatalf("Expected response code %d. Got %d\n", c.ExpectedStatusCode, res.StatusCode)
+			}
+		})
+	}
+
+	login := userDeps.MemberLogin(context.Background(), user.LoginIn{
+		Identifier: memberNormal.Username,
+		Password:   "newpassword",
+	})
+	if login.StatusCode != http.StatusOK {
+		t.Fatalf("Expected login with the new password code %d. Got %d\n", http.StatusOK, login.StatusCode)
+	}
+}
+
+func TestLoginRehashPassword(t *testing.T) {
+	err := ClearTables(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	uid, err := createUser(memberRepository, memberNormal)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stats := userDeps.QueryPasswordHashStats(context.Background())
+	if stats.Error != nil {
+		t.Fatal(stats.Error)
+	}
+	if stats.Res.Total != 1 || stats.Res.Legacy != 1 {
+		t.Fatalf("Expected 1 legacy hash of 1. Got %#v\n", stats.Res)
+	}
+
+	legacy, err := memberRepository.FindById(context.Background(), uid)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		login := userDeps.MemberLogin(context.Background(), user.LoginIn{
+			Identifier: memberNormal.Username,
+			Password:   memberNormal.Password,
+		})
+		if login.StatusCode != http.StatusOK {
+			t.Fatalf("Expected login code %d. Got %d\n", http.StatusOK, login.StatusCode)
+		}
+	}
+
+	upgraded, err := memberRepository.FindById(context.Background(), uid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if upgraded.Password == legacy.Password {
+		t.Fatal("Expected the legacy hash rehashed")
+	}
+
+	stats = userDeps.QueryPasswordHashStats(context.Background())
+	if stats.Error != nil {
+		t.Fatal(stats.Error)
+	}
+	if stats.Res.Legacy != 0 || stats.Res.Outdated != 0 {
+		t.Fatalf("Expected no legacy or outdated hash. Got %#v\n", stats.Res)
+	}
+}
@@ -0,0 +1,86 @@
+package user
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sync/errgroup"
+)
+
+var (
+	ErrOldPasswordRequired   = errors.New("password lama tidak boleh kosong")
+	ErrNewPasswordRequired   = errors.New("password baru tidak boleh kosong")
+	ErrMaxNewPassword        = errors.New("password baru tidak dapat lebih dari 200 karakter")
+	ErrSamePassword          = errors.New("password baru tidak boleh sama dengan password lama")
+	ErrResetTokenRequired    = errors.New("token reset password tidak boleh kosong")
+	ErrResetPasswordTooOften = errors.New("terlalu banyak permintaan reset password, silakan coba lagi nanti")
+)
+
+func validateNewPassword(p string) error {
+	if strings.Trim(p, " ") == "" {
+		return ErrNewPasswordRequired
+	}
+
+	if utf8.RuneCountInString(p) > 200 {
+		return ErrMaxNewPassword
+	}
+
+	return nil
+}
+
+func ValidateChangePasswordIn(i ChangePasswordIn) error {
+	g := new(errgroup.Group)
+	g.Go(func() error {
+		if strings.Trim(i.OldPassword, " ") == "" {
+			return ErrOldPasswordRequired
+		}
+		return nil
+	})
+	g.Go(func() error {
+		return validateNewPassword(i.NewPassword)
+	})
+	g.Go(func() error {
+		if i.OldPassword == i.NewPassword {
+			return ErrSamePassword
+		}
+		return nil
+	})
+
+	if err := g.Wait(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func ValidateForgotPasswordIn(i ForgotPasswordIn) error {
+	if strings.Trim(i.Username, " ") == "" {
+		return ErrUsernameRequired
+	}
+
+	if utf8.RuneCountInString(i.Username) > 50 {
+		return ErrMaxUsername
+	}
+
+	return nil
+}
+
+func ValidateResetPasswordIn(i ResetPasswordIn) error {
+	g := new(errgroup.Group)
+	g.Go(func() error {
+		if strings.Trim(i.Token, " ") == "" {
+			return ErrResetTokenRequired
+		}
+		return nil
+	})
+	g.Go(func() error {
+		return validateNewPassword(i.Password)
+	})
+
+	if err := g.Wait(); err != nil {
+		return err
+	}
+
+	return nil
+}
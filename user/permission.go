@@ -0,0 +1,75 @@
+package user
+
+// Permissions checked by the permission middleware, admin member has every
+// permission while other member get them from the roles and from the position
+// held in the active period.
+const (
+	PermMemberWrite   = "member:write"
+	PermMemberApprove = "member:approve"
+	PermOrgWrite      = "org:write"
+	PermDocumentWrite = "document:write"
+	PermHistoryWrite  = "history:write"
+	PermBlogPublish   = "blog:publish"
+	PermGalleryWrite  = "gallery:write"
+	PermCashflowWrite = "cashflow:write"
+	PermDuesWrite     = "dues:write"
+	PermDuesVerify    = "dues:verify"
+	PermDashboardRead = "dashboard:read"
+	PermFileManage    = "file:manage"
+	PermRoleManage    = "role:manage"
+	PermAuditRead     = "audit:read"
+)
+
+type PermissionOut struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+var permissions = []PermissionOut{
+	{Name: PermMemberWrite, Description: "Menambah, mengubah dan menghapus anggota"},
+	{Name: PermMemberApprove, Description: "Menyetujui pendaftaran anggota"},
+	{Name: PermOrgWrite, Description: "Mengelola periode, jabatan beserta izinnya dan visi misi organisasi"},
+	{Name: PermDocumentWrite, Description: "Mengelola dokumen"},
+	{Name: PermHistoryWrite, Description: "Mengubah sejarah organisasi"},
+	{Name: PermBlogPublish, Description: "Menulis dan menerbitkan blog"},
+	{Name: PermGalleryWrite, Description: "Mengelola galeri foto"},
+	{Name: PermCashflowWrite, Description: "Mencatat dan mengekspor arus kas"},
+	{Name: PermDuesWrite, Description: "Mengelola tagihan iuran dan pengingatnya"},
+	{Name: PermDuesVerify, Description: "Memverifikasi pembayaran iuran anggota"},
+	{Name: PermDashboardRead, Description: "Melihat dashboard pengurus"},
+	{Name: PermFileManage, Description: "Mengelola file yang tidak terpakai"},
+	{Name: PermRoleManage, Description: "Mengelola peran dan izin anggota"},
+	{Name: PermAuditRead, Description: "Melihat catatan audit perubahan data"},
+}
+
+// IsPermission report whether the permission is known.
+func IsPermission(permission string) bool {
+	for _, p := range permissions {
+		if p.Name == permission {
+			return true
+		}
+	}
+
+	return false
+}
+
+// validatePermissions make sure every permission is known.
+func validatePermissions(permissions []string) error {
+	for _, p := range permissions {
+		if !IsPermission(p) {
+			return ErrUnknownPermission
+		}
+	}
+
+	return nil
+}
+
+// allPermissions is the permissions of the admin member.
+func allPermissions() []string {
+	ps := make([]string, len(permissions))
+	for i, p := range permissions {
+		ps[i] = p.Name
+	}
+
+	return ps
+}
@@ -69,6 +69,10 @@ func (r *PositionRepository) Save(ctx context.Context, m PositionModel) (nm Posi
 	m.CreatedAt = t
 	m.UpdatedAt = t
 
+	if err = r.SetPermissions(ctx, m.Id, m.Permissions); err != nil {
+		return PositionModel{}, err
+	}
+
 	return m, nil
 }
 
@@ -105,6 +109,49 @@ func (r *PositionRepository) UpdateById(ctx context.Context, id uint64, m Positi
 		return err
 	}
 
+	return r.SetPermissions(ctx, id, m.Permissions)
+}
+
+// SetPermissions replace the permissions of the position.
+func (r *PositionRepository) SetPermissions(ctx context.Context, id uint64, permissions []string) error {
+	deleteQuery := `
+		DELETE FROM position_permissions
+		WHERE position_id = $1
+	`
+	insertQuery := `
+		INSERT INTO position_permissions (position_id, permission)
+		SELECT $1::BIGINT, unnest($2::VARCHAR[])
+	`
+
+	var exec PositionExecutor
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		exec = tx.Exec
+	} else {
+		exec = r.PostgreDb.Exec
+	}
+
+	_, err := exec(
+		context.Background(),
+		deleteQuery,
+		id,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = exec(
+		context.Background(),
+		insertQuery,
+		id,
+		permissions,
+	)
+
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -116,7 +163,15 @@ func (r *PositionRepository) FindUndeletedById(ctx context.Context, id uint64) (
 			level,
 			created_at,
 			updated_at,
-			deleted_at
+			deleted_at,
+			COALESCE(
+				(
+					SELECT array_agg(pp.permission ORDER BY pp.permission)
+					FROM position_permissions pp
+					WHERE pp.position_id = positions.id
+				),
+				'{}'
+			) AS permissions
 		FROM positions
 		WHERE deleted_at IS NULL
 		AND id = $1
@@ -193,7 +248,15 @@ func (r *PositionRepository) Query(ctx context.Context, id, limit int64) ([]Posi
 			level,
 			created_at,
 			updated_at,
-			deleted_at
+			deleted_at,
+			COALESCE(
+				(
+					SELECT array_agg(pp.permission ORDER BY pp.permission)
+					FROM position_permissions pp
+					WHERE pp.position_id = positions.id
+				),
+				'{}'
+			) AS permissions
 		FROM positions
 		WHERE deleted_at IS NULL
 		 AND ` + fromId + `
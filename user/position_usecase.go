@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/audit"
 	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/resp"
 	"github.com/jackc/pgx/v4"
 	"github.com/pkg/errors"
@@ -14,8 +15,9 @@ var ErrPositionNotFound = errors.New("jabatan tidak ditemukan")
 
 type (
 	AddPositionIn struct {
-		Name  string `json:"name"`
-		Level int64  `json:"level"`
+		Name        string   `json:"name"`
+		Level       int64    `json:"level"`
+		Permissions []string `json:"permissions"`
 	}
 	AddPositionRes struct {
 		Id uint64 `json:"id"`
@@ -35,15 +37,40 @@ func (d *UserDeps) AddPosition(ctx context.Context, in AddPositionIn) (out AddPo
 		return
 	}
 
+	if len(in.Permissions) != 0 {
+		allowed, err := d.canManageRole(ctx)
+		if err != nil {
+			out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "can manage role"))
+			return
+		}
+
+		if !allowed {
+			out.Response = resp.NewResponse(http.StatusForbidden, "", ErrRoleManagePosPerm)
+			return
+		}
+	}
+
 	position := PositionModel{
-		Name:  in.Name,
-		Level: int16(in.Level),
+		Name:        in.Name,
+		Level:       int16(in.Level),
+		Permissions: uniquePermissions(in.Permissions),
 	}
 	if position, err = d.PositionRepository.Save(ctx, position); err != nil {
 		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "save position"))
 		return
 	}
 
+	err = d.Audit.Record(ctx, audit.Event{
+		Action:   audit.ActionCreate,
+		Entity:   "position",
+		EntityId: strconv.FormatUint(position.Id, 10),
+		After:    position,
+	})
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "record audit"))
+		return
+	}
+
 	out.Res.Id = position.Id
 
 	return
@@ -51,9 +78,10 @@ func (d *UserDeps) AddPosition(ctx context.Context, in AddPositionIn) (out AddPo
 
 type (
 	PositionOut struct {
-		Level int16  `json:"level"`
-		Id    uint64 `json:"id"`
-		Name  string `json:"name"`
+		Level       int16    `json:"level"`
+		Id          uint64   `json:"id"`
+		Name        string   `json:"name"`
+		Permissions []string `json:"permissions"`
 	}
 	QueryPositionRes struct {
 		Total     int64         `json:"total"`
@@ -98,9 +126,10 @@ func (d *UserDeps) QueryPosition(ctx context.Context, cursor, limit string) (out
 	outPoisitons := make([]PositionOut, posLen)
 	for i, p := range positions {
 		outPoisitons[i] = PositionOut{
-			Id:    p.Id,
-			Name:  p.Name,
-			Level: p.Level,
+			Id:          p.Id,
+			Name:        p.Name,
+			Level:       p.Level,
+			Permissions: p.Permissions,
 		}
 	}
 
@@ -148,8 +177,9 @@ func (d *UserDeps) QueryPositionLevel(ctx context.Context) (out QueryPositionLev
 
 type (
 	EditPositionIn struct {
-		Name  string `json:"name"`
-		Level int64  `json:"level"`
+		Name        string   `json:"name"`
+		Level       int64    `json:"level"`
+		Permissions []string `json:"permissions"`
 	}
 	EditPositionRes struct {
 		Id uint64 `json:"id"`
@@ -197,14 +227,47 @@ func (d *UserDeps) EditPosition(ctx context.Context, pid string, in EditPosition
 		return
 	}
 
+	// The permissions is kept when it is not given.
+	permissions := position.Permissions
+	if in.Permissions != nil {
+		permissions = uniquePermissions(in.Permissions)
+	}
+
+	if !samePermissions(permissions, position.Permissions) {
+		allowed, err := d.canManageRole(ctx)
+		if err != nil {
+			out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "can manage role"))
+			return
+		}
+
+		if !allowed {
+			out.Response = resp.NewResponse(http.StatusForbidden, "", ErrRoleManagePosPerm)
+			return
+		}
+	}
+
+	before := position
 	position.Name = in.Name
 	position.Level = int16(level)
+	position.Permissions = permissions
 
 	if err = d.PositionRepository.UpdateById(ctx, position.Id, position); err != nil {
 		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "update position by id"))
 		return
 	}
 
+	err = d.Audit.Record(ctx, audit.Event{
+		Action:   audit.ActionUpdate,
+		Entity:   "position",
+		EntityId: strconv.FormatUint(id, 10),
+		Before:   before,
+		After:    position,
+	})
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "record audit"))
+		return
+	}
+
 	if orgStruct.Id != 0 {
 		if err := d.OrgStructureRepository.UpdatePosByPosIdAndOrgId(ctx, position.Id, orgStruct.OrgPeriodId, position); err != nil {
 			out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "edit position by position id and org id"))
@@ -237,7 +300,7 @@ func (d *UserDeps) RemovePosition(ctx context.Context, pid string) (out RemovePo
 		return
 	}
 
-	_, err = d.PositionRepository.FindUndeletedById(ctx, id)
+	position, err := d.PositionRepository.FindUndeletedById(ctx, id)
 	if errors.Is(err, pgx.ErrNoRows) {
 		out.Response = resp.NewResponse(http.StatusNotFound, "", errors.Wrap(err, "no row find position by id"))
 		return
@@ -252,6 +315,17 @@ func (d *UserDeps) RemovePosition(ctx context.Context, pid string) (out RemovePo
 		return
 	}
 
+	err = d.Audit.Record(ctx, audit.Event{
+		Action:   audit.ActionDelete,
+		Entity:   "position",
+		EntityId: strconv.FormatUint(id, 10),
+		Before:   position,
+	})
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "record audit"))
+		return
+	}
+
 	out.Res.Id = id
 
 	return
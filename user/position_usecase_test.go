@@ -6,9 +6,11 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	arbitary "github.com/PA-D3RPLA/d3if43-htt-uhomestay/arbitrary"
 	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/user"
+	"gopkg.in/guregu/null.v4"
 )
 
 func TestAddPosition(t *testing.T) {
@@ -60,6 +62,24 @@ func TestAddPosition(t *testing.T) {
 				Level: 1,
 			},
 		},
+		{
+			Name:               "Add Position with Permissions Success",
+			ExpectedStatusCode: http.StatusCreated,
+			In: user.AddPositionIn{
+				Name:        "Bendahara",
+				Level:       2,
+				Permissions: []string{user.PermCashflowWrite, user.PermDuesVerify},
+			},
+		},
+		{
+			Name:               "Add Position Fail, Unknown Permission",
+			ExpectedStatusCode: http.StatusUnprocessableEntity,
+			In: user.AddPositionIn{
+				Name:        "Bendahara",
+				Level:       2,
+				Permissions: []string{"cashflow:everything"},
+			},
+		},
 	}
 
 	for _, c := range testCases {
@@ -237,6 +257,26 @@ func TestEditPosition(t *testing.T) {
 				Level: 1,
 			},
 		},
+		{
+			Name:               "Edit Position Permissions Success",
+			ExpectedStatusCode: http.StatusOK,
+			Id:                 pid,
+			In: user.EditPositionIn{
+				Name:        "Leader",
+				Level:       1,
+				Permissions: []string{user.PermOrgWrite},
+			},
+		},
+		{
+			Name:               "Edit Position Fail, Unknown Permission",
+			ExpectedStatusCode: http.StatusUnprocessableEntity,
+			Id:                 pid,
+			In: user.EditPositionIn{
+				Name:        "Leader",
+				Level:       1,
+				Permissions: []string{"org:everything"},
+			},
+		},
 	}
 
 	for _, c := range testCases {
@@ -309,3 +349,147 @@ func TestRemovePosition(t *testing.T) {
 		})
 	}
 }
+
+func TestPositionPermission(t *testing.T) {
+	err := ClearTables(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	treasurer := user.PositionModel{
+		Name:        "Bendahara",
+		Level:       2,
+		Permissions: []string{user.PermDuesVerify},
+	}
+
+	uid, periodId, _, err := createFullUser(userDeps, memberNormal, period, treasurer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	endedPeriod := user.OrgPeriodModel{
+		StartDate: time.Now().Add(-time.Hour * 24 * 400),
+		EndDate:   time.Now().Add(-time.Hour * 24 * 35),
+		IsActive:  true,
+	}
+
+	formerTreasurer := memberNormal
+	formerTreasurer.Username = "formertreasurer"
+	formerTreasurer.WaPhone = "+62 821-1111-9990"
+	formerTreasurer.OtherPhone = "+62 821-1111-9990"
+
+	endedUid, _, _, err := createFullUser(userDeps, formerTreasurer, endedPeriod, treasurer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hasPermission := func(uid string) bool {
+		ok, err := userDeps.HasPermission(context.Background(), uid, user.PermDuesVerify)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ok
+	}
+
+	if !hasPermission(uid) {
+		t.Fatal("Expected the permission of the position in the active period")
+	}
+
+	if hasPermission(endedUid) {
+		t.Fatal("Expected no permission after the period ended")
+	}
+
+	login := userDeps.AdminLogin(context.Background(), user.LoginIn{
+		Identifier: memberNormal.Username,
+		Password:   memberNormal.Password,
+	})
+	if login.StatusCode != http.StatusOK {
+		t.Fatalf("Expected admin login of position holder code %d. Got %d\n", http.StatusOK, login.StatusCode)
+	}
+
+	res := userDeps.SwitchPeriodStatus(context.Background(), strconv.FormatUint(periodId, 10), user.SwitchPeriodStatusIn{
+		IsActive: null.BoolFrom(false),
+	})
+	if res.Error != nil {
+		t.Fatal(res.Error)
+	}
+
+	if hasPermission(uid) {
+		t.Fatal("Expected no permission after the period is deactivated")
+	}
+}
+
+func TestPositionPermissionRequireRoleManage(t *testing.T) {
+	err := ClearTables(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	uid, err := createUser(memberRepository, memberNormal)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	role := userDeps.AddRole(context.Background(), user.AddRoleIn{
+		Name:        "Pengurus Organisasi",
+		Permissions: []string{user.PermOrgWrite},
+	})
+	if role.Error != nil {
+		t.Fatal(role.Error)
+	}
+
+	res := userDeps.EditMemberRole(context.Background(), uid, user.EditMemberRoleIn{
+		RoleIds: []uint64{role.Res.Id},
+	})
+	if res.Error != nil {
+		t.Fatal(res.Error)
+	}
+
+	ps, err := positionRepository.Save(context.Background(), position)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pid := strconv.FormatUint(ps.Id, 10)
+
+	// The member holding org:write only.
+	ctx := context.WithValue(context.Background(), arbitary.ActorX{}, uid)
+
+	t.Run("Add Position Fail, Permissions Without Role Manage", func(t *testing.T) {
+		res := userDeps.AddPosition(ctx, user.AddPositionIn{
+			Name:        "Ketua",
+			Level:       1,
+			Permissions: []string{user.PermRoleManage},
+		})
+
+		if res.StatusCode != http.StatusForbidden {
+			t.Logf("%#v", res)
+			t.Fatalf("Expected response code %d. Got %d\n", http.StatusForbidden, res.StatusCode)
+		}
+	})
+
+	t.Run("Edit Position Fail, Permissions Without Role Manage", func(t *testing.T) {
+		res := userDeps.EditPosition(ctx, pid, user.EditPositionIn{
+			Name:        "Leader",
+			Level:       1,
+			Permissions: []string{user.PermRoleManage},
+		})
+
+		if res.StatusCode != http.StatusForbidden {
+			t.Logf("%#v", res)
+			t.Fatalf("Expected response code %d. Got %d\n", http.StatusForbidden, res.StatusCode)
+		}
+	})
+
+	t.Run("Edit Position Success, Without Permissions", func(t *testing.T) {
+		res := userDeps.EditPosition(ctx, pid, user.EditPositionIn{
+			Name:  "Leader Baru",
+			Level: 1,
+		})
+
+		if res.StatusCode != http.StatusOK {
+			t.Logf("%#v", res)
+			t.Fatalf("Expected response code %d. Got %d\n", http.StatusOK, res.StatusCode)
+		}
+	})
+}
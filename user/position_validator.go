@@ -16,6 +16,9 @@ var (
 
 func ValidateAddPositionIn(i AddPositionIn) error {
 	g := new(errgroup.Group)
+	g.Go(func() error {
+		return validatePermissions(i.Permissions)
+	})
 	g.Go(func() error {
 		if strings.Trim(i.Name, " ") == "" {
 			return ErrPositionNameRequired
@@ -43,6 +46,9 @@ func ValidateAddPositionIn(i AddPositionIn) error {
 
 func ValidateEditPositionIn(i EditPositionIn) error {
 	g := new(errgroup.Group)
+	g.Go(func() error {
+		return validatePermissions(i.Permissions)
+	})
 	g.Go(func() error {
 		if strings.Trim(i.Name, " ") == "" {
 			return ErrPositionNameRequired
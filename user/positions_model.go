@@ -6,10 +6,12 @@ import (
 )
 
 type PositionModel struct {
-	Id        uint64
-	Name      string
-	Level     int16
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	DeletedAt sql.NullTime
+	Id    uint64
+	Name  string
+	Level int16
+	// Permissions is only loaded by FindUndeletedById and Query.
+	Permissions []string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	DeletedAt   sql.NullTime
 }
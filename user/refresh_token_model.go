@@ -0,0 +1,11 @@
+package user
+
+import "time"
+
+type RefreshTokenFamilyModel struct {
+	IsAdmin   bool
+	Id        string
+	MemberId  string
+	TokenHash string
+	CreatedAt time.Time
+}
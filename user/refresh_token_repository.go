@@ -0,0 +1,157 @@
+package user
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+type RefreshTokenRepository struct {
+	KeyPrefix string
+	RedisCl   *redis.Client
+}
+
+func NewRefreshTokenRepository(keyPrefix string, redisCl *redis.Client) *RefreshTokenRepository {
+	return &RefreshTokenRepository{
+		KeyPrefix: keyPrefix,
+		RedisCl:   redisCl,
+	}
+}
+
+func (r *RefreshTokenRepository) familyKey(id string) string {
+	return r.KeyPrefix + ":family:" + id
+}
+
+func (r *RefreshTokenRepository) memberKey(uid string) string {
+	return r.KeyPrefix + ":member:" + uid
+}
+
+func (r *RefreshTokenRepository) Save(ctx context.Context, m RefreshTokenFamilyModel, ttl time.Duration) error {
+	key := r.familyKey(m.Id)
+
+	_, err := r.RedisCl.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, key, map[string]interface{}{
+			"member_id":  m.MemberId,
+			"is_admin":   strconv.FormatBool(m.IsAdmin),
+			"token_hash": m.TokenHash,
+			"created_at": m.CreatedAt.Format(time.RFC3339),
+		})
+		pipe.Expire(ctx, key, ttl)
+		pipe.SAdd(ctx, r.memberKey(m.MemberId), m.Id)
+		pipe.Expire(ctx, r.memberKey(m.MemberId), ttl)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *RefreshTokenRepository) FindById(ctx context.Context, id string) (m RefreshTokenFamilyModel, err error) {
+	vals, err := r.RedisCl.HGetAll(ctx, r.familyKey(id)).Result()
+	if err != nil {
+		return RefreshTokenFamilyModel{}, err
+	}
+
+	if len(vals) == 0 {
+		return RefreshTokenFamilyModel{}, redis.Nil
+	}
+
+	isAdmin, _ := strconv.ParseBool(vals["is_admin"])
+	createdAt, _ := time.Parse(time.RFC3339, vals["created_at"])
+
+	m = RefreshTokenFamilyModel{
+		IsAdmin:   isAdmin,
+		Id:        id,
+		MemberId:  vals["member_id"],
+		TokenHash: vals["token_hash"],
+		CreatedAt: createdAt,
+	}
+
+	return m, nil
+}
+
+// rotateMaxRetries bound the retry of the rotation interrupted by other
+// request writing the same family.
+const rotateMaxRetries = 5
+
+// Rotate replace the current token of the family only if the given old hash
+// is still the current one, the returned bool is false when it's not,
+// which mean the old token is being reused. It return redis.Nil when the
+// family no longer exist.
+func (r *RefreshTokenRepository) Rotate(ctx context.Context, id, oldHash, newHash string, ttl time.Duration) (ok bool, err error) {
+	key := r.familyKey(id)
+
+	rotate := func(tx *redis.Tx) error {
+		current, err := tx.HGet(ctx, key, "token_hash").Result()
+		if err != nil {
+			return err
+		}
+
+		if current != oldHash {
+			return nil
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.HSet(ctx, key, "token_hash", newHash)
+			pipe.Expire(ctx, key, ttl)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		ok = true
+
+		return nil
+	}
+
+	// The family changed while being watched, read it again to tell apart
+	// the reused token from the write that didn't touch the token hash.
+	for i := 0; i < rotateMaxRetries; i++ {
+		err = r.RedisCl.Watch(ctx, rotate, key)
+		if err != redis.TxFailedErr {
+			break
+		}
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	return ok, nil
+}
+
+func (r *RefreshTokenRepository) DeleteById(ctx context.Context, id string) error {
+	_, err := r.RedisCl.Del(ctx, r.familyKey(id)).Result()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *RefreshTokenRepository) DeleteByMemberId(ctx context.Context, uid string) error {
+	memberKey := r.memberKey(uid)
+
+	ids, err := r.RedisCl.SMembers(ctx, memberKey).Result()
+	if err != nil {
+		return err
+	}
+
+	keys := make([]string, len(ids)+1)
+	for i, id := range ids {
+		keys[i] = r.familyKey(id)
+	}
+	keys[len(ids)] = memberKey
+
+	_, err = r.RedisCl.Del(ctx, keys...).Result()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,13 @@
+package user
+
+import (
+	"time"
+)
+
+type RoleModel struct {
+	Id          uint64
+	Name        string
+	Permissions []string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
@@ -0,0 +1,360 @@
+package user
+
+import (
+	"context"
+	"time"
+
+	arbitary "github.com/PA-D3RPLA/d3if43-htt-uhomestay/arbitrary"
+	"github.com/georgysavva/scany/pgxscan"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+type RoleRepository struct {
+	PostgreDb *pgxpool.Pool
+}
+
+func NewRoleRepository(postgreDb *pgxpool.Pool) *RoleRepository {
+	return &RoleRepository{
+		PostgreDb: postgreDb,
+	}
+}
+
+type (
+	RoleExecutor   func(ctx context.Context, sql string, arguments ...interface{}) (commandTag pgconn.CommandTag, err error)
+	RoleQuerierRow func(ctx context.Context, sql string, args ...interface{}) pgx.Row
+	RoleQuerier    func(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+)
+
+// roleSelect select the roles with their permissions, the query should be
+// followed by the where clause of the roles and the group by.
+const roleSelect = `
+	SELECT
+		r.id,
+		r.name,
+		COALESCE(
+			array_agg(rp.permission ORDER BY rp.permission) FILTER (WHERE rp.permission IS NOT NULL),
+			'{}'
+		) AS permissions,
+		r.created_at,
+		r.updated_at
+	FROM roles r
+	LEFT JOIN role_permissions rp ON rp.role_id = r.id
+`
+
+func (r *RoleRepository) Save(ctx context.Context, m RoleModel) (nm RoleModel, err error) {
+	sqlQuery := `
+		INSERT INTO roles (
+			name,
+			created_at,
+			updated_at
+		)
+		VALUES ($1, $2, $3)
+		RETURNING id
+	`
+
+	var queryRow RoleQuerierRow
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		queryRow = tx.QueryRow
+	} else {
+		queryRow = r.PostgreDb.QueryRow
+	}
+
+	t := time.Now()
+	err = queryRow(
+		context.Background(),
+		sqlQuery,
+		m.Name,
+		t,
+		t,
+	).Scan(&m.Id)
+
+	if err != nil {
+		return RoleModel{}, err
+	}
+
+	if err = r.SetPermissions(ctx, m.Id, m.Permissions); err != nil {
+		return RoleModel{}, err
+	}
+
+	m.CreatedAt = t
+	m.UpdatedAt = t
+
+	return m, nil
+}
+
+func (r *RoleRepository) UpdateById(ctx context.Context, id uint64, m RoleModel) error {
+	sqlQuery := `
+		UPDATE roles SET
+			name = $1,
+			updated_at = $2
+		WHERE id = $3
+	`
+
+	var exec RoleExecutor
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		exec = tx.Exec
+	} else {
+		exec = r.PostgreDb.Exec
+	}
+
+	_, err := exec(
+		context.Background(),
+		sqlQuery,
+		m.Name,
+		time.Now(),
+		id,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return r.SetPermissions(ctx, id, m.Permissions)
+}
+
+// SetPermissions replace the permissions of the role.
+func (r *RoleRepository) SetPermissions(ctx context.Context, id uint64, permissions []string) error {
+	deleteQuery := `
+		DELETE FROM role_permissions
+		WHERE role_id = $1
+	`
+	insertQuery := `
+		INSERT INTO role_permissions (role_id, permission)
+		SELECT $1::BIGINT, unnest($2::VARCHAR[])
+	`
+
+	var exec RoleExecutor
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		exec = tx.Exec
+	} else {
+		exec = r.PostgreDb.Exec
+	}
+
+	_, err := exec(
+		context.Background(),
+		deleteQuery,
+		id,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = exec(
+		context.Background(),
+		insertQuery,
+		id,
+		permissions,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteById delete the role, its permissions and the members assignment are
+// deleted along by the foreign key.
+func (r *RoleRepository) DeleteById(ctx context.Context, id uint64) error {
+	sqlQuery := `
+		DELETE FROM roles
+		WHERE id = $1
+	`
+
+	var exec RoleExecutor
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		exec = tx.Exec
+	} else {
+		exec = r.PostgreDb.Exec
+	}
+
+	_, err := exec(
+		context.Background(),
+		sqlQuery,
+		id,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *RoleRepository) FindById(ctx context.Context, id uint64) (m RoleModel, err error) {
+	sqlQuery := roleSelect + `
+		WHERE r.id = $1
+		GROUP BY r.id
+	`
+
+	var query RoleQuerier
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		query = tx.Query
+	} else {
+		query = r.PostgreDb.Query
+	}
+
+	rows, err := query(
+		context.Background(),
+		sqlQuery,
+		id,
+	)
+
+	if err != nil {
+		return RoleModel{}, err
+	}
+
+	if err = pgxscan.ScanOne(&m, rows); err != nil {
+		return RoleModel{}, err
+	}
+
+	return m, nil
+}
+
+func (r *RoleRepository) FindByName(ctx context.Context, name string) (m RoleModel, err error) {
+	sqlQuery := roleSelect + `
+		WHERE LOWER(r.name) = LOWER($1)
+		GROUP BY r.id
+	`
+
+	var query RoleQuerier
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		query = tx.Query
+	} else {
+		query = r.PostgreDb.Query
+	}
+
+	rows, err := query(
+		context.Background(),
+		sqlQuery,
+		name,
+	)
+
+	if err != nil {
+		return RoleModel{}, err
+	}
+
+	if err = pgxscan.ScanOne(&m, rows); err != nil {
+		return RoleModel{}, err
+	}
+
+	return m, nil
+}
+
+func (r *RoleRepository) Query(ctx context.Context) (ms []RoleModel, err error) {
+	sqlQuery := roleSelect + `
+		GROUP BY r.id
+		ORDER BY r.name
+	`
+
+	return r.query(ctx, sqlQuery)
+}
+
+func (r *RoleRepository) QueryByMemberId(ctx context.Context, uid string) (ms []RoleModel, err error) {
+	sqlQuery := roleSelect + `
+		WHERE r.id IN (
+			SELECT role_id
+			FROM member_roles
+			WHERE member_id = $1
+		)
+		GROUP BY r.id
+		ORDER BY r.name
+	`
+
+	return r.query(ctx, sqlQuery, uid)
+}
+
+func (r *RoleRepository) QueryInId(ctx context.Context, ids []uint64) (ms []RoleModel, err error) {
+	sqlQuery := roleSelect + `
+		WHERE r.id = ANY($1)
+		GROUP BY r.id
+		ORDER BY r.name
+	`
+
+	return r.query(ctx, sqlQuery, ids)
+}
+
+func (r *RoleRepository) query(ctx context.Context, sqlQuery string, args ...interface{}) (ms []RoleModel, err error) {
+	var query RoleQuerier
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		query = tx.Query
+	} else {
+		query = r.PostgreDb.Query
+	}
+
+	rows, err := query(
+		context.Background(),
+		sqlQuery,
+		args...,
+	)
+
+	if err != nil {
+		return []RoleModel{}, err
+	}
+
+	var mps []*RoleModel
+	if err = pgxscan.ScanAll(&mps, rows); err != nil {
+		return []RoleModel{}, err
+	}
+
+	ms = make([]RoleModel, len(mps))
+	for i, m := range mps {
+		ms[i] = *m
+	}
+
+	return ms, nil
+}
+
+// SetMemberRoles replace the roles of the member.
+func (r *RoleRepository) SetMemberRoles(ctx context.Context, uid string, ids []uint64) error {
+	deleteQuery := `
+		DELETE FROM member_roles
+		WHERE member_id = $1
+	`
+	insertQuery := `
+		INSERT INTO member_roles (member_id, role_id, created_at)
+		SELECT $1::UUID, unnest($2::BIGINT[]), $3::TIMESTAMP
+	`
+
+	var exec RoleExecutor
+	tx, ok := ctx.Value(arbitary.TrxX{}).(pgx.Tx)
+	if ok {
+		exec = tx.Exec
+	} else {
+		exec = r.PostgreDb.Exec
+	}
+
+	_, err := exec(
+		context.Background(),
+		deleteQuery,
+		uid,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = exec(
+		context.Background(),
+		insertQuery,
+		uid,
+		ids,
+		time.Now(),
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,486 @@
+package user
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	arbitary "github.com/PA-D3RPLA/d3if43-htt-uhomestay/arbitrary"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/audit"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/resp"
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrRoleNotFound      = errors.New("peran tidak ditemukan")
+	ErrRoleNameExist     = errors.New("nama peran sudah digunakan")
+	ErrRoleManageAdmin   = errors.New("hanya pengelola peran yang dapat mengubah status admin anggota")
+	ErrRoleManagePosPerm = errors.New("hanya pengelola peran yang dapat mengubah izin jabatan")
+)
+
+// canManageRole report whether the actor of the request can grant the admin
+// status or the permissions, which is given by role:manage or being admin.
+// The request without actor is run by the system itself.
+func (d *UserDeps) canManageRole(ctx context.Context) (bool, error) {
+	uid, ok := ctx.Value(arbitary.ActorX{}).(string)
+	if !ok || uid == "" {
+		return true, nil
+	}
+
+	return d.MemberRepository.HasPermission(ctx, uid, PermRoleManage)
+}
+
+// uniquePermissions drop the repeated permissions, keeping the order.
+func uniquePermissions(permissions []string) []string {
+	ps := make([]string, 0, len(permissions))
+	seen := make(map[string]bool, len(permissions))
+	for _, p := range permissions {
+		if seen[p] {
+			continue
+		}
+		seen[p] = true
+		ps = append(ps, p)
+	}
+
+	return ps
+}
+
+// samePermissions report whether both hold the same permissions regardless of
+// the order.
+func samePermissions(a, b []string) bool {
+	a, b = uniquePermissions(a), uniquePermissions(b)
+	if len(a) != len(b) {
+		return false
+	}
+
+	set := make(map[string]bool, len(a))
+	for _, p := range a {
+		set[p] = true
+	}
+	for _, p := range b {
+		if !set[p] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// memberPermissions list every permission of the member, the admin member has
+// all of them.
+func (d *UserDeps) memberPermissions(ctx context.Context, member MemberModel) ([]string, error) {
+	if member.IsAdmin {
+		return allPermissions(), nil
+	}
+
+	return d.MemberRepository.QueryPermission(ctx, member.Id.UUID.String())
+}
+
+// HasPermission report whether the member has the permission, it is used by
+// the permission middleware so the change of the roles take effect right away.
+func (d *UserDeps) HasPermission(ctx context.Context, uid, permission string) (bool, error) {
+	if _, err := uuid.FromString(uid); err != nil {
+		return false, nil
+	}
+
+	return d.MemberRepository.HasPermission(ctx, uid, permission)
+}
+
+type (
+	QueryPermissionRes struct {
+		Permissions []PermissionOut `json:"permissions"`
+	}
+	QueryPermissionOut struct {
+		resp.Response
+		Res QueryPermissionRes
+	}
+)
+
+// QueryPermission list every permission that can be given to a role.
+func (d *UserDeps) QueryPermission(ctx context.Context) (out QueryPermissionOut) {
+	out.Response = resp.NewResponse(http.StatusOK, "", nil)
+	out.Res.Permissions = permissions
+
+	return
+}
+
+type (
+	AddRoleIn struct {
+		Name        string   `json:"name"`
+		Permissions []string `json:"permissions"`
+	}
+	AddRoleRes struct {
+		Id uint64 `json:"id"`
+	}
+	AddRoleOut struct {
+		resp.Response
+		Res AddRoleRes
+	}
+)
+
+func (d *UserDeps) AddRole(ctx context.Context, in AddRoleIn) (out AddRoleOut) {
+	var err error
+	out.Response = resp.NewResponse(http.StatusCreated, "", nil)
+
+	if err = ValidateAddRoleIn(in); err != nil {
+		out.Response = resp.NewResponse(http.StatusUnprocessableEntity, "", err)
+		return
+	}
+
+	name := strings.Trim(in.Name, " ")
+
+	_, err = d.RoleRepository.FindByName(ctx, name)
+	if err == nil {
+		out.Response = resp.NewResponse(http.StatusBadRequest, "", ErrRoleNameExist)
+		return
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "find role by name"))
+		return
+	}
+
+	role, err := d.RoleRepository.Save(ctx, RoleModel{
+		Name:        name,
+		Permissions: uniquePermissions(in.Permissions),
+	})
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "save role"))
+		return
+	}
+
+	err = d.Audit.Record(ctx, audit.Event{
+		Action:   audit.ActionCreate,
+		Entity:   "role",
+		EntityId: strconv.FormatUint(role.Id, 10),
+		After:    role,
+	})
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "record audit"))
+		return
+	}
+
+	out.Res.Id = role.Id
+
+	return
+}
+
+type (
+	RoleOut struct {
+		Id          uint64   `json:"id"`
+		Name        string   `json:"name"`
+		Permissions []string `json:"permissions"`
+		UpdatedAt   string   `json:"updated_at"`
+	}
+	QueryRoleRes struct {
+		Roles []RoleOut `json:"roles"`
+	}
+	QueryRoleOut struct {
+		resp.Response
+		Res QueryRoleRes
+	}
+)
+
+func toRoleOuts(roles []RoleModel) []RoleOut {
+	outRoles := make([]RoleOut, len(roles))
+	for i, r := range roles {
+		outRoles[i] = RoleOut{
+			Id:          r.Id,
+			Name:        r.Name,
+			Permissions: r.Permissions,
+			UpdatedAt:   r.UpdatedAt.Format(time.RFC3339),
+		}
+	}
+
+	return outRoles
+}
+
+func (d *UserDeps) QueryRole(ctx context.Context) (out QueryRoleOut) {
+	out.Response = resp.NewResponse(http.StatusOK, "", nil)
+
+	roles, err := d.RoleRepository.Query(ctx)
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "query roles"))
+		return
+	}
+
+	out.Res.Roles = toRoleOuts(roles)
+
+	return
+}
+
+type (
+	EditRoleIn struct {
+		Name        string   `json:"name"`
+		Permissions []string `json:"permissions"`
+	}
+	EditRoleRes struct {
+		Id uint64 `json:"id"`
+	}
+	EditRoleOut struct {
+		resp.Response
+		Res EditRoleRes
+	}
+)
+
+func (d *UserDeps) EditRole(ctx context.Context, rid string, in EditRoleIn) (out EditRoleOut) {
+	var err error
+	out.Response = resp.NewResponse(http.StatusOK, "", nil)
+
+	id, err := strconv.ParseUint(rid, 10, 64)
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusNotFound, "", ErrRoleNotFound)
+		return
+	}
+
+	if err = ValidateEditRoleIn(in); err != nil {
+		out.Response = resp.NewResponse(http.StatusUnprocessableEntity, "", err)
+		return
+	}
+
+	role, err := d.RoleRepository.FindById(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		out.Response = resp.NewResponse(http.StatusNotFound, "", ErrRoleNotFound)
+		return
+	}
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "find role by id"))
+		return
+	}
+
+	name := strings.Trim(in.Name, " ")
+
+	other, err := d.RoleRepository.FindByName(ctx, name)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "find role by name"))
+		return
+	}
+	if err == nil && other.Id != role.Id {
+		out.Response = resp.NewResponse(http.StatusBadRequest, "", ErrRoleNameExist)
+		return
+	}
+
+	before := role
+	role.Name = name
+	role.Permissions = uniquePermissions(in.Permissions)
+
+	if err = d.RoleRepository.UpdateById(ctx, id, role); err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "update role by id"))
+		return
+	}
+
+	err = d.Audit.Record(ctx, audit.Event{
+		Action:   audit.ActionUpdate,
+		Entity:   "role",
+		EntityId: rid,
+		Before:   before,
+		After:    role,
+	})
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "record audit"))
+		return
+	}
+
+	out.Res.Id = id
+
+	return
+}
+
+type (
+	RemoveRoleRes struct {
+		Id uint64 `json:"id"`
+	}
+	RemoveRoleOut struct {
+		resp.Response
+		Res RemoveRoleRes
+	}
+)
+
+func (d *UserDeps) RemoveRole(ctx context.Context, rid string) (out RemoveRoleOut) {
+	var err error
+	out.Response = resp.NewResponse(http.StatusOK, "", nil)
+
+	id, err := strconv.ParseUint(rid, 10, 64)
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusNotFound, "", ErrRoleNotFound)
+		return
+	}
+
+	role, err := d.RoleRepository.FindById(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		out.Response = resp.NewResponse(http.StatusNotFound, "", ErrRoleNotFound)
+		return
+	}
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "find role by id"))
+		return
+	}
+
+	if err = d.RoleRepository.DeleteById(ctx, id); err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "delete role by id"))
+		return
+	}
+
+	err = d.Audit.Record(ctx, audit.Event{
+		Action:   audit.ActionDelete,
+		Entity:   "role",
+		EntityId: rid,
+		Before:   role,
+	})
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "record audit"))
+		return
+	}
+
+	out.Res.Id = id
+
+	return
+}
+
+type (
+	MemberRoleRes struct {
+		IsAdmin     bool      `json:"is_admin"`
+		Roles       []RoleOut `json:"roles"`
+		Permissions []string  `json:"permissions"`
+	}
+	MemberRoleOut struct {
+		resp.Response
+		Res MemberRoleRes
+	}
+)
+
+// QueryMemberRole list the roles of the member and the permissions the member
+// has from them.
+func (d *UserDeps) QueryMemberRole(ctx context.Context, uid string) (out MemberRoleOut) {
+	var err error
+	out.Response = resp.NewResponse(http.StatusOK, "", nil)
+
+	if _, err = uuid.FromString(uid); err != nil {
+		out.Response = resp.NewResponse(http.StatusNotFound, "", ErrMemberNotFound)
+		return
+	}
+
+	member, err := d.MemberRepository.FindById(ctx, uid)
+	if errors.Is(err, pgx.ErrNoRows) {
+		out.Response = resp.NewResponse(http.StatusNotFound, "", ErrMemberNotFound)
+		return
+	}
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "find member by id"))
+		return
+	}
+
+	roles, err := d.RoleRepository.QueryByMemberId(ctx, uid)
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "query roles by member id"))
+		return
+	}
+
+	permissions, err := d.memberPermissions(ctx, member)
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "member permissions"))
+		return
+	}
+
+	out.Res = MemberRoleRes{
+		IsAdmin:     member.IsAdmin,
+		Roles:       toRoleOuts(roles),
+		Permissions: permissions,
+	}
+
+	return
+}
+
+// memberRoleNames is the recorded state of the member roles.
+func memberRoleNames(roles []RoleModel) map[string][]string {
+	names := make([]string, len(roles))
+	for i, r := range roles {
+		names[i] = r.Name
+	}
+
+	return map[string][]string{"roles": names}
+}
+
+type (
+	EditMemberRoleIn struct {
+		RoleIds []uint64 `json:"role_ids"`
+	}
+	EditMemberRoleRes struct {
+		Id string `json:"id"`
+	}
+	EditMemberRoleOut struct {
+		resp.Response
+		Res EditMemberRoleRes
+	}
+)
+
+// EditMemberRole replace the roles of the member, an empty role ids remove
+// every role of the member.
+func (d *UserDeps) EditMemberRole(ctx context.Context, uid string, in EditMemberRoleIn) (out EditMemberRoleOut) {
+	var err error
+	out.Response = resp.NewResponse(http.StatusOK, "", nil)
+
+	if _, err = uuid.FromString(uid); err != nil {
+		out.Response = resp.NewResponse(http.StatusNotFound, "", ErrMemberNotFound)
+		return
+	}
+
+	_, err = d.MemberRepository.FindById(ctx, uid)
+	if errors.Is(err, pgx.ErrNoRows) {
+		out.Response = resp.NewResponse(http.StatusNotFound, "", ErrMemberNotFound)
+		return
+	}
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "find member by id"))
+		return
+	}
+
+	ids := make([]uint64, 0, len(in.RoleIds))
+	seen := make(map[uint64]bool, len(in.RoleIds))
+	for _, id := range in.RoleIds {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	roles, err := d.RoleRepository.QueryInId(ctx, ids)
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "query roles in id"))
+		return
+	}
+	if len(roles) != len(ids) {
+		out.Response = resp.NewResponse(http.StatusNotFound, "", ErrRoleNotFound)
+		return
+	}
+
+	prevRoles, err := d.RoleRepository.QueryByMemberId(ctx, uid)
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "query roles by member id"))
+		return
+	}
+
+	if err = d.RoleRepository.SetMemberRoles(ctx, uid, ids); err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "set member roles"))
+		return
+	}
+
+	err = d.Audit.Record(ctx, audit.Event{
+		Action:   audit.ActionUpdate,
+		Entity:   "member_role",
+		EntityId: uid,
+		Before:   memberRoleNames(prevRoles),
+		After:    memberRoleNames(roles),
+	})
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "record audit"))
+		return
+	}
+
+	out.Res.Id = uid
+
+	return
+}
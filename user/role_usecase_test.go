@@ -0,0 +1,250 @@
+package user_test
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/user"
+)
+
+var roleTreasurer = user.AddRoleIn{
+	Name:        "Bendahara",
+	Permissions: []string{user.PermCashflowWrite, user.PermDuesVerify},
+}
+
+func TestAddRole(t *testing.T) {
+	err := ClearTables(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		Name               string
+		ExpectedStatusCode int
+		In                 user.AddRoleIn
+	}{
+		{
+			Name:               "Add Role Success",
+			ExpectedStatusCode: http.StatusCreated,
+			In:                 roleTreasurer,
+		},
+		{
+			Name:               "Add Role Fail, Name Already Used",
+			ExpectedStatusCode: http.StatusBadRequest,
+			In:                 roleTreasurer,
+		},
+		{
+			Name:               "Add Role Fail, Name Required",
+			ExpectedStatusCode: http.StatusUnprocessableEntity,
+			In: user.AddRoleIn{
+				Permissions: []string{user.PermBlogPublish},
+			},
+		},
+		{
+			Name:               "Add Role Fail, Permission Required",
+			ExpectedStatusCode: http.StatusUnprocessableEntity,
+			In: user.AddRoleIn{
+				Name: "Editor Blog",
+			},
+		},
+		{
+			Name:               "Add Role Fail, Unknown Permission",
+			ExpectedStatusCode: http.StatusUnprocessableEntity,
+			In: user.AddRoleIn{
+				Name:        "Editor Blog",
+				Permissions: []string{"blog:everything"},
+			},
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.Name, func(t *testing.T) {
+			res := userDeps.AddRole(context.Background(), c.In)
+
+			if res.StatusCode != c.ExpectedStatusCode {
+				t.Logf("%#v", res)
+				t.Fatalf("Expected response code %d. Got %d\n", c.ExpectedStatusCode, res.StatusCode)
+			}
+		})
+	}
+}
+
+func TestEditRole(t *testing.T) {
+	err := ClearTables(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	role := userDeps.AddRole(context.Background(), roleTreasurer)
+	if role.Error != nil {
+		t.Fatal(role.Error)
+	}
+
+	other := userDeps.AddRole(context.Background(), user.AddRoleIn{
+		Name:        "Sekretaris",
+		Permissions: []string{user.PermDocumentWrite},
+	})
+	if other.Error != nil {
+		t.Fatal(other.Error)
+	}
+
+	rid := strconv.FormatUint(role.Res.Id, 10)
+
+	testCases := []struct {
+		Name               string
+		ExpectedStatusCode int
+		Id                 string
+		In                 user.EditRoleIn
+	}{
+		{
+			Name:               "Edit Role Success",
+			ExpectedStatusCode: http.StatusOK,
+			Id:                 rid,
+			In: user.EditRoleIn{
+				Name:        "Bendahara Umum",
+				Permissions: []string{user.PermDuesVerify, user.PermDuesWrite},
+			},
+		},
+		{
+			Name:               "Edit Role Fail, Name Used By Other Role",
+			ExpectedStatusCode: http.StatusBadRequest,
+			Id:                 rid,
+			In: user.EditRoleIn{
+				Name:        "Sekretaris",
+				Permissions: []string{user.PermDuesVerify},
+			},
+		},
+		{
+			Name:               "Edit Role Fail, Role Not Found",
+			ExpectedStatusCode: http.StatusNotFound,
+			Id:                 "999",
+			In: user.EditRoleIn{
+				Name:        "Bendahara",
+				Permissions: []string{user.PermDuesVerify},
+			},
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.Name, func(t *testing.T) {
+			res := userDeps.EditRole(context.Background(), c.Id, c.In)
+
+			if res.StatusCode != c.ExpectedStatusCode {
+				t.Logf("%#v", res)
+				t.Fatalf("Expected response code %d. Got %d\n", c.ExpectedStatusCode, res.StatusCode)
+			}
+		})
+	}
+
+	roles := userDeps.QueryRole(context.Background())
+	if roles.Error != nil {
+		t.Fatal(roles.Error)
+	}
+
+	for _, r := range roles.Res.Roles {
+		if r.Id == role.Res.Id && len(r.Permissions) != 2 {
+			t.Fatalf("Expected 2 permissions of the edited role. Got %v\n", r.Permissions)
+		}
+	}
+}
+
+func TestEditMemberRole(t *testing.T) {
+	err := ClearTables(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	uid, err := createUser(memberRepository, memberNormal)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	role := userDeps.AddRole(context.Background(), roleTreasurer)
+	if role.Error != nil {
+		t.Fatal(role.Error)
+	}
+
+	testCases := []struct {
+		Name               string
+		ExpectedStatusCode int
+		Uid                string
+		In                 user.EditMemberRoleIn
+	}{
+		{
+			Name:               "Edit Member Role Fail, Role Not Found",
+			ExpectedStatusCode: http.StatusNotFound,
+			Uid:                uid,
+			In: user.EditMemberRoleIn{
+				RoleIds: []uint64{role.Res.Id, 999},
+			},
+		},
+		{
+			Name:               "Edit Member Role Fail, Member Not Found",
+			ExpectedStatusCode: http.StatusNotFound,
+			Uid:                "not-uuid",
+			In: user.EditMemberRoleIn{
+				RoleIds: []uint64{role.Res.Id},
+			},
+		},
+		{
+			Name:               "Edit Member Role Success",
+			ExpectedStatusCode: http.StatusOK,
+			Uid:                uid,
+			In: user.EditMemberRoleIn{
+				RoleIds: []uint64{role.Res.Id, role.Res.Id},
+			},
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.Name, func(t *testing.T) {
+			res := userDeps.EditMemberRole(context.Background(), c.Uid, c.In)
+
+			if res.StatusCode != c.ExpectedStatusCode {
+				t.Logf("%#v", res)
+				t.Fatalf("Expected response code %d. Got %d\n", c.ExpectedStatusCode, res.StatusCode)
+			}
+		})
+	}
+
+	for _, p := range []struct {
+		permission string
+		expected   bool
+	}{
+		{permission: user.PermDuesVerify, expected: true},
+		{permission: user.PermCashflowWrite, expected: true},
+		{permission: user.PermBlogPublish, expected: false},
+	} {
+		ok, err := userDeps.HasPermission(context.Background(), uid, p.permission)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok != p.expected {
+			t.Fatalf("Expected permission %s %t. Got %t\n", p.permission, p.expected, ok)
+		}
+	}
+
+	login := userDeps.AdminLogin(context.Background(), user.LoginIn{
+		Identifier: memberNormal.Username,
+		Password:   memberNormal.Password,
+	})
+	if login.StatusCode != http.StatusOK {
+		t.Fatalf("Expected admin login of member with role code %d. Got %d\n", http.StatusOK, login.StatusCode)
+	}
+
+	// Removing the role take the permissions right away.
+	remove := userDeps.RemoveRole(context.Background(), strconv.FormatUint(role.Res.Id, 10))
+	if remove.Error != nil {
+		t.Fatal(remove.Error)
+	}
+
+	ok, err := userDeps.HasPermission(context.Background(), uid, user.PermDuesVerify)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok {
+		t.Fatal("Expected no permission after the role is removed")
+	}
+}
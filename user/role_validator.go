@@ -0,0 +1,72 @@
+package user
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sync/errgroup"
+)
+
+var (
+	ErrRoleNameRequired       = errors.New("nama peran tidak boleh kosong")
+	ErrMaxRoleName            = errors.New("nama peran tidak dapat lebih dari 100 karakter")
+	ErrRolePermissionRequired = errors.New("izin peran tidak boleh kosong")
+	ErrUnknownPermission      = errors.New("izin tidak dikenal")
+)
+
+func validateRolePermissions(permissions []string) error {
+	if len(permissions) == 0 {
+		return ErrRolePermissionRequired
+	}
+
+	return validatePermissions(permissions)
+}
+
+func ValidateAddRoleIn(i AddRoleIn) error {
+	g := new(errgroup.Group)
+	g.Go(func() error {
+		if strings.Trim(i.Name, " ") == "" {
+			return ErrRoleNameRequired
+		}
+		return nil
+	})
+	g.Go(func() error {
+		if utf8.RuneCountInString(i.Name) > 100 {
+			return ErrMaxRoleName
+		}
+		return nil
+	})
+	g.Go(func() error {
+		return validateRolePermissions(i.Permissions)
+	})
+
+	if err := g.Wait(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func ValidateEditRoleIn(i EditRoleIn) error {
+	g := new(errgroup.Group)
+	g.Go(func() error {
+		if strings.Trim(i.Name, " ") == "" {
+			return ErrRoleNameRequired
+		}
+		return nil
+	})
+	g.Go(func() error {
+		if utf8.RuneCountInString(i.Name) > 100 {
+			return ErrMaxRoleName
+		}
+		return nil
+	})
+	g.Go(func() error {
+		return validateRolePermissions(i.Permissions)
+	})
+
+	if err := g.Wait(); err != nil {
+		return err
+	}
+	return nil
+}
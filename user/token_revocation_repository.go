@@ -0,0 +1,81 @@
+package user
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+type TokenRevocationRepository struct {
+	KeyPrefix string
+	RedisCl   *redis.Client
+}
+
+func NewTokenRevocationRepository(keyPrefix string, redisCl *redis.Client) *TokenRevocationRepository {
+	return &TokenRevocationRepository{
+		KeyPrefix: keyPrefix,
+		RedisCl:   redisCl,
+	}
+}
+
+func (r *TokenRevocationRepository) jtiKey(jti string) string {
+	return r.KeyPrefix + ":jti:" + jti
+}
+
+func (r *TokenRevocationRepository) memberKey(uid string) string {
+	return r.KeyPrefix + ":member:" + uid
+}
+
+// SaveJti deny the token with the given id, the ttl should be the remaining
+// lifetime of the token since expired token is already rejected.
+func (r *TokenRevocationRepository) SaveJti(ctx context.Context, jti string, ttl time.Duration) error {
+	if ttl <= 0 {
+		return nil
+	}
+
+	_, err := r.RedisCl.Set(ctx, r.jtiKey(jti), 1, ttl).Result()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// SaveMember deny every token of the member issued up to the second of the
+// given time. The issued at of the token is in whole second, so the token
+// issued in the same second is denied too, even if it is issued right after.
+func (r *TokenRevocationRepository) SaveMember(ctx context.Context, uid string, t time.Time, ttl time.Duration) error {
+	_, err := r.RedisCl.Set(ctx, r.memberKey(uid), t.Unix(), ttl).Result()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *TokenRevocationRepository) IsRevoked(ctx context.Context, jti, uid string, issuedAt int64) (bool, error) {
+	pipe := r.RedisCl.Pipeline()
+	jtiExist := pipe.Exists(ctx, r.jtiKey(jti))
+	revokedAt := pipe.Get(ctx, r.memberKey(uid))
+
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return false, err
+	}
+
+	if jtiExist.Val() != 0 {
+		return true, nil
+	}
+
+	if revokedAt.Err() == redis.Nil {
+		return false, nil
+	}
+
+	at, err := strconv.ParseInt(revokedAt.Val(), 10, 64)
+	if err != nil {
+		return false, err
+	}
+
+	return issuedAt <= at, nil
+}
@@ -0,0 +1,328 @@
+package user
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+
+	arbitary "github.com/PA-D3RPLA/d3if43-htt-uhomestay/arbitrary"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/audit"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/jwt"
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/resp"
+	"github.com/go-redis/redis/v8"
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrRefreshTokenInvalid = errors.New("refresh token tidak valid, silakan login kembali")
+	ErrRefreshTokenReused  = errors.New("refresh token sudah pernah digunakan, silakan login kembali")
+)
+
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// Refresh token is formatted as `<family id>.<random secret>`, so the family
+// can be looked up without storing the token itself.
+func newRefreshToken(familyId string) (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+
+	return familyId + "." + base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+func (d *UserDeps) signAccessToken(uid string, isAdmin bool, now time.Time) (token string, expiry time.Time, err error) {
+	jti, err := uuid.NewV4()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	var privateClaim interface{} = jwt.JwtPrivateClaim{
+		Uid: uid,
+	}
+	if isAdmin {
+		privateClaim = jwt.JwtPrivateAdminClaim{
+			Uid:     uid,
+			IsAdmin: true,
+		}
+	}
+
+	expiry = now.Add(d.AccessTokenExpiry)
+	token, err = jwt.Sign(
+		jti.String(),
+		"token",
+		d.JwtIssuerUrl,
+		d.JwtKey,
+		d.JwtAudiences,
+		time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC),
+		expiry,
+		now,
+		privateClaim,
+	)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return token, expiry, nil
+}
+
+// signToken start a new refresh token family for the member and sign the first
+// access token of the family.
+func (d *UserDeps) signToken(ctx context.Context, member MemberModel, isAdmin bool) (res LoginRes, err error) {
+	now := time.Now()
+	uid := member.Id.UUID.String()
+
+	familyId, err := uuid.NewV4()
+	if err != nil {
+		return LoginRes{}, errors.Wrap(err, "generate uuid")
+	}
+
+	refreshToken, err := newRefreshToken(familyId.String())
+	if err != nil {
+		return LoginRes{}, errors.Wrap(err, "generate refresh token")
+	}
+
+	family := RefreshTokenFamilyModel{
+		IsAdmin:   isAdmin,
+		Id:        familyId.String(),
+		MemberId:  uid,
+		TokenHash: hashRefreshToken(refreshToken),
+		CreatedAt: now,
+	}
+	if err = d.RefreshTokenRepository.Save(ctx, family, d.RefreshTokenExpiry); err != nil {
+		return LoginRes{}, errors.Wrap(err, "save refresh token family")
+	}
+
+	token, expiry, err := d.signAccessToken(uid, isAdmin, now)
+	if err != nil {
+		return LoginRes{}, errors.Wrap(err, "jwt signer")
+	}
+
+	res = LoginRes{
+		Token:        token,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(d.AccessTokenExpiry.Seconds()),
+		ExpiresAt:    expiry.Format(time.RFC3339),
+	}
+
+	return res, nil
+}
+
+type (
+	RefreshTokenIn struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+)
+
+func (d *UserDeps) RefreshToken(ctx context.Context, in RefreshTokenIn) (out LoginOut) {
+	var err error
+	out.Response = resp.NewResponse(http.StatusOK, "", nil)
+
+	if err = ValidateRefreshTokenIn(in); err != nil {
+		out.Response = resp.NewResponse(http.StatusUnprocessableEntity, "", err)
+		return
+	}
+
+	familyId, _, ok := strings.Cut(in.RefreshToken, ".")
+	if !ok {
+		out.Response = resp.NewResponse(http.StatusUnauthorized, "", ErrRefreshTokenInvalid)
+		return
+	}
+
+	family, err := d.RefreshTokenRepository.FindById(ctx, familyId)
+	if errors.Is(err, redis.Nil) {
+		out.Response = resp.NewResponse(http.StatusUnauthorized, "", ErrRefreshTokenInvalid)
+		return
+	}
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "find refresh token family"))
+		return
+	}
+
+	member, err := d.MemberRepository.FindById(ctx, family.MemberId)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "find member by id"))
+		return
+	}
+
+	if errors.Is(err, pgx.ErrNoRows) || !member.IsApproved {
+		if err = d.RefreshTokenRepository.DeleteById(ctx, familyId); err != nil {
+			out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "delete refresh token family"))
+			return
+		}
+
+		out.Response = resp.NewResponse(http.StatusUnauthorized, "", ErrRefreshTokenInvalid)
+		return
+	}
+
+	refreshToken, err := newRefreshToken(familyId)
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "generate refresh token"))
+		return
+	}
+
+	rotated, err := d.RefreshTokenRepository.Rotate(ctx, familyId, hashRefreshToken(in.RefreshToken), hashRefreshToken(refreshToken), d.RefreshTokenExpiry)
+	if errors.Is(err, redis.Nil) {
+		out.Response = resp.NewResponse(http.StatusUnauthorized, "", ErrRefreshTokenInvalid)
+		return
+	}
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "rotate refresh token"))
+		return
+	}
+
+	// The presented token is not the latest one of its family, either it was
+	// stolen or the client is compromised, so revoke the whole family.
+	if !rotated {
+		if err = d.RefreshTokenRepository.DeleteById(ctx, familyId); err != nil {
+			out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "delete refresh token family"))
+			return
+		}
+
+		d.CaptureMessage("refresh token reused for member " + family.MemberId)
+		out.Response = resp.NewResponse(http.StatusUnauthorized, "", ErrRefreshTokenReused)
+		return
+	}
+
+	token, expiry, err := d.signAccessToken(family.MemberId, family.IsAdmin && member.IsAdmin, time.Now())
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "jwt signer"))
+		return
+	}
+
+	out.Res = LoginRes{
+		Token:        token,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(d.AccessTokenExpiry.Seconds()),
+		ExpiresAt:    expiry.Format(time.RFC3339),
+	}
+
+	return
+}
+
+func (d *UserDeps) IsTokenRevoked(ctx context.Context, jti, uid string, issuedAt int64) (bool, error) {
+	return d.TokenRevocationRepository.IsRevoked(ctx, jti, uid, issuedAt)
+}
+
+// revokeMemberSessions deny every access token issued to the member so far and
+// remove all of its refresh token family, so the member need to login again.
+// It is done once the transaction is committed, so the rolled back change
+// doesn't log the member out.
+func (d *UserDeps) revokeMemberSessions(ctx context.Context, uid string) error {
+	return arbitary.AfterCommit(ctx, func() error {
+		if err := d.TokenRevocationRepository.SaveMember(ctx, uid, time.Now(), d.AccessTokenExpiry); err != nil {
+			return errors.Wrap(err, "save member revocation")
+		}
+
+		if err := d.RefreshTokenRepository.DeleteByMemberId(ctx, uid); err != nil {
+			return errors.Wrap(err, "delete refresh token family by member id")
+		}
+
+		return nil
+	})
+}
+
+type (
+	LogoutIn struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	LogoutRes struct {
+		Id string `json:"id"`
+	}
+	LogoutOut struct {
+		resp.Response
+		Res LogoutRes
+	}
+)
+
+func (d *UserDeps) Logout(ctx context.Context, uid, jti string, expiry int64, in LogoutIn) (out LogoutOut) {
+	var err error
+	out.Response = resp.NewResponse(http.StatusOK, "", nil)
+
+	if jti != "" {
+		if err = d.TokenRevocationRepository.SaveJti(ctx, jti, time.Until(time.Unix(expiry, 0))); err != nil {
+			out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "save jti revocation"))
+			return
+		}
+	}
+
+	if familyId, _, ok := strings.Cut(in.RefreshToken, "."); ok {
+		family, err := d.RefreshTokenRepository.FindById(ctx, familyId)
+		if err != nil && !errors.Is(err, redis.Nil) {
+			out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "find refresh token family"))
+			return
+		}
+
+		// Only the owner of the family can end it.
+		if err == nil && family.MemberId == uid {
+			if err = d.RefreshTokenRepository.DeleteById(ctx, familyId); err != nil {
+				out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "delete refresh token family"))
+				return
+			}
+		}
+	}
+
+	out.Res.Id = uid
+
+	return
+}
+
+type (
+	RevokeMemberSessionsRes struct {
+		Id string `json:"id"`
+	}
+	RevokeMemberSessionsOut struct {
+		resp.Response
+		Res RevokeMemberSessionsRes
+	}
+)
+
+func (d *UserDeps) RevokeMemberSessions(ctx context.Context, uid string) (out RevokeMemberSessionsOut) {
+	var err error
+	out.Response = resp.NewResponse(http.StatusOK, "", nil)
+
+	_, err = uuid.FromString(uid)
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusNotFound, "", ErrMemberNotFound)
+		return
+	}
+
+	_, err = d.MemberRepository.FindById(ctx, uid)
+	if errors.Is(err, pgx.ErrNoRows) {
+		out.Response = resp.NewResponse(http.StatusNotFound, "", ErrMemberNotFound)
+		return
+	}
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "find member by id"))
+		return
+	}
+
+	if err = d.revokeMemberSessions(ctx, uid); err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "revoke member sessions"))
+		return
+	}
+
+	err = d.Audit.Record(ctx, audit.Event{
+		Action:   "revoke_sessions",
+		Entity:   "member",
+		EntityId: uid,
+	})
+	if err != nil {
+		out.Response = resp.NewResponse(http.StatusInternalServerError, "", errors.Wrap(err, "record audit"))
+		return
+	}
+
+	out.Res.Id = uid
+
+	return
+}
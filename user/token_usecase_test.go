@@ -0,0 +1,228 @@
+package user_test
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/PA-D3RPLA/d3if43-htt-uhomestay/user"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRefreshToken(t *testing.T) {
+	err := ClearTables(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = createUser(memberRepository, memberNormal)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	login := userDeps.MemberLogin(context.Background(), user.LoginIn{
+		Identifier: memberNormal.Username,
+		Password:   memberNormal.Password,
+	})
+	if login.Error != nil {
+		t.Fatal(login.Error)
+	}
+
+	rotated := userDeps.RefreshToken(context.Background(), user.RefreshTokenIn{
+		RefreshToken: login.Res.RefreshToken,
+	})
+	if rotated.Error != nil {
+		t.Fatal(rotated.Error)
+	}
+
+	cases := []struct {
+		Name               string
+		ExpectedStatusCode int
+		In                 user.RefreshTokenIn
+	}{
+		{
+			Name:               "Refresh Token Success",
+			ExpectedStatusCode: http.StatusOK,
+			In: user.RefreshTokenIn{
+				RefreshToken: rotated.Res.RefreshToken,
+			},
+		},
+		{
+			Name:               "Refresh Token Fail, Empty Token",
+			ExpectedStatusCode: http.StatusUnprocessableEntity,
+			In:                 user.RefreshTokenIn{},
+		},
+		{
+			Name:               "Refresh Token Fail, Invalid Token",
+			ExpectedStatusCode: http.StatusUnauthorized,
+			In: user.RefreshTokenIn{
+				RefreshToken: "not-a-refresh-token",
+			},
+		},
+		{
+			Name:               "Refresh Token Fail, Reused Token",
+			ExpectedStatusCode: http.StatusUnauthorized,
+			In: user.RefreshTokenIn{
+				RefreshToken: login.Res.RefreshToken,
+			},
+		},
+		{
+			Name:               "Refresh Token Fail, Family Revoked After Reuse",
+			ExpectedStatusCode: http.StatusUnauthorized,
+			In: user.RefreshTokenIn{
+				RefreshToken: rotated.Res.RefreshToken,
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			res := userDeps.RefreshToken(context.Background(), c.In)
+
+			if res.StatusCode != c.ExpectedStatusCode {
+				t.Logf("%#v", res)
+				t.Fatalf("Expected response code %d. Got %d\n", c.ExpectedStatusCode, res.StatusCode)
+			}
+		})
+	}
+}
+
+func TestRotateRefreshToken(t *testing.T) {
+	family := user.RefreshTokenFamilyModel{
+		Id:        "rotate-family",
+		MemberId:  "rotate-member",
+		TokenHash: "old-hash",
+		CreatedAt: time.Now(),
+	}
+	if err := refreshTokenRepo.Save(context.Background(), family, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	// Concurrent rotations of the current token succeed once, the rest see
+	// the token already replaced.
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	rotatedN := 0
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			ok, err := refreshTokenRepo.Rotate(context.Background(), family.Id, family.TokenHash, "new-hash-"+strconv.Itoa(i), time.Minute)
+			assert.NoError(t, err)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if ok {
+				rotatedN++
+			}
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(t, 1, rotatedN)
+
+	_, err := refreshTokenRepo.Rotate(context.Background(), "missing-family", "old-hash", "new-hash", time.Minute)
+	assert.ErrorIs(t, err, redis.Nil)
+}
+
+func TestLogout(t *testing.T) {
+	err := ClearTables(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	uid, err := createUser(memberRepository, memberNormal)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	login := userDeps.MemberLogin(context.Background(), user.LoginIn{
+		Identifier: memberNormal.Username,
+		Password:   memberNormal.Password,
+	})
+	if login.Error != nil {
+		t.Fatal(login.Error)
+	}
+
+	res := userDeps.Logout(context.Background(), uid, "jti-logout", time.Now().Add(time.Hour).Unix(), user.LogoutIn{
+		RefreshToken: login.Res.RefreshToken,
+	})
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected response code %d. Got %d\n", http.StatusOK, res.StatusCode)
+	}
+
+	revoked, err := userDeps.IsTokenRevoked(context.Background(), "jti-logout", uid, time.Now().Unix())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, revoked)
+
+	refresh := userDeps.RefreshToken(context.Background(), user.RefreshTokenIn{
+		RefreshToken: login.Res.RefreshToken,
+	})
+	assert.Equal(t, http.StatusUnauthorized, refresh.StatusCode)
+}
+
+func TestRevokeMemberSessions(t *testing.T) {
+	err := ClearTables(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	uid, err := createUser(memberRepository, memberNormal)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	login := userDeps.MemberLogin(context.Background(), user.LoginIn{
+		Identifier: memberNormal.Username,
+		Password:   memberNormal.Password,
+	})
+	if login.Error != nil {
+		t.Fatal(login.Error)
+	}
+	issuedAt := time.Now().Unix()
+
+	cases := []struct {
+		Name               string
+		ExpectedStatusCode int
+		Uid                string
+	}{
+		{
+			Name:               "Revoke Member Sessions Success",
+			ExpectedStatusCode: http.StatusOK,
+			Uid:                uid,
+		},
+		{
+			Name:               "Revoke Member Sessions Fail, Member Not Found",
+			ExpectedStatusCode: http.StatusNotFound,
+			Uid:                "not-a-uuid",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			res := userDeps.RevokeMemberSessions(context.Background(), c.Uid)
+
+			if res.StatusCode != c.ExpectedStatusCode {
+				t.Logf("%#v", res)
+				t.Fatalf("Expected response code %d. Got %d\n", c.ExpectedStatusCode, res.StatusCode)
+			}
+		})
+	}
+
+	revoked, err := userDeps.IsTokenRevoked(context.Background(), "", uid, issuedAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, revoked)
+
+	refresh := userDeps.RefreshToken(context.Background(), user.RefreshTokenIn{
+		RefreshToken: login.Res.RefreshToken,
+	})
+	assert.Equal(t, http.StatusUnauthorized, refresh.StatusCode)
+}
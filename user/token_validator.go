@@ -0,0 +1,19 @@
+package user
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrRefreshTokenRequired = errors.New("refresh token tidak boleh kosong")
+)
+
+func ValidateRefreshTokenIn(i RefreshTokenIn) error {
+	if strings.Trim(i.RefreshToken, " ") == "" {
+		return ErrRefreshTokenRequired
+	}
+
+	return nil
+}